@@ -0,0 +1,247 @@
+// Package admin 提供运行中路由器的调试和管理HTTP端点
+// 以JSON（以及一个简单的HTML页面）展示路由表、每个路由的统计、中间件链、对象池统计和最近的错误，
+// 可以挂载到任意http.ServeMux上
+package admin
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+)
+
+// DefaultMaxErrors 是默认保留的最近错误数量
+const DefaultMaxErrors = 50
+
+// Admin 定义管理端点接口
+type Admin interface {
+	// Handler 提供管理端点，路径相对于挂载点:
+	//  - GET /: HTML页面
+	//  - GET /state: 以下所有内容
+	//  - GET /routes: 路由表
+	//  - GET /stats: 每个路由的统计
+	//  - GET /middleware: 全局中间件和管道
+	//  - GET /pools: 缓冲区、上下文和对象池统计
+	//  - GET /errors: 最近的错误，最新的在前
+	http.Handler
+
+	// Middleware 返回记录每个路由统计和最近错误的中间件
+	// 应当在其他中间件之前注册，才能看到其他中间件返回的错误
+	Middleware() router.MiddlewareFunc
+}
+
+// Option 定义管理端点的配置选项
+type Option func(*adminImpl)
+
+// WithPool 在/pools中展示对象池统计
+//   - name: 对象池名称
+func WithPool(name string, pool buffer.StatsProvider) Option {
+	return func(a *adminImpl) {
+		a.pools[name] = pool
+	}
+}
+
+// WithMaxErrors 设置保留的最近错误数量，默认DefaultMaxErrors
+func WithMaxErrors(n int) Option {
+	return func(a *adminImpl) {
+		a.maxErrors = n
+	}
+}
+
+// RouteStats 定义一个路由的处理统计
+type RouteStats struct {
+	// Route 路由名称，未命名时为匹配模式
+	Route string `json:"route"`
+	// Unmatched 为true时表示没有匹配任何路由的消息
+	Unmatched bool `json:"unmatched,omitempty"`
+	// Messages 处理的消息数
+	Messages uint64 `json:"messages"`
+	// Errors 返回错误的消息数
+	Errors uint64 `json:"errors"`
+	// AvgSeconds 平均处理耗时
+	AvgSeconds float64 `json:"avg_seconds"`
+	// MaxSeconds 最长处理耗时
+	MaxSeconds float64 `json:"max_seconds"`
+	// LastSeen 最近一次处理的时间
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// RecentError 定义一次处理错误
+type RecentError struct {
+	// Time 发生时间
+	Time time.Time `json:"time"`
+	// Route 路由名称，没有匹配时为空
+	Route string `json:"route,omitempty"`
+	// Transport 传输层名称
+	Transport string `json:"transport,omitempty"`
+	// Source 消息来源
+	Source string `json:"source,omitempty"`
+	// Error 错误信息
+	Error string `json:"error"`
+}
+
+// routeKey 是统计的键，区分未命名的路由和没有匹配的消息
+type routeKey struct {
+	route     string
+	unmatched bool
+}
+
+// routeStats 累计一个路由的统计
+type routeStats struct {
+	messages uint64
+	errors   uint64
+	total    time.Duration
+	max      time.Duration
+	lastSeen time.Time
+}
+
+// adminImpl 是Admin接口的具体实现
+type adminImpl struct {
+	router    router.Router
+	pools     map[string]buffer.StatsProvider
+	maxErrors int
+	mux       *http.ServeMux
+
+	mu     sync.Mutex
+	stats  map[routeKey]*routeStats
+	errors []RecentError // 环形缓冲区，next是下一个写入位置
+	next   int
+}
+
+// New 创建路由器的管理端点
+//   - r: 需要展示的路由器
+//   - opts: 配置选项
+//
+// 每个路由的统计和最近错误需要通过r.Use(a.Middleware())接入
+func New(r router.Router, opts ...Option) Admin {
+	a := &adminImpl{
+		router:    r,
+		pools:     map[string]buffer.StatsProvider{},
+		maxErrors: DefaultMaxErrors,
+		stats:     map[routeKey]*routeStats{},
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.mux = a.newMux()
+	return a
+}
+
+// ServeHTTP 处理管理端点请求
+func (a *adminImpl) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	a.mux.ServeHTTP(w, req)
+}
+
+// Middleware 返回记录统计的中间件
+func (a *adminImpl) Middleware() router.MiddlewareFunc {
+	return func(ctx router_context.Context, next router.HandlerFunc) error {
+		start := time.Now()
+		err := next(ctx)
+		if err == nil {
+			err = ctx.AbortError()
+		}
+		a.record(ctx, err, start)
+		return err
+	}
+}
+
+// record 累计一条消息的处理结果
+func (a *adminImpl) record(ctx router_context.Context, err error, start time.Time) {
+	now := time.Now()
+	d := now.Sub(start)
+	info := ctx.Route()
+	key := routeKey{route: routeLabel(info), unmatched: info == nil}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s := a.stats[key]
+	if s == nil {
+		s = &routeStats{}
+		a.stats[key] = s
+	}
+	s.messages++
+	s.total += d
+	s.max = max(s.max, d)
+	s.lastSeen = now
+	if err == nil {
+		return
+	}
+
+	s.errors++
+	if a.maxErrors <= 0 {
+		return
+	}
+	md := ctx.Metadata()
+	e := RecentError{
+		Time:      now,
+		Route:     key.route,
+		Transport: md.Transport,
+		Source:    md.Source,
+		Error:     err.Error(),
+	}
+	if len(a.errors) < a.maxErrors {
+		a.errors = append(a.errors, e)
+	} else {
+		a.errors[a.next] = e
+	}
+	a.next = (a.next + 1) % a.maxErrors
+}
+
+// routeStats 返回每个路由的统计快照，按路由名称排序，未匹配的消息排在最后
+func (a *adminImpl) routeStats() []RouteStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	result := make([]RouteStats, 0, len(a.stats))
+	for key, s := range a.stats {
+		rs := RouteStats{
+			Route:      key.route,
+			Unmatched:  key.unmatched,
+			Messages:   s.messages,
+			Errors:     s.errors,
+			MaxSeconds: s.max.Seconds(),
+			LastSeen:   s.lastSeen,
+		}
+		if s.messages > 0 {
+			rs.AvgSeconds = s.total.Seconds() / float64(s.messages)
+		}
+		result = append(result, rs)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Unmatched != result[j].Unmatched {
+			return !result[i].Unmatched
+		}
+		return result[i].Route < result[j].Route
+	})
+	return result
+}
+
+// recentErrors 返回最近的错误，最新的在前
+func (a *adminImpl) recentErrors() []RecentError {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	result := make([]RecentError, 0, len(a.errors))
+	for i := range a.errors {
+		// next之前的一个位置是最新的错误
+		index := (a.next - 1 - i + len(a.errors)) % len(a.errors)
+		result = append(result, a.errors[index])
+	}
+	return result
+}
+
+// routeLabel 返回统计使用的路由名称，未命名时使用匹配模式
+func routeLabel(info *router_context.RouteInfo) string {
+	if info == nil {
+		return ""
+	}
+	if info.Name != "" {
+		return info.Name
+	}
+	return info.Pattern
+}
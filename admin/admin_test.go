@@ -0,0 +1,197 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+)
+
+func newTestAdmin(t *testing.T, opts ...Option) (router.Router, Admin) {
+	t.Helper()
+	r := router.NewRouter()
+	a := New(r, opts...)
+	r.Use(a.Middleware())
+	r.RegisterRoute(router_context.RouteInfo{Name: "orders", Pattern: "order"}, router.PrefixMatcher("order"), func(ctx router_context.Context) error {
+		return nil
+	})
+	r.Match("bad", func(ctx router_context.Context) error {
+		return errors.New("rejected <b>")
+	})
+	return r, a
+}
+
+func route(r router.Router, msg string) {
+	buf := buffer.NewBuffer()
+	buf.Write([]byte(msg))
+	ctx := router_context.WithMetadata(context.Background(), router_context.Metadata{Transport: "test", Source: "peer"})
+	r.Route(ctx, buf)
+}
+
+func get[T any](t *testing.T, h http.Handler, path string) T {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET %s = %d", path, rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("GET %s Content-Type = %q", path, ct)
+	}
+	var v T
+	if err := json.Unmarshal(rec.Body.Bytes(), &v); err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+	return v
+}
+
+func TestAdmin_Routes(t *testing.T) {
+	_, a := newTestAdmin(t)
+
+	routes := get[[]Route](t, a, "/routes")
+	if len(routes) != 2 {
+		t.Fatalf("routes = %+v", routes)
+	}
+	if routes[0].Name != "orders" || routes[0].Matcher != "router.PrefixMatcher" || routes[0].Priority != 0 || routes[0].Middlewares != 1 {
+		t.Errorf("routes[0] = %+v", routes[0])
+	}
+	if routes[1].Pattern != "bad" || routes[1].Priority != 1 {
+		t.Errorf("routes[1] = %+v", routes[1])
+	}
+
+	m := get[Middleware](t, a, "/middleware")
+	if len(m.Global) != 1 || m.Global[0] != "admin.(*adminImpl).Middleware" {
+		t.Errorf("middleware = %+v", m)
+	}
+}
+
+func TestAdmin_StatsAndErrors(t *testing.T) {
+	r, a := newTestAdmin(t, WithMaxErrors(2))
+	for _, msg := range []string{"order", "order", "bad 1", "bad 2", "bad 3", "other"} {
+		route(r, msg)
+	}
+
+	stats := get[[]RouteStats](t, a, "/stats")
+	want := []struct {
+		route     string
+		unmatched bool
+		messages  uint64
+		errors    uint64
+	}{
+		{"bad", false, 3, 3},
+		{"orders", false, 2, 0},
+		{"", true, 1, 0},
+	}
+	if len(stats) != len(want) {
+		t.Fatalf("stats = %+v", stats)
+	}
+	for i, w := range want {
+		s := stats[i]
+		if s.Route != w.route || s.Unmatched != w.unmatched || s.Messages != w.messages || s.Errors != w.errors {
+			t.Errorf("stats[%d] = %+v, want %+v", i, s, w)
+		}
+	}
+
+	// 只保留最近两个错误，最新的在前
+	recent := get[[]RecentError](t, a, "/errors")
+	if len(recent) != 2 {
+		t.Fatalf("errors = %+v", recent)
+	}
+	if recent[0].Route != "bad" || recent[0].Transport != "test" || recent[0].Source != "peer" || recent[0].Error != "rejected <b>" {
+		t.Errorf("errors[0] = %+v", recent[0])
+	}
+}
+
+func TestAdmin_Pools(t *testing.T) {
+	pool := buffer.NewPool()
+	pool.Release(pool.Acquire())
+	r, a := newTestAdmin(t, WithPool("main", pool.(buffer.StatsProvider)))
+	route(r, "order")
+
+	p := get[Pools](t, a, "/pools")
+	if p.Contexts.Acquired != 1 || p.Contexts.Outstanding != 0 {
+		t.Errorf("contexts = %+v", p.Contexts)
+	}
+	if p.Pools["main"].Acquires != 1 {
+		t.Errorf("pools = %+v", p.Pools)
+	}
+
+	state := get[State](t, a, "/state")
+	if len(state.Routes) != 2 || len(state.Stats) != 1 || state.Pools.Pools["main"].Releases != 1 {
+		t.Errorf("state = %+v", state)
+	}
+}
+
+func TestAdmin_HTML(t *testing.T) {
+	r, a := newTestAdmin(t)
+	route(r, "bad")
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", http.StripPrefix("/admin", a))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/", nil))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/html") {
+		t.Fatalf("GET /admin/ = %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "orders") || !strings.Contains(body, "rejected &lt;b&gt;") {
+		t.Errorf("html missing routes or escaped error:\n%s", body)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/admin/routes", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET /admin/routes = %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("POST", "/admin/routes", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /admin/routes = %d", rec.Code)
+	}
+}
+
+func TestAdmin_Health(t *testing.T) {
+	r, a := newTestAdmin(t)
+
+	status := func(path string) (int, router.HealthReport) {
+		rec := httptest.NewRecorder()
+		a.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		var report router.HealthReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		return rec.Code, report
+	}
+
+	if code, report := status("/health"); code != http.StatusOK || !report.Ready || len(report.Checks) != 0 {
+		t.Errorf("empty health = %d %+v", code, report)
+	}
+
+	r.AddHealthCheck(router.HealthCheck{Name: "alive", Kind: router.Liveness, Check: func(context.Context) error { return nil }})
+	r.AddHealthCheck(router.HealthCheck{Name: "dlq", Kind: router.Readiness, Route: "orders", Check: func(context.Context) error {
+		return errors.New("dlq not writable")
+	}})
+
+	if code, _ := status("/health/live"); code != http.StatusOK {
+		t.Errorf("/health/live = %d, want 200", code)
+	}
+	code, report := status("/health/ready")
+	if code != http.StatusServiceUnavailable || report.Ready || !report.Live {
+		t.Errorf("/health/ready = %d %+v", code, report)
+	}
+	if len(report.Checks) != 2 || report.Checks[1].Route != "orders" || report.Checks[1].Error != "dlq not writable" {
+		t.Errorf("checks = %+v", report.Checks)
+	}
+	if code, _ := status("/health"); code != http.StatusServiceUnavailable {
+		t.Errorf("/health = %d, want 503", code)
+	}
+}
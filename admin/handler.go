@@ -0,0 +1,218 @@
+package admin
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+
+	"github.com/aomirun/content-router/buffer"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+)
+
+// Route 是/routes中的一条路由
+type Route struct {
+	Priority int               `json:"priority"`
+	Name     string            `json:"name,omitempty"`
+	Pattern  string            `json:"pattern,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Matcher  string            `json:"matcher"`
+	Handler  string            `json:"handler"`
+	// Middlewares 包裹处理器的中间件数量
+	Middlewares int `json:"middlewares"`
+}
+
+// Pipeline 是/middleware中的一个管道
+type Pipeline struct {
+	Index       int      `json:"index"`
+	Matcher     string   `json:"matcher"`
+	Middlewares []string `json:"middlewares"`
+}
+
+// Middleware 是/middleware的内容
+type Middleware struct {
+	// Global 全局中间件，按执行顺序排列
+	Global    []string   `json:"global"`
+	Pipelines []Pipeline `json:"pipelines"`
+}
+
+// Pools 是/pools的内容
+type Pools struct {
+	Buffers  manage.Stats                `json:"buffers"`
+	Contexts manage.ContextStats         `json:"contexts"`
+	Pools    map[string]buffer.PoolStats `json:"pools,omitempty"`
+}
+
+// State 是/state的内容
+type State struct {
+	Routes     []Route       `json:"routes"`
+	Stats      []RouteStats  `json:"stats"`
+	Middleware Middleware    `json:"middleware"`
+	Pools      Pools         `json:"pools"`
+	Errors     []RecentError `json:"errors"`
+}
+
+// newMux 注册管理端点的路径
+func (a *adminImpl) newMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", a.serveHTML)
+	mux.HandleFunc("GET /state", serveJSON(a.state))
+	mux.HandleFunc("GET /routes", serveJSON(a.routes))
+	mux.HandleFunc("GET /stats", serveJSON(a.routeStats))
+	mux.HandleFunc("GET /middleware", serveJSON(a.middleware))
+	mux.HandleFunc("GET /pools", serveJSON(a.poolStats))
+	mux.HandleFunc("GET /errors", serveJSON(a.recentErrors))
+	mux.HandleFunc("GET /health", a.serveHealth(func(h router.HealthReport) bool { return h.Ready }))
+	mux.HandleFunc("GET /health/live", a.serveHealth(func(h router.HealthReport) bool { return h.Live }))
+	mux.HandleFunc("GET /health/ready", a.serveHealth(func(h router.HealthReport) bool { return h.Ready }))
+	return mux
+}
+
+// serveJSON 返回以JSON输出fn结果的处理函数
+func serveJSON[T any](fn func() T) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(fn())
+	}
+}
+
+// serveHealth 返回执行健康检查的处理函数
+// 响应体是完整的检查结果，healthy返回false时状态码为503，便于直接作为编排系统的探针
+func (a *adminImpl) serveHealth(healthy func(router.HealthReport) bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		report := a.router.Health(req.Context())
+		w.Header().Set("Content-Type", "application/json")
+		if !healthy(report) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	}
+}
+
+// state 汇总所有内容
+func (a *adminImpl) state() State {
+	return State{
+		Routes:     a.routes(),
+		Stats:      a.routeStats(),
+		Middleware: a.middleware(),
+		Pools:      a.poolStats(),
+		Errors:     a.recentErrors(),
+	}
+}
+
+// routes 返回路由表
+func (a *adminImpl) routes() []Route {
+	descs := a.router.Routes()
+	routes := make([]Route, len(descs))
+	for i, desc := range descs {
+		routes[i] = Route{
+			Priority:    desc.Priority,
+			Name:        desc.Info.Name,
+			Pattern:     desc.Info.Pattern,
+			Metadata:    desc.Info.Metadata,
+			Matcher:     desc.Matcher,
+			Handler:     desc.Handler,
+			Middlewares: desc.Middlewares,
+		}
+	}
+	return routes
+}
+
+// middleware 返回全局中间件和管道
+func (a *adminImpl) middleware() Middleware {
+	topo := a.router.Inspect()
+	m := Middleware{
+		Global:    topo.Middlewares,
+		Pipelines: make([]Pipeline, len(topo.Pipelines)),
+	}
+	for i, desc := range topo.Pipelines {
+		m.Pipelines[i] = Pipeline{
+			Index:       desc.Index,
+			Matcher:     desc.Matcher,
+			Middlewares: desc.Middlewares,
+		}
+	}
+	return m
+}
+
+// poolStats 返回缓冲区、上下文和对象池统计
+func (a *adminImpl) poolStats() Pools {
+	p := Pools{
+		Buffers:  a.router.BufferManager().Stats(),
+		Contexts: a.router.ContextManager().Stats(),
+	}
+	if len(a.pools) > 0 {
+		p.Pools = make(map[string]buffer.PoolStats, len(a.pools))
+		for name, pool := range a.pools {
+			p.Pools[name] = pool.Stats()
+		}
+	}
+	return p
+}
+
+// serveHTML 输出HTML页面
+func (a *adminImpl) serveHTML(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	pageTemplate.Execute(w, a.state())
+}
+
+// pageTemplate 是HTML页面模板，链接使用相对路径以便挂载到任意前缀
+var pageTemplate = template.Must(template.New("admin").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>content-router admin</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+th { background: #f4f4f4; }
+</style>
+</head>
+<body>
+<h1>content-router</h1>
+<p>JSON: <a href="state">state</a> <a href="routes">routes</a> <a href="stats">stats</a>
+<a href="middleware">middleware</a> <a href="pools">pools</a> <a href="errors">errors</a> <a href="health">health</a></p>
+
+<h2>Routes</h2>
+<table>
+<tr><th>#</th><th>Name</th><th>Pattern</th><th>Matcher</th><th>Handler</th><th>Middlewares</th></tr>
+{{range .Routes}}<tr><td>{{.Priority}}</td><td>{{.Name}}</td><td>{{.Pattern}}</td><td>{{.Matcher}}</td><td>{{.Handler}}</td><td>{{.Middlewares}}</td></tr>
+{{end}}</table>
+
+<h2>Stats</h2>
+<table>
+<tr><th>Route</th><th>Messages</th><th>Errors</th><th>Avg (s)</th><th>Max (s)</th><th>Last seen</th></tr>
+{{range .Stats}}<tr><td>{{if .Unmatched}}<em>unmatched</em>{{else}}{{.Route}}{{end}}</td><td>{{.Messages}}</td><td>{{.Errors}}</td><td>{{printf "%.6f" .AvgSeconds}}</td><td>{{printf "%.6f" .MaxSeconds}}</td><td>{{.LastSeen.Format "2006-01-02 15:04:05"}}</td></tr>
+{{end}}</table>
+
+<h2>Middleware</h2>
+<ol>
+{{range .Middleware.Global}}<li>{{.}}</li>
+{{end}}</ol>
+{{range .Middleware.Pipelines}}<h3>Pipeline {{.Index}}: {{.Matcher}}</h3>
+<ol>
+{{range .Middlewares}}<li>{{.}}</li>
+{{end}}</ol>
+{{end}}
+
+<h2>Pools</h2>
+<table>
+<tr><th>Name</th><th>Acquires</th><th>Releases</th><th>Misses</th><th>Dropped</th><th>Double releases</th><th>Retained bytes</th></tr>
+{{range $name, $p := .Pools.Pools}}<tr><td>{{$name}}</td><td>{{$p.Acquires}}</td><td>{{$p.Releases}}</td><td>{{$p.Misses}}</td><td>{{$p.Dropped}}</td><td>{{$p.DoubleReleases}}</td><td>{{$p.RetainedBytes}}</td></tr>
+{{end}}</table>
+<p>Buffers outstanding: {{.Pools.Buffers.Outstanding}} ({{.Pools.Buffers.OutstandingBytes}} bytes),
+contexts outstanding: {{.Pools.Contexts.Outstanding}}</p>
+
+<h2>Recent errors</h2>
+<table>
+<tr><th>Time</th><th>Route</th><th>Transport</th><th>Source</th><th>Error</th></tr>
+{{range .Errors}}<tr><td>{{.Time.Format "2006-01-02 15:04:05.000"}}</td><td>{{.Route}}</td><td>{{.Transport}}</td><td>{{.Source}}</td><td>{{.Error}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
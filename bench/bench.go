@@ -0,0 +1,265 @@
+// Package bench 提供路由器的负载生成和性能分析工具
+// 按目标速率或最大吞吐量驱动真实的路由器，报告吞吐量、延迟百分位数和每条消息的分配次数，
+// 用来在微基准测试之外衡量路由和缓冲池改动对性能的影响
+package bench
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aomirun/content-router/router"
+)
+
+const (
+	// DefaultDuration 是默认的压测时长
+	DefaultDuration = 10 * time.Second
+	// DefaultSamples 是默认预先生成的负载数量
+	DefaultSamples = 1024
+	// tickInterval 是限速模式下调度器的最小检查间隔
+	tickInterval = time.Millisecond
+)
+
+// ErrNoPayload 表示没有配置负载
+var ErrNoPayload = errors.New("bench: no payload configured")
+
+// Router 定义压测需要的路由器功能
+// 消息缓冲区从路由器的BufferManager获取，路由完成后释放，与消息来源的用法一致
+type Router interface {
+	router.RouteHandler
+	router.BufferManagerAccessor
+}
+
+// Option 定义压测的配置选项
+type Option func(*config)
+
+// config 保存压测配置
+type config struct {
+	rate        float64
+	duration    time.Duration
+	requests    int
+	concurrency int
+	warmup      time.Duration
+	payload     Payload
+	samples     int
+	seed        uint64
+}
+
+// WithRate 设置目标速率（每秒消息数）
+// 限速模式下按固定间隔调度消息，延迟从计划发送的时间开始计算，
+// 路由器跟不上时排队等待的时间也计入延迟，避免协调遗漏（coordinated omission）。
+// 0表示不限速，各个工作goroutine尽可能快地发送，默认不限速
+func WithRate(perSecond float64) Option {
+	return func(c *config) {
+		c.rate = perSecond
+	}
+}
+
+// WithDuration 设置压测时长，默认为DefaultDuration
+func WithDuration(d time.Duration) Option {
+	return func(c *config) {
+		c.duration = d
+	}
+}
+
+// WithRequests 设置发送的消息总数，达到后提前结束，0表示只受时长限制
+func WithRequests(n int) Option {
+	return func(c *config) {
+		c.requests = n
+	}
+}
+
+// WithConcurrency 设置并发的工作goroutine数量，默认为GOMAXPROCS
+func WithConcurrency(n int) Option {
+	return func(c *config) {
+		c.concurrency = n
+	}
+}
+
+// WithWarmup 设置预热时长，预热期间的消息不计入结果
+// 用来填充缓冲池和上下文池，默认不预热
+func WithWarmup(d time.Duration) Option {
+	return func(c *config) {
+		c.warmup = d
+	}
+}
+
+// WithPayload 设置负载分布
+func WithPayload(p Payload) Option {
+	return func(c *config) {
+		c.payload = p
+	}
+}
+
+// WithSamples 设置预先生成的负载数量，默认为DefaultSamples
+// 压测时循环使用这些负载，数量应足以体现负载分布
+func WithSamples(n int) Option {
+	return func(c *config) {
+		c.samples = n
+	}
+}
+
+// WithSeed 设置生成负载的随机数种子，相同种子生成相同的负载序列
+func WithSeed(seed uint64) Option {
+	return func(c *config) {
+		c.seed = seed
+	}
+}
+
+// newConfig 应用配置选项
+func newConfig(opts []Option) config {
+	c := config{
+		duration:    DefaultDuration,
+		concurrency: runtime.GOMAXPROCS(0),
+		samples:     DefaultSamples,
+		seed:        1,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	c.concurrency = max(c.concurrency, 1)
+	c.samples = max(c.samples, 1)
+	return c
+}
+
+// worker 是一个工作goroutine的统计数据，只由该goroutine写入
+type worker struct {
+	latency  histogram
+	requests uint64
+	errors   uint64
+	bytes    uint64
+}
+
+// Run 按配置对路由器压测，直到达到时长、消息总数或ctx被取消
+// 压测期间的分配次数通过runtime.ReadMemStats统计，包括路由器和处理器的分配，
+// 同一进程中其他goroutine的分配也会计入，应当在独立的进程或测试中运行
+func Run(ctx context.Context, r Router, opts ...Option) (*Report, error) {
+	cfg := newConfig(opts)
+	if cfg.payload == nil {
+		return nil, ErrNoPayload
+	}
+
+	rng := rand.New(rand.NewPCG(cfg.seed, cfg.seed))
+	samples := make([][]byte, cfg.samples)
+	for i := range samples {
+		samples[i] = cfg.payload(rng)
+	}
+
+	if cfg.warmup > 0 {
+		warmupCtx, cancel := context.WithTimeout(ctx, cfg.warmup)
+		run(warmupCtx, ctx, r, cfg, samples, make([]worker, cfg.concurrency))
+		cancel()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, cfg.duration)
+	defer cancel()
+
+	workers := make([]worker, cfg.concurrency)
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	run(runCtx, ctx, r, cfg, samples, workers)
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	return newReport(cfg, workers, elapsed, &before, &after), nil
+}
+
+// run 启动工作goroutine并等待它们结束
+//   - stop: 被取消时停止发送新消息
+//   - parent: 传给路由器的父上下文，压测时长到期不会取消正在处理的消息
+func run(stop, parent context.Context, r Router, cfg config, samples [][]byte, workers []worker) {
+	// 不限速时由工作goroutine自行领取序号，限速时由调度器按计划时间发放
+	var next atomic.Int64
+	var schedule chan time.Time
+	if cfg.rate > 0 {
+		schedule = make(chan time.Time, len(workers))
+		go dispatch(stop, cfg, schedule)
+	}
+
+	var wg sync.WaitGroup
+	for i := range workers {
+		w := &workers[i]
+		wg.Go(func() {
+			for {
+				var planned time.Time
+				if schedule != nil {
+					var ok bool
+					if planned, ok = <-schedule; !ok {
+						return
+					}
+				} else {
+					if stop.Err() != nil {
+						return
+					}
+					if n := next.Add(1); cfg.requests > 0 && n > int64(cfg.requests) {
+						return
+					}
+					planned = time.Now()
+				}
+				data := samples[(w.requests*uint64(len(workers))+uint64(i))%uint64(len(samples))]
+				err := route(parent, r, data)
+				w.latency.record(time.Since(planned))
+				w.requests++
+				w.bytes += uint64(len(data))
+				if err != nil {
+					w.errors++
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
+
+// dispatch 按目标速率生成计划发送时间，结束时关闭schedule
+// 每次检查时补发所有已到期的消息，即使定时器不够精确，长期速率也保持准确
+func dispatch(ctx context.Context, cfg config, schedule chan<- time.Time) {
+	defer close(schedule)
+	interval := time.Duration(float64(time.Second) / cfg.rate)
+	ticker := time.NewTicker(max(interval, tickInterval))
+	defer ticker.Stop()
+
+	start := time.Now()
+	sent := 0
+	for {
+		due := int(time.Since(start).Seconds()*cfg.rate) + 1
+		if cfg.requests > 0 {
+			due = min(due, cfg.requests)
+		}
+		for ; sent < due; sent++ {
+			select {
+			case schedule <- start.Add(time.Duration(sent) * interval):
+			case <-ctx.Done():
+				return
+			}
+		}
+		if cfg.requests > 0 && sent >= cfg.requests {
+			return
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// route 把一条消息交给路由器处理
+func route(ctx context.Context, r Router, data []byte) error {
+	manager := r.BufferManager()
+	buf := manager.Acquire()
+	defer manager.Release(buf)
+	if _, err := buf.Write(data); err != nil {
+		return err
+	}
+	_, err := r.Route(ctx, buf)
+	return err
+}
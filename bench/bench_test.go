@@ -0,0 +1,123 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+)
+
+func newTestRouter(manager manage.BufferManager, routed *atomic.Int64) router.Router {
+	r := router.NewRouter(router.WithBufferManager(manager))
+	r.Match("order", func(ctx router_context.Context) error {
+		routed.Add(1)
+		return nil
+	})
+	r.Match("bad", func(ctx router_context.Context) error {
+		routed.Add(1)
+		return errors.New("rejected")
+	})
+	return r
+}
+
+func TestRunRequests(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var routed atomic.Int64
+	r := newTestRouter(manager, &routed)
+
+	rep, err := Run(context.Background(), r,
+		WithRequests(1000),
+		WithConcurrency(4),
+		WithPayload(Weighted(
+			WeightedPayload{Weight: 9, Payload: Fixed([]byte("order:1"))},
+			WeightedPayload{Weight: 1, Payload: Fixed([]byte("bad"))},
+		)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Requests != 1000 || routed.Load() != 1000 {
+		t.Errorf("requests = %d, routed = %d, want 1000", rep.Requests, routed.Load())
+	}
+	if rep.Errors == 0 || rep.Errors >= rep.Requests/2 {
+		t.Errorf("errors = %d, want about 10%% of %d", rep.Errors, rep.Requests)
+	}
+	if rep.Throughput <= 0 || rep.Latency.P50 <= 0 || rep.Latency.P50 > rep.Latency.P99 || rep.Latency.P99 > rep.Latency.Max {
+		t.Errorf("unexpected report: %+v", rep)
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRunRate(t *testing.T) {
+	var routed atomic.Int64
+	r := newTestRouter(manage.NewBufferManager(), &routed)
+
+	rep, err := Run(context.Background(), r,
+		WithRate(1000),
+		WithDuration(200*time.Millisecond),
+		WithPayload(Fixed([]byte("order:1"))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 200ms内按1000/s调度约200条消息，允许定时器误差
+	if rep.Requests < 100 || rep.Requests > 300 {
+		t.Errorf("requests = %d, want about 200", rep.Requests)
+	}
+	if rep.Rate != 1000 {
+		t.Errorf("rate = %v, want 1000", rep.Rate)
+	}
+}
+
+func TestRunRateRequests(t *testing.T) {
+	var routed atomic.Int64
+	r := newTestRouter(manage.NewBufferManager(), &routed)
+
+	rep, err := Run(context.Background(), r,
+		WithRate(10000),
+		WithRequests(50),
+		WithWarmup(10*time.Millisecond),
+		WithPayload(Fixed([]byte("order:1"))),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Requests != 50 {
+		t.Errorf("requests = %d, want 50", rep.Requests)
+	}
+}
+
+func TestRunNoPayload(t *testing.T) {
+	var routed atomic.Int64
+	if _, err := Run(context.Background(), newTestRouter(manage.NewBufferManager(), &routed)); !errors.Is(err, ErrNoPayload) {
+		t.Errorf("err = %v, want ErrNoPayload", err)
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	var routed atomic.Int64
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Run(ctx, newTestRouter(manage.NewBufferManager(), &routed), WithPayload(Fixed([]byte("order"))))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestReportString(t *testing.T) {
+	rep := &Report{Requests: 10, Duration: time.Second, Throughput: 10, Concurrency: 2, Latency: Latency{P99: time.Millisecond}}
+	s := rep.String()
+	for _, want := range []string{"requests", "unlimited", "p99 1ms", "allocs/op"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("report missing %q:\n%s", want, s)
+		}
+	}
+}
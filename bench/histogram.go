@@ -0,0 +1,89 @@
+package bench
+
+import (
+	"math/bits"
+	"time"
+)
+
+// subBuckets 是每个2的幂区间内的线性子桶数量，决定百分位数的相对精度（约3%）
+const (
+	subBucketBits = 5
+	subBuckets    = 1 << subBucketBits
+)
+
+// histogram 是对数线性分桶的延迟直方图
+// 桶数组大小固定，记录延迟时不分配内存，不会影响分配计数
+type histogram struct {
+	counts [64 * subBuckets]uint64
+	total  uint64
+	sum    time.Duration
+	min    time.Duration
+	max    time.Duration
+}
+
+// bucketIndex 返回延迟所在的桶
+// 小于subBuckets纳秒的延迟逐纳秒分桶，更大的延迟按最高位分组后再线性细分
+func bucketIndex(d time.Duration) int {
+	v := uint64(max(d, 0))
+	if v < subBuckets {
+		return int(v)
+	}
+	exp := bits.Len64(v) - subBucketBits
+	return exp*subBuckets + int(v>>(exp-1)) - subBuckets
+}
+
+// bucketUpper 返回桶内延迟的上界
+func bucketUpper(i int) time.Duration {
+	if i < subBuckets {
+		return time.Duration(i)
+	}
+	exp, sub := i/subBuckets, i%subBuckets
+	return time.Duration((uint64(subBuckets+sub+1) << (exp - 1)) - 1)
+}
+
+// record 记录一次延迟
+func (h *histogram) record(d time.Duration) {
+	h.counts[bucketIndex(d)]++
+	if h.total == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.total++
+	h.sum += d
+}
+
+// merge 合并另一个直方图
+func (h *histogram) merge(o *histogram) {
+	if o.total == 0 {
+		return
+	}
+	for i, n := range o.counts {
+		h.counts[i] += n
+	}
+	if h.total == 0 || o.min < h.min {
+		h.min = o.min
+	}
+	h.max = max(h.max, o.max)
+	h.total += o.total
+	h.sum += o.sum
+}
+
+// quantile 返回百分位数，q在[0, 1]之间
+// 结果是所在桶的上界，不超过记录到的最大值
+func (h *histogram) quantile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := uint64(q*float64(h.total) + 0.5)
+	rank = max(rank, 1)
+	var seen uint64
+	for i, n := range h.counts {
+		seen += n
+		if seen >= rank {
+			return min(bucketUpper(i), h.max)
+		}
+	}
+	return h.max
+}
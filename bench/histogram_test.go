@@ -0,0 +1,50 @@
+package bench
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBucketBounds(t *testing.T) {
+	for _, d := range []time.Duration{0, 1, 31, 32, 33, 63, 64, 1000, time.Millisecond, time.Second, time.Hour} {
+		i := bucketIndex(d)
+		if upper := bucketUpper(i); upper < d {
+			t.Errorf("bucketUpper(bucketIndex(%d)) = %d, want >= %d", d, upper, d)
+		}
+		if i > 0 && bucketUpper(i-1) >= d {
+			t.Errorf("%d also fits in bucket %d", d, i-1)
+		}
+		if upper := bucketUpper(i); float64(upper-d) > float64(d)*0.04 && d >= subBuckets {
+			t.Errorf("bucket for %d too wide: upper %d", d, upper)
+		}
+	}
+}
+
+func TestHistogramQuantile(t *testing.T) {
+	var a, b histogram
+	for i := 1; i <= 500; i++ {
+		a.record(time.Duration(i) * time.Microsecond)
+	}
+	for i := 501; i <= 1000; i++ {
+		b.record(time.Duration(i) * time.Microsecond)
+	}
+	a.merge(&b)
+
+	if a.total != 1000 || a.min != time.Microsecond || a.max != time.Millisecond {
+		t.Fatalf("total = %d, min = %v, max = %v", a.total, a.min, a.max)
+	}
+	for _, tc := range []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0.5, 500 * time.Microsecond},
+		{0.9, 900 * time.Microsecond},
+		{0.99, 990 * time.Microsecond},
+		{1, time.Millisecond},
+	} {
+		got := a.quantile(tc.q)
+		if got < tc.want || float64(got-tc.want) > float64(tc.want)*0.04 {
+			t.Errorf("quantile(%v) = %v, want about %v", tc.q, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package bench
+
+import (
+	"math/rand/v2"
+)
+
+// Payload 定义负载生成函数，每次调用返回一条消息
+// 负载在压测开始前预先生成，生成过程的分配不会计入结果
+type Payload func(rng *rand.Rand) []byte
+
+// Fixed 返回始终生成同一条消息的负载
+func Fixed(data []byte) Payload {
+	return func(*rand.Rand) []byte {
+		return data
+	}
+}
+
+// Uniform 返回从给定消息中均匀随机选择的负载
+func Uniform(samples ...[]byte) Payload {
+	if len(samples) == 0 {
+		panic("bench: Uniform requires at least one sample")
+	}
+	return func(rng *rand.Rand) []byte {
+		return samples[rng.IntN(len(samples))]
+	}
+}
+
+// Zipf 返回按Zipf分布从给定消息中选择的负载，靠前的消息被选中的概率更高
+// 用来模拟少数路由承担大部分流量的情况
+//   - s: 分布参数，必须大于1，越大越集中在前几条消息
+func Zipf(s float64, samples ...[]byte) Payload {
+	if len(samples) == 0 {
+		panic("bench: Zipf requires at least one sample")
+	}
+	if s <= 1 {
+		panic("bench: Zipf parameter must be greater than 1")
+	}
+	return func(rng *rand.Rand) []byte {
+		zipf := rand.NewZipf(rng, s, 1, uint64(len(samples)-1))
+		return samples[zipf.Uint64()]
+	}
+}
+
+// WeightedPayload 是带权重的负载
+type WeightedPayload struct {
+	Weight  int
+	Payload Payload
+}
+
+// Weighted 返回按权重从多个负载中选择的负载
+// 例如按流量比例混合订单、心跳和非法消息
+func Weighted(choices ...WeightedPayload) Payload {
+	total := 0
+	for _, c := range choices {
+		if c.Weight < 0 {
+			panic("bench: negative payload weight")
+		}
+		total += c.Weight
+	}
+	if total == 0 {
+		panic("bench: Weighted requires a positive total weight")
+	}
+	return func(rng *rand.Rand) []byte {
+		n := rng.IntN(total)
+		for _, c := range choices {
+			if n < c.Weight {
+				return c.Payload(rng)
+			}
+			n -= c.Weight
+		}
+		panic("unreachable")
+	}
+}
+
+// RandomSize 返回以prefix开头、总长度在[min, max]之间均匀分布的负载
+// prefix之后用可打印的ASCII字符填充，用来测量消息大小对路由和缓冲池的影响
+func RandomSize(prefix []byte, min, max int) Payload {
+	if min < len(prefix) || max < min {
+		panic("bench: invalid payload size range")
+	}
+	return func(rng *rand.Rand) []byte {
+		size := min + rng.IntN(max-min+1)
+		data := make([]byte, size)
+		copy(data, prefix)
+		for i := len(prefix); i < size; i++ {
+			data[i] = byte(' ' + rng.IntN('~'-' '+1))
+		}
+		return data
+	}
+}
@@ -0,0 +1,76 @@
+package bench
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestWeighted(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 1))
+	p := Weighted(
+		WeightedPayload{Weight: 3, Payload: Fixed([]byte("a"))},
+		WeightedPayload{Weight: 0, Payload: Fixed([]byte("never"))},
+		WeightedPayload{Weight: 1, Payload: Fixed([]byte("b"))},
+	)
+	counts := map[string]int{}
+	for range 4000 {
+		counts[string(p(rng))]++
+	}
+	if counts["never"] != 0 || counts["a"] < 2700 || counts["a"] > 3300 {
+		t.Errorf("counts = %v, want about 3000 a and 1000 b", counts)
+	}
+}
+
+func TestZipf(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 1))
+	p := Zipf(2, []byte("hot"), []byte("warm"), []byte("cold"))
+	counts := map[string]int{}
+	for range 1000 {
+		counts[string(p(rng))]++
+	}
+	if counts["hot"] <= counts["warm"] || counts["warm"] <= counts["cold"] {
+		t.Errorf("counts = %v, want decreasing", counts)
+	}
+}
+
+func TestUniform(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 1))
+	p := Uniform([]byte("a"), []byte("b"))
+	seen := map[string]bool{}
+	for range 100 {
+		seen[string(p(rng))] = true
+	}
+	if len(seen) != 2 {
+		t.Errorf("seen = %v, want both samples", seen)
+	}
+}
+
+func TestRandomSize(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 1))
+	p := RandomSize([]byte("order:"), 10, 20)
+	for range 100 {
+		data := p(rng)
+		if len(data) < 10 || len(data) > 20 || !bytes.HasPrefix(data, []byte("order:")) {
+			t.Fatalf("unexpected payload %q", data)
+		}
+	}
+}
+
+func TestPayloadPanics(t *testing.T) {
+	for name, fn := range map[string]func(){
+		"uniform":  func() { Uniform() },
+		"zipf":     func() { Zipf(1, []byte("a")) },
+		"weighted": func() { Weighted(WeightedPayload{Weight: 0, Payload: Fixed(nil)}) },
+		"size":     func() { RandomSize([]byte("long prefix"), 1, 2) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: expected panic", name)
+				}
+			}()
+			fn()
+		}()
+	}
+}
@@ -0,0 +1,109 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Report 是一次压测的结果
+type Report struct {
+	// Requests 是完成的消息数
+	Requests uint64 `json:"requests"`
+	// Errors 是Route返回错误的消息数
+	Errors uint64 `json:"errors"`
+	// Bytes 是发送的负载总字节数
+	Bytes uint64 `json:"bytes"`
+	// Duration 是实际的压测时长
+	Duration time.Duration `json:"duration"`
+	// Rate 是配置的目标速率，0表示不限速
+	Rate float64 `json:"rate,omitempty"`
+	// Throughput 是每秒完成的消息数
+	Throughput float64 `json:"throughput"`
+	// Concurrency 是工作goroutine数量
+	Concurrency int `json:"concurrency"`
+	// Latency 是单条消息的延迟分布
+	Latency Latency `json:"latency"`
+	// AllocsPerOp 是每条消息的平均分配次数
+	AllocsPerOp float64 `json:"allocs_per_op"`
+	// BytesPerOp 是每条消息的平均分配字节数
+	BytesPerOp float64 `json:"bytes_per_op"`
+	// GCCycles 是压测期间完成的GC次数
+	GCCycles uint32 `json:"gc_cycles"`
+}
+
+// Latency 是延迟分布
+// 百分位数来自对数线性分桶的直方图，相对误差约为3%
+type Latency struct {
+	Min  time.Duration `json:"min"`
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P99  time.Duration `json:"p99"`
+	P999 time.Duration `json:"p999"`
+	Max  time.Duration `json:"max"`
+}
+
+// newReport 汇总各个工作goroutine的统计数据
+func newReport(cfg config, workers []worker, elapsed time.Duration, before, after *runtime.MemStats) *Report {
+	var h histogram
+	rep := &Report{
+		Duration:    elapsed,
+		Rate:        cfg.rate,
+		Concurrency: len(workers),
+		GCCycles:    after.NumGC - before.NumGC,
+	}
+	for i := range workers {
+		w := &workers[i]
+		h.merge(&w.latency)
+		rep.Requests += w.requests
+		rep.Errors += w.errors
+		rep.Bytes += w.bytes
+	}
+	if elapsed > 0 {
+		rep.Throughput = float64(rep.Requests) / elapsed.Seconds()
+	}
+	if rep.Requests > 0 {
+		rep.AllocsPerOp = float64(after.Mallocs-before.Mallocs) / float64(rep.Requests)
+		rep.BytesPerOp = float64(after.TotalAlloc-before.TotalAlloc) / float64(rep.Requests)
+		rep.Latency = Latency{
+			Min:  h.min,
+			Mean: h.sum / time.Duration(h.total),
+			P50:  h.quantile(0.50),
+			P90:  h.quantile(0.90),
+			P99:  h.quantile(0.99),
+			P999: h.quantile(0.999),
+			Max:  h.max,
+		}
+	}
+	return rep
+}
+
+// WriteTo 以表格形式输出结果
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	rate := "unlimited"
+	if r.Rate > 0 {
+		rate = fmt.Sprintf("%.0f/s", r.Rate)
+	}
+	fmt.Fprintf(tw, "requests\t%d\t(%d errors)\n", r.Requests, r.Errors)
+	fmt.Fprintf(tw, "duration\t%v\t(rate %s, concurrency %d)\n", r.Duration.Round(time.Millisecond), rate, r.Concurrency)
+	fmt.Fprintf(tw, "throughput\t%.0f msg/s\t(%.2f MB/s)\n", r.Throughput, float64(r.Bytes)/r.Duration.Seconds()/1e6)
+	fmt.Fprintf(tw, "latency\tmin %v\tmean %v\tmax %v\n", r.Latency.Min, r.Latency.Mean, r.Latency.Max)
+	fmt.Fprintf(tw, "\tp50 %v\tp90 %v\tp99 %v\tp99.9 %v\n", r.Latency.P50, r.Latency.P90, r.Latency.P99, r.Latency.P999)
+	fmt.Fprintf(tw, "allocs\t%.1f allocs/op\t%.0f B/op\t(%d GC cycles)\n", r.AllocsPerOp, r.BytesPerOp, r.GCCycles)
+	tw.Flush()
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// String 返回表格形式的结果
+func (r *Report) String() string {
+	var b strings.Builder
+	r.WriteTo(&b)
+	return b.String()
+}
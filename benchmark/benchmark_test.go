@@ -2,11 +2,13 @@ package benchmark
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/aomirun/content-router"
 	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
 	"github.com/aomirun/content-router/router"
 )
 
@@ -36,6 +38,66 @@ func BenchmarkRouter_Route(b *testing.B) {
 	}
 }
 
+// benchmarkManyContains 注册500条包含匹配路由，消息匹配最后一条
+func benchmarkManyContains(b *testing.B, minRoutes int) {
+	// 创建路由器
+	r := router.NewRouter(router.WithMultiContains(minRoutes))
+	for i := 0; i < 500; i++ {
+		r.Register(router.ContainsMatcher(fmt.Sprintf("event-%03d;", i)), func(ctx router_context.Context) error {
+			return nil
+		})
+	}
+
+	// 创建缓冲区
+	buf := contentrouter.NewBuffer()
+	buf.WriteString(`{"ts":1718000000,"host":"gw-1","msg":"event-499;"}`)
+
+	// 重置计时器
+	b.ResetTimer()
+
+	// 运行基准测试
+	for i := 0; i < b.N; i++ {
+		if _, err := r.Route(context.Background(), buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRouter_ManyContains(b *testing.B) {
+	b.Run("scan", func(b *testing.B) { benchmarkManyContains(b, 0) })
+	b.Run("automaton", func(b *testing.B) { benchmarkManyContains(b, router.DefaultMultiContainsRoutes) })
+}
+
+// benchmarkManyPrefixes 用Match注册500条前缀路由，消息匹配最后一条
+func benchmarkManyPrefixes(b *testing.B, minRoutes int) {
+	// 创建路由器
+	r := router.NewRouter(router.WithPrefixTrie(minRoutes))
+	for i := 0; i < 500; i++ {
+		r.Match(fmt.Sprintf("CMD%03d ", i), func(ctx router_context.Context) error {
+			return nil
+		})
+	}
+
+	// 创建缓冲区
+	buf := contentrouter.NewBuffer()
+	buf.WriteString("CMD499 restart gateway")
+
+	// 重置计时器
+	b.ResetTimer()
+
+	// 运行基准测试
+	for i := 0; i < b.N; i++ {
+		if _, err := r.Route(context.Background(), buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRouter_ManyPrefixes(b *testing.B) {
+	b.Run("scan", func(b *testing.B) { benchmarkManyPrefixes(b, 0) })
+	b.Run("trie", func(b *testing.B) { benchmarkManyPrefixes(b, router.DefaultPrefixTrieRoutes) })
+}
+
 func BenchmarkRouter_RouteWithMiddleware(b *testing.B) {
 	// 创建路由器
 	router := contentrouter.NewRouter()
@@ -253,6 +315,42 @@ func BenchmarkMatcher_Contains(b *testing.B) {
 	}
 }
 
+func BenchmarkMatcher_Regex(b *testing.B) {
+	// 创建缓冲区和上下文
+	buf := contentrouter.NewBuffer()
+	buf.WriteString("Hello, World!")
+	ctx := contentrouter.NewContext(context.Background(), buf)
+
+	// 创建匹配器
+	matcher := router.RegexMatcher(`^Hello, [A-Z][a-z]+!$`)
+
+	// 重置计时器
+	b.ResetTimer()
+
+	// 运行基准测试
+	for i := 0; i < b.N; i++ {
+		_ = matcher.Match(ctx)
+	}
+}
+
+func BenchmarkMatcher_JSONField(b *testing.B) {
+	// 创建缓冲区和上下文
+	buf := contentrouter.NewBuffer()
+	buf.WriteString(`{"id":"6f1c","meta":{"source":"checkout","type":"order.created"},"items":[{"sku":"A-1","qty":2}]}`)
+	ctx := contentrouter.NewContext(context.Background(), buf)
+
+	// 创建匹配器
+	matcher := router.JSONFieldMatcher("meta.type", "order.created")
+
+	// 重置计时器
+	b.ResetTimer()
+
+	// 运行基准测试
+	for i := 0; i < b.N; i++ {
+		_ = matcher.Match(ctx)
+	}
+}
+
 func BenchmarkPipeline_WithMiddleware(b *testing.B) {
 	// 创建路由器
 	r := contentrouter.NewRouter()
@@ -0,0 +1,99 @@
+package buffer
+
+import "bytes"
+
+// BytesBuffer 定义与*bytes.Buffer和bytebufferpool.ByteBuffer兼容的最小接口
+// 用于将已有代码中的缓冲区类型接入路由器
+type BytesBuffer interface {
+	Bytes() []byte
+	Len() int
+	Write(p []byte) (int, error)
+	WriteString(s string) (int, error)
+	Reset()
+}
+
+// bytesBufferAdapter 将BytesBuffer适配为Buffer
+type bytesBufferAdapter struct {
+	b BytesBuffer
+}
+
+// FromBytesBuffer 将*bytes.Buffer或bytebufferpool.ByteBuffer包装为Buffer（不复制数据）
+func FromBytesBuffer(b BytesBuffer) Buffer {
+	return &bytesBufferAdapter{b: b}
+}
+
+// ToBytesBuffer 将Buffer的数据包装为*bytes.Buffer（共享底层数组，不复制数据）
+// 对于bytebufferpool.ByteBuffer，可以直接赋值 bb.B = buf.Get()
+func ToBytesBuffer(buf Buffer) *bytes.Buffer {
+	return bytes.NewBuffer(buf.Get())
+}
+
+// Unwrap 返回被包装的原始缓冲区
+func (a *bytesBufferAdapter) Unwrap() BytesBuffer {
+	return a.b
+}
+
+// Get 获取底层字节数组的引用
+func (a *bytesBufferAdapter) Get() []byte {
+	return a.b.Bytes()
+}
+
+// Len 获取当前有效数据长度
+func (a *bytesBufferAdapter) Len() int {
+	return a.b.Len()
+}
+
+// Cap 获取缓冲区容量
+func (a *bytesBufferAdapter) Cap() int {
+	if c, ok := a.b.(interface{ Cap() int }); ok {
+		return c.Cap()
+	}
+	return cap(a.b.Bytes())
+}
+
+// Write 写入数据到被包装的缓冲区
+func (a *bytesBufferAdapter) Write(p []byte) (n int, err error) {
+	return a.b.Write(p)
+}
+
+// WriteString 写入字符串到被包装的缓冲区
+func (a *bytesBufferAdapter) WriteString(s string) (n int, err error) {
+	return a.b.WriteString(s)
+}
+
+// Reset 重置被包装的缓冲区
+func (a *bytesBufferAdapter) Reset() {
+	a.b.Reset()
+}
+
+// Truncate 将缓冲区截断到指定长度
+func (a *bytesBufferAdapter) Truncate(n int) {
+	if n < 0 || n >= a.b.Len() {
+		return
+	}
+	if t, ok := a.b.(interface{ Truncate(n int) }); ok {
+		t.Truncate(n)
+		return
+	}
+	// 没有Truncate方法时，重置后写回前n个字节（原地复制，不分配内存）
+	kept := a.b.Bytes()[:n]
+	a.b.Reset()
+	a.b.Write(kept)
+}
+
+// Slice 创建子切片但不复制数据
+func (a *bytesBufferAdapter) Slice(start, end int) Buffer {
+	return &bufferImpl{
+		data: a.b.Bytes()[start:end],
+	}
+}
+
+// Clone 创建缓冲区的深拷贝
+func (a *bytesBufferAdapter) Clone() Buffer {
+	data := a.b.Bytes()
+	clone := make([]byte, len(data))
+	copy(clone, data)
+	return &bufferImpl{
+		data: clone,
+	}
+}
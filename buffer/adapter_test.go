@@ -0,0 +1,70 @@
+package buffer
+
+import (
+	"bytes"
+	"testing"
+)
+
+// byteBuffer 模拟bytebufferpool.ByteBuffer，没有Truncate和Cap方法
+type byteBuffer struct {
+	B []byte
+}
+
+func (b *byteBuffer) Bytes() []byte { return b.B }
+func (b *byteBuffer) Len() int      { return len(b.B) }
+func (b *byteBuffer) Reset()        { b.B = b.B[:0] }
+
+func (b *byteBuffer) Write(p []byte) (int, error) {
+	b.B = append(b.B, p...)
+	return len(p), nil
+}
+
+func (b *byteBuffer) WriteString(s string) (int, error) {
+	b.B = append(b.B, s...)
+	return len(s), nil
+}
+
+func TestFromBytesBuffer(t *testing.T) {
+	var std bytes.Buffer
+	buf := FromBytesBuffer(&std)
+
+	buf.WriteString("Hello, World!")
+	if std.String() != "Hello, World!" {
+		t.Errorf("Write should go to the wrapped bytes.Buffer, got %q", std.String())
+	}
+
+	buf.Truncate(5)
+	if string(buf.Get()) != "Hello" {
+		t.Errorf("Truncate returned %q, expected %q", buf.Get(), "Hello")
+	}
+
+	clone := buf.Clone()
+	buf.Reset()
+	if string(clone.Get()) != "Hello" || buf.Len() != 0 {
+		t.Error("Clone should be independent of the wrapped buffer")
+	}
+}
+
+func TestFromByteBufferPool(t *testing.T) {
+	bb := &byteBuffer{}
+	buf := FromBytesBuffer(bb)
+
+	buf.WriteString("0123456789")
+	buf.Truncate(4)
+	if string(bb.B) != "0123" {
+		t.Errorf("Truncate without native support returned %q", bb.B)
+	}
+	if string(buf.Slice(1, 3).Get()) != "12" {
+		t.Errorf("Slice returned %q", buf.Slice(1, 3).Get())
+	}
+}
+
+func TestToBytesBuffer(t *testing.T) {
+	buf := NewBuffer()
+	buf.WriteString("payload")
+
+	std := ToBytesBuffer(buf)
+	if std.String() != "payload" {
+		t.Errorf("ToBytesBuffer returned %q, expected %q", std.String(), "payload")
+	}
+}
@@ -0,0 +1,111 @@
+package buffer
+
+// DefaultArenaSlabSize 是区域分配器默认的内存块大小
+const DefaultArenaSlabSize = 64 * 1024
+
+// arenaBlockSize 是区域分配器一次分配的缓冲区对象数量
+const arenaBlockSize = 16
+
+// Arena 定义区域分配器接口
+// 在一次处理过程中获取的所有缓冲区都从同一片内存区域切分，
+// 处理结束时通过Free()一次性释放，省去逐个池化缓冲区的开销
+//
+// Release对单个缓冲区是空操作；Free()之后，从区域获取的所有缓冲区都不能再使用
+type Arena interface {
+	ObjectPool[Buffer]
+
+	// Free 一次性释放区域内的所有缓冲区，保留内存块供下次使用
+	Free()
+}
+
+// arenaImpl 是Arena接口的具体实现
+type arenaImpl struct {
+	slabSize int
+	opts     options
+
+	slabs   [][]byte
+	slab    int // 当前内存块下标
+	offset  int // 当前内存块中已分配的字节数
+	buffers [][]bufferImpl
+	used    int // 已分配的缓冲区对象数量
+}
+
+// NewArena 创建一个区域分配器
+//   - slabSize: 每个内存块的大小，小于等于0时使用DefaultArenaSlabSize
+//   - opts: 缓冲区选项，WithInitialCap决定每个缓冲区从区域切分的容量
+//
+// 缓冲区写入超过切分的容量时会在堆上重新分配，不会覆盖相邻的缓冲区
+func NewArena(slabSize int, opts ...Option) Arena {
+	if slabSize <= 0 {
+		slabSize = DefaultArenaSlabSize
+	}
+	o := newOptions(opts)
+	if o.initialCap > slabSize {
+		o.initialCap = slabSize
+	}
+	return &arenaImpl{
+		slabSize: slabSize,
+		opts:     o,
+	}
+}
+
+// Acquire 从区域切分一个缓冲区
+func (a *arenaImpl) Acquire() Buffer {
+	n := a.opts.initialCap
+	if a.slab == len(a.slabs) || a.offset+n > len(a.slabs[a.slab]) {
+		a.nextSlab()
+	}
+	slab := a.slabs[a.slab]
+	data := slab[a.offset : a.offset : a.offset+n]
+	a.offset += n
+
+	buf := a.nextBuffer()
+	buf.data = data
+	buf.growth = a.opts.growth
+	buf.maxCap = a.opts.maxCap
+	return buf
+}
+
+// Release 对单个缓冲区是空操作，内存在Free()时统一回收
+func (a *arenaImpl) Release(buf Buffer) {}
+
+// Size 返回当前已从区域分配的缓冲区数量
+func (a *arenaImpl) Size() int {
+	return a.used
+}
+
+// Free 一次性释放区域内的所有缓冲区
+func (a *arenaImpl) Free() {
+	for i := 0; i < a.used; i++ {
+		buf := &a.buffers[i/arenaBlockSize][i%arenaBlockSize]
+		buf.data = nil
+		buf.digest.invalidate()
+		buf.off = 0
+		buf.mark = 0
+	}
+	a.used = 0
+	a.slab = 0
+	a.offset = 0
+}
+
+// nextSlab 切换到下一个内存块，必要时分配新的内存块
+func (a *arenaImpl) nextSlab() {
+	if a.slab < len(a.slabs) {
+		a.slab++
+	}
+	if a.slab == len(a.slabs) {
+		a.slabs = append(a.slabs, make([]byte, a.slabSize))
+	}
+	a.offset = 0
+}
+
+// nextBuffer 返回下一个可用的缓冲区对象，缓冲区对象按块批量分配
+func (a *arenaImpl) nextBuffer() *bufferImpl {
+	block := a.used / arenaBlockSize
+	if block == len(a.buffers) {
+		a.buffers = append(a.buffers, make([]bufferImpl, arenaBlockSize))
+	}
+	buf := &a.buffers[block][a.used%arenaBlockSize]
+	a.used++
+	return buf
+}
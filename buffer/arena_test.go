@@ -0,0 +1,89 @@
+package buffer
+
+import "testing"
+
+func TestArenaAcquire(t *testing.T) {
+	arena := NewArena(64, WithInitialCap(16))
+
+	a := arena.Acquire()
+	b := arena.Acquire()
+	a.WriteString("hello")
+	b.WriteString("world")
+
+	if string(a.Get()) != "hello" || string(b.Get()) != "world" {
+		t.Fatalf("Expected independent buffers, got %q and %q", a.Get(), b.Get())
+	}
+	if a.Cap() != 16 {
+		t.Errorf("Expected cap 16, got %d", a.Cap())
+	}
+	if arena.Size() != 2 {
+		t.Errorf("Expected size 2, got %d", arena.Size())
+	}
+}
+
+func TestArenaOverflowDoesNotClobberNeighbour(t *testing.T) {
+	arena := NewArena(64, WithInitialCap(4))
+
+	a := arena.Acquire()
+	b := arena.Acquire()
+	b.WriteString("BBBB")
+	a.WriteString("aaaaaaaa")
+
+	if string(a.Get()) != "aaaaaaaa" {
+		t.Errorf("Expected aaaaaaaa, got %q", a.Get())
+	}
+	if string(b.Get()) != "BBBB" {
+		t.Errorf("Expected neighbour to be untouched, got %q", b.Get())
+	}
+}
+
+func TestArenaNewSlab(t *testing.T) {
+	arena := NewArena(32, WithInitialCap(16))
+
+	bufs := make([]Buffer, 5)
+	for i := range bufs {
+		bufs[i] = arena.Acquire()
+		bufs[i].WriteString(string(rune('a' + i)))
+	}
+	for i, buf := range bufs {
+		if string(buf.Get()) != string(rune('a'+i)) {
+			t.Errorf("Buffer %d: expected %q, got %q", i, string(rune('a'+i)), buf.Get())
+		}
+	}
+}
+
+func TestArenaFree(t *testing.T) {
+	arena := NewArena(32, WithInitialCap(16))
+	for i := 0; i < 40; i++ {
+		arena.Acquire().WriteString("data")
+	}
+
+	impl := arena.(*arenaImpl)
+	slabs := len(impl.slabs)
+	arena.Free()
+
+	if arena.Size() != 0 {
+		t.Errorf("Expected size 0 after Free, got %d", arena.Size())
+	}
+
+	for i := 0; i < 40; i++ {
+		buf := arena.Acquire()
+		if buf.Len() != 0 {
+			t.Fatalf("Expected empty buffer after Free, got %q", buf.Get())
+		}
+	}
+	if len(impl.slabs) != slabs {
+		t.Errorf("Expected slabs to be reused, had %d now %d", slabs, len(impl.slabs))
+	}
+}
+
+func BenchmarkArenaAcquire(b *testing.B) {
+	arena := NewArena(0)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < 8; j++ {
+			arena.Acquire().WriteString("payload")
+		}
+		arena.Free()
+	}
+}
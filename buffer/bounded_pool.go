@@ -0,0 +1,129 @@
+package buffer
+
+import "sync/atomic"
+
+// boundedSlot 是有界对象池环形队列中的一个槽位
+type boundedSlot struct {
+	seq atomic.Uint64
+	buf Buffer
+}
+
+// boundedPoolImpl 是基于无锁MPMC环形队列的固定容量ObjectPool实现
+// 与sync.Pool不同，池中的缓冲区不会在GC时被回收，Size()返回确定的数量，
+// 适用于希望复用行为稳定的延迟敏感场景
+type boundedPoolImpl struct {
+	slots   []boundedSlot
+	mask    uint64
+	newFunc func() Buffer
+	poolCore
+
+	_          [64]byte
+	enqueuePos atomic.Uint64
+	_          [56]byte
+	dequeuePos atomic.Uint64
+	_          [56]byte
+}
+
+// NewBoundedPool 创建一个固定容量的无锁对象池
+//   - capacity: 池中最多保留的缓冲区数量，向上取整为2的幂
+//   - opts: 缓冲区和对象池选项
+//
+// 池满时归还的缓冲区会被丢弃，池空时Acquire会新建缓冲区
+func NewBoundedPool(capacity int, opts ...Option) ObjectPool[Buffer] {
+	o := newOptions(opts)
+	size := uint64(1)
+	for size < uint64(capacity) {
+		size <<= 1
+	}
+
+	p := &boundedPoolImpl{
+		slots: make([]boundedSlot, size),
+		mask:  size - 1,
+		newFunc: func() Buffer {
+			return newBufferWithOptions(&o)
+		},
+		poolCore: newPoolCore(&o),
+	}
+	for i := range p.slots {
+		p.slots[i].seq.Store(uint64(i))
+	}
+	return p
+}
+
+// Acquire 从队列取出缓冲区，队列为空时新建
+func (p *boundedPoolImpl) Acquire() Buffer {
+	if buf, ok := p.dequeue(); ok {
+		p.onAcquire(buf)
+		return buf
+	}
+	buf := p.newFunc()
+	p.onMiss(buf.Cap())
+	p.onAcquire(buf)
+	return buf
+}
+
+// Release 将缓冲区放回队列，队列已满时丢弃
+func (p *boundedPoolImpl) Release(buf Buffer) {
+	if buf == nil || !p.onRelease(buf) {
+		return
+	}
+	if !p.enqueue(buf) {
+		// 已经计入保留容量，丢弃时扣除
+		p.retainedBytes.Add(-int64(buf.Cap()))
+		p.drop(buf.Cap())
+	}
+}
+
+// Size 返回队列中的缓冲区数量
+func (p *boundedPoolImpl) Size() int {
+	size := int64(p.enqueuePos.Load()) - int64(p.dequeuePos.Load())
+	if size < 0 {
+		return 0
+	}
+	return int(size)
+}
+
+// enqueue 将缓冲区放入队列，队列已满时返回false
+func (p *boundedPoolImpl) enqueue(buf Buffer) bool {
+	pos := p.enqueuePos.Load()
+	for {
+		slot := &p.slots[pos&p.mask]
+		seq := slot.seq.Load()
+		switch diff := int64(seq) - int64(pos); {
+		case diff == 0:
+			if p.enqueuePos.CompareAndSwap(pos, pos+1) {
+				slot.buf = buf
+				slot.seq.Store(pos + 1)
+				return true
+			}
+			pos = p.enqueuePos.Load()
+		case diff < 0:
+			return false
+		default:
+			pos = p.enqueuePos.Load()
+		}
+	}
+}
+
+// dequeue 从队列取出缓冲区，队列为空时返回false
+func (p *boundedPoolImpl) dequeue() (Buffer, bool) {
+	pos := p.dequeuePos.Load()
+	for {
+		slot := &p.slots[pos&p.mask]
+		seq := slot.seq.Load()
+		switch diff := int64(seq) - int64(pos+1); {
+		case diff == 0:
+			if p.dequeuePos.CompareAndSwap(pos, pos+1) {
+				buf := slot.buf
+				slot.buf = nil
+				slot.seq.Store(pos + p.mask + 1)
+				return buf, true
+			}
+			pos = p.dequeuePos.Load()
+		case diff < 0:
+			return nil, false
+		default:
+			pos = p.dequeuePos.Load()
+		}
+	}
+}
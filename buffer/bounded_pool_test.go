@@ -0,0 +1,65 @@
+package buffer
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestBoundedPoolCapacity(t *testing.T) {
+	pool := NewBoundedPool(3)
+
+	buffers := make([]Buffer, 6)
+	for i := range buffers {
+		buffers[i] = pool.Acquire()
+	}
+	for _, buf := range buffers {
+		pool.Release(buf)
+	}
+
+	// 容量向上取整为4，多余的缓冲区被丢弃
+	if pool.Size() != 4 {
+		t.Errorf("Size returned %d, expected 4", pool.Size())
+	}
+	if stats := pool.(StatsProvider).Stats(); stats.Dropped != 2 {
+		t.Errorf("Stats returned %d dropped, expected 2", stats.Dropped)
+	}
+}
+
+func TestBoundedPoolSurvivesGC(t *testing.T) {
+	pool := NewBoundedPool(4)
+	buf := pool.Acquire()
+	pool.Release(buf)
+
+	runtime.GC()
+	runtime.GC()
+
+	if got := pool.Acquire(); got != buf {
+		t.Error("Bounded pool should keep buffers across GC cycles")
+	}
+	if pool.Size() != 0 {
+		t.Errorf("Size returned %d, expected 0", pool.Size())
+	}
+}
+
+func TestBoundedPoolConcurrentAccess(t *testing.T) {
+	pool := NewBoundedPool(16, WithDebug(true))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				buf := pool.Acquire()
+				buf.WriteString("payload")
+				pool.Release(buf)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if pool.Size() > 16 {
+		t.Errorf("Size returned %d, expected at most 16", pool.Size())
+	}
+}
@@ -2,7 +2,13 @@ package buffer
 
 // bufferImpl 是Buffer接口的具体实现
 type bufferImpl struct {
-	data []byte
+	data   []byte
+	digest digestCache
+	growth GrowthStrategy
+	maxCap int
+	off    int // 读位置
+	mark   int // Mark()记录的读位置
+	releaseState
 }
 
 // Get 获取底层字节数组的引用
@@ -24,7 +30,11 @@ func (b *bufferImpl) Cap() int {
 // 与标准库io.Writer接口兼容
 func (b *bufferImpl) Write(p []byte) (n int, err error) {
 	// 实现写入逻辑
+	if err := b.grow(len(p)); err != nil {
+		return 0, err
+	}
 	b.data = append(b.data, p...)
+	b.digest.invalidate()
 	return len(p), nil
 }
 
@@ -32,14 +42,46 @@ func (b *bufferImpl) Write(p []byte) (n int, err error) {
 // 与标准库io.StringWriter接口兼容
 func (b *bufferImpl) WriteString(s string) (n int, err error) {
 	// 实现写入字符串逻辑
+	if err := b.grow(len(s)); err != nil {
+		return 0, err
+	}
 	b.data = append(b.data, s...)
+	b.digest.invalidate()
 	return len(s), nil
 }
 
+// grow 按扩容策略确保还能写入n个字节
+// 未配置扩容策略和最大容量时交由append处理
+func (b *bufferImpl) grow(n int) error {
+	required := len(b.data) + n
+	if required <= cap(b.data) || (b.growth == nil && b.maxCap == 0) {
+		return nil
+	}
+	if b.maxCap > 0 && required > b.maxCap {
+		return ErrTooLarge
+	}
+
+	newCap := max(cap(b.data)*2, required)
+	if b.growth != nil {
+		newCap = max(b.growth(cap(b.data), required), required)
+	}
+	if b.maxCap > 0 && newCap > b.maxCap {
+		newCap = b.maxCap
+	}
+
+	data := make([]byte, len(b.data), newCap)
+	copy(data, b.data)
+	b.data = data
+	return nil
+}
+
 // Reset 重置缓冲区，保留底层数组但清空内容
 func (b *bufferImpl) Reset() {
 	// 实现重置逻辑
 	b.data = b.data[:0]
+	b.digest.invalidate()
+	b.off = 0
+	b.mark = 0
 }
 
 // Truncate 将缓冲区截断到指定长度
@@ -47,6 +89,8 @@ func (b *bufferImpl) Truncate(n int) {
 	// 实现截断逻辑
 	if n < len(b.data) {
 		b.data = b.data[:n]
+		b.digest.invalidate()
+		b.clampCursor()
 	}
 }
 
@@ -64,13 +108,24 @@ func (b *bufferImpl) Clone() Buffer {
 	clone := make([]byte, len(b.data))
 	copy(clone, b.data)
 	return &bufferImpl{
-		data: clone,
+		data:   clone,
+		growth: b.growth,
+		maxCap: b.maxCap,
 	}
 }
 
 // NewBuffer 创建一个新的Buffer实例
-func NewBuffer() Buffer {
+// 默认初始容量1024字节，可以通过选项配置初始容量、扩容策略和最大容量
+func NewBuffer(opts ...Option) Buffer {
+	o := newOptions(opts)
+	return newBufferWithOptions(&o)
+}
+
+// newBufferWithOptions 根据配置创建缓冲区
+func newBufferWithOptions(o *options) *bufferImpl {
 	return &bufferImpl{
-		data: make([]byte, 0, 1024), // 初始容量1024字节
+		data:   make([]byte, 0, o.initialCap),
+		growth: o.growth,
+		maxCap: o.maxCap,
 	}
 }
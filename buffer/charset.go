@@ -0,0 +1,105 @@
+package buffer
+
+import "unicode/utf8"
+
+// Encoding 表示检测到的文本编码
+type Encoding int
+
+const (
+	// EncodingUnknown 表示无法识别的编码或二进制数据
+	EncodingUnknown Encoding = iota
+	// EncodingUTF8 表示UTF-8编码（包括纯ASCII）
+	EncodingUTF8
+	// EncodingUTF16LE 表示小端序UTF-16编码
+	EncodingUTF16LE
+	// EncodingUTF16BE 表示大端序UTF-16编码
+	EncodingUTF16BE
+)
+
+// encodingSampleSize 是启发式检测时采样的最大字节数
+const encodingSampleSize = 4096
+
+// String 返回编码名称
+func (e Encoding) String() string {
+	switch e {
+	case EncodingUTF8:
+		return "UTF-8"
+	case EncodingUTF16LE:
+		return "UTF-16LE"
+	case EncodingUTF16BE:
+		return "UTF-16BE"
+	default:
+		return "unknown"
+	}
+}
+
+// DetectEncoding 根据BOM和启发式规则检测缓冲区的文本编码
+// 返回: 检测到的编码和BOM长度（没有BOM时为0），调用方可以据此跳过BOM
+//
+// 检测规则:
+// 1. 优先识别UTF-8、UTF-16LE和UTF-16BE的BOM
+// 2. 没有BOM时，根据零字节在奇偶位置上的分布识别UTF-16
+// 3. 其余情况下，合法的UTF-8数据识别为UTF-8，否则为未知
+func DetectEncoding(buf Readable) (Encoding, int) {
+	return detectEncoding(buf.Get())
+}
+
+// detectEncoding 检测字节数据的文本编码
+func detectEncoding(data []byte) (Encoding, int) {
+	switch {
+	case len(data) >= 3 && data[0] == 0xEF && data[1] == 0xBB && data[2] == 0xBF:
+		return EncodingUTF8, 3
+	case len(data) >= 2 && data[0] == 0xFF && data[1] == 0xFE:
+		return EncodingUTF16LE, 2
+	case len(data) >= 2 && data[0] == 0xFE && data[1] == 0xFF:
+		return EncodingUTF16BE, 2
+	}
+
+	sample := data
+	truncated := len(sample) > encodingSampleSize
+	if truncated {
+		sample = sample[:encodingSampleSize]
+	}
+
+	// 统计偶数和奇数位置上的零字节，ASCII范围的UTF-16文本会在一侧出现大量零字节
+	evenZeros, oddZeros := 0, 0
+	for i, c := range sample {
+		if c != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			evenZeros++
+		} else {
+			oddZeros++
+		}
+	}
+	pairs := len(sample) / 2
+	if pairs > 0 {
+		switch {
+		case oddZeros*4 >= pairs && evenZeros*8 < oddZeros:
+			return EncodingUTF16LE, 0
+		case evenZeros*4 >= pairs && oddZeros*8 < evenZeros:
+			return EncodingUTF16BE, 0
+		}
+	}
+
+	if evenZeros+oddZeros > 0 {
+		return EncodingUnknown, 0
+	}
+
+	// 采样被截断时，末尾可能是不完整的多字节字符
+	if truncated {
+		for i := len(sample) - 1; i >= 0 && i >= len(sample)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(sample[i]) {
+				if !utf8.FullRune(sample[i:]) {
+					sample = sample[:i]
+				}
+				break
+			}
+		}
+	}
+	if utf8.Valid(sample) {
+		return EncodingUTF8, 0
+	}
+	return EncodingUnknown, 0
+}
@@ -0,0 +1,46 @@
+package buffer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDetectEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		encoding Encoding
+		bomLen   int
+	}{
+		{"utf8 bom", []byte("\xEF\xBB\xBFhello"), EncodingUTF8, 3},
+		{"utf16le bom", []byte("\xFF\xFEh\x00i\x00"), EncodingUTF16LE, 2},
+		{"utf16be bom", []byte("\xFE\xFF\x00h\x00i"), EncodingUTF16BE, 2},
+		{"utf16le heuristic", []byte("h\x00e\x00l\x00l\x00o\x00"), EncodingUTF16LE, 0},
+		{"utf16be heuristic", []byte("\x00h\x00e\x00l\x00l\x00o"), EncodingUTF16BE, 0},
+		{"ascii", []byte("plain text"), EncodingUTF8, 0},
+		{"utf8", []byte("你好, world"), EncodingUTF8, 0},
+		{"binary", []byte{0x00, 0x00, 0xff, 0x13, 0x00, 0x00, 0x00, 0x00}, EncodingUnknown, 0},
+		{"invalid utf8", []byte{0xff, 0xfe - 1, 0xc3}, EncodingUnknown, 0},
+	}
+
+	for _, tt := range tests {
+		buf := NewBuffer()
+		buf.Write(tt.data)
+		encoding, bomLen := DetectEncoding(buf)
+		if encoding != tt.encoding || bomLen != tt.bomLen {
+			t.Errorf("%s: DetectEncoding returned (%v, %d), expected (%v, %d)",
+				tt.name, encoding, bomLen, tt.encoding, tt.bomLen)
+		}
+	}
+}
+
+func TestDetectEncodingTruncatedSample(t *testing.T) {
+	// 多字节字符跨越采样边界时不应被识别为非法UTF-8
+	data := append(bytes.Repeat([]byte("a"), encodingSampleSize-1), "你好"...)
+	buf := NewBuffer()
+	buf.Write(data)
+
+	if encoding, _ := DetectEncoding(buf); encoding != EncodingUTF8 {
+		t.Errorf("DetectEncoding returned %v, expected UTF-8", encoding)
+	}
+}
@@ -0,0 +1,201 @@
+package buffer
+
+// DefaultChunkSize 是分块缓冲区的默认块大小
+const DefaultChunkSize = 64 * 1024
+
+// ChunkedBuffer 定义分块缓冲区接口
+// 数据保存在一组固定大小的块中，写入大负载时无需重新分配连续内存
+type ChunkedBuffer interface {
+	Buffer
+
+	// Chunks 返回底层数据块列表（不复制数据）
+	Chunks() [][]byte
+}
+
+// chunkedBufferImpl 是ChunkedBuffer接口的具体实现
+type chunkedBufferImpl struct {
+	chunks    [][]byte
+	chunkSize int
+	length    int
+	// flat 是Get()延迟拼接的连续数据，写入后失效
+	flat      []byte
+	flatValid bool
+	releaseState
+}
+
+// NewChunkedBuffer 创建一个新的分块缓冲区
+//   - chunkSize: 每个数据块的大小，小于等于0时使用DefaultChunkSize
+func NewChunkedBuffer(chunkSize int) ChunkedBuffer {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	return &chunkedBufferImpl{
+		chunkSize: chunkSize,
+	}
+}
+
+// Get 获取连续的数据
+// 只有一个数据块时直接返回该块，否则延迟拼接并缓存结果
+// 注意：多块情况下修改返回的切片不会影响缓冲区内容
+func (b *chunkedBufferImpl) Get() []byte {
+	switch len(b.chunks) {
+	case 0:
+		return nil
+	case 1:
+		return b.chunks[0]
+	}
+
+	if !b.flatValid {
+		if cap(b.flat) < b.length {
+			b.flat = make([]byte, 0, b.length)
+		}
+		b.flat = b.flat[:0]
+		for _, chunk := range b.chunks {
+			b.flat = append(b.flat, chunk...)
+		}
+		b.flatValid = true
+	}
+	return b.flat
+}
+
+// Len 获取当前有效数据长度
+func (b *chunkedBufferImpl) Len() int {
+	return b.length
+}
+
+// Cap 获取所有数据块的总容量
+func (b *chunkedBufferImpl) Cap() int {
+	total := 0
+	for _, chunk := range b.chunks {
+		total += cap(chunk)
+	}
+	return total
+}
+
+// Write 写入数据，当前块写满后追加新的数据块
+func (b *chunkedBufferImpl) Write(p []byte) (n int, err error) {
+	n = len(p)
+	for len(p) > 0 {
+		last := b.writableChunk()
+		free := cap(*last) - len(*last)
+		if free > len(p) {
+			free = len(p)
+		}
+		*last = append(*last, p[:free]...)
+		p = p[free:]
+	}
+	b.length += n
+	b.flatValid = false
+	return n, nil
+}
+
+// WriteString 写入字符串，当前块写满后追加新的数据块
+func (b *chunkedBufferImpl) WriteString(s string) (n int, err error) {
+	n = len(s)
+	for len(s) > 0 {
+		last := b.writableChunk()
+		free := cap(*last) - len(*last)
+		if free > len(s) {
+			free = len(s)
+		}
+		*last = append(*last, s[:free]...)
+		s = s[free:]
+	}
+	b.length += n
+	b.flatValid = false
+	return n, nil
+}
+
+// writableChunk 返回仍有剩余空间的最后一个数据块，必要时分配新块
+func (b *chunkedBufferImpl) writableChunk() *[]byte {
+	if len(b.chunks) == 0 || len(b.chunks[len(b.chunks)-1]) == cap(b.chunks[len(b.chunks)-1]) {
+		b.chunks = append(b.chunks, make([]byte, 0, b.chunkSize))
+	}
+	return &b.chunks[len(b.chunks)-1]
+}
+
+// Reset 重置缓冲区并丢弃所有数据块，避免大负载长期占用内存
+// 数据块可能与Slice创建的缓冲区共享，保留其容量会让之后的写入覆盖共享的数据
+func (b *chunkedBufferImpl) Reset() {
+	clear(b.chunks)
+	b.chunks = b.chunks[:0]
+	b.length = 0
+	b.flat = nil
+	b.flatValid = false
+}
+
+// Truncate 将缓冲区截断到指定长度
+// 截断处的数据块同时截断容量，之后的写入分配新的数据块，不会覆盖共享的数据
+func (b *chunkedBufferImpl) Truncate(n int) {
+	if n < 0 || n >= b.length {
+		return
+	}
+	if n == 0 {
+		b.Reset()
+		return
+	}
+
+	remaining := n
+	for i, chunk := range b.chunks {
+		if remaining <= len(chunk) {
+			b.chunks[i] = chunk[:remaining:remaining]
+			for j := i + 1; j < len(b.chunks); j++ {
+				b.chunks[j] = nil
+			}
+			b.chunks = b.chunks[:i+1]
+			break
+		}
+		remaining -= len(chunk)
+	}
+	b.length = n
+	b.flatValid = false
+}
+
+// Slice 创建子切片但不复制数据
+// 返回的缓冲区共享原有数据块，对其写入会分配新的数据块而不会覆盖原数据
+func (b *chunkedBufferImpl) Slice(start, end int) Buffer {
+	if start < 0 || end > b.length || start > end {
+		panic("buffer: slice bounds out of range")
+	}
+
+	slice := &chunkedBufferImpl{
+		chunkSize: b.chunkSize,
+		length:    end - start,
+	}
+	offset := 0
+	for _, chunk := range b.chunks {
+		chunkStart, chunkEnd := offset, offset+len(chunk)
+		offset = chunkEnd
+		if chunkEnd <= start {
+			continue
+		}
+		if chunkStart >= end {
+			break
+		}
+		lo, hi := 0, len(chunk)
+		if start > chunkStart {
+			lo = start - chunkStart
+		}
+		if end < chunkEnd {
+			hi = end - chunkStart
+		}
+		slice.chunks = append(slice.chunks, chunk[lo:hi:hi])
+	}
+	return slice
+}
+
+// Clone 创建缓冲区的深拷贝
+func (b *chunkedBufferImpl) Clone() Buffer {
+	clone := &chunkedBufferImpl{
+		chunkSize: b.chunkSize,
+	}
+	for _, chunk := range b.chunks {
+		clone.Write(chunk)
+	}
+	return clone
+}
+
+// Chunks 返回底层数据块列表（不复制数据）
+func (b *chunkedBufferImpl) Chunks() [][]byte {
+	return b.chunks
+}
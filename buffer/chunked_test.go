@@ -0,0 +1,100 @@
+package buffer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestChunkedBufferWriteAndGet(t *testing.T) {
+	buf := NewChunkedBuffer(4)
+
+	buf.WriteString("Hello, ")
+	buf.Write([]byte("World!"))
+
+	if buf.Len() != 13 {
+		t.Errorf("Len returned %d, expected 13", buf.Len())
+	}
+	if len(buf.Chunks()) != 4 {
+		t.Errorf("Chunks returned %d chunks, expected 4", len(buf.Chunks()))
+	}
+	if string(buf.Get()) != "Hello, World!" {
+		t.Errorf("Get returned %q, expected %q", buf.Get(), "Hello, World!")
+	}
+
+	// 写入后重新拼接
+	buf.WriteString("!!")
+	if string(buf.Get()) != "Hello, World!!!" {
+		t.Errorf("Get after Write returned %q", buf.Get())
+	}
+}
+
+func TestChunkedBufferTruncateAndReset(t *testing.T) {
+	buf := NewChunkedBuffer(4)
+	buf.WriteString("0123456789")
+
+	buf.Truncate(6)
+	if string(buf.Get()) != "012345" {
+		t.Errorf("Truncate returned %q, expected %q", buf.Get(), "012345")
+	}
+
+	buf.WriteString("ab")
+	if string(buf.Get()) != "012345ab" {
+		t.Errorf("Write after Truncate returned %q", buf.Get())
+	}
+
+	buf.Reset()
+	if buf.Len() != 0 || len(buf.Get()) != 0 {
+		t.Error("Reset should clear the buffer")
+	}
+	if len(buf.Chunks()) != 0 {
+		t.Errorf("Reset should drop all chunks, got %d", len(buf.Chunks()))
+	}
+}
+
+func TestChunkedBufferSliceTruncateAndReset(t *testing.T) {
+	buf := NewChunkedBuffer(0)
+	buf.WriteString("0123456789")
+
+	// 截断或重置切片后写入不应覆盖原数据
+	slice := buf.Slice(0, 7)
+	slice.Truncate(5)
+	slice.WriteString("XX")
+	if string(slice.Get()) != "01234XX" {
+		t.Errorf("Write after Truncate returned %q", slice.Get())
+	}
+	if string(buf.Get()) != "0123456789" {
+		t.Errorf("Writing to truncated slice modified parent: %q", buf.Get())
+	}
+
+	slice = buf.Slice(2, 8)
+	slice.Reset()
+	slice.WriteString("YY")
+	if string(slice.Get()) != "YY" {
+		t.Errorf("Write after Reset returned %q", slice.Get())
+	}
+	if string(buf.Get()) != "0123456789" {
+		t.Errorf("Writing to reset slice modified parent: %q", buf.Get())
+	}
+}
+
+func TestChunkedBufferSliceAndClone(t *testing.T) {
+	buf := NewChunkedBuffer(4)
+	buf.WriteString("0123456789")
+
+	slice := buf.Slice(3, 9)
+	if string(slice.Get()) != "345678" {
+		t.Errorf("Slice returned %q, expected %q", slice.Get(), "345678")
+	}
+
+	// 对切片写入不应覆盖原数据
+	slice.WriteString("XX")
+	if string(buf.Get()) != "0123456789" {
+		t.Errorf("Writing to slice modified parent: %q", buf.Get())
+	}
+
+	clone := buf.Clone()
+	buf.Truncate(0)
+	if !bytes.Equal(clone.Get(), []byte("0123456789")) {
+		t.Errorf("Clone returned %q", clone.Get())
+	}
+}
@@ -0,0 +1,86 @@
+package buffer
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// EncodeHex 将缓冲区内容编码为十六进制，结果写入从对象池获取的新缓冲区
+// 结果超过缓冲区最大容量时返回ErrTooLarge，结果缓冲区会被归还到对象池
+//   - pool: 结果缓冲区的来源，为nil时使用NewBuffer()
+//   - src: 源数据
+func EncodeHex(pool ObjectPool[Buffer], src Readable) (Buffer, error) {
+	return transcode(pool, func(b []byte) ([]byte, error) {
+		return hex.AppendEncode(b, src.Get()), nil
+	})
+}
+
+// DecodeHex 将十六进制内容解码，结果写入从对象池获取的新缓冲区
+// 解码失败或结果超过缓冲区最大容量时结果缓冲区会被归还到对象池
+func DecodeHex(pool ObjectPool[Buffer], src Readable) (Buffer, error) {
+	return transcode(pool, func(b []byte) ([]byte, error) {
+		return hex.AppendDecode(b, src.Get())
+	})
+}
+
+// EncodeBase64 将缓冲区内容编码为标准Base64，结果写入从对象池获取的新缓冲区
+// 结果超过缓冲区最大容量时返回ErrTooLarge，结果缓冲区会被归还到对象池
+func EncodeBase64(pool ObjectPool[Buffer], src Readable) (Buffer, error) {
+	return transcode(pool, func(b []byte) ([]byte, error) {
+		return base64.StdEncoding.AppendEncode(b, src.Get()), nil
+	})
+}
+
+// DecodeBase64 将标准Base64内容解码，结果写入从对象池获取的新缓冲区
+// 解码失败或结果超过缓冲区最大容量时结果缓冲区会被归还到对象池
+func DecodeBase64(pool ObjectPool[Buffer], src Readable) (Buffer, error) {
+	return transcode(pool, func(b []byte) ([]byte, error) {
+		return base64.StdEncoding.AppendDecode(b, src.Get())
+	})
+}
+
+// transcode 从对象池获取缓冲区并写入fn追加的结果，出错时归还缓冲区
+func transcode(pool ObjectPool[Buffer], fn func([]byte) ([]byte, error)) (Buffer, error) {
+	dst := acquireFrom(pool)
+	if err := appendTo(dst, fn); err != nil {
+		releaseTo(pool, dst)
+		return nil, err
+	}
+	return dst, nil
+}
+
+// acquireFrom 从对象池获取缓冲区，pool为nil时创建新缓冲区
+func acquireFrom(pool ObjectPool[Buffer]) Buffer {
+	if pool == nil {
+		return NewBuffer()
+	}
+	return pool.Acquire()
+}
+
+// releaseTo 将缓冲区归还对象池，pool为nil时忽略
+func releaseTo(pool ObjectPool[Buffer], buf Buffer) {
+	if pool != nil {
+		pool.Release(buf)
+	}
+}
+
+// appendTo 以追加方式写入缓冲区
+// 对于未配置扩容限制的内置实现直接追加到底层切片，避免中间分配；
+// 其他情况通过Write写入，返回fn或Write的错误（例如超过最大容量时的ErrTooLarge）
+func appendTo(dst Buffer, fn func([]byte) ([]byte, error)) error {
+	if impl, ok := dst.(*bufferImpl); ok && impl.growth == nil && impl.maxCap == 0 {
+		data, err := fn(impl.data)
+		if err != nil {
+			return err
+		}
+		impl.data = data
+		impl.digest.invalidate()
+		return nil
+	}
+	data, err := fn(nil)
+	if err != nil {
+		return err
+	}
+	_, err = dst.Write(data)
+	return err
+}
@@ -0,0 +1,97 @@
+package buffer
+
+import (
+	"testing"
+)
+
+func TestHexRoundTrip(t *testing.T) {
+	pool := NewPool()
+	src := NewBuffer()
+	src.Write([]byte{0x01, 0xab, 0xff})
+
+	encoded, err := EncodeHex(pool, src)
+	if err != nil {
+		t.Fatalf("EncodeHex returned error: %v", err)
+	}
+	if string(encoded.Get()) != "01abff" {
+		t.Errorf("EncodeHex returned %q, expected %q", encoded.Get(), "01abff")
+	}
+
+	decoded, err := DecodeHex(pool, encoded)
+	if err != nil {
+		t.Fatalf("DecodeHex returned error: %v", err)
+	}
+	if string(decoded.Get()) != string(src.Get()) {
+		t.Errorf("DecodeHex returned %x, expected %x", decoded.Get(), src.Get())
+	}
+
+	invalid := NewBuffer()
+	invalid.WriteString("zz")
+	if _, err := DecodeHex(pool, invalid); err == nil {
+		t.Error("DecodeHex should fail on invalid input")
+	}
+}
+
+func TestBase64RoundTrip(t *testing.T) {
+	src := NewBuffer()
+	src.WriteString("device-frame")
+
+	// pool为nil时使用新缓冲区
+	encoded, err := EncodeBase64(nil, src)
+	if err != nil {
+		t.Fatalf("EncodeBase64 returned error: %v", err)
+	}
+	if string(encoded.Get()) != "ZGV2aWNlLWZyYW1l" {
+		t.Errorf("EncodeBase64 returned %q", encoded.Get())
+	}
+
+	decoded, err := DecodeBase64(nil, encoded)
+	if err != nil {
+		t.Fatalf("DecodeBase64 returned error: %v", err)
+	}
+	if string(decoded.Get()) != "device-frame" {
+		t.Errorf("DecodeBase64 returned %q", decoded.Get())
+	}
+
+	invalid := NewBuffer()
+	invalid.WriteString("!!!")
+	if _, err := DecodeBase64(nil, invalid); err == nil {
+		t.Error("DecodeBase64 should fail on invalid input")
+	}
+}
+
+func TestCodecMaxCap(t *testing.T) {
+	pool := NewPool(WithMaxCap(8))
+	src := NewBuffer()
+	src.WriteString("0123456789")
+
+	// 超过最大容量时返回ErrTooLarge，而不是返回空的结果
+	if _, err := EncodeHex(pool, src); err != ErrTooLarge {
+		t.Errorf("EncodeHex returned %v, expected ErrTooLarge", err)
+	}
+	if _, err := EncodeBase64(pool, src); err != ErrTooLarge {
+		t.Errorf("EncodeBase64 returned %v, expected ErrTooLarge", err)
+	}
+
+	hexSrc := NewBuffer()
+	hexSrc.WriteString("000102030405060708090a")
+	if _, err := DecodeHex(pool, hexSrc); err != ErrTooLarge {
+		t.Errorf("DecodeHex returned %v, expected ErrTooLarge", err)
+	}
+	b64Src := NewBuffer()
+	b64Src.WriteString("MDEyMzQ1Njc4OQ==")
+	if _, err := DecodeBase64(pool, b64Src); err != ErrTooLarge {
+		t.Errorf("DecodeBase64 returned %v, expected ErrTooLarge", err)
+	}
+
+	// 未超过最大容量时正常写入
+	small := NewBuffer()
+	small.WriteString("ab")
+	encoded, err := EncodeHex(pool, small)
+	if err != nil {
+		t.Fatalf("EncodeHex returned error: %v", err)
+	}
+	if string(encoded.Get()) != "6162" {
+		t.Errorf("EncodeHex returned %q, expected %q", encoded.Get(), "6162")
+	}
+}
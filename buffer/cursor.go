@@ -0,0 +1,73 @@
+package buffer
+
+import "io"
+
+// Cursor 定义带读位置的缓冲区接口
+// 读位置独立于缓冲区内容，Get()仍然返回全部数据
+//
+// 推测式解析器可以先Mark()，尝试按一种格式解码，失败后ResetToMark()回退，
+// 再尝试另一种格式，整个过程无需克隆缓冲区
+type Cursor interface {
+	io.Reader
+	io.ByteReader
+
+	// Remaining 返回读位置之后尚未读取的数据（不复制数据）
+	Remaining() []byte
+
+	// Mark 记录当前读位置
+	Mark()
+
+	// ResetToMark 将读位置恢复到最近一次Mark()记录的位置，未调用过Mark()时回到开头
+	ResetToMark()
+}
+
+// Read 从读位置开始读取数据
+func (b *bufferImpl) Read(p []byte) (n int, err error) {
+	if b.off >= len(b.data) {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	n = copy(p, b.data[b.off:])
+	b.off += n
+	return n, nil
+}
+
+// ReadByte 从读位置读取一个字节
+func (b *bufferImpl) ReadByte() (byte, error) {
+	if b.off >= len(b.data) {
+		return 0, io.EOF
+	}
+	c := b.data[b.off]
+	b.off++
+	return c, nil
+}
+
+// Remaining 返回尚未读取的数据
+func (b *bufferImpl) Remaining() []byte {
+	if b.off >= len(b.data) {
+		return nil
+	}
+	return b.data[b.off:]
+}
+
+// Mark 记录当前读位置
+func (b *bufferImpl) Mark() {
+	b.mark = b.off
+}
+
+// ResetToMark 将读位置恢复到标记位置
+func (b *bufferImpl) ResetToMark() {
+	b.off = b.mark
+}
+
+// clampCursor 在数据被截断后修正读位置和标记位置
+func (b *bufferImpl) clampCursor() {
+	if b.off > len(b.data) {
+		b.off = len(b.data)
+	}
+	if b.mark > len(b.data) {
+		b.mark = len(b.data)
+	}
+}
@@ -0,0 +1,67 @@
+package buffer
+
+import (
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+func TestCursorRead(t *testing.T) {
+	buf := NewBuffer()
+	buf.WriteString("abcdef")
+	cur := buf.(Cursor)
+
+	p := make([]byte, 2)
+	cur.Read(p)
+	if string(p) != "ab" {
+		t.Errorf("Read returned %q, expected %q", p, "ab")
+	}
+	if c, _ := cur.ReadByte(); c != 'c' {
+		t.Errorf("ReadByte returned %q, expected 'c'", c)
+	}
+	if string(cur.Remaining()) != "def" {
+		t.Errorf("Remaining returned %q, expected %q", cur.Remaining(), "def")
+	}
+	if string(buf.Get()) != "abcdef" {
+		t.Error("Reading should not change Get()")
+	}
+
+	rest, _ := io.ReadAll(cur)
+	if string(rest) != "def" {
+		t.Errorf("ReadAll returned %q", rest)
+	}
+	if _, err := cur.ReadByte(); err != io.EOF {
+		t.Errorf("ReadByte at end returned %v, expected io.EOF", err)
+	}
+}
+
+func TestCursorMarkReset(t *testing.T) {
+	buf := NewBuffer()
+	buf.Write([]byte{0x00, 0x05, 'h', 'e', 'l', 'l', 'o'})
+	cur := buf.(Cursor)
+
+	// 尝试按固定头部解析，失败后回退
+	cur.Mark()
+	var header uint32
+	if err := binary.Read(cur, binary.BigEndian, &header); err != nil {
+		t.Fatalf("binary.Read failed: %v", err)
+	}
+	cur.ResetToMark()
+
+	var length uint16
+	binary.Read(cur, binary.BigEndian, &length)
+	if length != 5 || string(cur.Remaining()) != "hello" {
+		t.Errorf("Reading after ResetToMark returned length %d, remaining %q", length, cur.Remaining())
+	}
+
+	buf.Truncate(1)
+	if len(cur.Remaining()) != 0 {
+		t.Error("Truncate should clamp the read position")
+	}
+
+	buf.Reset()
+	buf.WriteString("xy")
+	if string(cur.Remaining()) != "xy" {
+		t.Error("Reset should rewind the read position")
+	}
+}
@@ -0,0 +1,102 @@
+package buffer
+
+import (
+	"crypto/sha256"
+	"hash/crc32"
+	"hash/fnv"
+)
+
+// Digester 定义带缓存的摘要计算接口
+// 摘要在首次调用时计算并缓存，缓冲区通过Write、Truncate、Reset等方法修改后失效
+//
+// 注意：直接修改Get()返回的切片不会使缓存失效
+type Digester interface {
+	// CRC32 返回数据的CRC-32（IEEE）校验和
+	CRC32() uint32
+
+	// SHA256 返回数据的SHA-256摘要
+	SHA256() [sha256.Size]byte
+
+	// Hash64 返回数据的64位FNV-1a哈希，适用于去重和缓存键
+	Hash64() uint64
+}
+
+const (
+	digestCRC32 uint8 = 1 << iota
+	digestSHA256
+	digestHash64
+)
+
+// digestCache 缓存已计算的摘要
+type digestCache struct {
+	valid  uint8
+	crc32  uint32
+	hash64 uint64
+	sha256 [sha256.Size]byte
+}
+
+// invalidate 使所有缓存的摘要失效
+func (d *digestCache) invalidate() {
+	d.valid = 0
+}
+
+// CRC32 返回数据的CRC-32校验和
+func (b *bufferImpl) CRC32() uint32 {
+	if b.digest.valid&digestCRC32 == 0 {
+		b.digest.crc32 = crc32.ChecksumIEEE(b.data)
+		b.digest.valid |= digestCRC32
+	}
+	return b.digest.crc32
+}
+
+// SHA256 返回数据的SHA-256摘要
+func (b *bufferImpl) SHA256() [sha256.Size]byte {
+	if b.digest.valid&digestSHA256 == 0 {
+		b.digest.sha256 = sha256.Sum256(b.data)
+		b.digest.valid |= digestSHA256
+	}
+	return b.digest.sha256
+}
+
+// Hash64 返回数据的64位FNV-1a哈希
+func (b *bufferImpl) Hash64() uint64 {
+	if b.digest.valid&digestHash64 == 0 {
+		b.digest.hash64 = hash64(b.data)
+		b.digest.valid |= digestHash64
+	}
+	return b.digest.hash64
+}
+
+// CRC32 返回缓冲区数据的CRC-32校验和
+// 如果缓冲区实现了Digester接口则使用其缓存结果
+func CRC32(buf Readable) uint32 {
+	if d, ok := buf.(Digester); ok {
+		return d.CRC32()
+	}
+	return crc32.ChecksumIEEE(buf.Get())
+}
+
+// SHA256 返回缓冲区数据的SHA-256摘要
+// 如果缓冲区实现了Digester接口则使用其缓存结果
+func SHA256(buf Readable) [sha256.Size]byte {
+	if d, ok := buf.(Digester); ok {
+		return d.SHA256()
+	}
+	return sha256.Sum256(buf.Get())
+}
+
+// Hash64 返回缓冲区数据的64位FNV-1a哈希
+// 如果缓冲区实现了Digester接口则使用其缓存结果
+func Hash64(buf Readable) uint64 {
+	if d, ok := buf.(Digester); ok {
+		return d.Hash64()
+	}
+	return hash64(buf.Get())
+}
+
+// hash64 计算64位FNV-1a哈希
+func hash64(data []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(data)
+	return h.Sum64()
+}
@@ -0,0 +1,60 @@
+package buffer
+
+import (
+	"crypto/sha256"
+	"hash/crc32"
+	"testing"
+)
+
+func TestDigestCaching(t *testing.T) {
+	buf := NewBuffer()
+	buf.WriteString("payload")
+
+	d, ok := buf.(Digester)
+	if !ok {
+		t.Fatal("bufferImpl should implement Digester")
+	}
+
+	if d.CRC32() != crc32.ChecksumIEEE([]byte("payload")) {
+		t.Error("CRC32 returned an unexpected checksum")
+	}
+	if d.SHA256() != sha256.Sum256([]byte("payload")) {
+		t.Error("SHA256 returned an unexpected digest")
+	}
+	first := d.Hash64()
+	if first != d.Hash64() {
+		t.Error("Hash64 should be stable")
+	}
+
+	// 修改后缓存应失效
+	buf.WriteString("!")
+	if d.CRC32() != crc32.ChecksumIEEE([]byte("payload!")) {
+		t.Error("CRC32 should be recomputed after Write")
+	}
+	if d.Hash64() == first {
+		t.Error("Hash64 should be recomputed after Write")
+	}
+
+	buf.Truncate(7)
+	if d.Hash64() != first {
+		t.Error("Hash64 should match after truncating back to the original data")
+	}
+}
+
+func TestDigestHelpers(t *testing.T) {
+	buf := NewChunkedBuffer(4)
+	buf.WriteString("payload")
+
+	if CRC32(buf) != crc32.ChecksumIEEE([]byte("payload")) {
+		t.Error("CRC32 helper returned an unexpected checksum")
+	}
+	if SHA256(buf) != sha256.Sum256([]byte("payload")) {
+		t.Error("SHA256 helper returned an unexpected digest")
+	}
+
+	plain := NewBuffer()
+	plain.WriteString("payload")
+	if Hash64(buf) != Hash64(plain) {
+		t.Error("Hash64 helper should not depend on the buffer implementation")
+	}
+}
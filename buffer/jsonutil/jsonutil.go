@@ -0,0 +1,229 @@
+// Package jsonutil 提供轻量级的JSON字段访问工具
+// 只扫描到目标字段为止，不做完整的反序列化，供匹配器和处理器共享使用
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+// Get 提取JSON中指定路径字段的原始字节（不复制数据）
+//   - data: JSON数据
+//   - path: 以"."分隔的字段路径，数组元素使用下标，例如"order.items.0.sku"；空路径返回整个值
+//
+// 返回: 字段的原始字节（字符串包含引号）以及是否找到
+func Get(data []byte, path string) ([]byte, bool) {
+	i := skipSpace(data, 0)
+	for path != "" {
+		var segment string
+		segment, path, _ = strings.Cut(path, ".")
+		if i >= len(data) {
+			return nil, false
+		}
+
+		var ok bool
+		switch data[i] {
+		case '{':
+			i, ok = findKey(data, i, segment)
+		case '[':
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 {
+				return nil, false
+			}
+			i, ok = findIndex(data, i, index)
+		default:
+			return nil, false
+		}
+		if !ok {
+			return nil, false
+		}
+	}
+
+	if i >= len(data) {
+		return nil, false
+	}
+	end, ok := skipValue(data, i)
+	if !ok {
+		return nil, false
+	}
+	return data[i:end], true
+}
+
+// GetField 提取缓冲区中JSON字段的原始字节
+func GetField(buf buffer.Readable, path string) ([]byte, bool) {
+	return Get(buf.Get(), path)
+}
+
+// GetString 提取JSON中字符串字段的值
+// 字段不存在或不是字符串时返回false
+func GetString(data []byte, path string) (string, bool) {
+	raw, ok := Get(data, path)
+	if !ok {
+		return "", false
+	}
+	return Unquote(raw)
+}
+
+// Unquote 将JSON字符串的原始字节解码为字符串
+// 不包含转义字符时不经过encoding/json
+func Unquote(raw []byte) (string, bool) {
+	if len(raw) < 2 || raw[0] != '"' || raw[len(raw)-1] != '"' {
+		return "", false
+	}
+	if bytes.IndexByte(raw, '\\') < 0 {
+		return string(raw[1 : len(raw)-1]), true
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", false
+	}
+	return s, true
+}
+
+// findKey 在对象中查找键，返回对应值的起始位置
+func findKey(data []byte, i int, key string) (int, bool) {
+	i++ // 跳过'{'
+	for {
+		i = skipSpace(data, i)
+		if i >= len(data) || data[i] != '"' {
+			return 0, false
+		}
+		keyEnd, ok := scanString(data, i)
+		if !ok {
+			return 0, false
+		}
+		rawKey := data[i+1 : keyEnd-1]
+
+		i = skipSpace(data, keyEnd)
+		if i >= len(data) || data[i] != ':' {
+			return 0, false
+		}
+		i = skipSpace(data, i+1)
+		if keyEquals(rawKey, key) {
+			return i, i < len(data)
+		}
+
+		if i, ok = nextElement(data, i); !ok {
+			return 0, false
+		}
+	}
+}
+
+// findIndex 在数组中查找指定下标的元素，返回其起始位置
+func findIndex(data []byte, i int, index int) (int, bool) {
+	i = skipSpace(data, i+1) // 跳过'['
+	if i >= len(data) || data[i] == ']' {
+		return 0, false
+	}
+	for n := 0; ; n++ {
+		if n == index {
+			return i, true
+		}
+		var ok bool
+		if i, ok = nextElement(data, i); !ok {
+			return 0, false
+		}
+		i = skipSpace(data, i)
+	}
+}
+
+// nextElement 跳过当前值和后面的逗号，返回下一个元素的位置
+// 遇到容器结束符时返回false
+func nextElement(data []byte, i int) (int, bool) {
+	end, ok := skipValue(data, i)
+	if !ok {
+		return 0, false
+	}
+	i = skipSpace(data, end)
+	if i >= len(data) || data[i] != ',' {
+		return 0, false
+	}
+	return i + 1, true
+}
+
+// skipValue 跳过一个完整的JSON值，返回其结束位置
+func skipValue(data []byte, i int) (int, bool) {
+	if i >= len(data) {
+		return 0, false
+	}
+	switch data[i] {
+	case '"':
+		return scanString(data, i)
+	case '{', '[':
+		depth := 0
+		for j := i; j < len(data); j++ {
+			switch data[j] {
+			case '"':
+				end, ok := scanString(data, j)
+				if !ok {
+					return 0, false
+				}
+				j = end - 1
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					return j + 1, true
+				}
+			}
+		}
+		return 0, false
+	default:
+		j := i
+		for j < len(data) && !isDelimiter(data[j]) {
+			j++
+		}
+		return j, j > i
+	}
+}
+
+// scanString 扫描从i开始的字符串，返回结束引号之后的位置
+func scanString(data []byte, i int) (int, bool) {
+	for j := i + 1; j < len(data); j++ {
+		switch data[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1, true
+		}
+	}
+	return 0, false
+}
+
+// keyEquals 比较原始键与目标键，仅在包含转义字符时解码
+func keyEquals(rawKey []byte, key string) bool {
+	if bytes.IndexByte(rawKey, '\\') < 0 {
+		return string(rawKey) == key
+	}
+	var s string
+	quoted := make([]byte, 0, len(rawKey)+2)
+	quoted = append(append(append(quoted, '"'), rawKey...), '"')
+	return json.Unmarshal(quoted, &s) == nil && s == key
+}
+
+// skipSpace 跳过空白字符
+func skipSpace(data []byte, i int) int {
+	for i < len(data) {
+		switch data[i] {
+		case ' ', '\t', '\n', '\r':
+			i++
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// isDelimiter 判断是否为值的结束符
+func isDelimiter(c byte) bool {
+	switch c {
+	case ',', '}', ']', ' ', '\t', '\n', '\r':
+		return true
+	}
+	return false
+}
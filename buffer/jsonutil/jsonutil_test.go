@@ -0,0 +1,77 @@
+package jsonutil
+
+import (
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+const sample = `{
+	"type": "order.created",
+	"meta": {"id": 42, "tags": ["a", "b\"c"], "ok": true},
+	"items": [{"sku": "A-1"}, {"sku": "B-2", "qty": 3}],
+	"escaped": null
+}`
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		path  string
+		value string
+		found bool
+	}{
+		{"type", `"order.created"`, true},
+		{"meta.id", `42`, true},
+		{"meta.tags.1", `"b\"c"`, true},
+		{"meta.ok", `true`, true},
+		{"items.1.qty", `3`, true},
+		{"items.1", `{"sku": "B-2", "qty": 3}`, true},
+		{"escaped", `null`, true},
+		{"missing", ``, false},
+		{"items.2", ``, false},
+		{"type.sub", ``, false},
+		{"items.x", ``, false},
+	}
+
+	for _, tt := range tests {
+		value, found := Get([]byte(sample), tt.path)
+		if found != tt.found || string(value) != tt.value {
+			t.Errorf("Get(%q) returned (%q, %v), expected (%q, %v)", tt.path, value, found, tt.value, tt.found)
+		}
+	}
+}
+
+func TestGetString(t *testing.T) {
+	if s, ok := GetString([]byte(sample), "items.0.sku"); !ok || s != "A-1" {
+		t.Errorf("GetString returned (%q, %v), expected (%q, true)", s, ok, "A-1")
+	}
+	if s, ok := GetString([]byte(sample), "meta.tags.1"); !ok || s != `b"c` {
+		t.Errorf("GetString with escapes returned (%q, %v)", s, ok)
+	}
+	if _, ok := GetString([]byte(sample), "meta.id"); ok {
+		t.Error("GetString should fail for non-string values")
+	}
+}
+
+func TestGetFieldMalformed(t *testing.T) {
+	buf := buffer.NewBuffer()
+	buf.WriteString(`{"type": "x", "broken": [1, 2`)
+
+	if _, ok := GetField(buf, "broken.5"); ok {
+		t.Error("GetField should fail on truncated input")
+	}
+	if value, ok := GetField(buf, "type"); !ok || string(value) != `"x"` {
+		t.Errorf("GetField returned (%q, %v) before the malformed part", value, ok)
+	}
+}
+
+func TestGetTruncatedValue(t *testing.T) {
+	// 键后面的值缺失时不能越界
+	for _, input := range []string{`{"order":{"":`, `{"a": `, `{"a":1,"b":`, `[1,`} {
+		if _, ok := Get([]byte(input), "order.type"); ok {
+			t.Errorf("Get(%q) should fail", input)
+		}
+		if _, ok := Get([]byte(input), "1"); ok {
+			t.Errorf("Get(%q, \"1\") should fail", input)
+		}
+	}
+}
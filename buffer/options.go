@@ -0,0 +1,106 @@
+package buffer
+
+import "errors"
+
+// DefaultInitialCap 是新建缓冲区的默认初始容量
+const DefaultInitialCap = 1024
+
+// ErrTooLarge 表示写入会使缓冲区超过最大容量限制
+var ErrTooLarge = errors.New("buffer: write exceeds max capacity")
+
+// GrowthStrategy 定义缓冲区扩容策略
+//   - currentCap: 当前容量
+//   - required: 写入后所需的最小容量
+//
+// 返回: 新的容量，小于required时按required处理
+type GrowthStrategy func(currentCap, required int) int
+
+// GrowDouble 返回倍增扩容策略
+func GrowDouble() GrowthStrategy {
+	return func(currentCap, required int) int {
+		return max(currentCap*2, required)
+	}
+}
+
+// GrowFactor 返回按比例扩容的策略，例如1.25表示每次扩容25%
+func GrowFactor(factor float64) GrowthStrategy {
+	if factor <= 1 {
+		panic("buffer: growth factor must be greater than 1")
+	}
+	return func(currentCap, required int) int {
+		return max(int(float64(currentCap)*factor), required)
+	}
+}
+
+// GrowFixed 返回按固定增量扩容的策略
+// 新容量为满足所需容量的最小的step整数倍
+func GrowFixed(step int) GrowthStrategy {
+	if step <= 0 {
+		panic("buffer: growth step must be positive")
+	}
+	return func(currentCap, required int) int {
+		return (required + step - 1) / step * step
+	}
+}
+
+// Option 定义缓冲区和对象池的配置选项
+type Option func(*options)
+
+// options 保存配置选项
+type options struct {
+	initialCap int
+	growth     GrowthStrategy
+	maxCap     int
+	// 以下选项只作用于对象池
+	maxRetainedCap int
+	observer       PoolObserver
+	debug          bool
+	shards         int
+}
+
+// WithInitialCap 设置新建缓冲区的初始容量
+func WithInitialCap(n int) Option {
+	return func(o *options) {
+		o.initialCap = n
+	}
+}
+
+// WithGrowth 设置缓冲区扩容策略，默认使用append的内置扩容行为
+func WithGrowth(strategy GrowthStrategy) Option {
+	return func(o *options) {
+		o.growth = strategy
+	}
+}
+
+// WithMaxCap 设置缓冲区最大容量，超过时写入返回ErrTooLarge
+// 0表示不限制
+func WithMaxCap(n int) Option {
+	return func(o *options) {
+		o.maxCap = n
+	}
+}
+
+// WithMaxRetainedCap 设置对象池保留缓冲区的最大容量
+// 归还时容量超过该值的缓冲区会被丢弃而不是放回池中，
+// 避免偶发的大负载在sync.Pool中长期占用内存。0表示不限制
+//
+// 该选项只作用于对象池
+func WithMaxRetainedCap(n int) Option {
+	return func(o *options) {
+		o.maxRetainedCap = n
+	}
+}
+
+// newOptions 应用配置选项
+func newOptions(opts []Option) options {
+	o := options{
+		initialCap: DefaultInitialCap,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.maxCap > 0 && o.initialCap > o.maxCap {
+		o.initialCap = o.maxCap
+	}
+	return o
+}
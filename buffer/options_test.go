@@ -0,0 +1,61 @@
+package buffer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestGrowthStrategies(t *testing.T) {
+	if got := GrowDouble()(1024, 1500); got != 2048 {
+		t.Errorf("GrowDouble returned %d, expected 2048", got)
+	}
+	if got := GrowFactor(1.25)(1024, 1100); got != 1280 {
+		t.Errorf("GrowFactor returned %d, expected 1280", got)
+	}
+	if got := GrowFixed(4096)(1024, 5000); got != 8192 {
+		t.Errorf("GrowFixed returned %d, expected 8192", got)
+	}
+}
+
+func TestNewBufferWithOptions(t *testing.T) {
+	buf := NewBuffer(WithInitialCap(16), WithGrowth(GrowFixed(16)))
+	if buf.Cap() != 16 {
+		t.Errorf("Cap returned %d, expected 16", buf.Cap())
+	}
+
+	buf.Write(bytes.Repeat([]byte("a"), 20))
+	if buf.Cap() != 32 {
+		t.Errorf("Cap after growth returned %d, expected 32", buf.Cap())
+	}
+	if buf.Len() != 20 {
+		t.Errorf("Len returned %d, expected 20", buf.Len())
+	}
+}
+
+func TestBufferMaxCap(t *testing.T) {
+	buf := NewBuffer(WithInitialCap(8), WithMaxCap(12))
+
+	if _, err := buf.WriteString("0123456789"); err != nil {
+		t.Fatalf("Write within max capacity failed: %v", err)
+	}
+	if buf.Cap() != 12 {
+		t.Errorf("Cap should be clamped to max capacity, got %d", buf.Cap())
+	}
+
+	n, err := buf.WriteString("abc")
+	if err != ErrTooLarge || n != 0 {
+		t.Errorf("Write beyond max capacity returned (%d, %v), expected (0, ErrTooLarge)", n, err)
+	}
+	if buf.Len() != 10 {
+		t.Errorf("Failed write should not change Len, got %d", buf.Len())
+	}
+}
+
+func TestNewPoolWithOptions(t *testing.T) {
+	pool := NewPool(WithInitialCap(64))
+	buf := pool.Acquire()
+	if buf.Cap() != 64 {
+		t.Errorf("Pooled buffer Cap returned %d, expected 64", buf.Cap())
+	}
+	pool.Release(buf)
+}
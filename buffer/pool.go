@@ -37,40 +37,88 @@ type ObjectPool[T any] interface {
 // poolImpl 是ObjectPool接口的具体实现
 type poolImpl[T any] struct {
 	pool sync.Pool
+	poolCore
 }
 
 // Acquire 从池中获取一个对象实例
 func (p *poolImpl[T]) Acquire() T {
 	obj := p.pool.Get()
 	if obj == nil {
+		p.acquires.Add(1)
 		var zero T
 		return zero
 	}
+	p.onAcquire(obj)
 	return obj.(T)
 }
 
 // Release 将对象实例归还池中
 func (p *poolImpl[T]) Release(obj T) {
-	// 如果对象实现了Mutable接口，重置它
-	if mutable, ok := interface{}(obj).(Mutable); ok {
-		mutable.Reset()
+	if interface{}(obj) == nil {
+		return
+	}
+	// 检测重复归还、丢弃超限对象并重置
+	if !p.onRelease(obj) {
+		return
 	}
 	p.pool.Put(obj)
 }
 
 // Size 返回池中当前可用对象数量的估计值
 func (p *poolImpl[T]) Size() int {
-	// sync.Pool没有提供获取大小的方法，这里返回0
-	return 0
+	// sync.Pool没有提供获取大小的方法，这里根据统计计数估算
+	return p.estimatedSize()
 }
 
 // NewPool 创建一个新的对象池
-func NewPool() ObjectPool[Buffer] {
-	return &poolImpl[Buffer]{
-		pool: sync.Pool{
-			New: func() interface{} {
-				return NewBuffer()
-			},
-		},
+// 选项会应用到池中新建的每个缓冲区，使用WithShards选项时返回分片对象池
+func NewPool(opts ...Option) ObjectPool[Buffer] {
+	o := newOptions(opts)
+	newFunc := func() Buffer {
+		return newBufferWithOptions(&o)
+	}
+	if o.shards > 0 {
+		return newShardedPool(&o, newFunc)
+	}
+	return newPool(&o, newFunc)
+}
+
+// NewPoolWithOptions 使用显式参数创建对象池
+//   - initialCap: 新建缓冲区的初始容量，小于等于0时使用DefaultInitialCap
+//   - maxCap: 池中保留缓冲区的最大容量，超过时归还的缓冲区被丢弃，0表示不限制
+//   - newFunc: 自定义缓冲区构造函数，为nil时使用initialCap创建默认实现
+//   - preallocate: 预先创建并放入池中的缓冲区数量
+func NewPoolWithOptions(initialCap, maxCap int, newFunc func() Buffer, preallocate int) ObjectPool[Buffer] {
+	if initialCap <= 0 {
+		initialCap = DefaultInitialCap
+	}
+	o := newOptions([]Option{WithInitialCap(initialCap), WithMaxRetainedCap(maxCap)})
+	if newFunc == nil {
+		newFunc = func() Buffer {
+			return newBufferWithOptions(&o)
+		}
+	}
+
+	p := newPool(&o, newFunc)
+	buffers := make([]Buffer, preallocate)
+	for i := range buffers {
+		buffers[i] = p.Acquire()
+	}
+	for _, buf := range buffers {
+		p.Release(buf)
+	}
+	return p
+}
+
+// newPool 使用配置和构造函数创建对象池
+func newPool(o *options, newFunc func() Buffer) *poolImpl[Buffer] {
+	p := &poolImpl[Buffer]{
+		poolCore: newPoolCore(o),
+	}
+	p.pool.New = func() interface{} {
+		buf := newFunc()
+		p.onMiss(buf.Cap())
+		return buf
 	}
+	return p
 }
@@ -0,0 +1,203 @@
+package buffer
+
+import "sync/atomic"
+
+// PoolStats 对象池统计信息
+type PoolStats struct {
+	// Acquires 获取对象的次数
+	Acquires uint64 `json:"acquires"`
+	// Releases 归还对象的次数（包括被丢弃的对象）
+	Releases uint64 `json:"releases"`
+	// Misses 池为空时新建对象的次数
+	Misses uint64 `json:"misses"`
+	// Dropped 归还时因容量超限被丢弃的对象数量
+	Dropped uint64 `json:"dropped"`
+	// DoubleReleases 检测到的重复归还次数
+	DoubleReleases uint64 `json:"double_releases"`
+	// RetainedBytes 池中保留的缓冲区容量总和的估计值
+	// sync.Pool在GC时可能回收对象，因此该值可能偏大
+	RetainedBytes int64 `json:"retained_bytes"`
+}
+
+// StatsProvider 定义对象池统计接口
+type StatsProvider interface {
+	// Stats 返回对象池的统计信息快照
+	Stats() PoolStats
+}
+
+// PoolEvent 表示对象池事件类型
+type PoolEvent int
+
+const (
+	// PoolEventAcquire 获取对象
+	PoolEventAcquire PoolEvent = iota
+	// PoolEventRelease 归还对象
+	PoolEventRelease
+	// PoolEventMiss 池为空，新建对象
+	PoolEventMiss
+	// PoolEventDrop 归还的对象被丢弃
+	PoolEventDrop
+)
+
+// String 返回事件名称
+func (e PoolEvent) String() string {
+	switch e {
+	case PoolEventAcquire:
+		return "acquire"
+	case PoolEventRelease:
+		return "release"
+	case PoolEventMiss:
+		return "miss"
+	case PoolEventDrop:
+		return "drop"
+	default:
+		return "unknown"
+	}
+}
+
+// PoolObserver 对象池事件观察函数，用于对接外部指标采集系统
+//   - event: 事件类型
+//   - capacity: 相关缓冲区的容量（字节）
+//
+// 观察函数在Acquire/Release的调用路径上同步执行，应当足够轻量
+type PoolObserver func(event PoolEvent, capacity int)
+
+// WithPoolObserver 设置对象池事件观察函数
+//
+// 该选项只作用于对象池
+func WithPoolObserver(observer PoolObserver) Option {
+	return func(o *options) {
+		o.observer = observer
+	}
+}
+
+// poolCore 保存对象池实现共享的策略和统计计数
+type poolCore struct {
+	maxRetainedCap int
+	observer       PoolObserver
+	debug          bool
+
+	acquires       atomic.Uint64
+	releases       atomic.Uint64
+	misses         atomic.Uint64
+	dropped        atomic.Uint64
+	doubleReleases atomic.Uint64
+	retainedBytes  atomic.Int64
+}
+
+// newPoolCore 根据配置创建poolCore
+func newPoolCore(o *options) poolCore {
+	return poolCore{
+		maxRetainedCap: o.maxRetainedCap,
+		observer:       o.observer,
+		debug:          o.debug,
+	}
+}
+
+// onAcquire 记录从池中取出的对象
+func (c *poolCore) onAcquire(obj interface{}) {
+	c.acquires.Add(1)
+	if tracker, ok := obj.(releaseTracker); ok {
+		tracker.markAcquired()
+	}
+	capacity := capacityOf(obj)
+	c.retainedBytes.Add(-int64(capacity))
+	c.observe(PoolEventAcquire, capacity)
+}
+
+// onMiss 记录池为空时新建的对象
+// 新建的对象会在onAcquire中扣除容量，这里预先计入以保持估计值平衡
+func (c *poolCore) onMiss(capacity int) {
+	c.misses.Add(1)
+	c.retainedBytes.Add(int64(capacity))
+	c.observe(PoolEventMiss, capacity)
+}
+
+// onRelease 处理归还的对象
+// 返回: 对象是否应当放回池中
+func (c *poolCore) onRelease(obj interface{}) bool {
+	// 检测重复归还，必须在重置对象之前完成
+	if tracker, ok := obj.(releaseTracker); ok && !tracker.markReleased() {
+		c.doubleReleases.Add(1)
+		if c.debug {
+			panic(ErrDoubleRelease)
+		}
+		return false
+	}
+	c.releases.Add(1)
+	capacity := capacityOf(obj)
+
+	// 丢弃容量超过限制的缓冲区
+	if c.maxRetainedCap > 0 && capacity > c.maxRetainedCap {
+		c.drop(capacity)
+		return false
+	}
+
+	// 如果对象实现了Mutable接口，重置它
+	if mutable, ok := obj.(Mutable); ok {
+		mutable.Reset()
+	}
+	c.retainedBytes.Add(int64(capacity))
+	c.observe(PoolEventRelease, capacity)
+	return true
+}
+
+// drop 记录被丢弃的对象
+func (c *poolCore) drop(capacity int) {
+	c.dropped.Add(1)
+	c.observe(PoolEventDrop, capacity)
+}
+
+// estimatedSize 根据统计计数估算池中可用对象数量
+// 从池中取出的对象数 = 获取次数 - 新建次数
+func (c *poolCore) estimatedSize() int {
+	retained := int64(c.releases.Load()-c.dropped.Load()) - int64(c.acquires.Load()-c.misses.Load())
+	if retained < 0 {
+		return 0
+	}
+	return int(retained)
+}
+
+// Stats 返回对象池的统计信息快照
+func (c *poolCore) Stats() PoolStats {
+	return PoolStats{
+		Acquires:       c.acquires.Load(),
+		Releases:       c.releases.Load(),
+		Misses:         c.misses.Load(),
+		Dropped:        c.dropped.Load(),
+		DoubleReleases: c.doubleReleases.Load(),
+		RetainedBytes:  c.retainedBytes.Load(),
+	}
+}
+
+// observe 通知观察函数
+func (c *poolCore) observe(event PoolEvent, capacity int) {
+	if c.observer != nil {
+		c.observer(event, capacity)
+	}
+}
+
+// Stats 汇总所有级别的统计信息
+func (p *tieredPoolImpl) Stats() PoolStats {
+	var total PoolStats
+	for _, tier := range p.tiers {
+		if sp, ok := tier.(StatsProvider); ok {
+			stats := sp.Stats()
+			total.Acquires += stats.Acquires
+			total.Releases += stats.Releases
+			total.Misses += stats.Misses
+			total.Dropped += stats.Dropped
+			total.DoubleReleases += stats.DoubleReleases
+			total.RetainedBytes += stats.RetainedBytes
+		}
+	}
+	return total
+}
+
+// capacityOf 返回对象的容量，非缓冲区对象返回0
+func capacityOf(obj interface{}) int {
+	if r, ok := obj.(Readable); ok && r != nil {
+		return r.Cap()
+	}
+	return 0
+}
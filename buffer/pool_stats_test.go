@@ -0,0 +1,50 @@
+package buffer
+
+import "testing"
+
+func TestPoolStats(t *testing.T) {
+	events := make(map[PoolEvent]int)
+	pool := NewPool(
+		WithInitialCap(64),
+		WithMaxRetainedCap(128),
+		WithPoolObserver(func(event PoolEvent, capacity int) {
+			events[event]++
+		}),
+	)
+
+	buf := pool.Acquire()
+	pool.Release(buf)
+
+	big := pool.Acquire()
+	big.Write(make([]byte, 1024))
+	pool.Release(big)
+
+	stats := pool.(StatsProvider).Stats()
+	if stats.Acquires != 2 || stats.Releases != 2 {
+		t.Errorf("Stats returned %d acquires and %d releases, expected 2 and 2", stats.Acquires, stats.Releases)
+	}
+	if stats.Misses < 1 {
+		t.Errorf("Stats returned %d misses, expected at least 1", stats.Misses)
+	}
+	if stats.Dropped != 1 {
+		t.Errorf("Stats returned %d dropped, expected 1", stats.Dropped)
+	}
+	if stats.RetainedBytes < 0 {
+		t.Errorf("RetainedBytes should not be negative, got %d", stats.RetainedBytes)
+	}
+
+	if events[PoolEventAcquire] != 2 || events[PoolEventDrop] != 1 || events[PoolEventMiss] != int(stats.Misses) {
+		t.Errorf("Observer received unexpected events: %v", events)
+	}
+}
+
+func TestTieredPoolStats(t *testing.T) {
+	pool := NewTieredPool([]int{16, 256})
+	pool.Release(pool.AcquireSize(8))
+	pool.Release(pool.AcquireSize(100))
+
+	stats := pool.(StatsProvider).Stats()
+	if stats.Acquires != 2 || stats.Releases != 2 {
+		t.Errorf("Stats returned %d acquires and %d releases, expected 2 and 2", stats.Acquires, stats.Releases)
+	}
+}
@@ -226,4 +226,49 @@ func TestObjectPoolGeneric(t *testing.T) {
 	// 测试大小方法
 	size := pool.Size()
 	_ = size // 只是确保方法可以调用
-}
\ No newline at end of file
+}
+func TestObjectPoolMaxRetainedCap(t *testing.T) {
+	pool := NewPool(WithInitialCap(64), WithMaxRetainedCap(128))
+
+	// 偶发的大负载不应被放回池中
+	big := pool.Acquire()
+	big.Write(make([]byte, 4096))
+	pool.Release(big)
+
+	for i := 0; i < 4; i++ {
+		buf := pool.Acquire()
+		if buf.Cap() > 128 {
+			t.Fatalf("Buffer with Cap %d exceeded max retained capacity", buf.Cap())
+		}
+	}
+}
+
+func TestNewPoolWithExplicitOptions(t *testing.T) {
+	created := 0
+	pool := NewPoolWithOptions(256, 512, func() Buffer {
+		created++
+		return NewBuffer(WithInitialCap(256))
+	}, 4)
+
+	if created != 4 {
+		t.Errorf("Preallocation created %d buffers, expected 4", created)
+	}
+
+	buf := pool.Acquire()
+	if buf.Cap() != 256 {
+		t.Errorf("Acquire returned Cap %d, expected 256", buf.Cap())
+	}
+
+	// 超过maxCap的缓冲区被丢弃
+	buf.Write(make([]byte, 1024))
+	pool.Release(buf)
+	if stats := pool.(StatsProvider).Stats(); stats.Dropped != 1 {
+		t.Errorf("Stats returned %d dropped, expected 1", stats.Dropped)
+	}
+
+	// newFunc为nil时使用默认实现
+	pool = NewPoolWithOptions(0, 0, nil, 0)
+	if buf := pool.Acquire(); buf.Cap() != DefaultInitialCap {
+		t.Errorf("Acquire returned Cap %d, expected %d", buf.Cap(), DefaultInitialCap)
+	}
+}
@@ -0,0 +1,84 @@
+package buffer
+
+import (
+	"errors"
+	"io"
+	"math"
+)
+
+// ErrNegativeOffset 表示随机访问使用了负偏移量
+var ErrNegativeOffset = errors.New("buffer: negative offset")
+
+// RandomAccess 定义随机访问接口
+// 实现了io.ReaderAt和io.WriterAt，zip读取器、定长二进制记录等随机访问编解码器可以直接操作缓冲区
+type RandomAccess interface {
+	io.ReaderAt
+	io.WriterAt
+}
+
+// ReadAt 从指定偏移量读取数据，不影响读位置
+func (b *bufferImpl) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, ErrNegativeOffset
+	}
+	if off >= int64(len(b.data)) {
+		return 0, io.EOF
+	}
+	n = copy(p, b.data[off:])
+	if n < len(p) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// WriteAt 在指定偏移量写入数据
+// 偏移量超过当前长度时，中间的空隙填充零值；写入结束位置超出int范围时返回ErrTooLarge
+func (b *bufferImpl) WriteAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, ErrNegativeOffset
+	}
+	if off > int64(math.MaxInt-len(p)) {
+		return 0, ErrTooLarge
+	}
+	end := int(off) + len(p)
+	if end > len(b.data) {
+		if err := b.grow(end - len(b.data)); err != nil {
+			return 0, err
+		}
+		oldLen := len(b.data)
+		if end <= cap(b.data) {
+			b.data = b.data[:end]
+			if int(off) > oldLen {
+				clear(b.data[oldLen:off])
+			}
+		} else {
+			b.data = append(b.data, make([]byte, end-oldLen)...)
+		}
+	}
+	n = copy(b.data[off:], p)
+	b.digest.invalidate()
+	return n, nil
+}
+
+// ReadAt 从指定偏移量读取数据
+func (b *chunkedBufferImpl) ReadAt(p []byte, off int64) (n int, err error) {
+	if off < 0 {
+		return 0, ErrNegativeOffset
+	}
+	if off >= int64(b.length) {
+		return 0, io.EOF
+	}
+	for _, chunk := range b.chunks {
+		if off >= int64(len(chunk)) {
+			off -= int64(len(chunk))
+			continue
+		}
+		copied := copy(p[n:], chunk[off:])
+		n += copied
+		off = 0
+		if n == len(p) {
+			return n, nil
+		}
+	}
+	return n, io.EOF
+}
@@ -0,0 +1,78 @@
+package buffer
+
+import (
+	"io"
+	"math"
+	"testing"
+)
+
+func TestBufferReadAtWriteAt(t *testing.T) {
+	buf := NewBuffer()
+	buf.WriteString("0123456789")
+	ra := buf.(RandomAccess)
+
+	p := make([]byte, 3)
+	if n, err := ra.ReadAt(p, 4); n != 3 || err != nil || string(p) != "456" {
+		t.Errorf("ReadAt returned (%d, %v, %q)", n, err, p)
+	}
+	if n, err := ra.ReadAt(p, 8); n != 2 || err != io.EOF {
+		t.Errorf("ReadAt near end returned (%d, %v), expected (2, io.EOF)", n, err)
+	}
+	if _, err := ra.ReadAt(p, -1); err != ErrNegativeOffset {
+		t.Errorf("ReadAt with negative offset returned %v", err)
+	}
+
+	ra.WriteAt([]byte("AB"), 2)
+	if string(buf.Get()) != "01AB456789" {
+		t.Errorf("WriteAt in place produced %q", buf.Get())
+	}
+
+	ra.WriteAt([]byte("Z"), 12)
+	if string(buf.Get()) != "01AB456789\x00\x00Z" {
+		t.Errorf("WriteAt past end produced %q", buf.Get())
+	}
+}
+
+func TestBufferWriteAtClearsStaleData(t *testing.T) {
+	buf := NewBuffer()
+	buf.WriteString("stale data")
+	buf.Reset()
+
+	buf.(RandomAccess).WriteAt([]byte("x"), 4)
+	if string(buf.Get()) != "\x00\x00\x00\x00x" {
+		t.Errorf("WriteAt should zero the gap, got %q", buf.Get())
+	}
+}
+
+func TestBufferWriteAtOverlapsEnd(t *testing.T) {
+	buf := NewBuffer()
+	buf.WriteString("hello world")
+	buf.Truncate(5)
+
+	// 从已有数据内部开始写入并越过末尾，容量足够时原地扩展
+	n, err := buf.(RandomAccess).WriteAt([]byte("XYZAB"), 3)
+	if n != 5 || err != nil {
+		t.Fatalf("WriteAt returned (%d, %v)", n, err)
+	}
+	if string(buf.Get()) != "helXYZAB" {
+		t.Errorf("WriteAt overlapping the end produced %q", buf.Get())
+	}
+}
+
+func TestBufferWriteAtOverflow(t *testing.T) {
+	buf := NewBuffer()
+	if _, err := buf.(RandomAccess).WriteAt([]byte("x"), math.MaxInt64); err != ErrTooLarge {
+		t.Errorf("WriteAt with overflowing offset returned %v, expected ErrTooLarge", err)
+	}
+}
+
+func TestChunkedReadAt(t *testing.T) {
+	buf := NewChunkedBuffer(4)
+	buf.WriteString("0123456789")
+
+	p := make([]byte, 5)
+	n, err := buf.(io.ReaderAt).ReadAt(p, 3)
+	if n != 5 || err != nil || string(p) != "34567" {
+		t.Errorf("ReadAt across chunks returned (%d, %v, %q)", n, err, p)
+	}
+}
@@ -0,0 +1,79 @@
+package buffer
+
+import "sync/atomic"
+
+// RefCounted 定义引用计数缓冲区接口
+// 用于在异步处理器、影子路由或批量输出之间安全地共享同一个池化缓冲区
+//
+// 引用计数从1开始，每个额外的使用者调用Retain()，
+// 使用完毕后调用Release()。当最后一个使用者释放时，
+// 缓冲区会且仅会被归还一次
+type RefCounted interface {
+	Buffer
+
+	// Retain 增加引用计数，返回缓冲区自身便于链式调用
+	Retain() RefCounted
+
+	// Release 减少引用计数
+	// 返回: 如果这是最后一次释放（缓冲区已被归还）则返回true
+	Release() bool
+
+	// RefCount 返回当前引用计数
+	RefCount() int32
+}
+
+// refCountedImpl 是RefCounted接口的具体实现
+type refCountedImpl struct {
+	Buffer
+	refs      atomic.Int32
+	onRelease func(Buffer)
+}
+
+// NewRefCounted 将缓冲区包装为引用计数缓冲区
+//   - buf: 被包装的缓冲区
+//   - onRelease: 最后一个使用者释放时调用，通常为对象池的Release方法，可以为nil
+func NewRefCounted(buf Buffer, onRelease func(Buffer)) RefCounted {
+	rc := &refCountedImpl{
+		Buffer:    buf,
+		onRelease: onRelease,
+	}
+	rc.refs.Store(1)
+	return rc
+}
+
+// AcquireRefCounted 从对象池获取缓冲区并包装为引用计数缓冲区
+// 最后一次Release时缓冲区会被归还到同一个对象池
+func AcquireRefCounted(pool ObjectPool[Buffer]) RefCounted {
+	return NewRefCounted(pool.Acquire(), pool.Release)
+}
+
+// Retain 增加引用计数
+func (r *refCountedImpl) Retain() RefCounted {
+	if r.refs.Add(1) <= 1 {
+		panic("buffer: Retain called on released buffer")
+	}
+	return r
+}
+
+// Release 减少引用计数，计数归零时归还底层缓冲区
+func (r *refCountedImpl) Release() bool {
+	refs := r.refs.Add(-1)
+	if refs > 0 {
+		return false
+	}
+	if refs < 0 {
+		panic("buffer: Release called more times than Retain")
+	}
+
+	buf := r.Buffer
+	r.Buffer = nil
+	if r.onRelease != nil {
+		r.onRelease(buf)
+	}
+	return true
+}
+
+// RefCount 返回当前引用计数
+func (r *refCountedImpl) RefCount() int32 {
+	return r.refs.Load()
+}
@@ -0,0 +1,75 @@
+package buffer
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRefCountedReleaseOnce(t *testing.T) {
+	released := 0
+	rc := NewRefCounted(NewBuffer(), func(buf Buffer) {
+		released++
+	})
+	rc.WriteString("shared")
+
+	rc.Retain()
+	rc.Retain()
+	if rc.RefCount() != 3 {
+		t.Errorf("RefCount returned %d, expected 3", rc.RefCount())
+	}
+
+	if rc.Release() || rc.Release() {
+		t.Error("Release should not report final release while references remain")
+	}
+	if released != 0 {
+		t.Error("Buffer should not be returned before the last release")
+	}
+
+	if !rc.Release() {
+		t.Error("Last Release should report final release")
+	}
+	if released != 1 {
+		t.Errorf("onRelease called %d times, expected 1", released)
+	}
+}
+
+func TestRefCountedConcurrentRelease(t *testing.T) {
+	var mu sync.Mutex
+	released := 0
+	rc := NewRefCounted(NewBuffer(), func(buf Buffer) {
+		mu.Lock()
+		released++
+		mu.Unlock()
+	})
+
+	const users = 16
+	for i := 0; i < users-1; i++ {
+		rc.Retain()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < users; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rc.Release()
+		}()
+	}
+	wg.Wait()
+
+	if released != 1 {
+		t.Errorf("onRelease called %d times, expected 1", released)
+	}
+}
+
+func TestRefCountedOverRelease(t *testing.T) {
+	rc := AcquireRefCounted(NewPool())
+	rc.Release()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Releasing more times than retained should panic")
+		}
+	}()
+	rc.Release()
+}
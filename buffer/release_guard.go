@@ -0,0 +1,99 @@
+package buffer
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrDoubleRelease 表示同一个缓冲区被重复归还到对象池
+var ErrDoubleRelease = errors.New("buffer: buffer released twice")
+
+// ErrUseAfterRelease 表示使用了已经归还到对象池的缓冲区
+var ErrUseAfterRelease = errors.New("buffer: buffer used after release")
+
+// releaseTracker 由支持重复释放检测的缓冲区实现
+type releaseTracker interface {
+	// markReleased 标记为已归还，如果已经处于归还状态则返回false
+	markReleased() bool
+	// markAcquired 标记为已取出
+	markAcquired()
+}
+
+// releaseState 记录缓冲区是否处于归还状态
+// 使用一次原子比较交换完成检测，开销足够低，可以在生产环境中保持开启
+type releaseState struct {
+	released atomic.Bool
+	// acquiredCap 是RecordAcquiredCap记录的容量加1，0表示没有记录
+	acquiredCap atomic.Int64
+}
+
+// markReleased 标记为已归还
+func (s *releaseState) markReleased() bool {
+	return s.released.CompareAndSwap(false, true)
+}
+
+// markAcquired 标记为已取出
+func (s *releaseState) markAcquired() {
+	s.released.Store(false)
+}
+
+// isReleased 判断是否处于归还状态
+func (s *releaseState) isReleased() bool {
+	return s.released.Load()
+}
+
+// RecordAcquiredCap 在缓冲区上记录当前容量，供TakeAcquiredCap在释放时取回
+// 缓冲区在使用中扩容后，管理器按记录的容量而不是当前容量扣减统计
+// 返回: 缓冲区实现是否支持记录容量
+func RecordAcquiredCap(buf Buffer) bool {
+	s, ok := buf.(interface{ state() *releaseState })
+	if ok {
+		s.state().acquiredCap.Store(int64(buf.Cap()) + 1)
+	}
+	return ok
+}
+
+// TakeAcquiredCap 取回并清除RecordAcquiredCap记录的容量
+// 返回: 记录的容量以及是否有记录
+func TakeAcquiredCap(buf Buffer) (int, bool) {
+	s, ok := buf.(interface{ state() *releaseState })
+	if !ok {
+		return 0, false
+	}
+	recorded := s.state().acquiredCap.Swap(0)
+	return int(recorded - 1), recorded > 0
+}
+
+// state 返回缓冲区的归还状态
+func (s *releaseState) state() *releaseState {
+	return s
+}
+
+// IsReleased 判断缓冲区是否已经归还到对象池
+// 不支持归还状态跟踪的缓冲区实现总是返回false
+func IsReleased(buf Buffer) bool {
+	if tracker, ok := buf.(interface{ isReleased() bool }); ok {
+		return tracker.isReleased()
+	}
+	return false
+}
+
+// CheckLive 检查缓冲区是否仍然可用
+// 返回: 缓冲区已归还到对象池时返回ErrUseAfterRelease
+func CheckLive(buf Buffer) error {
+	if IsReleased(buf) {
+		return ErrUseAfterRelease
+	}
+	return nil
+}
+
+// WithDebug 设置对象池的调试模式
+// 调试模式下重复归还缓冲区会panic(ErrDoubleRelease)；
+// 非调试模式下重复归还会被忽略并计入统计信息的DoubleReleases
+//
+// 该选项只作用于对象池
+func WithDebug(debug bool) Option {
+	return func(o *options) {
+		o.debug = debug
+	}
+}
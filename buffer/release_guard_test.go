@@ -0,0 +1,89 @@
+package buffer
+
+import "testing"
+
+func TestDoubleReleaseIgnored(t *testing.T) {
+	pool := NewPool()
+
+	buf := pool.Acquire()
+	pool.Release(buf)
+	pool.Release(buf)
+
+	stats := pool.(StatsProvider).Stats()
+	if stats.DoubleReleases != 1 {
+		t.Errorf("Stats returned %d double releases, expected 1", stats.DoubleReleases)
+	}
+	if stats.Releases != 1 {
+		t.Errorf("Stats returned %d releases, expected 1", stats.Releases)
+	}
+
+	// 只有一个实例被放回池中，两次获取不应得到同一个缓冲区
+	first := pool.Acquire()
+	second := pool.Acquire()
+	if first == second {
+		t.Error("Double release should not put the same buffer into the pool twice")
+	}
+}
+
+func TestDoubleReleasePanicsInDebugMode(t *testing.T) {
+	pool := NewPool(WithDebug(true))
+
+	buf := pool.Acquire()
+	pool.Release(buf)
+
+	defer func() {
+		if r := recover(); r != ErrDoubleRelease {
+			t.Errorf("Double release in debug mode recovered %v, expected ErrDoubleRelease", r)
+		}
+	}()
+	pool.Release(buf)
+}
+
+func TestReleaseAfterReacquire(t *testing.T) {
+	pool := NewPool(WithDebug(true))
+
+	buf := pool.Acquire()
+	pool.Release(buf)
+	buf = pool.Acquire()
+
+	// 重新获取后再次归还是合法的
+	pool.Release(buf)
+}
+
+func TestCheckLive(t *testing.T) {
+	pool := NewPool()
+	buf := pool.Acquire()
+	if IsReleased(buf) || CheckLive(buf) != nil {
+		t.Fatal("Acquired buffer should be live")
+	}
+
+	pool.Release(buf)
+	if !IsReleased(buf) {
+		t.Error("Released buffer should be reported as released")
+	}
+	if err := CheckLive(buf); err != ErrUseAfterRelease {
+		t.Errorf("Expected ErrUseAfterRelease, got %v", err)
+	}
+
+	again := pool.Acquire()
+	if again == buf && IsReleased(again) {
+		t.Error("Re-acquired buffer should be live again")
+	}
+}
+
+func TestRecordAcquiredCap(t *testing.T) {
+	buf := NewBuffer()
+	buf.WriteString("hello")
+	if !RecordAcquiredCap(buf) {
+		t.Fatal("RecordAcquiredCap should support the default buffer")
+	}
+	recorded := buf.Cap()
+	buf.Write(make([]byte, 1024))
+
+	if n, ok := TakeAcquiredCap(buf); !ok || n != recorded {
+		t.Errorf("TakeAcquiredCap returned (%d, %v), expected (%d, true)", n, ok, recorded)
+	}
+	if _, ok := TakeAcquiredCap(buf); ok {
+		t.Error("TakeAcquiredCap should clear the recorded capacity")
+	}
+}
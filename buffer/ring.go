@@ -0,0 +1,206 @@
+package buffer
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrBufferFull 表示环形缓冲区剩余空间不足
+var ErrBufferFull = errors.New("buffer: ring buffer is full")
+
+// RingBuffer 定义环形缓冲区接口
+// 适用于流式数据源：生产者通过Write写入，消费者通过Read或Frame/Discard读取，
+// 容量固定，不会在写入时扩容
+type RingBuffer interface {
+	Buffer
+	io.Reader
+
+	// Available 返回可写入的剩余空间
+	Available() int
+
+	// Frame 返回前n个可读字节的零拷贝视图，用于将完整帧交给路由器
+	// 视图在调用Discard并被后续写入覆盖之前保持有效
+	Frame(n int) (Buffer, error)
+
+	// Discard 丢弃前n个可读字节，返回实际丢弃的字节数
+	Discard(n int) int
+}
+
+// ringBufferImpl 是RingBuffer接口的具体实现
+type ringBufferImpl struct {
+	data   []byte
+	head   int // 读位置
+	length int // 可读字节数
+}
+
+// NewRingBuffer 创建一个指定容量的环形缓冲区
+func NewRingBuffer(size int) RingBuffer {
+	return &ringBufferImpl{
+		data: make([]byte, size),
+	}
+}
+
+// Get 获取可读窗口的连续数据
+// 如果数据跨越了缓冲区末尾，会先在原地整理为连续布局
+func (r *ringBufferImpl) Get() []byte {
+	r.linearize()
+	return r.data[r.head : r.head+r.length]
+}
+
+// Len 获取可读字节数
+func (r *ringBufferImpl) Len() int {
+	return r.length
+}
+
+// Cap 获取环形缓冲区的固定容量
+func (r *ringBufferImpl) Cap() int {
+	return len(r.data)
+}
+
+// Available 返回可写入的剩余空间
+func (r *ringBufferImpl) Available() int {
+	return len(r.data) - r.length
+}
+
+// Write 写入数据，空间不足时写入能容纳的部分并返回ErrBufferFull
+func (r *ringBufferImpl) Write(p []byte) (n int, err error) {
+	if len(p) > r.Available() {
+		p = p[:r.Available()]
+		err = ErrBufferFull
+	}
+	for len(p) > 0 {
+		tail := (r.head + r.length) % len(r.data)
+		end := len(r.data)
+		if tail < r.head {
+			end = r.head
+		}
+		written := copy(r.data[tail:end], p)
+		r.length += written
+		n += written
+		p = p[written:]
+	}
+	return n, err
+}
+
+// WriteString 写入字符串，空间不足时写入能容纳的部分并返回ErrBufferFull
+func (r *ringBufferImpl) WriteString(s string) (n int, err error) {
+	if len(s) > r.Available() {
+		s = s[:r.Available()]
+		err = ErrBufferFull
+	}
+	for len(s) > 0 {
+		tail := (r.head + r.length) % len(r.data)
+		end := len(r.data)
+		if tail < r.head {
+			end = r.head
+		}
+		written := copy(r.data[tail:end], s)
+		r.length += written
+		n += written
+		s = s[written:]
+	}
+	return n, err
+}
+
+// Read 读取数据并推进读位置
+func (r *ringBufferImpl) Read(p []byte) (n int, err error) {
+	if r.length == 0 {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+	for len(p) > 0 && r.length > 0 {
+		end := r.head + r.length
+		if end > len(r.data) {
+			end = len(r.data)
+		}
+		read := copy(p, r.data[r.head:end])
+		r.consume(read)
+		n += read
+		p = p[read:]
+	}
+	return n, nil
+}
+
+// Frame 返回前n个可读字节的零拷贝视图
+func (r *ringBufferImpl) Frame(n int) (Buffer, error) {
+	if n < 0 || n > r.length {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if r.head+n > len(r.data) {
+		r.linearize()
+	}
+	return &bufferImpl{
+		data: r.data[r.head : r.head+n : r.head+n],
+	}, nil
+}
+
+// Discard 丢弃前n个可读字节
+func (r *ringBufferImpl) Discard(n int) int {
+	if n > r.length {
+		n = r.length
+	}
+	if n > 0 {
+		r.consume(n)
+	}
+	return n
+}
+
+// consume 推进读位置
+func (r *ringBufferImpl) consume(n int) {
+	r.length -= n
+	if r.length == 0 {
+		r.head = 0
+		return
+	}
+	r.head = (r.head + n) % len(r.data)
+}
+
+// Reset 清空所有可读数据
+func (r *ringBufferImpl) Reset() {
+	r.head = 0
+	r.length = 0
+}
+
+// Truncate 只保留前n个可读字节
+func (r *ringBufferImpl) Truncate(n int) {
+	if n >= 0 && n < r.length {
+		r.length = n
+	}
+}
+
+// Slice 创建可读窗口的子切片但不复制数据
+func (r *ringBufferImpl) Slice(start, end int) Buffer {
+	data := r.Get()[start:end]
+	return &bufferImpl{
+		data: data[:len(data):len(data)],
+	}
+}
+
+// Clone 创建环形缓冲区的深拷贝
+func (r *ringBufferImpl) Clone() Buffer {
+	clone := &ringBufferImpl{
+		data: make([]byte, len(r.data)),
+	}
+	clone.Write(r.Get())
+	return clone
+}
+
+// linearize 在原地旋转底层数组，使可读数据从下标0开始连续排列
+func (r *ringBufferImpl) linearize() {
+	if r.head == 0 || r.head+r.length <= len(r.data) {
+		return
+	}
+	reverseBytes(r.data[:r.head])
+	reverseBytes(r.data[r.head:])
+	reverseBytes(r.data)
+	r.head = 0
+}
+
+// reverseBytes 原地反转字节切片
+func reverseBytes(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
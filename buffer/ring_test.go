@@ -0,0 +1,62 @@
+package buffer
+
+import (
+	"io"
+	"testing"
+)
+
+func TestRingBufferWriteRead(t *testing.T) {
+	ring := NewRingBuffer(8)
+
+	n, err := ring.WriteString("abcdef")
+	if err != nil || n != 6 {
+		t.Fatalf("WriteString returned (%d, %v), expected (6, nil)", n, err)
+	}
+
+	p := make([]byte, 4)
+	n, _ = ring.Read(p)
+	if string(p[:n]) != "abcd" {
+		t.Errorf("Read returned %q, expected %q", p[:n], "abcd")
+	}
+
+	// 写入跨越缓冲区末尾
+	ring.WriteString("ghijk")
+	if string(ring.Get()) != "efghijk" {
+		t.Errorf("Get returned %q, expected %q", ring.Get(), "efghijk")
+	}
+
+	n, err = ring.WriteString("lmn")
+	if err != ErrBufferFull || n != 1 {
+		t.Errorf("Write to full ring returned (%d, %v), expected (1, ErrBufferFull)", n, err)
+	}
+
+	all, _ := io.ReadAll(ring)
+	if string(all) != "efghijkl" {
+		t.Errorf("ReadAll returned %q, expected %q", all, "efghijkl")
+	}
+}
+
+func TestRingBufferFrame(t *testing.T) {
+	ring := NewRingBuffer(8)
+	ring.WriteString("xxxxxx")
+	ring.Discard(6)
+	ring.WriteString("HDR:body")
+
+	frame, err := ring.Frame(4)
+	if err != nil {
+		t.Fatalf("Frame returned error: %v", err)
+	}
+	if string(frame.Get()) != "HDR:" {
+		t.Errorf("Frame returned %q, expected %q", frame.Get(), "HDR:")
+	}
+	ring.Discard(4)
+
+	frame, _ = ring.Frame(4)
+	if string(frame.Get()) != "body" {
+		t.Errorf("Frame returned %q, expected %q", frame.Get(), "body")
+	}
+
+	if _, err := ring.Frame(5); err == nil {
+		t.Error("Frame larger than readable data should return an error")
+	}
+}
@@ -0,0 +1,113 @@
+package buffer
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync"
+)
+
+// defaultShardCapacity 是分片对象池每个分片默认保留的最大对象数
+const defaultShardCapacity = 256
+
+// poolShard 是分片对象池的一个分片
+type poolShard struct {
+	mu    sync.Mutex
+	items []Buffer
+	// 填充到缓存行大小，避免相邻分片之间的伪共享
+	_ [64]byte
+}
+
+// shardedPoolImpl 是按分片划分的ObjectPool实现
+// 每个goroutine随机选择一个分片，分片为空时从其他分片窃取，
+// 在极高的消息速率下可以减少单个sync.Pool和管理器间接调用带来的竞争
+type shardedPoolImpl struct {
+	shards        []poolShard
+	shardCapacity int
+	newFunc       func() Buffer
+	poolCore
+}
+
+// WithShards 使NewPool返回分片对象池
+//   - n: 分片数量，小于等于0时使用runtime.GOMAXPROCS(0)
+//
+// 与sync.Pool不同，分片对象池中的对象不会在GC时被回收
+//
+// 该选项只作用于对象池
+func WithShards(n int) Option {
+	return func(o *options) {
+		if n <= 0 {
+			n = runtime.GOMAXPROCS(0)
+		}
+		o.shards = n
+	}
+}
+
+// newShardedPool 创建分片对象池
+func newShardedPool(o *options, newFunc func() Buffer) *shardedPoolImpl {
+	return &shardedPoolImpl{
+		shards:        make([]poolShard, o.shards),
+		shardCapacity: defaultShardCapacity,
+		newFunc:       newFunc,
+		poolCore:      newPoolCore(o),
+	}
+}
+
+// Acquire 从随机分片获取缓冲区，分片为空时从其他分片窃取
+func (p *shardedPoolImpl) Acquire() Buffer {
+	start := rand.IntN(len(p.shards))
+	for i := 0; i < len(p.shards); i++ {
+		shard := &p.shards[(start+i)%len(p.shards)]
+		// 自己的分片阻塞等待，窃取时跳过正在被使用的分片
+		if i == 0 {
+			shard.mu.Lock()
+		} else if !shard.mu.TryLock() {
+			continue
+		}
+		if n := len(shard.items); n > 0 {
+			buf := shard.items[n-1]
+			shard.items[n-1] = nil
+			shard.items = shard.items[:n-1]
+			shard.mu.Unlock()
+			p.onAcquire(buf)
+			return buf
+		}
+		shard.mu.Unlock()
+	}
+
+	buf := p.newFunc()
+	p.onMiss(buf.Cap())
+	p.onAcquire(buf)
+	return buf
+}
+
+// Release 将缓冲区归还到随机分片，分片已满时丢弃
+func (p *shardedPoolImpl) Release(buf Buffer) {
+	if buf == nil || !p.onRelease(buf) {
+		return
+	}
+
+	shard := &p.shards[rand.IntN(len(p.shards))]
+	shard.mu.Lock()
+	if len(shard.items) < p.shardCapacity {
+		shard.items = append(shard.items, buf)
+		shard.mu.Unlock()
+		return
+	}
+	shard.mu.Unlock()
+
+	// 已经计入保留容量，丢弃时扣除
+	p.retainedBytes.Add(-int64(buf.Cap()))
+	p.drop(buf.Cap())
+}
+
+// Size 返回所有分片中可用对象的数量
+func (p *shardedPoolImpl) Size() int {
+	total := 0
+	for i := range p.shards {
+		shard := &p.shards[i]
+		shard.mu.Lock()
+		total += len(shard.items)
+		shard.mu.Unlock()
+	}
+	return total
+}
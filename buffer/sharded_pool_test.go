@@ -0,0 +1,58 @@
+package buffer
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestShardedPoolAcquireRelease(t *testing.T) {
+	pool := NewPool(WithShards(4), WithInitialCap(32))
+	if _, ok := pool.(*shardedPoolImpl); !ok {
+		t.Fatal("WithShards should select the sharded pool implementation")
+	}
+
+	buf := pool.Acquire()
+	if buf.Cap() != 32 {
+		t.Errorf("Acquire returned Cap %d, expected 32", buf.Cap())
+	}
+	buf.WriteString("data")
+	pool.Release(buf)
+
+	if pool.Size() != 1 {
+		t.Errorf("Size returned %d, expected 1", pool.Size())
+	}
+
+	// 从任意分片窃取
+	got := pool.Acquire()
+	if got != buf || got.Len() != 0 {
+		t.Error("Acquire should reuse the released buffer after resetting it")
+	}
+
+	stats := pool.(StatsProvider).Stats()
+	if stats.Acquires != 2 || stats.Misses != 1 || stats.Releases != 1 {
+		t.Errorf("Stats returned unexpected values: %+v", stats)
+	}
+}
+
+func TestShardedPoolConcurrentAccess(t *testing.T) {
+	pool := NewPool(WithShards(0), WithDebug(true))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				buf := pool.Acquire()
+				buf.WriteString("payload")
+				pool.Release(buf)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := pool.(StatsProvider).Stats()
+	if stats.Acquires != 8000 || stats.DoubleReleases != 0 {
+		t.Errorf("Stats returned unexpected values: %+v", stats)
+	}
+}
@@ -0,0 +1,86 @@
+package buffer
+
+import "sort"
+
+// DefaultSizeClasses 是分级对象池的默认容量级别：1KB、16KB、256KB、4MB
+var DefaultSizeClasses = []int{1 << 10, 16 << 10, 256 << 10, 4 << 20}
+
+// SizedPool 定义按容量分级的缓冲区对象池接口
+// 根据所需容量选择级别，避免偶发的大负载把巨大的底层数组留在服务小消息的池中
+type SizedPool interface {
+	ObjectPool[Buffer]
+
+	// AcquireSize 获取容量不小于n的缓冲区
+	// n超过最大级别时返回不池化的新缓冲区，归还时会被丢弃
+	AcquireSize(n int) Buffer
+}
+
+// tieredPoolImpl 是SizedPool接口的具体实现
+type tieredPoolImpl struct {
+	classes []int
+	tiers   []ObjectPool[Buffer]
+	opts    []Option
+}
+
+// NewTieredPool 创建一个按容量分级的对象池
+//   - classes: 容量级别（字节），为空时使用DefaultSizeClasses
+//   - opts: 应用到每个级别的选项，初始容量由级别决定
+func NewTieredPool(classes []int, opts ...Option) SizedPool {
+	if len(classes) == 0 {
+		classes = DefaultSizeClasses
+	}
+	classes = append([]int(nil), classes...)
+	sort.Ints(classes)
+
+	p := &tieredPoolImpl{
+		classes: classes,
+		tiers:   make([]ObjectPool[Buffer], len(classes)),
+		opts:    opts,
+	}
+	for i, class := range classes {
+		tierOpts := append(append([]Option(nil), opts...), WithInitialCap(class))
+		p.tiers[i] = NewPool(tierOpts...)
+	}
+	return p
+}
+
+// Acquire 从最小的级别获取缓冲区
+func (p *tieredPoolImpl) Acquire() Buffer {
+	return p.tiers[0].Acquire()
+}
+
+// AcquireSize 获取容量不小于n的缓冲区
+func (p *tieredPoolImpl) AcquireSize(n int) Buffer {
+	i := sort.SearchInts(p.classes, n)
+	if i == len(p.classes) {
+		return NewBuffer(append(append([]Option(nil), p.opts...), WithInitialCap(n))...)
+	}
+	return p.tiers[i].Acquire()
+}
+
+// Release 按缓冲区当前容量归还到对应级别
+// 容量小于最小级别或大于最大级别的缓冲区会被丢弃
+func (p *tieredPoolImpl) Release(buf Buffer) {
+	if buf == nil {
+		return
+	}
+	capacity := buf.Cap()
+	if capacity > p.classes[len(p.classes)-1] {
+		return
+	}
+	// 找到容量不超过当前容量的最大级别
+	i := sort.SearchInts(p.classes, capacity+1) - 1
+	if i < 0 {
+		return
+	}
+	p.tiers[i].Release(buf)
+}
+
+// Size 返回所有级别中可用对象数量的估计值
+func (p *tieredPoolImpl) Size() int {
+	total := 0
+	for _, tier := range p.tiers {
+		total += tier.Size()
+	}
+	return total
+}
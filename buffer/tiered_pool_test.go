@@ -0,0 +1,51 @@
+package buffer
+
+import "testing"
+
+func TestTieredPoolAcquireSize(t *testing.T) {
+	pool := NewTieredPool([]int{16, 256, 4096})
+
+	tests := []struct {
+		size        int
+		expectedCap int
+	}{
+		{0, 16},
+		{16, 16},
+		{17, 256},
+		{4096, 4096},
+		{10000, 10000},
+	}
+	for _, tt := range tests {
+		buf := pool.AcquireSize(tt.size)
+		if buf.Cap() != tt.expectedCap {
+			t.Errorf("AcquireSize(%d) returned Cap %d, expected %d", tt.size, buf.Cap(), tt.expectedCap)
+		}
+		pool.Release(buf)
+	}
+
+	if buf := pool.Acquire(); buf.Cap() != 16 {
+		t.Errorf("Acquire returned Cap %d, expected the smallest class", buf.Cap())
+	}
+}
+
+func TestTieredPoolReleaseByCapacity(t *testing.T) {
+	pool := NewTieredPool([]int{16, 256}).(*tieredPoolImpl)
+
+	// 增长后的缓冲区归还到容量不超过它的最大级别
+	buf := pool.AcquireSize(16)
+	buf.Write(make([]byte, 300))
+	pool.Release(buf)
+
+	// 超过最大级别的缓冲区被丢弃，不会服务后续的小消息
+	big := NewBuffer(WithInitialCap(1 << 20))
+	pool.Release(big)
+	for i := 0; i < 4; i++ {
+		if got := pool.AcquireSize(1); got.Cap() > 256 {
+			t.Fatalf("Oversized buffer was pooled, got Cap %d", got.Cap())
+		}
+	}
+
+	// 容量过小的缓冲区被丢弃
+	pool.Release(NewBuffer(WithInitialCap(4)))
+	pool.Release(nil)
+}
@@ -0,0 +1,100 @@
+package buffer
+
+import "net"
+
+// Vectored 定义向量化I/O接口
+// 传输层可以直接使用writev发送数据帧，无需先拼接为连续内存
+type Vectored interface {
+	// Buffers 以net.Buffers形式返回数据（不复制数据）
+	// 返回的net.Buffers可以安全地传给WriteTo消费
+	Buffers() net.Buffers
+
+	// WriteVec 依次写入多个切片
+	WriteVec(bufs ...[]byte) (n int, err error)
+}
+
+// Buffers 以net.Buffers形式返回数据
+func (b *bufferImpl) Buffers() net.Buffers {
+	return net.Buffers{b.data}
+}
+
+// WriteVec 依次写入多个切片，只扩容一次
+func (b *bufferImpl) WriteVec(bufs ...[]byte) (n int, err error) {
+	total := 0
+	for _, p := range bufs {
+		total += len(p)
+	}
+	if err := b.grow(total); err != nil {
+		return 0, err
+	}
+	if cap(b.data)-len(b.data) < total {
+		data := make([]byte, len(b.data), len(b.data)+total)
+		copy(data, b.data)
+		b.data = data
+	}
+	for _, p := range bufs {
+		b.data = append(b.data, p...)
+	}
+	b.digest.invalidate()
+	return total, nil
+}
+
+// Buffers 以net.Buffers形式返回数据块列表
+func (b *chunkedBufferImpl) Buffers() net.Buffers {
+	// 复制切片头，避免WriteTo消费时修改数据块列表
+	return append(net.Buffers(nil), b.chunks...)
+}
+
+// WriteVec 依次写入多个切片
+func (b *chunkedBufferImpl) WriteVec(bufs ...[]byte) (n int, err error) {
+	for _, p := range bufs {
+		written, _ := b.Write(p)
+		n += written
+	}
+	return n, nil
+}
+
+// Buffers 以net.Buffers形式返回可读数据，跨越末尾时返回两段
+func (r *ringBufferImpl) Buffers() net.Buffers {
+	if r.head+r.length <= len(r.data) {
+		return net.Buffers{r.data[r.head : r.head+r.length]}
+	}
+	return net.Buffers{r.data[r.head:], r.data[:r.head+r.length-len(r.data)]}
+}
+
+// WriteVec 依次写入多个切片，空间不足时返回ErrBufferFull
+func (r *ringBufferImpl) WriteVec(bufs ...[]byte) (n int, err error) {
+	for _, p := range bufs {
+		written, err := r.Write(p)
+		n += written
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Vec 以net.Buffers形式返回缓冲区数据
+// 如果缓冲区实现了Vectored接口则不复制数据块
+func Vec(buf Readable) net.Buffers {
+	if v, ok := buf.(Vectored); ok {
+		return v.Buffers()
+	}
+	return net.Buffers{buf.Get()}
+}
+
+// WriteVec 将多个切片写入缓冲区
+// 如果缓冲区实现了Vectored接口则使用其批量写入
+func WriteVec(w Writable, bufs ...[]byte) (n int, err error) {
+	if v, ok := w.(Vectored); ok {
+		return v.WriteVec(bufs...)
+	}
+	for _, p := range bufs {
+		written, err := w.Write(p)
+		n += written
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,56 @@
+package buffer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestVecChunked(t *testing.T) {
+	buf := NewChunkedBuffer(4)
+	buf.WriteString("0123456789")
+
+	vec := Vec(buf)
+	if len(vec) != 3 {
+		t.Errorf("Vec returned %d segments, expected 3", len(vec))
+	}
+
+	var out bytes.Buffer
+	vec.WriteTo(&out)
+	if out.String() != "0123456789" {
+		t.Errorf("WriteTo returned %q", out.String())
+	}
+
+	// WriteTo消费net.Buffers后，缓冲区的数据块不应受影响
+	if string(buf.Get()) != "0123456789" {
+		t.Errorf("Chunks were modified by WriteTo: %q", buf.Get())
+	}
+}
+
+func TestVecRing(t *testing.T) {
+	ring := NewRingBuffer(8)
+	ring.WriteString("xxxxxx")
+	ring.Discard(5)
+	ring.WriteString("abcde")
+
+	vec := Vec(ring)
+	if len(vec) != 2 {
+		t.Errorf("Vec of wrapped ring returned %d segments, expected 2", len(vec))
+	}
+
+	var out bytes.Buffer
+	vec.WriteTo(&out)
+	if out.String() != "xabcde" {
+		t.Errorf("WriteTo returned %q", out.String())
+	}
+}
+
+func TestWriteVec(t *testing.T) {
+	buf := NewBuffer()
+	n, err := WriteVec(buf, []byte("hdr:"), []byte("body"), nil, []byte(":crc"))
+	if err != nil || n != 12 {
+		t.Fatalf("WriteVec returned (%d, %v), expected (12, nil)", n, err)
+	}
+	if string(buf.Get()) != "hdr:body:crc" {
+		t.Errorf("WriteVec produced %q", buf.Get())
+	}
+}
@@ -0,0 +1,195 @@
+// content-router 按声明式规则文件路由消息
+// 从标准输入或网络套接字逐帧读取消息，按规则写入标准输出、文件或HTTP接收端，
+// 用于快速搭建处理管道和测试路由规则
+//
+// 用法:
+//
+//	content-router -rules rules.json < input.log
+//	content-router -rules rules.json -listen tcp://:9000
+//	content-router -rules rules.json -check
+//	content-router -rules rules.json -graph dot | dot -Tsvg > rules.svg
+//	content-router -rules rules.json -plugins ./plugins < input.log
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
+	"github.com/aomirun/content-router/plugin"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/rules"
+	"github.com/aomirun/content-router/transport"
+)
+
+func main() {
+	rulesPath := flag.String("rules", "", "规则文件路径（必需）")
+	listen := flag.String("listen", "", "监听地址，例如tcp://:9000、udp://:9000或unix:///tmp/router.sock；为空时读取标准输入")
+	framing := flag.String("framing", "line", "分帧方式：line或crlf，对udp无效")
+	check := flag.Bool("check", false, "只检查规则文件并报告永远不会被匹配的规则，不处理消息")
+	graph := flag.String("graph", "", "把规则的路由拓扑以dot或json格式输出到标准输出，不处理消息")
+	explain := flag.Bool("explain", false, "把每条消息匹配的规则名称输出到标准错误")
+	strict := flag.Bool("strict", false, "处理失败时停止，默认记录错误后继续")
+	pluginDir := flag.String("plugins", "", "插件目录，加载其中的所有.so插件")
+	flag.Parse()
+
+	log.SetFlags(0)
+	log.SetPrefix("content-router: ")
+	if *rulesPath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(*rulesPath, *listen, *framing, *graph, *pluginDir, *check, *explain, *strict); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run 加载规则并开始处理消息
+func run(rulesPath, listen, framing, graph, pluginDir string, check, explain, strict bool) error {
+	// 插件注册的匹配器要在解析规则文件之前可用
+	var plugins []*plugin.Plugin
+	if pluginDir != "" {
+		var err error
+		if plugins, err = plugin.Discover(pluginDir); err != nil {
+			return err
+		}
+	}
+
+	cfg, err := rules.Load(rulesPath)
+	if err != nil {
+		return err
+	}
+	if check {
+		diagnostics := cfg.Validate()
+		for _, d := range diagnostics {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", rulesPath, d)
+		}
+		if len(diagnostics) > 0 {
+			return fmt.Errorf("%s: %d problems found", rulesPath, len(diagnostics))
+		}
+		fmt.Printf("%s: %d rules OK\n", rulesPath, len(cfg.Rules))
+		return nil
+	}
+
+	var framer frame.Framer
+	switch framing {
+	case "line":
+		framer = frame.NewLineFramer(0)
+	case "crlf":
+		framer = frame.NewCRLFFramer(0)
+	default:
+		return fmt.Errorf("unknown framing %q", framing)
+	}
+
+	r := router.NewRouter()
+	if explain {
+		r.Use(explainMiddleware)
+	}
+	if !strict {
+		r.Use(logErrorsMiddleware)
+	}
+	// 插件的路由排在规则之前，否则会被匹配所有消息的规则覆盖
+	for _, p := range plugins {
+		if err := p.RegisterRoutes(r); err != nil {
+			return err
+		}
+	}
+	sinks, err := cfg.Apply(r)
+	if err != nil {
+		return err
+	}
+	defer sinks.Close()
+
+	if graph != "" {
+		return exportGraph(r, graph)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if listen == "" {
+		err := r.RouteStream(ctx, os.Stdin, framer)
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		return err
+	}
+	return serve(ctx, r, listen, framer)
+}
+
+// exportGraph 把路由拓扑输出到标准输出
+func exportGraph(r router.Router, format string) error {
+	switch format {
+	case "dot":
+		return router.ExportGraph(os.Stdout, r, router.GraphDOT)
+	case "json":
+		return router.ExportGraph(os.Stdout, r, router.GraphJSON)
+	default:
+		return fmt.Errorf("unknown graph format %q", format)
+	}
+}
+
+// serve 在listen指定的地址上接收消息，直到收到退出信号
+func serve(ctx context.Context, r router.Router, listen string, framer frame.Framer) error {
+	network, addr, ok := strings.Cut(listen, "://")
+	if !ok {
+		return fmt.Errorf("invalid listen address %q, expected network://address", listen)
+	}
+
+	type server interface {
+		ListenAndServe(addr string) error
+		Shutdown(ctx context.Context) error
+	}
+	var srv server
+	switch network {
+	case "tcp":
+		srv = transport.NewTCPServer(r, framer)
+	case "unix":
+		srv = transport.NewUnixServer(r, framer)
+	case "udp":
+		srv = transport.NewUDPServer(r)
+	default:
+		return fmt.Errorf("unsupported network %q", network)
+	}
+
+	go func() {
+		<-ctx.Done()
+		srv.Shutdown(context.Background())
+	}()
+	log.Printf("listening on %s", listen)
+	if err := srv.ListenAndServe(addr); !errors.Is(err, transport.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// explainMiddleware 把消息匹配的规则名称输出到标准错误，没有匹配时输出"-"
+func explainMiddleware(ctx router_context.Context, next router.HandlerFunc) error {
+	err := next(ctx)
+	name := "-"
+	if info := ctx.Route(); info != nil {
+		name = info.Name
+	}
+	fmt.Fprintf(os.Stderr, "%s\t%s\n", name, ctx.Buffer().Get())
+	return err
+}
+
+// logErrorsMiddleware 记录处理失败的消息并继续处理后续消息
+func logErrorsMiddleware(ctx router_context.Context, next router.HandlerFunc) error {
+	if err := next(ctx); err != nil {
+		name := ""
+		if info := ctx.Route(); info != nil {
+			name = info.Name
+		}
+		log.Printf("rule %q: %v", name, err)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/aomirun/content-router/buffer"
 	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
 	"github.com/aomirun/content-router/manage"
 	"github.com/aomirun/content-router/router"
 )
@@ -15,6 +16,15 @@ type Router = router.Router
 // RouteHandler 定义路由处理器接口
 type RouteHandler = router.RouteHandler
 
+// RouteResponder 定义请求/应答路由接口
+type RouteResponder = router.RouteResponder
+
+// StreamRouter 定义流式路由接口
+type StreamRouter = router.StreamRouter
+
+// ConnServer 定义连接服务接口
+type ConnServer = router.ConnServer
+
 // RouteRegistrar 定义路由注册接口
 type RouteRegistrar = router.RouteRegistrar
 
@@ -30,12 +40,32 @@ type ContextCreator = router.ContextCreator
 // BufferManagerAccessor 定义缓冲区管理器访问接口
 type BufferManagerAccessor = router.BufferManagerAccessor
 
+// ContextManagerAccessor 定义上下文管理器访问接口
+type ContextManagerAccessor = router.ContextManagerAccessor
+
+// RouteInspector 定义路由拓扑查询接口
+type RouteInspector = router.RouteInspector
+
+// HealthChecker 定义健康检查接口
+type HealthChecker = router.HealthChecker
+
 // Context 定义增强的上下文接口
 type Context = router_context.Context
 
 // ValueStore 定义键值存储接口
 type ValueStore = router_context.ValueStore
 
+// RouteInfo 描述匹配到的路由
+type RouteInfo = router_context.RouteInfo
+
+// ContextKey 定义带命名空间的上下文键
+type ContextKey = router_context.ContextKey
+
+// NewContextKey 创建一个带命名空间的上下文键
+func NewContextKey(namespace, name string) ContextKey {
+	return router_context.NewKey(namespace, name)
+}
+
 // BufferAccessor 定义缓冲区访问接口
 type BufferAccessor = router_context.BufferAccessor
 
@@ -57,9 +87,18 @@ type Sliceable = buffer.Sliceable
 // Cloneable 定义可克隆缓冲区接口
 type Cloneable = buffer.Cloneable
 
+// BufferOption 定义缓冲区和对象池的配置选项
+type BufferOption = buffer.Option
+
+// RefCounted 定义引用计数缓冲区接口
+type RefCounted = buffer.RefCounted
+
 // BufferManager 定义缓冲区管理接口
 type BufferManager = manage.BufferManager
 
+// ContextManager 定义上下文管理接口
+type ContextManager = manage.ContextManager
+
 // Handler 定义处理函数接口
 type Handler = router.Handler
 
@@ -78,17 +117,42 @@ type MiddlewareFunc = router.MiddlewareFunc
 // Pipeline 定义责任链管道接口
 type Pipeline = router.Pipeline
 
+// Framer 定义从字节流中提取消息帧的接口
+type Framer = frame.Framer
+
+// FramerFunc 是函数形式的Framer
+type FramerFunc = frame.FramerFunc
+
 // ObjectPool 定义通用对象池接口
 type ObjectPool[T any] = buffer.ObjectPool[T]
 
+// RouterOption 定义路由器的配置选项
+type RouterOption = router.Option
+
 // NewRouter 创建一个新的路由器实例
-func NewRouter() Router {
-	return router.NewRouter()
+func NewRouter(opts ...RouterOption) Router {
+	return router.NewRouter(opts...)
 }
 
 // NewBuffer 创建一个新的缓冲区实例
-func NewBuffer() Buffer {
-	return buffer.NewBuffer()
+// 缓冲区不来自对象池，不需要释放
+func NewBuffer(opts ...BufferOption) Buffer {
+	return buffer.NewBuffer(opts...)
+}
+
+// AcquireBuffer 从manage.Default()获取一个缓冲区，使用完毕后必须通过ReleaseBuffer归还
+func AcquireBuffer() Buffer {
+	return manage.Default().Acquire()
+}
+
+// ReleaseBuffer 将AcquireBuffer获取的缓冲区归还到manage.Default()
+func ReleaseBuffer(buf Buffer) {
+	manage.Default().Release(buf)
+}
+
+// SetDefaultBufferManager 设置包级默认的缓冲区管理器，应在程序启动时调用
+func SetDefaultBufferManager(manager BufferManager) {
+	manage.SetDefault(manager)
 }
 
 // NewContext 创建一个新的上下文实例
@@ -30,4 +30,24 @@ func TestContentRouterAPI(t *testing.T) {
 	var _ contentrouter.Router = router
 	var _ contentrouter.Buffer = buf
 	var _ contentrouter.Context = ctx
-}
\ No newline at end of file
+}
+
+func TestDefaultBufferManager(t *testing.T) {
+	manager := contentrouter.NewRouter().BufferManager()
+	before := manager.Stats()
+
+	buf := contentrouter.AcquireBuffer()
+	buf.WriteString("data")
+	contentrouter.ReleaseBuffer(buf)
+
+	after := manager.Stats()
+	if after.Acquired != before.Acquired+1 || after.Released != before.Released+1 {
+		t.Errorf("Expected AcquireBuffer to use the default manager, got %+v -> %+v", before, after)
+	}
+
+	// NewBuffer不来自默认管理器，不需要归还
+	contentrouter.NewBuffer().WriteString("data")
+	if stats := manager.Stats(); stats.Acquired != after.Acquired {
+		t.Errorf("Expected NewBuffer to bypass the default manager, got %+v", stats)
+	}
+}
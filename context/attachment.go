@@ -0,0 +1,36 @@
+package context
+
+import "github.com/aomirun/content-router/buffer"
+
+// SetAttachment 设置命名的附加缓冲区，buf为nil时删除该附加缓冲区
+func (c *contextImpl) SetAttachment(name string, buf buffer.Buffer) {
+	c.lock()
+	defer c.unlock()
+	if buf == nil {
+		delete(c.attachments, name)
+		return
+	}
+	if c.attachments == nil {
+		c.attachments = make(map[string]buffer.Buffer)
+	}
+	c.attachments[name] = buf
+}
+
+// Attachment 获取命名的附加缓冲区
+func (c *contextImpl) Attachment(name string) (buffer.Buffer, bool) {
+	c.rlock()
+	defer c.runlock()
+	buf, ok := c.attachments[name]
+	return buf, ok
+}
+
+// AttachmentNames 获取所有附加缓冲区的名称
+func (c *contextImpl) AttachmentNames() []string {
+	c.rlock()
+	defer c.runlock()
+	names := make([]string, 0, len(c.attachments))
+	for name := range c.attachments {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,67 @@
+package context
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+func TestContextAttachments(t *testing.T) {
+	ctx := NewContext(context.Background(), buffer.NewBuffer())
+
+	if _, ok := ctx.Attachment("body"); ok {
+		t.Error("Attachment should report missing attachments")
+	}
+
+	header := buffer.NewBuffer()
+	header.WriteString("v1")
+	body := buffer.NewBuffer()
+	body.WriteString("payload")
+	ctx.SetAttachment("header", header)
+	ctx.SetAttachment("body", body)
+
+	if got, ok := ctx.Attachment("body"); !ok || string(got.Get()) != "payload" {
+		t.Errorf("Attachment(body) returned %v, %v", got, ok)
+	}
+	names := ctx.AttachmentNames()
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "body" || names[1] != "header" {
+		t.Errorf("AttachmentNames returned %v", names)
+	}
+
+	ctx.SetAttachment("header", nil)
+	if _, ok := ctx.Attachment("header"); ok {
+		t.Error("SetAttachment(nil) should remove the attachment")
+	}
+
+	ctx.(*contextImpl).Reset()
+	if len(ctx.AttachmentNames()) != 0 {
+		t.Error("Reset should clear attachments")
+	}
+}
+
+func TestContextAttachmentsForkAndCopy(t *testing.T) {
+	ctx := NewContext(context.Background(), buffer.NewBuffer())
+	body := buffer.NewBuffer()
+	body.WriteString("payload")
+	ctx.SetAttachment("body", body)
+
+	fork := ctx.Fork()
+	cp := ctx.Copy()
+
+	// 分支的修改不影响原上下文的附加缓冲区集合
+	fork.SetAttachment("extra", buffer.NewBuffer())
+	if _, ok := ctx.Attachment("extra"); ok {
+		t.Error("Fork should not share the attachment map")
+	}
+
+	body.WriteString("-more")
+	if got, _ := fork.Attachment("body"); string(got.Get()) != "payload-more" {
+		t.Errorf("Fork should share attachment buffers, got %q", got.Get())
+	}
+	if got, _ := cp.Attachment("body"); string(got.Get()) != "payload" {
+		t.Errorf("Copy should clone attachment buffers, got %q", got.Get())
+	}
+}
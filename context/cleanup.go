@@ -0,0 +1,30 @@
+package context
+
+// OnReset 注册清理回调，上下文被重置或释放时按注册的逆序执行
+// 中间件可以借此清理与本次分发绑定的资源（临时文件、解码器状态、子缓冲区等）
+//
+// 回调只属于当前上下文，不会传播到Fork、ForkWithBuffer或Copy创建的副本
+func (c *contextImpl) OnReset(fn func()) {
+	if fn == nil {
+		return
+	}
+	c.lock()
+	c.cleanups = append(c.cleanups, fn)
+	c.unlock()
+}
+
+// RunCleanups 按注册的逆序执行并清除上下文的清理回调
+// Reset会自动调用它；不复用上下文的ContextManager在释放时调用它，保证回调总会执行
+func RunCleanups(ctx Context) {
+	c, ok := ctx.(*contextImpl)
+	if !ok {
+		return
+	}
+	c.lock()
+	cleanups := c.cleanups
+	c.cleanups = nil
+	c.unlock()
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
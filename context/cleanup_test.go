@@ -0,0 +1,49 @@
+package context
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextOnReset(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	ctx.Set("file", "tmp-1")
+
+	var order []string
+	ctx.OnReset(func() {
+		// 清理回调执行时上下文的值仍然可用
+		name, _ := ctx.GetString("file")
+		order = append(order, "first:"+name)
+	})
+	ctx.OnReset(func() { order = append(order, "second") })
+	ctx.OnReset(nil)
+
+	ctx.(*contextImpl).Reset()
+	if len(order) != 2 || order[0] != "second" || order[1] != "first:tmp-1" {
+		t.Errorf("Cleanups ran as %v, expected [second first:tmp-1]", order)
+	}
+}
+
+func TestRunCleanups(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	calls := 0
+	ctx.OnReset(func() { calls++ })
+
+	RunCleanups(ctx)
+	RunCleanups(ctx)
+	if calls != 1 {
+		t.Errorf("Cleanup ran %d times, expected 1", calls)
+	}
+
+	// 回调不会传播到副本
+	ctx.OnReset(func() { calls++ })
+	fork := ctx.Fork()
+	RunCleanups(fork)
+	if calls != 1 {
+		t.Error("Fork should not inherit cleanups")
+	}
+	ctx.(*contextImpl).Reset()
+	if calls != 2 {
+		t.Errorf("Reset should run pending cleanups, got %d calls", calls)
+	}
+}
@@ -2,6 +2,7 @@ package context
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -13,6 +14,31 @@ type contextImpl struct {
 	context.Context
 	buffer buffer.Buffer
 	values map[interface{}]interface{}
+	params map[string]string
+	route  *RouteInfo
+	// traceID 是追踪ID，为空时使用父上下文携带的值
+	traceID string
+
+	// metadata 是传输层元数据，为nil时使用父上下文携带的值
+	metadata *Metadata
+	abort    error
+	errors   []error
+
+	attachments map[string]buffer.Buffer
+
+	// cleanups 是OnReset注册的清理回调
+	cleanups []func()
+
+	// response 是延迟获取的响应缓冲区，provider为其来源
+	response buffer.Buffer
+	provider BufferProvider
+
+	// detached 为true时上下文不来自对象池，Reset不会将其放回对象池
+	detached bool
+
+	// safe 为true时所有键值操作都由mu保护
+	safe bool
+	mu   sync.RWMutex
 }
 
 // contextPool 是contextImpl的对象池
@@ -45,95 +71,157 @@ func NewContext(parent context.Context, buf buffer.Buffer) Context {
 
 // Reset 重置上下文，将其放回对象池
 func (c *contextImpl) Reset() {
+	// 清理回调可能还需要访问上下文，因此最先执行
+	RunCleanups(c)
 	// 清空values map
 	for k := range c.values {
 		delete(c.values, k)
 	}
+	for k := range c.params {
+		delete(c.params, k)
+	}
 	c.buffer = nil
+	c.route = nil
+	c.traceID = ""
+	c.metadata = nil
+	c.abort = nil
+	clear(c.attachments)
+	clear(c.errors)
+	c.errors = c.errors[:0]
+	ReleaseResponse(c)
+	c.provider = nil
 	c.Context = nil
-	contextPool.Put(c)
+	c.safe = false
+	if !c.detached {
+		contextPool.Put(c)
+	}
 }
 
 // Set 设置键值对
 func (c *contextImpl) Set(key, value interface{}) {
+	c.lock()
 	c.values[key] = value
+	c.unlock()
 }
 
 // Get 获取值
 func (c *contextImpl) Get(key interface{}) interface{} {
-	return c.values[key]
+	val, _ := c.lookup(key)
+	return val
+}
+
+// GetAny 获取值，本地不存在时回退到父上下文
+func (c *contextImpl) GetAny(key interface{}) interface{} {
+	if val, ok := c.lookup(key); ok {
+		return val
+	}
+	if c.Context == nil {
+		return nil
+	}
+	return c.Context.Value(key)
+}
+
+// MustGet 获取值，键不存在时panic
+func (c *contextImpl) MustGet(key interface{}) interface{} {
+	val, ok := c.lookup(key)
+	if !ok {
+		panic(fmt.Sprintf("context: key %v does not exist", key))
+	}
+	return val
+}
+
+// GetOrDefault 获取值，键不存在时返回默认值
+func (c *contextImpl) GetOrDefault(key, def interface{}) interface{} {
+	if val, ok := c.lookup(key); ok {
+		return val
+	}
+	return def
+}
+
+// GetOrSet 获取值，键不存在时调用factory创建值并保存
+// 线程安全模式下factory在持有写锁时调用，保证只创建一次
+func (c *contextImpl) GetOrSet(key interface{}, factory func() interface{}) interface{} {
+	c.lock()
+	val, ok := c.values[key]
+	if !ok {
+		val = factory()
+		c.values[key] = val
+	}
+	c.unlock()
+	return c.resolve(key, val)
 }
 
 // GetString 获取字符串值
 func (c *contextImpl) GetString(key interface{}) (string, bool) {
-	val := c.Get(key)
-	if str, ok := val.(string); ok {
-		return str, true
-	}
-	return "", false
+	return GetAs[string](c, key)
 }
 
 // GetInt 获取整数值
 func (c *contextImpl) GetInt(key interface{}) (int, bool) {
-	val := c.Get(key)
-	if i, ok := val.(int); ok {
-		return i, true
-	}
-	return 0, false
+	return GetAs[int](c, key)
 }
 
 // GetInt64 获取64位整数值
 func (c *contextImpl) GetInt64(key interface{}) (int64, bool) {
-	val := c.Get(key)
-	if i, ok := val.(int64); ok {
-		return i, true
-	}
-	return 0, false
+	return GetAs[int64](c, key)
 }
 
 // GetBool 获取布尔值
 func (c *contextImpl) GetBool(key interface{}) (bool, bool) {
-	val := c.Get(key)
-	if b, ok := val.(bool); ok {
-		return b, true
-	}
-	return false, false
+	return GetAs[bool](c, key)
 }
 
 // GetFloat64 获取浮点数值
 func (c *contextImpl) GetFloat64(key interface{}) (float64, bool) {
-	val := c.Get(key)
-	if f, ok := val.(float64); ok {
-		return f, true
-	}
-	return 0.0, false
+	return GetAs[float64](c, key)
 }
 
 // GetBytes 获取字节数组
 func (c *contextImpl) GetBytes(key interface{}) ([]byte, bool) {
-	val := c.Get(key)
-	if b, ok := val.([]byte); ok {
-		return b, true
-	}
-	return nil, false
+	return GetAs[[]byte](c, key)
 }
 
 // GetTime 获取时间值
 func (c *contextImpl) GetTime(key interface{}) (time.Time, bool) {
-	val := c.Get(key)
-	if t, ok := val.(time.Time); ok {
-		return t, true
-	}
-	return time.Time{}, false
+	return GetAs[time.Time](c, key)
+}
+
+// GetDuration 获取时间间隔值
+func (c *contextImpl) GetDuration(key interface{}) (time.Duration, bool) {
+	return GetAs[time.Duration](c, key)
+}
+
+// GetUint 获取无符号整数值
+func (c *contextImpl) GetUint(key interface{}) (uint, bool) {
+	return GetAs[uint](c, key)
+}
+
+// GetUint64 获取64位无符号整数值
+func (c *contextImpl) GetUint64(key interface{}) (uint64, bool) {
+	return GetAs[uint64](c, key)
+}
+
+// GetStringSlice 获取字符串切片
+func (c *contextImpl) GetStringSlice(key interface{}) ([]string, bool) {
+	return GetAs[[]string](c, key)
+}
+
+// GetStringMap 获取字符串映射
+func (c *contextImpl) GetStringMap(key interface{}) (map[string]string, bool) {
+	return GetAs[map[string]string](c, key)
 }
 
 // Delete 删除键值对
 func (c *contextImpl) Delete(key interface{}) {
+	c.lock()
 	delete(c.values, key)
+	c.unlock()
 }
 
 // Keys 获取所有键
 func (c *contextImpl) Keys() []interface{} {
+	c.rlock()
+	defer c.runlock()
 	keys := make([]interface{}, 0, len(c.values))
 	for k := range c.values {
 		keys = append(keys, k)
@@ -141,6 +229,51 @@ func (c *contextImpl) Keys() []interface{} {
 	return keys
 }
 
+// Route 获取匹配到的路由信息
+func (c *contextImpl) Route() *RouteInfo {
+	return c.route
+}
+
+// SetRoute 设置匹配到的路由信息
+func (c *contextImpl) SetRoute(info *RouteInfo) {
+	c.route = info
+}
+
+// Abort 终止后续处理
+func (c *contextImpl) Abort(err error) {
+	if err == nil {
+		err = ErrAborted
+	}
+	c.abort = err
+}
+
+// IsAborted 判断处理是否已被终止
+func (c *contextImpl) IsAborted() bool {
+	return c.abort != nil
+}
+
+// AbortError 获取终止原因
+func (c *contextImpl) AbortError() error {
+	return c.abort
+}
+
+// AddError 记录一个非致命错误
+func (c *contextImpl) AddError(err error) {
+	if err == nil {
+		return
+	}
+	c.lock()
+	c.errors = append(c.errors, err)
+	c.unlock()
+}
+
+// Errors 获取已记录的错误
+func (c *contextImpl) Errors() []error {
+	c.rlock()
+	defer c.runlock()
+	return c.errors
+}
+
 // Buffer 获取与上下文关联的缓冲区
 func (c *contextImpl) Buffer() buffer.Buffer {
 	return c.buffer
@@ -148,30 +281,73 @@ func (c *contextImpl) Buffer() buffer.Buffer {
 
 // Fork 创建上下文的副本，但共享相同的缓冲区
 func (c *contextImpl) Fork() Context {
-	// 复制values map
-	values := make(map[interface{}]interface{}, len(c.values))
-	for k, v := range c.values {
-		values[k] = v
-	}
-
-	return &contextImpl{
-		Context: c.Context,
-		buffer:  c.buffer,
-		values:  values,
-	}
+	return c.fork(c.buffer)
 }
 
 // ForkWithBuffer 创建上下文的副本，并使用新的缓冲区
 func (c *contextImpl) ForkWithBuffer(buf buffer.Buffer) Context {
+	return c.fork(buf)
+}
+
+// fork 创建上下文的副本，副本不来自对象池，线程安全模式会被继承
+// 副本没有路由器负责释放响应缓冲区，因此不继承BufferProvider，Response()使用buffer.NewBuffer()
+func (c *contextImpl) fork(buf buffer.Buffer) *contextImpl {
+	c.rlock()
+	defer c.runlock()
+
 	// 复制values map
 	values := make(map[interface{}]interface{}, len(c.values))
 	for k, v := range c.values {
 		values[k] = v
 	}
 
+	var attachments map[string]buffer.Buffer
+	if len(c.attachments) > 0 {
+		attachments = make(map[string]buffer.Buffer, len(c.attachments))
+		for k, v := range c.attachments {
+			attachments[k] = v
+		}
+	}
+
+	var params map[string]string
+	if len(c.params) > 0 {
+		params = make(map[string]string, len(c.params))
+		for k, v := range c.params {
+			params[k] = v
+		}
+	}
+
 	return &contextImpl{
-		Context: c.Context,
-		buffer:  buf,
-		values:  values,
+		Context:     c.Context,
+		buffer:      buf,
+		values:      values,
+		params:      params,
+		route:       c.route,
+		attachments: attachments,
+		traceID:     c.traceID,
+		metadata:    c.metadata,
+		errors:      append([]error(nil), c.errors...),
+		detached:    true,
+		safe:        c.safe,
+	}
+}
+
+// Copy 创建与原上下文完全分离的副本
+// 值映射被复制，[]byte值、缓冲区和附加缓冲区被克隆
+// 副本与原上下文共享父上下文，父上下文被取消时副本同样会被取消
+func (c *contextImpl) Copy() Context {
+	var buf buffer.Buffer
+	if c.buffer != nil {
+		buf = c.buffer.Clone()
+	}
+	cp := c.fork(buf)
+	for k, v := range cp.values {
+		if b, ok := v.([]byte); ok {
+			cp.values[k] = append([]byte(nil), b...)
+		}
+	}
+	for name, att := range cp.attachments {
+		cp.attachments[name] = att.Clone()
 	}
+	return cp
 }
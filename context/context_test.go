@@ -2,6 +2,7 @@ package context
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -120,6 +121,84 @@ func TestContextImpl(t *testing.T) {
 	}
 }
 
+func TestContextExtendedGetters(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+
+	ctx.Set("budget", 250*time.Millisecond)
+	ctx.Set("count", uint(7))
+	ctx.Set("offset", uint64(1<<40))
+	ctx.Set("tags", []string{"a", "b"})
+	ctx.Set("labels", map[string]string{"env": "prod"})
+
+	if val, ok := ctx.GetDuration("budget"); !ok || val != 250*time.Millisecond {
+		t.Errorf("GetDuration(budget) returned %v, %v, expected 250ms, true", val, ok)
+	}
+	if val, ok := ctx.GetUint("count"); !ok || val != 7 {
+		t.Errorf("GetUint(count) returned %v, %v, expected 7, true", val, ok)
+	}
+	if val, ok := ctx.GetUint64("offset"); !ok || val != 1<<40 {
+		t.Errorf("GetUint64(offset) returned %v, %v, expected %d, true", val, ok, uint64(1<<40))
+	}
+	if val, ok := ctx.GetStringSlice("tags"); !ok || len(val) != 2 || val[1] != "b" {
+		t.Errorf("GetStringSlice(tags) returned %v, %v, expected [a b], true", val, ok)
+	}
+	if val, ok := ctx.GetStringMap("labels"); !ok || val["env"] != "prod" {
+		t.Errorf("GetStringMap(labels) returned %v, %v, expected map[env:prod], true", val, ok)
+	}
+
+	// 错误类型
+	if val, ok := ctx.GetUint("offset"); ok || val != 0 {
+		t.Errorf("GetUint(offset) returned %v, %v, expected 0, false", val, ok)
+	}
+	if val, ok := ctx.GetDuration("count"); ok || val != 0 {
+		t.Errorf("GetDuration(count) returned %v, %v, expected 0, false", val, ok)
+	}
+}
+
+func TestContextMustGetAndDefaults(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	ctx.Set("present", "value")
+	ctx.Set("nil", nil)
+
+	if val := ctx.MustGet("present"); val != "value" {
+		t.Errorf("MustGet(present) returned %v, expected value", val)
+	}
+	// 值为nil的键仍然视为存在
+	if val := ctx.MustGet("nil"); val != nil {
+		t.Errorf("MustGet(nil) returned %v, expected nil", val)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("MustGet(missing) should panic")
+			}
+		}()
+		ctx.MustGet("missing")
+	}()
+
+	if val := ctx.GetOrDefault("missing", 10); val != 10 {
+		t.Errorf("GetOrDefault(missing) returned %v, expected 10", val)
+	}
+	if val := ctx.GetOrDefault("present", 10); val != "value" {
+		t.Errorf("GetOrDefault(present) returned %v, expected value", val)
+	}
+
+	calls := 0
+	factory := func() interface{} {
+		calls++
+		return []string{"created"}
+	}
+	first := ctx.GetOrSet("lazy", factory)
+	second := ctx.GetOrSet("lazy", factory)
+	if calls != 1 {
+		t.Errorf("GetOrSet called factory %d times, expected 1", calls)
+	}
+	if first.([]string)[0] != "created" || second.([]string)[0] != "created" {
+		t.Errorf("GetOrSet returned %v and %v, expected [created]", first, second)
+	}
+}
+
 func TestContextFork(t *testing.T) {
 	// 创建一个buffer
 	buf := buffer.NewBuffer()
@@ -299,3 +378,159 @@ func TestNewContextClearValuesMap(t *testing.T) {
 		t.Errorf("New context from pool should have empty values map, got %d keys", len(ctx.Keys()))
 	}
 }
+
+func TestContextParams(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	if ctx.Param("id") != "" || len(ctx.Params()) != 0 {
+		t.Error("New context should have no params")
+	}
+
+	ctx.SetParam("id", "42")
+	ctx.Set("id", "value")
+	if ctx.Param("id") != "42" {
+		t.Errorf("Param(id) returned %q, expected 42", ctx.Param("id"))
+	}
+	// 匹配参数与普通键值相互独立
+	if val, _ := ctx.GetString("id"); val != "value" {
+		t.Errorf("GetString(id) returned %q, expected value", val)
+	}
+
+	fork := ctx.Fork()
+	fork.SetParam("id", "43")
+	if ctx.Param("id") != "42" || fork.Param("id") != "43" {
+		t.Error("Fork should copy params")
+	}
+
+	ctx.(*contextImpl).Reset()
+	reused := NewContext(context.Background(), nil)
+	if len(reused.Params()) != 0 {
+		t.Error("Pooled context should not keep params")
+	}
+}
+
+func TestContextRouteInfo(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	if ctx.Route() != nil {
+		t.Error("New context should have no route")
+	}
+
+	info := &RouteInfo{Name: "orders", Pattern: "ORD"}
+	ctx.SetRoute(info)
+	if ctx.Route() != info {
+		t.Error("Route should return the info set by SetRoute")
+	}
+	if ctx.Fork().Route() != info {
+		t.Error("Fork should keep the matched route")
+	}
+}
+
+func TestContextAbort(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	if ctx.IsAborted() || ctx.AbortError() != nil {
+		t.Error("New context should not be aborted")
+	}
+
+	ctx.Abort(nil)
+	if !ctx.IsAborted() || ctx.AbortError() != ErrAborted {
+		t.Errorf("Abort(nil) should use ErrAborted, got %v", ctx.AbortError())
+	}
+
+	ctx.(*contextImpl).Reset()
+	if NewContext(context.Background(), nil).IsAborted() {
+		t.Error("Pooled context should not stay aborted")
+	}
+}
+
+func TestContextErrors(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	if len(ctx.Errors()) != 0 {
+		t.Error("New context should have no errors")
+	}
+
+	warn1 := errors.New("missing optional field")
+	warn2 := errors.New("geo lookup failed")
+	ctx.AddError(warn1)
+	ctx.AddError(nil)
+	ctx.AddError(warn2)
+
+	errs := ctx.Errors()
+	if len(errs) != 2 || errs[0] != warn1 || errs[1] != warn2 {
+		t.Errorf("Errors returned %v, expected [%v %v]", errs, warn1, warn2)
+	}
+
+	fork := ctx.Fork()
+	fork.AddError(errors.New("branch failure"))
+	if len(ctx.Errors()) != 2 || len(fork.Errors()) != 3 {
+		t.Error("Fork should copy errors without sharing them")
+	}
+
+	ctx.(*contextImpl).Reset()
+	if len(NewContext(context.Background(), nil).Errors()) != 0 {
+		t.Error("Pooled context should not keep errors")
+	}
+}
+
+func TestContextCopy(t *testing.T) {
+	buf := buffer.NewBuffer()
+	buf.WriteString("payload")
+	ctx := NewContext(context.Background(), buf)
+	raw := []byte("raw")
+	ctx.Set("raw", raw)
+	ctx.Set("id", 7)
+	ctx.SetParam("topic", "orders")
+
+	cp := ctx.Copy()
+
+	// 原上下文重置并被复用后，副本仍然有效
+	ctx.(*contextImpl).Reset()
+	buf.Reset()
+	buf.WriteString("reused")
+	raw[0] = 'X'
+	NewContext(context.Background(), buffer.NewBuffer()).Set("id", 99)
+
+	if string(cp.Buffer().Get()) != "payload" {
+		t.Errorf("Copy should clone the buffer, got %q", cp.Buffer().Get())
+	}
+	if val, _ := cp.GetBytes("raw"); string(val) != "raw" {
+		t.Errorf("Copy should clone []byte values, got %q", val)
+	}
+	if val, _ := cp.GetInt("id"); val != 7 {
+		t.Errorf("Copy should copy values, got %d", val)
+	}
+	if cp.Param("topic") != "orders" {
+		t.Errorf("Copy should copy params, got %q", cp.Param("topic"))
+	}
+
+	// 副本不参与对象池复用
+	cp.(*contextImpl).Reset()
+	if !cp.(*contextImpl).detached {
+		t.Error("Copy should be detached from the pool")
+	}
+}
+
+func TestContextGetAny(t *testing.T) {
+	type parentKey struct{}
+	parent := context.WithValue(context.Background(), parentKey{}, "from-parent")
+	ctx := NewContext(parent, nil)
+	ctx.Set("local", "from-local")
+
+	if val := ctx.GetAny("local"); val != "from-local" {
+		t.Errorf("GetAny(local) returned %v, expected from-local", val)
+	}
+	if val := ctx.GetAny(parentKey{}); val != "from-parent" {
+		t.Errorf("GetAny(parentKey) returned %v, expected from-parent", val)
+	}
+	// Get只查找本地存储
+	if val := ctx.Get(parentKey{}); val != nil {
+		t.Errorf("Get(parentKey) returned %v, expected nil", val)
+	}
+	if val := ctx.GetAny("missing"); val != nil {
+		t.Errorf("GetAny(missing) returned %v, expected nil", val)
+	}
+
+	// 本地值优先于父上下文
+	ctx.Set(parentKey{}, "override")
+	if val := ctx.GetAny(parentKey{}); val != "override" {
+		t.Errorf("GetAny(parentKey) returned %v, expected override", val)
+	}
+}
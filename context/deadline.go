@@ -0,0 +1,26 @@
+package context
+
+import "time"
+
+// RemainingTime 获取距离截止时间的剩余时间
+// 没有截止时间时返回false；截止时间已过时返回0
+func (c *contextImpl) RemainingTime() (time.Duration, bool) {
+	if c.Context == nil {
+		return 0, false
+	}
+	deadline, ok := c.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return max(time.Until(deadline), 0), true
+}
+
+// MustDeadline 获取截止时间，没有截止时间时panic
+func (c *contextImpl) MustDeadline() time.Time {
+	if c.Context != nil {
+		if deadline, ok := c.Deadline(); ok {
+			return deadline
+		}
+	}
+	panic("context: context has no deadline")
+}
@@ -0,0 +1,45 @@
+package context
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestContextRemainingTime(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	if _, ok := ctx.RemainingTime(); ok {
+		t.Error("RemainingTime should report false without a deadline")
+	}
+
+	parent, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	ctx = NewContext(parent, nil)
+	remaining, ok := ctx.RemainingTime()
+	if !ok || remaining <= 0 || remaining > time.Hour {
+		t.Errorf("RemainingTime returned %v, %v", remaining, ok)
+	}
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	ctx = NewContext(expired, nil)
+	if remaining, ok := ctx.RemainingTime(); !ok || remaining != 0 {
+		t.Errorf("RemainingTime after the deadline returned %v, %v, expected 0, true", remaining, ok)
+	}
+}
+
+func TestContextMustDeadline(t *testing.T) {
+	deadline := time.Now().Add(time.Minute)
+	parent, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	if got := NewContext(parent, nil).MustDeadline(); !got.Equal(deadline) {
+		t.Errorf("MustDeadline returned %v, expected %v", got, deadline)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustDeadline should panic without a deadline")
+		}
+	}()
+	NewContext(context.Background(), nil).MustDeadline()
+}
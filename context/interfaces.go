@@ -2,11 +2,15 @@ package context
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/aomirun/content-router/buffer"
 )
 
+// ErrAborted 表示处理被Abort(nil)终止
+var ErrAborted = errors.New("context: processing aborted")
+
 // ValueStore 定义键值存储接口
 type ValueStore interface {
 	// Set 设置键值对
@@ -15,6 +19,19 @@ type ValueStore interface {
 	// Get 获取值
 	Get(key interface{}) interface{}
 
+	// GetAny 获取值，本地不存在时回退到父上下文的Value(key)
+	// Get只查找本地存储，而Value只查找父上下文链，GetAny同时覆盖两者
+	GetAny(key interface{}) interface{}
+
+	// MustGet 获取值，键不存在时panic
+	MustGet(key interface{}) interface{}
+
+	// GetOrDefault 获取值，键不存在时返回默认值
+	GetOrDefault(key, def interface{}) interface{}
+
+	// GetOrSet 获取值，键不存在时调用factory创建值并保存
+	GetOrSet(key interface{}, factory func() interface{}) interface{}
+
 	// GetString 获取字符串值
 	GetString(key interface{}) (string, bool)
 
@@ -36,11 +53,113 @@ type ValueStore interface {
 	// GetTime 获取时间值
 	GetTime(key interface{}) (time.Time, bool)
 
+	// GetDuration 获取时间间隔值
+	GetDuration(key interface{}) (time.Duration, bool)
+
+	// GetUint 获取无符号整数值
+	GetUint(key interface{}) (uint, bool)
+
+	// GetUint64 获取64位无符号整数值
+	GetUint64(key interface{}) (uint64, bool)
+
+	// GetStringSlice 获取字符串切片
+	GetStringSlice(key interface{}) ([]string, bool)
+
+	// GetStringMap 获取字符串映射，例如标签集合
+	GetStringMap(key interface{}) (map[string]string, bool)
+
+	// SetLazy 设置延迟计算的值，fn在第一次获取该键时才执行，结果会被缓存
+	SetLazy(key interface{}, fn func() interface{})
+
 	// Delete 删除键值对
 	Delete(key interface{})
 
-	// Keys 获取所有键
+	// Keys 获取所有键，顺序不确定；需要稳定顺序时使用SortedKeys
 	Keys() []interface{}
+
+	// Range 遍历所有键值对，fn返回false时停止遍历
+	// 与Keys不同，非线程安全模式下遍历不需要分配键切片；fn中可以读写存储，
+	// 遍历期间新增的键是否会被访问不确定
+	Range(fn func(key, value interface{}) bool)
+}
+
+// ParamStore 定义匹配参数访问接口
+// 参数由捕获型匹配器（正则命名分组、模板占位符）写入，
+// 与ValueStore中的普通键值相互独立，不会发生键冲突
+type ParamStore interface {
+	// Param 获取匹配参数，不存在时返回空字符串
+	Param(name string) string
+
+	// Params 获取所有匹配参数，返回的映射不能修改
+	Params() map[string]string
+
+	// SetParam 设置匹配参数，供捕获型匹配器使用
+	SetParam(name, value string)
+}
+
+// RouteInfo 描述匹配到的路由
+// 由路由器在调用处理器之前写入上下文，日志和指标中间件可以据此按路由打标签
+type RouteInfo struct {
+	// Name 路由名称，未命名时为空
+	Name string `json:"name,omitempty"`
+	// Pattern 路由的匹配模式，自定义匹配器没有描述时为空
+	Pattern string `json:"pattern,omitempty"`
+	// Metadata 注册时附加的元数据，不能修改
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// RouteAccessor 定义匹配路由访问接口
+type RouteAccessor interface {
+	// Route 获取匹配到的路由信息，尚未匹配时返回nil
+	Route() *RouteInfo
+
+	// SetRoute 设置匹配到的路由信息，由路由器调用
+	SetRoute(info *RouteInfo)
+}
+
+// FlowController 定义处理流程控制接口
+type FlowController interface {
+	// Abort 终止后续处理，路由器和管道不再调用尚未执行的中间件和处理器
+	//  - err: 终止原因，作为Route的返回值；为nil时使用ErrAborted
+	Abort(err error)
+
+	// IsAborted 判断处理是否已被终止
+	IsAborted() bool
+
+	// AbortError 获取终止原因，未终止时返回nil
+	AbortError() error
+}
+
+// TraceCarrier 定义追踪ID访问接口
+// 追踪ID随Fork、ForkWithBuffer和Copy传播，
+// 使同一条消息在多次重新分发中的处理过程可以被串联起来
+type TraceCarrier interface {
+	// TraceID 获取追踪ID，没有时返回空字符串
+	TraceID() string
+
+	// SetTraceID 设置追踪ID
+	SetTraceID(id string)
+}
+
+// MetadataAccessor 定义传输层元数据访问接口
+// 元数据随Fork、ForkWithBuffer和Copy传播
+type MetadataAccessor interface {
+	// Metadata 获取传输层元数据，没有时返回零值
+	Metadata() Metadata
+
+	// SetMetadata 设置传输层元数据
+	SetMetadata(md Metadata)
+}
+
+// DeadlineAccessor 定义截止时间辅助接口
+// 关注时间预算的处理器无需每次都从Deadline()和time.Now()推导剩余时间
+type DeadlineAccessor interface {
+	// RemainingTime 获取距离截止时间的剩余时间
+	// 没有截止时间时返回false；截止时间已过时返回0
+	RemainingTime() (time.Duration, bool)
+
+	// MustDeadline 获取截止时间，没有截止时间时panic
+	MustDeadline() time.Time
 }
 
 // BufferAccessor 定义缓冲区访问接口
@@ -49,16 +168,82 @@ type BufferAccessor interface {
 	Buffer() buffer.Buffer
 }
 
+// ErrorCollector 定义非致命错误收集接口
+// 多阶段管道可以记录校验警告、部分补全失败等问题，而不终止处理
+type ErrorCollector interface {
+	// AddError 记录一个非致命错误，nil会被忽略
+	AddError(err error)
+
+	// Errors 获取已记录的错误，按记录顺序排列，返回的切片不能修改
+	Errors() []error
+}
+
+// AttachmentStore 定义命名附加缓冲区接口
+// 处理器可以把头部、正文和解码出的子帧作为独立的缓冲区在处理链中传递，
+// 而不必把[]byte塞进通用的键值存储
+//
+// 上下文不负责释放附加缓冲区，获取它们的一方负责释放
+type AttachmentStore interface {
+	// SetAttachment 设置命名的附加缓冲区，buf为nil时删除该附加缓冲区
+	SetAttachment(name string, buf buffer.Buffer)
+
+	// Attachment 获取命名的附加缓冲区
+	Attachment(name string) (buffer.Buffer, bool)
+
+	// AttachmentNames 获取所有附加缓冲区的名称
+	AttachmentNames() []string
+}
+
+// CleanupRegistrar 定义清理回调注册接口
+type CleanupRegistrar interface {
+	// OnReset 注册清理回调，上下文被重置或释放时按注册的逆序执行
+	// 打开了与本次分发绑定的资源的中间件可以借此保证资源被清理
+	OnReset(fn func())
+}
+
+// ResponseAccessor 定义响应缓冲区访问接口
+// 处理器把结果写入响应缓冲区，由请求/应答类传输层写回调用方
+type ResponseAccessor interface {
+	// Response 获取响应缓冲区，第一次调用时才从BufferProvider获取
+	// 响应缓冲区由路由器在分发完成后释放，处理器不能释放或继续持有它
+	Response() buffer.Buffer
+
+	// HasResponse 判断是否已经获取了响应缓冲区，不会触发获取
+	HasResponse() bool
+}
+
 // Context 定义增强的上下文接口
 // 它组合了标准context.Context、ValueStore和BufferAccessor接口
 type Context interface {
 	context.Context
 	ValueStore
+	ParamStore
+	RouteAccessor
+	FlowController
+	ErrorCollector
+	TraceCarrier
+	MetadataAccessor
+	DeadlineAccessor
 	BufferAccessor
+	AttachmentStore
+	CleanupRegistrar
+	ResponseAccessor
 
 	// Fork 创建上下文的副本，但共享相同的缓冲区
 	Fork() Context
 
 	// ForkWithBuffer 创建上下文的副本，并使用新的缓冲区
 	ForkWithBuffer(buffer buffer.Buffer) Context
+
+	// Scope 返回指定名称的作用域视图
+	// 作用域内的键与上下文中的其他键互不可见，值随上下文一起重置
+	Scope(name string) ScopedStore
+
+	// MergeFrom 把另一个上下文（通常是并行分支的Fork）中的值合并到当前上下文
+	// policy为nil时用来源的值覆盖当前值
+	MergeFrom(other Context, policy MergePolicy)
+
+	// Copy 创建与原上下文完全分离的副本，用于把上下文交给其他goroutine
+	// 副本复制所有值并克隆缓冲区，不参与对象池复用，原上下文被重置后仍然有效
+	Copy() Context
 }
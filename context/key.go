@@ -0,0 +1,75 @@
+package context
+
+// ContextKey 定义带命名空间的上下文键
+// 不同模块的中间件使用各自的命名空间，即使键名相同（例如"id"）也不会冲突
+// ContextKey可以比较，可以直接作为Set/Get的键
+type ContextKey struct {
+	namespace string
+	name      string
+}
+
+// NewKey 创建一个带命名空间的上下文键
+//   - namespace: 命名空间，建议使用模块的导入路径
+//   - name: 键名
+func NewKey(namespace, name string) ContextKey {
+	return ContextKey{namespace: namespace, name: name}
+}
+
+// Namespace 返回键的命名空间
+func (k ContextKey) Namespace() string {
+	return k.namespace
+}
+
+// Name 返回键名
+func (k ContextKey) Name() string {
+	return k.name
+}
+
+// String 返回"命名空间.键名"形式的描述
+func (k ContextKey) String() string {
+	if k.namespace == "" {
+		return k.name
+	}
+	return k.namespace + "." + k.name
+}
+
+// KeySpace 是一个命名空间下的键构造器
+// 每个包声明一个KeySpace，并通过它创建本包使用的所有键：
+//
+//	var keys = router_context.NewKeySpace("github.com/acme/auth")
+//	var UserKey = keys.Key("user")
+type KeySpace struct {
+	namespace string
+}
+
+// NewKeySpace 创建一个命名空间下的键构造器
+func NewKeySpace(namespace string) KeySpace {
+	return KeySpace{namespace: namespace}
+}
+
+// Key 在命名空间下创建一个键
+func (s KeySpace) Key(name string) ContextKey {
+	return NewKey(s.namespace, name)
+}
+
+// MigrateKeys 把旧的字符串键的值复制到新的ContextKey下，便于逐步迁移
+//   - store: 键值存储
+//   - mapping: 旧字符串键到新键的映射
+//   - removeLegacy: 为true时删除旧的字符串键；迁移期间仍有代码读取旧键时应为false
+//
+// 返回: 实际迁移的键数量
+func MigrateKeys(store ValueStore, mapping map[string]ContextKey, removeLegacy bool) int {
+	migrated := 0
+	for legacy, key := range mapping {
+		val := store.Get(legacy)
+		if val == nil {
+			continue
+		}
+		store.Set(key, val)
+		if removeLegacy {
+			store.Delete(legacy)
+		}
+		migrated++
+	}
+	return migrated
+}
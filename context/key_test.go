@@ -0,0 +1,59 @@
+package context
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextKeyNamespaces(t *testing.T) {
+	auth := NewKeySpace("github.com/acme/auth")
+	billing := NewKeySpace("github.com/acme/billing")
+
+	ctx := NewContext(context.Background(), nil)
+	ctx.Set(auth.Key("id"), "user-1")
+	ctx.Set(billing.Key("id"), "invoice-9")
+	ctx.Set("id", "legacy")
+
+	if val, _ := ctx.GetString(auth.Key("id")); val != "user-1" {
+		t.Errorf("auth id = %q, expected user-1", val)
+	}
+	if val, _ := ctx.GetString(billing.Key("id")); val != "invoice-9" {
+		t.Errorf("billing id = %q, expected invoice-9", val)
+	}
+	if val, _ := ctx.GetString("id"); val != "legacy" {
+		t.Errorf("legacy id = %q, expected legacy", val)
+	}
+
+	if auth.Key("id") != NewKey("github.com/acme/auth", "id") {
+		t.Error("Keys with the same namespace and name should be equal")
+	}
+	if s := auth.Key("id").String(); s != "github.com/acme/auth.id" {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestMigrateKeys(t *testing.T) {
+	keys := NewKeySpace("app")
+	ctx := NewContext(context.Background(), nil)
+	ctx.Set("user", "alice")
+
+	mapping := map[string]ContextKey{
+		"user":    keys.Key("user"),
+		"missing": keys.Key("missing"),
+	}
+
+	if n := MigrateKeys(ctx, mapping, false); n != 1 {
+		t.Errorf("MigrateKeys returned %d, expected 1", n)
+	}
+	if val, _ := ctx.GetString(keys.Key("user")); val != "alice" {
+		t.Errorf("Migrated value = %q, expected alice", val)
+	}
+	if ctx.Get("user") == nil {
+		t.Error("Legacy key should be kept when removeLegacy is false")
+	}
+
+	MigrateKeys(ctx, mapping, true)
+	if ctx.Get("user") != nil {
+		t.Error("Legacy key should be removed when removeLegacy is true")
+	}
+}
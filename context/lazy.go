@@ -0,0 +1,53 @@
+package context
+
+import "sync"
+
+// lazyValue 是SetLazy保存的延迟计算值
+type lazyValue struct {
+	once  sync.Once
+	fn    func() interface{}
+	value interface{}
+}
+
+// get 计算并缓存值，并发调用时fn只执行一次
+func (lv *lazyValue) get() interface{} {
+	lv.once.Do(func() {
+		lv.value = lv.fn()
+		lv.fn = nil
+	})
+	return lv.value
+}
+
+// SetLazy 设置延迟计算的值
+// fn在第一次获取该键时才执行，结果在本次分发的剩余时间内被缓存，
+// 适合解析后的JSON、地理位置查询等开销较大的派生值
+func (c *contextImpl) SetLazy(key interface{}, fn func() interface{}) {
+	c.Set(key, &lazyValue{fn: fn})
+}
+
+// lookup 获取值并解析延迟计算的值
+func (c *contextImpl) lookup(key interface{}) (interface{}, bool) {
+	c.rlock()
+	val, ok := c.values[key]
+	c.runlock()
+	if !ok {
+		return nil, false
+	}
+	return c.resolve(key, val), true
+}
+
+// resolve 解析延迟计算的值，并用计算结果替换存储中的延迟值
+// fn在不持有锁的情况下执行，因此可以访问上下文
+func (c *contextImpl) resolve(key, val interface{}) interface{} {
+	lv, ok := val.(*lazyValue)
+	if !ok {
+		return val
+	}
+	v := lv.get()
+	c.lock()
+	if current, ok := c.values[key]; ok && current == val {
+		c.values[key] = v
+	}
+	c.unlock()
+	return v
+}
@@ -0,0 +1,95 @@
+package context
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestContextSetLazy(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	ctx.Set("raw", "42")
+
+	calls := 0
+	ctx.SetLazy("parsed", func() interface{} {
+		calls++
+		// 延迟函数可以访问上下文
+		raw, _ := ctx.GetString("raw")
+		return "parsed:" + raw
+	})
+	if calls != 0 {
+		t.Fatal("SetLazy should not compute the value eagerly")
+	}
+
+	for i := 0; i < 3; i++ {
+		if v, _ := ctx.GetString("parsed"); v != "parsed:42" {
+			t.Errorf("GetString returned %q, expected parsed:42", v)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Lazy value computed %d times, expected 1", calls)
+	}
+	if v := ctx.MustGet("parsed"); v != "parsed:42" {
+		t.Errorf("MustGet returned %v", v)
+	}
+}
+
+func TestContextSetLazyAccessors(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	ctx.SetLazy("a", func() interface{} { return 1 })
+	ctx.SetLazy("b", func() interface{} { return 2 })
+	ctx.SetLazy("c", func() interface{} { return 3 })
+
+	if v := ctx.GetOrDefault("a", 0); v != 1 {
+		t.Errorf("GetOrDefault returned %v, expected 1", v)
+	}
+	if v := ctx.GetOrSet("b", func() interface{} { return 0 }); v != 2 {
+		t.Errorf("GetOrSet returned %v, expected 2", v)
+	}
+	if v := ctx.GetAny("c"); v != 3 {
+		t.Errorf("GetAny returned %v, expected 3", v)
+	}
+
+	ctx.SetLazy("d", func() interface{} { return 4 })
+	sum := 0
+	ctx.Range(func(key, value interface{}) bool {
+		sum += value.(int)
+		return true
+	})
+	if sum != 10 {
+		t.Errorf("Range should resolve lazy values, sum is %d", sum)
+	}
+
+	scope := ctx.Scope("geo")
+	scope.SetLazy("country", func() interface{} { return "NL" })
+	if v, _ := scope.GetString("country"); v != "NL" {
+		t.Errorf("Scope lazy value returned %q", v)
+	}
+}
+
+func TestContextSetLazyConcurrent(t *testing.T) {
+	ctx := NewSafeContext(context.Background(), nil)
+	var mu sync.Mutex
+	calls := 0
+	ctx.SetLazy("value", func() interface{} {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+		return "computed"
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, _ := ctx.GetString("value"); v != "computed" {
+				t.Errorf("GetString returned %q", v)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("Lazy value computed %d times, expected 1", calls)
+	}
+}
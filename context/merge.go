@@ -0,0 +1,76 @@
+package context
+
+// MergePolicy 决定MergeFrom如何合并一个键
+//   - key: 键
+//   - current: 当前上下文中的值（延迟值已计算），exists为false时为nil
+//   - incoming: 来源上下文中的值
+//   - exists: 当前上下文中是否已有该键
+//
+// 返回: 合并后的值以及是否写入
+type MergePolicy func(key, current, incoming interface{}, exists bool) (interface{}, bool)
+
+// MergeOverwrite 返回用来源上下文的值覆盖当前值的合并策略
+func MergeOverwrite() MergePolicy {
+	return func(key, current, incoming interface{}, exists bool) (interface{}, bool) {
+		return incoming, true
+	}
+}
+
+// MergeKeepExisting 返回只补充当前上下文中缺少的键的合并策略
+func MergeKeepExisting() MergePolicy {
+	return func(key, current, incoming interface{}, exists bool) (interface{}, bool) {
+		return incoming, !exists
+	}
+}
+
+// MergeKeys 返回只合并指定键的合并策略，指定键按policy合并
+// policy为nil时使用MergeOverwrite
+func MergeKeys(policy MergePolicy, keys ...interface{}) MergePolicy {
+	if policy == nil {
+		policy = MergeOverwrite()
+	}
+	selected := make(map[interface{}]struct{}, len(keys))
+	for _, k := range keys {
+		selected[k] = struct{}{}
+	}
+	return func(key, current, incoming interface{}, exists bool) (interface{}, bool) {
+		if _, ok := selected[key]; !ok {
+			return nil, false
+		}
+		return policy(key, current, incoming, exists)
+	}
+}
+
+// MergeFrom 把另一个上下文（通常是并行分支的Fork）中的值合并到当前上下文
+// policy为nil时使用MergeOverwrite，只合并键值存储，不合并参数、附加缓冲区等其他状态
+func (c *contextImpl) MergeFrom(other Context, policy MergePolicy) {
+	if other == nil || other == Context(c) {
+		return
+	}
+	if policy == nil {
+		policy = MergeOverwrite()
+	}
+
+	// 先收集来源的键值，避免同时持有两个上下文的锁
+	type pair struct {
+		key, value, current interface{}
+		exists              bool
+	}
+	var incoming []pair
+	other.Range(func(key, value interface{}) bool {
+		incoming = append(incoming, pair{key: key, value: value})
+		return true
+	})
+	// 在锁外解析当前值中的延迟值，合并策略看到的是计算结果而不是内部的延迟值
+	for i := range incoming {
+		incoming[i].current, incoming[i].exists = c.lookup(incoming[i].key)
+	}
+
+	c.lock()
+	defer c.unlock()
+	for _, p := range incoming {
+		if value, ok := policy(p.key, p.current, p.value, p.exists); ok {
+			c.values[p.key] = value
+		}
+	}
+}
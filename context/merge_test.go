@@ -0,0 +1,118 @@
+package context
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestContextMergeFrom(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	ctx.Set("shared", "parent")
+
+	branch := ctx.Fork()
+	branch.Set("shared", "branch")
+	branch.Set("geo", "NL")
+
+	ctx.MergeFrom(branch, nil)
+	if v, _ := ctx.GetString("shared"); v != "branch" {
+		t.Errorf("Default policy should overwrite, got %q", v)
+	}
+	if v, _ := ctx.GetString("geo"); v != "NL" {
+		t.Errorf("MergeFrom should add new keys, got %q", v)
+	}
+
+	// 合并自身不做任何事
+	ctx.MergeFrom(ctx, nil)
+}
+
+func TestContextMergeFromResolvesLazyCurrent(t *testing.T) {
+	ctx := NewSafeContext(context.Background(), nil)
+	ctx.SetLazy("count", func() interface{} {
+		// 延迟值的fn可以访问上下文
+		ctx.Set("computed", true)
+		return 1
+	})
+	branch := NewContext(context.Background(), nil)
+	branch.Set("count", 2)
+
+	ctx.MergeFrom(branch, func(key, current, incoming interface{}, exists bool) (interface{}, bool) {
+		c, ok := current.(int)
+		if !ok {
+			t.Fatalf("Policy received unresolved current value %T", current)
+		}
+		return c + incoming.(int), true
+	})
+	if v, _ := ctx.GetInt("count"); v != 3 {
+		t.Errorf("Expected merged count 3, got %d", v)
+	}
+	if ctx.Get("computed") != true {
+		t.Error("Lazy value should have been computed")
+	}
+}
+
+func TestMergePolicies(t *testing.T) {
+	newPair := func() (Context, Context) {
+		ctx := NewContext(context.Background(), nil)
+		ctx.Set("a", 1)
+		branch := ctx.Fork()
+		branch.Set("a", 2)
+		branch.Set("b", 3)
+		branch.Set("c", 4)
+		return ctx, branch
+	}
+
+	ctx, branch := newPair()
+	ctx.MergeFrom(branch, MergeKeepExisting())
+	if v, _ := ctx.GetInt("a"); v != 1 {
+		t.Errorf("MergeKeepExisting should keep existing values, got %d", v)
+	}
+	if v, _ := ctx.GetInt("b"); v != 3 {
+		t.Errorf("MergeKeepExisting should add missing keys, got %d", v)
+	}
+
+	ctx, branch = newPair()
+	ctx.MergeFrom(branch, MergeKeys(nil, "a", "b"))
+	if v, _ := ctx.GetInt("a"); v != 2 {
+		t.Errorf("MergeKeys should merge selected keys, got %d", v)
+	}
+	if ctx.Get("c") != nil {
+		t.Error("MergeKeys should skip unselected keys")
+	}
+
+	ctx, branch = newPair()
+	sum := func(key, current, incoming interface{}, exists bool) (interface{}, bool) {
+		if !exists {
+			return incoming, true
+		}
+		return current.(int) + incoming.(int), true
+	}
+	ctx.MergeFrom(branch, sum)
+	if v, _ := ctx.GetInt("a"); v != 3 {
+		t.Errorf("Custom policy should combine values, got %d", v)
+	}
+}
+
+func TestContextMergeFromParallelBranches(t *testing.T) {
+	ctx := NewSafeContext(context.Background(), nil)
+	branches := make([]Context, 4)
+	var wg sync.WaitGroup
+	for i := range branches {
+		branches[i] = ctx.Fork()
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			branches[i].Set(i, i*10)
+		}(i)
+	}
+	wg.Wait()
+
+	for _, branch := range branches {
+		ctx.MergeFrom(branch, nil)
+	}
+	for i := range branches {
+		if v, _ := ctx.GetInt(i); v != i*10 {
+			t.Errorf("Expected key %d to be %d, got %d", i, i*10, v)
+		}
+	}
+}
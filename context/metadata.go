@@ -0,0 +1,57 @@
+package context
+
+import (
+	"context"
+	"time"
+)
+
+// Metadata 定义传输层提供的标准元数据
+// 由各传输层集成填充，处理器通过ctx.Metadata()读取，无需各自约定不同的键
+type Metadata struct {
+	// Transport 传输层名称，例如"tcp"、"udp"、"kafka"
+	Transport string
+	// Source 消息的来源地址
+	Source string
+	// ReceivedAt 收到消息的时间
+	ReceivedAt time.Time
+	// ConnectionID 所属连接的标识，无连接的传输层为空
+	ConnectionID string
+}
+
+// metadataKey 是元数据在标准context中的键
+type metadataKey struct{}
+
+// WithMetadata 返回携带传输层元数据的标准context
+// 传给Route的父上下文携带元数据时，路由上下文的Metadata()会返回它
+func WithMetadata(parent context.Context, md Metadata) context.Context {
+	return context.WithValue(parent, metadataKey{}, md)
+}
+
+// MetadataFromContext 获取标准context携带的传输层元数据
+func MetadataFromContext(ctx context.Context) (Metadata, bool) {
+	md, ok := ctx.Value(metadataKey{}).(Metadata)
+	return md, ok
+}
+
+// Metadata 获取传输层元数据
+// 优先返回SetMetadata设置的值，否则返回父上下文通过WithMetadata携带的值，都没有时返回零值
+func (c *contextImpl) Metadata() Metadata {
+	c.rlock()
+	md := c.metadata
+	c.runlock()
+	if md != nil {
+		return *md
+	}
+	if c.Context == nil {
+		return Metadata{}
+	}
+	fromParent, _ := MetadataFromContext(c.Context)
+	return fromParent
+}
+
+// SetMetadata 设置传输层元数据
+func (c *contextImpl) SetMetadata(md Metadata) {
+	c.lock()
+	c.metadata = &md
+	c.unlock()
+}
@@ -0,0 +1,55 @@
+package context
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestContextMetadata(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	if md := ctx.Metadata(); md != (Metadata{}) {
+		t.Errorf("Metadata should be empty by default, got %+v", md)
+	}
+
+	md := Metadata{
+		Transport:    "tcp",
+		Source:       "10.0.0.1:5000",
+		ReceivedAt:   time.Now(),
+		ConnectionID: "conn-1",
+	}
+	ctx.SetMetadata(md)
+	if got := ctx.Metadata(); got != md {
+		t.Errorf("Metadata returned %+v, expected %+v", got, md)
+	}
+	if got := ctx.Fork().Metadata(); got != md {
+		t.Errorf("Fork should inherit metadata, got %+v", got)
+	}
+	if got := ctx.Copy().Metadata(); got != md {
+		t.Errorf("Copy should inherit metadata, got %+v", got)
+	}
+
+	ctx.(*contextImpl).Reset()
+	if got := ctx.Metadata(); got != (Metadata{}) {
+		t.Errorf("Reset should clear metadata, got %+v", got)
+	}
+}
+
+func TestContextMetadataFromParent(t *testing.T) {
+	md := Metadata{Transport: "udp", Source: "10.0.0.2:53"}
+	parent := WithMetadata(context.Background(), md)
+	if got, ok := MetadataFromContext(parent); !ok || got != md {
+		t.Errorf("MetadataFromContext returned %+v, %v", got, ok)
+	}
+
+	ctx := NewContext(parent, nil)
+	if got := ctx.Metadata(); got != md {
+		t.Errorf("Metadata should fall back to the parent, got %+v", got)
+	}
+
+	local := Metadata{Transport: "tcp"}
+	ctx.SetMetadata(local)
+	if got := ctx.Metadata(); got != local {
+		t.Errorf("SetMetadata should take precedence over the parent, got %+v", got)
+	}
+}
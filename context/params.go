@@ -0,0 +1,26 @@
+package context
+
+// Param 获取匹配参数，不存在时返回空字符串
+func (c *contextImpl) Param(name string) string {
+	c.rlock()
+	defer c.runlock()
+	return c.params[name]
+}
+
+// Params 获取所有匹配参数
+// 返回的映射由上下文持有，不能修改，也不能在上下文重置后继续使用
+func (c *contextImpl) Params() map[string]string {
+	c.rlock()
+	defer c.runlock()
+	return c.params
+}
+
+// SetParam 设置匹配参数
+func (c *contextImpl) SetParam(name, value string) {
+	c.lock()
+	defer c.unlock()
+	if c.params == nil {
+		c.params = make(map[string]string)
+	}
+	c.params[name] = value
+}
@@ -0,0 +1,81 @@
+package context
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// Range 遍历所有键值对，fn返回false时停止遍历
+// 非线程安全模式下直接遍历值存储，不分配内存；线程安全模式下先在读锁内复制键值对，
+// 再在锁外计算延迟值并调用fn。两种模式下延迟值的fn和遍历的fn中都可以访问该上下文
+// 尚未计算的延迟值会在遍历时计算，但不会替换存储中的延迟值
+func (c *contextImpl) Range(fn func(key, value interface{}) bool) {
+	if !c.safe {
+		for k, v := range c.values {
+			if !fn(k, resolveLazy(v)) {
+				return
+			}
+		}
+		return
+	}
+
+	type entry struct{ key, value interface{} }
+	c.rlock()
+	entries := make([]entry, 0, len(c.values))
+	for k, v := range c.values {
+		entries = append(entries, entry{k, v})
+	}
+	c.runlock()
+
+	for _, e := range entries {
+		if !fn(e.key, resolveLazy(e.value)) {
+			return
+		}
+	}
+}
+
+// resolveLazy 计算延迟值，其他值原样返回
+func resolveLazy(v interface{}) interface{} {
+	if lv, ok := v.(*lazyValue); ok {
+		return lv.get()
+	}
+	return v
+}
+
+// SortedKeys 按确定的顺序返回存储中的所有键
+// 先按键的类型名排序，再按键的字符串形式排序，适合审计和序列化等需要稳定输出的场景
+func SortedKeys(store ValueStore) []interface{} {
+	keys := store.Keys()
+	slices.SortStableFunc(keys, compareKeys)
+	return keys
+}
+
+// RangeSorted 按SortedKeys的顺序遍历键值对，fn返回false时停止遍历
+func RangeSorted(store ValueStore, fn func(key, value interface{}) bool) {
+	for _, k := range SortedKeys(store) {
+		if !fn(k, store.Get(k)) {
+			return
+		}
+	}
+}
+
+// compareKeys 比较两个键的排序顺序
+func compareKeys(a, b interface{}) int {
+	if c := cmp.Compare(fmt.Sprintf("%T", a), fmt.Sprintf("%T", b)); c != 0 {
+		return c
+	}
+	return cmp.Compare(keyString(a), keyString(b))
+}
+
+// keyString 返回键的字符串形式
+func keyString(key interface{}) string {
+	switch k := key.(type) {
+	case string:
+		return k
+	case fmt.Stringer:
+		return k.String()
+	default:
+		return fmt.Sprint(k)
+	}
+}
@@ -0,0 +1,120 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestContextRange(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	ctx.Set("a", 1)
+	ctx.Set("b", 2)
+	ctx.Set("c", 3)
+
+	sum := 0
+	ctx.Range(func(key, value interface{}) bool {
+		sum += value.(int)
+		return true
+	})
+	if sum != 6 {
+		t.Errorf("Range visited values summing to %d, expected 6", sum)
+	}
+
+	visited := 0
+	ctx.Range(func(key, value interface{}) bool {
+		visited++
+		return false
+	})
+	if visited != 1 {
+		t.Errorf("Range should stop when fn returns false, visited %d", visited)
+	}
+}
+
+func TestContextRangeNoAllocs(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	ctx.Set("a", 1)
+	ctx.Set("b", 2)
+	ctx.SetLazy("c", func() interface{} { return 3 })
+
+	visit := func(key, value interface{}) bool { return true }
+	if allocs := testing.AllocsPerRun(100, func() { ctx.Range(visit) }); allocs != 0 {
+		t.Errorf("Range allocated %v times per call", allocs)
+	}
+}
+
+func TestScopeRange(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	ctx.Set("id", "outer")
+	scope := ctx.Scope("auth")
+	scope.Set("id", "inner")
+
+	var keys []interface{}
+	scope.Range(func(key, value interface{}) bool {
+		keys = append(keys, key)
+		if value != "inner" {
+			t.Errorf("Scope Range returned value %v, expected inner", value)
+		}
+		return true
+	})
+	if len(keys) != 1 || keys[0] != "id" {
+		t.Errorf("Scope Range returned keys %v, expected [id]", keys)
+	}
+}
+
+func TestSortedKeys(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	ctx.Set("b", 2)
+	ctx.Set("a", 1)
+	ctx.Set(NewKey("app", "user"), "u")
+	ctx.Set("c", 3)
+
+	for i := 0; i < 5; i++ {
+		got := fmt.Sprint(SortedKeys(ctx))
+		if got != "[app.user a b c]" {
+			t.Fatalf("SortedKeys returned %s", got)
+		}
+	}
+
+	var order []interface{}
+	RangeSorted(ctx, func(key, value interface{}) bool {
+		order = append(order, key)
+		// RangeSorted的回调中可以访问存储
+		ctx.Get(key)
+		return len(order) < 2
+	})
+	if fmt.Sprint(order) != "[app.user a]" {
+		t.Errorf("RangeSorted visited %v", order)
+	}
+}
+
+func TestSafeContextRangeLazyWrites(t *testing.T) {
+	ctx := NewSafeContext(context.Background(), nil)
+	ctx.SetLazy("parsed", func() interface{} {
+		// 延迟值的fn写入上下文时不应死锁
+		ctx.Set("parsed_at", "now")
+		return "value"
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ctx.Range(func(key, value interface{}) bool {
+			if key == "parsed" && value != "value" {
+				t.Errorf("Range returned %v for lazy value, expected value", value)
+			}
+			// 遍历的fn中同样可以写入
+			ctx.Set("visited", true)
+			return true
+		})
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Range deadlocked on a lazy value that writes to the context")
+	}
+	if ctx.Get("parsed_at") != "now" || ctx.Get("visited") != true {
+		t.Errorf("Writes during Range were lost: %v", ctx.Keys())
+	}
+}
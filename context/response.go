@@ -0,0 +1,58 @@
+package context
+
+import "github.com/aomirun/content-router/buffer"
+
+// BufferProvider 定义响应缓冲区的来源
+// manage.BufferManager满足该接口
+type BufferProvider interface {
+	// Acquire 获取一个缓冲区
+	Acquire() buffer.Buffer
+
+	// Release 释放缓冲区
+	Release(buf buffer.Buffer)
+}
+
+// SetBufferProvider 设置上下文获取响应缓冲区使用的BufferProvider
+// 未设置时Response()使用buffer.NewBuffer()创建响应缓冲区
+// 返回: 上下文实现是否支持设置BufferProvider
+func SetBufferProvider(ctx Context, provider BufferProvider) bool {
+	c, ok := ctx.(*contextImpl)
+	if ok {
+		c.provider = provider
+	}
+	return ok
+}
+
+// ReleaseResponse 将上下文的响应缓冲区归还给BufferProvider
+// 由路由器在分发完成后调用，调用后Response()会重新获取新的缓冲区
+func ReleaseResponse(ctx Context) {
+	c, ok := ctx.(*contextImpl)
+	if !ok || c.response == nil {
+		return
+	}
+	if c.provider != nil {
+		c.provider.Release(c.response)
+	}
+	c.response = nil
+}
+
+// Response 获取响应缓冲区，第一次调用时才获取
+func (c *contextImpl) Response() buffer.Buffer {
+	c.lock()
+	defer c.unlock()
+	if c.response == nil {
+		if c.provider != nil {
+			c.response = c.provider.Acquire()
+		} else {
+			c.response = buffer.NewBuffer()
+		}
+	}
+	return c.response
+}
+
+// HasResponse 判断是否已经获取了响应缓冲区
+func (c *contextImpl) HasResponse() bool {
+	c.rlock()
+	defer c.runlock()
+	return c.response != nil
+}
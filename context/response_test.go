@@ -0,0 +1,78 @@
+package context
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+// countingProvider 记录获取和释放次数的BufferProvider
+type countingProvider struct {
+	acquired, released int
+}
+
+func (p *countingProvider) Acquire() buffer.Buffer {
+	p.acquired++
+	return buffer.NewBuffer()
+}
+
+func (p *countingProvider) Release(buf buffer.Buffer) {
+	p.released++
+}
+
+func TestContextResponseLazy(t *testing.T) {
+	provider := &countingProvider{}
+	ctx := NewContext(context.Background(), nil)
+	SetBufferProvider(ctx, provider)
+
+	if ctx.HasResponse() || provider.acquired != 0 {
+		t.Fatal("Response should not be acquired before first use")
+	}
+
+	ctx.Response().WriteString("reply")
+	if ctx.Response() != ctx.Response() || provider.acquired != 1 {
+		t.Errorf("Response should be acquired once, got %d", provider.acquired)
+	}
+	if string(ctx.Response().Get()) != "reply" {
+		t.Errorf("Unexpected response %q", ctx.Response().Get())
+	}
+
+	ReleaseResponse(ctx)
+	if ctx.HasResponse() || provider.released != 1 {
+		t.Errorf("ReleaseResponse should release to provider, released %d", provider.released)
+	}
+}
+
+func TestContextResponseReleasedOnReset(t *testing.T) {
+	provider := &countingProvider{}
+	ctx := NewContext(context.Background(), nil)
+	SetBufferProvider(ctx, provider)
+	ctx.Response()
+
+	ctx.(*contextImpl).Reset()
+	if provider.released != 1 {
+		t.Errorf("Reset should release the response, released %d", provider.released)
+	}
+}
+
+func TestContextForkResponseWithoutProvider(t *testing.T) {
+	provider := &countingProvider{}
+	ctx := NewContext(context.Background(), nil)
+	SetBufferProvider(ctx, provider)
+
+	// 没有路由器释放副本的响应缓冲区，副本不能从provider获取
+	ctx.Fork().Response().WriteString("fork")
+	ctx.Copy().Response().WriteString("copy")
+	if provider.acquired != 0 {
+		t.Errorf("Fork and Copy should not acquire from the provider, acquired %d", provider.acquired)
+	}
+}
+
+func TestContextResponseWithoutProvider(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	if ctx.Response() == nil {
+		t.Error("Response should fall back to a new buffer")
+	}
+	ReleaseResponse(ctx)
+}
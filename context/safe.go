@@ -0,0 +1,55 @@
+package context
+
+import (
+	"context"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+// NewSafeContext 创建一个键值操作线程安全的上下文实例
+// 适用于中间件启动goroutine并发读写值的场景（影子路由、并行数据补全等），
+// 代价是每次键值操作都需要加锁
+func NewSafeContext(parent context.Context, buf buffer.Buffer) Context {
+	ctx := NewContext(parent, buf)
+	Synchronize(ctx)
+	return ctx
+}
+
+// Synchronize 为上下文开启线程安全模式，之后的键值操作都会加锁
+// 必须在上下文被多个goroutine共享之前调用
+// 返回: 上下文实现是否支持线程安全模式
+func Synchronize(ctx Context) bool {
+	c, ok := ctx.(*contextImpl)
+	if ok {
+		c.safe = true
+	}
+	return ok
+}
+
+// lock 在线程安全模式下获取写锁
+func (c *contextImpl) lock() {
+	if c.safe {
+		c.mu.Lock()
+	}
+}
+
+// unlock 在线程安全模式下释放写锁
+func (c *contextImpl) unlock() {
+	if c.safe {
+		c.mu.Unlock()
+	}
+}
+
+// rlock 在线程安全模式下获取读锁
+func (c *contextImpl) rlock() {
+	if c.safe {
+		c.mu.RLock()
+	}
+}
+
+// runlock 在线程安全模式下释放读锁
+func (c *contextImpl) runlock() {
+	if c.safe {
+		c.mu.RUnlock()
+	}
+}
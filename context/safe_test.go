@@ -0,0 +1,55 @@
+package context
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestSafeContextConcurrentAccess(t *testing.T) {
+	ctx := NewSafeContext(context.Background(), nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("k%d-%d", id, j)
+				ctx.Set(key, j)
+				ctx.Get(key)
+				ctx.GetOrSet("shared", func() interface{} { return id })
+				ctx.Keys()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(ctx.Keys()) != 8*100+1 {
+		t.Errorf("Expected %d keys, got %d", 8*100+1, len(ctx.Keys()))
+	}
+}
+
+func TestSafeContextForkInheritsMode(t *testing.T) {
+	ctx := NewSafeContext(context.Background(), nil)
+	fork := ctx.Fork().(*contextImpl)
+	if !fork.safe {
+		t.Error("Fork of a safe context should be safe")
+	}
+
+	plain := NewContext(context.Background(), nil)
+	if plain.(*contextImpl).safe {
+		t.Error("NewContext should not be safe by default")
+	}
+	if !Synchronize(plain) || !plain.(*contextImpl).safe {
+		t.Error("Synchronize should enable safe mode")
+	}
+
+	// 重置后回到对象池的上下文不再处于线程安全模式
+	plain.(*contextImpl).Reset()
+	reused := NewContext(context.Background(), nil).(*contextImpl)
+	if reused.safe {
+		t.Error("Pooled context should not stay in safe mode")
+	}
+}
@@ -0,0 +1,188 @@
+package context
+
+import (
+	"fmt"
+	"time"
+)
+
+// ScopedStore 定义命名空间隔离的键值存储
+// 同一上下文中不同作用域的键互不可见，中间件套件可以只清理自己的作用域
+type ScopedStore interface {
+	ValueStore
+
+	// Name 返回作用域名称
+	Name() string
+
+	// Clear 删除作用域内的所有键值，不影响其他作用域
+	Clear()
+}
+
+// scopedKey 是作用域内的键在上下文中实际使用的键
+type scopedKey struct {
+	scope string
+	key   interface{}
+}
+
+// scopeImpl 是ScopedStore接口的实现，值保存在所属上下文中
+type scopeImpl struct {
+	store ValueStore
+	name  string
+}
+
+// missingValue 用于区分键不存在和值为nil
+type missingValue struct{}
+
+// NewScope 在任意ValueStore上创建作用域视图
+// 作用域的值以内部键保存在store中，供自定义的Context实现使用
+func NewScope(store ValueStore, name string) ScopedStore {
+	return &scopeImpl{store: store, name: name}
+}
+
+// Scope 返回指定名称的作用域视图
+func (c *contextImpl) Scope(name string) ScopedStore {
+	return NewScope(c, name)
+}
+
+// wrap 将作用域内的键转换为上下文中的键
+func (s *scopeImpl) wrap(key interface{}) scopedKey {
+	return scopedKey{scope: s.name, key: key}
+}
+
+// Name 返回作用域名称
+func (s *scopeImpl) Name() string {
+	return s.name
+}
+
+// Set 设置键值对
+func (s *scopeImpl) Set(key, value interface{}) {
+	s.store.Set(s.wrap(key), value)
+}
+
+// Get 获取值
+func (s *scopeImpl) Get(key interface{}) interface{} {
+	return s.store.Get(s.wrap(key))
+}
+
+// GetAny 获取值，作用域的键不会出现在父上下文中，因此等同于Get
+func (s *scopeImpl) GetAny(key interface{}) interface{} {
+	return s.Get(key)
+}
+
+// MustGet 获取值，键不存在时panic
+func (s *scopeImpl) MustGet(key interface{}) interface{} {
+	val := s.store.GetOrDefault(s.wrap(key), missingValue{})
+	if _, missing := val.(missingValue); missing {
+		panic(fmt.Sprintf("context: key %v does not exist in scope %s", key, s.name))
+	}
+	return val
+}
+
+// GetOrDefault 获取值，键不存在时返回默认值
+func (s *scopeImpl) GetOrDefault(key, def interface{}) interface{} {
+	return s.store.GetOrDefault(s.wrap(key), def)
+}
+
+// GetOrSet 获取值，键不存在时调用factory创建值并保存
+func (s *scopeImpl) GetOrSet(key interface{}, factory func() interface{}) interface{} {
+	return s.store.GetOrSet(s.wrap(key), factory)
+}
+
+// GetString 获取字符串值
+func (s *scopeImpl) GetString(key interface{}) (string, bool) {
+	return GetAs[string](s, key)
+}
+
+// GetInt 获取整数值
+func (s *scopeImpl) GetInt(key interface{}) (int, bool) {
+	return GetAs[int](s, key)
+}
+
+// GetInt64 获取64位整数值
+func (s *scopeImpl) GetInt64(key interface{}) (int64, bool) {
+	return GetAs[int64](s, key)
+}
+
+// GetBool 获取布尔值
+func (s *scopeImpl) GetBool(key interface{}) (bool, bool) {
+	return GetAs[bool](s, key)
+}
+
+// GetFloat64 获取浮点数值
+func (s *scopeImpl) GetFloat64(key interface{}) (float64, bool) {
+	return GetAs[float64](s, key)
+}
+
+// GetBytes 获取字节数组
+func (s *scopeImpl) GetBytes(key interface{}) ([]byte, bool) {
+	return GetAs[[]byte](s, key)
+}
+
+// GetTime 获取时间值
+func (s *scopeImpl) GetTime(key interface{}) (time.Time, bool) {
+	return GetAs[time.Time](s, key)
+}
+
+// GetDuration 获取时间间隔值
+func (s *scopeImpl) GetDuration(key interface{}) (time.Duration, bool) {
+	return GetAs[time.Duration](s, key)
+}
+
+// GetUint 获取无符号整数值
+func (s *scopeImpl) GetUint(key interface{}) (uint, bool) {
+	return GetAs[uint](s, key)
+}
+
+// GetUint64 获取64位无符号整数值
+func (s *scopeImpl) GetUint64(key interface{}) (uint64, bool) {
+	return GetAs[uint64](s, key)
+}
+
+// GetStringSlice 获取字符串切片
+func (s *scopeImpl) GetStringSlice(key interface{}) ([]string, bool) {
+	return GetAs[[]string](s, key)
+}
+
+// GetStringMap 获取字符串映射
+func (s *scopeImpl) GetStringMap(key interface{}) (map[string]string, bool) {
+	return GetAs[map[string]string](s, key)
+}
+
+// SetLazy 设置延迟计算的值
+func (s *scopeImpl) SetLazy(key interface{}, fn func() interface{}) {
+	s.store.SetLazy(s.wrap(key), fn)
+}
+
+// Delete 删除键值对
+func (s *scopeImpl) Delete(key interface{}) {
+	s.store.Delete(s.wrap(key))
+}
+
+// Keys 获取作用域内的所有键
+func (s *scopeImpl) Keys() []interface{} {
+	var keys []interface{}
+	for _, k := range s.store.Keys() {
+		if sk, ok := k.(scopedKey); ok && sk.scope == s.name {
+			keys = append(keys, sk.key)
+		}
+	}
+	return keys
+}
+
+// Range 遍历作用域内的所有键值对，fn返回false时停止遍历
+func (s *scopeImpl) Range(fn func(key, value interface{}) bool) {
+	s.store.Range(func(key, value interface{}) bool {
+		if sk, ok := key.(scopedKey); ok && sk.scope == s.name {
+			return fn(sk.key, value)
+		}
+		return true
+	})
+}
+
+// Clear 删除作用域内的所有键值
+func (s *scopeImpl) Clear() {
+	for _, k := range s.store.Keys() {
+		if sk, ok := k.(scopedKey); ok && sk.scope == s.name {
+			s.store.Delete(k)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package context
+
+import (
+	"context"
+	"testing"
+)
+
+func TestContextScope(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	auth := ctx.Scope("auth")
+	geo := ctx.Scope("geo")
+
+	ctx.Set("id", "root")
+	auth.Set("id", "user-1")
+	geo.Set("id", "region-7")
+	geo.Set("country", "NL")
+
+	if val, _ := auth.GetString("id"); val != "user-1" {
+		t.Errorf("auth id = %q, expected user-1", val)
+	}
+	if val, _ := geo.GetString("id"); val != "region-7" {
+		t.Errorf("geo id = %q, expected region-7", val)
+	}
+	if val, _ := ctx.GetString("id"); val != "root" {
+		t.Errorf("root id = %q, expected root", val)
+	}
+	if len(geo.Keys()) != 2 || len(auth.Keys()) != 1 {
+		t.Errorf("Unexpected scope keys: geo=%v auth=%v", geo.Keys(), auth.Keys())
+	}
+
+	// 同名作用域共享数据
+	if val, _ := ctx.Scope("auth").GetString("id"); val != "user-1" {
+		t.Errorf("Scope(auth) id = %q, expected user-1", val)
+	}
+
+	geo.Clear()
+	if len(geo.Keys()) != 0 {
+		t.Errorf("Clear should remove scope keys, got %v", geo.Keys())
+	}
+	if auth.Get("id") == nil || ctx.Get("id") == nil {
+		t.Error("Clear should not touch other scopes")
+	}
+}
+
+func TestContextScopeMustGet(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+	scope := ctx.Scope("s")
+	scope.Set("nil", nil)
+
+	if scope.MustGet("nil") != nil {
+		t.Error("MustGet should return nil values")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustGet should panic for missing keys")
+		}
+	}()
+	scope.MustGet("missing")
+}
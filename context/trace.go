@@ -0,0 +1,46 @@
+package context
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// traceIDKey 是追踪ID在标准context中的键
+type traceIDKey struct{}
+
+// WithTraceID 返回携带追踪ID的标准context
+// 传给Route的父上下文携带追踪ID时，路由上下文的TraceID()会返回它
+func WithTraceID(parent context.Context, id string) context.Context {
+	return context.WithValue(parent, traceIDKey{}, id)
+}
+
+// TraceIDFromContext 获取标准context携带的追踪ID，没有时返回空字符串
+func TraceIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
+
+// NewTraceID 生成一个随机的追踪ID（32个十六进制字符）
+func NewTraceID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// TraceID 获取追踪ID
+// 优先返回SetTraceID设置的值，否则返回父上下文通过WithTraceID携带的值
+func (c *contextImpl) TraceID() string {
+	if c.traceID != "" {
+		return c.traceID
+	}
+	if c.Context == nil {
+		return ""
+	}
+	return TraceIDFromContext(c.Context)
+}
+
+// SetTraceID 设置追踪ID
+func (c *contextImpl) SetTraceID(id string) {
+	c.traceID = id
+}
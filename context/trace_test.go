@@ -0,0 +1,48 @@
+package context
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+func TestContextTraceID(t *testing.T) {
+	parent := WithTraceID(context.Background(), "parent-trace")
+	ctx := NewContext(parent, buffer.NewBuffer())
+
+	if ctx.TraceID() != "parent-trace" {
+		t.Errorf("TraceID() = %q, expected parent-trace", ctx.TraceID())
+	}
+
+	ctx.SetTraceID("local-trace")
+	if ctx.TraceID() != "local-trace" {
+		t.Errorf("TraceID() = %q, expected local-trace", ctx.TraceID())
+	}
+
+	// 追踪ID随Fork、ForkWithBuffer和Copy传播
+	if ctx.Fork().TraceID() != "local-trace" {
+		t.Error("Fork should propagate the trace id")
+	}
+	if ctx.ForkWithBuffer(buffer.NewBuffer()).TraceID() != "local-trace" {
+		t.Error("ForkWithBuffer should propagate the trace id")
+	}
+	if ctx.Copy().TraceID() != "local-trace" {
+		t.Error("Copy should propagate the trace id")
+	}
+
+	ctx.(*contextImpl).Reset()
+	if NewContext(context.Background(), nil).TraceID() != "" {
+		t.Error("Pooled context should not keep the trace id")
+	}
+}
+
+func TestNewTraceID(t *testing.T) {
+	a, b := NewTraceID(), NewTraceID()
+	if len(a) != 32 || a == b {
+		t.Errorf("Expected unique 32-character ids, got %q and %q", a, b)
+	}
+	if TraceIDFromContext(context.Background()) != "" {
+		t.Error("Background context should carry no trace id")
+	}
+}
@@ -0,0 +1,17 @@
+package context
+
+// GetAs 以类型安全的方式获取值
+//   - store: 键值存储，通常是Context
+//   - key: 键
+//
+// 返回: 值和是否存在且类型匹配
+func GetAs[T any](store ValueStore, key interface{}) (T, bool) {
+	v, ok := store.Get(key).(T)
+	return v, ok
+}
+
+// SetTyped 以类型安全的方式设置值
+// 与GetAs配合使用时，由编译器保证写入和读取的类型一致
+func SetTyped[T any](store ValueStore, key interface{}, value T) {
+	store.Set(key, value)
+}
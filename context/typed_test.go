@@ -0,0 +1,39 @@
+package context
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type userInfo struct {
+	ID   int
+	Name string
+}
+
+func TestGetAs(t *testing.T) {
+	ctx := NewContext(context.Background(), nil)
+
+	SetTyped(ctx, "user", &userInfo{ID: 1, Name: "alice"})
+	SetTyped(ctx, "timeout", 3*time.Second)
+
+	user, ok := GetAs[*userInfo](ctx, "user")
+	if !ok || user.Name != "alice" {
+		t.Errorf("GetAs returned %v, %v, expected alice, true", user, ok)
+	}
+
+	timeout, ok := GetAs[time.Duration](ctx, "timeout")
+	if !ok || timeout != 3*time.Second {
+		t.Errorf("GetAs returned %v, %v, expected 3s, true", timeout, ok)
+	}
+
+	// 类型不匹配
+	if _, ok := GetAs[string](ctx, "user"); ok {
+		t.Error("GetAs should fail on type mismatch")
+	}
+
+	// 键不存在
+	if v, ok := GetAs[int](ctx, "missing"); ok || v != 0 {
+		t.Errorf("GetAs returned %v, %v for missing key, expected 0, false", v, ok)
+	}
+}
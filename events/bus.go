@@ -0,0 +1,164 @@
+package events
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// subscriber 是一个订阅者
+type subscriber struct {
+	mask uint32 // 订阅的事件类型
+	fn   func(Event)
+}
+
+// Bus 是事件总线，可以安全地并发使用
+// 发布是同步的：回调在发布者的goroutine上按订阅顺序执行，应当足够轻量；
+// 需要异步处理的订阅者使用Channel
+type Bus struct {
+	mu      sync.Mutex                    // 串行化订阅和取消订阅
+	subs    atomic.Pointer[[]*subscriber] // 订阅者快照，发布时无锁读取
+	mask    atomic.Uint32                 // 有订阅者的事件类型
+	dropped atomic.Uint64                 // 通道已满时丢弃的事件数量
+}
+
+// NewBus 创建事件总线
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Enabled 判断是否有订阅者订阅了kind，b为nil时返回false
+// 发布者可以在构造事件之前检查，避免没有订阅者时的开销
+func (b *Bus) Enabled(kind Kind) bool {
+	return b != nil && b.mask.Load()&(1<<kind) != 0
+}
+
+// Publish 把事件发送给订阅了该类型的所有订阅者，b为nil时什么都不做
+func (b *Bus) Publish(e Event) {
+	if !b.Enabled(e.Kind()) {
+		return
+	}
+	bit := uint32(1) << e.Kind()
+	for _, s := range *b.subs.Load() {
+		if s.mask&bit != 0 {
+			s.fn(e)
+		}
+	}
+}
+
+// Subscribe 订阅事件
+//   - fn: 回调函数，在发布者的goroutine上同步执行
+//   - kinds: 订阅的事件类型，为空时订阅所有类型
+//
+// 返回: 取消订阅的函数，返回后不会再调用fn
+func (b *Bus) Subscribe(fn func(Event), kinds ...Kind) (unsubscribe func()) {
+	s := &subscriber{mask: maskOf(kinds), fn: fn}
+	b.update(func(subs []*subscriber) []*subscriber {
+		return append(slices.Clip(subs), s)
+	})
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.update(func(subs []*subscriber) []*subscriber {
+				return slices.DeleteFunc(slices.Clone(subs), func(other *subscriber) bool { return other == s })
+			})
+		})
+	}
+}
+
+// Channel 通过通道订阅事件
+//   - size: 通道容量，通道已满时丢弃事件而不阻塞发布者，丢弃数量通过Dropped读取
+//   - kinds: 订阅的事件类型，为空时订阅所有类型
+//
+// 返回: 事件通道和取消订阅的函数，取消订阅后通道被关闭
+func (b *Bus) Channel(size int, kinds ...Kind) (<-chan Event, func()) {
+	ch := make(chan Event, size)
+	var mu sync.Mutex
+	closed := false
+	unsubscribe := b.Subscribe(func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		select {
+		case ch <- e:
+		default:
+			b.dropped.Add(1)
+		}
+	}, kinds...)
+
+	return ch, func() {
+		unsubscribe()
+		mu.Lock()
+		defer mu.Unlock()
+		if !closed {
+			closed = true
+			close(ch)
+		}
+	}
+}
+
+// Dropped 返回因通道已满而丢弃的事件总数
+func (b *Bus) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// update 替换订阅者快照并重新计算事件类型掩码
+func (b *Bus) update(fn func([]*subscriber) []*subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var subs []*subscriber
+	if current := b.subs.Load(); current != nil {
+		subs = *current
+	}
+	subs = fn(subs)
+	var mask uint32
+	for _, s := range subs {
+		mask |= s.mask
+	}
+	b.subs.Store(&subs)
+	b.mask.Store(mask)
+}
+
+// maskOf 返回事件类型的掩码，kinds为空时包含所有类型
+func maskOf(kinds []Kind) uint32 {
+	if len(kinds) == 0 {
+		return 1<<numKinds - 1
+	}
+	var mask uint32
+	for _, kind := range kinds {
+		mask |= 1 << kind
+	}
+	return mask
+}
+
+// WatchQueue 定期检查队列深度，深度达到capacity时发布QueueSaturated，直到ctx被取消
+// 深度回落到capacity以下之前不会重复发布
+//   - name: 队列名称
+//   - length: 返回当前队列深度的函数，例如func() int { return len(ch) }
+//   - interval: 检查间隔
+func WatchQueue(ctx context.Context, b *Bus, name string, capacity int, length func() int, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	saturated := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		depth := length()
+		switch {
+		case depth >= capacity && !saturated:
+			saturated = true
+			b.Publish(QueueSaturated{Queue: name, Depth: depth, Capacity: capacity})
+		case depth < capacity:
+			saturated = false
+		}
+	}
+}
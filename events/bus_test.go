@@ -0,0 +1,156 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+func TestBus_Subscribe(t *testing.T) {
+	bus := NewBus()
+	if bus.Enabled(KindRouteMatched) {
+		t.Error("empty bus enabled")
+	}
+	bus.Publish(RouteMatched{}) // 没有订阅者时什么都不做
+
+	var all, failed []Event
+	unsubscribe := bus.Subscribe(func(e Event) { all = append(all, e) })
+	bus.Subscribe(func(e Event) { failed = append(failed, e) }, KindRouteFailed)
+	if !bus.Enabled(KindConfigReloaded) || !bus.Enabled(KindRouteFailed) {
+		t.Error("subscribed kinds not enabled")
+	}
+
+	bus.Publish(RouteMatched{})
+	bus.Publish(RouteFailed{})
+	if len(all) != 2 || len(failed) != 1 || failed[0].Kind() != KindRouteFailed {
+		t.Errorf("all = %v, failed = %v", all, failed)
+	}
+
+	unsubscribe()
+	unsubscribe()
+	if bus.Enabled(KindRouteMatched) || !bus.Enabled(KindRouteFailed) {
+		t.Error("mask not updated after unsubscribe")
+	}
+	bus.Publish(RouteMatched{})
+	if len(all) != 2 {
+		t.Errorf("unsubscribed callback called: %v", all)
+	}
+
+	var nilBus *Bus
+	nilBus.Publish(RouteMatched{})
+	if nilBus.Enabled(KindRouteMatched) {
+		t.Error("nil bus enabled")
+	}
+}
+
+func TestBus_Channel(t *testing.T) {
+	bus := NewBus()
+	ch, cancel := bus.Channel(1, KindConfigReloaded)
+
+	bus.Publish(RouteMatched{})
+	bus.Publish(ConfigReloaded{Version: "v1"})
+	bus.Publish(ConfigReloaded{Version: "v2"}) // 通道已满，被丢弃
+
+	if e := <-ch; e.(ConfigReloaded).Version != "v1" {
+		t.Errorf("event = %+v", e)
+	}
+	if bus.Dropped() != 1 {
+		t.Errorf("Dropped = %d, want 1", bus.Dropped())
+	}
+
+	cancel()
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Error("channel not closed")
+	}
+	bus.Publish(ConfigReloaded{})
+}
+
+func TestBus_Concurrent(t *testing.T) {
+	bus := NewBus()
+	var received atomic.Int64
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for range 100 {
+				bus.Publish(RouteMatched{})
+			}
+		})
+	}
+	for range 50 {
+		unsubscribe := bus.Subscribe(func(Event) { received.Add(1) })
+		_, cancel := bus.Channel(8)
+		cancel()
+		unsubscribe()
+	}
+	wg.Wait()
+}
+
+func TestPoolObserver(t *testing.T) {
+	bus := NewBus()
+	ch, cancel := bus.Channel(4, KindBufferDropped)
+	defer cancel()
+
+	pool := buffer.NewPool(buffer.WithMaxRetainedCap(16), buffer.WithPoolObserver(PoolObserver(bus, "main")))
+	buf := pool.Acquire()
+	buf.Write(make([]byte, 64))
+	pool.Release(buf)
+
+	select {
+	case e := <-ch:
+		if dropped := e.(BufferDropped); dropped.Pool != "main" || dropped.Capacity < 64 {
+			t.Errorf("event = %+v", dropped)
+		}
+	default:
+		t.Fatal("no BufferDropped event")
+	}
+}
+
+func TestWatchQueue(t *testing.T) {
+	bus := NewBus()
+	ch, cancel := bus.Channel(4, KindQueueSaturated)
+	defer cancel()
+
+	var depth atomic.Int64
+	depth.Store(10)
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		WatchQueue(ctx, bus, "jobs", 10, func() int { return int(depth.Load()) }, time.Millisecond)
+		close(done)
+	}()
+
+	e := (<-ch).(QueueSaturated)
+	if e.Queue != "jobs" || e.Depth != 10 || e.Capacity != 10 {
+		t.Errorf("event = %+v", e)
+	}
+	// 深度回落之前不重复发布
+	time.Sleep(10 * time.Millisecond)
+	if len(ch) != 0 {
+		t.Errorf("%d duplicate events", len(ch))
+	}
+	depth.Store(0)
+	time.Sleep(10 * time.Millisecond)
+	depth.Store(12)
+	if e := (<-ch).(QueueSaturated); e.Depth != 12 {
+		t.Errorf("event = %+v", e)
+	}
+
+	stop()
+	<-done
+}
+
+func TestKindString(t *testing.T) {
+	for kind := range numKinds {
+		if s := kind.String(); s == "" || s[0] == 'K' {
+			t.Errorf("Kind(%d).String() = %q", kind, s)
+		}
+	}
+	if s := Kind(99).String(); s != "Kind(99)" {
+		t.Errorf("String() = %q", s)
+	}
+}
@@ -0,0 +1,131 @@
+// Package events 提供路由事件总线
+// 路由器、对象池、队列和版本化路由器把发生的事情作为类型化的事件发布到总线，
+// 可观测性、告警和审计功能通过回调或通道订阅，而不需要接入核心分发路径
+package events
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+)
+
+// Kind 定义事件类型
+type Kind uint8
+
+const (
+	// KindRouteMatched 消息匹配到路由并处理成功
+	KindRouteMatched Kind = iota
+	// KindRouteFailed 消息处理失败
+	KindRouteFailed
+	// KindBufferDropped 归还的缓冲区因容量超限被对象池丢弃
+	KindBufferDropped
+	// KindQueueSaturated 队列深度达到容量上限
+	KindQueueSaturated
+	// KindConfigReloaded 路由配置被更新或回滚
+	KindConfigReloaded
+
+	// numKinds 是事件类型的数量
+	numKinds
+)
+
+// String 返回事件类型的名称
+func (k Kind) String() string {
+	switch k {
+	case KindRouteMatched:
+		return "RouteMatched"
+	case KindRouteFailed:
+		return "RouteFailed"
+	case KindBufferDropped:
+		return "BufferDropped"
+	case KindQueueSaturated:
+		return "QueueSaturated"
+	case KindConfigReloaded:
+		return "ConfigReloaded"
+	default:
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+}
+
+// MarshalText 以名称编码类型
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Event 定义事件，订阅者通过类型断言读取具体的事件
+type Event interface {
+	// Kind 返回事件类型
+	Kind() Kind
+}
+
+// RouteMatched 在消息匹配到路由并处理成功后发布
+type RouteMatched struct {
+	// Route 匹配到的路由
+	Route router_context.RouteInfo
+	// Duration 处理耗时，包括中间件
+	Duration time.Duration
+}
+
+// Kind 返回KindRouteMatched
+func (RouteMatched) Kind() Kind { return KindRouteMatched }
+
+// RouteFailed 在消息处理失败后发布
+type RouteFailed struct {
+	// Route 匹配到的路由，在匹配之前失败（例如被中间件终止）时为零值
+	Route router_context.RouteInfo
+	// Err 处理器、中间件返回的错误或终止原因
+	Err error
+	// Duration 处理耗时，包括中间件
+	Duration time.Duration
+}
+
+// Kind 返回KindRouteFailed
+func (RouteFailed) Kind() Kind { return KindRouteFailed }
+
+// BufferDropped 在归还的缓冲区因容量超限被对象池丢弃后发布，见PoolObserver
+type BufferDropped struct {
+	// Pool 对象池名称
+	Pool string
+	// Capacity 被丢弃的缓冲区容量（字节）
+	Capacity int
+}
+
+// Kind 返回KindBufferDropped
+func (BufferDropped) Kind() Kind { return KindBufferDropped }
+
+// QueueSaturated 在队列深度达到容量上限时发布，见WatchQueue
+type QueueSaturated struct {
+	// Queue 队列名称
+	Queue string
+	// Depth 检查时的队列深度
+	Depth int
+	// Capacity 队列容量
+	Capacity int
+}
+
+// Kind 返回KindQueueSaturated
+func (QueueSaturated) Kind() Kind { return KindQueueSaturated }
+
+// ConfigReloaded 在路由配置被更新或回滚后发布
+type ConfigReloaded struct {
+	// Version 新的当前版本
+	Version string
+	// Previous 之前的当前版本
+	Previous string
+	// Rollback 是否为回滚
+	Rollback bool
+}
+
+// Kind 返回KindConfigReloaded
+func (ConfigReloaded) Kind() Kind { return KindConfigReloaded }
+
+// PoolObserver 返回把丢弃事件作为BufferDropped发布的对象池观察函数
+// 通过buffer.WithPoolObserver接入对象池；总线没有BufferDropped的订阅者时几乎没有开销
+func PoolObserver(b *Bus, pool string) buffer.PoolObserver {
+	return func(event buffer.PoolEvent, capacity int) {
+		if event == buffer.PoolEventDrop && b.Enabled(KindBufferDropped) {
+			b.Publish(BufferDropped{Pool: pool, Capacity: capacity})
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package frame
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+// DefaultMaxFrameSize 是内置Framer默认允许的最大帧长度
+const DefaultMaxFrameSize = 64 * 1024
+
+// ErrFrameTooLarge 表示帧长度超过了Framer允许的最大值
+// 出现该错误后流已经无法重新同步，调用方应当关闭流
+var ErrFrameTooLarge = errors.New("frame: frame exceeds max size")
+
+// delimiterFramer 是以分隔符结尾的Framer实现
+type delimiterFramer struct {
+	delim   []byte
+	maxSize int
+	// trimCR 为true时去掉帧末尾的'\r'，同时兼容LF和CRLF
+	trimCR bool
+}
+
+// NewDelimiterFramer 创建以指定字节序列结尾的Framer
+//   - delim: 帧分隔符，不能为空
+//   - maxSize: 帧内容的最大长度，小于等于0时使用DefaultMaxFrameSize
+//
+// 帧内容不包含分隔符。流在帧中间结束时返回io.ErrUnexpectedEOF，
+// 帧超过最大长度时返回ErrFrameTooLarge
+func NewDelimiterFramer(delim []byte, maxSize int) Framer {
+	if len(delim) == 0 {
+		panic("frame: empty delimiter")
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxFrameSize
+	}
+	return &delimiterFramer{
+		delim:   bytes.Clone(delim),
+		maxSize: maxSize,
+	}
+}
+
+// NewLineFramer 创建按行分帧的Framer，同时兼容LF和CRLF换行
+// 帧内容不包含行尾的"\n"或"\r\n"
+func NewLineFramer(maxSize int) Framer {
+	f := NewDelimiterFramer([]byte{'\n'}, maxSize).(*delimiterFramer)
+	f.trimCR = true
+	return f
+}
+
+// NewCRLFFramer 创建以"\r\n"结尾的Framer，适用于要求严格CRLF的文本协议
+func NewCRLFFramer(maxSize int) Framer {
+	return NewDelimiterFramer([]byte("\r\n"), maxSize)
+}
+
+// WriteFrame 写入data并追加分隔符，按行分帧时使用"\n"
+func (f *delimiterFramer) WriteFrame(w io.Writer, data []byte) error {
+	bufs := net.Buffers{data, f.delim}
+	_, err := bufs.WriteTo(w)
+	return err
+}
+
+// pending 返回缓冲区末尾可能不属于帧内容的最大字节数
+func (f *delimiterFramer) pending() int {
+	n := len(f.delim) - 1
+	if f.trimCR {
+		n++
+	}
+	return n
+}
+
+// ReadFrame 读取到分隔符为止的一帧
+func (f *delimiterFramer) ReadFrame(r *bufio.Reader, buf buffer.Buffer) error {
+	last := f.delim[len(f.delim)-1]
+	for {
+		chunk, err := r.ReadSlice(last)
+		if _, werr := buf.Write(chunk); werr != nil {
+			return werr
+		}
+
+		switch {
+		case err == nil && bytes.HasSuffix(buf.Get(), f.delim):
+			n := buf.Len() - len(f.delim)
+			if f.trimCR && n > 0 && buf.Get()[n-1] == '\r' {
+				n--
+			}
+			if n > f.maxSize {
+				return ErrFrameTooLarge
+			}
+			buf.Truncate(n)
+			return nil
+		case err == nil, err == bufio.ErrBufferFull:
+			// 末尾可能是分隔符的一部分，因此只按确定属于帧内容的长度检查
+			if buf.Len()-f.pending() > f.maxSize {
+				return ErrFrameTooLarge
+			}
+		case err == io.EOF:
+			if buf.Len() == 0 {
+				return io.EOF
+			}
+			return io.ErrUnexpectedEOF
+		default:
+			return err
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package frame
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+// readFrames 读取所有帧，返回帧内容和结束时的错误
+func readFrames(framer Framer, input string, bufSize int) ([]string, error) {
+	r := bufio.NewReaderSize(strings.NewReader(input), bufSize)
+	var frames []string
+	for {
+		buf := buffer.NewBuffer()
+		if err := framer.ReadFrame(r, buf); err != nil {
+			return frames, err
+		}
+		frames = append(frames, string(buf.Get()))
+	}
+}
+
+func TestLineFramer(t *testing.T) {
+	frames, err := readFrames(NewLineFramer(0), "first\r\nsecond\n\nthird\n", 16)
+	if err != io.EOF {
+		t.Fatalf("Expected io.EOF, got %v", err)
+	}
+	if strings.Join(frames, "|") != "first|second||third" {
+		t.Errorf("Unexpected frames %q", frames)
+	}
+}
+
+func TestCRLFFramer(t *testing.T) {
+	frames, err := readFrames(NewCRLFFramer(0), "a\nb\r\nc\r\n", 16)
+	if err != io.EOF {
+		t.Fatalf("Expected io.EOF, got %v", err)
+	}
+	if len(frames) != 2 || frames[0] != "a\nb" || frames[1] != "c" {
+		t.Errorf("Unexpected frames %q", frames)
+	}
+}
+
+func TestDelimiterFramer(t *testing.T) {
+	// 分隔符跨越bufio缓冲区边界
+	input := strings.Repeat("x", 20) + "<END>" + "short<END>"
+	frames, err := readFrames(NewDelimiterFramer([]byte("<END>"), 0), input, 16)
+	if err != io.EOF {
+		t.Fatalf("Expected io.EOF, got %v", err)
+	}
+	if len(frames) != 2 || frames[0] != strings.Repeat("x", 20) || frames[1] != "short" {
+		t.Errorf("Unexpected frames %q", frames)
+	}
+
+	// 帧内容中出现分隔符的最后一个字节
+	frames, _ = readFrames(NewDelimiterFramer([]byte("<END>"), 0), "a>b<END>", 16)
+	if len(frames) != 1 || frames[0] != "a>b" {
+		t.Errorf("Unexpected frames %q", frames)
+	}
+}
+
+func TestDelimiterFramerErrors(t *testing.T) {
+	_, err := readFrames(NewLineFramer(0), "complete\npartial", 16)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	frames, err := readFrames(NewLineFramer(8), "12345678\r\n"+strings.Repeat("y", 40)+"\n", 16)
+	if !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("Expected ErrFrameTooLarge, got %v", err)
+	}
+	if len(frames) != 1 || frames[0] != "12345678" {
+		t.Errorf("Frames up to the max size should be accepted, got %q", frames)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("NewDelimiterFramer should panic on an empty delimiter")
+		}
+	}()
+	NewDelimiterFramer(nil, 0)
+}
+
+func TestDelimiterFramerWriteFrame(t *testing.T) {
+	var out strings.Builder
+	enc := NewCRLFFramer(0).(Encoder)
+	enc.WriteFrame(&out, []byte("OK"))
+	if out.String() != "OK\r\n" {
+		t.Errorf("WriteFrame wrote %q", out.String())
+	}
+
+	frames, _ := readFrames(NewCRLFFramer(0), out.String(), 16)
+	if len(frames) != 1 || frames[0] != "OK" {
+		t.Errorf("Encoded frame should round-trip, got %q", frames)
+	}
+}
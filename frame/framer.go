@@ -0,0 +1,58 @@
+// Package frame 定义从字节流中提取消息帧的Framer接口
+// 路由器借助Framer在套接字、文件等流式输入上逐帧路由消息
+package frame
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+// Framer 定义从字节流中提取消息帧的接口
+// 实现可以是有状态的，但同一个Framer不会被多个流并发使用时才能保存流相关的状态
+type Framer interface {
+	// ReadFrame 从r读取下一帧，把帧内容（不包含分隔符、长度前缀等帧结构）写入buf
+	//  - r: 带缓冲的输入流，实现可以使用Peek、ReadSlice等方法
+	//  - buf: 调用方提供的空缓冲区
+	// 返回: 流在帧边界正常结束时返回io.EOF，在帧中间结束时返回io.ErrUnexpectedEOF
+	ReadFrame(r *bufio.Reader, buf buffer.Buffer) error
+}
+
+// FramerFunc 是函数形式的Framer
+type FramerFunc func(r *bufio.Reader, buf buffer.Buffer) error
+
+// ReadFrame 调用函数本身读取下一帧
+func (f FramerFunc) ReadFrame(r *bufio.Reader, buf buffer.Buffer) error {
+	return f(r, buf)
+}
+
+// Encoder 定义把消息编码为帧写入字节流的接口
+// 内置的Framer同时实现了Encoder，用于按相同的帧格式写回响应
+type Encoder interface {
+	// WriteFrame 把data编码为一帧写入w
+	WriteFrame(w io.Writer, data []byte) error
+}
+
+// NewFrameWriter 返回把每次Write编码为一帧的io.Writer
+// enc为nil时直接写入w
+func NewFrameWriter(w io.Writer, enc Encoder) io.Writer {
+	if enc == nil {
+		return w
+	}
+	return &frameWriter{w: w, enc: enc}
+}
+
+// frameWriter 是把每次Write编码为一帧的io.Writer
+type frameWriter struct {
+	w   io.Writer
+	enc Encoder
+}
+
+// Write 把p编码为一帧写入底层io.Writer
+func (fw *frameWriter) Write(p []byte) (int, error) {
+	if err := fw.enc.WriteFrame(fw.w, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,44 @@
+package frame
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+func TestFramerFunc(t *testing.T) {
+	// 每帧固定两个字节
+	var framer Framer = FramerFunc(func(r *bufio.Reader, buf buffer.Buffer) error {
+		var p [2]byte
+		if _, err := r.Read(p[:]); err != nil {
+			return err
+		}
+		_, err := buf.Write(p[:])
+		return err
+	})
+
+	r := bufio.NewReader(strings.NewReader("abcd"))
+	buf := buffer.NewBuffer()
+	if err := framer.ReadFrame(r, buf); err != nil {
+		t.Fatalf("ReadFrame failed: %v", err)
+	}
+	if string(buf.Get()) != "ab" {
+		t.Errorf("ReadFrame returned %q, expected ab", buf.Get())
+	}
+}
+
+func TestNewFrameWriter(t *testing.T) {
+	var out strings.Builder
+	w := NewFrameWriter(&out, NewLineFramer(0).(Encoder))
+	w.Write([]byte("a"))
+	w.Write([]byte("b"))
+	if out.String() != "a\nb\n" {
+		t.Errorf("Frame writer wrote %q, expected \"a\\nb\\n\"", out.String())
+	}
+
+	if NewFrameWriter(&out, nil) != &out {
+		t.Error("NewFrameWriter without an encoder should return w unchanged")
+	}
+}
@@ -0,0 +1,115 @@
+package frame
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+// SLIP（RFC 1055）使用的特殊字节
+const (
+	slipEnd    = 0xC0
+	slipEsc    = 0xDB
+	slipEscEnd = 0xDC
+	slipEscEsc = 0xDD
+)
+
+// slipFramer 是SLIP编码的Framer实现
+type slipFramer struct {
+	maxSize int
+}
+
+// NewSLIPFramer 创建SLIP（RFC 1055）编码的Framer，常用于串口设备
+//   - maxSize: 解码后帧内容的最大长度，小于等于0时使用DefaultMaxFrameSize
+//
+// 帧内容是去掉END分隔符并还原转义字节后的数据，连续的END（空帧）会被跳过，
+// 不合法的转义序列按原样保留
+func NewSLIPFramer(maxSize int) Framer {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxFrameSize
+	}
+	return &slipFramer{maxSize: maxSize}
+}
+
+// WriteFrame 转义data并在前后加上END写入
+// 前导END可以冲掉线路噪声产生的残留字节
+func (f *slipFramer) WriteFrame(w io.Writer, data []byte) error {
+	out := make([]byte, 0, len(data)+len(data)/8+2)
+	out = append(out, slipEnd)
+	for _, c := range data {
+		switch c {
+		case slipEnd:
+			out = append(out, slipEsc, slipEscEnd)
+		case slipEsc:
+			out = append(out, slipEsc, slipEscEsc)
+		default:
+			out = append(out, c)
+		}
+	}
+	out = append(out, slipEnd)
+	_, err := w.Write(out)
+	return err
+}
+
+// ReadFrame 读取到END为止的一帧并还原转义字节
+func (f *slipFramer) ReadFrame(r *bufio.Reader, buf buffer.Buffer) error {
+	// 跳过帧之间的END
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if c != slipEnd {
+			r.UnreadByte()
+			break
+		}
+	}
+
+	escaped := false
+	for {
+		chunk, err := r.ReadSlice(slipEnd)
+		if err == nil {
+			chunk = chunk[:len(chunk)-1]
+		}
+
+		start := 0
+		for i, c := range chunk {
+			switch {
+			case escaped:
+				escaped = false
+				switch c {
+				case slipEscEnd:
+					buf.Write([]byte{slipEnd})
+				case slipEscEsc:
+					buf.Write([]byte{slipEsc})
+				default:
+					buf.Write([]byte{slipEsc, c})
+				}
+				start = i + 1
+			case c == slipEsc:
+				buf.Write(chunk[start:i])
+				escaped = true
+				start = i + 1
+			}
+		}
+		buf.Write(chunk[start:])
+		if buf.Len() > f.maxSize {
+			return ErrFrameTooLarge
+		}
+
+		switch err {
+		case nil:
+			if escaped {
+				// 转义字节后直接出现END，按原样保留ESC
+				buf.Write([]byte{slipEsc})
+			}
+			return nil
+		case bufio.ErrBufferFull:
+		case io.EOF:
+			return io.ErrUnexpectedEOF
+		default:
+			return err
+		}
+	}
+}
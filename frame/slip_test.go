@@ -0,0 +1,64 @@
+package frame
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+func TestSLIPFramer(t *testing.T) {
+	input := "\xc0\xc0first\xc0sec\xdb\xdcond\xdb\xdd\xc0\xc0bad\xdb\x01esc\xdb\xc0"
+	// 缓冲区很小，转义序列会跨越ReadSlice的边界
+	frames, err := readFrames(NewSLIPFramer(0), input, 16)
+	if err != io.EOF {
+		t.Fatalf("Expected io.EOF, got %v", err)
+	}
+	want := []string{"first", "sec\xc0ond\xdb", "bad\xdb\x01esc\xdb"}
+	if len(frames) != len(want) {
+		t.Fatalf("Expected %d frames, got %q", len(want), frames)
+	}
+	for i := range want {
+		if frames[i] != want[i] {
+			t.Errorf("Frame %d: expected %q, got %q", i, want[i], frames[i])
+		}
+	}
+}
+
+func TestSLIPFramerErrors(t *testing.T) {
+	_, err := readFrames(NewSLIPFramer(0), "\xc0partial", 16)
+	if err != io.ErrUnexpectedEOF {
+		t.Errorf("Expected io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	_, err = readFrames(NewSLIPFramer(4), "\xc0toolong\xc0", 16)
+	if !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("Expected ErrFrameTooLarge, got %v", err)
+	}
+}
+
+func TestSLIPFramerRoundTrip(t *testing.T) {
+	framer := NewSLIPFramer(0)
+	payloads := [][]byte{{0x01, 0xc0, 0xdb, 0x02}, {0xdb}, []byte("plain")}
+
+	var stream bytes.Buffer
+	for _, p := range payloads {
+		if err := framer.(Encoder).WriteFrame(&stream, p); err != nil {
+			t.Fatalf("WriteFrame failed: %v", err)
+		}
+	}
+
+	r := bufio.NewReader(&stream)
+	for i, want := range payloads {
+		buf := buffer.NewBuffer()
+		if err := framer.ReadFrame(r, buf); err != nil {
+			t.Fatalf("ReadFrame %d failed: %v", i, err)
+		}
+		if !bytes.Equal(buf.Get(), want) {
+			t.Errorf("Frame %d: expected %x, got %x", i, want, buf.Get())
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package fuzz
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/rules"
+)
+
+func newFuzzRouter(t testing.TB) router.Router {
+	t.Helper()
+	r := router.NewRouter()
+	cfg, err := rules.Parse([]byte(`{"rules": [
+		{"name": "orders", "match": {"prefix": "{", "json": {"order.type": "refund"}}, "sink": {"type": "discard"}},
+		{"name": "errors", "match": {"regex": "^(ERROR|FATAL) [0-9]+"}, "sink": {"type": "discard"}},
+		{"name": "cmd", "match": {"template": "cmd:{name}:{arg}"}, "sink": {"type": "discard"}}
+	]}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if _, err := cfg.Apply(r); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	r.Match("PING", func(ctx router_context.Context) error { return nil })
+	r.Match("/regex/^CMD[0-9]+", func(ctx router_context.Context) error { return nil })
+	r.Match("/suffix/!!", func(ctx router_context.Context) error { return nil })
+	return r
+}
+
+func TestSeeds(t *testing.T) {
+	seeds := Seeds(newFuzzRouter(t), []byte("extra"))
+	has := func(s string) bool {
+		return slices.ContainsFunc(seeds, func(seed []byte) bool { return string(seed) == s })
+	}
+	for _, want := range []string{
+		`{"order":{"type":"refund"}}`,
+		"ERROR 0",
+		"FATAL 0",
+		"cmd:0:0",
+		"PING",
+		"PING0",
+		"PIN",
+		"CMD0",
+		"!!0",
+		"%PDF-",
+		"extra",
+	} {
+		if !has(want) {
+			t.Errorf("seeds missing %q", want)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, seed := range seeds {
+		if seen[string(seed)] {
+			t.Errorf("duplicate seed %q", seed)
+		}
+		seen[string(seed)] = true
+	}
+}
+
+func TestSeeds_MatchRoutes(t *testing.T) {
+	// 生成的样例应当能匹配对应的路由
+	r := newFuzzRouter(t)
+	h := NewHarness(r)
+	matched := map[string]bool{}
+	for _, seed := range Seeds(r) {
+		res := h.recorder.RouteString(string(seed))
+		if res.Route != nil {
+			matched[res.Route.Name+res.Route.Pattern] = true
+		}
+	}
+	for _, name := range []string{"orders", "errors", "cmd", "PING", "/regex/", "/suffix/"} {
+		if !slices.ContainsFunc(keys(matched), func(k string) bool { return strings.HasPrefix(k, name) }) {
+			t.Errorf("no seed matched route %q (matched %v)", name, matched)
+		}
+	}
+}
+
+func keys(m map[string]bool) []string {
+	var result []string
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
+
+func TestRegexSamples(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{`^GET /[a-z]+ HTTP/1\.[01]$`, []string{"GET /a HTTP/1.0"}},
+		{`a|b(c)`, []string{"a", "bc"}},
+		{`x{3}y?z*`, []string{"xxx"}},
+		{`(`, nil},
+	}
+	for _, tt := range tests {
+		got := regexSamples(tt.expr)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("regexSamples(%q) = %q, want %q", tt.expr, got, tt.want)
+			continue
+		}
+		if tt.want == nil {
+			continue
+		}
+		re := regexp.MustCompile(tt.expr)
+		for _, sample := range got {
+			if !re.MatchString(sample) {
+				t.Errorf("sample %q does not match %q", sample, tt.expr)
+			}
+		}
+	}
+}
+
+// fatalT 在Fatalf时panic，用于验证Check的失败路径
+type fatalT struct {
+	testing.TB
+}
+
+type fatal string
+
+func (fatalT) Helper() {}
+
+func (fatalT) Fatalf(format string, args ...any) {
+	panic(fatal(fmt.Sprintf(format, args...)))
+}
+
+func checkFailure(h *Harness, data string) (msg string) {
+	defer func() {
+		if v, ok := recover().(fatal); ok {
+			msg = string(v)
+		}
+	}()
+	h.Check(fatalT{}, []byte(data))
+	return ""
+}
+
+func TestHarness_Panic(t *testing.T) {
+	r := router.NewRouter()
+	r.Match("boom", func(ctx router_context.Context) error { panic("kaboom") })
+	h := NewHarness(r)
+
+	if msg := checkFailure(h, "boom"); !strings.Contains(msg, "panicked") || !strings.Contains(msg, "kaboom") {
+		t.Errorf("failure = %q", msg)
+	}
+	if msg := checkFailure(h, "fine"); msg != "" {
+		t.Errorf("unexpected failure %q", msg)
+	}
+}
+
+func TestHarness_Unstable(t *testing.T) {
+	r := router.NewRouter()
+	n := 0
+	r.Register(router.MatcherFunc(func(ctx router_context.Context) bool {
+		n++
+		return n%2 == 1
+	}), func(ctx router_context.Context) error { return nil })
+	h := NewHarness(r)
+
+	if msg := checkFailure(h, "x"); !strings.Contains(msg, "unstable match") {
+		t.Errorf("failure = %q", msg)
+	}
+
+	calls := 0
+	r2 := router.NewRouter()
+	r2.Match("", func(ctx router_context.Context) error {
+		calls++
+		if calls%2 == 0 {
+			return errors.New("flaky")
+		}
+		return nil
+	})
+	if msg := checkFailure(NewHarness(r2), "y"); !strings.Contains(msg, "unstable result") {
+		t.Errorf("failure = %q", msg)
+	}
+}
+
+func FuzzRouter(f *testing.F) {
+	NewHarness(newFuzzRouter(f)).Fuzz(f)
+}
@@ -0,0 +1,91 @@
+package fuzz
+
+import (
+	"fmt"
+	"runtime/debug"
+	"testing"
+
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/router/routertest"
+)
+
+// Harness 对路由器进行模糊测试
+// 每个输入分发两次，检查:
+//  1. 分发不会panic
+//  2. 两次匹配到同一条路由，并且返回错误的情况一致
+//
+// 处理器会被真实调用，应当使用没有外部副作用的处理器构建被测试的路由器
+type Harness struct {
+	router   router.Router
+	recorder *routertest.RouteRecorder
+}
+
+// NewHarness 创建路由器的模糊测试工具
+// 与routertest.RouteRecorder一样，通过r.Use注册一个中间件记录匹配结果
+func NewHarness(r router.Router) *Harness {
+	return &Harness{
+		router:   r,
+		recorder: routertest.NewRouteRecorder(r),
+	}
+}
+
+// Fuzz 把路由表生成的种子加入语料，并以Check作为模糊测试目标
+//
+//	func FuzzRouter(f *testing.F) {
+//		fuzz.NewHarness(newRouter()).Fuzz(f)
+//	}
+func (h *Harness) Fuzz(f *testing.F, extra ...[]byte) {
+	for _, seed := range Seeds(h.router, extra...) {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		h.Check(t, data)
+	})
+}
+
+// Check 分发data两次并检查不变式，不满足时调用t.Fatalf
+func (h *Harness) Check(t testing.TB, data []byte) {
+	t.Helper()
+	first, err := h.route(data)
+	if err != nil {
+		t.Fatalf("fuzz: %v", err)
+	}
+	second, err := h.route(data)
+	if err != nil {
+		t.Fatalf("fuzz: %v", err)
+	}
+
+	if a, b := routeName(first), routeName(second); a != b {
+		t.Fatalf("fuzz: unstable match for %q: first %s, then %s", data, a, b)
+	}
+	if (first.Err == nil) != (second.Err == nil) {
+		t.Fatalf("fuzz: unstable result for %q: first error %v, then %v", data, first.Err, second.Err)
+	}
+}
+
+// route 分发一次，把panic转换为错误
+func (h *Harness) route(data []byte) (result *routertest.Result, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("router panicked on %q: %v\n%s", data, v, debug.Stack())
+		}
+	}()
+	result = h.recorder.RouteString(string(data))
+	// 结果只用于本次检查，不需要在记录器中累积
+	h.recorder.Reset()
+	return result, nil
+}
+
+// routeName 描述匹配结果
+func routeName(result *routertest.Result) string {
+	switch {
+	case !result.Captured:
+		return "(not dispatched)"
+	case result.Route == nil:
+		return "(no route)"
+	case result.Route.Name != "":
+		return fmt.Sprintf("%q", result.Route.Name)
+	default:
+		return fmt.Sprintf("pattern %q", result.Route.Pattern)
+	}
+}
@@ -0,0 +1,253 @@
+// Package fuzz 提供由路由表驱动的模糊测试工具
+// 根据已注册路由的匹配模式生成种子语料，并通过Harness接入go test的模糊测试，
+// 检查路由器不会panic、同一输入的匹配结果稳定
+package fuzz
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp/syntax"
+	"strings"
+
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/rules"
+)
+
+// MagicBytes 是常见格式的文件头，作为通用种子加入语料
+// 用于覆盖按内容类型分发的匹配器
+var MagicBytes = [][]byte{
+	{0x1f, 0x8b, 0x08},                            // gzip
+	{0x28, 0xb5, 0x2f, 0xfd},                      // zstd
+	{'P', 'K', 0x03, 0x04},                        // zip
+	{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}, // png
+	{0xff, 0xd8, 0xff},                            // jpeg
+	[]byte("%PDF-"),
+	[]byte("GIF89a"),
+	{0xef, 0xbb, 0xbf}, // UTF-8 BOM
+	{0xff, 0xfe},       // UTF-16LE BOM
+	{0xfe, 0xff},       // UTF-16BE BOM
+	{0x82, 0xa4},       // MessagePack fixmap
+	{0x0a, 0x00},       // protobuf field 1, length 0
+	[]byte("{}"),
+	[]byte("[]"),
+	[]byte("<?xml"),
+	{},
+}
+
+// Seeds 根据路由表生成种子语料
+// 对每条路由的匹配模式:
+//   - rules包生成的匹配条件（JSON）: 组合前缀、包含、后缀、正则和模板的样例，以及JSON字段
+//   - Match的"/regex/"模式: 正则表达式的样例；"/prefix/"、"/contains/"和"/suffix/"模式按其内容处理
+//   - 其他模式按字面量处理（Match注册的前缀）: 模式本身、加后缀和少一个字节的变体
+//
+// 结果还包含MagicBytes和extra，并去除重复
+func Seeds(r router.RouteInspector, extra ...[]byte) [][]byte {
+	var seeds [][]byte
+	for _, route := range r.Inspect().Routes {
+		seeds = append(seeds, patternSeeds(route.Info.Pattern)...)
+	}
+	seeds = append(seeds, MagicBytes...)
+	seeds = append(seeds, extra...)
+	return dedupe(seeds)
+}
+
+// patternSeeds 根据一个匹配模式生成种子
+func patternSeeds(pattern string) [][]byte {
+	if pattern == "" {
+		return nil
+	}
+	if spec, ok := parseMatchSpec(pattern); ok {
+		return specSeeds(spec)
+	}
+	if expr, ok := strings.CutPrefix(pattern, router.PatternRegex); ok {
+		var seeds [][]byte
+		for _, sample := range regexSamples(expr) {
+			seeds = append(seeds, []byte(sample))
+		}
+		return seeds
+	}
+	for _, kind := range []string{router.PatternPrefix, router.PatternContains, router.PatternSuffix} {
+		if literal, ok := strings.CutPrefix(pattern, kind); ok {
+			return literalSeeds(literal)
+		}
+	}
+	return literalSeeds(pattern)
+}
+
+// literalSeeds 生成字面量本身、加后缀和截断的变体，覆盖匹配边界
+func literalSeeds(literal string) [][]byte {
+	seeds := [][]byte{[]byte(literal), []byte(literal + "0")}
+	if len(literal) > 1 {
+		seeds = append(seeds, []byte(literal[:len(literal)-1]))
+	}
+	return seeds
+}
+
+// parseMatchSpec 尝试把模式解析为rules.MatchSpec
+func parseMatchSpec(pattern string) (rules.MatchSpec, bool) {
+	var spec rules.MatchSpec
+	if !strings.HasPrefix(pattern, "{") {
+		return spec, false
+	}
+	dec := json.NewDecoder(strings.NewReader(pattern))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return spec, false
+	}
+	return spec, true
+}
+
+// specSeeds 为匹配条件生成种子
+// 先为每个条件单独生成种子，再尝试构造同时满足所有条件的样例
+func specSeeds(spec rules.MatchSpec) [][]byte {
+	var seeds [][]byte
+	for _, literal := range []string{spec.Prefix, spec.Suffix, spec.Contains} {
+		if literal != "" {
+			seeds = append(seeds, literalSeeds(literal)...)
+		}
+	}
+	var regexFirst string
+	if spec.Regex != "" {
+		samples := regexSamples(spec.Regex)
+		for _, sample := range samples {
+			seeds = append(seeds, []byte(sample))
+		}
+		if len(samples) > 0 {
+			regexFirst = samples[0]
+		}
+	}
+	if spec.Template != "" {
+		seeds = append(seeds, []byte(templateSample(spec.Template)))
+	}
+
+	// 组合样例: 前缀 + JSON对象或正则样例 + 包含 + 后缀
+	var body string
+	if len(spec.JSON) > 0 {
+		obj := map[string]any{}
+		for path, value := range spec.JSON {
+			setPath(obj, strings.Split(path, "."), value)
+		}
+		data, _ := json.Marshal(obj)
+		body = string(data)
+		seeds = append(seeds, data)
+	} else {
+		body = regexFirst
+	}
+	combined := body
+	if !strings.HasPrefix(combined, spec.Prefix) {
+		combined = spec.Prefix + combined
+	}
+	if !strings.Contains(combined, spec.Contains) {
+		combined += spec.Contains
+	}
+	if !strings.HasSuffix(combined, spec.Suffix) {
+		combined += spec.Suffix
+	}
+	if spec.Template != "" && combined == "" {
+		combined = templateSample(spec.Template)
+	}
+	seeds = append(seeds, []byte(combined))
+	return seeds
+}
+
+// setPath 在嵌套对象中按路径设置字符串值
+func setPath(obj map[string]any, path []string, value string) {
+	for _, key := range path[:len(path)-1] {
+		child, ok := obj[key].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			obj[key] = child
+		}
+		obj = child
+	}
+	obj[path[len(path)-1]] = value
+}
+
+// templateSample 把模板中的占位符替换为"0"，生成满足模板的样例
+func templateSample(template string) string {
+	var b strings.Builder
+	for template != "" {
+		start := strings.IndexByte(template, '{')
+		end := strings.IndexByte(template, '}')
+		if start < 0 || end < start {
+			b.WriteString(template)
+			break
+		}
+		b.WriteString(template[:start])
+		b.WriteByte('0')
+		template = template[end+1:]
+	}
+	return b.String()
+}
+
+// maxRegexSamples 是每个正则表达式最多生成的样例数量
+const maxRegexSamples = 16
+
+// regexSamples 为正则表达式生成能被它匹配的样例，每个分支至少生成一个
+// 正则无法解析时返回nil
+func regexSamples(expr string) []string {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	return regexSample(re.Simplify())
+}
+
+// regexSample 为re生成样例
+// 分支展开为多个样例，可选和重复取最少次数，字符类取第一个字符，样例数量不超过maxRegexSamples
+func regexSample(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}
+	case syntax.OpCharClass:
+		if len(re.Rune) > 0 {
+			return []string{string(re.Rune[0])}
+		}
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return []string{"a"}
+	case syntax.OpCapture, syntax.OpPlus:
+		return regexSample(re.Sub[0])
+	case syntax.OpRepeat:
+		samples := regexSample(re.Sub[0])
+		for i, sample := range samples {
+			samples[i] = strings.Repeat(sample, re.Min)
+		}
+		return samples
+	case syntax.OpConcat:
+		samples := []string{""}
+		for _, sub := range re.Sub {
+			var next []string
+			for _, prefix := range samples {
+				for _, suffix := range regexSample(sub) {
+					if len(next) < maxRegexSamples {
+						next = append(next, prefix+suffix)
+					}
+				}
+			}
+			samples = next
+		}
+		return samples
+	case syntax.OpAlternate:
+		var samples []string
+		for _, sub := range re.Sub {
+			samples = append(samples, regexSample(sub)...)
+		}
+		return samples[:min(len(samples), maxRegexSamples)]
+	}
+	// 空匹配、锚点、边界、*和?
+	return []string{""}
+}
+
+// dedupe 去除重复的种子，保留第一次出现的顺序
+func dedupe(seeds [][]byte) [][]byte {
+	seen := map[string]bool{}
+	result := seeds[:0:0]
+	for _, seed := range seeds {
+		if seen[string(seed)] {
+			continue
+		}
+		seen[string(seed)] = true
+		result = append(result, bytes.Clone(seed))
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package manage
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+)
+
+// ContextManager 定义上下文管理器接口
+// 与BufferManager对应，统一管理路由上下文的获取和释放
+//
+// 主要功能:
+// 1. 控制上下文是否池化复用
+// 2. 提供上下文使用统计
+type ContextManager interface {
+	// Acquire 获取一个上下文
+	//  - parent: 父上下文
+	//  - buf: 关联的缓冲区
+	// 返回: 可用的上下文实例
+	Acquire(parent context.Context, buf buffer.Buffer) router_context.Context
+
+	// Release 释放上下文
+	// 池化模式下上下文会被重置并放回对象池，调用后不能再使用该上下文
+	//  - ctx: 需要释放的上下文
+	Release(ctx router_context.Context)
+
+	// Stats 返回上下文使用统计
+	Stats() ContextStats
+}
+
+// ContextStats 定义上下文管理器的使用统计
+type ContextStats struct {
+	// Acquired 累计获取次数
+	Acquired uint64 `json:"acquired"`
+	// Released 累计释放次数
+	Released uint64 `json:"released"`
+	// Outstanding 当前已获取但尚未释放的上下文数量
+	Outstanding int64 `json:"outstanding"`
+}
+
+// contextManagerImpl 是ContextManager接口的实现
+type contextManagerImpl struct {
+	pooled   bool
+	acquired atomic.Uint64
+	released atomic.Uint64
+}
+
+// NewContextManager 创建一个池化的ContextManager实例
+// 释放的上下文会被重置并复用
+func NewContextManager() ContextManager {
+	return &contextManagerImpl{pooled: true}
+}
+
+// NewUnpooledContextManager 创建一个不复用上下文的ContextManager实例
+// 释放时不重置上下文（只执行OnReset注册的清理回调），处理器保存的上下文引用在Route返回后仍然有效，
+// 代价是每次获取都会分配新的上下文
+func NewUnpooledContextManager() ContextManager {
+	return &contextManagerImpl{}
+}
+
+// Acquire 获取一个上下文
+func (cm *contextManagerImpl) Acquire(parent context.Context, buf buffer.Buffer) router_context.Context {
+	cm.acquired.Add(1)
+	return router_context.NewContext(parent, buf)
+}
+
+// Release 释放上下文
+func (cm *contextManagerImpl) Release(ctx router_context.Context) {
+	cm.released.Add(1)
+	if !cm.pooled {
+		// 不复用的上下文不会被重置，但清理回调仍然需要执行
+		router_context.RunCleanups(ctx)
+		return
+	}
+	if resettable, ok := ctx.(interface{ Reset() }); ok {
+		resettable.Reset()
+	}
+}
+
+// Stats 返回上下文使用统计
+func (cm *contextManagerImpl) Stats() ContextStats {
+	released := cm.released.Load()
+	acquired := cm.acquired.Load()
+	return ContextStats{
+		Acquired:    acquired,
+		Released:    released,
+		Outstanding: int64(acquired) - int64(released),
+	}
+}
@@ -0,0 +1,52 @@
+package manage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+func TestContextManager_AcquireRelease(t *testing.T) {
+	manager := NewContextManager()
+	buf := buffer.NewBuffer()
+
+	ctx := manager.Acquire(context.Background(), buf)
+	if ctx.Buffer() != buf {
+		t.Error("Acquire should bind the buffer to the context")
+	}
+
+	stats := manager.Stats()
+	if stats.Acquired != 1 || stats.Outstanding != 1 {
+		t.Errorf("Expected 1 acquired and 1 outstanding, got %+v", stats)
+	}
+
+	manager.Release(ctx)
+	stats = manager.Stats()
+	if stats.Released != 1 || stats.Outstanding != 0 {
+		t.Errorf("Expected 1 released and 0 outstanding, got %+v", stats)
+	}
+}
+
+func TestUnpooledContextManager(t *testing.T) {
+	manager := NewUnpooledContextManager()
+	buf := buffer.NewBuffer()
+
+	ctx := manager.Acquire(context.Background(), buf)
+	ctx.Set("key", "value")
+	cleaned := false
+	ctx.OnReset(func() { cleaned = true })
+	manager.Release(ctx)
+
+	if !cleaned {
+		t.Error("Unpooled release should still run cleanups")
+	}
+
+	// 不池化时释放后上下文仍然保持原有状态
+	if ctx.Buffer() != buf {
+		t.Error("Unpooled context should keep its buffer after release")
+	}
+	if v, ok := ctx.GetString("key"); !ok || v != "value" {
+		t.Error("Unpooled context should keep its values after release")
+	}
+}
@@ -0,0 +1,30 @@
+package manage
+
+import "sync/atomic"
+
+// defaultManager 保存包级默认的BufferManager
+var defaultManager atomic.Pointer[BufferManager]
+
+// Default 返回包级默认的BufferManager
+// 根包的NewBuffer()、传输层以及未指定缓冲区管理器的路由器都使用它，
+// 使所有分配汇集到同一个可观测的对象池
+func Default() BufferManager {
+	if m := defaultManager.Load(); m != nil {
+		return *m
+	}
+	m := NewBufferManager()
+	if defaultManager.CompareAndSwap(nil, &m) {
+		return m
+	}
+	return *defaultManager.Load()
+}
+
+// SetDefault 设置包级默认的BufferManager
+// 应在程序启动时、创建路由器和缓冲区之前调用一次；
+// 之后创建的路由器仍可以通过router.WithBufferManager单独覆盖
+//   - manager: 新的默认管理器，为nil时忽略
+func SetDefault(manager BufferManager) {
+	if manager != nil {
+		defaultManager.Store(&manager)
+	}
+}
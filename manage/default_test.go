@@ -0,0 +1,27 @@
+package manage
+
+import (
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+func TestDefault(t *testing.T) {
+	if Default() != Default() {
+		t.Error("Default should return the same manager")
+	}
+
+	previous := Default()
+	defer SetDefault(previous)
+
+	custom := NewBufferManagerWithPool(buffer.NewTieredPool(nil))
+	SetDefault(custom)
+	if Default() != custom {
+		t.Error("SetDefault should replace the default manager")
+	}
+
+	SetDefault(nil)
+	if Default() != custom {
+		t.Error("SetDefault(nil) should be ignored")
+	}
+}
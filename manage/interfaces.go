@@ -19,4 +19,27 @@ type BufferManager interface {
 	// Release 将缓冲区释放回池中
 	// buf: 需要释放的缓冲区实例
 	Release(buf buffer.Buffer)
+
+	// WithBuffer 获取一个缓冲区并传给fn，fn返回后（包括panic时）总是释放缓冲区
+	//  - fn: 使用缓冲区的函数，不能在返回后继续持有缓冲区
+	// 返回: fn返回的错误
+	WithBuffer(fn func(buf buffer.Buffer) error) error
+
+	// Stats 返回缓冲区使用统计
+	// 返回: 获取和释放次数、未归还的缓冲区数量和字节数
+	Stats() Stats
+}
+
+// Stats 定义缓冲区管理器的使用统计
+type Stats struct {
+	// Acquired 累计获取次数
+	Acquired uint64 `json:"acquired"`
+	// Released 累计释放次数
+	Released uint64 `json:"released"`
+	// Outstanding 当前已获取但尚未释放的缓冲区数量
+	Outstanding int `json:"outstanding"`
+	// OutstandingBytes 未释放缓冲区的估算字节数
+	// 按获取时的容量计算，缓冲区在使用中扩容不计入；
+	// 不支持记录容量的自定义缓冲区实现在扩容后释放会使估算偏差
+	OutstandingBytes int `json:"outstanding_bytes"`
 }
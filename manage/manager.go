@@ -1,29 +1,80 @@
 package manage
 
 import (
+	"sync/atomic"
+
 	"github.com/aomirun/content-router/buffer"
 )
 
 // bufferManagerImpl 是BufferManager接口的实现
 type bufferManagerImpl struct {
 	pool buffer.ObjectPool[buffer.Buffer]
+
+	acquired atomic.Uint64
+	released atomic.Uint64
+	// outstandingBytes 按获取时的容量估算的未释放字节数
+	outstandingBytes atomic.Int64
 }
 
 // NewBufferManager 创建一个新的BufferManager实例
 func NewBufferManager() BufferManager {
+	return NewBufferManagerWithPool(nil)
+}
+
+// NewBufferManagerWithPool 使用指定的对象池创建BufferManager实例
+// 可以接入分级、分片或带统计的对象池
+//   - pool: 缓冲区对象池，为nil时使用buffer.NewPool()
+func NewBufferManagerWithPool(pool buffer.ObjectPool[buffer.Buffer]) BufferManager {
+	if pool == nil {
+		pool = buffer.NewPool()
+	}
 	return &bufferManagerImpl{
-		pool: buffer.NewPool(),
+		pool: pool,
 	}
 }
 
 // Acquire 从池中获取一个缓冲区
 func (bm *bufferManagerImpl) Acquire() buffer.Buffer {
-	return bm.pool.Acquire()
+	buf := bm.pool.Acquire()
+	bm.acquired.Add(1)
+	// 获取时的容量记录在缓冲区上，未释放的缓冲区仍然可以被GC回收
+	buffer.RecordAcquiredCap(buf)
+	bm.outstandingBytes.Add(int64(buf.Cap()))
+	return buf
 }
 
 // Release 将缓冲区释放回池中
 func (bm *bufferManagerImpl) Release(buf buffer.Buffer) {
+	bm.released.Add(1)
+	// 使用中扩容的缓冲区容量已经变化，按获取时记录的容量扣减；
+	// 不支持记录容量的缓冲区实现只能按当前容量扣减，估算会有偏差
+	capacity, ok := buffer.TakeAcquiredCap(buf)
+	if !ok {
+		capacity = buf.Cap()
+	}
+	bm.outstandingBytes.Add(-int64(capacity))
+
 	// 重置缓冲区后再放回池中
 	buf.Reset()
 	bm.pool.Release(buf)
 }
+
+// WithBuffer 获取一个缓冲区并传给fn，返回后总是释放缓冲区
+func (bm *bufferManagerImpl) WithBuffer(fn func(buf buffer.Buffer) error) error {
+	buf := bm.Acquire()
+	defer bm.Release(buf)
+	return fn(buf)
+}
+
+// Stats 返回缓冲区使用统计
+func (bm *bufferManagerImpl) Stats() Stats {
+	// 先读取释放次数，避免并发获取时得到负的未释放数量
+	released := bm.released.Load()
+	acquired := bm.acquired.Load()
+	return Stats{
+		Acquired:         acquired,
+		Released:         released,
+		Outstanding:      int(int64(acquired) - int64(released)),
+		OutstandingBytes: int(bm.outstandingBytes.Load()),
+	}
+}
@@ -1,6 +1,7 @@
 package manage
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/aomirun/content-router/buffer"
@@ -224,3 +225,111 @@ func TestBufferManager_ConcurrentAccess(t *testing.T) {
 		<-done
 	}
 }
+
+func TestNewBufferManagerWithPool(t *testing.T) {
+	pool := &mockObjectPool{}
+	manager := NewBufferManagerWithPool(pool)
+
+	buf := manager.Acquire()
+	if !pool.acquireCalled {
+		t.Error("Acquire should use the provided pool")
+	}
+
+	manager.Release(buf)
+	if !pool.releaseCalled {
+		t.Error("Release should use the provided pool")
+	}
+	if !pool.resetCalled {
+		t.Error("Buffer should be reset before release")
+	}
+}
+
+func TestNewBufferManagerWithNilPool(t *testing.T) {
+	manager := NewBufferManagerWithPool(nil)
+
+	buf := manager.Acquire()
+	if buf == nil {
+		t.Fatal("Acquire should fall back to the default pool")
+	}
+	manager.Release(buf)
+}
+
+func TestBufferManager_Stats(t *testing.T) {
+	manager := NewBufferManagerWithPool(buffer.NewPool(buffer.WithInitialCap(64)))
+
+	a := manager.Acquire()
+	b := manager.Acquire()
+	stats := manager.Stats()
+	if stats.Acquired != 2 || stats.Outstanding != 2 {
+		t.Errorf("Expected 2 acquired and 2 outstanding, got %+v", stats)
+	}
+	if stats.OutstandingBytes != a.Cap()+b.Cap() {
+		t.Errorf("Expected %d outstanding bytes, got %d", a.Cap()+b.Cap(), stats.OutstandingBytes)
+	}
+
+	manager.Release(a)
+	stats = manager.Stats()
+	if stats.Released != 1 || stats.Outstanding != 1 {
+		t.Errorf("Expected 1 released and 1 outstanding, got %+v", stats)
+	}
+
+	manager.Release(b)
+	stats = manager.Stats()
+	if stats.Outstanding != 0 || stats.OutstandingBytes != 0 {
+		t.Errorf("Expected nothing outstanding, got %+v", stats)
+	}
+}
+
+func TestBufferManager_StatsAfterGrowth(t *testing.T) {
+	manager := NewBufferManagerWithPool(buffer.NewPool(buffer.WithInitialCap(16)))
+
+	// 使用中扩容后释放，未释放字节数应回到零而不是变为负数
+	buf := manager.Acquire()
+	buf.Write(make([]byte, 1024))
+	manager.Release(buf)
+	if stats := manager.Stats(); stats.Outstanding != 0 || stats.OutstandingBytes != 0 {
+		t.Errorf("Expected nothing outstanding, got %+v", stats)
+	}
+
+	// 之后获取的缓冲区只计入它自己的容量
+	buf = manager.Acquire()
+	if stats := manager.Stats(); stats.OutstandingBytes != buf.Cap() {
+		t.Errorf("Expected %d outstanding bytes, got %+v", buf.Cap(), stats)
+	}
+	manager.Release(buf)
+}
+
+func TestBufferManager_WithBuffer(t *testing.T) {
+	manager := NewBufferManager()
+
+	wantErr := errors.New("handler failed")
+	err := manager.WithBuffer(func(buf buffer.Buffer) error {
+		buf.WriteString("scratch")
+		return wantErr
+	})
+	if err != wantErr {
+		t.Errorf("Expected error to be returned, got %v", err)
+	}
+	if stats := manager.Stats(); stats.Outstanding != 0 {
+		t.Errorf("Expected buffer to be released, got %+v", stats)
+	}
+}
+
+func TestBufferManager_WithBufferPanic(t *testing.T) {
+	manager := NewBufferManager()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic to propagate")
+			}
+		}()
+		manager.WithBuffer(func(buf buffer.Buffer) error {
+			panic("boom")
+		})
+	}()
+
+	if stats := manager.Stats(); stats.Released != 1 || stats.Outstanding != 0 {
+		t.Errorf("Expected buffer to be released on panic, got %+v", stats)
+	}
+}
@@ -0,0 +1,84 @@
+package manage
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Manager 定义通用对象管理器接口
+// 应用可以用它池化自定义的解码器、临时对象等，遵循与BufferManager相同的获取和释放约定
+type Manager[T any] interface {
+	// Acquire 从池中获取一个对象
+	Acquire() T
+
+	// Release 重置对象并放回池中，调用后不能再使用该对象
+	Release(obj T)
+
+	// Stats 返回对象使用统计
+	Stats() ObjectStats
+}
+
+// ObjectStats 定义通用对象管理器的使用统计
+type ObjectStats struct {
+	// Acquired 累计获取次数
+	Acquired uint64 `json:"acquired"`
+	// Released 累计释放次数
+	Released uint64 `json:"released"`
+	// Outstanding 当前已获取但尚未释放的对象数量
+	Outstanding int64 `json:"outstanding"`
+	// Created 累计新建的对象数量，接近Acquired时说明池化效果不佳
+	Created uint64 `json:"created"`
+}
+
+// managerImpl 是Manager接口的实现
+type managerImpl[T any] struct {
+	pool     sync.Pool
+	resetFn  func(T)
+	acquired atomic.Uint64
+	released atomic.Uint64
+	created  atomic.Uint64
+}
+
+// NewManager 创建一个通用对象管理器
+//   - newFn: 创建新对象的函数，不能为nil
+//   - resetFn: 释放时重置对象的函数，为nil时不重置
+func NewManager[T any](newFn func() T, resetFn func(T)) Manager[T] {
+	if newFn == nil {
+		panic("manage: newFn must not be nil")
+	}
+	m := &managerImpl[T]{
+		resetFn: resetFn,
+	}
+	m.pool.New = func() interface{} {
+		m.created.Add(1)
+		return newFn()
+	}
+	return m
+}
+
+// Acquire 从池中获取一个对象
+func (m *managerImpl[T]) Acquire() T {
+	m.acquired.Add(1)
+	return m.pool.Get().(T)
+}
+
+// Release 重置对象并放回池中
+func (m *managerImpl[T]) Release(obj T) {
+	m.released.Add(1)
+	if m.resetFn != nil {
+		m.resetFn(obj)
+	}
+	m.pool.Put(obj)
+}
+
+// Stats 返回对象使用统计
+func (m *managerImpl[T]) Stats() ObjectStats {
+	released := m.released.Load()
+	acquired := m.acquired.Load()
+	return ObjectStats{
+		Acquired:    acquired,
+		Released:    released,
+		Outstanding: int64(acquired) - int64(released),
+		Created:     m.created.Load(),
+	}
+}
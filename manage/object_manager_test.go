@@ -0,0 +1,51 @@
+package manage
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestManager_AcquireRelease(t *testing.T) {
+	resets := 0
+	manager := NewManager(func() *bytes.Buffer {
+		return new(bytes.Buffer)
+	}, func(b *bytes.Buffer) {
+		resets++
+		b.Reset()
+	})
+
+	obj := manager.Acquire()
+	obj.WriteString("scratch")
+	if stats := manager.Stats(); stats.Acquired != 1 || stats.Outstanding != 1 || stats.Created != 1 {
+		t.Errorf("Unexpected stats after acquire: %+v", stats)
+	}
+
+	manager.Release(obj)
+	if resets != 1 {
+		t.Errorf("Expected resetFn to be called once, got %d", resets)
+	}
+	if obj.Len() != 0 {
+		t.Error("Released object should be reset")
+	}
+	if stats := manager.Stats(); stats.Released != 1 || stats.Outstanding != 0 {
+		t.Errorf("Unexpected stats after release: %+v", stats)
+	}
+}
+
+func TestManager_NilReset(t *testing.T) {
+	manager := NewManager(func() []int { return make([]int, 0, 8) }, nil)
+	obj := manager.Acquire()
+	manager.Release(obj)
+	if stats := manager.Stats(); stats.Released != 1 {
+		t.Errorf("Expected release to be counted, got %+v", stats)
+	}
+}
+
+func TestManager_NilNewFn(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("NewManager should panic on nil newFn")
+		}
+	}()
+	NewManager[*bytes.Buffer](nil, nil)
+}
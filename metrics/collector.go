@@ -0,0 +1,71 @@
+// Package metrics 定义与具体指标系统无关的指标采集接口
+// 路由器、对象池和中间件通过Collector上报指标，由内存注册表聚合后
+// 交给prometheus或otel子包导出，也可以由使用方对接自己的指标库
+package metrics
+
+// 内置组件上报的指标名称
+const (
+	// MessagesTotal 路由器分发的消息数，标签为route和result（ok、error、unmatched）
+	MessagesTotal = "content_router_messages_total"
+	// RouteDurationSeconds 路由器分发一条消息的耗时，标签为route
+	RouteDurationSeconds = "content_router_route_duration_seconds"
+	// InflightMessages 正在经过指标中间件处理的消息数
+	InflightMessages = "content_router_inflight_messages"
+	// MessageBytes 经过指标中间件的消息长度，标签为route
+	MessageBytes = "content_router_message_bytes"
+	// PoolEventsTotal 对象池事件数，标签为pool和event（acquire、release、miss、drop）
+	PoolEventsTotal = "content_router_pool_events_total"
+	// PoolRetainedBytes 对象池中保留的缓冲区容量总和的估计值，标签为pool
+	PoolRetainedBytes = "content_router_pool_retained_bytes"
+	// PoolDoubleReleases 对象池检测到的重复归还次数，标签为pool
+	PoolDoubleReleases = "content_router_pool_double_releases"
+)
+
+// Label 定义指标的一个标签
+type Label struct {
+	Name  string
+	Value string
+}
+
+// CounterCollector 定义计数器采集接口
+type CounterCollector interface {
+	// AddCounter 把计数器增加delta，delta不能为负数
+	AddCounter(name string, delta float64, labels ...Label)
+}
+
+// GaugeCollector 定义仪表采集接口
+type GaugeCollector interface {
+	// SetGauge 把仪表设置为value
+	SetGauge(name string, value float64, labels ...Label)
+}
+
+// HistogramCollector 定义直方图采集接口
+type HistogramCollector interface {
+	// ObserveHistogram 记录一个观测值
+	ObserveHistogram(name string, value float64, labels ...Label)
+}
+
+// Collector 定义指标采集接口
+// 组合了计数器、仪表和直方图，实现必须是线程安全的，并且在调用路径上足够轻量
+type Collector interface {
+	CounterCollector
+	GaugeCollector
+	HistogramCollector
+}
+
+// nopCollector 是丢弃所有指标的Collector实现
+type nopCollector struct{}
+
+// Nop 返回丢弃所有指标的Collector
+func Nop() Collector {
+	return nopCollector{}
+}
+
+// AddCounter 丢弃计数器
+func (nopCollector) AddCounter(string, float64, ...Label) {}
+
+// SetGauge 丢弃仪表
+func (nopCollector) SetGauge(string, float64, ...Label) {}
+
+// ObserveHistogram 丢弃观测值
+func (nopCollector) ObserveHistogram(string, float64, ...Label) {}
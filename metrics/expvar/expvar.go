@@ -0,0 +1,93 @@
+// Package expvar 通过标准库expvar发布路由器、对象池和队列的统计信息
+// 发布后可以直接从/debug/vars读取，不需要额外的依赖
+package expvar
+
+import (
+	"errors"
+	std_expvar "expvar"
+	"sync"
+
+	"github.com/aomirun/content-router/buffer"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/metrics"
+)
+
+// ErrAlreadyPublished 表示命名空间已经被发布过
+var ErrAlreadyPublished = errors.New("expvar: namespace already published")
+
+// publishMu 使检查命名空间和发布成为原子操作，std_expvar.Publish在重名时会panic
+var publishMu sync.Mutex
+
+// Publish 在namespace下发布统计信息
+// 统计信息在每次读取时计算，读取/debug/vars的开销只在被抓取时产生
+//   - namespace: expvar变量名，例如"content_router"
+//   - opts: 需要发布的路由器、对象池和队列，见metrics.WithRouter、metrics.WithPool和metrics.WithQueue
+//
+// 返回: namespace已经存在时返回ErrAlreadyPublished
+func Publish(namespace string, opts ...metrics.SourceOption) error {
+	p := &publisher{metrics.NewSources(opts...)}
+
+	publishMu.Lock()
+	defer publishMu.Unlock()
+	if std_expvar.Get(namespace) != nil {
+		return ErrAlreadyPublished
+	}
+	std_expvar.Publish(namespace, std_expvar.Func(p.snapshot))
+	return nil
+}
+
+// snapshot 是发布的JSON结构
+type snapshot struct {
+	Routers map[string]routerSnapshot `json:"routers,omitempty"`
+	Pools   map[string]poolSnapshot   `json:"pools,omitempty"`
+	Queues  map[string]int            `json:"queues,omitempty"`
+}
+
+// publisher 读取需要发布的统计来源
+type publisher struct {
+	sources metrics.Sources
+}
+
+// routerSnapshot 是一个路由器的统计信息
+type routerSnapshot struct {
+	Buffers  manage.Stats        `json:"buffers"`
+	Contexts manage.ContextStats `json:"contexts"`
+}
+
+// poolSnapshot 是一个对象池的统计信息，对象池实现了Size() int时包含size
+type poolSnapshot struct {
+	buffer.PoolStats
+	Size *int `json:"size,omitempty"`
+}
+
+// snapshot 读取所有统计来源
+func (p *publisher) snapshot() any {
+	s := snapshot{}
+	if len(p.sources.Routers) > 0 {
+		s.Routers = make(map[string]routerSnapshot, len(p.sources.Routers))
+		for name, r := range p.sources.Routers {
+			s.Routers[name] = routerSnapshot{
+				Buffers:  r.BufferManager().Stats(),
+				Contexts: r.ContextManager().Stats(),
+			}
+		}
+	}
+	if len(p.sources.Pools) > 0 {
+		s.Pools = make(map[string]poolSnapshot, len(p.sources.Pools))
+		for name, pool := range p.sources.Pools {
+			ps := poolSnapshot{PoolStats: pool.Stats()}
+			if sized, ok := pool.(interface{ Size() int }); ok {
+				size := sized.Size()
+				ps.Size = &size
+			}
+			s.Pools[name] = ps
+		}
+	}
+	if len(p.sources.Queues) > 0 {
+		s.Queues = make(map[string]int, len(p.sources.Queues))
+		for name, length := range p.sources.Queues {
+			s.Queues[name] = length()
+		}
+	}
+	return s
+}
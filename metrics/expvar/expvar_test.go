@@ -0,0 +1,84 @@
+package expvar
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	std_expvar "expvar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/metrics"
+	"github.com/aomirun/content-router/router"
+)
+
+func TestPublish(t *testing.T) {
+	r := router.NewRouter()
+	r.Match("ping", func(ctx router_context.Context) error { return nil })
+	buf := buffer.NewBuffer()
+	buf.Write([]byte("ping"))
+	r.Route(context.Background(), buf)
+
+	pool := buffer.NewBoundedPool(4)
+	pool.Release(pool.Acquire())
+
+	queue := make(chan int, 8)
+	queue <- 1
+	queue <- 2
+
+	err := Publish("test_publish",
+		metrics.WithRouter("main", r),
+		metrics.WithPool("bounded", pool.(buffer.StatsProvider)),
+		metrics.WithQueue("jobs", func() int { return len(queue) }),
+	)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	var got snapshot
+	if err := json.Unmarshal([]byte(std_expvar.Get("test_publish").String()), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if c := got.Routers["main"].Contexts; c.Acquired != 1 || c.Outstanding != 0 {
+		t.Errorf("router contexts = %+v", c)
+	}
+	p := got.Pools["bounded"]
+	if p.Acquires != 1 || p.Releases != 1 || p.Size == nil || *p.Size != 1 {
+		t.Errorf("pool = %+v", p)
+	}
+	if got.Queues["jobs"] != 2 {
+		t.Errorf("queues = %v", got.Queues)
+	}
+
+	// 值在每次读取时重新计算
+	<-queue
+	if err := json.Unmarshal([]byte(std_expvar.Get("test_publish").String()), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.Queues["jobs"] != 1 {
+		t.Errorf("queues after receive = %v", got.Queues)
+	}
+}
+
+func TestPublish_Duplicate(t *testing.T) {
+	if err := Publish("test_duplicate"); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if err := Publish("test_duplicate"); !errors.Is(err, ErrAlreadyPublished) {
+		t.Errorf("second Publish = %v, want ErrAlreadyPublished", err)
+	}
+}
+
+func TestPublish_DebugVars(t *testing.T) {
+	if err := Publish("test_debug_vars", metrics.WithQueue("q", func() int { return 3 })); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	std_expvar.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/vars", nil))
+	if !strings.Contains(rec.Body.String(), `"test_debug_vars": {"queues":{"q":3}}`) {
+		t.Errorf("/debug/vars does not contain namespace:\n%s", rec.Body.String())
+	}
+}
@@ -0,0 +1,203 @@
+package otel
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aomirun/content-router/metrics"
+)
+
+// 按OpenTelemetry语义约定命名的属性
+const (
+	// AttrRoute 是路由名称属性
+	AttrRoute = "content_router.route"
+	// AttrResult 是分发结果属性（ok、error、unmatched）
+	AttrResult = "content_router.result"
+	// AttrRouter 是路由器名称属性，对应metrics.WithRouter的name
+	AttrRouter = "content_router.router"
+	// AttrPool 是对象池名称属性
+	AttrPool = "content_router.pool"
+	// AttrPoolEvent 是对象池事件属性（acquire、release、miss、drop）
+	AttrPoolEvent = "content_router.pool.event"
+	// AttrQueue 是队列名称属性，对应metrics.WithQueue的name
+	AttrQueue = "content_router.queue"
+)
+
+// instrument 描述一个内置指标对应的OpenTelemetry instrument
+type instrument struct {
+	name        string
+	unit        string
+	description string
+	// attrs 把metrics标签名映射为属性名
+	attrs map[string]string
+}
+
+// instruments 是内置组件上报的指标，单位使用UCUM代码
+var instruments = map[string]instrument{
+	metrics.MessagesTotal: {
+		name: "content_router.messages", unit: "{message}",
+		description: "Messages dispatched by the router",
+		attrs:       map[string]string{"route": AttrRoute, "result": AttrResult},
+	},
+	metrics.RouteDurationSeconds: {
+		name: "content_router.route.duration", unit: "s",
+		description: "Time spent dispatching a message",
+		attrs:       map[string]string{"route": AttrRoute},
+	},
+	metrics.InflightMessages: {
+		name: "content_router.messages.inflight", unit: "{message}",
+		description: "Messages currently being processed",
+	},
+	metrics.MessageBytes: {
+		name: "content_router.message.size", unit: "By",
+		description: "Size of processed messages",
+		attrs:       map[string]string{"route": AttrRoute},
+	},
+	metrics.PoolEventsTotal: {
+		name: "content_router.pool.events", unit: "{event}",
+		description: "Buffer pool events",
+		attrs:       map[string]string{"pool": AttrPool, "event": AttrPoolEvent},
+	},
+	metrics.PoolRetainedBytes: {
+		name: "content_router.pool.retained", unit: "By",
+		description: "Estimated capacity of buffers retained by the pool",
+		attrs:       map[string]string{"pool": AttrPool},
+	},
+	metrics.PoolDoubleReleases: {
+		name: "content_router.pool.double_releases", unit: "{release}",
+		description: "Double releases detected by the pool",
+		attrs:       map[string]string{"pool": AttrPool},
+	},
+}
+
+// WithSemanticConventions 按OpenTelemetry语义约定导出内置指标
+// 指标名称改为以"."分隔的形式（例如content_router.route.duration），标签改为带命名空间的属性（例如content_router.route），
+// 便于与其他OpenTelemetry指标一起查询。默认保留与Prometheus导出一致的名称，不影响已有的仪表盘
+func WithSemanticConventions() Option {
+	return func(e *exporter) {
+		e.semantic = true
+	}
+}
+
+// WithSources 在每次导出时上报路由器、对象池和队列的使用情况
+// 统计来源通过metrics.WithRouter、metrics.WithPool和metrics.WithQueue指定，
+// 名称分别作为AttrRouter、AttrPool和AttrQueue属性；对象池同时实现了Size() int时一并上报池中可用对象数量
+func WithSources(opts ...metrics.SourceOption) Option {
+	return func(e *exporter) {
+		for _, opt := range opts {
+			opt(&e.sources)
+		}
+	}
+}
+
+// describe 为内置指标补充单位和说明，启用语义约定时改写名称
+// 返回: 启用语义约定时标签名到属性名的映射，否则为nil
+func (e *exporter) describe(m *metric) map[string]string {
+	inst, ok := instruments[m.Name]
+	if !ok {
+		return nil
+	}
+	m.Unit, m.Description = inst.unit, inst.description
+	if !e.semantic {
+		return nil
+	}
+	m.Name = inst.name
+	return inst.attrs
+}
+
+// observe 读取WithSources注册的统计来源
+// 这些指标没有对应的metrics名称，始终按语义约定命名
+func (e *exporter) observe(now time.Time) []metric {
+	var ms []*metric
+	start := nanos(e.start)
+	at := nanos(now)
+	gaugeOf := func(name, unit, description string) *metric {
+		m := &metric{Name: name, Unit: unit, Description: description, Gauge: &gauge{}}
+		ms = append(ms, m)
+		return m
+	}
+	sumOf := func(name, unit, description string) *metric {
+		m := &metric{Name: name, Unit: unit, Description: description,
+			Sum: &sum{AggregationTemporality: temporalityCumulative, IsMonotonic: true}}
+		ms = append(ms, m)
+		return m
+	}
+	point := func(value float64, attrs ...keyValue) numberDataPoint {
+		return numberDataPoint{Attributes: attrs, TimeUnixNano: at, AsDouble: value}
+	}
+	cumulative := func(value float64, attrs ...keyValue) numberDataPoint {
+		p := point(value, attrs...)
+		p.StartTimeUnixNano = start
+		return p
+	}
+
+	if len(e.sources.Routers) > 0 {
+		acquired := sumOf("content_router.buffers.acquired", "{buffer}", "Buffers acquired from the router's buffer manager")
+		outstanding := gaugeOf("content_router.buffers.outstanding", "{buffer}", "Buffers acquired and not yet released")
+		outstandingBytes := gaugeOf("content_router.buffers.outstanding.size", "By", "Estimated capacity of outstanding buffers")
+		contexts := gaugeOf("content_router.contexts.outstanding", "{context}", "Router contexts acquired and not yet released")
+		for _, name := range sortedKeys(e.sources.Routers) {
+			attr := stringAttr(AttrRouter, name)
+			r := e.sources.Routers[name]
+			buffers, ctxs := r.BufferManager().Stats(), r.ContextManager().Stats()
+			acquired.Sum.DataPoints = append(acquired.Sum.DataPoints, cumulative(float64(buffers.Acquired), attr))
+			outstanding.Gauge.DataPoints = append(outstanding.Gauge.DataPoints, point(float64(buffers.Outstanding), attr))
+			outstandingBytes.Gauge.DataPoints = append(outstandingBytes.Gauge.DataPoints, point(float64(buffers.OutstandingBytes), attr))
+			contexts.Gauge.DataPoints = append(contexts.Gauge.DataPoints, point(float64(ctxs.Outstanding), attr))
+		}
+	}
+
+	if len(e.sources.Pools) > 0 {
+		acquires := sumOf("content_router.pool.acquires", "{buffer}", "Buffers acquired from the pool")
+		misses := sumOf("content_router.pool.misses", "{buffer}", "Acquires that allocated a new buffer because the pool was empty")
+		dropped := sumOf("content_router.pool.dropped", "{buffer}", "Released buffers dropped for exceeding the retained capacity")
+		utilization := gaugeOf("content_router.pool.utilization", "1", "Fraction of acquires served from the pool since start")
+		retained := gaugeOf("content_router.pool.retained", "By", "Estimated capacity of buffers retained by the pool")
+		var idle *metric
+		for _, name := range sortedKeys(e.sources.Pools) {
+			attr := stringAttr(AttrPool, name)
+			pool := e.sources.Pools[name]
+			stats := pool.Stats()
+			acquires.Sum.DataPoints = append(acquires.Sum.DataPoints, cumulative(float64(stats.Acquires), attr))
+			misses.Sum.DataPoints = append(misses.Sum.DataPoints, cumulative(float64(stats.Misses), attr))
+			dropped.Sum.DataPoints = append(dropped.Sum.DataPoints, cumulative(float64(stats.Dropped), attr))
+			if stats.Acquires > 0 {
+				hits := stats.Acquires - min(stats.Misses, stats.Acquires)
+				utilization.Gauge.DataPoints = append(utilization.Gauge.DataPoints, point(float64(hits)/float64(stats.Acquires), attr))
+			}
+			retained.Gauge.DataPoints = append(retained.Gauge.DataPoints, point(float64(stats.RetainedBytes), attr))
+			if sized, ok := pool.(interface{ Size() int }); ok {
+				if idle == nil {
+					idle = gaugeOf("content_router.pool.idle", "{buffer}", "Buffers available in the pool")
+				}
+				idle.Gauge.DataPoints = append(idle.Gauge.DataPoints, point(float64(sized.Size()), attr))
+			}
+		}
+	}
+
+	if len(e.sources.Queues) > 0 {
+		depth := gaugeOf("content_router.queue.depth", "{message}", "Messages waiting in the queue")
+		for _, name := range sortedKeys(e.sources.Queues) {
+			depth.Gauge.DataPoints = append(depth.Gauge.DataPoints, point(float64(e.sources.Queues[name]()), stringAttr(AttrQueue, name)))
+		}
+	}
+
+	// 没有数据点的指标不导出
+	out := make([]metric, 0, len(ms))
+	for _, m := range ms {
+		if (m.Gauge != nil && len(m.Gauge.DataPoints) > 0) || (m.Sum != nil && len(m.Sum.DataPoints) > 0) {
+			out = append(out, *m)
+		}
+	}
+	return out
+}
+
+// sortedKeys 返回按名称排序的键，使导出结果稳定
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
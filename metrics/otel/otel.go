@@ -0,0 +1,262 @@
+// Package otel 以OTLP/HTTP（JSON编码）协议把metrics.Registry中的指标推送到OpenTelemetry Collector
+// 不依赖OpenTelemetry SDK，计数器和直方图按累计（cumulative）时间性导出
+package otel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aomirun/content-router/metrics"
+)
+
+const (
+	// DefaultInterval 是默认的导出间隔，与OpenTelemetry SDK的默认值相同
+	DefaultInterval = time.Minute
+	// DefaultServiceName 是默认的service.name资源属性
+	DefaultServiceName = "content-router"
+	// scopeName 是导出指标的instrumentation scope名称
+	scopeName = "github.com/aomirun/content-router"
+	// temporalityCumulative 是OTLP中AGGREGATION_TEMPORALITY_CUMULATIVE的值
+	temporalityCumulative = 2
+)
+
+// Exporter 定义OTLP指标导出器接口
+type Exporter interface {
+	// Export 立即导出一次当前指标
+	Export(ctx context.Context) error
+	// Run 按导出间隔持续导出，直到ctx被取消
+	// 返回前再导出一次，避免丢失最后一个间隔的数据；返回ctx的错误
+	Run(ctx context.Context) error
+}
+
+// Option 定义导出器的配置选项
+type Option func(*exporter)
+
+// WithInterval 设置Run的导出间隔，默认DefaultInterval
+func WithInterval(d time.Duration) Option {
+	return func(e *exporter) {
+		e.interval = d
+	}
+}
+
+// WithHTTPClient 设置发送请求使用的HTTP客户端，默认http.DefaultClient
+func WithHTTPClient(client *http.Client) Option {
+	return func(e *exporter) {
+		e.client = client
+	}
+}
+
+// WithServiceName 设置service.name资源属性，默认DefaultServiceName
+func WithServiceName(name string) Option {
+	return func(e *exporter) {
+		e.serviceName = name
+	}
+}
+
+// WithHeader 设置每个请求附带的HTTP头部，例如认证信息
+func WithHeader(key, value string) Option {
+	return func(e *exporter) {
+		e.headers.Set(key, value)
+	}
+}
+
+// WithErrorHandler 设置Run中导出失败时的回调，导出失败不会停止Run
+func WithErrorHandler(fn func(err error)) Option {
+	return func(e *exporter) {
+		e.onError = fn
+	}
+}
+
+// exporter 是Exporter接口的具体实现
+type exporter struct {
+	reg         metrics.Registry
+	endpoint    string
+	interval    time.Duration
+	client      *http.Client
+	serviceName string
+	headers     http.Header
+	onError     func(err error)
+	semantic    bool
+	sources     metrics.Sources
+	// start 是累计指标的起始时间
+	start time.Time
+}
+
+// NewExporter 创建OTLP指标导出器
+//   - reg: 指标注册表
+//   - endpoint: OTLP/HTTP指标接收地址，例如"http://localhost:4318/v1/metrics"
+func NewExporter(reg metrics.Registry, endpoint string, opts ...Option) Exporter {
+	e := &exporter{
+		reg:         reg,
+		endpoint:    endpoint,
+		interval:    DefaultInterval,
+		client:      http.DefaultClient,
+		serviceName: DefaultServiceName,
+		headers:     make(http.Header),
+		sources:     metrics.NewSources(),
+		start:       time.Now(),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Run 按导出间隔持续导出
+func (e *exporter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+			e.report(e.Export(flushCtx))
+			cancel()
+			return ctx.Err()
+		case <-ticker.C:
+			e.report(e.Export(ctx))
+		}
+	}
+}
+
+// report 把导出错误交给回调
+func (e *exporter) report(err error) {
+	if err != nil && e.onError != nil {
+		e.onError(err)
+	}
+}
+
+// Export 立即导出一次当前指标
+func (e *exporter) Export(ctx context.Context) error {
+	body, err := json.Marshal(e.request(e.reg.Snapshot(), time.Now()))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range e.headers {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("otel: export failed: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// request 把指标快照和统计来源转换为ExportMetricsServiceRequest的JSON结构
+// 统计来源的指标与注册表中的指标同名时（例如启用语义约定后的对象池保留容量），只导出统计来源读取的当前值
+func (e *exporter) request(families []metrics.Family, now time.Time) exportRequest {
+	observed := e.observe(now)
+	names := make(map[string]bool, len(observed))
+	for _, m := range observed {
+		names[m.Name] = true
+	}
+
+	ms := make([]metric, 0, len(families)+len(observed))
+	for _, fam := range families {
+		m := metric{Name: fam.Name}
+		rename := e.describe(&m)
+		if names[m.Name] {
+			continue
+		}
+		switch fam.Kind {
+		case metrics.KindCounter:
+			m.Sum = &sum{AggregationTemporality: temporalityCumulative, IsMonotonic: true}
+			for _, s := range fam.Series {
+				m.Sum.DataPoints = append(m.Sum.DataPoints, numberPoint(s, now, rename))
+			}
+		case metrics.KindGauge:
+			m.Gauge = &gauge{}
+			for _, s := range fam.Series {
+				p := numberPoint(s, now, rename)
+				p.StartTimeUnixNano = ""
+				m.Gauge.DataPoints = append(m.Gauge.DataPoints, p)
+			}
+		case metrics.KindHistogram:
+			m.Histogram = &histogram{AggregationTemporality: temporalityCumulative}
+			for _, s := range fam.Series {
+				m.Histogram.DataPoints = append(m.Histogram.DataPoints, histogramPoint(s, now, rename))
+			}
+		default:
+			continue
+		}
+		ms = append(ms, m)
+	}
+	ms = append(ms, observed...)
+
+	return exportRequest{ResourceMetrics: []resourceMetrics{{
+		Resource: resource{Attributes: []keyValue{stringAttr("service.name", e.serviceName)}},
+		ScopeMetrics: []scopeMetrics{{
+			Scope:   scope{Name: scopeName},
+			Metrics: ms,
+		}},
+	}}}
+}
+
+// numberPoint 创建计数器或仪表的数据点
+func numberPoint(s metrics.Series, now time.Time, rename map[string]string) numberDataPoint {
+	return numberDataPoint{
+		Attributes:        attributes(s.Labels, rename),
+		StartTimeUnixNano: nanos(s.Start),
+		TimeUnixNano:      nanos(now),
+		AsDouble:          s.Value,
+	}
+}
+
+// histogramPoint 创建直方图的数据点
+func histogramPoint(s metrics.Series, now time.Time, rename map[string]string) histogramDataPoint {
+	counts := make([]string, len(s.BucketCounts))
+	for i, n := range s.BucketCounts {
+		counts[i] = strconv.FormatUint(n, 10)
+	}
+	return histogramDataPoint{
+		Attributes:        attributes(s.Labels, rename),
+		StartTimeUnixNano: nanos(s.Start),
+		TimeUnixNano:      nanos(now),
+		Count:             strconv.FormatUint(s.Count, 10),
+		Sum:               s.Sum,
+		BucketCounts:      counts,
+		ExplicitBounds:    s.Bounds,
+	}
+}
+
+// attributes 把标签转换为OTLP属性
+//   - rename: 标签名到属性名的映射，不在映射中的标签保留原名
+func attributes(labels []metrics.Label, rename map[string]string) []keyValue {
+	attrs := make([]keyValue, len(labels))
+	for i, l := range labels {
+		key := l.Name
+		if renamed, ok := rename[key]; ok {
+			key = renamed
+		}
+		attrs[i] = stringAttr(key, l.Value)
+	}
+	return attrs
+}
+
+// stringAttr 创建字符串属性
+func stringAttr(key, value string) keyValue {
+	return keyValue{Key: key, Value: anyValue{StringValue: value}}
+}
+
+// nanos 返回Unix纳秒时间戳，OTLP的JSON编码中64位整数使用字符串
+func nanos(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,260 @@
+package otel
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/metrics"
+	"github.com/aomirun/content-router/router"
+)
+
+// collector 是记录收到的导出请求的测试服务器
+type collector struct {
+	server   *httptest.Server
+	requests chan map[string]any
+	status   int
+}
+
+// newCollector 创建测试用的OTLP接收端
+func newCollector(t *testing.T, status int) *collector {
+	c := &collector{requests: make(chan map[string]any, 16), status: status}
+	c.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/metrics" || r.Header.Get("Content-Type") != "application/json" || r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		var body map[string]any
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &body)
+		c.requests <- body
+		w.WriteHeader(c.status)
+	}))
+	t.Cleanup(c.server.Close)
+	return c
+}
+
+// path 按路径读取JSON值，数字表示数组下标
+func path(v any, keys ...any) any {
+	for _, k := range keys {
+		switch k := k.(type) {
+		case string:
+			m, _ := v.(map[string]any)
+			v = m[k]
+		case int:
+			a, _ := v.([]any)
+			if k >= len(a) {
+				return nil
+			}
+			v = a[k]
+		}
+	}
+	return v
+}
+
+func TestExport(t *testing.T) {
+	reg := metrics.NewRegistry(metrics.WithBuckets("latency", 0.1, 1))
+	reg.AddCounter("requests_total", 3, metrics.Label{Name: "route", Value: "orders"})
+	reg.SetGauge("queue", 2)
+	reg.ObserveHistogram("latency", 0.5)
+	reg.ObserveHistogram("latency", 5)
+
+	c := newCollector(t, http.StatusOK)
+	exp := NewExporter(reg, c.server.URL+"/v1/metrics", WithServiceName("gateway"), WithHeader("Authorization", "Bearer token"))
+	if err := exp.Export(context.Background()); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	body := <-c.requests
+	rm := path(body, "resourceMetrics", 0)
+	if got := path(rm, "resource", "attributes", 0, "value", "stringValue"); got != "gateway" {
+		t.Errorf("service.name = %v, want gateway", got)
+	}
+	ms := path(rm, "scopeMetrics", 0, "metrics")
+
+	hist := path(ms, 0)
+	if path(hist, "name") != "latency" || path(hist, "histogram", "aggregationTemporality") != float64(2) {
+		t.Errorf("histogram = %v", hist)
+	}
+	hp := path(hist, "histogram", "dataPoints", 0)
+	if path(hp, "count") != "2" || path(hp, "sum") != 5.5 {
+		t.Errorf("histogram point = %v", hp)
+	}
+	counts, _ := json.Marshal(path(hp, "bucketCounts"))
+	if string(counts) != `["0","1","1"]` {
+		t.Errorf("bucketCounts = %s", counts)
+	}
+
+	if path(ms, 1, "gauge", "dataPoints", 0, "asDouble") != float64(2) {
+		t.Errorf("gauge = %v", path(ms, 1))
+	}
+
+	counter := path(ms, 2)
+	if path(counter, "sum", "isMonotonic") != true || path(counter, "sum", "dataPoints", 0, "asDouble") != float64(3) {
+		t.Errorf("counter = %v", counter)
+	}
+	if path(counter, "sum", "dataPoints", 0, "attributes", 0, "key") != "route" {
+		t.Errorf("counter attributes = %v", path(counter, "sum", "dataPoints", 0, "attributes"))
+	}
+}
+
+func TestExportError(t *testing.T) {
+	c := newCollector(t, http.StatusServiceUnavailable)
+	exp := NewExporter(metrics.NewRegistry(), c.server.URL+"/v1/metrics", WithHeader("Authorization", "Bearer token"))
+	err := exp.Export(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("Export() error = %v, want 503 error", err)
+	}
+}
+
+func TestRunFlushesOnCancel(t *testing.T) {
+	reg := metrics.NewRegistry()
+	reg.AddCounter("requests_total", 1)
+
+	c := newCollector(t, http.StatusOK)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- NewExporter(reg, c.server.URL+"/v1/metrics",
+			WithInterval(10*time.Millisecond), WithHeader("Authorization", "Bearer token")).Run(ctx)
+	}()
+
+	// 等待至少一次定时导出
+	select {
+	case <-c.requests:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for periodic export")
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Run() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for Run to return")
+	}
+	// 取消后的最后一次导出
+	if len(c.requests) == 0 {
+		t.Error("expected a final export after cancel")
+	}
+}
+
+// findMetric 按名称查找导出的指标
+func findMetric(req exportRequest, name string) *metric {
+	for i, m := range req.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		if m.Name == name {
+			return &req.ResourceMetrics[0].ScopeMetrics[0].Metrics[i]
+		}
+	}
+	return nil
+}
+
+func TestSemanticConventions(t *testing.T) {
+	reg := metrics.NewRegistry()
+	reg.AddCounter(metrics.MessagesTotal, 2, metrics.Label{Name: "route", Value: "orders"}, metrics.Label{Name: "result", Value: "ok"})
+	reg.ObserveHistogram(metrics.RouteDurationSeconds, 0.01, metrics.Label{Name: "route", Value: "orders"})
+	reg.SetGauge("custom_gauge", 1, metrics.Label{Name: "route", Value: "orders"})
+
+	// 默认保留原名，只补充单位和说明
+	req := NewExporter(reg, "").(*exporter).request(reg.Snapshot(), time.Now())
+	if m := findMetric(req, metrics.MessagesTotal); m == nil || m.Unit != "{message}" || m.Sum.DataPoints[0].Attributes[1].Key != "route" {
+		t.Errorf("default messages metric = %+v", m)
+	}
+
+	req = NewExporter(reg, "", WithSemanticConventions()).(*exporter).request(reg.Snapshot(), time.Now())
+	m := findMetric(req, "content_router.messages")
+	if m == nil || m.Description == "" {
+		t.Fatalf("semantic messages metric = %+v", m)
+	}
+	attrs := m.Sum.DataPoints[0].Attributes
+	if len(attrs) != 2 || attrs[0].Key != AttrResult || attrs[1].Key != AttrRoute {
+		t.Errorf("attributes = %+v", attrs)
+	}
+	if d := findMetric(req, "content_router.route.duration"); d == nil || d.Unit != "s" || d.Histogram.DataPoints[0].Attributes[0].Key != AttrRoute {
+		t.Errorf("duration metric = %+v", d)
+	}
+	// 未知指标保持不变
+	if c := findMetric(req, "custom_gauge"); c == nil || c.Unit != "" || c.Gauge.DataPoints[0].Attributes[0].Key != "route" {
+		t.Errorf("custom metric = %+v", c)
+	}
+}
+
+func TestObservedSources(t *testing.T) {
+	r := router.NewRouter()
+	r.Match("ping", func(ctx router_context.Context) error { return nil })
+	buf := r.BufferManager().Acquire()
+	buf.Write([]byte("ping"))
+	r.Route(context.Background(), buf)
+
+	pool := buffer.NewBoundedPool(4)
+	pool.Release(pool.Acquire())
+	pool.Release(pool.Acquire())
+
+	queue := make(chan int, 8)
+	queue <- 1
+
+	reg := metrics.NewRegistry()
+	metrics.RecordPoolStats(reg, "bounded", pool.(buffer.StatsProvider).Stats())
+	exp := NewExporter(reg, "",
+		WithSemanticConventions(),
+		WithSources(
+			metrics.WithRouter("main", r),
+			metrics.WithPool("bounded", pool.(buffer.StatsProvider)),
+			metrics.WithQueue("jobs", func() int { return len(queue) }),
+		),
+	).(*exporter)
+	req := exp.request(reg.Snapshot(), time.Now())
+
+	value := func(name string) (float64, string) {
+		m := findMetric(req, name)
+		if m == nil {
+			t.Errorf("metric %s not exported", name)
+			return 0, ""
+		}
+		points := m.Gauge
+		if m.Sum != nil {
+			points = &gauge{DataPoints: m.Sum.DataPoints}
+		}
+		p := points.DataPoints[0]
+		return p.AsDouble, p.Attributes[0].Key + "=" + p.Attributes[0].Value.StringValue
+	}
+
+	if v, attr := value("content_router.buffers.outstanding"); v != 1 || attr != AttrRouter+"=main" {
+		t.Errorf("outstanding = %v %s, want 1", v, attr)
+	}
+	if v, _ := value("content_router.contexts.outstanding"); v != 0 {
+		t.Errorf("contexts outstanding = %v, want 0", v)
+	}
+	if v, attr := value("content_router.pool.acquires"); v != 2 || attr != AttrPool+"=bounded" {
+		t.Errorf("pool acquires = %v %s", v, attr)
+	}
+	if v, _ := value("content_router.pool.utilization"); v != 0.5 {
+		t.Errorf("pool utilization = %v, want 0.5", v)
+	}
+	if v, _ := value("content_router.pool.idle"); v != 1 {
+		t.Errorf("pool idle = %v, want 1", v)
+	}
+	if v, attr := value("content_router.queue.depth"); v != 1 || attr != AttrQueue+"=jobs" {
+		t.Errorf("queue depth = %v %s", v, attr)
+	}
+
+	// 注册表中同名的保留容量指标被统计来源取代
+	count := 0
+	for _, m := range req.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		if m.Name == "content_router.pool.retained" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("content_router.pool.retained exported %d times", count)
+	}
+	r.BufferManager().Release(buf)
+}
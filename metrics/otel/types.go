@@ -0,0 +1,88 @@
+package otel
+
+// 以下类型对应opentelemetry-proto中ExportMetricsServiceRequest的JSON编码
+
+// exportRequest 对应ExportMetricsServiceRequest
+type exportRequest struct {
+	ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+}
+
+// resourceMetrics 对应ResourceMetrics
+type resourceMetrics struct {
+	Resource     resource       `json:"resource"`
+	ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+}
+
+// resource 对应Resource
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+// scopeMetrics 对应ScopeMetrics
+type scopeMetrics struct {
+	Scope   scope    `json:"scope"`
+	Metrics []metric `json:"metrics"`
+}
+
+// scope 对应InstrumentationScope
+type scope struct {
+	Name string `json:"name"`
+}
+
+// metric 对应Metric，Sum、Gauge和Histogram中只有一个非空
+type metric struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Unit        string     `json:"unit,omitempty"`
+	Sum         *sum       `json:"sum,omitempty"`
+	Gauge       *gauge     `json:"gauge,omitempty"`
+	Histogram   *histogram `json:"histogram,omitempty"`
+}
+
+// sum 对应Sum
+type sum struct {
+	DataPoints             []numberDataPoint `json:"dataPoints"`
+	AggregationTemporality int               `json:"aggregationTemporality"`
+	IsMonotonic            bool              `json:"isMonotonic"`
+}
+
+// gauge 对应Gauge
+type gauge struct {
+	DataPoints []numberDataPoint `json:"dataPoints"`
+}
+
+// histogram 对应Histogram
+type histogram struct {
+	DataPoints             []histogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                  `json:"aggregationTemporality"`
+}
+
+// numberDataPoint 对应NumberDataPoint
+type numberDataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	AsDouble          float64    `json:"asDouble"`
+}
+
+// histogramDataPoint 对应HistogramDataPoint
+type histogramDataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	Count             string     `json:"count"`
+	Sum               float64    `json:"sum"`
+	BucketCounts      []string   `json:"bucketCounts"`
+	ExplicitBounds    []float64  `json:"explicitBounds"`
+}
+
+// keyValue 对应KeyValue
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+// anyValue 对应AnyValue，只使用字符串值
+type anyValue struct {
+	StringValue string `json:"stringValue"`
+}
@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"github.com/aomirun/content-router/buffer"
+)
+
+// PoolObserver 返回把对象池事件上报为PoolEventsTotal计数器的buffer.PoolObserver
+//   - pool: 对象池名称，作为pool标签
+//
+// 通过buffer.WithPoolObserver接入对象池
+func PoolObserver(c CounterCollector, pool string) buffer.PoolObserver {
+	// 预先创建每种事件的标签，避免在Acquire/Release的调用路径上分配
+	labels := map[buffer.PoolEvent][]Label{}
+	for _, event := range []buffer.PoolEvent{buffer.PoolEventAcquire, buffer.PoolEventRelease, buffer.PoolEventMiss, buffer.PoolEventDrop} {
+		labels[event] = []Label{{Name: "pool", Value: pool}, {Name: "event", Value: event.String()}}
+	}
+	return func(event buffer.PoolEvent, capacity int) {
+		c.AddCounter(PoolEventsTotal, 1, labels[event]...)
+	}
+}
+
+// RecordPoolStats 把对象池统计信息上报为仪表
+// 适合在导出前或定时调用，例如RecordPoolStats(c, "default", pool.Stats())
+func RecordPoolStats(c GaugeCollector, pool string, stats buffer.PoolStats) {
+	label := Label{Name: "pool", Value: pool}
+	c.SetGauge(PoolRetainedBytes, float64(stats.RetainedBytes), label)
+	c.SetGauge(PoolDoubleReleases, float64(stats.DoubleReleases), label)
+}
@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+func TestPoolObserver(t *testing.T) {
+	reg := NewRegistry()
+	pool := buffer.NewPool(buffer.WithPoolObserver(PoolObserver(reg, "main")))
+
+	buf := pool.Acquire()
+	pool.Release(buf)
+	buf = pool.Acquire()
+	pool.Release(buf)
+
+	events := map[string]float64{}
+	for _, fam := range reg.Snapshot() {
+		if fam.Name != PoolEventsTotal {
+			continue
+		}
+		for _, s := range fam.Series {
+			if s.Labels[1].Name != "pool" || s.Labels[1].Value != "main" {
+				t.Errorf("labels = %v", s.Labels)
+			}
+			events[s.Labels[0].Value] = s.Value
+		}
+	}
+	if events["acquire"] != 2 || events["release"] != 2 || events["miss"] < 1 {
+		t.Errorf("events = %v", events)
+	}
+}
+
+func TestRecordPoolStats(t *testing.T) {
+	reg := NewRegistry()
+	RecordPoolStats(reg, "main", buffer.PoolStats{RetainedBytes: 4096, DoubleReleases: 2})
+
+	got := map[string]float64{}
+	for _, fam := range reg.Snapshot() {
+		got[fam.Name] = fam.Series[0].Value
+	}
+	if got[PoolRetainedBytes] != 4096 || got[PoolDoubleReleases] != 2 {
+		t.Errorf("gauges = %v", got)
+	}
+}
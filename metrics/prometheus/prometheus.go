@@ -0,0 +1,106 @@
+// Package prometheus 以Prometheus文本格式导出metrics.Registry中的指标
+// 不依赖Prometheus客户端库，可以直接被Prometheus服务器抓取
+package prometheus
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aomirun/content-router/metrics"
+)
+
+// ContentType 是Prometheus文本格式的Content-Type
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// NewHandler 创建以Prometheus文本格式输出reg中指标的http.Handler
+//
+//	http.Handle("/metrics", prometheus.NewHandler(reg))
+func NewHandler(reg metrics.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		Write(w, reg.Snapshot())
+	})
+}
+
+// Write 把指标快照以Prometheus文本格式写入w
+func Write(w io.Writer, families []metrics.Family) error {
+	bw := bufio.NewWriter(w)
+	for _, fam := range families {
+		bw.WriteString("# TYPE ")
+		bw.WriteString(fam.Name)
+		bw.WriteByte(' ')
+		bw.WriteString(fam.Kind.String())
+		bw.WriteByte('\n')
+
+		for _, s := range fam.Series {
+			if fam.Kind != metrics.KindHistogram {
+				writeSample(bw, fam.Name, s.Labels, nil, s.Value)
+				continue
+			}
+			// Prometheus的桶是累计的
+			var cumulative uint64
+			for i, bound := range s.Bounds {
+				cumulative += s.BucketCounts[i]
+				le := metrics.Label{Name: "le", Value: formatFloat(bound)}
+				writeSample(bw, fam.Name+"_bucket", s.Labels, &le, float64(cumulative))
+			}
+			writeSample(bw, fam.Name+"_bucket", s.Labels, &metrics.Label{Name: "le", Value: "+Inf"}, float64(s.Count))
+			writeSample(bw, fam.Name+"_sum", s.Labels, nil, s.Sum)
+			writeSample(bw, fam.Name+"_count", s.Labels, nil, float64(s.Count))
+		}
+	}
+	return bw.Flush()
+}
+
+// writeSample 写入一行样本
+//   - extra: 额外的标签（直方图的le），可以为nil
+func writeSample(w *bufio.Writer, name string, labels []metrics.Label, extra *metrics.Label, value float64) {
+	w.WriteString(name)
+	if len(labels) > 0 || extra != nil {
+		w.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			writeLabel(w, l)
+		}
+		if extra != nil {
+			if len(labels) > 0 {
+				w.WriteByte(',')
+			}
+			writeLabel(w, *extra)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(value))
+	w.WriteByte('\n')
+}
+
+// labelEscaper 转义标签值中的反斜杠、双引号和换行符
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// writeLabel 写入name="value"
+func writeLabel(w *bufio.Writer, l metrics.Label) {
+	w.WriteString(l.Name)
+	w.WriteString(`="`)
+	labelEscaper.WriteString(w, l.Value)
+	w.WriteByte('"')
+}
+
+// formatFloat 按Prometheus文本格式格式化数值
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
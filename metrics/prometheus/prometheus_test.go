@@ -0,0 +1,53 @@
+package prometheus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aomirun/content-router/metrics"
+)
+
+func TestWrite(t *testing.T) {
+	reg := metrics.NewRegistry(metrics.WithBuckets("latency", 0.1, 1))
+	reg.AddCounter("requests_total", 2, metrics.Label{Name: "route", Value: `say "hi"\n`})
+	reg.AddCounter("requests_total", 1, metrics.Label{Name: "route", Value: `say "hi"\n`})
+	reg.SetGauge("queue", 1.5)
+	reg.ObserveHistogram("latency", 0.05, metrics.Label{Name: "route", Value: "a"})
+	reg.ObserveHistogram("latency", 0.5, metrics.Label{Name: "route", Value: "a"})
+	reg.ObserveHistogram("latency", 3, metrics.Label{Name: "route", Value: "a"})
+
+	var out strings.Builder
+	if err := Write(&out, reg.Snapshot()); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	want := `# TYPE latency histogram
+latency_bucket{route="a",le="0.1"} 1
+latency_bucket{route="a",le="1"} 2
+latency_bucket{route="a",le="+Inf"} 3
+latency_sum{route="a"} 3.55
+latency_count{route="a"} 3
+# TYPE queue gauge
+queue 1.5
+# TYPE requests_total counter
+requests_total{route="say \"hi\"\\n"} 3
+`
+	if out.String() != want {
+		t.Errorf("Write() output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	reg := metrics.NewRegistry()
+	reg.AddCounter(metrics.MessagesTotal, 1)
+
+	rec := httptest.NewRecorder()
+	NewHandler(reg).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, ContentType)
+	}
+	if !strings.Contains(rec.Body.String(), metrics.MessagesTotal+" 1\n") {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+}
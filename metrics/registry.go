@@ -0,0 +1,264 @@
+package metrics
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets 是直方图默认的桶上界，与Prometheus客户端的默认值相同，适合以秒为单位的耗时
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// ByteBuckets 是以字节为单位的直方图的桶上界，注册表默认用于MessageBytes
+var ByteBuckets = []float64{64, 256, 1024, 4096, 16384, 65536, 262144, 1048576}
+
+// Kind 表示指标类型
+type Kind int
+
+const (
+	// KindCounter 计数器
+	KindCounter Kind = iota
+	// KindGauge 仪表
+	KindGauge
+	// KindHistogram 直方图
+	KindHistogram
+)
+
+// String 返回指标类型名称
+func (k Kind) String() string {
+	switch k {
+	case KindCounter:
+		return "counter"
+	case KindGauge:
+		return "gauge"
+	case KindHistogram:
+		return "histogram"
+	default:
+		return "unknown"
+	}
+}
+
+// Family 定义同名指标的快照
+type Family struct {
+	Name string
+	Kind Kind
+	// Series 按标签排序的时间序列
+	Series []Series
+}
+
+// Series 定义一组标签对应的时间序列快照
+type Series struct {
+	// Labels 按名称排序的标签
+	Labels []Label
+	// Value 计数器或仪表的当前值
+	Value float64
+	// Count 直方图的观测次数
+	Count uint64
+	// Sum 直方图观测值的总和
+	Sum float64
+	// Bounds 直方图的桶上界
+	Bounds []float64
+	// BucketCounts 落入每个桶的观测次数（非累计），比Bounds多一个+Inf桶
+	BucketCounts []uint64
+	// Start 时间序列的创建时间
+	Start time.Time
+}
+
+// Registry 定义在内存中聚合指标的Collector
+// 导出器通过Snapshot读取当前值
+type Registry interface {
+	Collector
+
+	// Snapshot 返回所有指标的快照，按名称排序
+	Snapshot() []Family
+}
+
+// RegistryOption 定义注册表的配置选项
+type RegistryOption func(*registryImpl)
+
+// WithBuckets 设置指定直方图的桶上界
+func WithBuckets(name string, bounds ...float64) RegistryOption {
+	return func(r *registryImpl) {
+		r.buckets[name] = sortedBounds(bounds)
+	}
+}
+
+// WithDefaultBuckets 设置没有单独配置的直方图使用的桶上界，默认DefaultBuckets
+func WithDefaultBuckets(bounds ...float64) RegistryOption {
+	return func(r *registryImpl) {
+		r.defaultBuckets = sortedBounds(bounds)
+	}
+}
+
+// sortedBounds 复制并排序桶上界
+func sortedBounds(bounds []float64) []float64 {
+	bounds = slices.Clone(bounds)
+	slices.Sort(bounds)
+	return bounds
+}
+
+// registryImpl 是Registry接口的具体实现
+type registryImpl struct {
+	mu             sync.Mutex
+	families       map[string]*family
+	buckets        map[string][]float64
+	defaultBuckets []float64
+}
+
+// family 保存同名指标的时间序列
+type family struct {
+	kind   Kind
+	bounds []float64
+	series map[string]*series
+}
+
+// series 保存一个时间序列的当前值
+type series struct {
+	labels  []Label
+	value   float64
+	count   uint64
+	sum     float64
+	buckets []uint64
+	start   time.Time
+}
+
+// NewRegistry 创建内存指标注册表
+// 同一个名称只能用于一种指标类型，类型不一致的上报会被忽略
+func NewRegistry(opts ...RegistryOption) Registry {
+	r := &registryImpl{
+		families:       make(map[string]*family),
+		buckets:        map[string][]float64{MessageBytes: ByteBuckets},
+		defaultBuckets: DefaultBuckets,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// AddCounter 增加计数器，负数增量会被忽略
+func (r *registryImpl) AddCounter(name string, delta float64, labels ...Label) {
+	if delta < 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s := r.series(name, KindCounter, labels); s != nil {
+		s.value += delta
+	}
+}
+
+// SetGauge 设置仪表
+func (r *registryImpl) SetGauge(name string, value float64, labels ...Label) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s := r.series(name, KindGauge, labels); s != nil {
+		s.value = value
+	}
+}
+
+// ObserveHistogram 记录直方图观测值
+func (r *registryImpl) ObserveHistogram(name string, value float64, labels ...Label) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.series(name, KindHistogram, labels)
+	if s == nil {
+		return
+	}
+	bounds := r.families[name].bounds
+	// 第一个上界大于等于value的桶，都小于value时落入+Inf桶
+	i := sort.SearchFloat64s(bounds, value)
+	s.buckets[i]++
+	s.count++
+	s.sum += value
+}
+
+// series 查找或创建时间序列，指标类型不一致时返回nil
+// 调用方必须持有锁
+func (r *registryImpl) series(name string, kind Kind, labels []Label) *series {
+	f, ok := r.families[name]
+	if !ok {
+		f = &family{kind: kind, series: make(map[string]*series)}
+		if kind == KindHistogram {
+			f.bounds = r.defaultBuckets
+			if bounds, ok := r.buckets[name]; ok {
+				f.bounds = bounds
+			}
+		}
+		r.families[name] = f
+	}
+	if f.kind != kind {
+		return nil
+	}
+
+	key := labelKey(labels)
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: sortedLabels(labels), start: time.Now()}
+		if kind == KindHistogram {
+			s.buckets = make([]uint64, len(f.bounds)+1)
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Snapshot 返回所有指标的快照
+func (r *registryImpl) Snapshot() []Family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	families := make([]Family, 0, len(r.families))
+	for name, f := range r.families {
+		fam := Family{Name: name, Kind: f.kind, Series: make([]Series, 0, len(f.series))}
+		for _, s := range f.series {
+			fam.Series = append(fam.Series, Series{
+				Labels:       s.labels,
+				Value:        s.value,
+				Count:        s.count,
+				Sum:          s.sum,
+				Bounds:       f.bounds,
+				BucketCounts: slices.Clone(s.buckets),
+				Start:        s.start,
+			})
+		}
+		sort.Slice(fam.Series, func(i, j int) bool {
+			return labelKey(fam.Series[i].Labels) < labelKey(fam.Series[j].Labels)
+		})
+		families = append(families, fam)
+	}
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].Name < families[j].Name
+	})
+	return families
+}
+
+// sortedLabels 复制并按名称排序标签
+func sortedLabels(labels []Label) []Label {
+	labels = slices.Clone(labels)
+	sort.SliceStable(labels, func(i, j int) bool {
+		return labels[i].Name < labels[j].Name
+	})
+	return labels
+}
+
+// labelKey 返回与标签顺序无关的时间序列键
+func labelKey(labels []Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	sorted := labels
+	if !sort.SliceIsSorted(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name }) {
+		sorted = sortedLabels(labels)
+	}
+	var b strings.Builder
+	for _, l := range sorted {
+		b.WriteString(l.Name)
+		b.WriteByte(0xff)
+		b.WriteString(l.Value)
+		b.WriteByte(0xff)
+	}
+	return b.String()
+}
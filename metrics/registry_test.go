@@ -0,0 +1,108 @@
+package metrics
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	reg := NewRegistry(WithBuckets("latency", 1, 0.1))
+
+	reg.AddCounter("requests", 1, Label{"route", "a"}, Label{"code", "ok"})
+	// 标签顺序不影响时间序列
+	reg.AddCounter("requests", 2, Label{"code", "ok"}, Label{"route", "a"})
+	reg.AddCounter("requests", -5, Label{"code", "ok"}, Label{"route", "a"})
+	reg.AddCounter("requests", 1, Label{"route", "b"})
+	reg.SetGauge("queue", 3)
+	reg.SetGauge("queue", 1)
+	// 类型不一致的上报被忽略
+	reg.SetGauge("requests", 100)
+	for _, v := range []float64{0.05, 0.1, 0.5, 2} {
+		reg.ObserveHistogram("latency", v)
+	}
+
+	snap := reg.Snapshot()
+	names := make([]string, len(snap))
+	for i, fam := range snap {
+		names[i] = fam.Name
+	}
+	if !slices.Equal(names, []string{"latency", "queue", "requests"}) {
+		t.Fatalf("families = %v", names)
+	}
+
+	latency := snap[0].Series[0]
+	if snap[0].Kind != KindHistogram || latency.Count != 4 || latency.Sum != 2.65 {
+		t.Errorf("latency = %+v", latency)
+	}
+	if !slices.Equal(latency.Bounds, []float64{0.1, 1}) || !slices.Equal(latency.BucketCounts, []uint64{2, 1, 1}) {
+		t.Errorf("latency buckets = %v %v, want [0.1 1] [2 1 1]", latency.Bounds, latency.BucketCounts)
+	}
+
+	if queue := snap[1]; queue.Kind != KindGauge || queue.Series[0].Value != 1 {
+		t.Errorf("queue = %+v", queue)
+	}
+
+	requests := snap[2]
+	if requests.Kind != KindCounter || len(requests.Series) != 2 {
+		t.Fatalf("requests = %+v", requests)
+	}
+	a := requests.Series[0]
+	if a.Value != 3 || !slices.Equal(a.Labels, []Label{{"code", "ok"}, {"route", "a"}}) {
+		t.Errorf("requests{route=a} = %+v", a)
+	}
+	if requests.Series[1].Value != 1 {
+		t.Errorf("requests{route=b} = %+v", requests.Series[1])
+	}
+}
+
+func TestRegistryDefaultBuckets(t *testing.T) {
+	reg := NewRegistry(WithDefaultBuckets(10, 20))
+	reg.ObserveHistogram("custom", 15)
+	reg.ObserveHistogram(MessageBytes, 100)
+
+	for _, fam := range reg.Snapshot() {
+		s := fam.Series[0]
+		switch fam.Name {
+		case "custom":
+			if !slices.Equal(s.Bounds, []float64{10, 20}) {
+				t.Errorf("custom bounds = %v", s.Bounds)
+			}
+		case MessageBytes:
+			if !slices.Equal(s.Bounds, ByteBuckets) {
+				t.Errorf("message bytes bounds = %v, want ByteBuckets", s.Bounds)
+			}
+		}
+	}
+}
+
+func TestRegistryConcurrent(t *testing.T) {
+	reg := NewRegistry()
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				reg.AddCounter("n", 1)
+				reg.ObserveHistogram("h", 0.01)
+			}
+		}()
+	}
+	wg.Wait()
+	for _, fam := range reg.Snapshot() {
+		if fam.Name == "n" && fam.Series[0].Value != 8000 {
+			t.Errorf("counter = %v, want 8000", fam.Series[0].Value)
+		}
+		if fam.Name == "h" && fam.Series[0].Count != 8000 {
+			t.Errorf("histogram count = %v, want 8000", fam.Series[0].Count)
+		}
+	}
+}
+
+func TestNop(t *testing.T) {
+	c := Nop()
+	c.AddCounter("n", 1)
+	c.SetGauge("g", 1)
+	c.ObserveHistogram("h", 1)
+}
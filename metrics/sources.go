@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"github.com/aomirun/content-router/buffer"
+	"github.com/aomirun/content-router/manage"
+)
+
+// RouterStats 定义可以上报缓冲区和上下文使用统计的路由器
+// router.Router满足该接口
+type RouterStats interface {
+	// BufferManager 获取路由器的缓冲区管理器
+	BufferManager() manage.BufferManager
+	// ContextManager 获取路由器的上下文管理器
+	ContextManager() manage.ContextManager
+}
+
+// Sources 保存导出器在每次读取时查询的统计来源
+// expvar、otel等导出器通过SourceOption配置统计来源
+type Sources struct {
+	// Routers 按名称保存的路由器
+	Routers map[string]RouterStats
+	// Pools 按名称保存的对象池
+	Pools map[string]buffer.StatsProvider
+	// Queues 按名称保存的返回队列长度的函数
+	Queues map[string]func() int
+}
+
+// SourceOption 定义统计来源的配置选项
+type SourceOption func(*Sources)
+
+// NewSources 创建统计来源
+func NewSources(opts ...SourceOption) Sources {
+	s := Sources{
+		Routers: map[string]RouterStats{},
+		Pools:   map[string]buffer.StatsProvider{},
+		Queues:  map[string]func() int{},
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
+
+// WithRouter 上报路由器的缓冲区和上下文使用统计
+//   - name: 路由器名称
+func WithRouter(name string, r RouterStats) SourceOption {
+	return func(s *Sources) {
+		s.Routers[name] = r
+	}
+}
+
+// WithPool 上报对象池统计信息
+// 对象池同时实现了Size() int时，导出器一并上报池中可用对象数量
+//   - name: 对象池名称
+func WithPool(name string, pool buffer.StatsProvider) SourceOption {
+	return func(s *Sources) {
+		s.Pools[name] = pool
+	}
+}
+
+// WithQueue 上报队列长度
+//   - name: 队列名称
+//   - length: 返回当前队列长度的函数，例如func() int { return len(ch) }
+func WithQueue(name string, length func() int) SourceOption {
+	return func(s *Sources) {
+		s.Queues[name] = length
+	}
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	"github.com/aomirun/content-router/manage"
+)
+
+// testRouter 是只提供管理器的RouterStats实现
+type testRouter struct {
+	buffers  manage.BufferManager
+	contexts manage.ContextManager
+}
+
+func (r testRouter) BufferManager() manage.BufferManager   { return r.buffers }
+func (r testRouter) ContextManager() manage.ContextManager { return r.contexts }
+
+func TestNewSources(t *testing.T) {
+	r := testRouter{manage.NewBufferManager(), manage.NewContextManager()}
+	pool := buffer.NewBoundedPool(4)
+
+	s := NewSources(
+		WithRouter("main", r),
+		WithPool("bounded", pool.(buffer.StatsProvider)),
+		WithQueue("jobs", func() int { return 3 }),
+	)
+	if s.Routers["main"] != r {
+		t.Errorf("Routers = %v", s.Routers)
+	}
+	if s.Pools["bounded"] == nil {
+		t.Errorf("Pools = %v", s.Pools)
+	}
+	if length := s.Queues["jobs"]; length == nil || length() != 3 {
+		t.Errorf("Queues = %v", s.Queues)
+	}
+
+	// 没有选项时也可以直接读取
+	if empty := NewSources(); len(empty.Routers) != 0 || empty.Pools == nil {
+		t.Errorf("NewSources() = %+v", empty)
+	}
+}
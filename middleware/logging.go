@@ -24,8 +24,12 @@ func LoggingMiddleware() router.MiddlewareFunc {
 			dataPreview = string(data)
 		}
 
-		// 记录请求开始
-		fmt.Printf("Starting processing at %v, data preview: %s\n", start, dataPreview)
+		// 记录请求开始，存在追踪ID时一并记录
+		traceInfo := ""
+		if id := ctx.TraceID(); id != "" {
+			traceInfo = ", trace id: " + id
+		}
+		fmt.Printf("Starting processing at %v, data preview: %s%s\n", start, dataPreview, traceInfo)
 
 		// 执行下一个处理器
 		err := next(ctx)
@@ -33,9 +37,9 @@ func LoggingMiddleware() router.MiddlewareFunc {
 		// 记录结束时间和处理结果
 		duration := time.Since(start)
 		if err != nil {
-			fmt.Printf("Processing failed after %v, error: %v\n", duration, err)
+			fmt.Printf("Processing failed after %v, error: %v%s\n", duration, err, traceInfo)
 		} else {
-			fmt.Printf("Processing completed in %v\n", duration)
+			fmt.Printf("Processing completed in %v%s\n", duration, traceInfo)
 		}
 
 		return err
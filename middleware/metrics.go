@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"sync/atomic"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/metrics"
+	"github.com/aomirun/content-router/router"
+)
+
+// MetricsMiddleware 创建一个指标中间件
+// 该中间件上报正在处理的消息数（metrics.InflightMessages）和消息长度（metrics.MessageBytes），
+// 与router.WithMetrics上报的分发次数和耗时互为补充
+//   - c: 指标采集器
+func MetricsMiddleware(c metrics.Collector) router.MiddlewareFunc {
+	var inflight atomic.Int64
+	return func(ctx router_context.Context, next router.HandlerFunc) error {
+		c.SetGauge(metrics.InflightMessages, float64(inflight.Add(1)))
+		defer func() {
+			c.SetGauge(metrics.InflightMessages, float64(inflight.Add(-1)))
+		}()
+
+		// 执行下一个处理器，路由在处理链中匹配，因此在之后读取
+		err := next(ctx)
+
+		route := ""
+		if info := ctx.Route(); info != nil {
+			route = info.Name
+			if route == "" {
+				route = info.Pattern
+			}
+		}
+		c.ObserveHistogram(metrics.MessageBytes, float64(ctx.Buffer().Len()), metrics.Label{Name: "route", Value: route})
+		return err
+	}
+}
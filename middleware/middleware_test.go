@@ -3,6 +3,7 @@ package middleware
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -12,13 +13,26 @@ import (
 
 	"github.com/aomirun/content-router/buffer"
 	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/metrics"
 )
 
+// attachmentKey 是mockContext保存附加缓冲区使用的键
+type attachmentKey string
+
 // mockContext 是一个模拟的上下文实现，用于测试
 type mockContext struct {
 	context.Context
 	buffer buffer.Buffer
 	values map[interface{}]interface{}
+	params map[string]string
+	route  *router_context.RouteInfo
+	trace  string
+	meta   router_context.Metadata
+	abort  error
+	errors []error
+
+	cleanups []func()
+	response buffer.Buffer
 }
 
 func (m *mockContext) Get(key interface{}) interface{} {
@@ -28,6 +42,40 @@ func (m *mockContext) Get(key interface{}) interface{} {
 	return m.values[key]
 }
 
+func (m *mockContext) GetAny(key interface{}) interface{} {
+	if val, ok := m.values[key]; ok {
+		return val
+	}
+	if m.Context == nil {
+		return nil
+	}
+	return m.Context.Value(key)
+}
+
+func (m *mockContext) MustGet(key interface{}) interface{} {
+	val, ok := m.values[key]
+	if !ok {
+		panic("key does not exist")
+	}
+	return val
+}
+
+func (m *mockContext) GetOrDefault(key, def interface{}) interface{} {
+	if val, ok := m.values[key]; ok {
+		return val
+	}
+	return def
+}
+
+func (m *mockContext) GetOrSet(key interface{}, factory func() interface{}) interface{} {
+	if val, ok := m.values[key]; ok {
+		return val
+	}
+	val := factory()
+	m.Set(key, val)
+	return val
+}
+
 func (m *mockContext) GetString(key interface{}) (string, bool) {
 	if m.values == nil {
 		return "", false
@@ -112,6 +160,26 @@ func (m *mockContext) GetTime(key interface{}) (time.Time, bool) {
 	return time.Time{}, false
 }
 
+func (m *mockContext) GetDuration(key interface{}) (time.Duration, bool) {
+	return router_context.GetAs[time.Duration](m, key)
+}
+
+func (m *mockContext) GetUint(key interface{}) (uint, bool) {
+	return router_context.GetAs[uint](m, key)
+}
+
+func (m *mockContext) GetUint64(key interface{}) (uint64, bool) {
+	return router_context.GetAs[uint64](m, key)
+}
+
+func (m *mockContext) GetStringSlice(key interface{}) ([]string, bool) {
+	return router_context.GetAs[[]string](m, key)
+}
+
+func (m *mockContext) GetStringMap(key interface{}) (map[string]string, bool) {
+	return router_context.GetAs[map[string]string](m, key)
+}
+
 func (m *mockContext) Set(key, value interface{}) {
 	if m.values == nil {
 		m.values = make(map[interface{}]interface{})
@@ -119,12 +187,25 @@ func (m *mockContext) Set(key, value interface{}) {
 	m.values[key] = value
 }
 
+// SetLazy 模拟实现直接计算值
+func (m *mockContext) SetLazy(key interface{}, fn func() interface{}) {
+	m.Set(key, fn())
+}
+
 func (m *mockContext) Delete(key interface{}) {
 	if m.values != nil {
 		delete(m.values, key)
 	}
 }
 
+func (m *mockContext) Range(fn func(key, value interface{}) bool) {
+	for k, v := range m.values {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
 func (m *mockContext) Keys() []interface{} {
 	if m.values == nil {
 		return nil
@@ -140,6 +221,8 @@ func (m *mockContext) Fork() router_context.Context {
 	return &mockContext{
 		buffer: m.buffer,
 		values: m.values,
+		trace:  m.trace,
+		meta:   m.meta,
 	}
 }
 
@@ -147,9 +230,158 @@ func (m *mockContext) ForkWithBuffer(buffer buffer.Buffer) router_context.Contex
 	return &mockContext{
 		buffer: buffer,
 		values: m.values,
+		trace:  m.trace,
+		meta:   m.meta,
 	}
 }
 
+func (m *mockContext) MergeFrom(other router_context.Context, policy router_context.MergePolicy) {
+	if policy == nil {
+		policy = router_context.MergeOverwrite()
+	}
+	other.Range(func(key, value interface{}) bool {
+		current, exists := m.values[key]
+		if v, ok := policy(key, current, value, exists); ok {
+			m.Set(key, v)
+		}
+		return true
+	})
+}
+
+func (m *mockContext) Param(name string) string {
+	return m.params[name]
+}
+
+func (m *mockContext) Params() map[string]string {
+	return m.params
+}
+
+func (m *mockContext) SetParam(name, value string) {
+	if m.params == nil {
+		m.params = make(map[string]string)
+	}
+	m.params[name] = value
+}
+
+func (m *mockContext) Route() *router_context.RouteInfo {
+	return m.route
+}
+
+func (m *mockContext) SetRoute(info *router_context.RouteInfo) {
+	m.route = info
+}
+
+func (m *mockContext) Abort(err error) {
+	if err == nil {
+		err = router_context.ErrAborted
+	}
+	m.abort = err
+}
+
+func (m *mockContext) IsAborted() bool {
+	return m.abort != nil
+}
+
+func (m *mockContext) AbortError() error {
+	return m.abort
+}
+
+func (m *mockContext) Response() buffer.Buffer {
+	if m.response == nil {
+		m.response = buffer.NewBuffer()
+	}
+	return m.response
+}
+
+func (m *mockContext) HasResponse() bool {
+	return m.response != nil
+}
+
+func (m *mockContext) AddError(err error) {
+	if err != nil {
+		m.errors = append(m.errors, err)
+	}
+}
+
+func (m *mockContext) Errors() []error {
+	return m.errors
+}
+
+func (m *mockContext) Scope(name string) router_context.ScopedStore {
+	return router_context.NewScope(m, name)
+}
+
+func (m *mockContext) Copy() router_context.Context {
+	values := make(map[interface{}]interface{}, len(m.values))
+	for k, v := range m.values {
+		values[k] = v
+	}
+	var buf buffer.Buffer
+	if m.buffer != nil {
+		buf = m.buffer.Clone()
+	}
+	return &mockContext{
+		Context: m.Context,
+		buffer:  buf,
+		values:  values,
+	}
+}
+
+func (m *mockContext) TraceID() string {
+	return m.trace
+}
+
+func (m *mockContext) SetTraceID(id string) {
+	m.trace = id
+}
+
+func (m *mockContext) Metadata() router_context.Metadata {
+	return m.meta
+}
+
+func (m *mockContext) SetMetadata(md router_context.Metadata) {
+	m.meta = md
+}
+
+func (m *mockContext) RemainingTime() (time.Duration, bool) {
+	deadline, ok := m.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return max(time.Until(deadline), 0), true
+}
+
+func (m *mockContext) MustDeadline() time.Time {
+	deadline, ok := m.Deadline()
+	if !ok {
+		panic("mockContext: no deadline")
+	}
+	return deadline
+}
+
+func (m *mockContext) OnReset(fn func()) {
+	m.cleanups = append(m.cleanups, fn)
+}
+
+func (m *mockContext) SetAttachment(name string, buf buffer.Buffer) {
+	m.Set(attachmentKey(name), buf)
+}
+
+func (m *mockContext) Attachment(name string) (buffer.Buffer, bool) {
+	buf, ok := m.Get(attachmentKey(name)).(buffer.Buffer)
+	return buf, ok
+}
+
+func (m *mockContext) AttachmentNames() []string {
+	var names []string
+	for k := range m.values {
+		if name, ok := k.(attachmentKey); ok {
+			names = append(names, string(name))
+		}
+	}
+	return names
+}
+
 func (m *mockContext) Buffer() buffer.Buffer {
 	return m.buffer
 }
@@ -511,3 +743,86 @@ func TestLoggingMiddlewareWithLongData(t *testing.T) {
 		t.Errorf("Expected log output to contain first 50 characters of data: %s", expectedPreview)
 	}
 }
+
+func TestTracingMiddleware(t *testing.T) {
+	tracing := TracingMiddleware(nil)
+
+	var seen string
+	handler := func(ctx router_context.Context) error {
+		seen = ctx.TraceID()
+		return nil
+	}
+
+	// 没有追踪ID时生成新的ID
+	mockCtx := &mockContext{}
+	if err := tracing(mockCtx, handler); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(seen) != 32 {
+		t.Errorf("Expected generated trace id, got %q", seen)
+	}
+
+	// 已有追踪ID时保持不变，并随Fork传播
+	mockCtx = &mockContext{trace: "existing"}
+	tracing(mockCtx, handler)
+	if seen != "existing" {
+		t.Errorf("Expected existing trace id, got %q", seen)
+	}
+	if mockCtx.Fork().TraceID() != "existing" {
+		t.Error("Expected trace id to survive Fork")
+	}
+}
+
+func TestTracingMiddlewareExtract(t *testing.T) {
+	tracing := TracingMiddleware(func(ctx router_context.Context) string {
+		data := ctx.Buffer().Get()
+		if id, ok := bytes.CutPrefix(data, []byte("trace=")); ok {
+			return string(id)
+		}
+		return ""
+	})
+
+	mockCtx := &mockContext{buffer: &mockBuffer{data: []byte("trace=abc123")}}
+	tracing(mockCtx, func(ctx router_context.Context) error { return nil })
+	if mockCtx.TraceID() != "abc123" {
+		t.Errorf("Expected extracted trace id, got %q", mockCtx.TraceID())
+	}
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	reg := metrics.NewRegistry()
+	mw := MetricsMiddleware(reg)
+
+	var inflight float64
+	handler := func(ctx router_context.Context) error {
+		ctx.SetRoute(&router_context.RouteInfo{Pattern: "/prefix/order"})
+		for _, fam := range reg.Snapshot() {
+			if fam.Name == metrics.InflightMessages {
+				inflight = fam.Series[0].Value
+			}
+		}
+		return errors.New("failed")
+	}
+
+	mockCtx := &mockContext{buffer: &mockBuffer{data: []byte("order-123")}}
+	if err := mw(mockCtx, handler); err == nil || err.Error() != "failed" {
+		t.Fatalf("Expected handler error to pass through, got %v", err)
+	}
+	if inflight != 1 {
+		t.Errorf("Expected 1 inflight message during handling, got %v", inflight)
+	}
+
+	for _, fam := range reg.Snapshot() {
+		switch fam.Name {
+		case metrics.InflightMessages:
+			if fam.Series[0].Value != 0 {
+				t.Errorf("Expected 0 inflight messages after handling, got %v", fam.Series[0].Value)
+			}
+		case metrics.MessageBytes:
+			s := fam.Series[0]
+			if s.Labels[0].Value != "/prefix/order" || s.Count != 1 || s.Sum != 9 {
+				t.Errorf("Unexpected message size series: %+v", s)
+			}
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+)
+
+// TracingMiddleware 创建一个追踪ID中间件
+// 该中间件保证每条消息都有追踪ID，日志等中间件可以通过ctx.TraceID()串联同一条消息的处理过程
+//   - extract: 从消息中提取已有追踪ID的函数（例如读取协议头部），可以为nil
+//
+// 追踪ID的来源依次为: 上下文已有的追踪ID、extract的结果、新生成的随机ID
+func TracingMiddleware(extract func(ctx router_context.Context) string) router.MiddlewareFunc {
+	return func(ctx router_context.Context, next router.HandlerFunc) error {
+		if ctx.TraceID() == "" {
+			id := ""
+			if extract != nil {
+				id = extract(ctx)
+			}
+			if id == "" {
+				id = router_context.NewTraceID()
+			}
+			ctx.SetTraceID(id)
+		}
+
+		// 执行下一个处理器
+		return next(ctx)
+	}
+}
@@ -0,0 +1,144 @@
+// Package plugin 从Go插件（.so文件）加载路由和自定义匹配器
+// 宿主程序无需重新编译即可扩展路由逻辑。插件用-buildmode=plugin构建，
+// 必须与宿主程序使用相同的Go版本和相同版本的content-router
+//
+// 插件导出以下符号，至少导出一个:
+//
+//	// 注册路由、中间件等，返回的错误使加载失败
+//	func RegisterRoutes(r router.Router) error
+//
+//	// 自定义匹配器工厂，注册到rules包后可以在规则文件中通过名称引用
+//	func Matchers() map[string]rules.MatcherFactory
+//
+// Go插件只支持Linux、FreeBSD和macOS，并且需要启用cgo，其他平台上Open返回错误
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	std_plugin "plugin"
+	"sort"
+
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/rules"
+)
+
+// 插件导出的符号名称
+const (
+	// RegisterRoutesSymbol 是注册路由的函数名称
+	RegisterRoutesSymbol = "RegisterRoutes"
+	// MatchersSymbol 是返回匹配器工厂的函数名称
+	MatchersSymbol = "Matchers"
+)
+
+// ErrNoSymbols 表示插件没有导出任何可识别的符号
+var ErrNoSymbols = errors.New("plugin: no RegisterRoutes or Matchers symbol")
+
+// Plugin 是一个已加载的插件
+type Plugin struct {
+	// Path 插件文件路径
+	Path string
+	// Matchers 插件注册的自定义匹配器名称，按名称排序
+	Matchers []string
+
+	registerRoutes func(router.Router) error
+}
+
+// symbolLookup 定义查找插件符号的接口，由*plugin.Plugin实现
+type symbolLookup interface {
+	Lookup(name string) (std_plugin.Symbol, error)
+}
+
+// Open 加载插件并把它的匹配器工厂注册到rules包
+// 应当在解析规则文件之前调用。同一个插件只能加载一次，重复加载时匹配器名称冲突返回错误
+func Open(path string) (*Plugin, error) {
+	p, err := std_plugin.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return load(path, p)
+}
+
+// Discover 加载目录中的所有.so插件，按文件名顺序加载
+// 遇到第一个错误时停止，返回已经加载的插件和错误
+func Discover(dir string) ([]*Plugin, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.so"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	plugins := make([]*Plugin, 0, len(paths))
+	for _, path := range paths {
+		p, err := Open(path)
+		if err != nil {
+			return plugins, err
+		}
+		plugins = append(plugins, p)
+	}
+	return plugins, nil
+}
+
+// RegisterRoutes 调用插件的RegisterRoutes函数，插件没有导出该函数时什么也不做
+// 注册的路由按调用顺序排在已有路由之后，与直接调用r.Match等方法一致
+func (p *Plugin) RegisterRoutes(r router.Router) error {
+	if p.registerRoutes == nil {
+		return nil
+	}
+	if err := p.registerRoutes(r); err != nil {
+		return fmt.Errorf("plugin %s: %w", p.Path, err)
+	}
+	return nil
+}
+
+// HasRoutes 返回插件是否导出了RegisterRoutes函数
+func (p *Plugin) HasRoutes() bool {
+	return p.registerRoutes != nil
+}
+
+// load 查找插件的符号并注册匹配器工厂
+func load(path string, syms symbolLookup) (*Plugin, error) {
+	p := &Plugin{Path: path}
+
+	if sym, err := syms.Lookup(RegisterRoutesSymbol); err == nil {
+		switch fn := sym.(type) {
+		case func(router.Router) error:
+			p.registerRoutes = fn
+		case func(router.Router):
+			p.registerRoutes = func(r router.Router) error {
+				fn(r)
+				return nil
+			}
+		default:
+			return nil, fmt.Errorf("plugin %s: %s has type %T, want func(router.Router) error", path, RegisterRoutesSymbol, sym)
+		}
+	}
+
+	var factories map[string]rules.MatcherFactory
+	if sym, err := syms.Lookup(MatchersSymbol); err == nil {
+		fn, ok := sym.(func() map[string]rules.MatcherFactory)
+		if !ok {
+			return nil, fmt.Errorf("plugin %s: %s has type %T, want func() map[string]rules.MatcherFactory", path, MatchersSymbol, sym)
+		}
+		factories = fn()
+		if factories == nil {
+			factories = map[string]rules.MatcherFactory{}
+		}
+	}
+
+	if p.registerRoutes == nil && factories == nil {
+		return nil, fmt.Errorf("%w in %s", ErrNoSymbols, path)
+	}
+
+	for name := range factories {
+		p.Matchers = append(p.Matchers, name)
+	}
+	sort.Strings(p.Matchers)
+	for _, name := range p.Matchers {
+		if err := rules.RegisterMatcher(name, factories[name]); err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", path, err)
+		}
+	}
+	return p, nil
+}
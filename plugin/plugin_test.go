@@ -0,0 +1,171 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	std_plugin "plugin"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/rules"
+)
+
+var loadRuns atomic.Int64
+
+// fakeSymbols 是内存中的符号表，代替真实的插件
+type fakeSymbols map[string]std_plugin.Symbol
+
+func (f fakeSymbols) Lookup(name string) (std_plugin.Symbol, error) {
+	if sym, ok := f[name]; ok {
+		return sym, nil
+	}
+	return nil, fmt.Errorf("symbol %s not found", name)
+}
+
+func routeString(t *testing.T, r router.Router, msg string) string {
+	t.Helper()
+	buf := buffer.NewBuffer()
+	buf.WriteString(msg)
+	var out bytes.Buffer
+	if err := r.RouteTo(context.Background(), buf, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestLoad(t *testing.T) {
+	// 匹配器注册是全局的，使用-count多次运行时需要不同的名称
+	name := fmt.Sprintf("fake-always-%d", loadRuns.Add(1))
+	p, err := load("fake.so", fakeSymbols{
+		RegisterRoutesSymbol: func(r router.Router) {
+			r.Match("ping", func(ctx router_context.Context) error {
+				ctx.Response().Write([]byte("pong"))
+				return nil
+			})
+		},
+		MatchersSymbol: func() map[string]rules.MatcherFactory {
+			return map[string]rules.MatcherFactory{
+				name: func(json.RawMessage) (router.Matcher, error) {
+					return router.MatcherFunc(func(router_context.Context) bool { return true }), nil
+				},
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.HasRoutes() || !slices.Equal(p.Matchers, []string{name}) {
+		t.Fatalf("unexpected plugin: %+v", p)
+	}
+	if !slices.Contains(rules.Matchers(), name) {
+		t.Errorf("matcher not registered: %v", rules.Matchers())
+	}
+
+	r := router.NewRouter()
+	if err := p.RegisterRoutes(r); err != nil {
+		t.Fatal(err)
+	}
+	if got := routeString(t, r, "ping"); got != "pong" {
+		t.Errorf("response = %q, want pong", got)
+	}
+
+	// 再次加载时匹配器名称冲突
+	if _, err := load("fake.so", fakeSymbols{MatchersSymbol: func() map[string]rules.MatcherFactory {
+		return map[string]rules.MatcherFactory{name: nil}
+	}}); err == nil {
+		t.Error("expected error for duplicate matcher")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	errFailed := errors.New("failed")
+	p, err := load("routes.so", fakeSymbols{
+		RegisterRoutesSymbol: func(router.Router) error { return errFailed },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.RegisterRoutes(router.NewRouter()); !errors.Is(err, errFailed) || !strings.Contains(err.Error(), "routes.so") {
+		t.Errorf("err = %v, want wrapped errFailed", err)
+	}
+
+	if _, err := load("empty.so", fakeSymbols{}); !errors.Is(err, ErrNoSymbols) {
+		t.Errorf("err = %v, want ErrNoSymbols", err)
+	}
+	for name, syms := range map[string]fakeSymbols{
+		"routes":   {RegisterRoutesSymbol: func() {}},
+		"matchers": {MatchersSymbol: map[string]rules.MatcherFactory{}},
+	} {
+		if _, err := load(name, syms); err == nil || !strings.Contains(err.Error(), "has type") {
+			t.Errorf("%s: err = %v, want type error", name, err)
+		}
+	}
+}
+
+func TestDiscoverEmpty(t *testing.T) {
+	plugins, err := Discover(t.TempDir())
+	if err != nil || len(plugins) != 0 {
+		t.Errorf("Discover = %v, %v, want no plugins", plugins, err)
+	}
+}
+
+// TestOpen 构建testdata/greeter插件并加载，需要cgo
+func TestOpen(t *testing.T) {
+	if testing.Short() {
+		t.Skip("building a plugin is slow")
+	}
+	dir := t.TempDir()
+	cmd := exec.Command("go", "build", "-buildmode=plugin", "-o", filepath.Join(dir, "greeter.so"), "./testdata/greeter")
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=1")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Skipf("cannot build plugin: %v\n%s", err, out)
+	}
+
+	plugins, err := Discover(dir)
+	if err != nil {
+		// 测试二进制与插件的构建参数不同（例如-cover或-race）时无法加载，
+		// 使用-count多次运行时插件已经加载过
+		if strings.Contains(err.Error(), "different version") || strings.Contains(err.Error(), "already loaded") {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	if len(plugins) != 1 || !plugins[0].HasRoutes() || !slices.Equal(plugins[0].Matchers, []string{"greeter-upper"}) {
+		t.Fatalf("unexpected plugins: %+v", plugins)
+	}
+
+	cfg, err := rules.Parse([]byte(`{"rules": [
+		{"name": "shout", "match": {"custom": {"name": "greeter-upper"}}, "sink": {"type": "stdout"}}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	r := router.NewRouter()
+	if err := plugins[0].RegisterRoutes(r); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cfg.Apply(r, rules.WithStdout(&stdout)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := routeString(t, r, "hello world"); got != "hi from plugin" {
+		t.Errorf("response = %q, want hi from plugin", got)
+	}
+	routeString(t, r, "LOUD")
+	routeString(t, r, "quiet")
+	if stdout.String() != "LOUD\n" {
+		t.Errorf("stdout = %q, want LOUD", stdout.String())
+	}
+}
@@ -0,0 +1,34 @@
+// greeter 是测试用的插件，用-buildmode=plugin构建
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/rules"
+)
+
+// RegisterRoutes 注册问候路由
+func RegisterRoutes(r router.Router) error {
+	r.Match("hello", func(ctx router_context.Context) error {
+		ctx.Response().Write([]byte("hi from plugin"))
+		return nil
+	})
+	return nil
+}
+
+// Matchers 返回自定义匹配器工厂
+func Matchers() map[string]rules.MatcherFactory {
+	return map[string]rules.MatcherFactory{
+		"greeter-upper": func(args json.RawMessage) (router.Matcher, error) {
+			return router.MatcherFunc(func(ctx router_context.Context) bool {
+				data := ctx.Buffer().Get()
+				return len(data) > 0 && bytes.Equal(data, bytes.ToUpper(data))
+			}), nil
+		},
+	}
+}
+
+func main() {}
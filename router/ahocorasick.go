@@ -0,0 +1,51 @@
+package router
+
+// ahoCorasick 是多模式字符串匹配的Aho-Corasick自动机
+// 转移表是完整的（每个状态对每个字节类都有转移），扫描时每个字节只需一次查表
+type ahoCorasick struct {
+	byteTrie
+}
+
+// newAhoCorasick 根据模式创建自动机，模式的下标即其优先级，越小越优先
+func newAhoCorasick(patterns [][]byte) *ahoCorasick {
+	ac := &ahoCorasick{byteTrie: newByteTrie(patterns)}
+
+	// 按广度优先计算失败转移，补全转移表并合并输出
+	fail := make([]int32, len(ac.out))
+	var queue []int32
+	for c := range ac.nclass {
+		if next := ac.delta[c]; next < 0 {
+			ac.delta[c] = 0
+		} else {
+			queue = append(queue, next)
+		}
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		ac.out[state] = minOutput(ac.out[state], ac.out[fail[state]])
+		for c := range ac.nclass {
+			idx := int(state)*ac.nclass + c
+			fallback := ac.delta[int(fail[state])*ac.nclass+c]
+			if next := ac.delta[idx]; next < 0 {
+				ac.delta[idx] = fallback
+			} else {
+				fail[next] = fallback
+				queue = append(queue, next)
+			}
+		}
+	}
+	return ac
+}
+
+// first 返回在data中出现的模式中最小的下标，没有模式出现时返回-1
+// 找到下标0时提前结束扫描
+func (ac *ahoCorasick) first(data []byte) int {
+	best := ac.out[0]
+	state := int32(0)
+	for i := 0; i < len(data) && best != 0; i++ {
+		state = ac.delta[int(state)*ac.nclass+int(ac.classes[data[i]])]
+		best = minOutput(best, ac.out[state])
+	}
+	return int(best)
+}
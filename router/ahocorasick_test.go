@@ -0,0 +1,151 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+)
+
+// naiveFirst 逐个检查模式，返回出现的模式中最小的下标
+func naiveFirst(patterns [][]byte, data []byte) int {
+	for i, pattern := range patterns {
+		if bytes.Contains(data, pattern) {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestAhoCorasick(t *testing.T) {
+	patterns := [][]byte{[]byte("hers"), []byte("his"), []byte("she"), []byte("he"), []byte("she")}
+	ac := newAhoCorasick(patterns)
+	tests := []struct {
+		data string
+		want int
+	}{
+		{"ushers", 0}, // she、he和hers都出现，hers优先级最高
+		{"ahishe", 1},
+		{"xshex", 2},
+		{"the", 3},
+		{"", -1},
+		{"hxrs", -1},
+	}
+	for _, tt := range tests {
+		if got := ac.first([]byte(tt.data)); got != tt.want {
+			t.Errorf("first(%q) = %d, want %d", tt.data, got, tt.want)
+		}
+	}
+
+	if got := newAhoCorasick([][]byte{[]byte("x"), {}}).first([]byte("abc")); got != 1 {
+		t.Errorf("empty pattern: first = %d, want 1", got)
+	}
+}
+
+func TestAhoCorasick_Random(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	randomBytes := func(n int) []byte {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = "abc;"[rng.IntN(4)]
+		}
+		return b
+	}
+	for round := 0; round < 50; round++ {
+		patterns := make([][]byte, 1+rng.IntN(30))
+		for i := range patterns {
+			patterns[i] = randomBytes(1 + rng.IntN(5))
+		}
+		ac := newAhoCorasick(patterns)
+		for range 50 {
+			data := randomBytes(rng.IntN(40))
+			if got, want := ac.first(data), naiveFirst(patterns, data); got != want {
+				t.Fatalf("patterns %q, data %q: first = %d, want %d", patterns, data, got, want)
+			}
+		}
+	}
+}
+
+func TestMultiContainsMatcher(t *testing.T) {
+	matcher := MultiContainsMatcher("ERROR", "FATAL", "panic:")
+	for content, want := range map[string]bool{
+		"disk ERROR":         true,
+		"goroutine panic: x": true,
+		"all good":           false,
+	} {
+		if got := matcher.Match(newTestContext(content)); got != want {
+			t.Errorf("Match(%q) = %v, want %v", content, got, want)
+		}
+	}
+	if name := matcherName(matcher); name != "router.MultiContainsMatcher" {
+		t.Errorf("matcherName = %q", name)
+	}
+}
+
+func TestRouter_MultiContains(t *testing.T) {
+	// 包含匹配路由之间夹着其他路由时，合并后的匹配结果与逐条尝试相同
+	newRouter := func(minRoutes int, got *string) Router {
+		r := NewRouter(WithMultiContains(minRoutes))
+		register := func(name string, matcher Matcher) {
+			r.RegisterRoute(router_context.RouteInfo{Name: name}, matcher, func(ctx router_context.Context) error {
+				*got = name
+				return nil
+			})
+		}
+		for i := range 10 {
+			register(fmt.Sprintf("a%d", i), ContainsMatcher(fmt.Sprintf("%d;", i)))
+		}
+		register("prefix", PrefixMatcher("7"))
+		for i := range 10 {
+			register(fmt.Sprintf("b%d", i), ContainsMatcher(fmt.Sprintf("x%d", i%4)))
+		}
+		register("empty", ContainsMatcher(""))
+		return r
+	}
+
+	var grouped, scanned string
+	rg, rs := newRouter(4, &grouped), newRouter(0, &scanned)
+	rng := rand.New(rand.NewPCG(3, 4))
+	for range 500 {
+		msg := make([]byte, rng.IntN(12))
+		for i := range msg {
+			msg[i] = "0123456789x;"[rng.IntN(12)]
+		}
+		grouped, scanned = "", ""
+		for _, r := range []Router{rg, rs} {
+			buf := buffer.NewBuffer()
+			buf.Write(msg)
+			if _, err := r.Route(context.Background(), buf); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if grouped != scanned {
+			t.Fatalf("message %q: grouped route %q, scanned route %q", msg, grouped, scanned)
+		}
+	}
+}
+
+func TestGroupRoutes(t *testing.T) {
+	var routes []routeEntry
+	for _, m := range []Matcher{
+		ContainsMatcher("a"), ContainsMatcher("b"), ContainsMatcher("c"),
+		PrefixMatcher("p"),
+		ContainsMatcher("d"), ContainsMatcher("e"),
+	} {
+		routes = append(routes, routeEntry{matcher: m})
+	}
+	groups := groupRoutes(routes, 3, 0)
+	if len(groups) != 4 || groups[0].index == nil || len(groups[0].routes) != 3 || groups[1].index != nil || groups[3].index != nil {
+		t.Errorf("groups = %+v", groups)
+	}
+	if groups := groupRoutes(routes, 2, 2); len(groups) != 3 || groups[1].index != nil || groups[2].index == nil {
+		t.Errorf("groups = %+v", groups)
+	}
+	if groups := groupRoutes(routes, 0, 0); len(groups) != len(routes) {
+		t.Errorf("disabled grouping produced %d groups", len(groups))
+	}
+}
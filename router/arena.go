@@ -0,0 +1,46 @@
+package router
+
+import (
+	"sync"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+)
+
+// arenaKey 是区域分配器在上下文中的键
+type arenaKey struct{}
+
+// WithArena 为每次Route调用启用区域分配模式
+// 处理过程中通过Arena(ctx)获取的缓冲区都从同一片内存区域切分，
+// 分发完成后一次性释放，适用于一次处理需要多个临时缓冲区的管道
+//   - slabSize: 每个内存块的大小，小于等于0时使用buffer.DefaultArenaSlabSize
+//   - opts: 缓冲区选项，WithInitialCap决定每个缓冲区的容量
+//
+// 注意：处理器不能在Route返回后继续持有从区域获取的缓冲区
+func WithArena(slabSize int, opts ...buffer.Option) Option {
+	return func(r *routerImpl) {
+		r.arenas = &sync.Pool{
+			New: func() interface{} {
+				return buffer.NewArena(slabSize, opts...)
+			},
+		}
+	}
+}
+
+// Arena 获取当前分发使用的区域分配器
+// 路由器未启用WithArena时返回false
+func Arena(ctx router_context.Context) (buffer.Arena, bool) {
+	arena, ok := ctx.Get(arenaKey{}).(buffer.Arena)
+	return arena, ok
+}
+
+// attachArena 为上下文绑定一个区域分配器
+// 返回: 分发完成后调用的释放函数
+func (r *routerImpl) attachArena(ctx router_context.Context) func() {
+	arena := r.arenas.Get().(buffer.Arena)
+	ctx.Set(arenaKey{}, arena)
+	return func() {
+		arena.Free()
+		r.arenas.Put(arena)
+	}
+}
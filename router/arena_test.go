@@ -0,0 +1,52 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+)
+
+func TestRouter_WithArena(t *testing.T) {
+	r := NewRouter(WithArena(1024, buffer.WithInitialCap(64)))
+
+	var scratch buffer.Buffer
+	r.Register(&mockMatcher{matchResult: true}, func(ctx router_context.Context) error {
+		arena, ok := Arena(ctx)
+		if !ok {
+			t.Fatal("Expected arena to be attached to context")
+		}
+		scratch = arena.Acquire()
+		scratch.Write(ctx.Buffer().Get())
+		if arena.Size() != 1 {
+			t.Errorf("Expected 1 buffer from arena, got %d", arena.Size())
+		}
+		return nil
+	})
+
+	buf := buffer.NewBuffer()
+	buf.WriteString("payload")
+	if _, err := r.Route(context.Background(), buf); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+
+	// 分发完成后区域被一次性释放
+	if scratch.Len() != 0 {
+		t.Errorf("Expected arena buffer to be freed after Route, got %q", scratch.Get())
+	}
+}
+
+func TestRouter_WithoutArena(t *testing.T) {
+	r := NewRouter()
+	r.Register(&mockMatcher{matchResult: true}, func(ctx router_context.Context) error {
+		if _, ok := Arena(ctx); ok {
+			t.Error("Expected no arena without WithArena")
+		}
+		return nil
+	})
+
+	if _, err := r.Route(context.Background(), buffer.NewBuffer()); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package router
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	router_context "github.com/aomirun/content-router/context"
+)
+
+// CaptureMatcher 创建一个捕获正则命名分组的匹配器
+// 匹配成功时，每个命名分组的内容通过ctx.SetParam写入匹配参数，
+// 处理器可以用ctx.Param(name)读取
+func CaptureMatcher(re *regexp.Regexp) Matcher {
+	names := re.SubexpNames()
+	return MatcherFunc(func(ctx router_context.Context) bool {
+		data := ctx.Buffer().Get()
+		match := re.FindSubmatchIndex(data)
+		if match == nil {
+			return false
+		}
+		for i, name := range names {
+			if name == "" || match[2*i] < 0 {
+				continue
+			}
+			ctx.SetParam(name, string(data[match[2*i]:match[2*i+1]]))
+		}
+		return true
+	})
+}
+
+// templatePart 是模板的一个片段，name为空时表示字面量
+type templatePart struct {
+	literal []byte
+	name    string
+}
+
+// TemplateMatcher 创建一个模板匹配器
+// 模板由字面量和{name}形式的占位符组成，例如"order:{id}:{action}"，
+// 整个缓冲区内容必须与模板完全匹配。占位符匹配到下一个字面量第一次出现的位置，
+// 最后一个占位符匹配剩余的全部内容，占位符不能匹配空内容
+//
+// 匹配成功时占位符的内容通过ctx.SetParam写入匹配参数
+// 模板格式错误（占位符未闭合、为空或相邻）时panic
+func TemplateMatcher(template string) Matcher {
+	parts := parseTemplate(template)
+	return MatcherFunc(func(ctx router_context.Context) bool {
+		data := ctx.Buffer().Get()
+
+		// 先完成整个匹配，再写入参数，避免匹配失败时留下部分参数
+		var captured [8][2]int
+		captures := captured[:0]
+		pos := 0
+		for i, part := range parts {
+			if part.name == "" {
+				if !bytes.HasPrefix(data[pos:], part.literal) {
+					return false
+				}
+				pos += len(part.literal)
+				continue
+			}
+
+			end := len(data)
+			if i+1 < len(parts) {
+				idx := bytes.Index(data[pos:], parts[i+1].literal)
+				if idx < 0 {
+					return false
+				}
+				end = pos + idx
+			}
+			if end == pos {
+				return false
+			}
+			captures = append(captures, [2]int{pos, end})
+			pos = end
+		}
+		if pos != len(data) {
+			return false
+		}
+
+		n := 0
+		for _, part := range parts {
+			if part.name != "" {
+				ctx.SetParam(part.name, string(data[captures[n][0]:captures[n][1]]))
+				n++
+			}
+		}
+		return true
+	})
+}
+
+// parseTemplate 将模板解析为字面量和占位符片段
+func parseTemplate(template string) []templatePart {
+	var parts []templatePart
+	for template != "" {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			parts = append(parts, templatePart{literal: []byte(template)})
+			break
+		}
+		if start > 0 {
+			parts = append(parts, templatePart{literal: []byte(template[:start])})
+		}
+
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			panic("router: unclosed placeholder in template")
+		}
+		name := template[start+1 : start+end]
+		if name == "" {
+			panic("router: empty placeholder in template")
+		}
+		if len(parts) > 0 && parts[len(parts)-1].name != "" {
+			panic("router: adjacent placeholders in template")
+		}
+		parts = append(parts, templatePart{name: name})
+		template = template[start+end+1:]
+	}
+	return parts
+}
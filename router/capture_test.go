@@ -0,0 +1,104 @@
+package router
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+)
+
+// newTestContext 创建包含指定内容的测试上下文
+func newTestContext(content string) router_context.Context {
+	buf := buffer.NewBuffer()
+	buf.WriteString(content)
+	return router_context.NewContext(context.Background(), buf)
+}
+
+func TestCaptureMatcher(t *testing.T) {
+	matcher := CaptureMatcher(regexp.MustCompile(`^user:(?P<id>\d+) action:(?P<action>\w+)`))
+
+	ctx := newTestContext("user:42 action:login")
+	if !matcher.Match(ctx) {
+		t.Fatal("Expected match")
+	}
+	if ctx.Param("id") != "42" || ctx.Param("action") != "login" {
+		t.Errorf("Unexpected params: %v", ctx.Params())
+	}
+
+	ctx = newTestContext("guest action:login")
+	if matcher.Match(ctx) {
+		t.Error("Expected no match")
+	}
+	if len(ctx.Params()) != 0 {
+		t.Errorf("Expected no params on mismatch, got %v", ctx.Params())
+	}
+}
+
+func TestTemplateMatcher(t *testing.T) {
+	tests := []struct {
+		template string
+		content  string
+		match    bool
+		params   map[string]string
+	}{
+		{"order:{id}:{action}", "order:123:ship", true, map[string]string{"id": "123", "action": "ship"}},
+		{"order:{id}:{action}", "order:123:", false, nil},
+		{"order:{id}:{action}", "invoice:123:ship", false, nil},
+		{"{topic}/events", "sensors/events", true, map[string]string{"topic": "sensors"}},
+		{"{topic}/events", "sensors/events/extra", false, nil},
+		{"GET {path} HTTP/1.1", "GET /index.html HTTP/1.1", true, map[string]string{"path": "/index.html"}},
+		{"ping", "ping", true, map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		ctx := newTestContext(tt.content)
+		if got := TemplateMatcher(tt.template).Match(ctx); got != tt.match {
+			t.Errorf("TemplateMatcher(%q).Match(%q) = %v, expected %v", tt.template, tt.content, got, tt.match)
+			continue
+		}
+		if !tt.match {
+			if len(ctx.Params()) != 0 {
+				t.Errorf("Expected no params on mismatch, got %v", ctx.Params())
+			}
+			continue
+		}
+		for name, value := range tt.params {
+			if ctx.Param(name) != value {
+				t.Errorf("TemplateMatcher(%q): param %s = %q, expected %q", tt.template, name, ctx.Param(name), value)
+			}
+		}
+	}
+}
+
+func TestTemplateMatcherInvalid(t *testing.T) {
+	for _, template := range []string{"order:{id", "order:{}", "{a}{b}"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("TemplateMatcher(%q) should panic", template)
+				}
+			}()
+			TemplateMatcher(template)
+		}()
+	}
+}
+
+func TestRouter_TemplateParams(t *testing.T) {
+	r := NewRouter()
+	var id string
+	r.Register(TemplateMatcher("order:{id}"), func(ctx router_context.Context) error {
+		id = ctx.Param("id")
+		return nil
+	})
+
+	buf := buffer.NewBuffer()
+	buf.WriteString("order:987")
+	if _, err := r.Route(context.Background(), buf); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if id != "987" {
+		t.Errorf("Expected param id=987, got %q", id)
+	}
+}
@@ -0,0 +1,106 @@
+package router
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
+)
+
+// WithReadTimeout 设置ServeConn等待下一帧的最长时间，超时后ServeConn返回超时错误
+// 0表示不限制
+func WithReadTimeout(d time.Duration) Option {
+	return func(r *routerImpl) {
+		r.readTimeout = d
+	}
+}
+
+// WithWriteTimeout 设置ServeConn写回一个响应的最长时间
+// 0表示不限制
+func WithWriteTimeout(d time.Duration) Option {
+	return func(r *routerImpl) {
+		r.writeTimeout = d
+	}
+}
+
+// ServeConn 从连接中逐帧读取消息并路由，把处理器写入的响应写回连接
+// framer同时实现frame.Encoder时，响应按相同的帧格式编码
+func (r *routerImpl) ServeConn(ctx context.Context, conn net.Conn, framer frame.Framer) error {
+	// 上下文被取消时让阻塞中的读写立即返回
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Unix(1, 0))
+	})
+	defer stop()
+
+	enc, _ := framer.(frame.Encoder)
+	w := frame.NewFrameWriter(&deadlineWriter{ctx: ctx, conn: conn, timeout: r.writeTimeout}, enc)
+	md := connMetadata(ctx, conn)
+	br := bufio.NewReader(conn)
+
+	for {
+		if r.readTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(r.readTimeout))
+		}
+		// 在设置超时之后检查，避免覆盖取消时设置的过期时间
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		buf := r.bufferManager.Acquire()
+		if err := framer.ReadFrame(br, buf); err != nil {
+			r.bufferManager.Release(buf)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		md.ReceivedAt = time.Now()
+		err := r.RouteTo(router_context.WithMetadata(ctx, md), buf, w)
+		r.bufferManager.Release(buf)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return err
+		}
+	}
+}
+
+// connMetadata 创建连接的传输层元数据，保留父上下文中已有的字段（例如连接标识）
+func connMetadata(ctx context.Context, conn net.Conn) router_context.Metadata {
+	md, _ := router_context.MetadataFromContext(ctx)
+	if md.Transport == "" && conn.LocalAddr() != nil {
+		md.Transport = conn.LocalAddr().Network()
+	}
+	if md.Source == "" && conn.RemoteAddr() != nil {
+		md.Source = conn.RemoteAddr().String()
+	}
+	return md
+}
+
+// deadlineWriter 在每次写入前设置写超时
+type deadlineWriter struct {
+	ctx     context.Context
+	conn    net.Conn
+	timeout time.Duration
+}
+
+// Write 设置写超时后写入连接
+func (w *deadlineWriter) Write(p []byte) (int, error) {
+	if w.timeout > 0 {
+		w.conn.SetWriteDeadline(time.Now().Add(w.timeout))
+	}
+	if err := w.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return w.conn.Write(p)
+}
@@ -0,0 +1,88 @@
+package router
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
+	"github.com/aomirun/content-router/manage"
+)
+
+func TestRouter_ServeConn(t *testing.T) {
+	manager := manage.NewBufferManager()
+	r := NewRouter(WithBufferManager(manager))
+	r.Match("PING", func(ctx router_context.Context) error {
+		md := ctx.Metadata()
+		if md.Transport != "pipe" || md.ReceivedAt.IsZero() {
+			t.Errorf("Unexpected metadata %+v", md)
+		}
+		ctx.Response().WriteString("PONG")
+		return nil
+	})
+	r.Match("NOTE", func(ctx router_context.Context) error {
+		return nil
+	})
+
+	server, client := net.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- r.ServeConn(context.Background(), server, frame.NewLineFramer(0))
+	}()
+
+	reader := bufio.NewReader(client)
+	client.Write([]byte("NOTE 1\nPING\n"))
+	line, err := reader.ReadString('\n')
+	if err != nil || line != "PONG\n" {
+		t.Fatalf("Expected PONG response, got %q, %v", line, err)
+	}
+
+	client.Close()
+	if err := <-done; err != nil {
+		t.Errorf("ServeConn should return nil when the peer closes, got %v", err)
+	}
+	server.Close()
+	if stats := manager.Stats(); stats.Outstanding != 0 {
+		t.Errorf("Every buffer should be released, got %+v", stats)
+	}
+}
+
+func TestRouter_ServeConnCancel(t *testing.T) {
+	r := NewRouter()
+	server, client := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- r.ServeConn(ctx, server, frame.NewLineFramer(0))
+	}()
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ServeConn did not return after cancellation")
+	}
+}
+
+func TestRouter_ServeConnReadTimeout(t *testing.T) {
+	r := NewRouter(WithReadTimeout(20 * time.Millisecond))
+	server, client := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	err := r.ServeConn(context.Background(), server, frame.NewLineFramer(0))
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("Expected a deadline error, got %v", err)
+	}
+}
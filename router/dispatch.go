@@ -0,0 +1,105 @@
+package router
+
+import (
+	router_context "github.com/aomirun/content-router/context"
+)
+
+// DefaultMultiContainsRoutes 是合并为多模式自动机的连续包含匹配路由的默认最小数量
+const DefaultMultiContainsRoutes = 8
+
+// DefaultPrefixTrieRoutes 是合并为前缀字典树的连续前缀匹配路由的默认最小数量
+const DefaultPrefixTrieRoutes = 8
+
+// routeIndex 在一组字面量路由中找出匹配数据的优先级最高的路由
+type routeIndex interface {
+	// first 返回匹配的路由在组内的下标，没有匹配时返回-1
+	first(data []byte) int
+}
+
+// dispatchGroup 是分发时按顺序尝试的一组路由
+// index为nil时只包含一条路由；否则是连续的同类字面量路由，由index一次查找选出优先级最高的匹配
+type dispatchGroup struct {
+	routes []routeEntry
+	index  routeIndex
+	// matcherE 路由的匹配器可以报告错误时不为nil，只用于单条路由的组
+	matcherE MatcherE
+}
+
+// match 返回组中匹配消息的优先级最高的路由，没有匹配时返回nil
+// 匹配器出错时返回出错的路由和错误
+func (g *dispatchGroup) match(ctx router_context.Context) (*routeEntry, error) {
+	switch {
+	case g.matcherE != nil:
+		ok, err := g.matcherE.MatchE(ctx)
+		if err != nil {
+			return &g.routes[0], err
+		}
+		if ok {
+			return &g.routes[0], nil
+		}
+	case g.index == nil:
+		if g.routes[0].matcher.Match(ctx) {
+			return &g.routes[0], nil
+		}
+	default:
+		if i := g.index.first(ctx.Buffer().Get()); i >= 0 {
+			return &g.routes[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// groupRoutes 合并连续的同类字面量路由，其余路由各自成组
+//   - 连续multiContains条及以上的包含匹配路由合并为Aho-Corasick自动机
+//   - 连续prefixTrie条及以上的前缀匹配路由（包括Match注册的前缀）合并为前缀字典树
+//
+// 组内的路由与原来的顺序相同，合并不改变匹配结果；阈值小于等于0时不合并对应的路由
+func groupRoutes(routes []routeEntry, multiContains, prefixTrie int) []dispatchGroup {
+	groups := make([]dispatchGroup, 0, len(routes))
+	for i := 0; i < len(routes); {
+		kind, ok := literalKindOf(routes[i].matcher)
+		j := i + 1
+		for ok && j < len(routes) {
+			if next, ok := literalKindOf(routes[j].matcher); !ok || next != kind {
+				break
+			}
+			j++
+		}
+
+		var index routeIndex
+		switch {
+		case !ok:
+		case kind == literalContains && multiContains > 0 && j-i >= multiContains:
+			index = newAhoCorasick(literals(routes[i:j]))
+		case kind == literalPrefix && prefixTrie > 0 && j-i >= prefixTrie:
+			index = newPrefixTrie(literals(routes[i:j]))
+		}
+		var matcherE MatcherE
+		if index == nil {
+			j = i + 1
+			matcherE, _ = asMatcherE(routes[i].matcher)
+		}
+		groups = append(groups, dispatchGroup{routes: routes[i:j], index: index, matcherE: matcherE})
+		i = j
+	}
+	return groups
+}
+
+// literalKindOf 返回可以合并的字面量匹配器的类型
+// 空的包含匹配器匹配所有消息，不参与合并
+func literalKindOf(matcher Matcher) (literalKind, bool) {
+	m, ok := matcher.(*literalMatcher)
+	if !ok || m.kind == literalSuffix || (m.kind == literalContains && len(m.literal) == 0) {
+		return 0, false
+	}
+	return m.kind, true
+}
+
+// literals 返回路由的字面量
+func literals(routes []routeEntry) [][]byte {
+	result := make([][]byte, len(routes))
+	for i, route := range routes {
+		result[i] = route.matcher.(*literalMatcher).literal
+	}
+	return result
+}
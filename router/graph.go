@@ -0,0 +1,134 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// GraphFormat 定义路由拓扑图的导出格式
+type GraphFormat int
+
+const (
+	// GraphDOT 导出为Graphviz DOT，可以用dot -Tsvg渲染
+	GraphDOT GraphFormat = iota
+	// GraphJSON 导出为Topology的JSON编码
+	GraphJSON
+)
+
+// String 返回格式名称
+func (f GraphFormat) String() string {
+	switch f {
+	case GraphDOT:
+		return "dot"
+	case GraphJSON:
+		return "json"
+	default:
+		return "unknown"
+	}
+}
+
+// ExportGraph 导出路由器的匹配器、匹配优先级、中间件链和管道
+// 用于审查和自动生成复杂路由拓扑的文档
+//   - w: 输出目标
+//   - r: 路由器
+//   - format: 导出格式
+func ExportGraph(w io.Writer, r RouteInspector, format GraphFormat) error {
+	topo := r.Inspect()
+	switch format {
+	case GraphDOT:
+		return writeDOT(w, topo)
+	case GraphJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(topo)
+	default:
+		return fmt.Errorf("router: unknown graph format %d", format)
+	}
+}
+
+// writeDOT 把路由拓扑写为DOT
+// 消息依次经过全局中间件到达分发节点，再按优先级尝试每条路由；
+// 管道不参与分发，单独画在一个子图中
+func writeDOT(w io.Writer, topo Topology) error {
+	var b strings.Builder
+	b.WriteString("digraph router {\n")
+	b.WriteString("\trankdir=LR;\n")
+	b.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+	b.WriteString("\tinput [label=\"message\", shape=ellipse];\n")
+	b.WriteString("\tdispatch [label=\"match in priority order\", shape=diamond];\n")
+
+	prev := "input"
+	if len(topo.Middlewares) > 0 {
+		b.WriteString("\tsubgraph cluster_middleware {\n\t\tlabel=\"middleware\";\n")
+		for i, name := range topo.Middlewares {
+			fmt.Fprintf(&b, "\t\tmw%d [label=%s];\n", i, dotQuote(name))
+		}
+		b.WriteString("\t}\n")
+		for i := range topo.Middlewares {
+			fmt.Fprintf(&b, "\t%s -> mw%d;\n", prev, i)
+			prev = fmt.Sprintf("mw%d", i)
+		}
+	}
+	fmt.Fprintf(&b, "\t%s -> dispatch;\n", prev)
+
+	for _, route := range topo.Routes {
+		lines := []string{strings.TrimSpace(fmt.Sprintf("#%d %s", route.Priority, route.Info.Name))}
+		if route.Info.Pattern != "" {
+			lines = append(lines, "pattern: "+route.Info.Pattern)
+		}
+		lines = append(lines, "matcher: "+route.Matcher, "handler: "+route.Handler)
+		for _, key := range slices.Sorted(maps.Keys(route.Info.Metadata)) {
+			lines = append(lines, key+"="+route.Info.Metadata[key])
+		}
+		fmt.Fprintf(&b, "\troute%d [label=%s];\n", route.Priority, dotQuote(lines...))
+		fmt.Fprintf(&b, "\tdispatch -> route%d [label=\"%d\"];\n", route.Priority, route.Priority)
+	}
+
+	if len(topo.Pipelines) > 0 {
+		b.WriteString("\tsubgraph cluster_pipelines {\n\t\tlabel=\"pipelines\";\n")
+		for _, p := range topo.Pipelines {
+			fmt.Fprintf(&b, "\t\tpipeline%d [label=%s, shape=component];\n", p.Index, dotQuote(fmt.Sprintf("pipeline #%d", p.Index), "matcher: "+p.Matcher))
+			prev := fmt.Sprintf("pipeline%d", p.Index)
+			for i, name := range p.Middlewares {
+				node := fmt.Sprintf("pipeline%d_mw%d", p.Index, i)
+				fmt.Fprintf(&b, "\t\t%s [label=%s];\n", node, dotQuote(name))
+				fmt.Fprintf(&b, "\t\t%s -> %s;\n", prev, node)
+				prev = node
+			}
+		}
+		b.WriteString("\t}\n")
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote 把多行文本编码为带引号的DOT字符串，各行以\n分隔
+func dotQuote(lines ...string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString(`\n`)
+		}
+		for _, c := range line {
+			switch c {
+			case '"', '\\':
+				b.WriteByte('\\')
+				b.WriteRune(c)
+			case '\n':
+				b.WriteString(`\n`)
+			case '\r':
+			default:
+				b.WriteRune(c)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
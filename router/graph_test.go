@@ -0,0 +1,80 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	router_context "github.com/aomirun/content-router/context"
+)
+
+func newGraphRouter() Router {
+	r := NewRouter()
+	r.Use(func(ctx router_context.Context, next HandlerFunc) error { return next(ctx) })
+	r.RegisterRoute(router_context.RouteInfo{
+		Name:     "orders",
+		Pattern:  `"type":"order"`,
+		Metadata: map[string]string{"team": "billing"},
+	}, ContainsMatcher(`"type":"order"`), handleOrders)
+	r.Match("ping", handleOrders)
+	p := r.Pipeline(PrefixMatcher("audit"))
+	p.Use(func(ctx router_context.Context, next HandlerFunc) error { return next(ctx) })
+	return r
+}
+
+func TestExportGraph_DOT(t *testing.T) {
+	var out bytes.Buffer
+	if err := ExportGraph(&out, newGraphRouter(), GraphDOT); err != nil {
+		t.Fatalf("ExportGraph: %v", err)
+	}
+	dot := out.String()
+	for _, want := range []string{
+		"digraph router {",
+		`mw0 [label="router.newGraphRouter"];`,
+		"input -> mw0;",
+		"mw0 -> dispatch;",
+		`route0 [label="#0 orders\npattern: \"type\":\"order\"\nmatcher: router.ContainsMatcher\nhandler: router.handleOrders\nteam=billing"];`,
+		`dispatch -> route1 [label="1"];`,
+		`pipeline0 [label="pipeline #0\nmatcher: router.PrefixMatcher", shape=component];`,
+		"pipeline0 -> pipeline0_mw0;",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT missing %q:\n%s", want, dot)
+		}
+	}
+	if !strings.HasSuffix(dot, "}\n") {
+		t.Errorf("DOT not terminated:\n%s", dot)
+	}
+}
+
+func TestExportGraph_JSON(t *testing.T) {
+	var out bytes.Buffer
+	if err := ExportGraph(&out, newGraphRouter(), GraphJSON); err != nil {
+		t.Fatalf("ExportGraph: %v", err)
+	}
+	var topo Topology
+	if err := json.Unmarshal(out.Bytes(), &topo); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if len(topo.Routes) != 2 || topo.Routes[0].Info.Metadata["team"] != "billing" || topo.Routes[1].Info.Pattern != "ping" {
+		t.Errorf("routes = %+v", topo.Routes)
+	}
+	if !strings.Contains(out.String(), `"priority": 1`) {
+		t.Errorf("JSON missing priority:\n%s", out.String())
+	}
+}
+
+func TestExportGraph_UnknownFormat(t *testing.T) {
+	if err := ExportGraph(&bytes.Buffer{}, NewRouter(), GraphFormat(9)); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestExportGraph_EmptyRouter(t *testing.T) {
+	var out bytes.Buffer
+	ExportGraph(&out, NewRouter(), GraphDOT)
+	if !strings.Contains(out.String(), "input -> dispatch;") {
+		t.Errorf("DOT = %s", out.String())
+	}
+}
@@ -0,0 +1,71 @@
+package router
+
+import (
+	router_context "github.com/aomirun/content-router/context"
+)
+
+// RouteMounter 定义路由分组和子路由器挂载接口
+// 团队可以在自己的路由组或路由器上注册路由和中间件，再挂载到父路由器的某个判别条件之下
+type RouteMounter interface {
+	// Group 创建一个路由组，并作为一条路由注册到当前位置
+	// 消息匹配matcher时按组内的路由继续分发，组内的中间件只包裹组内的路由
+	//  - matcher: 进入路由组的条件
+	// 返回: 路由组，可以继续注册路由、添加中间件或嵌套分组
+	//
+	// 路由组继承父路由器的分发配置（匹配器错误处理、字面量路由合并阈值），
+	// 之后对组的修改立即生效，不需要重新注册
+	Group(matcher Matcher) RouteGroup
+
+	// Mount 把子路由器挂载到以prefix开头的消息上，等价于以PrefixMatcher(prefix)注册一条转发到sub的路由
+	//  - prefix: 消息前缀，例如"ORD"；子路由器看到的是完整的消息，不会去掉前缀
+	//  - sub: 子路由器，执行自己的中间件和路由
+	//
+	// sub由NewRouter创建时在同一个上下文中分发，父路由器的上下文值、指标和事件覆盖整个处理过程，
+	// 子路由器自己的指标、事件等分发选项不生效；其他实现通过sub.Route分发。sub为nil时panic
+	Mount(prefix string, sub Router)
+}
+
+// RouteGroup 定义路由组接口
+type RouteGroup interface {
+	RouteRegistrar
+	RouteRemover
+	MiddlewareHandler
+	RouteMounter
+}
+
+// Group 创建一个路由组，并作为一条路由注册到当前位置
+func (r *routerImpl) Group(matcher Matcher) RouteGroup {
+	group := &routerImpl{
+		bufferManager: r.bufferManager,
+		ctxManager:    r.ctxManager,
+		matchErrors:   r.matchErrors,
+		multiContains: r.multiContains,
+		prefixTrie:    r.prefixTrie,
+	}
+	group.table.Store(&routeTable{})
+	r.RegisterRoute(router_context.RouteInfo{Pattern: describeMatcher(matcher)}, matcher, mountHandler(group))
+	return group
+}
+
+// Mount 把子路由器挂载到以prefix开头的消息上
+func (r *routerImpl) Mount(prefix string, sub Router) {
+	if sub == nil {
+		panic("router: mount requires a router")
+	}
+	r.RegisterRoute(router_context.RouteInfo{Pattern: prefix}, PrefixMatcher(prefix), mountHandler(sub))
+}
+
+// mountHandler 返回把消息转发到子路由器的处理器
+// 子路由器没有匹配的路由时消息视为已处理，不会继续尝试父路由器中之后的路由
+func mountHandler(sub Router) HandlerFunc {
+	if impl, ok := sub.(*routerImpl); ok {
+		// 在同一个上下文中执行子路由器当前快照的处理链，子路由器匹配的路由覆盖挂载路由的信息
+		return func(ctx router_context.Context) error {
+			return impl.table.Load().handler(impl)(ctx)
+		}
+	}
+	return func(ctx router_context.Context) error {
+		_, err := sub.Route(ctx, ctx.Buffer())
+		return err
+	}
+}
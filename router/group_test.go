@@ -0,0 +1,131 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+)
+
+// recordRoute 返回记录每条消息匹配的路由名称的处理器
+func recordRoute(got *[]string) HandlerFunc {
+	return func(ctx router_context.Context) error {
+		*got = append(*got, ctx.Route().Name)
+		return nil
+	}
+}
+
+// routeAll 依次路由messages
+func routeAll(t *testing.T, r Router, messages ...string) {
+	t.Helper()
+	for _, msg := range messages {
+		buf := buffer.NewBuffer()
+		buf.WriteString(msg)
+		if _, err := r.Route(context.Background(), buf); err != nil {
+			t.Fatalf("Route(%q): %v", msg, err)
+		}
+	}
+}
+
+func TestRouter_Mount(t *testing.T) {
+	var got, middlewares []string
+
+	orders := NewRouter()
+	orders.Use(func(ctx router_context.Context, next HandlerFunc) error {
+		middlewares = append(middlewares, "orders:"+string(ctx.Buffer().Get()))
+		return next(ctx)
+	})
+	orders.RegisterNamed("created", PrefixMatcher("ORD created"), recordRoute(&got))
+	orders.RegisterNamed("cancelled", PrefixMatcher("ORD cancelled"), func(ctx router_context.Context) error {
+		if ctx.Get("tenant") != "acme" {
+			t.Error("sub-router should see values set by the parent")
+		}
+		return recordRoute(&got)(ctx)
+	})
+
+	r := NewRouter()
+	r.Use(func(ctx router_context.Context, next HandlerFunc) error {
+		ctx.Set("tenant", "acme")
+		return next(ctx)
+	})
+	r.Mount("ORD", orders)
+	r.RegisterNamed("rest", AllMatcher(), recordRoute(&got))
+
+	routeAll(t, r, "ORD created 1", "INV paid 2", "ORD cancelled 3", "ORD unknown 4")
+
+	// 子路由器没有匹配的ORD消息不会落到父路由器之后的路由
+	want := []string{"created", "rest", "cancelled"}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Errorf("matched %v, want %v", got, want)
+	}
+	if len(middlewares) != 3 || middlewares[1] != "orders:ORD cancelled 3" {
+		t.Errorf("sub-router middleware ran for %v, want only ORD messages", middlewares)
+	}
+
+	routes := r.Routes()
+	if len(routes) != 2 || routes[0].Info.Pattern != "ORD" || routes[0].Matcher != "router.PrefixMatcher" || routes[0].Handler != "router.mountHandler" {
+		t.Errorf("routes = %+v", routes)
+	}
+}
+
+func TestRouter_Group(t *testing.T) {
+	var got []string
+	errAuth := errors.New("unauthorized")
+
+	r := NewRouter()
+	invoices := r.Group(PrefixMatcher("INV"))
+	r.RegisterNamed("rest", AllMatcher(), recordRoute(&got))
+
+	invoices.Use(func(ctx router_context.Context, next HandlerFunc) error {
+		if ContainsMatcher("token").Match(ctx) {
+			return next(ctx)
+		}
+		return errAuth
+	})
+	// 注册到组之后立即生效
+	invoices.RegisterNamed("paid", ContainsMatcher("paid"), recordRoute(&got))
+	refunds := invoices.Group(ContainsMatcher("refund"))
+	refunds.RegisterNamed("refund", AllMatcher(), recordRoute(&got))
+
+	routeAll(t, r, "INV paid token", "ORD paid", "INV refund token")
+	if len(got) != 3 || got[0] != "paid" || got[1] != "rest" || got[2] != "refund" {
+		t.Errorf("matched %v", got)
+	}
+
+	buf := buffer.NewBuffer()
+	buf.WriteString("INV paid")
+	if _, err := r.Route(context.Background(), buf); !errors.Is(err, errAuth) {
+		t.Errorf("Route err = %v, want group middleware error", err)
+	}
+
+	if n := invoices.Deregister("paid"); n != 1 {
+		t.Errorf("Deregister = %d, want 1", n)
+	}
+}
+
+// wrappedRouter 隐藏具体实现，测试通过Route转发的路径
+type wrappedRouter struct {
+	Router
+}
+
+func TestRouter_MountOtherRouter(t *testing.T) {
+	var got []string
+	sub := NewRouter()
+	sub.RegisterNamed("sub", AllMatcher(), recordRoute(&got))
+
+	r := NewRouter()
+	r.Mount("ORD", wrappedRouter{sub})
+	routeAll(t, r, "ORD 1", "INV 2")
+	if len(got) != 1 || got[0] != "sub" {
+		t.Errorf("matched %v", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Mount(nil) should panic")
+		}
+	}()
+	r.Mount("x", nil)
+}
@@ -0,0 +1,203 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultHealthTimeout 是单个健康检查的默认超时时间
+const DefaultHealthTimeout = 5 * time.Second
+
+// HealthKind 表示健康检查的类型
+type HealthKind int
+
+const (
+	// Liveness 存活检查，失败表示进程无法自行恢复，应当重启
+	Liveness HealthKind = iota
+	// Readiness 就绪检查，失败表示暂时不能接收流量，例如依赖的服务不可用
+	Readiness
+)
+
+// String 返回类型名称
+func (k HealthKind) String() string {
+	switch k {
+	case Liveness:
+		return "liveness"
+	case Readiness:
+		return "readiness"
+	default:
+		return fmt.Sprintf("HealthKind(%d)", int(k))
+	}
+}
+
+// MarshalText 以名称编码类型
+func (k HealthKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText 从名称解码类型
+func (k *HealthKind) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "liveness":
+		*k = Liveness
+	case "readiness":
+		*k = Readiness
+	default:
+		return fmt.Errorf("router: unknown health check kind %q", text)
+	}
+	return nil
+}
+
+// HealthCheckFunc 定义健康检查函数，返回nil表示健康
+// ctx在超时后被取消，检查应当及时返回
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthCheck 定义一个健康检查
+type HealthCheck struct {
+	// Name 检查名称，例如"dlq-writable"，同一个路由器中唯一
+	Name string
+	// Kind 检查类型
+	Kind HealthKind
+	// Route 关联的路由名称，为空表示路由器或消息来源级别的检查
+	Route string
+	// Check 检查函数
+	Check HealthCheckFunc
+	// Timeout 单次检查的超时时间，0表示使用DefaultHealthTimeout
+	Timeout time.Duration
+}
+
+// HealthChecker 定义健康检查接口
+// 路由和消息来源注册各自的检查，由Health汇总，供管理端点或编排系统的探针读取
+type HealthChecker interface {
+	// AddHealthCheck 注册健康检查，同名的检查会被替换
+	// 与路由注册不同，可以在路由器使用过程中调用
+	AddHealthCheck(check HealthCheck)
+
+	// RemoveHealthCheck 移除健康检查，例如消息来源停止时
+	RemoveHealthCheck(name string)
+
+	// Health 并发执行所有健康检查并汇总结果
+	Health(ctx context.Context) HealthReport
+}
+
+// HealthReport 是健康检查的汇总结果
+type HealthReport struct {
+	// Live 所有存活检查都通过
+	Live bool `json:"live"`
+	// Ready 所有检查都通过，存活检查失败时也不就绪
+	Ready bool `json:"ready"`
+	// Checks 各个检查的结果，按名称排序
+	Checks []HealthResult `json:"checks"`
+}
+
+// HealthResult 是一个健康检查的结果
+type HealthResult struct {
+	Name     string        `json:"name"`
+	Kind     HealthKind    `json:"kind"`
+	Route    string        `json:"route,omitempty"`
+	Healthy  bool          `json:"healthy"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// healthRegistry 保存路由器的健康检查
+type healthRegistry struct {
+	mu     sync.RWMutex
+	checks map[string]HealthCheck
+}
+
+// AddHealthCheck 注册健康检查
+func (r *routerImpl) AddHealthCheck(check HealthCheck) {
+	if check.Name == "" || check.Check == nil {
+		panic("router: health check requires a name and a function")
+	}
+	r.health.mu.Lock()
+	defer r.health.mu.Unlock()
+	if r.health.checks == nil {
+		r.health.checks = make(map[string]HealthCheck)
+	}
+	r.health.checks[check.Name] = check
+}
+
+// RemoveHealthCheck 移除健康检查
+func (r *routerImpl) RemoveHealthCheck(name string) {
+	r.health.mu.Lock()
+	defer r.health.mu.Unlock()
+	delete(r.health.checks, name)
+}
+
+// Health 并发执行所有健康检查并汇总结果
+// 没有注册任何检查时路由器视为存活且就绪
+func (r *routerImpl) Health(ctx context.Context) HealthReport {
+	r.health.mu.RLock()
+	checks := make([]HealthCheck, 0, len(r.health.checks))
+	for _, check := range r.health.checks {
+		checks = append(checks, check)
+	}
+	r.health.mu.RUnlock()
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+
+	report := HealthReport{Live: true, Ready: true, Checks: make([]HealthResult, len(checks))}
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Go(func() {
+			report.Checks[i] = runHealthCheck(ctx, check)
+		})
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Healthy {
+			continue
+		}
+		report.Ready = false
+		if result.Kind == Liveness {
+			report.Live = false
+		}
+	}
+	return report
+}
+
+// runHealthCheck 在超时时间内执行一个检查
+// 检查函数没有按时返回时结果为超时，函数在后台继续运行直到返回；检查函数panic视为失败
+func runHealthCheck(ctx context.Context, check HealthCheck) HealthResult {
+	timeout := check.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHealthTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- fmt.Errorf("panic: %v", p)
+			}
+		}()
+		done <- check.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := HealthResult{
+		Name:     check.Name,
+		Kind:     check.Kind,
+		Route:    check.Route,
+		Healthy:  err == nil,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
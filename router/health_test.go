@@ -0,0 +1,73 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHealth(t *testing.T) {
+	r := NewRouter()
+
+	report := r.Health(context.Background())
+	if !report.Live || !report.Ready || len(report.Checks) != 0 {
+		t.Errorf("empty report = %+v", report)
+	}
+
+	r.AddHealthCheck(HealthCheck{Name: "b-ready", Kind: Readiness, Route: "orders", Check: func(context.Context) error {
+		return errors.New("kafka consumer lagging")
+	}})
+	r.AddHealthCheck(HealthCheck{Name: "a-live", Kind: Liveness, Check: func(context.Context) error { return nil }})
+
+	report = r.Health(context.Background())
+	if !report.Live || report.Ready {
+		t.Errorf("live = %v, ready = %v, want live but not ready", report.Live, report.Ready)
+	}
+	if len(report.Checks) != 2 || report.Checks[0].Name != "a-live" || !report.Checks[0].Healthy {
+		t.Fatalf("checks = %+v", report.Checks)
+	}
+	failed := report.Checks[1]
+	if failed.Healthy || failed.Route != "orders" || failed.Kind != Readiness || failed.Error != "kafka consumer lagging" {
+		t.Errorf("failed check = %+v", failed)
+	}
+
+	// 同名检查被替换，存活检查失败时也不就绪
+	r.AddHealthCheck(HealthCheck{Name: "a-live", Kind: Liveness, Check: func(context.Context) error { panic("boom") }})
+	r.RemoveHealthCheck("b-ready")
+	report = r.Health(context.Background())
+	if report.Live || report.Ready || len(report.Checks) != 1 || !strings.Contains(report.Checks[0].Error, "boom") {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestHealthTimeout(t *testing.T) {
+	r := NewRouter()
+	release := make(chan struct{})
+	defer close(release)
+	r.AddHealthCheck(HealthCheck{Name: "stuck", Kind: Readiness, Timeout: 10 * time.Millisecond, Check: func(context.Context) error {
+		<-release // 忽略ctx的检查也不会阻塞Health
+		return nil
+	}})
+
+	start := time.Now()
+	report := r.Health(context.Background())
+	if time.Since(start) > time.Second {
+		t.Error("Health waited for a stuck check")
+	}
+	if report.Ready || !report.Live || report.Checks[0].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestHealthReportJSON(t *testing.T) {
+	data, err := json.Marshal(HealthResult{Name: "dlq", Kind: Readiness, Healthy: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"kind":"readiness"`) {
+		t.Errorf("json = %s", data)
+	}
+}
@@ -0,0 +1,166 @@
+package router
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+
+	router_context "github.com/aomirun/content-router/context"
+)
+
+// RouteInspector 定义路由拓扑查询接口
+// 供管理端点和拓扑导出读取已注册的路由、中间件和管道
+type RouteInspector interface {
+	// Inspect 返回当前路由拓扑的快照
+	// 可以与路由注册和移除并发调用，返回调用时的快照
+	Inspect() Topology
+
+	// Routes 返回已注册的路由，按匹配顺序排列，与Inspect().Routes相同
+	Routes() []RouteDescription
+
+	// Validate 静态分析路由表，返回永远不会被匹配的路由和重复注册的路由
+	// 与Inspect一样应当在路由注册完成后调用
+	Validate() []Diagnostic
+}
+
+// Topology 描述路由器的路由拓扑
+type Topology struct {
+	// Middlewares 全局中间件，按执行顺序排列
+	Middlewares []string `json:"middlewares"`
+	// Routes 已注册的路由，按匹配顺序排列
+	Routes []RouteDescription `json:"routes"`
+	// Pipelines 已创建的管道，按创建顺序排列
+	Pipelines []PipelineDescription `json:"pipelines"`
+}
+
+// RouteDescription 描述一条已注册的路由
+type RouteDescription struct {
+	// Priority 匹配优先级，即注册顺序，从0开始，越小越先匹配
+	Priority int `json:"priority"`
+	// Info 注册时提供的路由信息
+	Info router_context.RouteInfo `json:"info"`
+	// Matcher 匹配器描述
+	Matcher string `json:"matcher"`
+	// Handler 处理器的函数名
+	Handler string `json:"handler"`
+	// Middlewares 包裹处理器的中间件数量，包括全局中间件和路由中间件
+	Middlewares int `json:"middlewares"`
+	// RouteMiddlewares 注册时附加的路由中间件，按执行顺序排列
+	RouteMiddlewares []string `json:"route_middlewares,omitempty"`
+}
+
+// PipelineDescription 描述一个管道
+type PipelineDescription struct {
+	// Index 管道的创建顺序，从0开始
+	Index int `json:"index"`
+	// Matcher 关联的匹配器描述
+	Matcher string `json:"matcher"`
+	// Middlewares 管道中的中间件，按执行顺序排列；非内置实现的管道为空
+	Middlewares []string `json:"middlewares"`
+}
+
+// Inspect 返回当前路由拓扑的快照
+func (r *routerImpl) Inspect() Topology {
+	t := r.table.Load()
+	topo := Topology{
+		Middlewares: make([]string, len(t.middlewares)),
+		Routes:      t.describeRoutes(),
+		Pipelines:   make([]PipelineDescription, len(t.pipelines)),
+	}
+	for i, middleware := range t.middlewares {
+		topo.Middlewares[i] = funcName(middleware)
+	}
+	for i, entry := range t.pipelines {
+		desc := PipelineDescription{
+			Index:       i,
+			Matcher:     matcherName(entry.matcher),
+			Middlewares: []string{},
+		}
+		if impl, ok := entry.pipeline.(*pipelineImpl); ok {
+			for _, middleware := range impl.middlewares {
+				desc.Middlewares = append(desc.Middlewares, funcName(middleware))
+			}
+		}
+		topo.Pipelines[i] = desc
+	}
+	return topo
+}
+
+// Routes 返回已注册的路由
+func (r *routerImpl) Routes() []RouteDescription {
+	return r.table.Load().describeRoutes()
+}
+
+// describeRoutes 返回快照中路由的描述
+func (t *routeTable) describeRoutes() []RouteDescription {
+	descs := make([]RouteDescription, len(t.routes))
+	for i, entry := range t.routes {
+		descs[i] = RouteDescription{
+			Priority:    i,
+			Info:        *entry.info,
+			Matcher:     matcherName(entry.matcher),
+			Handler:     funcName(entry.handler),
+			Middlewares: len(t.middlewares) + len(entry.middlewares),
+		}
+		for _, middleware := range entry.middlewares {
+			descs[i].RouteMiddlewares = append(descs[i].RouteMiddlewares, funcName(middleware))
+		}
+	}
+	return descs
+}
+
+// matcherName 返回匹配器的描述
+// 优先使用fmt.Stringer，其次是内置匹配器和MatcherFunc的函数名，最后是类型名
+func matcherName(matcher Matcher) string {
+	if s := describeMatcher(matcher); s != "" {
+		return s
+	}
+	if builtin, ok := matcher.(builtinMatcher); ok {
+		return builtin.name()
+	}
+	if fn, ok := matcher.(MatcherFunc); ok {
+		return funcName(fn)
+	}
+	return fmt.Sprintf("%T", matcher)
+}
+
+// funcName 返回函数的简短名称，例如"middleware.LoggingMiddleware"
+// 去掉包路径和编译器为闭包和方法值生成的后缀，fn不是函数时返回空字符串
+func funcName(fn any) string {
+	v := reflect.ValueOf(fn)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return ""
+	}
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return ""
+	}
+	// 方法值的名称带有"-fm"后缀
+	name := strings.TrimSuffix(f.Name(), "-fm")
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	for {
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 || !isClosureSuffix(name[i+1:]) {
+			break
+		}
+		name = name[:i]
+	}
+	return name
+}
+
+// isClosureSuffix 判断名称片段是否为编译器生成的闭包后缀，例如"func1"或"1"
+func isClosureSuffix(s string) bool {
+	s = strings.TrimPrefix(s, "func")
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
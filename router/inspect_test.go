@@ -0,0 +1,95 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+)
+
+type namedMatcher struct{}
+
+func (namedMatcher) Match(ctx router_context.Context) bool { return false }
+func (namedMatcher) String() string                        { return "named" }
+
+func handleOrders(ctx router_context.Context) error { return nil }
+
+func TestRouter_Inspect(t *testing.T) {
+	r := NewRouter()
+	r.Use(func(ctx router_context.Context, next HandlerFunc) error { return next(ctx) })
+	r.RegisterRoute(router_context.RouteInfo{Name: "orders", Pattern: "order"}, PrefixMatcher("order"), handleOrders)
+	r.Register(namedMatcher{}, handleOrders)
+	p := r.Pipeline(ContainsMatcher("x"))
+	p.Use(func(ctx router_context.Context, next HandlerFunc) error { return next(ctx) })
+
+	topo := r.Inspect()
+	if len(topo.Middlewares) != 1 || topo.Middlewares[0] != "router.TestRouter_Inspect" {
+		t.Errorf("middlewares = %v", topo.Middlewares)
+	}
+	if len(topo.Routes) != 2 {
+		t.Fatalf("routes = %+v", topo.Routes)
+	}
+	first := topo.Routes[0]
+	if first.Priority != 0 || first.Info.Name != "orders" || first.Handler != "router.handleOrders" || first.Matcher != "router.PrefixMatcher" {
+		t.Errorf("routes[0] = %+v", first)
+	}
+	if second := topo.Routes[1]; second.Priority != 1 || second.Matcher != "named" || second.Info.Pattern != "named" {
+		t.Errorf("routes[1] = %+v", second)
+	}
+	if len(topo.Pipelines) != 1 || topo.Pipelines[0].Matcher != "router.ContainsMatcher" || len(topo.Pipelines[0].Middlewares) != 1 {
+		t.Errorf("pipelines = %+v", topo.Pipelines)
+	}
+}
+
+func TestRouter_Routes(t *testing.T) {
+	r := NewRouter()
+	r.RegisterNamed("orders", PrefixMatcher("order"), handleOrders)
+	r.RegisterNamed("named", namedMatcher{}, handleOrders)
+	r.Use(
+		func(ctx router_context.Context, next HandlerFunc) error { return next(ctx) },
+		func(ctx router_context.Context, next HandlerFunc) error { return next(ctx) },
+	)
+
+	routes := r.Routes()
+	if len(routes) != 2 {
+		t.Fatalf("routes = %+v", routes)
+	}
+	if first := routes[0]; first.Priority != 0 || first.Info.Name != "orders" || first.Matcher != "router.PrefixMatcher" || first.Middlewares != 2 {
+		t.Errorf("routes[0] = %+v", first)
+	}
+	if second := routes[1]; second.Priority != 1 || second.Info.Name != "named" || second.Info.Pattern != "named" {
+		t.Errorf("routes[1] = %+v", second)
+	}
+
+	// 名称通过ctx.Route()提供给中间件
+	var matched string
+	r.Use(func(ctx router_context.Context, next HandlerFunc) error {
+		err := next(ctx)
+		matched = ctx.Route().Name
+		return err
+	})
+	buf := buffer.NewBuffer()
+	buf.WriteString("order:1")
+	if _, err := r.Route(context.Background(), buf); err != nil || matched != "orders" {
+		t.Errorf("Route = %v, matched %q", err, matched)
+	}
+}
+
+func TestFuncName(t *testing.T) {
+	tests := []struct {
+		fn   any
+		want string
+	}{
+		{handleOrders, "router.handleOrders"},
+		{TemplateMatcher("a"), "router.TemplateMatcher"},
+		{(&pipelineImpl{}).Use, "router.(*pipelineImpl).Use"},
+		{nil, ""},
+		{42, ""},
+	}
+	for _, tt := range tests {
+		if got := funcName(tt.fn); got != tt.want {
+			t.Errorf("funcName(%T) = %q, want %q", tt.fn, got, tt.want)
+		}
+	}
+}
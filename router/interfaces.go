@@ -2,9 +2,12 @@ package router
 
 import (
 	"context"
+	"io"
+	"net"
 
 	"github.com/aomirun/content-router/buffer"
 	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
 	"github.com/aomirun/content-router/manage"
 )
 
@@ -14,25 +17,105 @@ type RouteHandler interface {
 	//  - ctx: 上下文，用于传递请求范围的值和控制超时
 	//  - buffer: 要路由的消息内容，以Buffer形式提供
 	// 返回: 处理结果（可能是同一个Buffer）和可能的错误
+	//
+	// 调用方拥有传入的缓冲区并负责释放；处理器只在Route期间借用缓冲区和上下文
 	Route(ctx context.Context, buffer buffer.Buffer) (buffer.Buffer, error)
 }
 
+// RouteResponder 定义请求/应答路由接口
+type RouteResponder interface {
+	// RouteTo 路由消息，并把处理器写入ctx.Response()的内容写到w
+	//  - ctx: 上下文
+	//  - buffer: 要路由的消息内容
+	//  - w: 响应的写入目标，处理器没有写入响应时不写入任何内容
+	// 返回: 处理或写入响应时的错误
+	RouteTo(ctx context.Context, buffer buffer.Buffer, w io.Writer) error
+}
+
+// StreamRouter 定义流式路由接口
+type StreamRouter interface {
+	// RouteStream 使用framer从r中逐帧读取消息并依次路由
+	//  - ctx: 上下文，被取消时在当前帧处理完成后停止读取
+	//  - r: 输入流，例如net.Conn或文件
+	//  - framer: 帧提取器，决定消息在字节流中的边界
+	// 返回: 流正常结束时返回nil，否则返回读取或路由遇到的第一个错误
+	//
+	// 每一帧的缓冲区从路由器的BufferManager获取，路由完成后立即释放，处理器不能在返回后继续持有
+	RouteStream(ctx context.Context, r io.Reader, framer frame.Framer) error
+
+	// Writer 返回把写入的字节流逐帧路由的io.WriteCloser，供写入io.Writer的现有代码（日志、管道等）直接接入路由器
+	//  - framer: 帧提取器，不完整的帧在后续写入补齐前保留在内部缓冲中
+	// 返回: 写入端，使用完毕后必须调用Close
+	//
+	// 路由在后台按写入顺序进行，路由失败后停止，错误由之后的Write或Close返回；
+	// Close等待已写入的完整帧路由完成，剩余不完整的帧时返回io.ErrUnexpectedEOF
+	Writer(framer frame.Framer) io.WriteCloser
+}
+
+// ConnServer 定义连接服务接口
+type ConnServer interface {
+	// ServeConn 从连接中逐帧读取消息并路由，把处理器写入ctx.Response()的内容写回连接
+	//  - ctx: 上下文，被取消时阻塞中的读写立即返回
+	//  - conn: 网络连接，ServeConn不会关闭它
+	//  - framer: 帧提取器，同时实现frame.Encoder时响应按相同的帧格式编码
+	// 返回: 对端正常关闭连接时返回nil，否则返回遇到的第一个错误
+	//
+	// 每一帧的上下文通过ctx.Metadata()提供传输层名称、对端地址和接收时间
+	ServeConn(ctx context.Context, conn net.Conn, framer frame.Framer) error
+}
+
 // RouteRegistrar 定义路由注册接口
+// 注册方法都可以附加只包裹该路由处理器的路由中间件，例如校验或鉴权；
+// 执行顺序固定为全局中间件（按Use的顺序）、路由中间件（按参数顺序）、处理器
 type RouteRegistrar interface {
 	// Register 注册新的路由规则
 	//  - matcher: 内容匹配器，用于判断消息是否匹配
 	//  - handler: 消息处理器，用于处理匹配的消息
-	Register(matcher Matcher, handler HandlerFunc)
+	//  - middleware: 路由中间件
+	Register(matcher Matcher, handler HandlerFunc, middleware ...MiddlewareFunc)
 
-	// Match 注册基于字符串前缀的路由规则
-	// pattern: 匹配模式
+	// RegisterRoute 注册带有路由信息的路由规则
+	// 匹配成功后路由信息通过ctx.Route()提供给中间件和处理器
+	//  - info: 路由名称、匹配模式和元数据
+	//  - matcher: 内容匹配器
+	//  - handler: 消息处理器
+	//  - middleware: 路由中间件
+	RegisterRoute(info router_context.RouteInfo, matcher Matcher, handler HandlerFunc, middleware ...MiddlewareFunc)
+
+	// RegisterNamed 注册带有名称的路由规则，等价于只设置Name的RegisterRoute
+	// 名称通过ctx.Route()、Routes()和指标标签提供，便于在管理端点中区分路由
+	//  - name: 路由名称
+	//  - matcher: 内容匹配器
+	//  - handler: 消息处理器
+	//  - middleware: 路由中间件
+	RegisterNamed(name string, matcher Matcher, handler HandlerFunc, middleware ...MiddlewareFunc)
+
+	// Match 注册基于匹配模式的路由规则
+	// pattern: 匹配模式，作为路由信息的Pattern
 	// 支持的匹配模式:
-	//  - "/regex/正则表达式": 符合正则表达式的消息
+	//  - "/regex/正则表达式": 符合正则表达式的消息，命名分组写入匹配参数
 	//  - "/contains/特征值": 包含特征值的消息
 	//  - "/prefix/前缀": 以指定前缀开头的消息
 	//  - "/suffix/后缀": 以指定后缀结尾的消息
+	//  - 其他模式: 以整个模式开头的消息，例如"/api/"
 	// handler: 消息处理器，用于处理匹配的消息
-	Match(pattern string, handler HandlerFunc)
+	// middleware: 路由中间件
+	//
+	// 模式格式错误（类型前缀之后为空或正则表达式无法编译）时panic，需要处理错误时使用ParsePattern和RegisterRoute
+	Match(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc)
+}
+
+// RouteRemover 定义运行时移除路由的接口
+// 与注册一样可以在Route并发调用时使用：移除后开始的分发不再匹配被移除的路由，正在进行的分发按移除前的路由表完成
+type RouteRemover interface {
+	// Deregister 移除所有名称为name的路由
+	// 返回: 移除的路由数量，没有同名路由时为0
+	Deregister(name string) int
+
+	// Remove 移除所有使用matcher注册的路由
+	// 匹配器按值比较，PrefixMatcher等内置匹配器按实例比较；MatcherFunc和AllMatcher无法比较，需要用Deregister按名称移除
+	// 返回: 移除的路由数量
+	Remove(matcher Matcher) int
 }
 
 // MiddlewareHandler 定义中间件处理接口
@@ -65,13 +148,27 @@ type BufferManagerAccessor interface {
 	BufferManager() manage.BufferManager
 }
 
+// ContextManagerAccessor 定义上下文管理器访问接口
+type ContextManagerAccessor interface {
+	// ContextManager 获取ContextManager接口
+	ContextManager() manage.ContextManager
+}
+
 // Router 定义路由器接口
 // 它组合了所有路由器功能接口
 type Router interface {
 	RouteHandler
+	RouteResponder
+	StreamRouter
+	ConnServer
 	RouteRegistrar
+	RouteRemover
+	RouteMounter
 	MiddlewareHandler
 	PipelineManager
 	ContextCreator
 	BufferManagerAccessor
+	ContextManagerAccessor
+	RouteInspector
+	HealthChecker
 }
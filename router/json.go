@@ -0,0 +1,34 @@
+package router
+
+import (
+	"bytes"
+
+	"github.com/aomirun/content-router/buffer/jsonutil"
+	router_context "github.com/aomirun/content-router/context"
+)
+
+// JSONFieldMatcher 创建一个JSON字段匹配器，path指定的字段等于expected时匹配
+//   - path: 以"."分隔的字段路径，语法与jsonutil.Get相同，例如"type"或"order.items.0.sku"
+//   - expected: 期望值；字符串字段比较解码后的值，数字、布尔值和null比较其JSON文本，例如"2"或"true"
+//
+// 只扫描到目标字段为止，不做完整的反序列化；字段值不包含转义字符时不分配内存。
+// 消息不是JSON或不包含该字段时不匹配
+func JSONFieldMatcher(path, expected string) Matcher {
+	return MatcherFunc(func(ctx router_context.Context) bool {
+		raw, ok := jsonutil.Get(ctx.Buffer().Get(), path)
+		if !ok {
+			return false
+		}
+		if raw[0] != '"' {
+			return string(raw) == expected
+		}
+		if len(raw) < 2 || raw[len(raw)-1] != '"' {
+			return false
+		}
+		if inner := raw[1 : len(raw)-1]; bytes.IndexByte(inner, '\\') < 0 {
+			return string(inner) == expected
+		}
+		value, ok := jsonutil.Unquote(raw)
+		return ok && value == expected
+	})
+}
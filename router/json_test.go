@@ -0,0 +1,43 @@
+package router
+
+import "testing"
+
+func TestJSONFieldMatcher(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+		content  string
+		match    bool
+	}{
+		{"type", "order.created", `{"id":1,"type":"order.created"}`, true},
+		{"type", "order.created", `{"type":"order.updated"}`, false},
+		{"type", "order.created", ` { "type" : "order.created" } `, true},
+		{"event.kind", "click", `{"event":{"kind":"click","x":[1,2]}}`, true},
+		{"items.1.sku", "B-2", `{"items":[{"sku":"A-1"},{"sku":"B-2"}]}`, true},
+		{"name", `say "hi"`, `{"name":"say \"hi\""}`, true},
+		{"name", "café", `{"name":"café"}`, true},
+		{"version", "2", `{"version":2}`, true},
+		{"version", "2", `{"version":"2"}`, true},
+		{"paid", "true", `{"paid":true}`, true},
+		{"type", "order", `{"kind":"order"}`, false},
+		{"type", "order", `type=order`, false},
+		{"type", "order", ``, false},
+		{"type", "order", `{"type":"order`, false},
+	}
+	for _, tt := range tests {
+		if got := JSONFieldMatcher(tt.path, tt.expected).Match(newTestContext(tt.content)); got != tt.match {
+			t.Errorf("JSONFieldMatcher(%q, %q).Match(%q) = %v, want %v", tt.path, tt.expected, tt.content, got, tt.match)
+		}
+	}
+}
+
+func TestJSONFieldMatcher_Allocs(t *testing.T) {
+	matcher := JSONFieldMatcher("meta.type", "order.created")
+	ctx := newTestContext(`{"id":"6f1c","meta":{"source":"checkout","type":"order.created"},"items":[1,2,3]}`)
+	if !matcher.Match(ctx) {
+		t.Fatal("Expected match")
+	}
+	if allocs := testing.AllocsPerRun(100, func() { matcher.Match(ctx) }); allocs != 0 {
+		t.Errorf("Match allocated %v times per call", allocs)
+	}
+}
@@ -1,6 +1,8 @@
 package router
 
 import (
+	"fmt"
+
 	router_context "github.com/aomirun/content-router/context"
 )
 
@@ -35,3 +37,84 @@ type MatcherFunc func(ctx router_context.Context) bool
 func (f MatcherFunc) Match(ctx router_context.Context) bool {
 	return f(ctx)
 }
+
+// MatcherE 定义可以报告错误的匹配器，例如需要解码消息才能判断是否匹配的匹配器
+// 路由器分发时检测到匹配器实现MatcherE会调用MatchE，错误交给WithMatchErrorHandler设置的处理函数，
+// 而不是被当作不匹配静默忽略；Match在出错时应返回false，供不区分错误的调用方使用
+type MatcherE interface {
+	Matcher
+	// MatchE 检查内容是否匹配
+	// ctx: 请求上下文
+	// 返回: 是否匹配，以及无法判断时的错误
+	MatchE(ctx router_context.Context) (bool, error)
+}
+
+// MatcherEFunc 定义可以报告错误的匹配器函数类型
+type MatcherEFunc func(ctx router_context.Context) (bool, error)
+
+// Match 检查内容是否匹配，出错时返回false
+func (f MatcherEFunc) Match(ctx router_context.Context) bool {
+	ok, err := f(ctx)
+	return ok && err == nil
+}
+
+// MatchE 检查内容是否匹配
+func (f MatcherEFunc) MatchE(ctx router_context.Context) (bool, error) {
+	return f(ctx)
+}
+
+// MatchError 是匹配器在分发时报告的错误
+type MatchError struct {
+	// Route 出错的匹配器所属的路由
+	Route *router_context.RouteInfo
+	// Err 匹配器返回的错误
+	Err error
+}
+
+// Error 实现error接口
+func (e *MatchError) Error() string {
+	name := e.Route.Name
+	if name == "" {
+		name = e.Route.Pattern
+	}
+	return fmt.Sprintf("router: route %q: match: %v", name, e.Err)
+}
+
+// Unwrap 返回匹配器返回的错误
+func (e *MatchError) Unwrap() error {
+	return e.Err
+}
+
+// MatchErrorHandler 定义匹配器出错时的处理函数
+// 返回nil时把出错的路由视为不匹配，继续尝试后续路由；否则停止分发，Route返回该错误
+type MatchErrorHandler func(ctx router_context.Context, err *MatchError) error
+
+// returnMatchError 是默认的匹配器错误处理函数，停止分发并返回错误
+func returnMatchError(ctx router_context.Context, err *MatchError) error {
+	return err
+}
+
+// asMatcherE 判断分发时是否需要通过MatchE调用匹配器
+// AllMatcher只有包含可以报告错误的匹配器时才需要
+func asMatcherE(matcher Matcher) (MatcherE, bool) {
+	switch m := matcher.(type) {
+	case allMatcher:
+		for _, sub := range m {
+			if _, ok := asMatcherE(sub); ok {
+				return m, true
+			}
+		}
+		return nil, false
+	case MatcherE:
+		return m, true
+	}
+	return nil, false
+}
+
+// describeMatcher 返回匹配器的描述，匹配器实现fmt.Stringer时使用其String()
+func describeMatcher(matcher Matcher) string {
+	if s, ok := matcher.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return ""
+}
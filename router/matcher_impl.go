@@ -6,29 +6,138 @@ import (
 	router_context "github.com/aomirun/content-router/context"
 )
 
+// builtinMatcher 是内置的可静态分析的匹配器，Validate据此判断路由之间的覆盖关系
+type builtinMatcher interface {
+	Matcher
+	// name 返回创建匹配器的函数名，供Inspect描述匹配器
+	name() string
+}
+
+// literalKind 定义字面量匹配器的匹配方式
+type literalKind int
+
+const (
+	literalPrefix literalKind = iota
+	literalSuffix
+	literalContains
+)
+
+// literalMatcher 按字面量匹配缓冲区内容
+type literalMatcher struct {
+	kind    literalKind
+	literal []byte
+}
+
 // PrefixMatcher 创建一个前缀匹配器
 func PrefixMatcher(prefix string) Matcher {
-	prefixBytes := []byte(prefix)
-	return MatcherFunc(func(ctx router_context.Context) bool {
-		data := ctx.Buffer().Get()
-		return len(data) >= len(prefixBytes) && bytes.HasPrefix(data, prefixBytes)
-	})
+	return &literalMatcher{kind: literalPrefix, literal: []byte(prefix)}
 }
 
 // SuffixMatcher 创建一个后缀匹配器
 func SuffixMatcher(suffix string) Matcher {
-	suffixBytes := []byte(suffix)
-	return MatcherFunc(func(ctx router_context.Context) bool {
-		data := ctx.Buffer().Get()
-		return len(data) >= len(suffixBytes) && bytes.HasSuffix(data, suffixBytes)
-	})
+	return &literalMatcher{kind: literalSuffix, literal: []byte(suffix)}
 }
 
 // ContainsMatcher 创建一个包含匹配器
 func ContainsMatcher(substring string) Matcher {
-	substringBytes := []byte(substring)
-	return MatcherFunc(func(ctx router_context.Context) bool {
-		data := ctx.Buffer().Get()
-		return len(data) >= len(substringBytes) && bytes.Contains(data, substringBytes)
-	})
+	return &literalMatcher{kind: literalContains, literal: []byte(substring)}
+}
+
+// Match 检查内容是否匹配
+func (m *literalMatcher) Match(ctx router_context.Context) bool {
+	data := ctx.Buffer().Get()
+	if len(data) < len(m.literal) {
+		return false
+	}
+	switch m.kind {
+	case literalPrefix:
+		return bytes.HasPrefix(data, m.literal)
+	case literalSuffix:
+		return bytes.HasSuffix(data, m.literal)
+	default:
+		return bytes.Contains(data, m.literal)
+	}
+}
+
+// name 返回创建匹配器的函数名
+func (m *literalMatcher) name() string {
+	switch m.kind {
+	case literalPrefix:
+		return "router.PrefixMatcher"
+	case literalSuffix:
+		return "router.SuffixMatcher"
+	default:
+		return "router.ContainsMatcher"
+	}
+}
+
+// allMatcher 在所有匹配器都匹配时匹配
+type allMatcher []Matcher
+
+// AllMatcher 创建一个组合匹配器，所有匹配器都匹配时才匹配，没有匹配器时匹配所有消息
+// 按顺序求值，遇到第一个不匹配的匹配器时停止
+func AllMatcher(matchers ...Matcher) Matcher {
+	return allMatcher(matchers)
+}
+
+// Match 检查内容是否匹配
+func (m allMatcher) Match(ctx router_context.Context) bool {
+	for _, matcher := range m {
+		if !matcher.Match(ctx) {
+			return false
+		}
+	}
+	return true
+}
+
+// MatchE 检查内容是否匹配，按顺序求值，遇到第一个不匹配或出错的匹配器时停止
+func (m allMatcher) MatchE(ctx router_context.Context) (bool, error) {
+	for _, matcher := range m {
+		ok, err := matchE(matcher, ctx)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchE 检查内容是否匹配，匹配器实现MatcherE时报告错误
+func matchE(matcher Matcher, ctx router_context.Context) (bool, error) {
+	if m, ok := matcher.(MatcherE); ok {
+		return m.MatchE(ctx)
+	}
+	return matcher.Match(ctx), nil
+}
+
+// name 返回创建匹配器的函数名
+func (m allMatcher) name() string {
+	return "router.AllMatcher"
+}
+
+// lengthMatcher 按缓冲区长度匹配，max < 0表示没有上限
+type lengthMatcher struct {
+	min, max int
+}
+
+// LengthMatcher 创建一个长度匹配器，内容长度在[min, max]范围内时匹配
+//   - min: 最小长度（字节），0表示没有下限
+//   - max: 最大长度（字节），小于0表示没有上限；LengthMatcher(0, 0)只匹配空消息
+//
+// 与其他匹配器通过AllMatcher组合，例如把超长的帧路由到单独的处理器。min < 0或max < min时panic
+func LengthMatcher(min, max int) Matcher {
+	if min < 0 || (max >= 0 && max < min) {
+		panic("router: invalid length range")
+	}
+	return &lengthMatcher{min: min, max: max}
+}
+
+// Match 检查内容是否匹配
+func (m *lengthMatcher) Match(ctx router_context.Context) bool {
+	n := ctx.Buffer().Len()
+	return n >= m.min && (m.max < 0 || n <= m.max)
+}
+
+// name 返回创建匹配器的函数名
+func (m *lengthMatcher) name() string {
+	return "router.LengthMatcher"
 }
@@ -0,0 +1,110 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+)
+
+var errDecode = errors.New("decode failed")
+
+// versionMatcher 在消息是偶数时匹配，不是数字时报告错误
+var versionMatcher = MatcherEFunc(func(ctx router_context.Context) (bool, error) {
+	n, err := strconv.Atoi(string(ctx.Buffer().Get()))
+	if err != nil {
+		return false, errDecode
+	}
+	return n%2 == 0, nil
+})
+
+// routeMatchE 路由content，返回匹配的路由名称
+func routeMatchE(t *testing.T, r Router, content string) (string, error) {
+	t.Helper()
+	var matched string
+	r.Use(func(ctx router_context.Context, next HandlerFunc) error {
+		err := next(ctx)
+		if info := ctx.Route(); info != nil {
+			matched = info.Name
+		}
+		return err
+	})
+	buf := buffer.NewBuffer()
+	buf.WriteString(content)
+	_, err := r.Route(context.Background(), buf)
+	return matched, err
+}
+
+func TestMatcherEFunc(t *testing.T) {
+	if !versionMatcher.Match(newTestContext("2")) || versionMatcher.Match(newTestContext("x")) {
+		t.Error("Match should report matches and treat errors as no match")
+	}
+	if _, err := versionMatcher.MatchE(newTestContext("x")); !errors.Is(err, errDecode) {
+		t.Errorf("MatchE err = %v, want errDecode", err)
+	}
+}
+
+func TestRouter_MatchError(t *testing.T) {
+	newRouter := func(opts ...Option) Router {
+		r := NewRouter(opts...)
+		r.RegisterRoute(router_context.RouteInfo{Name: "even"}, AllMatcher(PrefixMatcher(""), versionMatcher), handleOrders)
+		r.RegisterRoute(router_context.RouteInfo{Name: "rest"}, AllMatcher(), handleOrders)
+		return r
+	}
+
+	// 默认停止分发并返回*MatchError
+	name, err := routeMatchE(t, newRouter(), "x")
+	var matchErr *MatchError
+	if !errors.As(err, &matchErr) || matchErr.Route.Name != "even" || !errors.Is(err, errDecode) || name != "" {
+		t.Fatalf("Route = %q, %v; want *MatchError for route even", name, err)
+	}
+	if err.Error() != `router: route "even": match: decode failed` {
+		t.Errorf("Error() = %q", err.Error())
+	}
+
+	// 没有出错时正常匹配
+	if name, err := routeMatchE(t, newRouter(), "4"); err != nil || name != "even" {
+		t.Errorf("Route = %q, %v; want even", name, err)
+	}
+
+	// 处理函数返回nil时继续尝试后续路由
+	var handled []string
+	skip := WithMatchErrorHandler(func(ctx router_context.Context, err *MatchError) error {
+		handled = append(handled, err.Route.Name)
+		return nil
+	})
+	if name, err := routeMatchE(t, newRouter(skip), "x"); err != nil || name != "rest" || len(handled) != 1 {
+		t.Errorf("Route = %q, %v, handled %v; want rest", name, err, handled)
+	}
+
+	// 处理函数返回的错误由Route返回
+	errRejected := errors.New("rejected")
+	reject := WithMatchErrorHandler(func(ctx router_context.Context, err *MatchError) error {
+		return errRejected
+	})
+	if _, err := routeMatchE(t, newRouter(reject), "x"); !errors.Is(err, errRejected) {
+		t.Errorf("Route err = %v, want errRejected", err)
+	}
+}
+
+func TestAsMatcherE(t *testing.T) {
+	tests := []struct {
+		name    string
+		matcher Matcher
+		want    bool
+	}{
+		{"literal", PrefixMatcher("a"), false},
+		{"func", versionMatcher, true},
+		{"plain all", AllMatcher(PrefixMatcher("a"), SuffixMatcher("b")), false},
+		{"all with MatcherE", AllMatcher(PrefixMatcher("a"), versionMatcher), true},
+		{"nested all", AllMatcher(AllMatcher(versionMatcher)), true},
+	}
+	for _, tt := range tests {
+		if _, got := asMatcherE(tt.matcher); got != tt.want {
+			t.Errorf("%s: asMatcherE = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
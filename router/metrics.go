@@ -0,0 +1,50 @@
+package router
+
+import (
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/events"
+	"github.com/aomirun/content-router/metrics"
+)
+
+// recordMetrics 上报一次分发的结果和耗时
+//   - info: 匹配到的路由，没有匹配时为nil
+func (r *routerImpl) recordMetrics(info *router_context.RouteInfo, err error, d time.Duration) {
+	route, result := "", "ok"
+	if info != nil {
+		route = info.Name
+		if route == "" {
+			route = info.Pattern
+		}
+	}
+	switch {
+	case err != nil:
+		result = "error"
+	case info == nil:
+		result = "unmatched"
+	}
+
+	routeLabel := metrics.Label{Name: "route", Value: route}
+	r.metrics.AddCounter(metrics.MessagesTotal, 1, routeLabel, metrics.Label{Name: "result", Value: result})
+	r.metrics.ObserveHistogram(metrics.RouteDurationSeconds, d.Seconds(), routeLabel)
+}
+
+// publishEvent 发布一次分发的结果
+//   - info: 匹配到的路由，没有匹配时为nil
+func (r *routerImpl) publishEvent(info *router_context.RouteInfo, err error, d time.Duration) {
+	var route router_context.RouteInfo
+	if info != nil {
+		route = *info
+	}
+	switch {
+	case err != nil:
+		if r.events.Enabled(events.KindRouteFailed) {
+			r.events.Publish(events.RouteFailed{Route: route, Err: err, Duration: d})
+		}
+	case info != nil:
+		if r.events.Enabled(events.KindRouteMatched) {
+			r.events.Publish(events.RouteMatched{Route: route, Duration: d})
+		}
+	}
+}
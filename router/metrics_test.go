@@ -0,0 +1,85 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/events"
+	"github.com/aomirun/content-router/metrics"
+)
+
+func TestRouter_WithMetrics(t *testing.T) {
+	reg := metrics.NewRegistry()
+	r := NewRouter(WithMetrics(reg))
+	r.RegisterRoute(router_context.RouteInfo{Name: "orders"}, MatcherFunc(func(ctx router_context.Context) bool {
+		return string(ctx.Buffer().Get()) == "order"
+	}), func(ctx router_context.Context) error { return nil })
+	r.Match("bad", func(ctx router_context.Context) error { return errors.New("rejected") })
+
+	for _, msg := range []string{"order", "order", "bad", "other"} {
+		buf := buffer.NewBuffer()
+		buf.Write([]byte(msg))
+		r.Route(context.Background(), buf)
+	}
+
+	counts := map[string]float64{}
+	var observations uint64
+	for _, fam := range reg.Snapshot() {
+		for _, s := range fam.Series {
+			switch fam.Name {
+			case metrics.MessagesTotal:
+				counts[s.Labels[0].Value+"/"+s.Labels[1].Value] = s.Value
+			case metrics.RouteDurationSeconds:
+				observations += s.Count
+			}
+		}
+	}
+	want := map[string]float64{"ok/orders": 2, "error/bad": 1, "unmatched/": 1}
+	for key, n := range want {
+		if counts[key] != n {
+			t.Errorf("messages{result/route=%s} = %v, want %v (all: %v)", key, counts[key], n, counts)
+		}
+	}
+	if observations != 4 {
+		t.Errorf("duration observations = %d, want 4", observations)
+	}
+}
+
+func TestRouter_WithEvents(t *testing.T) {
+	bus := events.NewBus()
+	var got []events.Event
+	bus.Subscribe(func(e events.Event) { got = append(got, e) })
+
+	r := NewRouter(WithEvents(bus))
+	r.RegisterRoute(router_context.RouteInfo{Name: "orders"}, PrefixMatcher("order"), func(ctx router_context.Context) error { return nil })
+	r.RegisterRoute(router_context.RouteInfo{Name: "bad"}, PrefixMatcher("bad"), func(ctx router_context.Context) error { return errors.New("rejected") })
+	r.Use(func(ctx router_context.Context, next HandlerFunc) error {
+		if string(ctx.Buffer().Get()) == "abort" {
+			ctx.Abort(errors.New("aborted"))
+			return nil
+		}
+		return next(ctx)
+	})
+
+	for _, msg := range []string{"order", "bad", "other", "abort"} {
+		buf := buffer.NewBuffer()
+		buf.Write([]byte(msg))
+		r.Route(context.Background(), buf)
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("events = %+v", got)
+	}
+	if e, ok := got[0].(events.RouteMatched); !ok || e.Route.Name != "orders" {
+		t.Errorf("event 0 = %+v", got[0])
+	}
+	if e, ok := got[1].(events.RouteFailed); !ok || e.Route.Name != "bad" || e.Err == nil {
+		t.Errorf("event 1 = %+v", got[1])
+	}
+	if e, ok := got[2].(events.RouteFailed); !ok || e.Route.Name != "" || e.Err.Error() != "aborted" {
+		t.Errorf("event 2 = %+v", got[2])
+	}
+}
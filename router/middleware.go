@@ -11,3 +11,20 @@ import (
 // next: 下一个处理器函数
 // 返回: 可能的错误
 type MiddlewareFunc func(ctx router_context.Context, next HandlerFunc) error
+
+// chainMiddlewares 从后往前将中间件组合成处理链
+// 上下文被Abort后，尚未执行的中间件和处理器都会被跳过，直接返回终止原因
+func chainMiddlewares(middlewares []MiddlewareFunc, final HandlerFunc) HandlerFunc {
+	handler := final
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		middleware := middlewares[i]
+		next := handler
+		handler = func(ctx router_context.Context) error {
+			if ctx.IsAborted() {
+				return ctx.AbortError()
+			}
+			return middleware(ctx, next)
+		}
+	}
+	return handler
+}
@@ -0,0 +1,32 @@
+package router
+
+import (
+	router_context "github.com/aomirun/content-router/context"
+)
+
+// multiContainsMatcher 在消息包含任意一个模式时匹配
+type multiContainsMatcher struct {
+	patterns [][]byte
+	ac       *ahoCorasick
+}
+
+// MultiContainsMatcher 创建一个多模式包含匹配器，消息包含任意一个模式时匹配
+// 使用Aho-Corasick自动机，无论模式有多少个，每条消息只扫描一遍
+func MultiContainsMatcher(patterns ...string) Matcher {
+	m := &multiContainsMatcher{patterns: make([][]byte, len(patterns))}
+	for i, pattern := range patterns {
+		m.patterns[i] = []byte(pattern)
+	}
+	m.ac = newAhoCorasick(m.patterns)
+	return m
+}
+
+// Match 检查内容是否匹配
+func (m *multiContainsMatcher) Match(ctx router_context.Context) bool {
+	return m.ac.first(ctx.Buffer().Get()) >= 0
+}
+
+// name 返回创建匹配器的函数名
+func (m *multiContainsMatcher) name() string {
+	return "router.MultiContainsMatcher"
+}
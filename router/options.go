@@ -0,0 +1,112 @@
+package router
+
+import (
+	"github.com/aomirun/content-router/buffer"
+	"github.com/aomirun/content-router/events"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/metrics"
+)
+
+// Option 定义路由器的配置选项
+type Option func(*routerImpl)
+
+// WithBufferManager 设置路由器使用的缓冲区管理器
+func WithBufferManager(manager manage.BufferManager) Option {
+	return func(r *routerImpl) {
+		if manager != nil {
+			r.bufferManager = manager
+		}
+	}
+}
+
+// WithBufferPool 使用指定的对象池创建路由器的缓冲区管理器
+// 可以接入buffer.NewTieredPool、NewPool(WithShards(n))等对象池
+func WithBufferPool(pool buffer.ObjectPool[buffer.Buffer]) Option {
+	return WithBufferManager(manage.NewBufferManagerWithPool(pool))
+}
+
+// WithContextManager 设置路由器使用的上下文管理器
+// 使用manage.NewUnpooledContextManager()可以绕过上下文池化
+func WithContextManager(manager manage.ContextManager) Option {
+	return func(r *routerImpl) {
+		if manager != nil {
+			r.ctxManager = manager
+		}
+	}
+}
+
+// WithContextPooling 设置是否池化复用每次分发的上下文，默认开启
+// 关闭后Route不会重置上下文，处理器保存的上下文引用在Route返回后仍然有效，
+// 代价是每次分发都会分配新的上下文
+func WithContextPooling(enabled bool) Option {
+	if enabled {
+		return WithContextManager(manage.NewContextManager())
+	}
+	return WithContextManager(manage.NewUnpooledContextManager())
+}
+
+// WithSafeDefaults 开启以正确性优先的配置，适合更看重正确性而不是少量内存分配的应用
+// 等价于同时使用WithContextPooling(false)、WithSafeContext()和WithOwnershipChecks(true)
+func WithSafeDefaults() Option {
+	return func(r *routerImpl) {
+		WithContextPooling(false)(r)
+		WithSafeContext()(r)
+		WithOwnershipChecks(true)(r)
+	}
+}
+
+// WithSafeContext 为每次分发的上下文开启线程安全模式
+// 中间件或处理器会在goroutine中并发读写上下文值时使用
+func WithSafeContext() Option {
+	return func(r *routerImpl) {
+		r.safeContext = true
+	}
+}
+
+// WithEvents 设置分发事件的总线
+// 处理成功时发布events.RouteMatched，失败时发布events.RouteFailed，没有匹配任何路由的消息不发布事件；
+// 总线没有对应类型的订阅者时不构造事件
+func WithEvents(bus *events.Bus) Option {
+	return func(r *routerImpl) {
+		r.events = bus
+	}
+}
+
+// WithMatchErrorHandler 设置匹配器出错时的处理函数，见MatcherE
+// 默认停止分发并由Route返回*MatchError；处理函数返回nil时把出错的路由视为不匹配，继续尝试后续路由
+func WithMatchErrorHandler(fn MatchErrorHandler) Option {
+	return func(r *routerImpl) {
+		if fn != nil {
+			r.matchErrors = fn
+		}
+	}
+}
+
+// WithMultiContains 设置合并包含匹配路由的阈值
+// 连续注册的ContainsMatcher路由达到minRoutes条时，分发时合并为一个Aho-Corasick自动机，
+// 每条消息只扫描一遍就能找到其中优先级最高的匹配，匹配结果与逐条尝试相同。
+// 默认DefaultMultiContainsRoutes，小于等于0时关闭
+func WithMultiContains(minRoutes int) Option {
+	return func(r *routerImpl) {
+		r.multiContains = minRoutes
+	}
+}
+
+// WithPrefixTrie 设置合并前缀匹配路由的阈值
+// 连续注册的前缀路由（Match的前缀模式或PrefixMatcher）达到minRoutes条时，分发时合并为一个字典树，
+// 查找耗时与前缀长度成正比而不是与路由数量成正比，匹配结果与逐条尝试相同。
+// 默认DefaultPrefixTrieRoutes，小于等于0时关闭
+func WithPrefixTrie(minRoutes int) Option {
+	return func(r *routerImpl) {
+		r.prefixTrie = minRoutes
+	}
+}
+
+// WithMetrics 设置分发指标的采集器
+// 每次分发上报metrics.MessagesTotal计数器和metrics.RouteDurationSeconds直方图，
+// route标签为路由名称，未命名时为匹配模式
+func WithMetrics(c metrics.Collector) Option {
+	return func(r *routerImpl) {
+		r.metrics = c
+	}
+}
@@ -0,0 +1,46 @@
+package router
+
+import (
+	"errors"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+var (
+	// ErrBufferReleased 表示传给Route的缓冲区已经归还到对象池
+	ErrBufferReleased = errors.New("router: buffer passed to Route was already released")
+
+	// ErrBorrowedBufferReleased 表示处理器释放了从调用方借用的缓冲区
+	ErrBorrowedBufferReleased = errors.New("router: handler released a borrowed buffer")
+)
+
+// WithOwnershipChecks 启用缓冲区所有权的运行时检查
+//
+// 所有权约定: Route的调用方拥有传入的缓冲区，并负责在Route返回后释放它；
+// 处理器只在Route执行期间借用缓冲区和上下文，不能释放缓冲区，
+// 也不能在Route返回后继续持有它们（上下文会被重置复用）
+//
+// 启用后Route在分发前返回ErrBufferReleased，
+// 在处理器释放了借用的缓冲区时返回ErrBorrowedBufferReleased。
+// 检查依赖缓冲区的归还状态跟踪，只对buffer包的对象池生效
+func WithOwnershipChecks(enabled bool) Option {
+	return func(r *routerImpl) {
+		r.ownershipChecks = enabled
+	}
+}
+
+// checkBorrow 在分发前检查调用方的缓冲区
+func checkBorrow(buf buffer.Buffer) error {
+	if buf != nil && buffer.IsReleased(buf) {
+		return ErrBufferReleased
+	}
+	return nil
+}
+
+// checkReturn 在分发后检查借用的缓冲区是否被处理器释放
+func checkReturn(buf buffer.Buffer, err error) error {
+	if err == nil && buf != nil && buffer.IsReleased(buf) {
+		return ErrBorrowedBufferReleased
+	}
+	return err
+}
@@ -0,0 +1,55 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+)
+
+func TestRouter_OwnershipReleasedBeforeRoute(t *testing.T) {
+	pool := buffer.NewPool()
+	r := NewRouter(WithOwnershipChecks(true))
+	called := false
+	r.Register(&mockMatcher{matchResult: true}, func(ctx router_context.Context) error {
+		called = true
+		return nil
+	})
+
+	buf := pool.Acquire()
+	pool.Release(buf)
+
+	if _, err := r.Route(context.Background(), buf); err != ErrBufferReleased {
+		t.Errorf("Expected ErrBufferReleased, got %v", err)
+	}
+	if called {
+		t.Error("Handler should not run for a released buffer")
+	}
+}
+
+func TestRouter_OwnershipHandlerReleasesBorrowedBuffer(t *testing.T) {
+	pool := buffer.NewPool()
+	r := NewRouter(WithOwnershipChecks(true))
+	r.Register(&mockMatcher{matchResult: true}, func(ctx router_context.Context) error {
+		pool.Release(ctx.Buffer())
+		return nil
+	})
+
+	if _, err := r.Route(context.Background(), pool.Acquire()); err != ErrBorrowedBufferReleased {
+		t.Errorf("Expected ErrBorrowedBufferReleased, got %v", err)
+	}
+}
+
+func TestRouter_OwnershipChecksDisabled(t *testing.T) {
+	pool := buffer.NewPool()
+	r := NewRouter()
+	r.Register(&mockMatcher{matchResult: true}, mockHandler)
+
+	buf := pool.Acquire()
+	pool.Release(buf)
+
+	if _, err := r.Route(context.Background(), buf); err != nil {
+		t.Errorf("Expected no error without ownership checks, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidPattern 表示Match的匹配模式格式错误
+var ErrInvalidPattern = errors.New("router: invalid pattern")
+
+// Match匹配模式的类型前缀
+const (
+	// PatternRegex 正则表达式，命名分组写入匹配参数，见CaptureMatcher
+	PatternRegex = "/regex/"
+	// PatternContains 包含特征值
+	PatternContains = "/contains/"
+	// PatternPrefix 以前缀开头
+	PatternPrefix = "/prefix/"
+	// PatternSuffix 以后缀结尾
+	PatternSuffix = "/suffix/"
+)
+
+// ParsePattern 解析Match的匹配模式并创建匹配器
+// 以PatternRegex、PatternContains、PatternPrefix或PatternSuffix开头的模式按类型解析，
+// 其他模式（例如"/api/"）整体作为前缀匹配；以类型前缀开头的字面量前缀可以写成"/prefix//regex/..."
+//
+// 类型前缀之后的内容为空或正则表达式无法编译时返回包装了ErrInvalidPattern的错误
+func ParsePattern(pattern string) (Matcher, error) {
+	kind, value, ok := splitPattern(pattern)
+	if !ok {
+		return PrefixMatcher(pattern), nil
+	}
+	if value == "" {
+		return nil, fmt.Errorf("%w %q: empty %s value", ErrInvalidPattern, pattern, strings.Trim(kind, "/"))
+	}
+	switch kind {
+	case PatternRegex:
+		re, err := compileRegex(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidPattern, pattern, err)
+		}
+		if !hasNamedGroups(re) {
+			return RegexMatcher(value), nil
+		}
+		return CaptureMatcher(re), nil
+	case PatternContains:
+		return ContainsMatcher(value), nil
+	case PatternSuffix:
+		return SuffixMatcher(value), nil
+	default:
+		return PrefixMatcher(value), nil
+	}
+}
+
+// splitPattern 把匹配模式拆分为类型前缀和内容，不以类型前缀开头时返回false
+func splitPattern(pattern string) (kind, value string, ok bool) {
+	for _, kind := range []string{PatternRegex, PatternContains, PatternPrefix, PatternSuffix} {
+		if value, ok := strings.CutPrefix(pattern, kind); ok {
+			return kind, value, true
+		}
+	}
+	return "", "", false
+}
+
+// hasNamedGroups 判断正则表达式是否包含命名分组
+func hasNamedGroups(re *regexp.Regexp) bool {
+	return slices.ContainsFunc(re.SubexpNames(), func(name string) bool { return name != "" })
+}
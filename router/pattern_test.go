@@ -0,0 +1,85 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+)
+
+func TestParsePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		content string
+		match   bool
+	}{
+		{"/regex/^CMD[0-9]+", "CMD42 run", true},
+		{"/regex/^CMD[0-9]+", "cmd42", false},
+		{"/contains/ERROR", "disk ERROR full", true},
+		{"/contains/ERROR", "disk full", false},
+		{"/prefix/T=", "T=21.5", true},
+		{"/prefix/T=", "H=40", false},
+		{"/suffix/!", "hello!", true},
+		{"/suffix/!", "hello", false},
+		// 其他模式整体作为前缀
+		{"/api/", "/api/orders", true},
+		{"/api/", "api/orders", false},
+		{"Hello", "Hello, World!", true},
+		// 以类型前缀开头的字面量前缀
+		{"/prefix//regex/", "/regex/x", true},
+	}
+	for _, tt := range tests {
+		matcher, err := ParsePattern(tt.pattern)
+		if err != nil {
+			t.Fatalf("ParsePattern(%q): %v", tt.pattern, err)
+		}
+		if got := matcher.Match(newTestContext(tt.content)); got != tt.match {
+			t.Errorf("ParsePattern(%q).Match(%q) = %v, want %v", tt.pattern, tt.content, got, tt.match)
+		}
+	}
+}
+
+func TestParsePatternErrors(t *testing.T) {
+	for _, pattern := range []string{"/regex/", "/regex/[a-", "/contains/", "/prefix/", "/suffix/"} {
+		if _, err := ParsePattern(pattern); !errors.Is(err, ErrInvalidPattern) {
+			t.Errorf("ParsePattern(%q) = %v, want ErrInvalidPattern", pattern, err)
+		}
+	}
+}
+
+func TestRouter_MatchPattern(t *testing.T) {
+	r := NewRouter()
+	var got string
+	r.Match("/regex/^CMD(?P<n>[0-9]+)", func(ctx router_context.Context) error {
+		got = "cmd " + ctx.Param("n")
+		return nil
+	})
+	r.Match("/suffix/!", func(ctx router_context.Context) error {
+		got = "shout"
+		return nil
+	})
+
+	for msg, want := range map[string]string{"CMD7": "cmd 7", "hey!": "shout"} {
+		got = ""
+		buf := buffer.NewBuffer()
+		buf.WriteString(msg)
+		if _, err := r.Route(context.Background(), buf); err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("Route(%q) handled by %q, want %q", msg, got, want)
+		}
+	}
+	if pattern := r.Inspect().Routes[0].Info.Pattern; pattern != "/regex/^CMD(?P<n>[0-9]+)" {
+		t.Errorf("Pattern = %q", pattern)
+	}
+
+	defer func() {
+		if err, _ := recover().(error); !errors.Is(err, ErrInvalidPattern) {
+			t.Errorf("Match with malformed pattern recovered %v", err)
+		}
+	}()
+	r.Match("/regex/(", func(ctx router_context.Context) error { return nil })
+}
@@ -0,0 +1,41 @@
+package router
+
+import (
+	"regexp"
+	"sync"
+
+	router_context "github.com/aomirun/content-router/context"
+)
+
+// regexCache 缓存编译后的正则表达式，键为表达式
+// 条目数量与注册的不同表达式数量相同，不做淘汰
+var regexCache sync.Map
+
+// compileRegex 编译正则表达式，相同的表达式复用缓存中的结果
+// regexp.Regexp可以安全地并发使用，共享同一个实例不影响正确性
+func compileRegex(expr string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	actual, _ := regexCache.LoadOrStore(expr, re)
+	return actual.(*regexp.Regexp), nil
+}
+
+// RegexMatcher 创建一个正则表达式匹配器
+// 表达式只编译一次，并且在包级缓存中共享：用相同的表达式注册多条路由时复用同一个编译结果。
+// 匹配直接作用于缓冲区的字节，不会为每条消息分配字符串；需要读取分组时使用CaptureMatcher
+//
+// 表达式无法编译时panic
+func RegexMatcher(pattern string) Matcher {
+	re, err := compileRegex(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return MatcherFunc(func(ctx router_context.Context) bool {
+		return re.Match(ctx.Buffer().Get())
+	})
+}
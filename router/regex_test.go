@@ -0,0 +1,42 @@
+package router
+
+import "testing"
+
+func TestRegexMatcher(t *testing.T) {
+	matcher := RegexMatcher(`^CMD[0-9]+`)
+	if !matcher.Match(newTestContext("CMD42 run")) {
+		t.Error("Expected match")
+	}
+	if matcher.Match(newTestContext("cmd42")) {
+		t.Error("Expected no match")
+	}
+
+	ctx := newTestContext("CMD42 run")
+	if allocs := testing.AllocsPerRun(100, func() { matcher.Match(ctx) }); allocs != 0 {
+		t.Errorf("Match allocated %v times per call", allocs)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic for invalid expression")
+		}
+	}()
+	RegexMatcher(`[a-`)
+}
+
+func TestCompileRegexCache(t *testing.T) {
+	first, err := compileRegex(`^order:(\d+)$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := compileRegex(`^order:(\d+)$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("Expected the compiled expression to be reused")
+	}
+	if _, err := compileRegex(`(`); err == nil {
+		t.Error("Expected compile error")
+	}
+}
@@ -1,28 +1,54 @@
 package router
 
 import (
-	"bytes"
 	"context"
+	"io"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/aomirun/content-router/buffer"
 	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/events"
 	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/metrics"
 )
 
 // routerImpl 是Router接口的具体实现
 type routerImpl struct {
 	bufferManager manage.BufferManager
-	routes        []routeEntry
-	middlewares   []MiddlewareFunc
-	pipelines     []pipelineEntry
-	handlerChain  HandlerFunc
-	dirty         bool // 标记路由或中间件是否发生变化
+	ctxManager    manage.ContextManager
+	arenas        *sync.Pool // 区域分配器池，未启用区域分配时为nil
+
+	table atomic.Pointer[routeTable] // 当前的路由表快照
+	mu    sync.Mutex                 // 串行化对路由表的修改
+
+	ownershipChecks bool // 是否检查缓冲区所有权
+	safeContext     bool // 是否为上下文开启线程安全模式
+
+	readTimeout  time.Duration // ServeConn等待下一帧的超时时间
+	writeTimeout time.Duration // ServeConn写回响应的超时时间
+
+	metrics metrics.Collector // 分发指标的采集器，为nil时不采集
+	events  *events.Bus       // 分发事件的总线，为nil时不发布
+
+	health healthRegistry // 健康检查
+
+	matchErrors MatchErrorHandler // 匹配器出错时的处理函数
+
+	multiContains int // 合并为自动机的连续包含匹配路由的最小数量，小于等于0时不合并
+	prefixTrie    int // 合并为字典树的连续前缀匹配路由的最小数量，小于等于0时不合并
 }
 
 // routeEntry 定义路由条目
 type routeEntry struct {
-	matcher Matcher
-	handler HandlerFunc
+	matcher     Matcher
+	handler     HandlerFunc
+	middlewares []MiddlewareFunc // 路由中间件
+	chain       HandlerFunc      // 路由中间件包裹处理器后的处理链，没有路由中间件时就是handler
+	info        *router_context.RouteInfo
 }
 
 // pipelineEntry 定义管道条目
@@ -32,101 +58,198 @@ type pipelineEntry struct {
 }
 
 // NewRouter 创建一个新的路由器实例
-func NewRouter() Router {
-	return &routerImpl{
-		bufferManager: manage.NewBufferManager(),
-		routes:        make([]routeEntry, 0),
-		middlewares:   make([]MiddlewareFunc, 0),
-		pipelines:     make([]pipelineEntry, 0),
+//   - opts: 路由器配置选项
+func NewRouter(opts ...Option) Router {
+	r := &routerImpl{
+		bufferManager: manage.Default(),
+		ctxManager:    manage.NewContextManager(),
+		multiContains: DefaultMultiContainsRoutes,
+		prefixTrie:    DefaultPrefixTrieRoutes,
+		matchErrors:   returnMatchError,
 	}
+	r.table.Store(&routeTable{})
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // Route 使用Buffer进行消息路由，减少数据复制
 func (r *routerImpl) Route(ctx context.Context, buffer buffer.Buffer) (buffer.Buffer, error) {
-	// 创建路由器上下文
-	routerCtx := router_context.NewContext(ctx, buffer)
+	return buffer, r.dispatch(ctx, buffer, nil)
+}
+
+// RouteTo 路由消息，并把处理器写入的响应缓冲区内容写到w
+func (r *routerImpl) RouteTo(ctx context.Context, buffer buffer.Buffer, w io.Writer) error {
+	return r.dispatch(ctx, buffer, w)
+}
+
+// dispatch 执行一次分发
+//   - w: 响应的写入目标，为nil时丢弃响应
+func (r *routerImpl) dispatch(ctx context.Context, buffer buffer.Buffer, w io.Writer) error {
+	if r.ownershipChecks {
+		if err := checkBorrow(buffer); err != nil {
+			return err
+		}
+	}
+
+	// 创建路由器上下文，响应缓冲区从路由器的BufferManager获取
+	routerCtx := r.ctxManager.Acquire(ctx, buffer)
+	router_context.SetBufferProvider(routerCtx, r.bufferManager)
+	if r.safeContext {
+		router_context.Synchronize(routerCtx)
+	}
+
+	// 启用区域分配时，本次分发获取的缓冲区在处理完成后一次性释放
+	if r.arenas != nil {
+		defer r.attachArena(routerCtx)()
+	}
 
 	// 应用全局中间件
-	handler := r.buildHandlerChain()
+	handler := r.table.Load().handler(r)
 
-	// 执行处理链
+	var start time.Time
+	if r.metrics != nil || r.events != nil {
+		start = time.Now()
+	}
+
+	// 执行处理链，被终止时以终止原因作为返回值
 	err := handler(routerCtx)
+	if err == nil {
+		err = routerCtx.AbortError()
+	}
+	if r.metrics != nil {
+		r.recordMetrics(routerCtx.Route(), err, time.Since(start))
+	}
+	if r.events != nil {
+		r.publishEvent(routerCtx.Route(), err, time.Since(start))
+	}
 
-	// 如果上下文实现了Reset方法，则重置它
-	if resettable, ok := routerCtx.(interface{ Reset() }); ok {
-		resettable.Reset()
+	// 写回响应后释放响应缓冲区
+	if w != nil && err == nil && routerCtx.HasResponse() {
+		_, err = w.Write(routerCtx.Response().Get())
 	}
+	router_context.ReleaseResponse(routerCtx)
 
-	return buffer, err
-}
+	// 释放上下文，池化模式下会重置并复用
+	r.ctxManager.Release(routerCtx)
 
-// buildHandlerChain 构建处理链
-func (r *routerImpl) buildHandlerChain() HandlerFunc {
-	// 如果处理链未变化，直接返回缓存的处理链
-	if !r.dirty && r.handlerChain != nil {
-		return r.handlerChain
+	if r.ownershipChecks {
+		err = checkReturn(buffer, err)
 	}
 
+	return err
+}
+
+// buildHandlerChain 为路由表快照构建处理链
+func (r *routerImpl) buildHandlerChain(t *routeTable) HandlerFunc {
+	// 连续的包含匹配路由合并为自动机，连续的前缀匹配路由合并为字典树
+	groups := groupRoutes(t.routes, r.multiContains, r.prefixTrie)
+
 	// 基础处理器
 	baseHandler := func(ctx router_context.Context) error {
+		if ctx.IsAborted() {
+			return ctx.AbortError()
+		}
 		// 查找匹配的路由
-		for _, entry := range r.routes {
-			if entry.matcher.Match(ctx) {
-				return entry.handler(ctx)
+		for i := range groups {
+			entry, err := groups[i].match(ctx)
+			if err != nil {
+				if err := r.matchErrors(ctx, &MatchError{Route: entry.info, Err: err}); err != nil {
+					return err
+				}
+				continue
+			}
+			if entry != nil {
+				ctx.SetRoute(entry.info)
+				return entry.chain(ctx)
 			}
 		}
 		return nil
 	}
 
-	// 如果没有中间件，直接返回基础处理器并缓存
-	if len(r.middlewares) == 0 {
-		r.handlerChain = baseHandler
-		r.dirty = false
-		return baseHandler
-	}
-
 	// 从后往前应用中间件（符合中间件链的常规做法）
-	handler := baseHandler
-	for i := len(r.middlewares) - 1; i >= 0; i-- {
-		middleware := r.middlewares[i]
-		next := handler
-		handler = func(ctx router_context.Context) error {
-			return middleware(ctx, next)
-		}
+	return chainMiddlewares(t.middlewares, baseHandler)
+}
+
+// Register 注册新的路由规则
+func (r *routerImpl) Register(matcher Matcher, handler HandlerFunc, middleware ...MiddlewareFunc) {
+	r.RegisterRoute(router_context.RouteInfo{Pattern: describeMatcher(matcher)}, matcher, handler, middleware...)
+}
+
+// RegisterRoute 注册带有路由信息的路由规则
+func (r *routerImpl) RegisterRoute(info router_context.RouteInfo, matcher Matcher, handler HandlerFunc, middleware ...MiddlewareFunc) {
+	// 路由中间件在注册时组合，复制参数避免调用方之后修改切片
+	middleware = slices.Clone(middleware)
+	entry := routeEntry{
+		matcher:     matcher,
+		handler:     handler,
+		middlewares: middleware,
+		chain:       chainMiddlewares(middleware, handler),
+		info:        &info,
 	}
+	r.update(func(t *routeTable) bool {
+		t.routes = append(t.routes, entry)
+		return true
+	})
+}
 
-	// 缓存处理链并重置dirty标记
-	r.handlerChain = handler
-	r.dirty = false
+// RegisterNamed 注册带有名称的路由规则
+func (r *routerImpl) RegisterNamed(name string, matcher Matcher, handler HandlerFunc, middleware ...MiddlewareFunc) {
+	r.RegisterRoute(router_context.RouteInfo{Name: name, Pattern: describeMatcher(matcher)}, matcher, handler, middleware...)
+}
 
-	return handler
+// Deregister 移除所有名称为name的路由
+func (r *routerImpl) Deregister(name string) int {
+	return r.removeRoutes(func(entry routeEntry) bool {
+		return entry.info.Name == name
+	})
 }
 
-// Register 注册新的路由规则
-func (r *routerImpl) Register(matcher Matcher, handler HandlerFunc) {
-	r.routes = append(r.routes, routeEntry{
-		matcher: matcher,
-		handler: handler,
+// Remove 移除所有使用matcher注册的路由
+func (r *routerImpl) Remove(matcher Matcher) int {
+	return r.removeRoutes(func(entry routeEntry) bool {
+		return sameMatcher(entry.matcher, matcher)
 	})
-	r.dirty = true
 }
 
-// Match 注册基于字符串前缀的路由规则
-func (r *routerImpl) Match(pattern string, handler HandlerFunc) {
-	// 简单实现：只支持前缀匹配
-	patternBytes := []byte(pattern)
-	matcher := MatcherFunc(func(ctx router_context.Context) bool {
-		data := ctx.Buffer().Get()
-		return len(data) >= len(patternBytes) && bytes.HasPrefix(data, patternBytes)
+// removeRoutes 移除满足del的路由，返回移除的数量
+// 在切片的副本上删除，不改写旧快照的路由；没有移除任何路由时保留当前快照
+func (r *routerImpl) removeRoutes(del func(routeEntry) bool) int {
+	var removed int
+	r.update(func(t *routeTable) bool {
+		routes := slices.DeleteFunc(slices.Clone(t.routes), del)
+		removed = len(t.routes) - len(routes)
+		t.routes = routes
+		return removed > 0
 	})
+	return removed
+}
 
-	r.Register(matcher, handler)
+// sameMatcher 判断两个匹配器是否是同一个值，动态类型不可比较时返回false
+func sameMatcher(a, b Matcher) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() || va.Type() != vb.Type() || !va.Comparable() {
+		return false
+	}
+	return va.Equal(vb)
+}
+
+// Match 注册基于匹配模式的路由规则，模式的语法见ParsePattern
+func (r *routerImpl) Match(pattern string, handler HandlerFunc, middleware ...MiddlewareFunc) {
+	matcher, err := ParsePattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+	r.RegisterRoute(router_context.RouteInfo{Pattern: pattern}, matcher, handler, middleware...)
 }
 
 // Use 添加中间件
 func (r *routerImpl) Use(middleware ...MiddlewareFunc) {
-	r.middlewares = append(r.middlewares, middleware...)
-	r.dirty = true
+	r.update(func(t *routeTable) bool {
+		t.middlewares = append(t.middlewares, middleware...)
+		return true
+	})
 }
 
 // Pipeline 创建一个新的责任链管道，并与指定的匹配器关联
@@ -136,9 +259,12 @@ func (r *routerImpl) Pipeline(matcher Matcher) Pipeline {
 		middlewares: make([]MiddlewareFunc, 0),
 	}
 
-	r.pipelines = append(r.pipelines, pipelineEntry{
-		matcher:  matcher,
-		pipeline: pipeline,
+	r.update(func(t *routeTable) bool {
+		t.pipelines = append(t.pipelines, pipelineEntry{
+			matcher:  matcher,
+			pipeline: pipeline,
+		})
+		return true
 	})
 
 	return pipeline
@@ -154,6 +280,11 @@ func (r *routerImpl) BufferManager() manage.BufferManager {
 	return r.bufferManager
 }
 
+// ContextManager 获取ContextManager接口
+func (r *routerImpl) ContextManager() manage.ContextManager {
+	return r.ctxManager
+}
+
 // pipelineImpl 是Pipeline接口的简单实现
 type pipelineImpl struct {
 	middlewares []MiddlewareFunc
@@ -172,20 +303,9 @@ func (p *pipelineImpl) Handle(ctx router_context.Context) error {
 		return nil
 	}
 
-	// 如果没有中间件，直接返回基础处理器
-	if len(p.middlewares) == 0 {
-		return baseHandler(ctx)
-	}
-
-	// 从后往前应用中间件
-	handler := baseHandler
-	for i := len(p.middlewares) - 1; i >= 0; i-- {
-		middleware := p.middlewares[i]
-		next := handler
-		handler = func(ctx router_context.Context) error {
-			return middleware(ctx, next)
-		}
+	// 从后往前应用中间件，被终止时以终止原因作为返回值
+	if err := chainMiddlewares(p.middlewares, baseHandler)(ctx); err != nil {
+		return err
 	}
-
-	return handler(ctx)
+	return ctx.AbortError()
 }
@@ -1,7 +1,12 @@
 package router
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"sync"
 	"testing"
 
 	"github.com/aomirun/content-router/buffer"
@@ -237,31 +242,37 @@ func TestRouter_UseMiddleware(t *testing.T) {
 func TestRouter_HandlerChainCaching(t *testing.T) {
 	router := NewRouter().(*routerImpl)
 
-	// 创建测试数据
-	buf := buffer.NewBuffer()
-	buf.WriteString("test data")
-
-	// 第一次构建处理链
-	router.buildHandlerChain()
-
-	// 保存当前dirty状态
-	initialDirty := router.dirty
+	// 处理链按快照缓存，同一个快照只构建一次
+	table := router.table.Load()
+	table.handler(router)
+	first := table.chain.handler
+	table.handler(router)
+	if first == nil || reflect.ValueOf(table.chain.handler).Pointer() != reflect.ValueOf(first).Pointer() {
+		t.Fatal("Handler chain should be built once per table")
+	}
 
-	// 添加中间件后，dirty标志应该变为true
+	// 添加中间件后替换为新的快照，旧快照保持不变
 	router.Use(func(ctx router_context.Context, next HandlerFunc) error {
 		return next(ctx)
 	})
-
-	// 验证dirty标志已更新
-	if router.dirty == initialDirty {
-		t.Error("Dirty flag should be updated after adding middleware")
+	next := router.table.Load()
+	if next == table || len(table.middlewares) != 0 || len(next.middlewares) != 1 {
+		t.Error("Adding middleware should swap in a new table")
+	}
+	if next.chain.handler != nil {
+		t.Error("New table should build its handler chain lazily")
 	}
 
-	// 构建处理链后，dirty标志应该变为false
-	router.buildHandlerChain()
-
-	if router.dirty != false {
-		t.Error("Dirty flag should be false after building handler chain")
+	// 移除路由同样替换快照，没有移除任何路由时保留当前快照
+	router.RegisterNamed("a", PrefixMatcher("a"), handleOrders)
+	table = router.table.Load()
+	router.Deregister("missing")
+	if router.table.Load() != table {
+		t.Error("Table should be kept when nothing was removed")
+	}
+	router.Deregister("a")
+	if router.table.Load() == table || len(table.routes) != 1 {
+		t.Error("Removing a route should swap in a new table and leave the old one intact")
 	}
 }
 
@@ -563,6 +574,44 @@ func TestContainsMatcher(t *testing.T) {
 	}
 }
 
+func TestLengthMatcher(t *testing.T) {
+	tests := []struct {
+		min, max int
+		content  string
+		want     bool
+	}{
+		{0, 0, "", true},
+		{0, 0, "a", false},
+		{1, -1, "", false},
+		{1, -1, "Hello, World!", true},
+		{0, 5, "Hello", true},
+		{0, 5, "Hello!", false},
+		{6, 13, "Hello, World!", true},
+	}
+	for _, tt := range tests {
+		if got := LengthMatcher(tt.min, tt.max).Match(newTestContext(tt.content)); got != tt.want {
+			t.Errorf("LengthMatcher(%d, %d).Match(%q) = %v, want %v", tt.min, tt.max, tt.content, got, tt.want)
+		}
+	}
+
+	// 与其他匹配器组合
+	matcher := AllMatcher(PrefixMatcher("{"), LengthMatcher(0, 8))
+	if !matcher.Match(newTestContext(`{"a":1}`)) || matcher.Match(newTestContext(`{"a":100}`)) {
+		t.Error("AllMatcher should combine the prefix and length conditions")
+	}
+
+	for _, r := range [][2]int{{-1, 5}, {5, 4}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("LengthMatcher(%d, %d) should panic", r[0], r[1])
+				}
+			}()
+			LengthMatcher(r[0], r[1])
+		}()
+	}
+}
+
 func TestPipelineHandleWithoutMiddlewares(t *testing.T) {
 	// 创建管道实现的独立测试
 	pipeline := &pipelineImpl{}
@@ -579,3 +628,444 @@ func TestPipelineHandleWithoutMiddlewares(t *testing.T) {
 		t.Errorf("Pipeline.Handle should not return error: %v", err)
 	}
 }
+
+func TestRouter_WithBufferPool(t *testing.T) {
+	pool := buffer.NewTieredPool(nil)
+	r := NewRouter(WithBufferPool(pool))
+
+	buf := r.BufferManager().Acquire()
+	r.BufferManager().Release(buf)
+
+	stats := pool.(buffer.StatsProvider).Stats()
+	if stats.Acquires != 1 || stats.Releases != 1 {
+		t.Errorf("Expected router to use the provided pool, got %+v", stats)
+	}
+}
+
+func TestRouter_WithBufferManager(t *testing.T) {
+	manager := manage.NewBufferManager()
+	r := NewRouter(WithBufferManager(manager))
+
+	if r.BufferManager() != manager {
+		t.Error("Expected router to use the provided buffer manager")
+	}
+}
+
+func TestRouter_WithContextManager(t *testing.T) {
+	manager := manage.NewUnpooledContextManager()
+	r := NewRouter(WithContextManager(manager))
+
+	var saved router_context.Context
+	r.Register(&mockMatcher{matchResult: true}, func(ctx router_context.Context) error {
+		ctx.Set("key", "value")
+		saved = ctx
+		return nil
+	})
+
+	if _, err := r.Route(context.Background(), buffer.NewBuffer()); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+
+	if r.ContextManager() != manager {
+		t.Error("Expected router to use the provided context manager")
+	}
+	if stats := manager.Stats(); stats.Acquired != 1 || stats.Released != 1 {
+		t.Errorf("Expected 1 acquire and 1 release, got %+v", stats)
+	}
+	if v, ok := saved.GetString("key"); !ok || v != "value" {
+		t.Error("Context saved by handler should stay valid without pooling")
+	}
+}
+
+func TestRouter_WithContextPooling(t *testing.T) {
+	r := NewRouter(WithContextPooling(false))
+
+	var saved router_context.Context
+	r.Register(&mockMatcher{matchResult: true}, func(ctx router_context.Context) error {
+		ctx.Set("key", "value")
+		saved = ctx
+		return nil
+	})
+	if _, err := r.Route(context.Background(), buffer.NewBuffer()); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if v, ok := saved.GetString("key"); !ok || v != "value" {
+		t.Error("Context saved by handler should stay valid without pooling")
+	}
+
+	r = NewRouter(WithContextPooling(true))
+	r.Register(&mockMatcher{matchResult: true}, func(ctx router_context.Context) error {
+		ctx.Set("key", "value")
+		saved = ctx
+		return nil
+	})
+	if _, err := r.Route(context.Background(), buffer.NewBuffer()); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if _, ok := saved.GetString("key"); ok {
+		t.Error("Pooled context should be reset after Route returns")
+	}
+}
+
+func TestRouter_WithSafeDefaults(t *testing.T) {
+	r := NewRouter(WithSafeDefaults()).(*routerImpl)
+	if !r.safeContext || !r.ownershipChecks {
+		t.Error("WithSafeDefaults should enable safe contexts and ownership checks")
+	}
+
+	var saved router_context.Context
+	r.Register(&mockMatcher{matchResult: true}, func(ctx router_context.Context) error {
+		ctx.Set("key", "value")
+		saved = ctx
+		return nil
+	})
+	if _, err := r.Route(context.Background(), buffer.NewBuffer()); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if v, ok := saved.GetString("key"); !ok || v != "value" {
+		t.Error("Context saved by handler should stay valid with safe defaults")
+	}
+}
+
+func TestRouter_WithSafeContext(t *testing.T) {
+	r := NewRouter(WithSafeContext())
+	r.Register(&mockMatcher{matchResult: true}, func(ctx router_context.Context) error {
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(id int) {
+				defer wg.Done()
+				ctx.Set(id, id)
+			}(i)
+		}
+		wg.Wait()
+		if len(ctx.Keys()) != 4 {
+			t.Errorf("Expected 4 keys, got %d", len(ctx.Keys()))
+		}
+		return nil
+	})
+
+	if _, err := r.Route(context.Background(), buffer.NewBuffer()); err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+}
+
+func TestRouter_RouteInfo(t *testing.T) {
+	r := NewRouter()
+
+	var matched *router_context.RouteInfo
+	handler := func(ctx router_context.Context) error {
+		matched = ctx.Route()
+		return nil
+	}
+	r.Match("PING", handler)
+	r.RegisterRoute(router_context.RouteInfo{
+		Name:     "orders",
+		Pattern:  "order:{id}",
+		Metadata: map[string]string{"team": "checkout"},
+	}, TemplateMatcher("order:{id}"), handler)
+
+	buf := buffer.NewBuffer()
+	buf.WriteString("order:1")
+	r.Route(context.Background(), buf)
+	if matched == nil || matched.Name != "orders" || matched.Metadata["team"] != "checkout" {
+		t.Errorf("Unexpected route info: %+v", matched)
+	}
+
+	buf.Reset()
+	buf.WriteString("PING")
+	r.Route(context.Background(), buf)
+	if matched == nil || matched.Pattern != "PING" || matched.Name != "" {
+		t.Errorf("Unexpected route info for Match: %+v", matched)
+	}
+}
+
+func TestRouter_Abort(t *testing.T) {
+	r := NewRouter()
+	errUnauthorized := errors.New("unauthorized")
+
+	var order []string
+	r.Use(func(ctx router_context.Context, next HandlerFunc) error {
+		order = append(order, "auth")
+		ctx.Abort(errUnauthorized)
+		// 即使中间件继续调用next，后续处理也会被跳过
+		return next(ctx)
+	})
+	r.Use(func(ctx router_context.Context, next HandlerFunc) error {
+		order = append(order, "logging")
+		return next(ctx)
+	})
+	r.Register(&mockMatcher{matchResult: true}, func(ctx router_context.Context) error {
+		order = append(order, "handler")
+		return nil
+	})
+
+	_, err := r.Route(context.Background(), buffer.NewBuffer())
+	if err != errUnauthorized {
+		t.Errorf("Expected abort error, got %v", err)
+	}
+	if len(order) != 1 || order[0] != "auth" {
+		t.Errorf("Expected only auth middleware to run, got %v", order)
+	}
+}
+
+func TestRouter_AbortWithoutError(t *testing.T) {
+	r := NewRouter()
+	r.Use(func(ctx router_context.Context, next HandlerFunc) error {
+		ctx.Abort(nil)
+		return nil
+	})
+
+	_, err := r.Route(context.Background(), buffer.NewBuffer())
+	if err != router_context.ErrAborted {
+		t.Errorf("Expected ErrAborted, got %v", err)
+	}
+}
+
+func TestPipeline_Abort(t *testing.T) {
+	pipeline := &pipelineImpl{}
+	called := false
+	pipeline.Use(func(ctx router_context.Context, next HandlerFunc) error {
+		ctx.Abort(nil)
+		return next(ctx)
+	}, func(ctx router_context.Context, next HandlerFunc) error {
+		called = true
+		return next(ctx)
+	})
+
+	ctx := router_context.NewContext(context.Background(), buffer.NewBuffer())
+	if err := pipeline.Handle(ctx); err != router_context.ErrAborted {
+		t.Errorf("Expected ErrAborted, got %v", err)
+	}
+	if called {
+		t.Error("Middleware after Abort should not run")
+	}
+}
+
+func TestRouter_RouteTo(t *testing.T) {
+	manager := manage.NewBufferManager()
+	r := NewRouter(WithBufferManager(manager))
+	r.Match("PING", func(ctx router_context.Context) error {
+		ctx.Response().WriteString("PONG")
+		return nil
+	})
+	r.Match("NOTE", mockHandler)
+
+	var out bytes.Buffer
+	buf := buffer.NewBuffer()
+	buf.WriteString("PING")
+	if err := r.RouteTo(context.Background(), buf, &out); err != nil {
+		t.Fatalf("RouteTo failed: %v", err)
+	}
+	if out.String() != "PONG" {
+		t.Errorf("Expected PONG, got %q", out.String())
+	}
+
+	// 处理器没有写入响应时不写入任何内容
+	out.Reset()
+	buf.Reset()
+	buf.WriteString("NOTE")
+	if err := r.RouteTo(context.Background(), buf, &out); err != nil {
+		t.Fatalf("RouteTo failed: %v", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Expected no response, got %q", out.String())
+	}
+
+	if stats := manager.Stats(); stats.Outstanding != 0 || stats.Acquired != 1 {
+		t.Errorf("Expected response buffer to be acquired once and released, got %+v", stats)
+	}
+}
+
+func TestRouter_Deregister(t *testing.T) {
+	r := NewRouter()
+	r.RegisterNamed("legacy", PrefixMatcher("order"), handleOrders)
+	r.RegisterNamed("orders", PrefixMatcher("order"), handleOrders)
+	r.RegisterNamed("legacy", ContainsMatcher("v1"), handleOrders)
+
+	if name, _ := routeMatchE(t, r, "order:1"); name != "legacy" {
+		t.Fatalf("matched %q before Deregister, want legacy", name)
+	}
+	if n := r.Deregister("legacy"); n != 2 {
+		t.Errorf("Deregister = %d, want 2", n)
+	}
+	if n := r.Deregister("missing"); n != 0 {
+		t.Errorf("Deregister(missing) = %d, want 0", n)
+	}
+	if name, _ := routeMatchE(t, r, "order:1"); name != "orders" {
+		t.Errorf("matched %q after Deregister, want orders", name)
+	}
+	if routes := r.Routes(); len(routes) != 1 || routes[0].Info.Name != "orders" || routes[0].Priority != 0 {
+		t.Errorf("routes = %+v", routes)
+	}
+}
+
+func TestRouter_Remove(t *testing.T) {
+	prefix := PrefixMatcher("order")
+	fn := MatcherFunc(func(ctx router_context.Context) bool { return true })
+	r := NewRouter()
+	r.Register(prefix, handleOrders)
+	r.Register(PrefixMatcher("order"), handleOrders)
+	r.Register(fn, handleOrders)
+
+	if n := r.Remove(prefix); n != 1 {
+		t.Errorf("Remove(prefix) = %d, want 1", n)
+	}
+	// MatcherFunc无法比较
+	if n := r.Remove(fn); n != 0 {
+		t.Errorf("Remove(MatcherFunc) = %d, want 0", n)
+	}
+	if n := r.Remove(AllMatcher()); n != 0 {
+		t.Errorf("Remove(AllMatcher) = %d, want 0", n)
+	}
+	if routes := r.Routes(); len(routes) != 2 {
+		t.Errorf("routes = %+v", routes)
+	}
+}
+
+func TestRouter_DeregisterConcurrent(t *testing.T) {
+	r := NewRouter()
+	for i := 0; i < 20; i++ {
+		r.RegisterNamed("old", PrefixMatcher("msg"), handleOrders)
+	}
+	r.RegisterNamed("new", PrefixMatcher("msg"), handleOrders)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := buffer.NewBuffer()
+			buf.WriteString("msg")
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if _, err := r.Route(context.Background(), buf); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		r.RegisterNamed("tmp", ContainsMatcher("x"), handleOrders)
+		r.Deregister("tmp")
+		r.Routes()
+	}
+	r.Deregister("old")
+	close(stop)
+	wg.Wait()
+
+	if name, _ := routeMatchE(t, r, "msg"); name != "new" {
+		t.Errorf("matched %q, want new", name)
+	}
+}
+
+func TestRouter_ConcurrentRegistration(t *testing.T) {
+	r := NewRouter()
+	r.RegisterNamed("fallback", AllMatcher(), handleOrders)
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := buffer.NewBuffer()
+			buf.WriteString("msg")
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if _, err := r.Route(context.Background(), buf); err != nil {
+					t.Error(err)
+					return
+				}
+				r.Inspect()
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		r.RegisterNamed("msg", PrefixMatcher("msg"), handleOrders)
+		r.Use(func(ctx router_context.Context, next HandlerFunc) error { return next(ctx) })
+		r.Pipeline(ContainsMatcher("x"))
+		r.Validate()
+	}
+	close(stop)
+	wg.Wait()
+
+	topo := r.Inspect()
+	if len(topo.Routes) != 51 || len(topo.Middlewares) != 50 || len(topo.Pipelines) != 50 {
+		t.Errorf("routes = %d, middlewares = %d, pipelines = %d", len(topo.Routes), len(topo.Middlewares), len(topo.Pipelines))
+	}
+}
+
+// traceMiddleware 返回把name追加到calls的中间件
+func traceMiddleware(calls *[]string, name string) MiddlewareFunc {
+	return func(ctx router_context.Context, next HandlerFunc) error {
+		*calls = append(*calls, name)
+		return next(ctx)
+	}
+}
+
+func TestRouter_RouteMiddleware(t *testing.T) {
+	var calls []string
+	errInvalid := errors.New("invalid")
+	validate := func(ctx router_context.Context, next HandlerFunc) error {
+		calls = append(calls, "validate")
+		if !SuffixMatcher("}").Match(ctx) {
+			return errInvalid
+		}
+		return next(ctx)
+	}
+	handler := func(name string) HandlerFunc {
+		return func(ctx router_context.Context) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	r := NewRouter()
+	r.Use(traceMiddleware(&calls, "global1"))
+	r.Match("{", handler("json"), traceMiddleware(&calls, "route1"), validate)
+	r.Register(PrefixMatcher("plain"), handler("plain"))
+	r.Use(traceMiddleware(&calls, "global2"))
+
+	routeAll(t, r, `{"a":1}`)
+	want := []string{"global1", "global2", "route1", "validate", "json"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+
+	// 路由中间件只包裹自己的路由
+	calls = nil
+	routeAll(t, r, "plain text")
+	if want := []string{"global1", "global2", "plain"}; !slices.Equal(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+
+	// 路由中间件可以拒绝消息
+	calls = nil
+	buf := buffer.NewBuffer()
+	buf.WriteString(`{"a":1`)
+	if _, err := r.Route(context.Background(), buf); !errors.Is(err, errInvalid) {
+		t.Errorf("Route err = %v, want errInvalid", err)
+	}
+	if slices.Contains(calls, "json") {
+		t.Error("handler should not run when route middleware rejects the message")
+	}
+
+	routes := r.Routes()
+	if routes[0].Middlewares != 4 || len(routes[0].RouteMiddlewares) != 2 || routes[0].RouteMiddlewares[0] != "router.traceMiddleware" {
+		t.Errorf("routes[0] = %+v", routes[0])
+	}
+	if routes[1].Middlewares != 2 || routes[1].RouteMiddlewares != nil {
+		t.Errorf("routes[1] = %+v", routes[1])
+	}
+}
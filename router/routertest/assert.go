@@ -0,0 +1,200 @@
+package routertest
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Assertion 对Result进行链式断言
+// 断言失败时调用t.Errorf报告，不会终止测试，因此一次可以看到所有不符合预期的地方
+type Assertion struct {
+	t testing.TB
+	r *Result
+	// reported 记录是否已经报告过消息没有到达记录器
+	reported bool
+}
+
+// Assert 返回结果的链式断言
+//
+//	rec.RouteString(`{"type":"order"}`).Assert(t).
+//		Route("orders").
+//		NoError().
+//		Value("tenant", "acme").
+//		Response("accepted")
+func (r *Result) Assert(t testing.TB) *Assertion {
+	return &Assertion{t: t, r: r}
+}
+
+// Route 断言消息匹配了指定路由，name与路由名称比较，路由未命名时与匹配模式比较
+func (a *Assertion) Route(name string) *Assertion {
+	a.t.Helper()
+	if !a.captured() {
+		return a
+	}
+	if a.r.Route == nil {
+		a.t.Errorf("routertest: %q matched no route, want %q", a.r.Input, name)
+		return a
+	}
+	got := a.r.Route.Name
+	if got == "" {
+		got = a.r.Route.Pattern
+	}
+	if got != name {
+		a.t.Errorf("routertest: %q matched route %q, want %q", a.r.Input, got, name)
+	}
+	return a
+}
+
+// Unmatched 断言消息没有匹配任何路由
+func (a *Assertion) Unmatched() *Assertion {
+	a.t.Helper()
+	if a.captured() && a.r.Route != nil {
+		a.t.Errorf("routertest: %q matched route %+v, want no match", a.r.Input, *a.r.Route)
+	}
+	return a
+}
+
+// NoError 断言Route没有返回错误
+func (a *Assertion) NoError() *Assertion {
+	a.t.Helper()
+	if a.r.Err != nil {
+		a.t.Errorf("routertest: %q returned error %v", a.r.Input, a.r.Err)
+	}
+	return a
+}
+
+// Error 断言Route返回的错误满足errors.Is(err, target)
+func (a *Assertion) Error(target error) *Assertion {
+	a.t.Helper()
+	if !errors.Is(a.r.Err, target) {
+		a.t.Errorf("routertest: %q returned error %v, want %v", a.r.Input, a.r.Err, target)
+	}
+	return a
+}
+
+// ErrorContains 断言Route返回了错误，并且错误信息包含substr
+func (a *Assertion) ErrorContains(substr string) *Assertion {
+	a.t.Helper()
+	if a.r.Err == nil || !strings.Contains(a.r.Err.Error(), substr) {
+		a.t.Errorf("routertest: %q returned error %v, want error containing %q", a.r.Input, a.r.Err, substr)
+	}
+	return a
+}
+
+// Aborted 断言处理被终止
+func (a *Assertion) Aborted() *Assertion {
+	a.t.Helper()
+	if a.captured() && !a.r.Aborted {
+		a.t.Errorf("routertest: %q was not aborted", a.r.Input)
+	}
+	return a
+}
+
+// Value 断言上下文中key的值与want相等（reflect.DeepEqual）
+func (a *Assertion) Value(key, want any) *Assertion {
+	a.t.Helper()
+	if !a.captured() {
+		return a
+	}
+	got, ok := a.r.Values[key]
+	switch {
+	case !ok:
+		a.t.Errorf("routertest: %q has no value for key %v, want %v", a.r.Input, key, want)
+	case !reflect.DeepEqual(got, want):
+		a.t.Errorf("routertest: %q value for key %v = %v, want %v", a.r.Input, key, got, want)
+	}
+	return a
+}
+
+// NoValue 断言上下文中没有key
+func (a *Assertion) NoValue(key any) *Assertion {
+	a.t.Helper()
+	if !a.captured() {
+		return a
+	}
+	if got, ok := a.r.Values[key]; ok {
+		a.t.Errorf("routertest: %q has value %v for key %v, want none", a.r.Input, got, key)
+	}
+	return a
+}
+
+// Param 断言匹配参数name的值为want
+func (a *Assertion) Param(name, want string) *Assertion {
+	a.t.Helper()
+	if !a.captured() {
+		return a
+	}
+	if got, ok := a.r.Params[name]; !ok || got != want {
+		a.t.Errorf("routertest: %q param %q = %q, want %q (params: %v)", a.r.Input, name, got, want, a.r.Params)
+	}
+	return a
+}
+
+// TraceID 断言追踪ID为want
+func (a *Assertion) TraceID(want string) *Assertion {
+	a.t.Helper()
+	if a.captured() && a.r.TraceID != want {
+		a.t.Errorf("routertest: %q trace ID = %q, want %q", a.r.Input, a.r.TraceID, want)
+	}
+	return a
+}
+
+// CollectedErrors 断言处理器通过AddError记录了n个非致命错误
+func (a *Assertion) CollectedErrors(n int) *Assertion {
+	a.t.Helper()
+	if a.captured() && len(a.r.Errors) != n {
+		a.t.Errorf("routertest: %q collected %d errors %v, want %d", a.r.Input, len(a.r.Errors), a.r.Errors, n)
+	}
+	return a
+}
+
+// Attachment 断言附加缓冲区name的内容为want
+func (a *Assertion) Attachment(name, want string) *Assertion {
+	a.t.Helper()
+	if !a.captured() {
+		return a
+	}
+	if got, ok := a.r.Attachments[name]; !ok || string(got) != want {
+		a.t.Errorf("routertest: %q attachment %q = %q (present: %v), want %q", a.r.Input, name, got, ok, want)
+	}
+	return a
+}
+
+// Response 断言响应内容为want
+func (a *Assertion) Response(want string) *Assertion {
+	a.t.Helper()
+	if a.r.Response == nil || string(a.r.Response) != want {
+		a.t.Errorf("routertest: %q response = %q, want %q", a.r.Input, a.r.Response, want)
+	}
+	return a
+}
+
+// NoResponse 断言处理器没有写入响应
+func (a *Assertion) NoResponse() *Assertion {
+	a.t.Helper()
+	if a.r.Response != nil {
+		a.t.Errorf("routertest: %q response = %q, want none", a.r.Input, a.r.Response)
+	}
+	return a
+}
+
+// Output 断言处理完成后输入缓冲区的内容为want
+func (a *Assertion) Output(want string) *Assertion {
+	a.t.Helper()
+	if string(a.r.Output) != want {
+		a.t.Errorf("routertest: %q output = %q, want %q", a.r.Input, a.r.Output, want)
+	}
+	return a
+}
+
+// captured 检查消息是否到达了记录器的中间件，没有时报告一次错误
+func (a *Assertion) captured() bool {
+	a.t.Helper()
+	if !a.r.Captured && !a.reported {
+		a.reported = true
+		a.t.Errorf("routertest: %q did not reach the recorder middleware (err: %v)", a.r.Input, a.r.Err)
+	}
+	return a.r.Captured
+}
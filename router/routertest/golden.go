@@ -0,0 +1,225 @@
+package routertest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// UpdateEnv 是开启golden文件更新模式的环境变量
+// 设置为非空值时，Golden和RunGolden用实际结果覆盖golden文件，而不是比较
+//
+//	ROUTERTEST_UPDATE=1 go test ./...
+const UpdateEnv = "ROUTERTEST_UPDATE"
+
+// GoldenOption 定义golden文件比较的配置选项
+type GoldenOption func(*goldenConfig)
+
+// goldenConfig 保存golden文件比较的配置
+type goldenConfig struct {
+	update bool
+	keys   []any // 为nil时记录所有值
+}
+
+// WithUpdate 设置是否更新golden文件，默认由UpdateEnv环境变量决定
+// 可以用来接入测试自己的-update标志
+func WithUpdate(update bool) GoldenOption {
+	return func(c *goldenConfig) {
+		c.update = update
+	}
+}
+
+// WithValueKeys 只记录上下文中指定键的值，默认记录所有值
+// 上下文中有时间戳等每次运行都不同的值时，用它排除这些值
+func WithValueKeys(keys ...any) GoldenOption {
+	return func(c *goldenConfig) {
+		c.keys = append(c.keys, keys...)
+	}
+}
+
+// newGoldenConfig 应用配置选项
+func newGoldenConfig(opts []GoldenOption) goldenConfig {
+	cfg := goldenConfig{update: os.Getenv(UpdateEnv) != ""}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// golden 是golden文件的内容
+// 字节内容是合法的UTF-8时保存为字符串，否则保存为base64，便于审查差异
+type golden struct {
+	Route          string            `json:"route,omitempty"`
+	Unmatched      bool              `json:"unmatched,omitempty"`
+	Error          string            `json:"error,omitempty"`
+	Aborted        bool              `json:"aborted,omitempty"`
+	Output         string            `json:"output,omitempty"`
+	OutputBase64   string            `json:"output_base64,omitempty"`
+	Response       string            `json:"response,omitempty"`
+	ResponseBase64 string            `json:"response_base64,omitempty"`
+	Params         map[string]string `json:"params,omitempty"`
+	Values         map[string]any    `json:"values,omitempty"`
+	Errors         []string          `json:"errors,omitempty"`
+}
+
+// encodeBytes 按golden的约定编码字节内容
+func encodeBytes(data []byte) (text, b64 string) {
+	if utf8.Valid(data) {
+		return string(data), ""
+	}
+	return "", base64.StdEncoding.EncodeToString(data)
+}
+
+// marshalGolden 把结果编码为golden文件内容
+func (r *Result) marshalGolden(cfg goldenConfig) ([]byte, error) {
+	g := golden{Aborted: r.Aborted}
+	switch {
+	case r.Route == nil:
+		g.Unmatched = r.Captured
+	case r.Route.Name != "":
+		g.Route = r.Route.Name
+	default:
+		g.Route = r.Route.Pattern
+	}
+	if r.Err != nil {
+		g.Error = r.Err.Error()
+	}
+	g.Output, g.OutputBase64 = encodeBytes(r.Output)
+	if r.Response != nil {
+		g.Response, g.ResponseBase64 = encodeBytes(r.Response)
+	}
+	if len(r.Params) > 0 {
+		g.Params = r.Params
+	}
+	for _, err := range r.Errors {
+		g.Errors = append(g.Errors, err.Error())
+	}
+
+	values := map[string]any{}
+	record := func(key, value any) {
+		// 无法编码为JSON的值使用%v
+		if _, err := json.Marshal(value); err != nil {
+			value = fmt.Sprintf("%v", value)
+		}
+		values[fmt.Sprint(key)] = value
+	}
+	if cfg.keys != nil {
+		for _, key := range cfg.keys {
+			if value, ok := r.Values[key]; ok {
+				record(key, value)
+			}
+		}
+	} else {
+		for key, value := range r.Values {
+			record(key, value)
+		}
+	}
+	if len(values) > 0 {
+		g.Values = values
+	}
+
+	// encoding/json按键排序编码映射，输出是稳定的
+	data, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Golden 断言结果与golden文件一致
+// 文件保存匹配的路由、错误、输出缓冲区、响应、匹配参数、上下文中的值和非致命错误，格式为JSON。
+// 更新模式下用实际结果覆盖文件，必要时创建目录
+//   - path: golden文件路径，例如"testdata/orders.golden"
+func (a *Assertion) Golden(path string, opts ...GoldenOption) *Assertion {
+	a.t.Helper()
+	cfg := newGoldenConfig(opts)
+	got, err := a.r.marshalGolden(cfg)
+	if err != nil {
+		a.t.Errorf("routertest: encode golden %s: %v", path, err)
+		return a
+	}
+
+	if cfg.update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			a.t.Errorf("routertest: %v", err)
+		} else if err := os.WriteFile(path, got, 0o644); err != nil {
+			a.t.Errorf("routertest: %v", err)
+		}
+		return a
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		a.t.Errorf("routertest: %v (set %s=1 to create it)", err, UpdateEnv)
+		return a
+	}
+	if !bytes.Equal(got, want) {
+		a.t.Errorf("routertest: %q does not match %s (set %s=1 to update)\n%s", a.r.Input, path, UpdateEnv, lineDiff(want, got))
+	}
+	return a
+}
+
+// RunGolden 把dir下的每个*.input文件交给路由器处理，并与同名的*.golden文件比较
+// 每个输入文件作为一个子测试运行，子测试名称为去掉扩展名的文件名
+//
+//	testdata/
+//	  refund.input     // 消息内容
+//	  refund.golden    // 处理结果，更新模式下生成
+func RunGolden(t *testing.T, rec *RouteRecorder, dir string, opts ...GoldenOption) {
+	t.Helper()
+	inputs, err := filepath.Glob(filepath.Join(dir, "*.input"))
+	if err != nil {
+		t.Fatalf("routertest: %v", err)
+	}
+	if len(inputs) == 0 {
+		t.Fatalf("routertest: no *.input files in %s", dir)
+	}
+	sort.Strings(inputs)
+
+	for _, input := range inputs {
+		name := strings.TrimSuffix(filepath.Base(input), ".input")
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatalf("routertest: %v", err)
+			}
+			rec.RouteString(string(data)).Assert(t).Golden(strings.TrimSuffix(input, ".input")+".golden", opts...)
+		})
+	}
+}
+
+// lineDiff 逐行对比两段文本，标出不同的行
+func lineDiff(want, got []byte) string {
+	wantLines := strings.Split(string(want), "\n")
+	gotLines := strings.Split(string(got), "\n")
+	var b strings.Builder
+	for i := range max(len(wantLines), len(gotLines)) {
+		var w, g string
+		hasW, hasG := i < len(wantLines), i < len(gotLines)
+		if hasW {
+			w = wantLines[i]
+		}
+		if hasG {
+			g = gotLines[i]
+		}
+		switch {
+		case hasW && hasG && w == g:
+			fmt.Fprintf(&b, "  %s\n", w)
+		default:
+			if hasW {
+				fmt.Fprintf(&b, "- %s\n", w)
+			}
+			if hasG {
+				fmt.Fprintf(&b, "+ %s\n", g)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package routertest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aomirun/content-router/manage"
+)
+
+func TestRunGolden(t *testing.T) {
+	manager := manage.NewBufferManager()
+	_, rec := newTestRouter(manager)
+
+	RunGolden(t, rec, filepath.Join("testdata", "golden"))
+
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestGoldenUpdate(t *testing.T) {
+	_, rec := newTestRouter(manage.NewBufferManager())
+	path := filepath.Join(t.TempDir(), "nested", "order.golden")
+
+	res := rec.RouteString("order:7")
+	res.Assert(t).Golden(path, WithUpdate(true), WithValueKeys("tenant"))
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"route": "orders"`, `"id": "7"`, `"tenant": "acme"`, `"response": "accepted"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("golden file missing %s:\n%s", want, data)
+		}
+	}
+
+	// 更新后的文件应当能直接通过比较
+	ft := &fakeT{}
+	res.Assert(ft).Golden(path, WithUpdate(false), WithValueKeys("tenant"))
+	if len(ft.failures) != 0 {
+		t.Errorf("unexpected failures: %v", ft.failures)
+	}
+}
+
+func TestGoldenMismatch(t *testing.T) {
+	_, rec := newTestRouter(manage.NewBufferManager())
+	path := filepath.Join(t.TempDir(), "order.golden")
+	rec.RouteString("order:1").Assert(t).Golden(path, WithUpdate(true))
+
+	ft := &fakeT{}
+	rec.RouteString("order:2").Assert(ft).Golden(path, WithUpdate(false))
+	if len(ft.failures) != 1 {
+		t.Fatalf("failures = %v, want 1", ft.failures)
+	}
+	for _, want := range []string{`-     "id": "1"`, `+     "id": "2"`, UpdateEnv} {
+		if !strings.Contains(ft.failures[0], want) {
+			t.Errorf("failure message missing %q:\n%s", want, ft.failures[0])
+		}
+	}
+}
+
+func TestGoldenMissingFile(t *testing.T) {
+	_, rec := newTestRouter(manage.NewBufferManager())
+
+	ft := &fakeT{}
+	rec.RouteString("order:1").Assert(ft).Golden(filepath.Join(t.TempDir(), "missing.golden"), WithUpdate(false))
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], UpdateEnv) {
+		t.Errorf("failures = %v, want hint about %s", ft.failures, UpdateEnv)
+	}
+}
+
+func TestGoldenBinary(t *testing.T) {
+	res := &Result{Captured: true, Output: []byte{0xff, 0x00}, Response: []byte("ok")}
+	data, err := res.marshalGolden(goldenConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"output_base64": "/wA="`) || !strings.Contains(string(data), `"unmatched": true`) {
+		t.Errorf("unexpected golden:\n%s", data)
+	}
+}
@@ -0,0 +1,153 @@
+// Package routertest 提供测试路由规则、中间件和处理器的工具
+// 与net/http/httptest类似，RouteRecorder把消息交给真实的路由器处理，
+// 并记录匹配的路由、上下文的变化、输出缓冲区和错误，测试无需自行模拟上下文和缓冲区
+package routertest
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+)
+
+// Result 记录一条消息的处理结果
+type Result struct {
+	// Input 传给路由器的消息
+	Input []byte
+	// Output 处理完成后输入缓冲区的内容，处理器可能修改了它
+	Output []byte
+	// Response 处理器写入响应缓冲区的内容，没有响应时为nil
+	Response []byte
+	// Err Route返回的错误，包括终止原因
+	Err error
+
+	// Captured 为true时表示消息到达了记录器的中间件，以下字段有效
+	// 之前注册的中间件没有调用next时为false
+	Captured bool
+	// Route 匹配到的路由，没有匹配时为nil
+	Route *router_context.RouteInfo
+	// Aborted 处理是否被终止
+	Aborted bool
+	// Values 上下文中的键值
+	Values map[any]any
+	// Params 匹配参数
+	Params map[string]string
+	// Errors 处理器通过AddError记录的非致命错误
+	Errors []error
+	// TraceID 追踪ID
+	TraceID string
+	// Metadata 传输层元数据
+	Metadata router_context.Metadata
+	// Attachments 附加缓冲区的内容
+	Attachments map[string][]byte
+}
+
+// Matched 判断消息是否匹配了路由
+func (r *Result) Matched() bool {
+	return r.Route != nil
+}
+
+// RouteRecorder 把消息交给路由器处理并记录结果
+// 可以在多个goroutine中并发使用
+type RouteRecorder struct {
+	router router.Router
+
+	mu      sync.Mutex
+	results []*Result
+}
+
+// NewRouteRecorder 创建路由记录器
+// 记录器通过r.Use注册一个中间件，在它之后的中间件和处理器返回后读取上下文。
+// 在注册其他中间件之前创建记录器可以记录整个处理链的修改；
+// 之后创建时，之前注册的中间件在调用next之后做的修改不会被记录
+//   - r: 需要测试的路由器
+func NewRouteRecorder(r router.Router) *RouteRecorder {
+	rec := &RouteRecorder{
+		router: r,
+	}
+	r.Use(rec.capture)
+	return rec
+}
+
+// Route 路由一条消息并记录结果
+//   - ctx: 父上下文，可以通过router_context.WithMetadata携带传输层元数据
+//   - data: 消息内容
+func (rec *RouteRecorder) Route(ctx context.Context, data []byte) *Result {
+	manager := rec.router.BufferManager()
+	buf := manager.Acquire()
+	defer manager.Release(buf)
+	buf.Write(data)
+
+	result := &Result{Input: bytes.Clone(data)}
+	ctx = context.WithValue(ctx, resultKey{}, result)
+	_, result.Err = rec.router.Route(ctx, buf)
+	result.Output = bytes.Clone(buf.Get())
+
+	rec.mu.Lock()
+	rec.results = append(rec.results, result)
+	rec.mu.Unlock()
+	return result
+}
+
+// RouteString 使用context.Background()路由一条字符串消息并记录结果
+func (rec *RouteRecorder) RouteString(data string) *Result {
+	return rec.Route(context.Background(), []byte(data))
+}
+
+// Results 返回所有记录的结果，按完成顺序排列
+func (rec *RouteRecorder) Results() []*Result {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]*Result(nil), rec.results...)
+}
+
+// Reset 清空记录的结果
+func (rec *RouteRecorder) Reset() {
+	rec.mu.Lock()
+	rec.results = nil
+	rec.mu.Unlock()
+}
+
+// resultKey 是本次分发的Result在父上下文中的键
+type resultKey struct{}
+
+// capture 在分发完成后、上下文被重置前记录上下文的状态
+func (rec *RouteRecorder) capture(ctx router_context.Context, next router.HandlerFunc) error {
+	err := next(ctx)
+
+	result, ok := ctx.Value(resultKey{}).(*Result)
+	if !ok {
+		// 不是通过记录器路由的消息
+		return err
+	}
+	result.Captured = true
+	if info := ctx.Route(); info != nil {
+		copied := *info
+		result.Route = &copied
+	}
+	result.Aborted = ctx.IsAborted()
+	result.Values = map[any]any{}
+	ctx.Range(func(key, value any) bool {
+		result.Values[key] = value
+		return true
+	})
+	result.Params = map[string]string{}
+	for name, value := range ctx.Params() {
+		result.Params[name] = value
+	}
+	result.Errors = append([]error(nil), ctx.Errors()...)
+	result.TraceID = ctx.TraceID()
+	result.Metadata = ctx.Metadata()
+	result.Attachments = map[string][]byte{}
+	for _, name := range ctx.AttachmentNames() {
+		if buf, ok := ctx.Attachment(name); ok {
+			result.Attachments[name] = bytes.Clone(buf.Get())
+		}
+	}
+	if ctx.HasResponse() {
+		result.Response = bytes.Clone(ctx.Response().Get())
+	}
+	return err
+}
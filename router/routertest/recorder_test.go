@@ -0,0 +1,173 @@
+package routertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+)
+
+// fakeT 记录断言失败，用于验证断言本身
+type fakeT struct {
+	testing.TB
+	failures []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+var errRejected = errors.New("rejected")
+
+func newTestRouter(manager manage.BufferManager) (router.Router, *RouteRecorder) {
+	r := router.NewRouter(router.WithBufferManager(manager))
+	rec := NewRouteRecorder(r)
+	r.Use(func(ctx router_context.Context, next router.HandlerFunc) error {
+		ctx.Set("tenant", "acme")
+		ctx.SetTraceID("trace-1")
+		return next(ctx)
+	})
+	r.RegisterRoute(router_context.RouteInfo{Name: "orders"}, router.PrefixMatcher("order:"), func(ctx router_context.Context) error {
+		ctx.SetParam("id", strings.TrimPrefix(string(ctx.Buffer().Get()), "order:"))
+		ctx.AddError(errors.New("missing currency"))
+		ctx.Response().Write([]byte("accepted"))
+		ctx.Buffer().Write([]byte("!"))
+		return nil
+	})
+	r.Match("bad", func(ctx router_context.Context) error {
+		ctx.Abort(errRejected)
+		return nil
+	})
+	return r, rec
+}
+
+func TestRouteRecorder(t *testing.T) {
+	manager := manage.NewBufferManager()
+	_, rec := newTestRouter(manager)
+
+	res := rec.RouteString("order:42")
+	res.Assert(t).
+		Route("orders").
+		NoError().
+		Value("tenant", "acme").
+		NoValue("missing").
+		Param("id", "42").
+		TraceID("trace-1").
+		CollectedErrors(1).
+		Response("accepted").
+		Output("order:42!")
+	if !res.Matched() || string(res.Input) != "order:42" {
+		t.Errorf("result = %+v", res)
+	}
+
+	rec.RouteString("bad input").Assert(t).Route("bad").Aborted().Error(errRejected).ErrorContains("reject").NoResponse()
+	rec.RouteString("other").Assert(t).Unmatched().NoError().NoResponse().Output("other")
+
+	if n := len(rec.Results()); n != 3 {
+		t.Errorf("Results() = %d, want 3", n)
+	}
+	rec.Reset()
+	if n := len(rec.Results()); n != 0 {
+		t.Errorf("Results() after Reset = %d", n)
+	}
+	if stats := manager.Stats(); stats.Outstanding != 0 {
+		t.Errorf("outstanding buffers = %d", stats.Outstanding)
+	}
+}
+
+func TestRouteRecorder_Metadata(t *testing.T) {
+	_, rec := newTestRouter(manage.NewBufferManager())
+	ctx := router_context.WithMetadata(context.Background(), router_context.Metadata{Transport: "test", Source: "peer"})
+	res := rec.Route(ctx, []byte("order:1"))
+	if res.Metadata.Transport != "test" || res.Metadata.Source != "peer" {
+		t.Errorf("metadata = %+v", res.Metadata)
+	}
+}
+
+func TestRouteRecorder_Attachments(t *testing.T) {
+	r := router.NewRouter()
+	rec := NewRouteRecorder(r)
+	r.Match("a", func(ctx router_context.Context) error {
+		buf := r.BufferManager().Acquire()
+		buf.Write([]byte("header"))
+		ctx.SetAttachment("head", buf)
+		ctx.OnReset(func() { r.BufferManager().Release(buf) })
+		return nil
+	})
+	rec.RouteString("a").Assert(t).Attachment("head", "header")
+}
+
+func TestRouteRecorder_NotCaptured(t *testing.T) {
+	r := router.NewRouter()
+	r.Use(func(ctx router_context.Context, next router.HandlerFunc) error {
+		return errRejected
+	})
+	rec := NewRouteRecorder(r)
+	res := rec.RouteString("x")
+	if res.Captured {
+		t.Fatal("Captured = true for short-circuited message")
+	}
+
+	ft := &fakeT{}
+	res.Assert(ft).Error(errRejected).Route("x").Value("k", 1)
+	if len(ft.failures) != 1 || !strings.Contains(ft.failures[0], "did not reach") {
+		t.Errorf("failures = %q", ft.failures)
+	}
+}
+
+func TestAssertion_Failures(t *testing.T) {
+	_, rec := newTestRouter(manage.NewBufferManager())
+	res := rec.RouteString("order:42")
+
+	ft := &fakeT{}
+	res.Assert(ft).
+		Route("refunds").
+		Unmatched().
+		Error(errRejected).
+		ErrorContains("x").
+		Aborted().
+		Value("tenant", "other").
+		Value("missing", 1).
+		NoValue("tenant").
+		Param("id", "1").
+		TraceID("x").
+		CollectedErrors(0).
+		Attachment("none", "").
+		Response("rejected").
+		NoResponse().
+		Output("order:42")
+	if len(ft.failures) != 15 {
+		t.Errorf("got %d failures, want 15:\n%s", len(ft.failures), strings.Join(ft.failures, "\n"))
+	}
+}
+
+func TestRouteRecorder_Concurrent(t *testing.T) {
+	_, rec := newTestRouter(manage.NewBufferManager())
+	// 第一次分发时构建处理链，之后路由器才能并发使用
+	rec.RouteString("warmup")
+	rec.Reset()
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := rec.RouteString(fmt.Sprintf("order:%d", i))
+			if res.Params["id"] != fmt.Sprint(i) {
+				t.Errorf("params = %v, want id=%d", res.Params, i)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := len(rec.Results()); n != 8 {
+		t.Errorf("Results() = %d, want 8", n)
+	}
+}
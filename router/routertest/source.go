@@ -0,0 +1,74 @@
+package routertest
+
+import (
+	"errors"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+)
+
+// ErrRejected 是NewSourceRouter创建的路由器拒绝消息时返回的错误
+var ErrRejected = errors.New("rejected")
+
+// ErrMissingMetadata 表示消息的传输层元数据与ExpectMetadata指定的不一致
+var ErrMissingMetadata = errors.New("missing metadata")
+
+// SourceOption 定义NewSourceRouter的配置选项
+type SourceOption func(*sourceConfig)
+
+// sourceConfig 保存NewSourceRouter的配置
+type sourceConfig struct {
+	metadata router_context.Metadata
+	reject   router.Matcher
+}
+
+// ExpectMetadata 设置期望的传输层元数据，只比较非空的Transport和Source
+// 不一致时Route返回ErrMissingMetadata
+func ExpectMetadata(md router_context.Metadata) SourceOption {
+	return func(c *sourceConfig) {
+		c.metadata = md
+	}
+}
+
+// RejectWhen 设置被拒绝的消息，默认拒绝内容为"bad"的消息
+func RejectWhen(matcher router.Matcher) SourceOption {
+	return func(c *sourceConfig) {
+		c.reject = matcher
+	}
+}
+
+// NewSourceRouter 创建测试消息来源（source包下的数据源、传输层等）使用的路由器
+// 路由器匹配所有消息，每条消息依次：交给observe记录，检查传输层元数据，
+// 拒绝RejectWhen匹配的消息并返回ErrRejected。测试可以借此验证数据源设置的元数据以及处理失败时的行为
+//   - manager: 路由器使用的缓冲区管理器
+//   - observe: 每条消息调用一次，返回错误时Route返回该错误；为nil时忽略
+func NewSourceRouter(manager manage.BufferManager, observe router.HandlerFunc, opts ...SourceOption) router.Router {
+	cfg := sourceConfig{
+		reject: router.MatcherFunc(func(ctx router_context.Context) bool {
+			return string(ctx.Buffer().Get()) == "bad"
+		}),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	r := router.NewRouter(router.WithBufferManager(manager))
+	r.Register(router.MatcherFunc(func(router_context.Context) bool { return true }), func(ctx router_context.Context) error {
+		if observe != nil {
+			if err := observe(ctx); err != nil {
+				return err
+			}
+		}
+		md := ctx.Metadata()
+		if (cfg.metadata.Transport != "" && md.Transport != cfg.metadata.Transport) ||
+			(cfg.metadata.Source != "" && md.Source != cfg.metadata.Source) {
+			return ErrMissingMetadata
+		}
+		if cfg.reject.Match(ctx) {
+			return ErrRejected
+		}
+		return nil
+	})
+	return r
+}
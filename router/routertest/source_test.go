@@ -0,0 +1,64 @@
+package routertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+)
+
+func TestNewSourceRouter(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []string
+	r := NewSourceRouter(manager, func(ctx router_context.Context) error {
+		seen = append(seen, string(ctx.Buffer().Get()))
+		return nil
+	}, ExpectMetadata(router_context.Metadata{Transport: "test"}))
+
+	route := func(ctx context.Context, data string) error {
+		buf := manager.Acquire()
+		defer manager.Release(buf)
+		buf.WriteString(data)
+		_, err := r.Route(ctx, buf)
+		return err
+	}
+	ctx := router_context.WithMetadata(context.Background(), router_context.Metadata{Transport: "test"})
+
+	if err := route(ctx, "ok"); err != nil {
+		t.Errorf("Route(ok) = %v", err)
+	}
+	if err := route(ctx, "bad"); !errors.Is(err, ErrRejected) {
+		t.Errorf("Route(bad) = %v, want ErrRejected", err)
+	}
+	if err := route(context.Background(), "ok"); !errors.Is(err, ErrMissingMetadata) {
+		t.Errorf("Route without metadata = %v, want ErrMissingMetadata", err)
+	}
+	if len(seen) != 3 {
+		t.Errorf("observe saw %v, want every message", seen)
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestNewSourceRouterRejectWhen(t *testing.T) {
+	errObserve := errors.New("observe failed")
+	r := NewSourceRouter(manage.NewBufferManager(), func(ctx router_context.Context) error {
+		if string(ctx.Buffer().Get()) == "fail" {
+			return errObserve
+		}
+		return nil
+	}, RejectWhen(router.PrefixMatcher("drop")))
+
+	for data, want := range map[string]error{"bad": nil, "drop:1": ErrRejected, "fail": errObserve} {
+		buf := buffer.NewBuffer()
+		buf.WriteString(data)
+		if _, err := r.Route(context.Background(), buf); !errors.Is(err, want) {
+			t.Errorf("Route(%q) = %v, want %v", data, err, want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package router
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+
+	"github.com/aomirun/content-router/frame"
+)
+
+// RouteStream 从r中逐帧读取消息并依次路由
+// 每一帧读入从路由器BufferManager获取的缓冲区，路由完成后立即释放
+func (r *routerImpl) RouteStream(ctx context.Context, reader io.Reader, framer frame.Framer) error {
+	br, ok := reader.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(reader)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		buf := r.bufferManager.Acquire()
+		if err := framer.ReadFrame(br, buf); err != nil {
+			r.bufferManager.Release(buf)
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		_, err := r.Route(ctx, buf)
+		r.bufferManager.Release(buf)
+		if err != nil {
+			return err
+		}
+	}
+}
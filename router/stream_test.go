@@ -0,0 +1,82 @@
+package router
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
+	"github.com/aomirun/content-router/manage"
+)
+
+// lineFramer 按换行符分帧，用于测试
+var lineFramer = frame.FramerFunc(func(r *bufio.Reader, buf buffer.Buffer) error {
+	line, err := r.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	buf.WriteString(strings.TrimSuffix(line, "\n"))
+	return nil
+})
+
+func TestRouter_RouteStream(t *testing.T) {
+	manager := manage.NewBufferManager()
+	r := NewRouter(WithBufferManager(manager))
+
+	var got []string
+	r.Match("", func(ctx router_context.Context) error {
+		got = append(got, string(ctx.Buffer().Get()))
+		return nil
+	})
+
+	err := r.RouteStream(context.Background(), strings.NewReader("a\nbb\nccc\n"), lineFramer)
+	if err != nil {
+		t.Fatalf("RouteStream failed: %v", err)
+	}
+	if strings.Join(got, ",") != "a,bb,ccc" {
+		t.Errorf("Routed frames %v, expected [a bb ccc]", got)
+	}
+	if stats := manager.Stats(); stats.Acquired != stats.Released {
+		t.Errorf("Every frame buffer should be released, got %+v", stats)
+	}
+}
+
+func TestRouter_RouteStreamErrors(t *testing.T) {
+	r := NewRouter()
+	handlerErr := errors.New("handler failed")
+	count := 0
+	r.Match("", func(ctx router_context.Context) error {
+		count++
+		if string(ctx.Buffer().Get()) == "bad" {
+			return handlerErr
+		}
+		return nil
+	})
+
+	err := r.RouteStream(context.Background(), strings.NewReader("ok\nbad\nnever\n"), lineFramer)
+	if !errors.Is(err, handlerErr) {
+		t.Errorf("Expected handler error, got %v", err)
+	}
+	if count != 2 {
+		t.Errorf("RouteStream should stop at the first error, routed %d frames", count)
+	}
+
+	err = r.RouteStream(context.Background(), strings.NewReader("ok\ntrunc"), lineFramer)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Expected io.ErrUnexpectedEOF for a truncated frame, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := r.RouteStream(ctx, strings.NewReader("ok\n"), lineFramer); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+package router
+
+import "sync"
+
+// routeTable 是路由表的不可变快照
+// 注册、移除路由，添加中间件或创建管道时复制出新的快照并原子替换当前快照，
+// 正在进行的分发继续使用开始时读取的快照，因此修改可以与Route并发进行
+type routeTable struct {
+	routes      []routeEntry
+	middlewares []MiddlewareFunc
+	pipelines   []pipelineEntry
+
+	// chain 快照的处理链，第一次分发时构建，之后不再变化
+	chain lazyChain
+}
+
+// lazyChain 是第一次使用时构建的处理链
+type lazyChain struct {
+	once    sync.Once
+	handler HandlerFunc
+}
+
+// handler 返回快照的处理链，第一次调用时构建
+func (t *routeTable) handler(r *routerImpl) HandlerFunc {
+	t.chain.once.Do(func() {
+		t.chain.handler = r.buildHandlerChain(t)
+	})
+	return t.chain.handler
+}
+
+// update 在当前快照的副本上执行fn，fn返回true时用副本替换当前快照
+// 修改串行执行。副本与当前快照共享切片，fn只能替换切片或在末尾追加，
+// 不能改写已有元素：末尾追加的元素在旧快照的长度之外，对旧快照不可见
+func (r *routerImpl) update(fn func(t *routeTable) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current := r.table.Load()
+	next := &routeTable{
+		routes:      current.routes,
+		middlewares: current.middlewares,
+		pipelines:   current.pipelines,
+	}
+	if fn(next) {
+		r.table.Store(next)
+	}
+}
@@ -0,0 +1,82 @@
+package router
+
+// byteTrie 是字节字典树，转移表按字节类压缩：
+// 只在模式中出现的字节有独立的字节类，其余字节共用类0
+type byteTrie struct {
+	classes [256]uint16 // 字节到字节类的映射
+	nclass  int         // 字节类数量
+	delta   []int32     // 转移表，下标为state*nclass+class，-1表示没有转移
+	out     []int32     // 在该状态结束的模式中最小的下标，-1表示没有
+}
+
+// newByteTrie 根据模式创建字典树，模式的下标即其优先级，相同的模式保留最小的下标
+func newByteTrie(patterns [][]byte) byteTrie {
+	var t byteTrie
+	for _, pattern := range patterns {
+		for _, c := range pattern {
+			if t.classes[c] == 0 {
+				t.nclass++
+				t.classes[c] = uint16(t.nclass)
+			}
+		}
+	}
+	t.nclass++ // 类0：不在任何模式中出现的字节
+
+	t.addState()
+	for i, pattern := range patterns {
+		state := int32(0)
+		for _, c := range pattern {
+			idx := int(state)*t.nclass + int(t.classes[c])
+			if t.delta[idx] < 0 {
+				t.delta[idx] = t.addState()
+			}
+			state = t.delta[idx]
+		}
+		if t.out[state] < 0 {
+			t.out[state] = int32(i)
+		}
+	}
+	return t
+}
+
+// addState 添加一个没有转移的状态，返回其编号
+func (t *byteTrie) addState() int32 {
+	state := int32(len(t.out))
+	t.out = append(t.out, -1)
+	for range t.nclass {
+		t.delta = append(t.delta, -1)
+	}
+	return state
+}
+
+// prefixTrie 是前缀的字典树，找出数据的所有前缀中优先级最高的模式
+type prefixTrie struct {
+	byteTrie
+}
+
+// newPrefixTrie 根据前缀创建字典树
+func newPrefixTrie(prefixes [][]byte) *prefixTrie {
+	return &prefixTrie{byteTrie: newByteTrie(prefixes)}
+}
+
+// first 返回是data前缀的模式中最小的下标，没有时返回-1
+// 只沿data走一遍字典树，耗时与最长的匹配前缀成正比，与模式数量无关
+func (t *prefixTrie) first(data []byte) int {
+	best := t.out[0]
+	state := int32(0)
+	for _, c := range data {
+		if state = t.delta[int(state)*t.nclass+int(t.classes[c])]; state < 0 {
+			break
+		}
+		best = minOutput(best, t.out[state])
+	}
+	return int(best)
+}
+
+// minOutput 返回两个输出中较小的下标，-1表示没有
+func minOutput(a, b int32) int32 {
+	if a < 0 || (b >= 0 && b < a) {
+		return b
+	}
+	return a
+}
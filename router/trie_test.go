@@ -0,0 +1,107 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+)
+
+func TestPrefixTrie(t *testing.T) {
+	trie := newPrefixTrie([][]byte{[]byte("HELLO"), []byte("HE"), []byte("GET /"), []byte("HE"), {}})
+	tests := []struct {
+		data string
+		want int
+	}{
+		{"HELLO world", 0},
+		{"HELP", 1},
+		{"GET /index", 2},
+		{"POST /", 4}, // 空前缀匹配所有数据
+	}
+	for _, tt := range tests {
+		if got := trie.first([]byte(tt.data)); got != tt.want {
+			t.Errorf("first(%q) = %d, want %d", tt.data, got, tt.want)
+		}
+	}
+	if got := newPrefixTrie([][]byte{[]byte("ab")}).first([]byte("a")); got != -1 {
+		t.Errorf("first of a shorter input = %d, want -1", got)
+	}
+}
+
+func TestPrefixTrie_Random(t *testing.T) {
+	rng := rand.New(rand.NewPCG(5, 6))
+	randomBytes := func(n int) []byte {
+		b := make([]byte, n)
+		for i := range b {
+			b[i] = "ab/"[rng.IntN(3)]
+		}
+		return b
+	}
+	for range 50 {
+		prefixes := make([][]byte, 1+rng.IntN(30))
+		for i := range prefixes {
+			prefixes[i] = randomBytes(rng.IntN(6))
+		}
+		trie := newPrefixTrie(prefixes)
+		for range 50 {
+			data := randomBytes(rng.IntN(10))
+			want := -1
+			for i, prefix := range prefixes {
+				if bytes.HasPrefix(data, prefix) {
+					want = i
+					break
+				}
+			}
+			if got := trie.first(data); got != want {
+				t.Fatalf("prefixes %q, data %q: first = %d, want %d", prefixes, data, got, want)
+			}
+		}
+	}
+}
+
+func TestRouter_PrefixTrie(t *testing.T) {
+	// 前缀路由之间夹着其他路由时，合并后的匹配结果与逐条尝试相同
+	newRouter := func(minRoutes int, got *string) Router {
+		r := NewRouter(WithPrefixTrie(minRoutes))
+		handler := func(name string) HandlerFunc {
+			return func(ctx router_context.Context) error {
+				*got = name
+				return nil
+			}
+		}
+		for i := range 12 {
+			r.Match(fmt.Sprintf("CMD%d", i), handler(fmt.Sprintf("cmd%d", i)))
+		}
+		r.Match("/suffix/;", handler("suffix"))
+		for _, prefix := range []string{"GET /a", "GET /", "GET", "POST /", "PUT", "DELETE", "G", "GET /a"} {
+			r.RegisterRoute(router_context.RouteInfo{Name: prefix}, PrefixMatcher(prefix), handler(prefix))
+		}
+		return r
+	}
+
+	var grouped, scanned string
+	rg, rs := newRouter(4, &grouped), newRouter(0, &scanned)
+	words := []string{"CMD", "1", "0", "GET", " ", "/", "a", ";", "PUT", "G"}
+	rng := rand.New(rand.NewPCG(7, 8))
+	for range 1000 {
+		var msg []byte
+		for range rng.IntN(5) {
+			msg = append(msg, words[rng.IntN(len(words))]...)
+		}
+		grouped, scanned = "", ""
+		for _, r := range []Router{rg, rs} {
+			buf := buffer.NewBuffer()
+			buf.Write(msg)
+			if _, err := r.Route(context.Background(), buf); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if grouped != scanned {
+			t.Fatalf("message %q: grouped route %q, scanned route %q", msg, grouped, scanned)
+		}
+	}
+}
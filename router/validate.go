@@ -0,0 +1,187 @@
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// DiagnosticKind 定义路由表诊断的类型
+type DiagnosticKind int
+
+const (
+	// ShadowedRoute 路由永远不会被匹配，因为之前注册的路由匹配它能匹配的所有消息
+	// 例如PrefixMatcher("HE")之后的PrefixMatcher("HELLO")
+	ShadowedRoute DiagnosticKind = iota
+	// DuplicateRoute 路由的匹配器与之前注册的路由等价，同样永远不会被匹配
+	DuplicateRoute
+	// DuplicateName 路由名称与之前注册的路由重复，两条路由都可能被匹配，但在统计和报告中无法区分
+	DuplicateName
+)
+
+// String 返回诊断类型的名称
+func (k DiagnosticKind) String() string {
+	switch k {
+	case ShadowedRoute:
+		return "shadowed"
+	case DuplicateRoute:
+		return "duplicate"
+	case DuplicateName:
+		return "duplicate-name"
+	default:
+		return fmt.Sprintf("DiagnosticKind(%d)", int(k))
+	}
+}
+
+// MarshalText 以名称编码类型
+func (k DiagnosticKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Diagnostic 描述路由表中的一个问题
+type Diagnostic struct {
+	// Kind 问题类型
+	Kind DiagnosticKind `json:"kind"`
+	// Route 有问题的路由
+	Route RouteDescription `json:"route"`
+	// By 导致问题的、之前注册的路由
+	By RouteDescription `json:"by"`
+}
+
+// String 返回诊断的描述，例如`route #1 "hello" is shadowed by route #0 "he"`
+func (d Diagnostic) String() string {
+	var problem string
+	switch d.Kind {
+	case ShadowedRoute:
+		problem = "is shadowed by"
+	case DuplicateRoute:
+		problem = "duplicates the matcher of"
+	case DuplicateName:
+		problem = "reuses the name of"
+	default:
+		problem = d.Kind.String() + " of"
+	}
+	return fmt.Sprintf("route %s %s route %s", routeLabel(d.Route), problem, routeLabel(d.By))
+}
+
+// routeLabel 返回路由的简短标识：优先级加上名称或模式
+func routeLabel(route RouteDescription) string {
+	label := "#" + strconv.Itoa(route.Priority)
+	switch {
+	case route.Info.Name != "":
+		label += " " + strconv.Quote(route.Info.Name)
+	case route.Info.Pattern != "":
+		label += " " + strconv.Quote(route.Info.Pattern)
+	}
+	return label
+}
+
+// Validate 静态分析路由表
+// 只能分析内置的PrefixMatcher、SuffixMatcher、ContainsMatcher、MultiContainsMatcher和由它们组成的AllMatcher，
+// 其他匹配器被视为不透明的：它们不会覆盖其他路由，但可以被其他路由覆盖
+// 每条路由最多报告一个覆盖它的路由（最早注册的那个），结果按路由优先级排列
+func (r *routerImpl) Validate() []Diagnostic {
+	t := r.table.Load()
+	routes := t.routes
+	topo := Topology{Routes: t.describeRoutes()}
+	var diagnostics []Diagnostic
+	names := map[string]int{}
+	for i, entry := range routes {
+		for j := range i {
+			earlier := routes[j].matcher
+			if !covers(earlier, entry.matcher) {
+				continue
+			}
+			kind := ShadowedRoute
+			if covers(entry.matcher, earlier) {
+				kind = DuplicateRoute
+			}
+			diagnostics = append(diagnostics, Diagnostic{Kind: kind, Route: topo.Routes[i], By: topo.Routes[j]})
+			break
+		}
+
+		name := entry.info.Name
+		if name == "" {
+			continue
+		}
+		if j, ok := names[name]; ok {
+			diagnostics = append(diagnostics, Diagnostic{Kind: DuplicateName, Route: topo.Routes[i], By: topo.Routes[j]})
+			continue
+		}
+		names[name] = i
+	}
+	return diagnostics
+}
+
+// covers 判断匹配器a是否一定匹配b能匹配的所有消息，无法确定时返回false
+func covers(a, b Matcher) bool {
+	// MultiContainsMatcher是多个包含条件的“或”：b的每个条件都被a覆盖时a覆盖b
+	if multi, ok := b.(*multiContainsMatcher); ok {
+		for _, pattern := range multi.patterns {
+			if !covers(a, &literalMatcher{kind: literalContains, literal: pattern}) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch a := a.(type) {
+	case *multiContainsMatcher:
+		// a的任意一个条件覆盖b时a覆盖b
+		for _, pattern := range a.patterns {
+			if covers(&literalMatcher{kind: literalContains, literal: pattern}, b) {
+				return true
+			}
+		}
+		return false
+	case allMatcher:
+		// a的每个条件都覆盖b时a覆盖b，没有条件的AllMatcher匹配所有消息
+		for _, sub := range a {
+			if !covers(sub, b) {
+				return false
+			}
+		}
+		return true
+	case *literalMatcher:
+		if len(a.literal) == 0 {
+			return true
+		}
+		switch b := b.(type) {
+		case allMatcher:
+			// b的任意一个条件被a覆盖时，满足b的所有条件的消息也满足a
+			for _, sub := range b {
+				if covers(a, sub) {
+					return true
+				}
+			}
+		case *literalMatcher:
+			return a.coversLiteral(b)
+		}
+	case *lengthMatcher:
+		switch b := b.(type) {
+		case allMatcher:
+			for _, sub := range b {
+				if covers(a, sub) {
+					return true
+				}
+			}
+		case *lengthMatcher:
+			// b的范围包含在a的范围内
+			return a.min <= b.min && (a.max < 0 || (b.max >= 0 && b.max <= a.max))
+		}
+	}
+	return false
+}
+
+// coversLiteral 判断m是否覆盖字面量匹配器other
+func (m *literalMatcher) coversLiteral(other *literalMatcher) bool {
+	switch m.kind {
+	case literalPrefix:
+		return other.kind == literalPrefix && bytes.HasPrefix(other.literal, m.literal)
+	case literalSuffix:
+		return other.kind == literalSuffix && bytes.HasSuffix(other.literal, m.literal)
+	default:
+		// 以other.literal为前缀、后缀或包含它的消息都包含other.literal的子串
+		return bytes.Contains(other.literal, m.literal)
+	}
+}
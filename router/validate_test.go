@@ -0,0 +1,90 @@
+package router
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	router_context "github.com/aomirun/content-router/context"
+)
+
+func TestCovers(t *testing.T) {
+	opaque := MatcherFunc(func(ctx router_context.Context) bool { return true })
+	tests := []struct {
+		name string
+		a, b Matcher
+		want bool
+	}{
+		{"shorter prefix", PrefixMatcher("HE"), PrefixMatcher("HELLO"), true},
+		{"longer prefix", PrefixMatcher("HELLO"), PrefixMatcher("HE"), false},
+		{"equal prefix", PrefixMatcher("HE"), PrefixMatcher("HE"), true},
+		{"empty prefix", PrefixMatcher(""), opaque, true},
+		{"suffix", SuffixMatcher("!"), SuffixMatcher("world!"), true},
+		{"prefix and suffix", PrefixMatcher("a"), SuffixMatcher("a"), false},
+		{"contains prefix", ContainsMatcher("LL"), PrefixMatcher("HELLO"), true},
+		{"contains suffix", ContainsMatcher("x"), SuffixMatcher("abc"), false},
+		{"opaque", opaque, PrefixMatcher("a"), false},
+		{"opaque target", PrefixMatcher("a"), opaque, false},
+		{"empty all", AllMatcher(), PrefixMatcher("a"), true},
+		{"all covers", AllMatcher(PrefixMatcher("a"), SuffixMatcher("z")), AllMatcher(PrefixMatcher("ab"), opaque, SuffixMatcher("yz")), true},
+		{"all missing condition", AllMatcher(PrefixMatcher("a"), SuffixMatcher("z")), PrefixMatcher("ab"), false},
+		{"literal covers all", PrefixMatcher("a"), AllMatcher(opaque, PrefixMatcher("ab")), true},
+		{"multi covers literal", MultiContainsMatcher("x", "LL"), PrefixMatcher("HELLO"), true},
+		{"literal covers multi", ContainsMatcher("a"), MultiContainsMatcher("ab", "ca"), true},
+		{"literal covers part of multi", ContainsMatcher("a"), MultiContainsMatcher("ab", "c"), false},
+		{"wider length", LengthMatcher(0, 100), LengthMatcher(10, 20), true},
+		{"narrower length", LengthMatcher(10, 20), LengthMatcher(0, 100), false},
+		{"unbounded length", LengthMatcher(1, -1), LengthMatcher(5, -1), true},
+		{"bounded covers unbounded", LengthMatcher(1, 100), LengthMatcher(5, -1), false},
+		{"length covers all", LengthMatcher(0, 10), AllMatcher(PrefixMatcher("a"), LengthMatcher(1, 2)), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := covers(tt.a, tt.b); got != tt.want {
+				t.Errorf("covers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouter_Validate(t *testing.T) {
+	r := NewRouter()
+	r.RegisterRoute(router_context.RouteInfo{Name: "he"}, PrefixMatcher("HE"), handleOrders)
+	r.RegisterRoute(router_context.RouteInfo{Name: "hello"}, PrefixMatcher("HELLO"), handleOrders)
+	r.RegisterRoute(router_context.RouteInfo{Name: "json"}, SuffixMatcher("}"), handleOrders)
+	r.RegisterRoute(router_context.RouteInfo{Name: "json"}, PrefixMatcher("{"), handleOrders)
+	r.Match("HE", handleOrders)
+
+	diagnostics := r.Validate()
+	if len(diagnostics) != 3 {
+		t.Fatalf("diagnostics = %v", diagnostics)
+	}
+	want := []struct {
+		kind      DiagnosticKind
+		route, by int
+	}{
+		{ShadowedRoute, 1, 0},
+		{DuplicateName, 3, 2},
+		{DuplicateRoute, 4, 0},
+	}
+	for i, w := range want {
+		d := diagnostics[i]
+		if d.Kind != w.kind || d.Route.Priority != w.route || d.By.Priority != w.by {
+			t.Errorf("diagnostic %d = %v (%v), want %v %d by %d", i, d, d.Kind, w.kind, w.route, w.by)
+		}
+	}
+	if got := diagnostics[0].String(); got != `route #1 "hello" is shadowed by route #0 "he"` {
+		t.Errorf("String() = %q", got)
+	}
+	if got := diagnostics[2].String(); got != `route #4 "HE" duplicates the matcher of route #0 "he"` {
+		t.Errorf("String() = %q", got)
+	}
+	data, err := json.Marshal(diagnostics[0])
+	if err != nil || !strings.Contains(string(data), `"kind":"shadowed"`) {
+		t.Errorf("json = %s, %v", data, err)
+	}
+
+	if diagnostics := NewRouter().Validate(); diagnostics != nil {
+		t.Errorf("empty router diagnostics = %v", diagnostics)
+	}
+}
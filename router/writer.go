@@ -0,0 +1,42 @@
+package router
+
+import (
+	"context"
+	"io"
+
+	"github.com/aomirun/content-router/frame"
+)
+
+// streamWriter 是把写入的字节流交给RouteStream逐帧路由的io.WriteCloser
+type streamWriter struct {
+	pw   *io.PipeWriter
+	done chan struct{}
+	err  error
+}
+
+// Writer 返回把写入的字节流逐帧路由的io.WriteCloser
+// 写入的数据经过io.Pipe交给后台的RouteStream，Write在数据被读取后返回
+func (r *routerImpl) Writer(framer frame.Framer) io.WriteCloser {
+	pr, pw := io.Pipe()
+	w := &streamWriter{pw: pw, done: make(chan struct{})}
+	go func() {
+		defer close(w.done)
+		w.err = r.RouteStream(context.Background(), pr, framer)
+		// 路由停止后让阻塞中和之后的Write返回错误
+		pr.CloseWithError(w.err)
+	}()
+	return w
+}
+
+// Write 写入字节流，路由已经停止时返回停止的原因
+func (w *streamWriter) Write(p []byte) (int, error) {
+	return w.pw.Write(p)
+}
+
+// Close 结束字节流并等待已写入的帧路由完成
+// 返回: 路由或读取帧时遇到的第一个错误
+func (w *streamWriter) Close() error {
+	w.pw.Close()
+	<-w.done
+	return w.err
+}
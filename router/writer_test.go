@@ -0,0 +1,80 @@
+package router
+
+import (
+	"errors"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+)
+
+func TestRouter_Writer(t *testing.T) {
+	manager := manage.NewBufferManager()
+	r := NewRouter(WithBufferManager(manager))
+
+	var got []string
+	r.Match("", func(ctx router_context.Context) error {
+		got = append(got, string(ctx.Buffer().Get()))
+		return nil
+	})
+
+	w := r.Writer(lineFramer)
+	// 帧跨越多次写入
+	io.WriteString(w, "fi")
+	io.WriteString(w, "rst\nsec")
+	io.WriteString(w, "ond\n")
+	logger := log.New(w, "", 0)
+	logger.Print("from logger")
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	want := []string{"first", "second", "from logger"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("routed = %q, want %q", got, want)
+	}
+	if _, err := io.WriteString(w, "late\n"); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("Write() after Close error = %v, want io.ErrClosedPipe", err)
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRouter_WriterPartialFrame(t *testing.T) {
+	r := NewRouter()
+	r.Match("", func(ctx router_context.Context) error { return nil })
+
+	w := r.Writer(lineFramer)
+	io.WriteString(w, "complete\npartial")
+	if err := w.Close(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("Close() error = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestRouter_WriterHandlerError(t *testing.T) {
+	r := NewRouter()
+	errRejected := errors.New("rejected")
+	r.Match("bad", func(ctx router_context.Context) error { return errRejected })
+	r.Match("", func(ctx router_context.Context) error { return nil })
+
+	w := r.Writer(lineFramer)
+	io.WriteString(w, "ok\nbad\n")
+
+	// 路由失败后之后的写入最终返回该错误
+	var err error
+	for range 100 {
+		if _, err = io.WriteString(w, "more\n"); err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, errRejected) {
+		t.Errorf("Write() error = %v, want %v", err, errRejected)
+	}
+	if err := w.Close(); !errors.Is(err, errRejected) {
+		t.Errorf("Close() error = %v, want %v", err, errRejected)
+	}
+}
@@ -0,0 +1,78 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aomirun/content-router/router"
+)
+
+// ErrMatcherExists 表示同名的匹配器工厂已经注册
+var ErrMatcherExists = errors.New("rules: matcher factory already registered")
+
+// MatcherFactory 定义自定义匹配器的工厂函数
+//   - args: 规则文件中custom条件的args字段，未设置时为nil
+//
+// 返回: 匹配器，参数无效时返回错误，规则文件解析失败
+type MatcherFactory func(args json.RawMessage) (router.Matcher, error)
+
+// CustomSpec 定义自定义匹配条件，由注册的匹配器工厂创建匹配器
+//
+//	"match": {"custom": {"name": "geoip", "args": {"country": "DE"}}}
+type CustomSpec struct {
+	// Name 匹配器工厂的注册名称
+	Name string `json:"name"`
+	// Args 传给工厂函数的参数，格式由工厂决定
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]MatcherFactory{}
+)
+
+// RegisterMatcher 注册自定义匹配器工厂，规则文件通过名称引用
+// 应当在解析规则文件之前注册，通常在init函数或加载插件时调用
+// 返回: 名称已被注册时返回ErrMatcherExists
+func RegisterMatcher(name string, factory MatcherFactory) error {
+	if name == "" || factory == nil {
+		return errors.New("rules: matcher factory requires a name and a function")
+	}
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if _, ok := factories[name]; ok {
+		return fmt.Errorf("%w: %q", ErrMatcherExists, name)
+	}
+	factories[name] = factory
+	return nil
+}
+
+// Matchers 返回已注册的自定义匹配器名称，按名称排序
+func Matchers() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// matcher 调用注册的工厂创建匹配器
+func (c *CustomSpec) matcher() (router.Matcher, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[c.Name]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown custom matcher %q", c.Name)
+	}
+	m, err := factory(c.Args)
+	if err != nil {
+		return nil, fmt.Errorf("custom matcher %q: %w", c.Name, err)
+	}
+	return m, nil
+}
@@ -0,0 +1,175 @@
+// Package rules 从声明式的规则文件构建路由
+// 规则文件是JSON格式，每条规则由匹配条件和接收端组成，
+// 适合快速搭建处理管道和测试路由规则，cmd/content-router基于本包实现
+package rules
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+)
+
+// Config 定义规则文件
+type Config struct {
+	// Rules 按顺序匹配的规则列表，第一条匹配的规则生效
+	Rules []Rule `json:"rules"`
+}
+
+// Rule 定义一条规则
+type Rule struct {
+	// Name 规则名称，通过ctx.Route()提供给处理器
+	Name string `json:"name"`
+	// Match 匹配条件
+	Match MatchSpec `json:"match"`
+	// Sink 匹配的消息写入的接收端
+	Sink SinkSpec `json:"sink"`
+}
+
+// MatchSpec 定义匹配条件，设置的所有条件都满足时才匹配，全部为空时匹配所有消息
+type MatchSpec struct {
+	Prefix   string `json:"prefix,omitempty"`
+	Suffix   string `json:"suffix,omitempty"`
+	Contains string `json:"contains,omitempty"`
+	// Regex 正则表达式，命名分组作为捕获参数
+	Regex string `json:"regex,omitempty"`
+	// Template 模板，例如"GET {path} HTTP/1.1"
+	Template string `json:"template,omitempty"`
+	// JSON 字段路径到期望值的映射，消息必须是包含这些字段的JSON，见router.JSONFieldMatcher
+	JSON map[string]string `json:"json,omitempty"`
+	// Custom 由RegisterMatcher注册的自定义匹配器
+	Custom *CustomSpec `json:"custom,omitempty"`
+}
+
+// Load 读取并解析规则文件
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse 解析规则文件内容并检查规则是否有效，不允许未知字段
+func Parse(data []byte) (*Config, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var cfg Config
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	if len(cfg.Rules) == 0 {
+		return nil, errors.New("rules: no rules defined")
+	}
+	for i, rule := range cfg.Rules {
+		if _, err := rule.Match.matcher(); err != nil {
+			return nil, fmt.Errorf("rules: rule %d (%s): %w", i, rule.Name, err)
+		}
+		if err := rule.Sink.validate(); err != nil {
+			return nil, fmt.Errorf("rules: rule %d (%s): %w", i, rule.Name, err)
+		}
+	}
+	return &cfg, nil
+}
+
+// Apply 把规则注册到路由器
+// 返回: 关闭所有接收端的io.Closer，路由器停止使用后调用
+func (c *Config) Apply(r router.RouteRegistrar, opts ...Option) (io.Closer, error) {
+	o := newOptions(opts)
+	var sinks sinkList
+	for i, rule := range c.Rules {
+		matcher, err := rule.Match.matcher()
+		if err != nil {
+			sinks.Close()
+			return nil, fmt.Errorf("rules: rule %d (%s): %w", i, rule.Name, err)
+		}
+		sink, err := rule.Sink.open(&o)
+		if err != nil {
+			sinks.Close()
+			return nil, fmt.Errorf("rules: rule %d (%s): %w", i, rule.Name, err)
+		}
+		sinks = append(sinks, sink)
+
+		info := router_context.RouteInfo{
+			Name:     rule.Name,
+			Pattern:  rule.Match.String(),
+			Metadata: map[string]string{"sink": rule.Sink.Type},
+		}
+		r.RegisterRoute(info, matcher, sink.Handle)
+	}
+	return sinks, nil
+}
+
+// Validate 静态分析规则，返回永远不会被匹配的规则和重复的规则名称，见router.Validate
+// 只创建匹配器，不打开接收端
+func (c *Config) Validate() []router.Diagnostic {
+	r := router.NewRouter()
+	for _, rule := range c.Rules {
+		matcher, err := rule.Match.matcher()
+		if err != nil {
+			// Parse已经检查过匹配条件，只有直接构造的Config会走到这里
+			continue
+		}
+		info := router_context.RouteInfo{Name: rule.Name, Pattern: rule.Match.String()}
+		r.RegisterRoute(info, matcher, discardHandler)
+	}
+	return r.Validate()
+}
+
+// discardHandler 是Validate注册的占位处理器
+func discardHandler(ctx router_context.Context) error {
+	return nil
+}
+
+// matcher 根据匹配条件创建匹配器
+func (m MatchSpec) matcher() (router.Matcher, error) {
+	var matchers []router.Matcher
+	if m.Prefix != "" {
+		matchers = append(matchers, router.PrefixMatcher(m.Prefix))
+	}
+	if m.Suffix != "" {
+		matchers = append(matchers, router.SuffixMatcher(m.Suffix))
+	}
+	if m.Contains != "" {
+		matchers = append(matchers, router.ContainsMatcher(m.Contains))
+	}
+	if m.Regex != "" {
+		re, err := regexp.Compile(m.Regex)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, router.CaptureMatcher(re))
+	}
+	if m.Template != "" {
+		matchers = append(matchers, router.TemplateMatcher(m.Template))
+	}
+	for path, want := range m.JSON {
+		matchers = append(matchers, router.JSONFieldMatcher(path, want))
+	}
+	if m.Custom != nil {
+		custom, err := m.Custom.matcher()
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, custom)
+	}
+
+	// 使用AllMatcher使router.Validate能够分析前缀、后缀和包含条件
+	return router.AllMatcher(matchers...), nil
+}
+
+// String 返回匹配条件的描述
+func (m MatchSpec) String() string {
+	data, _ := json.Marshal(m)
+	return string(data)
+}
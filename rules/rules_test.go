@@ -0,0 +1,274 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+)
+
+func TestApply(t *testing.T) {
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		posted = append(posted, r.Header.Get("Content-Type")+" "+string(body))
+	}))
+	defer server.Close()
+
+	out := filepath.Join(t.TempDir(), "errors.log")
+	cfg, err := Parse([]byte(`{"rules": [
+		{"name": "orders", "match": {"prefix": "{", "json": {"type": "order"}},
+		 "sink": {"type": "http", "url": "` + server.URL + `", "content_type": "application/json"}},
+		{"name": "errors", "match": {"regex": "^(?P<level>ERROR|FATAL) "}, "sink": {"type": "file", "path": "` + out + `"}},
+		{"name": "rest", "match": {}, "sink": {"type": "stdout"}}
+	]}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	var stdout bytes.Buffer
+	r := router.NewRouter()
+	closer, err := cfg.Apply(r, WithStdout(&stdout))
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	for _, msg := range []string{`{"type":"order","id":1}`, `{"type":"refund"}`, "ERROR disk full", "FATAL oom", "hello"} {
+		buf := buffer.NewBuffer()
+		buf.WriteString(msg)
+		if _, err := r.Route(context.Background(), buf); err != nil {
+			t.Fatalf("Route(%q): %v", msg, err)
+		}
+	}
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if len(posted) != 1 || posted[0] != `application/json {"type":"order","id":1}` {
+		t.Errorf("Unexpected HTTP posts %q", posted)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil || string(data) != "ERROR disk full\nFATAL oom\n" {
+		t.Errorf("Unexpected file contents %q, %v", data, err)
+	}
+	if stdout.String() != "{\"type\":\"refund\"}\nhello\n" {
+		t.Errorf("Unexpected stdout %q", stdout.String())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg, err := Parse([]byte(`{"rules": [
+		{"name": "orders", "match": {"prefix": "{", "json": {"type": "order"}}, "sink": {"type": "discard"}},
+		{"name": "paid", "match": {"prefix": "{\"", "json": {"status": "paid"}}, "sink": {"type": "discard"}},
+		{"name": "rest", "match": {}, "sink": {"type": "discard"}},
+		{"name": "errors", "match": {"contains": "ERROR"}, "sink": {"type": "discard"}}
+	]}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	r := router.NewRouter()
+	if _, err := cfg.Apply(r); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	// JSON条件不透明，orders不覆盖paid；匹配所有消息的rest覆盖之后的errors
+	for _, diagnostics := range [][]router.Diagnostic{r.Validate(), cfg.Validate()} {
+		if len(diagnostics) != 1 || diagnostics[0].Kind != router.ShadowedRoute ||
+			diagnostics[0].Route.Info.Name != "errors" || diagnostics[0].By.Info.Name != "rest" {
+			t.Errorf("diagnostics = %v", diagnostics)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		config string
+		want   string
+	}{
+		{`{"rules": []}`, "no rules"},
+		{`{"rules": [{"sink": {"type": "ftp"}}]}`, "unknown sink type"},
+		{`{"rules": [{"sink": {"type": "file"}}]}`, "requires path"},
+		{`{"rules": [{"match": {"regex": "("}, "sink": {"type": "stdout"}}]}`, "missing closing"},
+		{`{"rules": [{"match": {"prefx": "a"}, "sink": {"type": "stdout"}}]}`, "unknown field"},
+	}
+	for _, tt := range tests {
+		if _, err := Parse([]byte(tt.config)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%s) = %v, want error containing %q", tt.config, err, tt.want)
+		}
+	}
+}
+
+func TestHTTPSinkStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	cfg, err := Parse([]byte(`{"rules": [{"name": "all", "sink": {"type": "http", "url": "` + server.URL + `"}}]}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	r := router.NewRouter()
+	if _, err := cfg.Apply(r); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	buf := buffer.NewBuffer()
+	buf.WriteString("x")
+	if _, err := r.Route(context.Background(), buf); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Errorf("Expected status error, got %v", err)
+	}
+}
+
+func TestHTTPSinkCancel(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cfg, err := Parse([]byte(`{"rules": [{"name": "all", "sink": {"type": "http", "url": "` + server.URL + `"}}]}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	r := router.NewRouter()
+	if _, err := cfg.Apply(r); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	// 取消路由的上下文时请求随之取消
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	buf := buffer.NewBuffer()
+	buf.WriteString("x")
+	if _, err := r.Route(ctx, buf); !errors.Is(err, context.Canceled) {
+		t.Errorf("Route err = %v, want context.Canceled", err)
+	}
+}
+
+func TestRequestContext(t *testing.T) {
+	deadline := time.Now().Add(time.Hour)
+	parent, cancelParent := context.WithDeadline(context.Background(), deadline)
+	defer cancelParent()
+	ctx := router_context.NewContext(parent, buffer.NewBuffer())
+	ctx.Set("key", "value")
+
+	reqCtx, cancel := requestContext(ctx)
+	if got, ok := reqCtx.Deadline(); !ok || !got.Equal(deadline) {
+		t.Errorf("Deadline = %v, %v; want %v", got, ok, deadline)
+	}
+	// 请求上下文不引用路由器的上下文
+	if reqCtx.Value("key") != nil {
+		t.Error("request context should not reach the pooled router context")
+	}
+	cancel()
+	if reqCtx.Err() == nil {
+		t.Error("cancel should cancel the request context")
+	}
+}
+
+func TestCustomMatcher(t *testing.T) {
+	err := RegisterMatcher("test-length", func(args json.RawMessage) (router.Matcher, error) {
+		var cfg struct {
+			Min int `json:"min"`
+		}
+		if err := json.Unmarshal(args, &cfg); err != nil {
+			return nil, err
+		}
+		return router.MatcherFunc(func(ctx router_context.Context) bool {
+			return ctx.Buffer().Len() >= cfg.Min
+		}), nil
+	})
+	// 注册是全局的，使用-count多次运行时已经注册过
+	if err != nil && !errors.Is(err, ErrMatcherExists) {
+		t.Fatal(err)
+	}
+	if err := RegisterMatcher("test-length", nil); err == nil {
+		t.Error("expected error for invalid registration")
+	}
+	if err := RegisterMatcher("test-length", func(json.RawMessage) (router.Matcher, error) { return nil, nil }); !errors.Is(err, ErrMatcherExists) {
+		t.Errorf("err = %v, want ErrMatcherExists", err)
+	}
+	if !slices.Contains(Matchers(), "test-length") {
+		t.Errorf("Matchers() = %v, want test-length", Matchers())
+	}
+
+	cfg, err := Parse([]byte(`{"rules": [
+		{"name": "long", "match": {"prefix": "x", "custom": {"name": "test-length", "args": {"min": 5}}}, "sink": {"type": "stdout"}}
+	]}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var stdout bytes.Buffer
+	r := router.NewRouter()
+	if _, err := cfg.Apply(r, WithStdout(&stdout)); err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range []string{"xx", "xxxxxx", "yyyyyy"} {
+		buf := buffer.NewBuffer()
+		buf.WriteString(msg)
+		if _, err := r.Route(context.Background(), buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stdout.String() != "xxxxxx\n" {
+		t.Errorf("stdout = %q, want only the long message", stdout.String())
+	}
+
+	for _, rule := range []string{
+		`{"name": "x", "match": {"custom": {"name": "missing"}}, "sink": {"type": "stdout"}}`,
+		`{"name": "x", "match": {"custom": {"name": "test-length", "args": "bad"}}, "sink": {"type": "stdout"}}`,
+	} {
+		if _, err := Parse([]byte(`{"rules": [` + rule + `]}`)); err == nil {
+			t.Errorf("Parse(%s): expected error", rule)
+		}
+	}
+}
+
+func TestJSONMatch(t *testing.T) {
+	cfg, err := Parse([]byte(`{"rules": [
+		{"name": "v2", "match": {"json": {"version": "2", "meta.type": "order.created"}}, "sink": {"type": "stdout"}}
+	]}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var stdout bytes.Buffer
+	r := router.NewRouter()
+	if _, err := cfg.Apply(r, WithStdout(&stdout)); err != nil {
+		t.Fatal(err)
+	}
+	for _, msg := range []string{
+		`{"version":2,"meta":{"type":"order.created"}}`,
+		`{"version":"2","meta":{"type":"order.created"}}`,
+		`{"version":3,"meta":{"type":"order.created"}}`,
+		`{"version":2,"meta":{"type":"order.updated"}}`,
+	} {
+		buf := buffer.NewBuffer()
+		buf.WriteString(msg)
+		if _, err := r.Route(context.Background(), buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "{\"version\":2,\"meta\":{\"type\":\"order.created\"}}\n{\"version\":\"2\",\"meta\":{\"type\":\"order.created\"}}\n"
+	if stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+}
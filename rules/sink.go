@@ -0,0 +1,237 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	router_context "github.com/aomirun/content-router/context"
+)
+
+// 内置的接收端类型
+const (
+	// SinkStdout 把消息逐行写到标准输出
+	SinkStdout = "stdout"
+	// SinkFile 把消息逐行追加到文件
+	SinkFile = "file"
+	// SinkHTTP 把每条消息作为请求体POST到URL
+	SinkHTTP = "http"
+	// SinkDiscard 丢弃消息，用于过滤
+	SinkDiscard = "discard"
+)
+
+// SinkSpec 定义接收端
+type SinkSpec struct {
+	// Type 接收端类型：stdout、file、http或discard
+	Type string `json:"type"`
+	// Path file接收端的文件路径
+	Path string `json:"path,omitempty"`
+	// URL http接收端的地址
+	URL string `json:"url,omitempty"`
+	// ContentType http接收端请求的Content-Type，默认application/octet-stream
+	ContentType string `json:"content_type,omitempty"`
+}
+
+// Option 定义应用规则时的配置选项
+type Option func(*options)
+
+// options 保存配置选项
+type options struct {
+	stdout     io.Writer
+	httpClient *http.Client
+}
+
+// WithStdout 设置stdout接收端的写入目标，默认os.Stdout
+func WithStdout(w io.Writer) Option {
+	return func(o *options) {
+		o.stdout = w
+	}
+}
+
+// WithHTTPClient 设置http接收端使用的http.Client，默认http.DefaultClient
+func WithHTTPClient(c *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = c
+	}
+}
+
+// newOptions 应用配置选项
+func newOptions(opts []Option) options {
+	o := options{
+		stdout:     os.Stdout,
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// sink 定义接收端
+type sink interface {
+	io.Closer
+	// Handle 把匹配的消息写入接收端
+	Handle(ctx router_context.Context) error
+}
+
+// validate 检查接收端配置
+func (s SinkSpec) validate() error {
+	switch s.Type {
+	case SinkStdout, SinkDiscard:
+	case SinkFile:
+		if s.Path == "" {
+			return errors.New("file sink requires path")
+		}
+	case SinkHTTP:
+		if s.URL == "" {
+			return errors.New("http sink requires url")
+		}
+	default:
+		return fmt.Errorf("unknown sink type %q", s.Type)
+	}
+	return nil
+}
+
+// open 创建接收端
+func (s SinkSpec) open(o *options) (sink, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	switch s.Type {
+	case SinkStdout:
+		return &lineSink{w: o.stdout}, nil
+	case SinkFile:
+		f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		return &lineSink{w: f, closer: f}, nil
+	case SinkHTTP:
+		contentType := s.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		return &httpSink{client: o.httpClient, url: s.URL, contentType: contentType}, nil
+	default:
+		return discardSink{}, nil
+	}
+}
+
+// lineSink 把每条消息作为一行写入
+type lineSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	line   []byte
+}
+
+// Handle 写入消息和换行符
+func (s *lineSink) Handle(ctx router_context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.line = append(append(s.line[:0], ctx.Buffer().Get()...), '\n')
+	_, err := s.w.Write(s.line)
+	return err
+}
+
+// Close 关闭底层文件
+func (s *lineSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// httpSink 把每条消息POST到URL
+type httpSink struct {
+	client      *http.Client
+	url         string
+	contentType string
+}
+
+// Handle 发送消息，响应状态不是2xx时返回错误
+// 返回前读完并关闭响应体，请求不会在Handle返回后继续使用上下文
+func (s *httpSink) Handle(ctx router_context.Context) error {
+	reqCtx, cancel := requestContext(ctx)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, s.url, bytes.NewReader(ctx.Buffer().Get()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", s.contentType)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(io.Discard, resp.Body)
+	if closeErr := resp.Body.Close(); err == nil {
+		err = closeErr
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("rules: POST %s: %s", s.url, resp.Status)
+	}
+	return err
+}
+
+// requestContext 返回HTTP请求使用的上下文，带有ctx的截止时间并随ctx取消，调用cancel后失效
+// 路由器的上下文在分发结束后被重置并放回对象池，而net/http的后台goroutine（拨号、读取响应）
+// 可能在请求返回后仍然访问请求的上下文，因此请求上下文不引用ctx，只监听ctx.Done()返回的通道
+func requestContext(ctx router_context.Context) (context.Context, context.CancelFunc) {
+	reqCtx, cancelReq := context.WithCancel(context.Background())
+	cancel := cancelReq
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancelDeadline context.CancelFunc
+		reqCtx, cancelDeadline = context.WithDeadline(reqCtx, deadline)
+		cancel = func() {
+			cancelDeadline()
+			cancelReq()
+		}
+	}
+	if done := ctx.Done(); done != nil {
+		go func() {
+			select {
+			case <-done:
+				cancel()
+			case <-reqCtx.Done():
+			}
+		}()
+	}
+	return reqCtx, cancel
+}
+
+// Close 实现io.Closer
+func (s *httpSink) Close() error {
+	return nil
+}
+
+// discardSink 丢弃消息
+type discardSink struct{}
+
+// Handle 丢弃消息
+func (discardSink) Handle(ctx router_context.Context) error {
+	return nil
+}
+
+// Close 实现io.Closer
+func (discardSink) Close() error {
+	return nil
+}
+
+// sinkList 是可以一次关闭的接收端列表
+type sinkList []sink
+
+// Close 关闭所有接收端，返回遇到的错误
+func (l sinkList) Close() error {
+	var errs []error
+	for _, s := range l {
+		if err := s.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
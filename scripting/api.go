@@ -0,0 +1,147 @@
+package scripting
+
+import (
+	"errors"
+
+	"github.com/aomirun/content-router/buffer/jsonutil"
+	router_context "github.com/aomirun/content-router/context"
+)
+
+// API 是脚本可以访问的受限上下文
+// 只提供读写消息内容、上下文中的字符串键、匹配参数和响应的方法，
+// 引擎适配器把这些方法暴露给脚本，例如goja直接传入*API，gopher-lua注册为userdata的方法。
+// API只在一次调用期间有效，调用返回后所有方法返回零值
+type API struct {
+	ctx router_context.Context
+}
+
+// expire 使API失效，防止脚本保存后在之后的调用中使用
+func (a *API) expire() {
+	a.ctx = nil
+}
+
+// Data 返回消息内容
+func (a *API) Data() string {
+	if a.ctx == nil {
+		return ""
+	}
+	return string(a.ctx.Buffer().Get())
+}
+
+// Len 返回消息长度
+func (a *API) Len() int {
+	if a.ctx == nil {
+		return 0
+	}
+	return a.ctx.Buffer().Len()
+}
+
+// SetData 替换消息内容，后续的处理器看到新的内容
+func (a *API) SetData(data string) {
+	if a.ctx == nil {
+		return
+	}
+	buf := a.ctx.Buffer()
+	buf.Reset()
+	buf.WriteString(data)
+}
+
+// Field 返回JSON消息中指定路径的字段，字符串字段返回解码后的值，其他类型返回原始文本
+// 字段不存在或消息不是JSON时返回空字符串
+func (a *API) Field(path string) string {
+	if a.ctx == nil {
+		return ""
+	}
+	raw, ok := jsonutil.Get(a.ctx.Buffer().Get(), path)
+	if !ok {
+		return ""
+	}
+	if s, ok := jsonutil.Unquote(raw); ok {
+		return s
+	}
+	return string(raw)
+}
+
+// Route 返回匹配的路由名称，匹配器中调用时返回空字符串
+func (a *API) Route() string {
+	if a.ctx == nil {
+		return ""
+	}
+	if info := a.ctx.Route(); info != nil {
+		return info.Name
+	}
+	return ""
+}
+
+// Get 返回上下文中字符串键对应的值，不存在时返回nil
+// 脚本只能访问以字符串为键的值，中间件使用的私有键类型对脚本不可见
+func (a *API) Get(key string) any {
+	if a.ctx == nil {
+		return nil
+	}
+	return a.ctx.Get(key)
+}
+
+// Set 设置上下文中字符串键对应的值
+func (a *API) Set(key string, value any) {
+	if a.ctx == nil {
+		return
+	}
+	a.ctx.Set(key, value)
+}
+
+// Param 返回匹配参数
+func (a *API) Param(name string) string {
+	if a.ctx == nil {
+		return ""
+	}
+	return a.ctx.Param(name)
+}
+
+// SetParam 设置匹配参数
+func (a *API) SetParam(name, value string) {
+	if a.ctx == nil {
+		return
+	}
+	a.ctx.SetParam(name, value)
+}
+
+// Respond 把内容追加到响应缓冲区
+func (a *API) Respond(data string) {
+	if a.ctx == nil {
+		return
+	}
+	a.ctx.Response().WriteString(data)
+}
+
+// Abort 以reason为原因终止处理
+func (a *API) Abort(reason string) {
+	if a.ctx == nil {
+		return
+	}
+	a.ctx.Abort(errors.New(reason))
+}
+
+// TraceID 返回追踪ID
+func (a *API) TraceID() string {
+	if a.ctx == nil {
+		return ""
+	}
+	return a.ctx.TraceID()
+}
+
+// Transport 返回传输层名称，例如"tcp"、"kafka"
+func (a *API) Transport() string {
+	if a.ctx == nil {
+		return ""
+	}
+	return a.ctx.Metadata().Transport
+}
+
+// Source 返回消息的来源地址
+func (a *API) Source() string {
+	if a.ctx == nil {
+		return ""
+	}
+	return a.ctx.Metadata().Source
+}
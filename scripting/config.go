@@ -0,0 +1,193 @@
+package scripting
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/rules"
+)
+
+// 脚本函数的默认名称
+const (
+	// DefaultMatchFunc 是匹配函数的默认名称
+	DefaultMatchFunc = "match"
+	// DefaultHandleFunc 是处理函数的默认名称
+	DefaultHandleFunc = "handle"
+)
+
+// Config 定义脚本路由配置文件
+//
+//	{
+//	  "routes": [
+//	    {"name": "geo", "lang": "lua", "file": "geo.lua"},
+//	    {"name": "vip", "lang": "js", "source": "function match(api) { return api.Field('tier') === 'vip' }", "handle": "route"}
+//	  ]
+//	}
+type Config struct {
+	// Routes 按顺序注册的脚本路由
+	Routes []Route `json:"routes"`
+
+	// dir 是配置文件所在目录，相对路径的脚本文件相对于它解析
+	dir string
+}
+
+// Route 定义一条脚本路由
+type Route struct {
+	// Name 路由名称，通过ctx.Route()提供给处理器
+	Name string `json:"name"`
+	// Lang 脚本语言，对应WithEngine注册的引擎
+	Lang string `json:"lang"`
+	// File 脚本文件路径，相对路径相对于配置文件所在目录
+	File string `json:"file,omitempty"`
+	// Source 内联的脚本源码，与File二选一
+	Source string `json:"source,omitempty"`
+	// Match 匹配函数名称，默认DefaultMatchFunc
+	Match string `json:"match,omitempty"`
+	// Handle 处理函数名称，默认DefaultHandleFunc
+	Handle string `json:"handle,omitempty"`
+}
+
+// Load 读取并解析脚本路由配置文件
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	cfg.dir = filepath.Dir(path)
+	return cfg, nil
+}
+
+// Parse 解析配置文件内容，不允许未知字段
+// 脚本在Apply时才加载，因为引擎由Apply的选项提供
+func Parse(data []byte) (*Config, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var cfg Config
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	if len(cfg.Routes) == 0 {
+		return nil, errors.New("scripting: no routes defined")
+	}
+	for i, route := range cfg.Routes {
+		if route.Lang == "" {
+			return nil, fmt.Errorf("scripting: route %d (%s): lang is required", i, route.Name)
+		}
+		if (route.File == "") == (route.Source == "") {
+			return nil, fmt.Errorf("scripting: route %d (%s): exactly one of file and source is required", i, route.Name)
+		}
+	}
+	return &cfg, nil
+}
+
+// Apply 加载所有脚本并把路由注册到路由器
+// 任何一个脚本无法加载时不注册任何路由
+func (c *Config) Apply(r router.RouteRegistrar, opts ...Option) error {
+	o := newOptions(opts)
+	type compiled struct {
+		route   Route
+		program *Program
+	}
+	routes := make([]compiled, 0, len(c.Routes))
+	for i, route := range c.Routes {
+		program, err := compileSource(&o, route.Lang, c.resolve(route.File), route.Source)
+		if err != nil {
+			return fmt.Errorf("scripting: route %d (%s): %w", i, route.Name, err)
+		}
+		routes = append(routes, compiled{route, program})
+	}
+
+	for _, cr := range routes {
+		match, handle := cr.route.Match, cr.route.Handle
+		if match == "" {
+			match = DefaultMatchFunc
+		}
+		if handle == "" {
+			handle = DefaultHandleFunc
+		}
+		info := router_context.RouteInfo{
+			Name:     cr.route.Name,
+			Pattern:  cr.program.Name() + ":" + match,
+			Metadata: map[string]string{"lang": cr.route.Lang},
+		}
+		r.RegisterRoute(info, cr.program.Matcher(match), cr.program.Handler(handle))
+	}
+	return nil
+}
+
+// resolve 把相对路径解析为相对于配置文件目录的路径
+func (c *Config) resolve(path string) string {
+	if path == "" || filepath.IsAbs(path) || c.dir == "" {
+		return path
+	}
+	return filepath.Join(c.dir, path)
+}
+
+// compileSource 按语言选择引擎加载脚本文件或内联源码
+func compileSource(o *options, lang, file, source string) (*Program, error) {
+	engine, ok := o.engines[lang]
+	if !ok {
+		return nil, fmt.Errorf("no engine for language %q", lang)
+	}
+	name, data := "inline", []byte(source)
+	if file != "" {
+		var err error
+		if data, err = os.ReadFile(file); err != nil {
+			return nil, err
+		}
+		name = filepath.Base(file)
+	}
+	return Compile(engine, name, data, WithTimeout(o.timeout))
+}
+
+// RulesMatcherName 是RegisterRulesMatcher注册的自定义匹配器名称
+const RulesMatcherName = "script"
+
+// ruleArgs 是规则文件中script匹配器的参数
+type ruleArgs struct {
+	Lang     string `json:"lang"`
+	File     string `json:"file,omitempty"`
+	Source   string `json:"source,omitempty"`
+	Function string `json:"function,omitempty"`
+}
+
+// RegisterRulesMatcher 把脚本匹配器注册为rules包的自定义匹配器，规则文件可以这样引用:
+//
+//	"match": {"custom": {"name": "script", "args": {"lang": "lua", "file": "match.lua", "function": "match"}}}
+//
+// function默认DefaultMatchFunc，相对路径的脚本文件相对于当前工作目录
+func RegisterRulesMatcher(opts ...Option) error {
+	o := newOptions(opts)
+	return rules.RegisterMatcher(RulesMatcherName, func(raw json.RawMessage) (router.Matcher, error) {
+		if len(raw) == 0 {
+			return nil, errors.New("args are required")
+		}
+		var args ruleArgs
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&args); err != nil {
+			return nil, err
+		}
+		if (args.File == "") == (args.Source == "") {
+			return nil, errors.New("exactly one of file and source is required")
+		}
+		program, err := compileSource(&o, args.Lang, args.File, args.Source)
+		if err != nil {
+			return nil, err
+		}
+		if args.Function == "" {
+			args.Function = DefaultMatchFunc
+		}
+		return program.Matcher(args.Function), nil
+	})
+}
@@ -0,0 +1,204 @@
+// Package scripting 支持用Lua、JavaScript等脚本语言编写匹配器和处理器
+// 运维人员无需Go工具链即可调整路由行为。脚本只能通过受限的API访问消息和上下文，
+// 不能获取或释放缓冲区，也不能访问路由器
+//
+// 本包不依赖具体的脚本引擎，使用方通过实现Engine接口接入所选的解释器，
+// 例如github.com/yuin/gopher-lua或github.com/dop251/goja
+package scripting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+)
+
+// DefaultTimeout 是单次脚本调用的默认超时时间
+const DefaultTimeout = 100 * time.Millisecond
+
+// ErrNoFunction 表示脚本没有定义被调用的函数，由Script.Call返回
+var ErrNoFunction = errors.New("scripting: function not defined")
+
+// Engine 定义脚本引擎
+type Engine interface {
+	// Load 编译并执行脚本的顶层代码，返回一个独立的脚本实例
+	// 同一段脚本可能被加载多次，每个实例只会被一个goroutine使用，
+	// 因此解释器状态不需要支持并发访问
+	//   - name: 脚本名称，用于错误信息
+	//   - source: 脚本源码
+	Load(name string, source []byte) (Script, error)
+}
+
+// Script 定义已加载的脚本实例
+type Script interface {
+	// Call 调用脚本中定义的函数，把api作为唯一的参数传入
+	// ctx到期时应当中断脚本执行，例如goja的Runtime.Interrupt或gopher-lua的LState.SetContext
+	// 返回: 函数的返回值，函数不存在时返回ErrNoFunction
+	Call(ctx context.Context, fn string, api *API) (any, error)
+}
+
+// Option 定义脚本的配置选项
+type Option func(*options)
+
+// options 保存配置选项
+type options struct {
+	engines map[string]Engine
+	timeout time.Duration
+}
+
+// WithEngine 注册脚本语言对应的引擎，配置文件通过lang字段选择
+func WithEngine(lang string, engine Engine) Option {
+	return func(o *options) {
+		o.engines[lang] = engine
+	}
+}
+
+// WithTimeout 设置单次脚本调用的超时时间，默认DefaultTimeout，0表示不限制
+// 超时依赖引擎在ctx到期时中断脚本
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// newOptions 应用配置选项
+func newOptions(opts []Option) options {
+	o := options{
+		engines: map[string]Engine{},
+		timeout: DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Program 是编译后的脚本，可以创建匹配器和处理器
+// 脚本实例按需加载并在调用之间复用，并发调用时每个goroutine使用不同的实例。
+// 不使用sync.Pool，因为加载脚本的开销远大于缓冲区，实例被GC清除后重新加载的代价太高，
+// 空闲实例数不超过并发调用的峰值
+type Program struct {
+	name    string
+	source  []byte
+	engine  Engine
+	timeout time.Duration
+
+	mu   sync.Mutex
+	idle []Script
+}
+
+// Compile 加载脚本并检查能否编译，返回的Program可以在多个goroutine中使用
+// 只有WithTimeout选项对Compile有效
+func Compile(engine Engine, name string, source []byte, opts ...Option) (*Program, error) {
+	o := newOptions(opts)
+	p := &Program{
+		name:    name,
+		source:  source,
+		engine:  engine,
+		timeout: o.timeout,
+	}
+	script, err := engine.Load(name, source)
+	if err != nil {
+		return nil, fmt.Errorf("scripting: %s: %w", name, err)
+	}
+	p.release(script)
+	return p, nil
+}
+
+// Name 返回脚本名称
+func (p *Program) Name() string {
+	return p.name
+}
+
+// Call 调用脚本函数，api在调用返回后失效
+// 调用出错（包括超时被中断）的脚本实例状态不确定，不再复用；
+// 函数不存在（ErrNoFunction）时脚本没有执行，实例仍然复用
+func (p *Program) Call(ctx router_context.Context, fn string) (any, error) {
+	script, err := p.acquire()
+	if err != nil {
+		return nil, err
+	}
+
+	var callCtx context.Context = ctx
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	api := &API{ctx: ctx}
+	defer api.expire()
+	result, err := script.Call(callCtx, fn, api)
+	if errors.Is(err, ErrNoFunction) {
+		p.release(script)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("scripting: %s.%s: %w", p.name, fn, err)
+	}
+	p.release(script)
+	return result, nil
+}
+
+// acquire 取出一个空闲的脚本实例，没有空闲实例时重新加载
+func (p *Program) acquire() (Script, error) {
+	p.mu.Lock()
+	if n := len(p.idle); n > 0 {
+		script := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.mu.Unlock()
+		return script, nil
+	}
+	p.mu.Unlock()
+
+	script, err := p.engine.Load(p.name, p.source)
+	if err != nil {
+		return nil, fmt.Errorf("scripting: %s: %w", p.name, err)
+	}
+	return script, nil
+}
+
+// release 归还脚本实例
+func (p *Program) release(script Script) {
+	p.mu.Lock()
+	p.idle = append(p.idle, script)
+	p.mu.Unlock()
+}
+
+// Matcher 返回调用脚本函数fn的匹配器
+// 函数返回true时匹配，返回其他值时不匹配；调用出错时不匹配，错误通过ctx.AddError记录
+func (p *Program) Matcher(fn string) router.Matcher {
+	return router.MatcherFunc(func(ctx router_context.Context) bool {
+		result, err := p.Call(ctx, fn)
+		if err != nil {
+			ctx.AddError(err)
+			return false
+		}
+		matched, _ := result.(bool)
+		return matched
+	})
+}
+
+// Handler 返回调用脚本函数fn的处理器
+// 函数没有返回值或返回空值时处理成功，返回非空字符串时以该字符串作为错误，
+// 通过api.Abort终止时返回终止原因
+func (p *Program) Handler(fn string) router.HandlerFunc {
+	return func(ctx router_context.Context) error {
+		result, err := p.Call(ctx, fn)
+		if err != nil {
+			return err
+		}
+		switch v := result.(type) {
+		case error:
+			return v
+		case string:
+			if v != "" {
+				return fmt.Errorf("scripting: %s.%s: %s", p.name, fn, v)
+			}
+		}
+		return nil
+	}
+}
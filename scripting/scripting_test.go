@@ -0,0 +1,328 @@
+package scripting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/rules"
+)
+
+// scriptFunc 是测试脚本中的一个函数
+type scriptFunc func(ctx context.Context, api *API) (any, error)
+
+// fakeEngine 是测试用的脚本引擎，源码是scripts中预定义脚本的名称
+type fakeEngine struct {
+	scripts map[string]map[string]scriptFunc
+	loads   atomic.Int64
+}
+
+// fakeScript 是fakeEngine加载的脚本实例，检查实例没有被并发使用
+type fakeScript struct {
+	funcs map[string]scriptFunc
+	busy  atomic.Bool
+}
+
+func (e *fakeEngine) Load(name string, source []byte) (Script, error) {
+	funcs, ok := e.scripts[strings.TrimSpace(string(source))]
+	if !ok {
+		return nil, fmt.Errorf("syntax error in %s", name)
+	}
+	e.loads.Add(1)
+	return &fakeScript{funcs: funcs}, nil
+}
+
+func (s *fakeScript) Call(ctx context.Context, fn string, api *API) (any, error) {
+	if !s.busy.CompareAndSwap(false, true) {
+		panic("script instance used concurrently")
+	}
+	defer s.busy.Store(false)
+	f, ok := s.funcs[fn]
+	if !ok {
+		return nil, ErrNoFunction
+	}
+	return f(ctx, api)
+}
+
+func newFakeEngine() *fakeEngine {
+	return &fakeEngine{scripts: map[string]map[string]scriptFunc{
+		"orders": {
+			"match": func(_ context.Context, api *API) (any, error) {
+				return api.Field("type") == "order", nil
+			},
+			"handle": func(_ context.Context, api *API) (any, error) {
+				api.SetParam("id", api.Field("id"))
+				api.Set("tenant", "acme")
+				api.Respond("accepted " + api.Param("id") + " via " + api.Route())
+				if api.Field("amount") == "0" {
+					return "zero amount", nil
+				}
+				return nil, nil
+			},
+		},
+		"rewrite": {
+			"match": func(_ context.Context, api *API) (any, error) {
+				return strings.HasPrefix(api.Data(), "legacy:"), nil
+			},
+			"handle": func(_ context.Context, api *API) (any, error) {
+				api.SetData(strings.ToUpper(strings.TrimPrefix(api.Data(), "legacy:")))
+				api.Respond(api.Data())
+				return nil, nil
+			},
+		},
+		"reject": {
+			"check": func(_ context.Context, api *API) (any, error) {
+				return api.Len() > 0, nil
+			},
+			"deny": func(_ context.Context, api *API) (any, error) {
+				api.Abort("denied")
+				return nil, nil
+			},
+		},
+		"slow": {
+			"match": func(ctx context.Context, api *API) (any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			"ping": func(_ context.Context, api *API) (any, error) {
+				return true, nil
+			},
+		},
+	}}
+}
+
+func route(t *testing.T, r router.Router, msg string) (string, error) {
+	t.Helper()
+	buf := buffer.NewBuffer()
+	buf.WriteString(msg)
+	var out bytes.Buffer
+	err := r.RouteTo(context.Background(), buf, &out)
+	return out.String(), err
+}
+
+func TestConfigApply(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "orders.fake"), []byte("orders\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "scripts.json")
+	if err := os.WriteFile(path, []byte(`{"routes": [
+		{"name": "orders", "lang": "fake", "file": "orders.fake"},
+		{"name": "rewrite", "lang": "fake", "source": "rewrite"},
+		{"name": "reject", "lang": "fake", "source": "reject", "match": "check", "handle": "deny"}
+	]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := router.NewRouter()
+	if err := cfg.Apply(r, WithEngine("fake", newFakeEngine())); err != nil {
+		t.Fatal(err)
+	}
+
+	if out, err := route(t, r, `{"type":"order","id":"42"}`); err != nil || out != "accepted 42 via orders" {
+		t.Errorf("orders = %q, %v", out, err)
+	}
+	if _, err := route(t, r, `{"type":"order","id":"1","amount":0}`); err == nil || !strings.Contains(err.Error(), "zero amount") {
+		t.Errorf("err = %v, want zero amount", err)
+	}
+	if out, err := route(t, r, "legacy:hello"); err != nil || out != "HELLO" {
+		t.Errorf("rewrite = %q, %v", out, err)
+	}
+	if _, err := route(t, r, "other"); err == nil || err.Error() != "denied" {
+		t.Errorf("err = %v, want denied", err)
+	}
+
+	topo := r.Inspect()
+	if len(topo.Routes) != 3 || topo.Routes[0].Info.Pattern != "orders.fake:match" || topo.Routes[2].Info.Metadata["lang"] != "fake" {
+		t.Errorf("unexpected routes: %+v", topo.Routes)
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"routes": []}`,
+		`{"routes": [{"name": "x", "source": "orders"}]}`,
+		`{"routes": [{"name": "x", "lang": "fake"}]}`,
+		`{"routes": [{"name": "x", "lang": "fake", "file": "a", "source": "b"}]}`,
+		`{"routes": [{"name": "x", "lang": "fake", "source": "orders", "unknown": 1}]}`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%s): expected error", data)
+		}
+	}
+
+	for _, data := range []string{
+		`{"routes": [{"name": "x", "lang": "lua", "source": "orders"}]}`,
+		`{"routes": [{"name": "x", "lang": "fake", "source": "garbage"}]}`,
+		`{"routes": [{"name": "x", "lang": "fake", "file": "missing.fake"}]}`,
+	} {
+		cfg, err := Parse([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := router.NewRouter()
+		if err := cfg.Apply(r, WithEngine("fake", newFakeEngine())); err == nil {
+			t.Errorf("Apply(%s): expected error", data)
+		}
+		if n := len(r.Inspect().Routes); n != 0 {
+			t.Errorf("Apply(%s) registered %d routes", data, n)
+		}
+	}
+}
+
+func TestMatcherErrors(t *testing.T) {
+	engine := newFakeEngine()
+	program, err := Compile(engine, "slow", []byte("slow"), WithTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := router_context.NewContext(context.Background(), buffer.NewBuffer())
+	start := time.Now()
+	if program.Matcher("match").Match(ctx) {
+		t.Error("timed out matcher matched")
+	}
+	if time.Since(start) > time.Second {
+		t.Error("timeout not applied")
+	}
+	if program.Matcher("missing").Match(ctx) {
+		t.Error("missing function matched")
+	}
+	errs := ctx.Errors()
+	if len(errs) != 2 || !errors.Is(errs[0], context.DeadlineExceeded) || !errors.Is(errs[1], ErrNoFunction) {
+		t.Errorf("errors = %v", errs)
+	}
+}
+
+func TestProgramDiscardsFailedScript(t *testing.T) {
+	engine := newFakeEngine()
+	program, err := Compile(engine, "slow", []byte("slow"), WithTimeout(10*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := router_context.NewContext(context.Background(), buffer.NewBuffer())
+	if _, err := program.Call(ctx, "match"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	// 超时的实例被丢弃，之后的调用使用重新加载的实例
+	if result, err := program.Call(ctx, "ping"); err != nil || result != true {
+		t.Fatalf("Call after timeout = %v, %v", result, err)
+	}
+	if n := engine.loads.Load(); n != 2 {
+		t.Errorf("script loaded %d times, expected the timed out instance to be replaced", n)
+	}
+	// 成功调用的实例继续复用
+	program.Call(ctx, "ping")
+	if n := engine.loads.Load(); n != 2 {
+		t.Errorf("script loaded %d times, expected the healthy instance to be reused", n)
+	}
+	// 函数不存在时脚本没有执行，实例同样复用
+	for range 3 {
+		if _, err := program.Call(ctx, "missing"); !errors.Is(err, ErrNoFunction) {
+			t.Fatalf("err = %v, want ErrNoFunction", err)
+		}
+	}
+	if n := engine.loads.Load(); n != 2 {
+		t.Errorf("script loaded %d times, expected instances to be reused after ErrNoFunction", n)
+	}
+}
+
+func TestAPIExpires(t *testing.T) {
+	var saved *API
+	engine := &fakeEngine{scripts: map[string]map[string]scriptFunc{
+		"keep": {"match": func(_ context.Context, api *API) (any, error) {
+			saved = api
+			return api.Data() == "x", nil
+		}},
+	}}
+	program, err := Compile(engine, "keep", []byte("keep"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := buffer.NewBuffer()
+	buf.WriteString("x")
+	ctx := router_context.NewContext(context.Background(), buf)
+	if !program.Matcher("match").Match(ctx) {
+		t.Fatal("expected match")
+	}
+
+	saved.SetData("changed")
+	saved.Set("k", "v")
+	saved.Abort("late")
+	if saved.Data() != "" || string(buf.Get()) != "x" || ctx.Get("k") != nil || ctx.IsAborted() {
+		t.Error("expired API still has access to the context")
+	}
+}
+
+func TestProgramConcurrent(t *testing.T) {
+	engine := newFakeEngine()
+	program, err := Compile(engine, "orders", []byte("orders"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := router.NewRouter()
+	r.RegisterRoute(router_context.RouteInfo{Name: "orders"}, program.Matcher("match"), program.Handler("handle"))
+	route(t, r, `{"type":"order","id":"0"}`) // 构建处理链
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			for j := range 50 {
+				out, err := route(t, r, fmt.Sprintf(`{"type":"order","id":"%d-%d"}`, i, j))
+				if err != nil || out != fmt.Sprintf("accepted %d-%d via orders", i, j) {
+					t.Errorf("out = %q, err = %v", out, err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+	if n := engine.loads.Load(); n > 9 {
+		t.Errorf("script loaded %d times, instances are not reused", n)
+	}
+}
+
+func TestRegisterRulesMatcher(t *testing.T) {
+	// 注册是全局的，使用-count多次运行时已经注册过
+	if err := RegisterRulesMatcher(WithEngine("fake", newFakeEngine())); err != nil && !errors.Is(err, rules.ErrMatcherExists) {
+		t.Fatal(err)
+	}
+	cfg, err := rules.Parse([]byte(`{"rules": [
+		{"name": "checked", "match": {"custom": {"name": "script", "args": {"lang": "fake", "source": "reject", "function": "check"}}}, "sink": {"type": "stdout"}}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	r := router.NewRouter()
+	if _, err := cfg.Apply(r, rules.WithStdout(&stdout)); err != nil {
+		t.Fatal(err)
+	}
+	route(t, r, "hello")
+	route(t, r, "")
+	if stdout.String() != "hello\n" {
+		t.Errorf("stdout = %q, want hello", stdout.String())
+	}
+
+	for _, args := range []string{`{"lang": "fake"}`, `{"lang": "lua", "source": "reject"}`, `{"lang": "fake", "source": "reject", "extra": 1}`} {
+		rule := `{"rules": [{"name": "x", "match": {"custom": {"name": "script", "args": ` + args + `}}, "sink": {"type": "stdout"}}]}`
+		if _, err := rules.Parse([]byte(rule)); err == nil {
+			t.Errorf("args %s: expected error", args)
+		}
+	}
+}
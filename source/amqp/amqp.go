@@ -0,0 +1,186 @@
+// Package amqp 提供AMQP（RabbitMQ）消费者消息来源
+// 把每条投递的消息体作为缓冲区路由，并根据处理结果确认或拒绝投递
+//
+// 本包不依赖具体的AMQP客户端库，使用方通过实现Channel和Acknowledger接口接入所选的客户端
+package amqp
+
+import (
+	"context"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/source"
+)
+
+// Acknowledger 定义投递的确认操作
+type Acknowledger interface {
+	// Ack 确认投递
+	Ack() error
+	// Nack 拒绝投递，requeue为false时消息进入队列配置的死信交换机（如果有）
+	Nack(requeue bool) error
+}
+
+// Delivery 定义一条投递
+type Delivery struct {
+	Acknowledger
+
+	Exchange    string
+	RoutingKey  string
+	Headers     map[string]interface{}
+	ContentType string
+	MessageID   string
+	DeliveryTag uint64
+	Redelivered bool
+	Body        []byte
+}
+
+// Channel 定义本包需要的AMQP通道功能
+type Channel interface {
+	// Consume 开始消费队列，返回的通道在消费结束时关闭
+	Consume(ctx context.Context, queue string) (<-chan Delivery, error)
+}
+
+// Decision 定义处理器返回错误后对投递的处理方式
+type Decision int
+
+const (
+	// Requeue 拒绝投递并重新入队
+	Requeue Decision = iota
+	// DeadLetter 拒绝投递且不重新入队，消息进入队列配置的死信交换机
+	DeadLetter
+	// Ack 仍然确认投递，丢弃消息
+	Ack
+)
+
+// FailurePolicy 根据投递和处理器错误决定如何处理失败的投递
+type FailurePolicy func(d Delivery, err error) Decision
+
+// DeadLetterRedelivered 返回默认的失败策略
+// 首次失败重新入队，再次投递仍然失败时进入死信交换机，避免消息反复重试
+func DeadLetterRedelivered() FailurePolicy {
+	return func(d Delivery, err error) Decision {
+		if d.Redelivered {
+			return DeadLetter
+		}
+		return Requeue
+	}
+}
+
+// deliveryKey 是投递元数据在标准context中的键
+type deliveryKey struct{}
+
+// DeliveryFromContext 获取正在处理的投递，处理器可以直接传入路由上下文
+// 投递的Body只在路由期间有效，处理器应该读取ctx.Buffer()
+func DeliveryFromContext(ctx context.Context) (Delivery, bool) {
+	d, ok := ctx.Value(deliveryKey{}).(Delivery)
+	return d, ok
+}
+
+// Option 定义AMQP消息来源的配置选项
+type Option func(*amqpSource)
+
+// WithFailurePolicy 设置处理失败时的策略，默认DeadLetterRedelivered()
+func WithFailurePolicy(policy FailurePolicy) Option {
+	return func(s *amqpSource) {
+		if policy != nil {
+			s.policy = policy
+		}
+	}
+}
+
+// WithErrorHandler 设置投递处理或确认失败时的回调
+func WithErrorHandler(fn source.ErrorHandler) Option {
+	return func(s *amqpSource) {
+		s.onError = fn
+	}
+}
+
+// amqpSource 是AMQP消息来源的实现
+type amqpSource struct {
+	channel Channel
+	queue   string
+	router  source.Router
+	policy  FailurePolicy
+	onError source.ErrorHandler
+}
+
+// New 创建消费queue的AMQP消息来源
+// 每条投递的上下文通过DeliveryFromContext提供路由键和消息头，
+// 通过ctx.Metadata()提供传输层名称"amqp"、交换机和接收时间
+func New(channel Channel, queue string, r source.Router, opts ...Option) source.Source {
+	s := &amqpSource{
+		channel: channel,
+		queue:   queue,
+		router:  r,
+		policy:  DeadLetterRedelivered(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run 消费队列并路由投递，直到ctx被取消或投递通道关闭
+func (s *amqpSource) Run(ctx context.Context) error {
+	deliveries, err := s.channel.Consume(ctx, s.queue)
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case d, ok := <-deliveries:
+			if !ok {
+				return ctx.Err()
+			}
+			s.handle(ctx, d)
+		}
+	}
+}
+
+// handle 路由一条投递并确认或拒绝
+func (s *amqpSource) handle(ctx context.Context, d Delivery) {
+	decision := Ack
+	if err := s.route(ctx, d); err != nil {
+		s.report(err)
+		decision = s.policy(d, err)
+	}
+
+	var err error
+	switch decision {
+	case Ack:
+		err = d.Ack()
+	case DeadLetter:
+		err = d.Nack(false)
+	default:
+		err = d.Nack(true)
+	}
+	if err != nil {
+		s.report(err)
+	}
+}
+
+// route 路由一条投递
+func (s *amqpSource) route(ctx context.Context, d Delivery) error {
+	ctx = context.WithValue(ctx, deliveryKey{}, d)
+	ctx = router_context.WithMetadata(ctx, router_context.Metadata{
+		Transport:  "amqp",
+		Source:     d.Exchange,
+		ReceivedAt: time.Now(),
+	})
+
+	manager := s.router.BufferManager()
+	buf := manager.Acquire()
+	defer manager.Release(buf)
+	buf.Write(d.Body)
+	_, err := s.router.Route(ctx, buf)
+	return err
+}
+
+// report 调用错误回调
+func (s *amqpSource) report(err error) {
+	if s.onError != nil {
+		s.onError(err)
+	}
+}
@@ -0,0 +1,125 @@
+package amqp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+)
+
+// fakeAck 记录对投递的确认操作
+type fakeAck struct {
+	result *[]string
+	tag    string
+}
+
+func (a fakeAck) Ack() error {
+	*a.result = append(*a.result, a.tag+":ack")
+	return nil
+}
+
+func (a fakeAck) Nack(requeue bool) error {
+	if requeue {
+		*a.result = append(*a.result, a.tag+":requeue")
+	} else {
+		*a.result = append(*a.result, a.tag+":dead")
+	}
+	return nil
+}
+
+// fakeChannel 投递预设的消息后关闭通道
+type fakeChannel struct {
+	deliveries []Delivery
+	queue      string
+}
+
+func (c *fakeChannel) Consume(ctx context.Context, queue string) (<-chan Delivery, error) {
+	c.queue = queue
+	ch := make(chan Delivery, len(c.deliveries))
+	for _, d := range c.deliveries {
+		ch <- d
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestAMQPSource(t *testing.T) {
+	var result []string
+	delivery := func(tag, body string, redelivered bool) Delivery {
+		return Delivery{
+			Acknowledger: fakeAck{&result, tag},
+			Exchange:     "events",
+			RoutingKey:   "order.created",
+			Headers:      map[string]interface{}{"tenant": "acme"},
+			Redelivered:  redelivered,
+			Body:         []byte(body),
+		}
+	}
+	channel := &fakeChannel{deliveries: []Delivery{
+		delivery("1", "ok", false),
+		delivery("2", "bad", false),
+		delivery("3", "bad", true),
+	}}
+
+	manager := manage.NewBufferManager()
+	r := router.NewRouter(router.WithBufferManager(manager))
+	var keys []string
+	var md router_context.Metadata
+	r.Match("", func(ctx router_context.Context) error {
+		d, _ := DeliveryFromContext(ctx)
+		keys = append(keys, d.RoutingKey+"/"+d.Headers["tenant"].(string))
+		md = ctx.Metadata()
+		if string(ctx.Buffer().Get()) == "bad" {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+
+	var errs []error
+	src := New(channel, "orders", r, WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	if err := src.Run(context.Background()); err != nil {
+		t.Fatalf("Run should stop cleanly when deliveries close, got %v", err)
+	}
+
+	if channel.queue != "orders" {
+		t.Errorf("Expected to consume queue orders, got %q", channel.queue)
+	}
+	want := []string{"1:ack", "2:requeue", "3:dead"}
+	if len(result) != len(want) || result[0] != want[0] || result[1] != want[1] || result[2] != want[2] {
+		t.Errorf("Expected %v, got %v", want, result)
+	}
+	if len(keys) != 3 || keys[0] != "order.created/acme" {
+		t.Errorf("Unexpected delivery metadata %v", keys)
+	}
+	if md.Transport != "amqp" || md.Source != "events" {
+		t.Errorf("Unexpected metadata %+v", md)
+	}
+	if len(errs) != 2 {
+		t.Errorf("Expected two reported errors, got %v", errs)
+	}
+	if stats := manager.Stats(); stats.Outstanding != 0 {
+		t.Errorf("Every buffer should be released, got %+v", stats)
+	}
+}
+
+func TestAMQPFailurePolicy(t *testing.T) {
+	var result []string
+	channel := &fakeChannel{deliveries: []Delivery{
+		{Acknowledger: fakeAck{&result, "1"}, Body: []byte("bad")},
+	}}
+	r := router.NewRouter()
+	r.Match("", func(ctx router_context.Context) error {
+		return errors.New("rejected")
+	})
+
+	src := New(channel, "orders", r, WithFailurePolicy(func(d Delivery, err error) Decision {
+		return Ack
+	}))
+	src.Run(context.Background())
+	if len(result) != 1 || result[0] != "1:ack" {
+		t.Errorf("Custom policy should ack, got %v", result)
+	}
+}
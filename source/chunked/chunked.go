@@ -0,0 +1,226 @@
+// Package chunked 解码HTTP分块传输编码（chunked transfer encoding）并路由
+// 可以逐块路由，也可以重组为完整的消息体后路由，用于构建反向代理式的流量检查工具
+package chunked
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/source"
+)
+
+// DefaultMaxSize 是单个块或重组后消息体的默认最大长度
+const DefaultMaxSize = 16 << 20
+
+// maxLineSize 是块大小行的最大长度
+const maxLineSize = 4096
+
+var (
+	// ErrMalformed 表示输入不是合法的分块编码
+	ErrMalformed = errors.New("chunked: malformed chunked encoding")
+	// ErrTooLarge 表示块或重组后的消息体超过WithMaxSize设置的长度
+	ErrTooLarge = errors.New("chunked: chunk too large")
+)
+
+// Chunk 定义逐块路由时一个块的元数据
+type Chunk struct {
+	// Index 块的序号，从0开始
+	Index int
+	// Extensions 块扩展（块大小后面";"之后的部分），没有时为空
+	Extensions string
+}
+
+// Body 定义重组路由时消息体的元数据
+type Body struct {
+	// Chunks 组成消息体的数据块数量
+	Chunks int
+	// Trailer 结束块之后的尾部字段，没有时为空
+	Trailer http.Header
+}
+
+// chunkKey 是块元数据在标准context中的键
+type chunkKey struct{}
+
+// bodyKey 是消息体元数据在标准context中的键
+type bodyKey struct{}
+
+// ChunkFromContext 获取逐块路由时正在处理的块的元数据，处理器可以直接传入路由上下文
+func ChunkFromContext(ctx context.Context) (Chunk, bool) {
+	chunk, ok := ctx.Value(chunkKey{}).(Chunk)
+	return chunk, ok
+}
+
+// BodyFromContext 获取重组路由时消息体的元数据，处理器可以直接传入路由上下文
+func BodyFromContext(ctx context.Context) (Body, bool) {
+	body, ok := ctx.Value(bodyKey{}).(Body)
+	return body, ok
+}
+
+// Option 定义分块解码的配置选项
+type Option func(*options)
+
+// options 保存配置选项
+type options struct {
+	reassemble bool
+	maxSize    int
+	source     string
+	onError    source.ErrorHandler
+}
+
+// WithReassemble 把所有数据块重组为完整的消息体后作为一个缓冲区路由
+// 重组模式下尾部字段通过BodyFromContext提供
+func WithReassemble() Option {
+	return func(o *options) {
+		o.reassemble = true
+	}
+}
+
+// WithMaxSize 设置单个块（重组模式下为整个消息体）的最大长度，默认DefaultMaxSize
+func WithMaxSize(n int) Option {
+	return func(o *options) {
+		o.maxSize = n
+	}
+}
+
+// WithSource 设置ctx.Metadata()中的来源名称，例如连接的对端地址
+func WithSource(name string) Option {
+	return func(o *options) {
+		o.source = name
+	}
+}
+
+// WithErrorHandler 设置逐块路由时单个块处理失败的回调，设置后处理失败不会停止解码
+func WithErrorHandler(fn source.ErrorHandler) Option {
+	return func(o *options) {
+		o.onError = fn
+	}
+}
+
+// Route 从r解码一个分块编码的消息体并路由
+// 默认逐块路由，长度为0的结束块不会被路由；使用WithReassemble时重组后路由一次
+// r是*bufio.Reader时直接使用，消息体之后的数据保留在其中，便于在同一连接上继续读取下一个消息
+// 没有设置WithErrorHandler时遇到第一个处理失败即停止并返回错误
+// 返回: 读到结束块和尾部字段后返回nil，输入在消息体中间结束时返回io.ErrUnexpectedEOF
+func Route(ctx context.Context, r io.Reader, rt source.Router, opts ...Option) error {
+	o := options{maxSize: DefaultMaxSize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+
+	manager := rt.BufferManager()
+	md := router_context.Metadata{Transport: "chunked", Source: o.source}
+	buf := manager.Acquire()
+	defer func() {
+		manager.Release(buf)
+	}()
+
+	for index := 0; ; index++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		size, ext, err := readChunkHeader(br)
+		if err != nil {
+			return err
+		}
+		if size == 0 {
+			trailer, err := textproto.NewReader(br).ReadMIMEHeader()
+			if err != nil {
+				return fmt.Errorf("chunked: trailer: %w", unexpectedEOF(err))
+			}
+			if !o.reassemble {
+				return nil
+			}
+			md.ReceivedAt = time.Now()
+			body := Body{Chunks: index, Trailer: http.Header(trailer)}
+			_, err = rt.Route(context.WithValue(router_context.WithMetadata(ctx, md), bodyKey{}, body), buf)
+			return err
+		}
+
+		if size > int64(o.maxSize-buf.Len()) {
+			return ErrTooLarge
+		}
+		if _, err := io.CopyN(buf, br, size); err != nil {
+			return unexpectedEOF(err)
+		}
+		if err := readCRLF(br); err != nil {
+			return err
+		}
+		if o.reassemble {
+			continue
+		}
+
+		md.ReceivedAt = time.Now()
+		chunk := Chunk{Index: index, Extensions: ext}
+		_, rerr := rt.Route(context.WithValue(router_context.WithMetadata(ctx, md), chunkKey{}, chunk), buf)
+		manager.Release(buf)
+		buf = manager.Acquire()
+		if rerr != nil {
+			rerr = fmt.Errorf("chunked: chunk %d: %w", index, rerr)
+			if o.onError == nil {
+				return rerr
+			}
+			o.onError(rerr)
+		}
+	}
+}
+
+// readChunkHeader 读取块大小行，返回块大小和块扩展
+func readChunkHeader(br *bufio.Reader) (int64, string, error) {
+	line, err := readLine(br)
+	if err != nil {
+		return 0, "", err
+	}
+	sizeField, ext, _ := bytes.Cut(line, []byte(";"))
+	sizeField = bytes.TrimRight(sizeField, " \t")
+	size, err := strconv.ParseInt(string(sizeField), 16, 64)
+	if err != nil || size < 0 || len(sizeField) == 0 || sizeField[0] == '+' || sizeField[0] == '-' {
+		return 0, "", ErrMalformed
+	}
+	return size, string(bytes.TrimSpace(ext)), nil
+}
+
+// readLine 读取以LF或CRLF结尾的一行，返回的数据不包含行尾
+func readLine(br *bufio.Reader) ([]byte, error) {
+	line, err := br.ReadSlice('\n')
+	switch {
+	case errors.Is(err, bufio.ErrBufferFull) || len(line) > maxLineSize:
+		return nil, ErrMalformed
+	case err != nil:
+		return nil, unexpectedEOF(err)
+	}
+	line = bytes.TrimSuffix(line[:len(line)-1], []byte("\r"))
+	return line, nil
+}
+
+// readCRLF 读取块数据之后的行尾
+func readCRLF(br *bufio.Reader) error {
+	line, err := readLine(br)
+	if err != nil {
+		return err
+	}
+	if len(line) != 0 {
+		return ErrMalformed
+	}
+	return nil
+}
+
+// unexpectedEOF 把消息体中间的io.EOF转换为io.ErrUnexpectedEOF
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
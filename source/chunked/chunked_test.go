@@ -0,0 +1,163 @@
+package chunked
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http/httputil"
+	"strings"
+	"testing"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/router/routertest"
+)
+
+// seenChunk 记录处理器看到的一次路由
+type seenChunk struct {
+	chunk Chunk
+	body  Body
+	data  string
+}
+
+// newTestRouter 创建记录所有数据并拒绝内容为"bad"的数据的路由器
+func newTestRouter(manager manage.BufferManager, seen *[]seenChunk) router.Router {
+	return routertest.NewSourceRouter(manager, func(ctx router_context.Context) error {
+		chunk, _ := ChunkFromContext(ctx)
+		body, _ := BodyFromContext(ctx)
+		*seen = append(*seen, seenChunk{chunk: chunk, body: body, data: string(ctx.Buffer().Get())})
+		return nil
+	}, routertest.ExpectMetadata(router_context.Metadata{Transport: "chunked"}))
+}
+
+const testBody = "5;name=first\r\nhello\r\n7\r\n, world\r\n0\r\nChecksum: abc\r\n\r\n"
+
+func TestRouteChunks(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenChunk
+	r := newTestRouter(manager, &seen)
+
+	br := bufio.NewReader(strings.NewReader(testBody + "NEXT"))
+	if err := Route(context.Background(), br, r); err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	want := []seenChunk{
+		{chunk: Chunk{Index: 0, Extensions: "name=first"}, data: "hello"},
+		{chunk: Chunk{Index: 1}, data: ", world"},
+	}
+	if len(seen) != len(want) {
+		t.Fatalf("seen %d chunks, want %d", len(seen), len(want))
+	}
+	for i := range want {
+		if seen[i].chunk != want[i].chunk || seen[i].data != want[i].data {
+			t.Errorf("chunk %d = %+v, want %+v", i, seen[i], want[i])
+		}
+	}
+	if rest, _ := io.ReadAll(br); string(rest) != "NEXT" {
+		t.Errorf("remaining data = %q, want NEXT", rest)
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRouteReassemble(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenChunk
+	r := newTestRouter(manager, &seen)
+
+	if err := Route(context.Background(), strings.NewReader(testBody), r, WithReassemble()); err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if len(seen) != 1 {
+		t.Fatalf("seen %d bodies, want 1", len(seen))
+	}
+	got := seen[0]
+	if got.data != "hello, world" || got.body.Chunks != 2 || got.body.Trailer.Get("Checksum") != "abc" {
+		t.Errorf("body = %+v", got)
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRouteErrors(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenChunk
+	r := newTestRouter(manager, &seen)
+
+	tests := []struct {
+		name  string
+		input string
+		opts  []Option
+		want  error
+	}{
+		{"bad size", "zz\r\nhello\r\n0\r\n\r\n", nil, ErrMalformed},
+		{"negative size", "-1\r\n\r\n", nil, ErrMalformed},
+		{"missing crlf", "3\r\nabcX\r\n0\r\n\r\n", nil, ErrMalformed},
+		{"truncated data", "5\r\nhel", nil, io.ErrUnexpectedEOF},
+		{"truncated trailer", "0\r\nX-A: b\r\n", nil, io.ErrUnexpectedEOF},
+		{"empty input", "", nil, io.ErrUnexpectedEOF},
+		{"chunk too large", "8\r\n12345678\r\n0\r\n\r\n", []Option{WithMaxSize(4)}, ErrTooLarge},
+		{"body too large", "3\r\n123\r\n3\r\n456\r\n0\r\n\r\n", []Option{WithMaxSize(4), WithReassemble()}, ErrTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Route(context.Background(), strings.NewReader(tt.input), r, tt.opts...)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Route() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRouteHandlerErrors(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenChunk
+	r := newTestRouter(manager, &seen)
+
+	input := "3\r\nbad\r\n2\r\nok\r\n0\r\n\r\n"
+	if err := Route(context.Background(), strings.NewReader(input), r); err == nil || !strings.Contains(err.Error(), "chunk 0") {
+		t.Errorf("Route() error = %v, want chunk 0 error", err)
+	}
+
+	seen = nil
+	var errs []error
+	err := Route(context.Background(), strings.NewReader(input), r, WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	if err != nil || len(seen) != 2 || len(errs) != 1 {
+		t.Errorf("Route() error = %v, seen %d, errors %d; want nil, 2, 1", err, len(seen), len(errs))
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRouteFromConnection(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenChunk
+	r := newTestRouter(manager, &seen)
+
+	client, server := net.Pipe()
+	defer server.Close()
+	go func() {
+		defer client.Close()
+		w := httputil.NewChunkedWriter(client)
+		w.Write([]byte("first"))
+		w.Write([]byte("second"))
+		w.Close()
+		io.WriteString(client, "\r\n")
+	}()
+
+	if err := Route(context.Background(), server, r, WithSource("pipe")); err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if len(seen) != 2 || seen[0].data != "first" || seen[1].data != "second" {
+		t.Errorf("seen = %+v, want first and second", seen)
+	}
+}
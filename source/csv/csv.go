@@ -0,0 +1,209 @@
+// Package csv 提供CSV/TSV记录的逐行路由
+// 每条记录作为一个缓冲区路由，可选地把表头映射为字段名，便于ETL任务按行使用匹配器分支
+package csv
+
+import (
+	"context"
+	encoding_csv "encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/source"
+)
+
+// Record 定义一条记录的元数据
+type Record struct {
+	// Line 记录在输入中的起始行号，从1开始
+	Line int
+	// Fields 记录的字段值
+	Fields []string
+	// Header 表头，没有使用WithHeader或WithColumns时为nil
+	Header []string
+}
+
+// Field 按列名获取字段值，列名不存在或记录缺少该列时返回false
+func (r Record) Field(name string) (string, bool) {
+	for i, h := range r.Header {
+		if h == name {
+			if i < len(r.Fields) {
+				return r.Fields[i], true
+			}
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// recordKey 是记录元数据在标准context中的键
+type recordKey struct{}
+
+// RecordFromContext 获取正在处理的记录的元数据，处理器可以直接传入路由上下文
+func RecordFromContext(ctx context.Context) (Record, bool) {
+	rec, ok := ctx.Value(recordKey{}).(Record)
+	return rec, ok
+}
+
+// FieldMatcher 创建按列值匹配的匹配器，需要配合WithHeader或WithColumns使用
+func FieldMatcher(name, value string) router.Matcher {
+	return router.MatcherFunc(func(ctx router_context.Context) bool {
+		rec, ok := RecordFromContext(ctx)
+		if !ok {
+			return false
+		}
+		v, ok := rec.Field(name)
+		return ok && v == value
+	})
+}
+
+// Option 定义CSV路由的配置选项
+type Option func(*options)
+
+// options 保存配置选项
+type options struct {
+	comma      rune
+	comment    rune
+	header     bool
+	columns    []string
+	lazyQuotes bool
+	source     string
+	onError    source.ErrorHandler
+}
+
+// WithComma 设置字段分隔符，默认','
+func WithComma(r rune) Option {
+	return func(o *options) {
+		o.comma = r
+	}
+}
+
+// WithTSV 使用制表符作为字段分隔符
+func WithTSV() Option {
+	return WithComma('\t')
+}
+
+// WithComment 设置注释字符，以该字符开头的行会被忽略
+func WithComment(r rune) Option {
+	return func(o *options) {
+		o.comment = r
+	}
+}
+
+// WithHeader 把第一条记录作为表头，表头本身不会被路由
+func WithHeader() Option {
+	return func(o *options) {
+		o.header = true
+	}
+}
+
+// WithColumns 使用给定的列名作为表头，用于没有表头行的输入
+func WithColumns(names ...string) Option {
+	return func(o *options) {
+		o.columns = names
+	}
+}
+
+// WithLazyQuotes 允许不规范的引号，参见encoding/csv.Reader.LazyQuotes
+func WithLazyQuotes() Option {
+	return func(o *options) {
+		o.lazyQuotes = true
+	}
+}
+
+// WithSource 设置ctx.Metadata()中的来源名称，例如输入文件名
+func WithSource(name string) Option {
+	return func(o *options) {
+		o.source = name
+	}
+}
+
+// WithErrorHandler 设置单条记录处理失败时的回调，设置后处理失败不会停止路由
+func WithErrorHandler(fn source.ErrorHandler) Option {
+	return func(o *options) {
+		o.onError = fn
+	}
+}
+
+// Route 逐条读取r中的CSV记录并依次路由
+// 缓冲区内容是按相同分隔符重新编码的记录（不含行尾），字段数可以不一致
+// 没有设置WithErrorHandler时遇到第一个处理失败即停止并返回错误，错误中包含行号
+// 返回: 解析错误、ctx的错误或处理失败的错误，读完全部输入时返回nil
+func Route(ctx context.Context, r io.Reader, rt source.Router, opts ...Option) error {
+	o := options{comma: ','}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	reader := encoding_csv.NewReader(r)
+	reader.Comma = o.comma
+	reader.Comment = o.comment
+	reader.LazyQuotes = o.lazyQuotes
+	reader.FieldsPerRecord = -1
+
+	header := o.columns
+	if o.header {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("csv: header: %w", err)
+		}
+		header = fields
+	}
+
+	out := &bufferWriter{}
+	writer := encoding_csv.NewWriter(out)
+	writer.Comma = o.comma
+	manager := rt.BufferManager()
+	md := router_context.Metadata{Transport: "csv", Source: o.source}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("csv: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		buf := manager.Acquire()
+		out.buf = buf
+		writer.Write(fields)
+		writer.Flush()
+		// 去掉csv.Writer写入的行尾
+		buf.Truncate(buf.Len() - 1)
+
+		md.ReceivedAt = time.Now()
+		rec := Record{Line: line, Fields: fields, Header: header}
+		recCtx := context.WithValue(router_context.WithMetadata(ctx, md), recordKey{}, rec)
+		_, rerr := rt.Route(recCtx, buf)
+		out.buf = nil
+		manager.Release(buf)
+		if rerr != nil {
+			rerr = fmt.Errorf("csv: line %d: %w", line, rerr)
+			if o.onError == nil {
+				return rerr
+			}
+			o.onError(rerr)
+		}
+	}
+}
+
+// bufferWriter 把csv.Writer的输出写入当前记录的缓冲区
+type bufferWriter struct {
+	buf buffer.Buffer
+}
+
+// Write 实现io.Writer接口
+func (w *bufferWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
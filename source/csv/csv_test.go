@@ -0,0 +1,120 @@
+package csv
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/router/routertest"
+)
+
+// seenRecord 记录处理器看到的一条记录
+type seenRecord struct {
+	rec  Record
+	data string
+}
+
+// newTestRouter 创建记录所有记录并拒绝status为"bad"的记录的路由器
+func newTestRouter(manager manage.BufferManager, seen *[]seenRecord) router.Router {
+	return routertest.NewSourceRouter(manager, func(ctx router_context.Context) error {
+		rec, _ := RecordFromContext(ctx)
+		*seen = append(*seen, seenRecord{rec: rec, data: string(ctx.Buffer().Get())})
+		return nil
+	},
+		routertest.ExpectMetadata(router_context.Metadata{Transport: "csv"}),
+		routertest.RejectWhen(FieldMatcher("status", "bad")))
+}
+
+func TestRouteWithHeader(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenRecord
+	r := newTestRouter(manager, &seen)
+
+	input := "id,status,note\n1,ok,\"multi\nline\"\n# skipped\n2,ok\n"
+	if err := Route(context.Background(), strings.NewReader(input), r, WithHeader(), WithComment('#')); err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if len(seen) != 2 {
+		t.Fatalf("seen %d records, want 2", len(seen))
+	}
+
+	first := seen[0]
+	if first.rec.Line != 2 || first.data != "1,ok,\"multi\nline\"" {
+		t.Errorf("record 0 = line %d %q", first.rec.Line, first.data)
+	}
+	if note, ok := first.rec.Field("note"); !ok || note != "multi\nline" {
+		t.Errorf("Field(note) = %q, %v", note, ok)
+	}
+
+	second := seen[1]
+	if second.rec.Line != 5 || second.data != "2,ok" {
+		t.Errorf("record 1 = line %d %q", second.rec.Line, second.data)
+	}
+	if _, ok := second.rec.Field("note"); ok {
+		t.Error("Field(note) on short record should be missing")
+	}
+	if _, ok := second.rec.Field("unknown"); ok {
+		t.Error("Field(unknown) should be missing")
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRouteTSVWithColumns(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenRecord
+	r := newTestRouter(manager, &seen)
+
+	input := "1\tok\n2\tbad\n3\tok\n"
+	var errs []error
+	err := Route(context.Background(), strings.NewReader(input), r,
+		WithTSV(), WithColumns("id", "status"),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if len(seen) != 3 || seen[2].data != "3\tok" {
+		t.Errorf("seen = %+v, want records 1 to 3", seen)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "line 2") {
+		t.Errorf("errors = %v, want one line 2 error", errs)
+	}
+}
+
+func TestRouteStopsOnFirstError(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenRecord
+	r := newTestRouter(manager, &seen)
+
+	input := "id,status\n1,bad\n2,ok\n"
+	err := Route(context.Background(), strings.NewReader(input), r, WithHeader())
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("Route() error = %v, want line 2 error", err)
+	}
+	if len(seen) != 1 {
+		t.Errorf("seen %d records, want only the rejected record", len(seen))
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRouteParseError(t *testing.T) {
+	var seen []seenRecord
+	r := newTestRouter(manage.NewBufferManager(), &seen)
+
+	err := Route(context.Background(), strings.NewReader("a,\"b\n"), r)
+	if err == nil {
+		t.Fatal("Route() should fail on unterminated quote")
+	}
+	if err := Route(context.Background(), strings.NewReader("a,b\"c\n"), r, WithLazyQuotes()); err != nil {
+		t.Fatalf("Route() with lazy quotes error = %v", err)
+	}
+	if err := Route(context.Background(), strings.NewReader(""), r, WithHeader()); err != nil {
+		t.Fatalf("Route() on empty input error = %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+// Package file 提供文件消息来源
+// NewTail持续跟踪追加写入的文件，NewSpool监视投递目录并逐个处理新文件，
+// 两者都可以按行（或任意Framer）分帧，也可以把整个文件作为一条消息路由
+//
+// 文件变化通过定期轮询发现，不依赖操作系统的文件通知机制
+package file
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
+	"github.com/aomirun/content-router/source"
+)
+
+// DefaultPollInterval 是检查文件变化的默认间隔
+const DefaultPollInterval = time.Second
+
+// Position 定义消息在文件中的位置
+type Position struct {
+	// Path 文件路径
+	Path string
+	// Offset 消息在文件中的起始偏移量，可以用于记录处理进度
+	Offset int64
+}
+
+// positionKey 是消息位置在标准context中的键
+type positionKey struct{}
+
+// PositionFromContext 获取消息在文件中的位置，处理器可以直接传入路由上下文
+func PositionFromContext(ctx context.Context) (Position, bool) {
+	pos, ok := ctx.Value(positionKey{}).(Position)
+	return pos, ok
+}
+
+// Option 定义文件消息来源的配置选项
+type Option func(*options)
+
+// options 保存配置选项
+type options struct {
+	framer       frame.Framer
+	wholeFile    bool
+	pollInterval time.Duration
+	fromStart    bool
+	pattern      string
+	doneDir      string
+	failedDir    string
+	onError      source.ErrorHandler
+}
+
+// WithFramer 设置分帧方式，NewTail默认按行分帧
+func WithFramer(framer frame.Framer) Option {
+	return func(o *options) {
+		o.framer = framer
+		o.wholeFile = false
+	}
+}
+
+// WithWholeFile 把整个文件作为一条消息路由，这是NewSpool的默认行为
+// 该选项只作用于NewSpool
+func WithWholeFile() Option {
+	return func(o *options) {
+		o.framer = nil
+		o.wholeFile = true
+	}
+}
+
+// WithPollInterval 设置检查文件变化的间隔，默认DefaultPollInterval
+func WithPollInterval(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.pollInterval = d
+		}
+	}
+}
+
+// WithFromStart 让NewTail从文件开头读取，默认从当前末尾开始只读取新写入的内容
+// 轮转后的新文件总是从开头读取
+func WithFromStart() Option {
+	return func(o *options) {
+		o.fromStart = true
+	}
+}
+
+// WithPattern 设置NewSpool处理的文件名模式（filepath.Match语法），默认处理所有文件
+// 以"."开头的文件总是被忽略，写入方可以先写入隐藏的临时文件再重命名
+func WithPattern(pattern string) Option {
+	return func(o *options) {
+		o.pattern = pattern
+	}
+}
+
+// WithDoneDir 设置NewSpool处理成功的文件移动到的目录，默认删除处理成功的文件
+func WithDoneDir(dir string) Option {
+	return func(o *options) {
+		o.doneDir = dir
+	}
+}
+
+// WithFailedDir 设置NewSpool处理失败的文件移动到的目录
+// 默认把失败的文件留在原处，直到文件被修改后才重新处理
+func WithFailedDir(dir string) Option {
+	return func(o *options) {
+		o.failedDir = dir
+	}
+}
+
+// WithErrorHandler 设置消息处理失败或读取文件出错时的回调
+func WithErrorHandler(fn source.ErrorHandler) Option {
+	return func(o *options) {
+		o.onError = fn
+	}
+}
+
+// newOptions 应用配置选项
+func newOptions(opts []Option) options {
+	o := options{
+		pollInterval: DefaultPollInterval,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// report 调用错误回调
+func (o *options) report(err error) {
+	if o.onError != nil {
+		o.onError(err)
+	}
+}
+
+// messageContext 返回带有位置和元数据的消息上下文
+func messageContext(ctx context.Context, pos Position) context.Context {
+	ctx = context.WithValue(ctx, positionKey{}, pos)
+	return router_context.WithMetadata(ctx, router_context.Metadata{
+		Transport:  "file",
+		Source:     pos.Path,
+		ReceivedAt: time.Now(),
+	})
+}
+
+// routeFrames 从br逐帧读取并路由，单帧处理失败时报告错误并继续
+//   - consumed: 返回br的底层读取器在文件中的当前偏移量，用于计算帧的位置
+//
+// 返回: 处理失败的帧数和读取结束的原因，流在帧中间结束时先路由剩余的不完整帧再返回io.EOF
+func routeFrames(ctx context.Context, r source.Router, o *options, path string, br *bufio.Reader, consumed func() int64) (int, error) {
+	manager := r.BufferManager()
+	failed := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return failed, err
+		}
+
+		pos := Position{Path: path, Offset: consumed() - int64(br.Buffered())}
+		buf := manager.Acquire()
+		err := o.framer.ReadFrame(br, buf)
+		if err != nil && !(errors.Is(err, io.ErrUnexpectedEOF) && buf.Len() > 0) {
+			manager.Release(buf)
+			return failed, err
+		}
+
+		_, rerr := r.Route(messageContext(ctx, pos), buf)
+		manager.Release(buf)
+		if rerr != nil {
+			failed++
+			o.report(rerr)
+		}
+		if err != nil {
+			// 不完整的最后一帧已经路由
+			return failed, io.EOF
+		}
+	}
+}
+
+// countingReader 统计从底层读取的字节数
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read 读取数据并累计字节数
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
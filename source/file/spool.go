@@ -0,0 +1,137 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aomirun/content-router/source"
+)
+
+// spoolSource 是监视投递目录的消息来源
+type spoolSource struct {
+	dir    string
+	router source.Router
+	opts   options
+}
+
+// NewSpool 创建监视dir的消息来源
+// 按文件名顺序处理目录中的文件，默认把整个文件作为一条消息路由，
+// 设置WithFramer后逐帧路由。文件中所有消息都处理成功后删除文件或移动到WithDoneDir目录
+func NewSpool(dir string, r source.Router, opts ...Option) source.Source {
+	o := newOptions(opts)
+	if o.framer == nil {
+		o.wholeFile = true
+	}
+	return &spoolSource{dir: dir, router: r, opts: o}
+}
+
+// Run 定期扫描目录并处理新文件，直到ctx被取消或无法读取目录
+func (s *spoolSource) Run(ctx context.Context) error {
+	// failed 记录处理失败且留在原处的文件及其修改时间
+	failed := make(map[string]time.Time)
+	for {
+		entries, err := os.ReadDir(s.dir)
+		if err != nil {
+			return err
+		}
+
+		seen := make(map[string]time.Time, len(failed))
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.Type().IsRegular() || strings.HasPrefix(name, ".") || !s.matches(name) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				// 文件可能已被其他进程移走
+				continue
+			}
+			if mod, ok := failed[name]; ok && mod.Equal(info.ModTime()) {
+				seen[name] = mod
+				continue
+			}
+
+			ok := s.process(ctx, name)
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			switch {
+			case ok:
+				s.finish(name, s.opts.doneDir)
+			case s.opts.failedDir != "":
+				s.finish(name, s.opts.failedDir)
+			default:
+				seen[name] = info.ModTime()
+			}
+		}
+		failed = seen
+
+		if err := sleep(ctx, s.opts.pollInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// matches 判断文件名是否符合模式
+func (s *spoolSource) matches(name string) bool {
+	if s.opts.pattern == "" {
+		return true
+	}
+	ok, _ := filepath.Match(s.opts.pattern, name)
+	return ok
+}
+
+// process 路由一个文件的内容，返回是否全部处理成功
+func (s *spoolSource) process(ctx context.Context, name string) bool {
+	path := filepath.Join(s.dir, name)
+	f, err := os.Open(path)
+	if err != nil {
+		s.opts.report(err)
+		return false
+	}
+	defer f.Close()
+
+	if s.opts.wholeFile {
+		manager := s.router.BufferManager()
+		buf := manager.Acquire()
+		defer manager.Release(buf)
+		if _, err := io.Copy(buf, f); err != nil {
+			s.opts.report(err)
+			return false
+		}
+		if _, err := s.router.Route(messageContext(ctx, Position{Path: path}), buf); err != nil {
+			s.opts.report(err)
+			return false
+		}
+		return true
+	}
+
+	cr := &countingReader{r: f}
+	failed, err := routeFrames(ctx, s.router, &s.opts, path, bufio.NewReader(cr), func() int64 { return cr.n })
+	if err != io.EOF {
+		if ctx.Err() == nil {
+			s.opts.report(err)
+		}
+		return false
+	}
+	return failed == 0
+}
+
+// finish 把处理完的文件移动到dir，dir为空时删除文件
+func (s *spoolSource) finish(name, dir string) {
+	path := filepath.Join(s.dir, name)
+	var err error
+	if dir == "" {
+		err = os.Remove(path)
+	} else {
+		err = os.Rename(path, filepath.Join(dir, name))
+	}
+	if err != nil {
+		s.opts.report(err)
+	}
+}
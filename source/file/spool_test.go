@@ -0,0 +1,122 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+)
+
+// writeFile 写入测试文件
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitGone 等待文件被移走
+func waitGone(t *testing.T, path string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("%s was not moved", path)
+}
+
+func TestSpoolWholeFile(t *testing.T) {
+	dir, done := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(dir, "1.json"), `{"id":1}`)
+	writeFile(t, filepath.Join(dir, "2.json"), `{"id":2}`)
+	writeFile(t, filepath.Join(dir, "skip.txt"), "ignored")
+	writeFile(t, filepath.Join(dir, ".3.json"), "in progress")
+
+	manager := manage.NewBufferManager()
+	r, ch := newCollectingRouter(manager)
+	src := NewSpool(dir, r, WithPattern("*.json"), WithDoneDir(done), WithPollInterval(5*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	finished := make(chan error, 1)
+	go func() { finished <- src.Run(ctx) }()
+
+	msg := expect(t, ch, `{"id":1}`)
+	if msg.pos != (Position{Path: filepath.Join(dir, "1.json")}) {
+		t.Errorf("Unexpected position %+v", msg.pos)
+	}
+	expect(t, ch, `{"id":2}`)
+	waitGone(t, filepath.Join(dir, "2.json"))
+
+	// 写入方完成后重命名，新文件在下一次扫描时处理
+	if err := os.Rename(filepath.Join(dir, ".3.json"), filepath.Join(dir, "3.json")); err != nil {
+		t.Fatal(err)
+	}
+	expect(t, ch, "in progress")
+	waitGone(t, filepath.Join(dir, "3.json"))
+
+	cancel()
+	if err := <-finished; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	for _, name := range []string{"1.json", "2.json", "3.json"} {
+		if _, err := os.Stat(filepath.Join(done, name)); err != nil {
+			t.Errorf("%s should be moved to the done directory: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "skip.txt")); err != nil {
+		t.Errorf("Files not matching the pattern should be left alone: %v", err)
+	}
+	if stats := manager.Stats(); stats.Outstanding != 0 {
+		t.Errorf("Every buffer should be released, got %+v", stats)
+	}
+}
+
+func TestSpoolFramedFailures(t *testing.T) {
+	dir, failedDir := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(dir, "batch.log"), "ok\nbad\nok\n")
+	writeFile(t, filepath.Join(dir, "good.log"), "ok\n")
+
+	r := router.NewRouter()
+	var lines []string
+	r.Match("", func(ctx router_context.Context) error {
+		lines = append(lines, string(ctx.Buffer().Get()))
+		if string(ctx.Buffer().Get()) == "bad" {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var errs []error
+	src := NewSpool(dir, r,
+		WithFramer(frame.NewLineFramer(0)),
+		WithFailedDir(failedDir),
+		WithErrorHandler(func(err error) {
+			errs = append(errs, err)
+		}))
+	finished := make(chan error, 1)
+	go func() { finished <- src.Run(ctx) }()
+	waitGone(t, filepath.Join(dir, "good.log"))
+	cancel()
+	<-finished
+
+	if len(lines) != 4 || len(errs) != 1 {
+		t.Errorf("Every line should be routed and the failure reported, got %q, %v", lines, errs)
+	}
+	if _, err := os.Stat(filepath.Join(failedDir, "batch.log")); err != nil {
+		t.Errorf("Failed file should be moved to the failed directory: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(failedDir, "good.log")); err == nil {
+		t.Errorf("Successful file should be deleted, not moved")
+	}
+}
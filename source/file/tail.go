@@ -0,0 +1,159 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/aomirun/content-router/frame"
+	"github.com/aomirun/content-router/source"
+)
+
+// tailSource 是跟踪文件的消息来源
+type tailSource struct {
+	path   string
+	router source.Router
+	opts   options
+}
+
+// NewTail 创建跟踪path的消息来源，类似tail -F
+//   - 文件不存在时等待文件出现
+//   - 文件被轮转（重命名后重新创建）时读完旧文件再从头读取新文件
+//   - 文件被截断时从头读取
+//
+// 默认按行分帧，从文件当前末尾开始读取
+func NewTail(path string, r source.Router, opts ...Option) source.Source {
+	o := newOptions(opts)
+	if o.framer == nil {
+		o.framer = frame.NewLineFramer(0)
+	}
+	return &tailSource{path: path, router: r, opts: o}
+}
+
+// Run 持续读取并路由文件内容，直到ctx被取消或读取文件失败
+func (t *tailSource) Run(ctx context.Context) error {
+	f, err := t.open(ctx)
+	if err != nil {
+		return err
+	}
+	var offset int64
+	if !t.opts.fromStart {
+		if offset, err = f.Seek(0, io.SeekEnd); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	for {
+		fr := &followReader{ctx: ctx, file: f, path: t.path, offset: offset, interval: t.opts.pollInterval}
+		br := bufio.NewReader(fr)
+		_, err := routeFrames(ctx, t.router, &t.opts, t.path, br, func() int64 { return fr.offset })
+		f.Close()
+		if err != io.EOF || fr.next == nil {
+			if fr.next != nil {
+				fr.next.Close()
+			}
+			return err
+		}
+		// 文件已被轮转，从头读取新文件
+		f, offset = fr.next, 0
+	}
+}
+
+// open 打开文件，文件不存在时等待文件出现
+func (t *tailSource) open(ctx context.Context) (*os.File, error) {
+	for {
+		f, err := os.Open(t.path)
+		if !errors.Is(err, fs.ErrNotExist) {
+			return f, err
+		}
+		if err := sleep(ctx, t.opts.pollInterval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// followReader 是读到文件末尾时等待新内容的io.Reader
+// 发现文件被轮转后，读完旧文件的剩余内容再返回io.EOF，新文件保存在next中
+type followReader struct {
+	ctx      context.Context
+	file     *os.File
+	path     string
+	offset   int64
+	interval time.Duration
+	next     *os.File
+}
+
+// Read 读取文件内容，到达末尾时等待新内容
+func (f *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := f.file.Read(p)
+		f.offset += int64(n)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		if f.next != nil {
+			return 0, io.EOF
+		}
+
+		if err := f.check(); err != nil {
+			return 0, err
+		}
+		if f.next != nil {
+			// 再读一次旧文件，避免丢失轮转前最后写入的内容
+			continue
+		}
+		if err := sleep(f.ctx, f.interval); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// check 检查文件是否被轮转或截断
+func (f *followReader) check() error {
+	current, err := f.file.Stat()
+	if err != nil {
+		return err
+	}
+	latest, err := os.Stat(f.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// 旧文件已被移走但新文件尚未创建，继续等待
+		return nil
+	case err != nil:
+		return err
+	case !os.SameFile(current, latest):
+		next, err := os.Open(f.path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		f.next = next
+		return err
+	case current.Size() < f.offset:
+		// 文件被截断
+		if _, err := f.file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		f.offset = 0
+	}
+	return nil
+}
+
+// sleep 等待d，ctx被取消时提前返回ctx的错误
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,124 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+)
+
+// message 记录收到的消息
+type message struct {
+	data string
+	pos  Position
+	md   router_context.Metadata
+}
+
+// newCollectingRouter 创建把每条消息发送到通道的路由器
+func newCollectingRouter(manager manage.BufferManager) (router.Router, chan message) {
+	ch := make(chan message, 16)
+	r := router.NewRouter(router.WithBufferManager(manager))
+	r.Match("", func(ctx router_context.Context) error {
+		pos, _ := PositionFromContext(ctx)
+		ch <- message{string(ctx.Buffer().Get()), pos, ctx.Metadata()}
+		return nil
+	})
+	return r, ch
+}
+
+// expect 等待下一条消息并检查内容
+func expect(t *testing.T, ch chan message, data string) message {
+	t.Helper()
+	select {
+	case msg := <-ch:
+		if msg.data != data {
+			t.Fatalf("Expected %q, got %q", data, msg.data)
+		}
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for %q", data)
+		return message{}
+	}
+}
+
+// appendFile 向文件追加内容
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	appendFile(t, path, "a\r\nb\n")
+
+	manager := manage.NewBufferManager()
+	r, ch := newCollectingRouter(manager)
+	src := NewTail(path, r, WithFromStart(), WithPollInterval(5*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- src.Run(ctx) }()
+
+	first := expect(t, ch, "a")
+	if first.pos != (Position{Path: path, Offset: 0}) || first.md.Transport != "file" || first.md.Source != path {
+		t.Errorf("Unexpected first message %+v", first)
+	}
+	if second := expect(t, ch, "b"); second.pos.Offset != 3 {
+		t.Errorf("Expected offset 3, got %d", second.pos.Offset)
+	}
+
+	appendFile(t, path, "c\n")
+	expect(t, ch, "c")
+
+	// 轮转：旧文件末尾的不完整行在切换到新文件前路由
+	appendFile(t, path, "partial")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, "eeee\n")
+	expect(t, ch, "partial")
+	if msg := expect(t, ch, "eeee"); msg.pos.Offset != 0 {
+		t.Errorf("Rotated file should be read from the start, got offset %d", msg.pos.Offset)
+	}
+
+	// 截断后从头读取
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, "f\n")
+	expect(t, ch, "f")
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if stats := manager.Stats(); stats.Outstanding != 0 {
+		t.Errorf("Every buffer should be released, got %+v", stats)
+	}
+}
+
+func TestTailWaitsForFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "later.log")
+	r, ch := newCollectingRouter(manage.NewBufferManager())
+	src := NewTail(path, r, WithFromStart(), WithPollInterval(5*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go src.Run(ctx)
+
+	time.Sleep(20 * time.Millisecond)
+	appendFile(t, path, "hello\n")
+	expect(t, ch, "hello")
+}
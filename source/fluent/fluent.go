@@ -0,0 +1,339 @@
+// Package fluent 提供Fluentd Forward协议的消息来源
+// 现有的fluent-bit和fluentd代理可以直接把日志转发给路由器，
+// 每条记录转换为JSON对象作为缓冲区路由
+package fluent
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/source"
+	"github.com/aomirun/content-router/transport"
+)
+
+// DefaultAddr 是Forward协议的默认监听地址
+const DefaultAddr = ":24224"
+
+// DefaultMaxMessageSize 是单个字符串或二进制值的默认最大长度
+const DefaultMaxMessageSize = 16 * 1024 * 1024
+
+// Entry 定义记录的元数据，记录本身转换为JSON对象作为缓冲区路由
+type Entry struct {
+	// Tag 记录的标签
+	Tag string
+	// Time 记录的事件时间
+	Time time.Time
+}
+
+// entryKey 是记录元数据在标准context中的键
+type entryKey struct{}
+
+// EntryFromContext 获取记录的标签和事件时间，处理器可以直接传入路由上下文
+func EntryFromContext(ctx context.Context) (Entry, bool) {
+	e, ok := ctx.Value(entryKey{}).(Entry)
+	return e, ok
+}
+
+// Option 定义Forward协议消息来源的配置选项
+type Option func(*options)
+
+// options 保存配置选项
+type options struct {
+	addr      string
+	listeners []net.Listener
+	maxSize   int
+	onError   source.ErrorHandler
+}
+
+// WithAddr 设置监听地址，默认DefaultAddr
+func WithAddr(addr string) Option {
+	return func(o *options) {
+		o.addr = addr
+	}
+}
+
+// WithListener 在已经打开的l上接受连接，设置后不再监听WithAddr的地址
+func WithListener(l net.Listener) Option {
+	return func(o *options) {
+		o.listeners = append(o.listeners, l)
+	}
+}
+
+// WithMaxMessageSize 设置单个字符串或二进制值的最大长度，默认DefaultMaxMessageSize
+func WithMaxMessageSize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.maxSize = n
+		}
+	}
+}
+
+// WithErrorHandler 设置记录处理失败时的回调
+func WithErrorHandler(fn source.ErrorHandler) Option {
+	return func(o *options) {
+		o.onError = fn
+	}
+}
+
+// newOptions 应用配置选项
+func newOptions(opts []Option) options {
+	o := options{
+		addr:    DefaultAddr,
+		maxSize: DefaultMaxMessageSize,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// forwardSource 是Forward协议消息来源的实现
+type forwardSource struct {
+	handler *connHandler
+	opts    options
+}
+
+// New 创建接收Forward协议的消息来源
+// 每条记录的上下文通过EntryFromContext提供标签和事件时间，
+// 通过ctx.Metadata()提供传输层名称"fluent"、对端地址和连接标识
+func New(r source.Router, opts ...Option) source.Source {
+	o := newOptions(opts)
+	return &forwardSource{handler: &connHandler{router: r, opts: o}, opts: o}
+}
+
+// NewConnServer 返回处理Forward协议连接的router.ConnServer
+// 可以交给transport.NewTCPServer以使用TLS等自定义监听方式，ServeConn会忽略传入的framer
+func NewConnServer(r source.Router, opts ...Option) router.ConnServer {
+	return &connHandler{router: r, opts: newOptions(opts)}
+}
+
+// Run 接受连接并路由收到的记录，直到ctx被取消或监听器失败
+func (s *forwardSource) Run(ctx context.Context) error {
+	listeners := s.opts.listeners
+	if len(listeners) == 0 {
+		l, err := net.Listen("tcp", s.opts.addr)
+		if err != nil {
+			return err
+		}
+		listeners = []net.Listener{l}
+	}
+
+	srv := transport.NewTCPServer(s.handler, nil, transport.WithBaseContext(ctx))
+	errc := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func() { errc <- srv.Serve(l) }()
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+	case err = <-errc:
+	}
+	srv.Shutdown(context.WithoutCancel(ctx))
+	return err
+}
+
+// connHandler 处理Forward协议连接
+type connHandler struct {
+	router source.Router
+	opts   options
+}
+
+// ServeConn 读取连接上的Forward协议消息并逐条路由记录
+// 消息带有chunk选项时，所有记录处理成功后回复ack；处理失败时不回复，由客户端重新发送
+func (h *connHandler) ServeConn(ctx context.Context, conn net.Conn, _ frame.Framer) error {
+	stop := context.AfterFunc(ctx, func() {
+		conn.SetDeadline(time.Unix(1, 0))
+	})
+	defer stop()
+
+	md, _ := router_context.MetadataFromContext(ctx)
+	md.Transport = "fluent"
+	if md.Source == "" && conn.RemoteAddr() != nil {
+		md.Source = conn.RemoteAddr().String()
+	}
+	dec := &decoder{r: bufio.NewReader(conn), maxSize: h.opts.maxSize}
+
+	for {
+		v, err := dec.decode()
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		md.ReceivedAt = time.Now()
+		chunk, ok, err := h.handleMessage(router_context.WithMetadata(ctx, md), v)
+		if err != nil {
+			return err
+		}
+		if ok && chunk != "" {
+			ack := appendString(append(make([]byte, 0, 8+len(chunk)), 0x81), "ack")
+			if _, err := conn.Write(appendString(ack, chunk)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// handleMessage 路由一条Forward协议消息中的所有记录
+// 返回: chunk选项、是否所有记录都处理成功，以及消息格式错误
+func (h *connHandler) handleMessage(ctx context.Context, v interface{}) (string, bool, error) {
+	msg, ok := v.([]interface{})
+	if !ok || len(msg) < 2 {
+		return "", false, ErrMalformed
+	}
+	tag, ok := msg[0].(string)
+	if !ok {
+		return "", false, ErrMalformed
+	}
+
+	var option map[string]interface{}
+	optionAt := func(i int) {
+		if len(msg) > i {
+			option, _ = msg[i].(map[string]interface{})
+		}
+	}
+
+	allOK := true
+	switch entries := msg[1].(type) {
+	case []interface{}:
+		// Forward模式：[tag, [[time, record], ...], option]
+		optionAt(2)
+		for _, e := range entries {
+			if !h.routeEntry(ctx, tag, e) {
+				allOK = false
+			}
+		}
+	case string, []byte:
+		// PackedForward模式：[tag, 连续的msgpack条目, option]
+		optionAt(2)
+		var data []byte
+		if s, ok := entries.(string); ok {
+			data = []byte(s)
+		} else {
+			data = entries.([]byte)
+		}
+		var r io.Reader = bytes.NewReader(data)
+		if option["compressed"] == "gzip" {
+			zr, err := gzip.NewReader(r)
+			if err != nil {
+				return "", false, err
+			}
+			r = zr
+		}
+		dec := &decoder{r: bufio.NewReader(r), maxSize: h.opts.maxSize}
+		for {
+			e, err := dec.decode()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", false, err
+			}
+			if !h.routeEntry(ctx, tag, e) {
+				allOK = false
+			}
+		}
+	default:
+		// Message模式：[tag, time, record, option]
+		if len(msg) < 3 {
+			return "", false, ErrMalformed
+		}
+		optionAt(3)
+		if !h.routeEntry(ctx, tag, msg[1:3]) {
+			allOK = false
+		}
+	}
+
+	chunk, _ := option["chunk"].(string)
+	return chunk, allOK, nil
+}
+
+// routeEntry 把[time, record]条目转换为JSON并路由，返回是否处理成功
+func (h *connHandler) routeEntry(ctx context.Context, tag string, v interface{}) bool {
+	pair, ok := v.([]interface{})
+	if !ok || len(pair) < 2 {
+		h.report(ErrMalformed)
+		return false
+	}
+	ts, ok := eventTime(pair[0])
+	record, isMap := pair[1].(map[string]interface{})
+	if !ok || !isMap {
+		h.report(ErrMalformed)
+		return false
+	}
+
+	data, err := json.Marshal(jsonValue(record))
+	if err != nil {
+		h.report(err)
+		return false
+	}
+	manager := h.router.BufferManager()
+	buf := manager.Acquire()
+	defer manager.Release(buf)
+	buf.Write(data)
+
+	ctx = context.WithValue(ctx, entryKey{}, Entry{Tag: tag, Time: ts})
+	if _, err := h.router.Route(ctx, buf); err != nil {
+		h.report(err)
+		return false
+	}
+	return true
+}
+
+// report 调用错误回调
+func (h *connHandler) report(err error) {
+	if h.opts.onError != nil {
+		h.opts.onError(err)
+	}
+}
+
+// eventTime 把整数秒或EventTime转换为时间
+func eventTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case int64:
+		return time.Unix(t, 0), true
+	case uint64:
+		return time.Unix(int64(t), 0), true
+	case float64:
+		sec := int64(t)
+		return time.Unix(sec, int64((t-float64(sec))*1e9)), true
+	}
+	return time.Time{}, false
+}
+
+// jsonValue 把解码的值转换为适合JSON编码的形式，二进制值按字符串处理
+func jsonValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case []byte:
+		return string(t)
+	case ext:
+		return t.data
+	case []interface{}:
+		for i := range t {
+			t[i] = jsonValue(t[i])
+		}
+	case map[string]interface{}:
+		for k := range t {
+			t[k] = jsonValue(t[k])
+		}
+	}
+	return v
+}
@@ -0,0 +1,140 @@
+package fluent
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+)
+
+// received 记录收到的记录
+type received struct {
+	entry Entry
+	data  string
+	md    router_context.Metadata
+}
+
+// readAck 读取服务端回复的ack
+func readAck(t *testing.T, conn net.Conn) string {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	d := &decoder{r: bufio.NewReader(conn), maxSize: 1024}
+	v, err := d.decode()
+	if err != nil {
+		t.Fatalf("read ack: %v", err)
+	}
+	ack, _ := v.(map[string]interface{})["ack"].(string)
+	return ack
+}
+
+func TestForward(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch := make(chan received, 16)
+	manager := manage.NewBufferManager()
+	r := router.NewRouter(router.WithBufferManager(manager))
+	r.Match("", func(ctx router_context.Context) error {
+		e, _ := EntryFromContext(ctx)
+		ch <- received{e, string(ctx.Buffer().Get()), ctx.Metadata()}
+		if e.Tag == "bad" {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+
+	var errs []error
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	src := New(r, WithListener(l), WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	go func() { done <- src.Run(ctx) }()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ts := time.Unix(1700000000, 5)
+	next := func() received {
+		t.Helper()
+		select {
+		case rec := <-ch:
+			return rec
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for record")
+			return received{}
+		}
+	}
+
+	// Message模式
+	conn.Write(pack([]interface{}{"app.log", 1700000000, map[string]interface{}{"msg": "hello", "raw": []byte("b")}}))
+	rec := next()
+	if rec.entry.Tag != "app.log" || !rec.entry.Time.Equal(time.Unix(1700000000, 0)) || rec.data != `{"msg":"hello","raw":"b"}` {
+		t.Errorf("Unexpected message mode record %+v", rec)
+	}
+	if rec.md.Transport != "fluent" || rec.md.Source == "" || rec.md.ConnectionID == "" {
+		t.Errorf("Unexpected metadata %+v", rec.md)
+	}
+
+	// Forward模式，带chunk选项时回复ack
+	conn.Write(pack([]interface{}{"app.log",
+		[]interface{}{
+			[]interface{}{ts, map[string]interface{}{"n": 1}},
+			[]interface{}{ts, map[string]interface{}{"n": 2}},
+		},
+		map[string]interface{}{"chunk": "c1"},
+	}))
+	if rec := next(); rec.data != `{"n":1}` || !rec.entry.Time.Equal(ts) {
+		t.Errorf("Unexpected forward mode record %+v", rec)
+	}
+	next()
+	if ack := readAck(t, conn); ack != "c1" {
+		t.Errorf("Expected ack c1, got %q", ack)
+	}
+
+	// 压缩的PackedForward模式
+	var entries bytes.Buffer
+	zw := gzip.NewWriter(&entries)
+	zw.Write(pack([]interface{}{ts, map[string]interface{}{"n": 3}}))
+	zw.Write(pack([]interface{}{ts, map[string]interface{}{"n": 4}}))
+	zw.Close()
+	conn.Write(pack([]interface{}{"packed", entries.Bytes(), map[string]interface{}{"chunk": "c2", "compressed": "gzip"}}))
+	if rec := next(); rec.data != `{"n":3}` || rec.entry.Tag != "packed" {
+		t.Errorf("Unexpected packed record %+v", rec)
+	}
+	next()
+	if ack := readAck(t, conn); ack != "c2" {
+		t.Errorf("Expected ack c2, got %q", ack)
+	}
+
+	// 处理失败时不回复ack，连接保持可用
+	conn.Write(pack([]interface{}{"bad", 1700000000, map[string]interface{}{}, map[string]interface{}{"chunk": "c3"}}))
+	next()
+	conn.Write(pack([]interface{}{"app.log", 1700000000, map[string]interface{}{}, map[string]interface{}{"chunk": "c4"}}))
+	next()
+	if ack := readAck(t, conn); ack != "c4" {
+		t.Errorf("Failed chunk should not be acked, got ack %q", ack)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected one reported error, got %v", errs)
+	}
+	if stats := manager.Stats(); stats.Outstanding != 0 {
+		t.Errorf("Every buffer should be released, got %+v", stats)
+	}
+}
@@ -0,0 +1,247 @@
+package fluent
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// ErrMalformed 表示无法解码的msgpack数据
+var ErrMalformed = errors.New("fluent: malformed msgpack data")
+
+// maxDepth 是嵌套数组和映射的最大深度
+const maxDepth = 64
+
+// ext 是无法识别的msgpack扩展类型
+type ext struct {
+	typ  int8
+	data []byte
+}
+
+// decoder 从流中解码msgpack值
+// 字符串解码为string，二进制解码为[]byte，映射解码为map[string]interface{}，
+// EventTime扩展类型解码为time.Time
+type decoder struct {
+	r       *bufio.Reader
+	maxSize int
+}
+
+// decode 解码下一个值，流在值的边界结束时返回io.EOF
+func (d *decoder) decode() (interface{}, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	v, err := d.value(c, 0)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+// value 解码以c开头的值
+func (d *decoder) value(c byte, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, ErrMalformed
+	}
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.mapValue(int(c&0x0f), depth)
+	case c&0xf0 == 0x90:
+		return d.array(int(c&0x0f), depth)
+	case c&0xe0 == 0xa0:
+		return d.str(int(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.length(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		return d.bytes(n)
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.length(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(n)
+	case 0xca:
+		u, err := d.uint(4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := d.uint(8)
+		return math.Float64frombits(u), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if u > math.MaxInt64 {
+			return u, nil
+		}
+		return int64(u), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		u, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		// 符号扩展
+		shift := 64 - 8*size
+		return int64(u<<shift) >> shift, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.length(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return d.str(n)
+	case 0xdc, 0xdd:
+		n, err := d.length(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(n, depth)
+	case 0xde, 0xdf:
+		n, err := d.length(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.mapValue(n, depth)
+	}
+	return nil, ErrMalformed
+}
+
+// uint 读取size字节的大端序无符号整数
+func (d *decoder) uint(size int) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(d.r, b[8-size:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+// length 读取长度字段并检查是否超过限制
+func (d *decoder) length(size int) (int, error) {
+	n, err := d.uint(size)
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(d.maxSize) {
+		return 0, fmt.Errorf("fluent: length %d exceeds limit %d", n, d.maxSize)
+	}
+	return int(n), nil
+}
+
+// bytes 读取n字节
+func (d *decoder) bytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := io.ReadFull(d.r, b)
+	return b, err
+}
+
+// str 读取n字节的字符串
+func (d *decoder) str(n int) (string, error) {
+	b, err := d.bytes(n)
+	return string(b), err
+}
+
+// ext 读取n字节数据的扩展类型，EventTime转换为time.Time
+func (d *decoder) ext(n int) (interface{}, error) {
+	typ, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.bytes(n)
+	if err != nil {
+		return nil, err
+	}
+	if typ == 0 && n == 8 {
+		sec := binary.BigEndian.Uint32(data)
+		nsec := binary.BigEndian.Uint32(data[4:])
+		return time.Unix(int64(sec), int64(nsec)), nil
+	}
+	return ext{typ: int8(typ), data: data}, nil
+}
+
+// array 读取n个元素的数组
+func (d *decoder) array(n int, depth int) ([]interface{}, error) {
+	// 元素数量来自不可信的输入，不按其预分配
+	arr := make([]interface{}, 0, min(n, 16))
+	for i := 0; i < n; i++ {
+		c, err := d.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		v, err := d.value(c, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, v)
+	}
+	return arr, nil
+}
+
+// mapValue 读取n个键值对的映射，非字符串的键格式化为字符串
+func (d *decoder) mapValue(n int, depth int) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, min(n, 16))
+	for i := 0; i < n; i++ {
+		c, err := d.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		k, err := d.value(c, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if c, err = d.r.ReadByte(); err != nil {
+			return nil, err
+		}
+		v, err := d.value(c, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		switch k := k.(type) {
+		case string:
+			m[k] = v
+		case []byte:
+			m[string(k)] = v
+		default:
+			m[fmt.Sprint(k)] = v
+		}
+	}
+	return m, nil
+}
+
+// appendString 把字符串编码为msgpack追加到dst
+func appendString(dst []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		dst = append(dst, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		dst = append(dst, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		dst = append(dst, 0xda)
+		dst = binary.BigEndian.AppendUint16(dst, uint16(n))
+	default:
+		dst = append(dst, 0xdb)
+		dst = binary.BigEndian.AppendUint32(dst, uint32(n))
+	}
+	return append(dst, s...)
+}
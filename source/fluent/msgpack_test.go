@@ -0,0 +1,117 @@
+package fluent
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// pack 把测试值编码为msgpack
+func pack(v interface{}) []byte {
+	var b []byte
+	switch t := v.(type) {
+	case nil:
+		b = append(b, 0xc0)
+	case bool:
+		if t {
+			b = append(b, 0xc3)
+		} else {
+			b = append(b, 0xc2)
+		}
+	case int:
+		b = append(b, 0xd3)
+		b = binary.BigEndian.AppendUint64(b, uint64(t))
+	case string:
+		b = appendString(b, t)
+	case []byte:
+		b = append(b, 0xc6)
+		b = binary.BigEndian.AppendUint32(b, uint32(len(t)))
+		b = append(b, t...)
+	case time.Time:
+		b = append(b, 0xd7, 0x00)
+		b = binary.BigEndian.AppendUint32(b, uint32(t.Unix()))
+		b = binary.BigEndian.AppendUint32(b, uint32(t.Nanosecond()))
+	case []interface{}:
+		b = append(b, 0xdd)
+		b = binary.BigEndian.AppendUint32(b, uint32(len(t)))
+		for _, e := range t {
+			b = append(b, pack(e)...)
+		}
+	case map[string]interface{}:
+		b = append(b, 0xdf)
+		b = binary.BigEndian.AppendUint32(b, uint32(len(t)))
+		for k, e := range t {
+			b = append(b, pack(k)...)
+			b = append(b, pack(e)...)
+		}
+	default:
+		panic("pack: unsupported type")
+	}
+	return b
+}
+
+// decodeAll 解码data中的第一个值
+func decodeAll(data []byte) (interface{}, error) {
+	d := &decoder{r: bufio.NewReader(bytes.NewReader(data)), maxSize: 1024}
+	return d.decode()
+}
+
+func TestDecode(t *testing.T) {
+	ts := time.Unix(1700000000, 123)
+	tests := []struct {
+		data []byte
+		want interface{}
+	}{
+		{[]byte{0x05}, int64(5)},
+		{[]byte{0xff}, int64(-1)},
+		{[]byte{0xcc, 0xc8}, int64(200)},
+		{[]byte{0xd0, 0x80}, int64(-128)},
+		{[]byte{0xd1, 0xff, 0x38}, int64(-200)},
+		{[]byte{0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, uint64(1<<64 - 1)},
+		{[]byte{0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}, 1.5},
+		{[]byte{0xa3, 'a', 'b', 'c'}, "abc"},
+		{[]byte{0xc4, 0x02, 1, 2}, []byte{1, 2}},
+		{[]byte{0x92, 0x01, 0xc0}, []interface{}{int64(1), nil}},
+		{[]byte{0x81, 0xa1, 'k', 0xc3}, map[string]interface{}{"k": true}},
+		{[]byte{0x81, 0x01, 0xc2}, map[string]interface{}{"1": false}},
+		{pack(ts), ts},
+		{[]byte{0xd4, 0x05, 0x07}, ext{typ: 5, data: []byte{7}}},
+	}
+	for _, tt := range tests {
+		got, err := decodeAll(tt.data)
+		if err != nil {
+			t.Errorf("decode(% x): %v", tt.data, err)
+			continue
+		}
+		if gt, ok := got.(time.Time); ok {
+			if !gt.Equal(tt.want.(time.Time)) {
+				t.Errorf("decode(% x) = %v, want %v", tt.data, got, tt.want)
+			}
+		} else if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("decode(% x) = %#v, want %#v", tt.data, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	if _, err := decodeAll(nil); err != io.EOF {
+		t.Errorf("Empty input should return io.EOF, got %v", err)
+	}
+	if _, err := decodeAll([]byte{0x92, 0x01}); err != io.ErrUnexpectedEOF {
+		t.Errorf("Truncated input should return io.ErrUnexpectedEOF, got %v", err)
+	}
+	if _, err := decodeAll([]byte{0xc1}); err != ErrMalformed {
+		t.Errorf("Reserved byte should be rejected, got %v", err)
+	}
+	if _, err := decodeAll([]byte{0xdb, 0xff, 0xff, 0xff, 0xff}); err == nil {
+		t.Errorf("Oversized string should be rejected")
+	}
+	deep := bytes.Repeat([]byte{0x91}, maxDepth+2)
+	if _, err := decodeAll(append(deep, 0x01)); err != ErrMalformed {
+		t.Errorf("Deep nesting should be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+// Package kafka 提供Kafka消费者消息来源
+// 从消费者组拉取记录并逐条路由，按提交模式在处理后提交偏移量
+//
+// 本包不依赖具体的Kafka客户端库，使用方通过实现Consumer接口接入所选的客户端
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/source"
+)
+
+// Header 定义记录头
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Record 定义一条Kafka记录
+type Record struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   []Header
+	Timestamp time.Time
+}
+
+// Header 返回第一个名为key的记录头的值
+func (r Record) Header(key string) ([]byte, bool) {
+	for _, h := range r.Headers {
+		if h.Key == key {
+			return h.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Consumer 定义本包需要的消费者组客户端功能
+// 分区分配和再均衡由客户端负责
+type Consumer interface {
+	// Fetch 阻塞直到获取到一批记录或ctx被取消
+	Fetch(ctx context.Context) ([]Record, error)
+
+	// Commit 提交记录的偏移量，records按分区有序
+	Commit(ctx context.Context, records ...Record) error
+}
+
+// LagReporter 定义报告消费延迟的客户端功能，通常由Consumer的实现一并实现
+type LagReporter interface {
+	// Lag 返回消费者组在所有已分配分区上落后的记录总数
+	Lag(ctx context.Context) (int64, error)
+}
+
+// LagCheck 创建检查消费延迟的健康检查函数，延迟超过maxLag或无法获取时不健康
+// 通常注册为就绪检查:
+//
+//	r.AddHealthCheck(router.HealthCheck{Name: "kafka-lag", Kind: router.Readiness, Check: kafka.LagCheck(consumer, 10000)})
+func LagCheck(lag LagReporter, maxLag int64) router.HealthCheckFunc {
+	return func(ctx context.Context) error {
+		n, err := lag.Lag(ctx)
+		if err != nil {
+			return fmt.Errorf("kafka: lag: %w", err)
+		}
+		if n > maxLag {
+			return fmt.Errorf("kafka: consumer lagging by %d records (max %d)", n, maxLag)
+		}
+		return nil
+	}
+}
+
+// CommitMode 定义偏移量的提交时机
+type CommitMode int
+
+const (
+	// CommitAfterSuccess 在处理器成功后提交（至少一次）
+	// 处理失败时不提交该记录，Run返回错误，重启或再均衡后该记录会被重新投递
+	CommitAfterSuccess CommitMode = iota
+	// CommitAfterHandle 处理后无论成功与否都提交，失败通过错误回调报告
+	CommitAfterHandle
+	// CommitBeforeHandle 在路由前提交（至多一次）
+	CommitBeforeHandle
+)
+
+// recordKey 是记录元数据在标准context中的键
+type recordKey struct{}
+
+// RecordFromContext 获取正在处理的记录，处理器可以直接传入路由上下文
+// 记录的Value只在路由期间有效，处理器应该读取ctx.Buffer()
+func RecordFromContext(ctx context.Context) (Record, bool) {
+	rec, ok := ctx.Value(recordKey{}).(Record)
+	return rec, ok
+}
+
+// Option 定义Kafka消息来源的配置选项
+type Option func(*kafkaSource)
+
+// WithCommitMode 设置偏移量的提交时机，默认CommitAfterSuccess
+func WithCommitMode(mode CommitMode) Option {
+	return func(s *kafkaSource) {
+		s.mode = mode
+	}
+}
+
+// WithErrorHandler 设置记录处理失败时的回调
+func WithErrorHandler(fn source.ErrorHandler) Option {
+	return func(s *kafkaSource) {
+		s.onError = fn
+	}
+}
+
+// kafkaSource 是Kafka消息来源的实现
+type kafkaSource struct {
+	consumer Consumer
+	router   source.Router
+	mode     CommitMode
+	onError  source.ErrorHandler
+}
+
+// New 创建Kafka消息来源
+// 每条记录的上下文通过RecordFromContext提供键、记录头、分区和偏移量，
+// 通过ctx.Metadata()提供传输层名称"kafka"、主题和接收时间
+func New(consumer Consumer, r source.Router, opts ...Option) source.Source {
+	s := &kafkaSource{
+		consumer: consumer,
+		router:   r,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run 持续拉取并路由记录，直到ctx被取消或拉取、提交失败
+// CommitAfterSuccess模式下处理失败也会使Run返回
+func (s *kafkaSource) Run(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		records, err := s.consumer.Fetch(ctx)
+		if err != nil {
+			return err
+		}
+		if err := s.handleBatch(ctx, records); err != nil {
+			return err
+		}
+	}
+}
+
+// handleBatch 路由一批记录并按提交模式提交
+func (s *kafkaSource) handleBatch(ctx context.Context, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if s.mode == CommitBeforeHandle {
+		if err := s.consumer.Commit(ctx, records...); err != nil {
+			return err
+		}
+	}
+
+	done := len(records)
+	var failure error
+	for i, rec := range records {
+		if err := s.route(ctx, rec); err != nil {
+			if s.onError != nil {
+				s.onError(err)
+			}
+			if s.mode == CommitAfterSuccess {
+				done, failure = i, err
+				break
+			}
+		}
+	}
+
+	if s.mode != CommitBeforeHandle && done > 0 {
+		// 处理中途失败时只提交之前成功的记录，取消后仍然提交已处理的记录
+		if err := s.consumer.Commit(context.WithoutCancel(ctx), records[:done]...); err != nil {
+			return err
+		}
+	}
+	return failure
+}
+
+// route 路由一条记录
+func (s *kafkaSource) route(ctx context.Context, rec Record) error {
+	ctx = context.WithValue(ctx, recordKey{}, rec)
+	ctx = router_context.WithMetadata(ctx, router_context.Metadata{
+		Transport:  "kafka",
+		Source:     rec.Topic,
+		ReceivedAt: time.Now(),
+	})
+
+	manager := s.router.BufferManager()
+	buf := manager.Acquire()
+	defer manager.Release(buf)
+	buf.Write(rec.Value)
+	_, err := s.router.Route(ctx, buf)
+	return err
+}
@@ -0,0 +1,183 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/router/routertest"
+)
+
+// errDrained 表示预设的批次已经用完
+var errDrained = errors.New("drained")
+
+// fakeConsumer 依次返回预设的批次，批次用完后返回errDrained
+type fakeConsumer struct {
+	batches   [][]Record
+	committed []int64
+}
+
+func (c *fakeConsumer) Fetch(ctx context.Context) ([]Record, error) {
+	if len(c.batches) == 0 {
+		return nil, errDrained
+	}
+	batch := c.batches[0]
+	c.batches = c.batches[1:]
+	return batch, nil
+}
+
+func (c *fakeConsumer) Commit(ctx context.Context, records ...Record) error {
+	for _, rec := range records {
+		c.committed = append(c.committed, rec.Offset)
+	}
+	return nil
+}
+
+// record 创建测试记录
+func record(offset int64, value string) Record {
+	return Record{
+		Topic:     "orders",
+		Partition: 2,
+		Offset:    offset,
+		Key:       []byte("k"),
+		Value:     []byte(value),
+		Headers:   []Header{{Key: "type", Value: []byte("order")}},
+	}
+}
+
+// newTestRouter 创建记录所有消息并拒绝"bad"消息的路由器
+func newTestRouter(manager manage.BufferManager, seen *[]Record) router.Router {
+	return routertest.NewSourceRouter(manager, func(ctx router_context.Context) error {
+		rec, _ := RecordFromContext(ctx)
+		*seen = append(*seen, rec)
+		return nil
+	})
+}
+
+func TestKafkaSourceAtLeastOnce(t *testing.T) {
+	consumer := &fakeConsumer{batches: [][]Record{
+		{record(1, "a"), record(2, "b")},
+		{record(3, "c"), record(4, "bad"), record(5, "d")},
+	}}
+	manager := manage.NewBufferManager()
+	var seen []Record
+	var errs []error
+	src := New(consumer, newTestRouter(manager, &seen),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }))
+
+	err := src.Run(context.Background())
+	if err == nil || err.Error() != "rejected" {
+		t.Fatalf("Expected handler error, got %v", err)
+	}
+	if want := []int64{1, 2, 3}; !equalOffsets(consumer.committed, want) {
+		t.Errorf("Expected committed offsets %v, got %v", want, consumer.committed)
+	}
+	if len(seen) != 4 {
+		t.Fatalf("Processing should stop at the failed record, saw %d", len(seen))
+	}
+	if v, ok := seen[0].Header("type"); !ok || string(v) != "order" || seen[0].Partition != 2 || string(seen[0].Key) != "k" {
+		t.Errorf("Unexpected record metadata %+v", seen[0])
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected one reported error, got %v", errs)
+	}
+	if stats := manager.Stats(); stats.Outstanding != 0 {
+		t.Errorf("Every buffer should be released, got %+v", stats)
+	}
+}
+
+func TestKafkaSourceCommitModes(t *testing.T) {
+	for _, mode := range []CommitMode{CommitAfterHandle, CommitBeforeHandle} {
+		consumer := &fakeConsumer{batches: [][]Record{
+			{record(1, "a"), record(2, "bad"), record(3, "b")},
+		}}
+		var seen []Record
+		var errs []error
+		src := New(consumer, newTestRouter(manage.NewBufferManager(), &seen),
+			WithCommitMode(mode),
+			WithErrorHandler(func(err error) { errs = append(errs, err) }))
+
+		if err := src.Run(context.Background()); err != errDrained {
+			t.Errorf("Mode %d: failures should not stop Run, got %v", mode, err)
+		}
+		if want := []int64{1, 2, 3}; !equalOffsets(consumer.committed, want) {
+			t.Errorf("Mode %d: expected committed offsets %v, got %v", mode, want, consumer.committed)
+		}
+		if len(seen) != 3 || len(errs) != 1 {
+			t.Errorf("Mode %d: failures should be reported, saw %d, errs %v", mode, len(seen), errs)
+		}
+	}
+}
+
+func TestKafkaSourceMetadata(t *testing.T) {
+	consumer := &fakeConsumer{batches: [][]Record{{record(1, "a")}}}
+	r := router.NewRouter()
+	var md router_context.Metadata
+	r.Match("", func(ctx router_context.Context) error {
+		md = ctx.Metadata()
+		return nil
+	})
+
+	New(consumer, r).Run(context.Background())
+	if md.Transport != "kafka" || md.Source != "orders" || md.ReceivedAt.IsZero() {
+		t.Errorf("Unexpected metadata %+v", md)
+	}
+}
+
+func TestKafkaSourceCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	consumer := &fakeConsumer{batches: [][]Record{{record(1, "a")}}}
+	if err := New(consumer, router.NewRouter()).Run(ctx); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(consumer.committed) != 0 {
+		t.Errorf("Nothing should be fetched after cancel, got %v", consumer.committed)
+	}
+}
+
+// equalOffsets 比较偏移量列表
+func equalOffsets(got, want []int64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// lagFunc 以函数实现LagReporter
+type lagFunc func(ctx context.Context) (int64, error)
+
+func (f lagFunc) Lag(ctx context.Context) (int64, error) { return f(ctx) }
+
+func TestLagCheck(t *testing.T) {
+	var lag int64
+	var lagErr error
+	check := LagCheck(lagFunc(func(context.Context) (int64, error) { return lag, lagErr }), 100)
+
+	lag = 100
+	if err := check(context.Background()); err != nil {
+		t.Errorf("lag at limit: %v", err)
+	}
+	lag = 101
+	if err := check(context.Background()); err == nil {
+		t.Error("lag over limit should be unhealthy")
+	}
+	lag, lagErr = 0, errors.New("broker unavailable")
+	if err := check(context.Background()); !errors.Is(err, lagErr) {
+		t.Errorf("err = %v, want wrapped %v", err, lagErr)
+	}
+
+	r := router.NewRouter()
+	r.AddHealthCheck(router.HealthCheck{Name: "kafka-lag", Kind: router.Readiness, Check: check})
+	if report := r.Health(context.Background()); report.Ready || !report.Live {
+		t.Errorf("report = %+v", report)
+	}
+}
@@ -0,0 +1,229 @@
+// Package mqtt 提供MQTT消息来源
+// 订阅主题并把每条PUBLISH消息的负载作为缓冲区路由，处理器的响应可以发布到响应主题
+//
+// 本包不依赖具体的MQTT客户端库，使用方通过实现Client接口接入所选的客户端
+package mqtt
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/source"
+)
+
+// Message 定义收到的MQTT消息
+type Message interface {
+	// Topic 消息的主题
+	Topic() string
+	// Payload 消息负载
+	Payload() []byte
+	// Qos 消息的服务质量等级
+	Qos() byte
+	// Retained 是否为保留消息
+	Retained() bool
+	// MessageID 消息标识
+	MessageID() uint16
+}
+
+// Client 定义本包需要的MQTT客户端功能
+type Client interface {
+	// Subscribe 订阅主题过滤器，收到消息时调用handler
+	Subscribe(ctx context.Context, filter string, qos byte, handler func(Message)) error
+
+	// Unsubscribe 取消订阅
+	Unsubscribe(ctx context.Context, filters ...string) error
+
+	// Publish 发布消息
+	Publish(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error
+}
+
+// Router 定义MQTT消息来源需要的路由器功能
+type Router interface {
+	router.RouteResponder
+	router.BufferManagerAccessor
+}
+
+// Info 定义消息的MQTT元数据
+type Info struct {
+	Topic     string
+	Qos       byte
+	Retained  bool
+	MessageID uint16
+}
+
+// infoKey 是消息元数据在标准context中的键
+type infoKey struct{}
+
+// InfoFromContext 获取消息的MQTT元数据，处理器可以直接传入路由上下文
+func InfoFromContext(ctx context.Context) (Info, bool) {
+	info, ok := ctx.Value(infoKey{}).(Info)
+	return info, ok
+}
+
+// Option 定义MQTT消息来源的配置选项
+type Option func(*mqttSource)
+
+// WithQoS 设置订阅的服务质量等级，默认0
+func WithQoS(qos byte) Option {
+	return func(s *mqttSource) {
+		s.qos = qos
+	}
+}
+
+// WithResponseTopic 设置响应主题
+// fn根据消息主题返回响应主题，返回空字符串时不发布响应；
+// 处理器写入ctx.Response()的内容以订阅的服务质量等级发布到响应主题
+func WithResponseTopic(fn func(topic string) string) Option {
+	return func(s *mqttSource) {
+		s.responseTopic = fn
+	}
+}
+
+// WithErrorHandler 设置消息处理失败时的回调
+func WithErrorHandler(fn source.ErrorHandler) Option {
+	return func(s *mqttSource) {
+		s.onError = fn
+	}
+}
+
+// mqttSource 是MQTT消息来源的实现
+type mqttSource struct {
+	client        Client
+	router        Router
+	filters       []string
+	qos           byte
+	responseTopic func(topic string) string
+	onError       source.ErrorHandler
+}
+
+// New 创建订阅filters的MQTT消息来源
+// 每条消息的上下文通过InfoFromContext提供主题等元数据，
+// 通过ctx.Metadata()提供传输层名称"mqtt"、主题和接收时间
+func New(client Client, r Router, filters []string, opts ...Option) source.Source {
+	s := &mqttSource{
+		client:  client,
+		router:  r,
+		filters: filters,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run 订阅主题并路由收到的消息，ctx被取消时取消订阅
+func (s *mqttSource) Run(ctx context.Context) error {
+	subscribed := make([]string, 0, len(s.filters))
+	defer func() {
+		if len(subscribed) > 0 {
+			s.client.Unsubscribe(context.WithoutCancel(ctx), subscribed...)
+		}
+	}()
+
+	for _, filter := range s.filters {
+		if err := s.client.Subscribe(ctx, filter, s.qos, func(msg Message) {
+			s.handle(ctx, msg)
+		}); err != nil {
+			return err
+		}
+		subscribed = append(subscribed, filter)
+	}
+
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// handle 路由一条消息
+func (s *mqttSource) handle(ctx context.Context, msg Message) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	info := Info{
+		Topic:     msg.Topic(),
+		Qos:       msg.Qos(),
+		Retained:  msg.Retained(),
+		MessageID: msg.MessageID(),
+	}
+	ctx = context.WithValue(ctx, infoKey{}, info)
+	ctx = router_context.WithMetadata(ctx, router_context.Metadata{
+		Transport:  "mqtt",
+		Source:     info.Topic,
+		ReceivedAt: time.Now(),
+	})
+
+	var w io.Writer
+	if s.responseTopic != nil {
+		if topic := s.responseTopic(info.Topic); topic != "" {
+			w = NewPublishWriter(ctx, s.client, topic, s.qos, false)
+		}
+	}
+
+	manager := s.router.BufferManager()
+	buf := manager.Acquire()
+	buf.Write(msg.Payload())
+	err := s.router.RouteTo(ctx, buf, w)
+	manager.Release(buf)
+	if err != nil && s.onError != nil {
+		s.onError(err)
+	}
+}
+
+// NewPublishWriter 返回把每次Write作为一条消息发布到topic的io.Writer
+// 可以作为RouteTo的响应写入目标，把响应缓冲区发布出去
+func NewPublishWriter(ctx context.Context, client Client, topic string, qos byte, retained bool) io.Writer {
+	return &publishWriter{ctx: ctx, client: client, topic: topic, qos: qos, retained: retained}
+}
+
+// publishWriter 把每次Write作为一条消息发布
+type publishWriter struct {
+	ctx      context.Context
+	client   Client
+	topic    string
+	qos      byte
+	retained bool
+}
+
+// Write 发布一条消息
+func (w *publishWriter) Write(p []byte) (int, error) {
+	if err := w.client.Publish(w.ctx, w.topic, w.qos, w.retained, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// TopicMatcher 创建按MQTT主题过滤器匹配消息主题的匹配器
+// 支持单层通配符"+"和多层通配符"#"
+func TopicMatcher(filter string) router.Matcher {
+	return router.MatcherFunc(func(ctx router_context.Context) bool {
+		info, ok := InfoFromContext(ctx)
+		return ok && MatchTopic(filter, info.Topic)
+	})
+}
+
+// MatchTopic 判断主题是否匹配MQTT主题过滤器
+func MatchTopic(filter, topic string) bool {
+	// 以$开头的系统主题不匹配以通配符开头的过滤器
+	if strings.HasPrefix(topic, "$") && (strings.HasPrefix(filter, "+") || strings.HasPrefix(filter, "#")) {
+		return false
+	}
+	for {
+		fpart, frest, fmore := strings.Cut(filter, "/")
+		if fpart == "#" {
+			return true
+		}
+		tpart, trest, tmore := strings.Cut(topic, "/")
+		if fpart != "+" && fpart != tpart {
+			return false
+		}
+		if !fmore || !tmore {
+			// "a/#"同时匹配"a"
+			return fmore == tmore || (fmore && frest == "#")
+		}
+		filter, topic = frest, trest
+	}
+}
@@ -0,0 +1,154 @@
+package mqtt
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+)
+
+// fakeMessage 是测试用的消息
+type fakeMessage struct {
+	topic   string
+	payload []byte
+}
+
+func (m fakeMessage) Topic() string     { return m.topic }
+func (m fakeMessage) Payload() []byte   { return m.payload }
+func (m fakeMessage) Qos() byte         { return 1 }
+func (m fakeMessage) Retained() bool    { return false }
+func (m fakeMessage) MessageID() uint16 { return 7 }
+
+// published 记录发布的消息
+type published struct {
+	topic   string
+	qos     byte
+	payload string
+}
+
+// fakeClient 是测试用的MQTT客户端
+type fakeClient struct {
+	mu           sync.Mutex
+	handlers     map[string]func(Message)
+	unsubscribed []string
+	published    []published
+	subscribed   chan struct{}
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{handlers: make(map[string]func(Message)), subscribed: make(chan struct{}, 8)}
+}
+
+func (c *fakeClient) Subscribe(ctx context.Context, filter string, qos byte, handler func(Message)) error {
+	c.mu.Lock()
+	c.handlers[filter] = handler
+	c.mu.Unlock()
+	c.subscribed <- struct{}{}
+	return nil
+}
+
+func (c *fakeClient) Unsubscribe(ctx context.Context, filters ...string) error {
+	c.mu.Lock()
+	c.unsubscribed = append(c.unsubscribed, filters...)
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *fakeClient) Publish(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error {
+	c.mu.Lock()
+	c.published = append(c.published, published{topic, qos, string(payload)})
+	c.mu.Unlock()
+	return nil
+}
+
+// deliver 把消息投递给订阅了filter的处理函数
+func (c *fakeClient) deliver(filter string, msg Message) {
+	c.mu.Lock()
+	handler := c.handlers[filter]
+	c.mu.Unlock()
+	handler(msg)
+}
+
+func TestMQTTSource(t *testing.T) {
+	client := newFakeClient()
+	manager := manage.NewBufferManager()
+	r := router.NewRouter(router.WithBufferManager(manager))
+
+	var infos []Info
+	var mds []router_context.Metadata
+	r.Register(TopicMatcher("sensors/+/temp"), func(ctx router_context.Context) error {
+		info, _ := InfoFromContext(ctx)
+		infos = append(infos, info)
+		mds = append(mds, ctx.Metadata())
+		ctx.Response().WriteString("ack:")
+		ctx.Response().Write(ctx.Buffer().Get())
+		return nil
+	})
+
+	src := New(client, r, []string{"sensors/#"},
+		WithQoS(1),
+		WithResponseTopic(func(topic string) string { return topic + "/reply" }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- src.Run(ctx) }()
+	<-client.subscribed
+
+	client.deliver("sensors/#", fakeMessage{"sensors/kitchen/temp", []byte("21.5")})
+	client.deliver("sensors/#", fakeMessage{"sensors/kitchen/humidity", []byte("40")})
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not stop after cancel")
+	}
+
+	if len(infos) != 1 || infos[0] != (Info{Topic: "sensors/kitchen/temp", Qos: 1, MessageID: 7}) {
+		t.Errorf("Unexpected infos %+v", infos)
+	}
+	if mds[0].Transport != "mqtt" || mds[0].Source != "sensors/kitchen/temp" || mds[0].ReceivedAt.IsZero() {
+		t.Errorf("Unexpected metadata %+v", mds[0])
+	}
+	if len(client.published) != 1 || client.published[0] != (published{"sensors/kitchen/temp/reply", 1, "ack:21.5"}) {
+		t.Errorf("Unexpected published messages %+v", client.published)
+	}
+	if len(client.unsubscribed) != 1 || client.unsubscribed[0] != "sensors/#" {
+		t.Errorf("Run should unsubscribe on exit, got %v", client.unsubscribed)
+	}
+	if stats := manager.Stats(); stats.Outstanding != 0 {
+		t.Errorf("Every buffer should be released, got %+v", stats)
+	}
+}
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		filter, topic string
+		want          bool
+	}{
+		{"a/b", "a/b", true},
+		{"a/b", "a/c", false},
+		{"a/+", "a/b", true},
+		{"a/+", "a/b/c", false},
+		{"+/+", "/b", true},
+		{"a/#", "a", true},
+		{"a/#", "a/b/c", true},
+		{"#", "a/b", true},
+		{"a/b", "a", false},
+		{"a", "a/b", false},
+		{"#", "$SYS/broker", false},
+		{"$SYS/#", "$SYS/broker", true},
+	}
+	for _, tt := range tests {
+		if got := MatchTopic(tt.filter, tt.topic); got != tt.want {
+			t.Errorf("MatchTopic(%q, %q) = %v, want %v", tt.filter, tt.topic, got, tt.want)
+		}
+	}
+}
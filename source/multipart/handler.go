@@ -0,0 +1,51 @@
+package multipart
+
+import (
+	"bytes"
+	"errors"
+	"mime"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/source"
+)
+
+// NewHandler 创建接收HTTP multipart请求（例如multipart/form-data上传）并拆分路由的http.Handler
+// 响应状态码:
+//   - 204: 全部部分已路由
+//   - 415: 请求的Content-Type不是multipart类型
+//   - 413: 部分过大或数量过多
+//   - 400: multipart格式错误
+//   - 500: 没有设置WithErrorHandler时部分处理失败
+func NewHandler(r source.Router, opts ...Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if !strings.HasPrefix(mediaType, "multipart/") {
+			http.Error(w, ErrNotMultipart.Error(), http.StatusUnsupportedMediaType)
+			return
+		}
+
+		err := Route(req.Context(), textproto.MIMEHeader(req.Header), req.Body, r, opts...)
+		switch {
+		case err == nil:
+			w.WriteHeader(http.StatusNoContent)
+		case errors.Is(err, ErrPartTooLarge), errors.Is(err, ErrTooManyParts):
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		case errors.As(err, new(*PartError)):
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		default:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	})
+}
+
+// NewSplitHandler 返回把ctx.Buffer()作为MIME消息拆分后交给r路由的处理器
+// 用于拆分从其他消息来源收到的整封邮件等负载，拆分失败或部分处理失败时返回错误
+func NewSplitHandler(r source.Router, opts ...Option) router.HandlerFunc {
+	return func(ctx router_context.Context) error {
+		return RouteMessage(ctx, bytes.NewReader(ctx.Buffer().Get()), r, opts...)
+	}
+}
@@ -0,0 +1,101 @@
+package multipart
+
+import (
+	"bytes"
+	"context"
+	mime_multipart "mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+)
+
+func TestHandler(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenPart
+	h := NewHandler(newTestRouter(manager, &seen))
+
+	var body bytes.Buffer
+	mw := mime_multipart.NewWriter(&body)
+	mw.WriteField("title", "quarterly")
+	fw, _ := mw.CreateFormFile("upload", "data.csv")
+	fw.Write([]byte("a,b\n1,2\n"))
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want 204: %s", rec.Code, rec.Body)
+	}
+	if len(seen) != 2 {
+		t.Fatalf("seen %d parts, want 2", len(seen))
+	}
+	if seen[0].part.FormName != "title" || seen[0].data != "quarterly" {
+		t.Errorf("part 0 = %+v %q", seen[0].part, seen[0].data)
+	}
+	if seen[1].part.FileName != "data.csv" || seen[1].part.ContentType != "application/octet-stream" || seen[1].data != "a,b\n1,2\n" {
+		t.Errorf("part 1 = %+v %q", seen[1].part, seen[1].data)
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestHandlerStatus(t *testing.T) {
+	var seen []seenPart
+	h := NewHandler(newTestRouter(manage.NewBufferManager(), &seen), WithMaxPartSize(4))
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"not multipart", "application/json", "{}", http.StatusUnsupportedMediaType},
+		{"malformed", "multipart/form-data; boundary=b", "--b\r\nbroken", http.StatusBadRequest},
+		{"too large", "multipart/form-data; boundary=b", "--b\r\n\r\ntoo large\r\n--b--\r\n", http.StatusRequestEntityTooLarge},
+		{"handler error", "multipart/form-data; boundary=b", "--b\r\n\r\nbad\r\n--b--\r\n", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitHandler(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenPart
+	parts := newTestRouter(manager, &seen)
+
+	r := router.NewRouter(router.WithBufferManager(manager))
+	r.Match("From:", NewSplitHandler(parts))
+	r.Match("", func(ctx router_context.Context) error { return nil })
+
+	buf := manager.Acquire()
+	buf.Write([]byte(testMessage))
+	_, err := r.Route(context.Background(), buf)
+	manager.Release(buf)
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if len(seen) != 3 || seen[2].part.FileName != "report.pdf" {
+		t.Errorf("seen = %+v, want 3 parts ending with report.pdf", seen)
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
@@ -0,0 +1,283 @@
+// Package multipart 把MIME multipart负载（邮件或HTTP multipart）拆分为单独的部分路由
+// 每个部分作为一个缓冲区路由，部分的头部通过PartFromContext读取，便于按附件路由
+package multipart
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	mime_multipart "mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/source"
+)
+
+const (
+	// DefaultMaxPartSize 是单个部分解码后的默认最大长度
+	DefaultMaxPartSize = 32 << 20
+	// DefaultMaxParts 是一个负载中默认最多拆分的部分数量
+	DefaultMaxParts = 1000
+	// maxDepth 是嵌套multipart的最大深度
+	maxDepth = 8
+)
+
+var (
+	// ErrNotMultipart 表示负载的Content-Type不是multipart类型
+	ErrNotMultipart = errors.New("multipart: content type is not multipart")
+	// ErrPartTooLarge 表示部分超过WithMaxPartSize设置的长度
+	ErrPartTooLarge = errors.New("multipart: part too large")
+	// ErrTooManyParts 表示部分数量超过WithMaxParts设置的数量
+	ErrTooManyParts = errors.New("multipart: too many parts")
+	// ErrTooDeep 表示multipart嵌套层数过多
+	ErrTooDeep = errors.New("multipart: nesting too deep")
+)
+
+// PartError 表示处理器处理某个部分失败
+type PartError struct {
+	// Index 部分的序号
+	Index int
+	// Err 处理器返回的错误
+	Err error
+}
+
+// Error 实现error接口
+func (e *PartError) Error() string {
+	return fmt.Sprintf("multipart: part %d: %v", e.Index, e.Err)
+}
+
+// Unwrap 返回处理器返回的错误
+func (e *PartError) Unwrap() error {
+	return e.Err
+}
+
+// Part 定义一个部分的元数据
+type Part struct {
+	// Index 部分的序号，按出现顺序从0开始，嵌套的部分同样按顺序编号
+	Index int
+	// Depth 嵌套深度，顶层multipart中的部分为1，不是multipart的负载为0
+	Depth int
+	// Header 部分的原始头部
+	Header textproto.MIMEHeader
+	// ContentType 不含参数的媒体类型，头部缺失时为"text/plain"
+	ContentType string
+	// FileName Content-Disposition中的文件名，没有时为空
+	FileName string
+	// FormName Content-Disposition中的表单字段名，没有时为空
+	FormName string
+}
+
+// partKey 是部分元数据在标准context中的键
+type partKey struct{}
+
+// PartFromContext 获取正在处理的部分的元数据，处理器可以直接传入路由上下文
+func PartFromContext(ctx context.Context) (Part, bool) {
+	part, ok := ctx.Value(partKey{}).(Part)
+	return part, ok
+}
+
+// ContentTypeMatcher 创建按部分媒体类型匹配的匹配器
+// pattern以"/*"结尾时匹配整个主类型，例如"image/*"
+func ContentTypeMatcher(pattern string) router.Matcher {
+	prefix, wildcard := strings.CutSuffix(pattern, "*")
+	return router.MatcherFunc(func(ctx router_context.Context) bool {
+		part, ok := PartFromContext(ctx)
+		if !ok {
+			return false
+		}
+		if wildcard {
+			return strings.HasPrefix(part.ContentType, prefix)
+		}
+		return part.ContentType == pattern
+	})
+}
+
+// AttachmentMatcher 创建匹配带文件名的部分（附件或上传的文件）的匹配器
+func AttachmentMatcher() router.Matcher {
+	return router.MatcherFunc(func(ctx router_context.Context) bool {
+		part, ok := PartFromContext(ctx)
+		return ok && part.FileName != ""
+	})
+}
+
+// Option 定义拆分的配置选项
+type Option func(*options)
+
+// options 保存配置选项
+type options struct {
+	maxPartSize int64
+	maxParts    int
+	source      string
+	onError     source.ErrorHandler
+}
+
+// WithMaxPartSize 设置单个部分解码后的最大长度，默认DefaultMaxPartSize
+func WithMaxPartSize(n int64) Option {
+	return func(o *options) {
+		o.maxPartSize = n
+	}
+}
+
+// WithMaxParts 设置最多拆分的部分数量，默认DefaultMaxParts
+func WithMaxParts(n int) Option {
+	return func(o *options) {
+		o.maxParts = n
+	}
+}
+
+// WithSource 设置ctx.Metadata()中的来源名称
+func WithSource(name string) Option {
+	return func(o *options) {
+		o.source = name
+	}
+}
+
+// WithErrorHandler 设置单个部分处理失败时的回调，设置后处理失败不会停止拆分
+func WithErrorHandler(fn source.ErrorHandler) Option {
+	return func(o *options) {
+		o.onError = fn
+	}
+}
+
+// newOptions 应用配置选项
+func newOptions(opts []Option) options {
+	o := options{
+		maxPartSize: DefaultMaxPartSize,
+		maxParts:    DefaultMaxParts,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// RouteMessage 读取MIME消息（例如邮件）的头部和正文，拆分后依次路由每个部分
+// 不是multipart类型的消息作为一个部分路由
+// 没有设置WithErrorHandler时遇到第一个处理失败即停止并返回*PartError
+func RouteMessage(ctx context.Context, r io.Reader, rt source.Router, opts ...Option) error {
+	br := bufio.NewReader(r)
+	header, err := textproto.NewReader(br).ReadMIMEHeader()
+	if err != nil && !(errors.Is(err, io.EOF) && len(header) > 0) {
+		return fmt.Errorf("multipart: read header: %w", err)
+	}
+	return Route(ctx, header, br, rt, opts...)
+}
+
+// Route 按header中的Content-Type拆分body并依次路由每个部分
+// 嵌套的multipart（例如multipart/mixed中的multipart/alternative）会被展开，
+// Content-Transfer-Encoding为base64或quoted-printable的部分在路由前解码
+// 不是multipart类型的负载作为一个部分路由
+func Route(ctx context.Context, header textproto.MIMEHeader, body io.Reader, rt source.Router, opts ...Option) error {
+	s := &splitter{
+		options: newOptions(opts),
+		router:  rt,
+		md:      router_context.Metadata{Transport: "multipart"},
+	}
+	s.md.Source = s.source
+	return s.split(ctx, header, body, 0)
+}
+
+// splitter 保存一次拆分的状态
+type splitter struct {
+	options
+	router source.Router
+	md     router_context.Metadata
+	count  int
+}
+
+// split 拆分一个实体，multipart类型递归拆分，其余类型作为一个部分路由
+func (s *splitter) split(ctx context.Context, header textproto.MIMEHeader, body io.Reader, depth int) error {
+	mediaType, params, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return s.route(ctx, header, body, depth)
+	}
+	if depth >= maxDepth {
+		return ErrTooDeep
+	}
+	boundary := params["boundary"]
+	if boundary == "" {
+		return fmt.Errorf("multipart: missing boundary in %q", mediaType)
+	}
+
+	mr := mime_multipart.NewReader(body, boundary)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		p, err := mr.NextRawPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("multipart: %w", err)
+		}
+		if err := s.split(ctx, p.Header, p, depth+1); err != nil {
+			return err
+		}
+	}
+}
+
+// route 把一个部分读入缓冲区并路由
+func (s *splitter) route(ctx context.Context, header textproto.MIMEHeader, body io.Reader, depth int) error {
+	if s.count >= s.maxParts {
+		return ErrTooManyParts
+	}
+	index := s.count
+	s.count++
+
+	part := Part{
+		Index:       index,
+		Depth:       depth,
+		Header:      header,
+		ContentType: "text/plain",
+	}
+	if mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil {
+		part.ContentType = mediaType
+	}
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+		part.FileName = params["filename"]
+		part.FormName = params["name"]
+	}
+
+	manager := s.router.BufferManager()
+	buf := manager.Acquire()
+	defer manager.Release(buf)
+	n, err := io.Copy(buf, io.LimitReader(decode(header, body), s.maxPartSize+1))
+	if err != nil {
+		return fmt.Errorf("multipart: part %d: %w", index, err)
+	}
+	if n > s.maxPartSize {
+		return fmt.Errorf("multipart: part %d: %w", index, ErrPartTooLarge)
+	}
+
+	s.md.ReceivedAt = time.Now()
+	partCtx := context.WithValue(router_context.WithMetadata(ctx, s.md), partKey{}, part)
+	if _, err := s.router.Route(partCtx, buf); err != nil {
+		err = &PartError{Index: index, Err: err}
+		if s.onError == nil {
+			return err
+		}
+		s.onError(err)
+	}
+	return nil
+}
+
+// decode 按Content-Transfer-Encoding解码部分内容
+func decode(header textproto.MIMEHeader, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(header.Get("Content-Transfer-Encoding"))) {
+	case "base64":
+		// base64解码器会忽略换行符
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	}
+	return body
+}
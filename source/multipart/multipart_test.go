@@ -0,0 +1,201 @@
+package multipart
+
+import (
+	"context"
+	"errors"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/router/routertest"
+)
+
+// seenPart 记录处理器看到的一个部分
+type seenPart struct {
+	part Part
+	data string
+}
+
+// newTestRouter 创建记录所有部分并拒绝内容为"bad"的部分的路由器
+func newTestRouter(manager manage.BufferManager, seen *[]seenPart) router.Router {
+	return routertest.NewSourceRouter(manager, func(ctx router_context.Context) error {
+		part, _ := PartFromContext(ctx)
+		*seen = append(*seen, seenPart{part: part, data: string(ctx.Buffer().Get())})
+		return nil
+	}, routertest.ExpectMetadata(router_context.Metadata{Transport: "multipart"}))
+}
+
+// testMessage 是包含正文、嵌套的multipart/alternative和base64附件的邮件
+const testMessage = "From: a@example.com\r\n" +
+	"Subject: report\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=outer\r\n" +
+	"\r\n" +
+	"preamble\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=inner\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"caf=C3=A9\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html\r\n" +
+	"\r\n" +
+	"<p>hi</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/pdf\r\n" +
+	"Content-Disposition: attachment; filename=\"report.pdf\"\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERi0x\r\n" +
+	"LjQ=\r\n" +
+	"--outer--\r\n"
+
+func TestRouteMessage(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenPart
+	r := newTestRouter(manager, &seen)
+
+	if err := RouteMessage(context.Background(), strings.NewReader(testMessage), r); err != nil {
+		t.Fatalf("RouteMessage() error = %v", err)
+	}
+
+	want := []struct {
+		index, depth int
+		contentType  string
+		fileName     string
+		data         string
+	}{
+		{0, 2, "text/plain", "", "café"},
+		{1, 2, "text/html", "", "<p>hi</p>"},
+		{2, 1, "application/pdf", "report.pdf", "%PDF-1.4"},
+	}
+	if len(seen) != len(want) {
+		t.Fatalf("seen %d parts, want %d", len(seen), len(want))
+	}
+	for i, w := range want {
+		got := seen[i]
+		if got.part.Index != w.index || got.part.Depth != w.depth || got.part.ContentType != w.contentType ||
+			got.part.FileName != w.fileName || got.data != w.data {
+			t.Errorf("part %d = %+v %q, want %+v", i, got.part, got.data, w)
+		}
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRouteMessageNotMultipart(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenPart
+	r := newTestRouter(manager, &seen)
+
+	msg := "Subject: hi\r\n\r\nplain body"
+	if err := RouteMessage(context.Background(), strings.NewReader(msg), r); err != nil {
+		t.Fatalf("RouteMessage() error = %v", err)
+	}
+	if len(seen) != 1 || seen[0].data != "plain body" || seen[0].part.Depth != 0 ||
+		seen[0].part.ContentType != "text/plain" {
+		t.Errorf("seen = %+v, want one text/plain part", seen)
+	}
+}
+
+// formBody 创建包含给定部分内容的multipart/form-data请求体
+func formBody(values ...string) (textproto.MIMEHeader, *strings.Reader) {
+	var b strings.Builder
+	for i, v := range values {
+		b.WriteString("--b\r\nContent-Disposition: form-data; name=\"f" + string(rune('0'+i)) + "\"\r\n\r\n" + v + "\r\n")
+	}
+	b.WriteString("--b--\r\n")
+	header := textproto.MIMEHeader{"Content-Type": {"multipart/form-data; boundary=b"}}
+	return header, strings.NewReader(b.String())
+}
+
+func TestRouteErrors(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenPart
+	r := newTestRouter(manager, &seen)
+
+	header, body := formBody("a", "bad", "c")
+	err := Route(context.Background(), header, body, r)
+	var partErr *PartError
+	if !errors.As(err, &partErr) || partErr.Index != 1 {
+		t.Fatalf("Route() error = %v, want PartError for part 1", err)
+	}
+	if len(seen) != 2 || seen[0].part.FormName != "f0" {
+		t.Errorf("seen = %+v, want parts 0 and 1", seen)
+	}
+
+	seen = nil
+	var errs []error
+	header, body = formBody("bad", "b", "bad")
+	err = Route(context.Background(), header, body, r, WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	if err != nil {
+		t.Fatalf("Route() with error handler error = %v", err)
+	}
+	if len(seen) != 3 || len(errs) != 2 {
+		t.Errorf("seen %d parts and %d errors, want 3 and 2", len(seen), len(errs))
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRouteLimits(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenPart
+	r := newTestRouter(manager, &seen)
+
+	header, body := formBody("short", strings.Repeat("x", 16))
+	if err := Route(context.Background(), header, body, r, WithMaxPartSize(8)); !errors.Is(err, ErrPartTooLarge) {
+		t.Errorf("Route() error = %v, want ErrPartTooLarge", err)
+	}
+
+	header, body = formBody("a", "b", "c")
+	if err := Route(context.Background(), header, body, r, WithMaxParts(2)); !errors.Is(err, ErrTooManyParts) {
+		t.Errorf("Route() error = %v, want ErrTooManyParts", err)
+	}
+
+	header = textproto.MIMEHeader{"Content-Type": {"multipart/mixed"}}
+	if err := Route(context.Background(), header, strings.NewReader(""), r); err == nil {
+		t.Error("Route() without boundary should fail")
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestMatchers(t *testing.T) {
+	manager := manage.NewBufferManager()
+	r := router.NewRouter(router.WithBufferManager(manager))
+	var images, attachments, other int
+	r.Register(ContentTypeMatcher("image/*"), func(ctx router_context.Context) error {
+		images++
+		return nil
+	})
+	r.Register(AttachmentMatcher(), func(ctx router_context.Context) error {
+		attachments++
+		return nil
+	})
+	r.Register(ContentTypeMatcher("text/plain"), func(ctx router_context.Context) error {
+		other++
+		return nil
+	})
+
+	body := "--b\r\nContent-Type: image/png\r\nContent-Disposition: attachment; filename=a.png\r\n\r\npng\r\n" +
+		"--b\r\nContent-Type: application/zip\r\nContent-Disposition: attachment; filename=a.zip\r\n\r\nzip\r\n" +
+		"--b\r\n\r\ntext\r\n--b--\r\n"
+	header := textproto.MIMEHeader{"Content-Type": {"multipart/mixed; boundary=b"}}
+	if err := Route(context.Background(), header, strings.NewReader(body), r); err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if images != 1 || attachments != 1 || other != 1 {
+		t.Errorf("images=%d attachments=%d other=%d, want 1 each", images, attachments, other)
+	}
+}
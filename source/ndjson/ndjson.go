@@ -0,0 +1,150 @@
+// Package ndjson 提供换行分隔JSON（NDJSON）的批量路由
+// 逐行读取io.Reader中的JSON对象并依次路由，适合对导出数据进行批量重新处理
+package ndjson
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aomirun/content-router/buffer/jsonutil"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/source"
+)
+
+// Record 定义一行JSON的元数据
+type Record struct {
+	// Line 行号，从1开始
+	Line int
+	// Type 通过WithTypeField提取的类型字段值，没有设置或字段不存在时为空
+	Type string
+}
+
+// recordKey 是行元数据在标准context中的键
+type recordKey struct{}
+
+// RecordFromContext 获取正在处理的行的元数据，处理器可以直接传入路由上下文
+func RecordFromContext(ctx context.Context) (Record, bool) {
+	rec, ok := ctx.Value(recordKey{}).(Record)
+	return rec, ok
+}
+
+// TypeMatcher 创建按预先提取的类型字段匹配的匹配器，需要配合WithTypeField使用
+func TypeMatcher(typ string) router.Matcher {
+	return router.MatcherFunc(func(ctx router_context.Context) bool {
+		rec, ok := RecordFromContext(ctx)
+		return ok && rec.Type == typ
+	})
+}
+
+// Option 定义NDJSON路由的配置选项
+type Option func(*options)
+
+// options 保存配置选项
+type options struct {
+	typeField string
+	maxSize   int
+	source    string
+	onError   source.ErrorHandler
+}
+
+// WithTypeField 在路由前提取path指定的字符串字段作为Record.Type
+// 字段路径语法与jsonutil.Get相同，例如"type"或"event.kind"
+func WithTypeField(path string) Option {
+	return func(o *options) {
+		o.typeField = path
+	}
+}
+
+// WithMaxLineSize 设置一行的最大长度，默认frame.DefaultMaxFrameSize
+func WithMaxLineSize(n int) Option {
+	return func(o *options) {
+		o.maxSize = n
+	}
+}
+
+// WithSource 设置ctx.Metadata()中的来源名称，例如输入文件名
+func WithSource(name string) Option {
+	return func(o *options) {
+		o.source = name
+	}
+}
+
+// WithErrorHandler 设置单行处理失败时的回调，设置后处理失败不会停止路由
+func WithErrorHandler(fn source.ErrorHandler) Option {
+	return func(o *options) {
+		o.onError = fn
+	}
+}
+
+// Route 逐行读取r中的JSON对象并依次路由，跳过空行
+// 没有设置WithErrorHandler时遇到第一个处理失败即停止并返回错误，错误中包含行号
+// 返回: 读取错误、ctx的错误或处理失败的错误，读完全部输入时返回nil
+func Route(ctx context.Context, r io.Reader, rt source.Router, opts ...Option) error {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	framer := frame.NewLineFramer(o.maxSize)
+	br := bufio.NewReader(r)
+	manager := rt.BufferManager()
+	md := router_context.Metadata{Transport: "ndjson", Source: o.source}
+
+	for line := 1; ; line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		buf := manager.Acquire()
+		err := framer.ReadFrame(br, buf)
+		// 最后一行可以没有换行符
+		if err != nil && !(errors.Is(err, io.ErrUnexpectedEOF) && buf.Len() > 0) {
+			manager.Release(buf)
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("ndjson: line %d: %w", line, err)
+		}
+		if isBlank(buf.Get()) {
+			manager.Release(buf)
+			if err != nil {
+				return nil
+			}
+			continue
+		}
+
+		rec := Record{Line: line}
+		if o.typeField != "" {
+			rec.Type, _ = jsonutil.GetString(buf.Get(), o.typeField)
+		}
+		md.ReceivedAt = time.Now()
+		lineCtx := context.WithValue(router_context.WithMetadata(ctx, md), recordKey{}, rec)
+		_, rerr := rt.Route(lineCtx, buf)
+		manager.Release(buf)
+		if rerr != nil {
+			rerr = fmt.Errorf("ndjson: line %d: %w", line, rerr)
+			if o.onError == nil {
+				return rerr
+			}
+			o.onError(rerr)
+		}
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+// isBlank 判断一行是否只包含空白字符
+func isBlank(data []byte) bool {
+	for _, c := range data {
+		if c != ' ' && c != '\t' && c != '\r' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+package ndjson
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/router/routertest"
+)
+
+// seenLine 记录处理器看到的一行
+type seenLine struct {
+	rec  Record
+	data string
+}
+
+// newTestRouter 创建记录所有行并拒绝type为"bad"的行的路由器
+func newTestRouter(manager manage.BufferManager, seen *[]seenLine) router.Router {
+	return routertest.NewSourceRouter(manager, func(ctx router_context.Context) error {
+		rec, _ := RecordFromContext(ctx)
+		*seen = append(*seen, seenLine{rec: rec, data: string(ctx.Buffer().Get())})
+		return nil
+	},
+		routertest.ExpectMetadata(router_context.Metadata{Transport: "ndjson", Source: "export.ndjson"}),
+		routertest.RejectWhen(TypeMatcher("bad")))
+}
+
+func TestRouteExtractsType(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenLine
+	r := newTestRouter(manager, &seen)
+
+	input := "{\"type\":\"order\",\"id\":1}\n\n  \r\n{\"event\":{\"kind\":\"x\"}}\r\n{\"type\":\"refund\"}"
+	err := Route(context.Background(), strings.NewReader(input), r,
+		WithTypeField("type"), WithSource("export.ndjson"))
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+
+	want := []seenLine{
+		{Record{Line: 1, Type: "order"}, `{"type":"order","id":1}`},
+		{Record{Line: 4}, `{"event":{"kind":"x"}}`},
+		{Record{Line: 5, Type: "refund"}, `{"type":"refund"}`},
+	}
+	if len(seen) != len(want) {
+		t.Fatalf("seen %d lines, want %d: %+v", len(seen), len(want), seen)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Errorf("line %d = %+v, want %+v", i, seen[i], want[i])
+		}
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRouteStopsOnFirstError(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenLine
+	r := newTestRouter(manager, &seen)
+
+	input := "{\"type\":\"a\"}\n{\"type\":\"bad\"}\n{\"type\":\"c\"}\n"
+	err := Route(context.Background(), strings.NewReader(input), r,
+		WithTypeField("type"), WithSource("export.ndjson"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("Route() error = %v, want line 2 error", err)
+	}
+	if len(seen) != 2 {
+		t.Errorf("seen %d lines, want processing to stop at line 2", len(seen))
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRouteContinuesWithErrorHandler(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenLine
+	r := newTestRouter(manager, &seen)
+
+	var errs []error
+	input := "{\"type\":\"bad\"}\n{\"type\":\"a\"}\n{\"type\":\"bad\"}\n"
+	err := Route(context.Background(), strings.NewReader(input), r,
+		WithTypeField("type"), WithSource("export.ndjson"),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	if err != nil {
+		t.Fatalf("Route() error = %v", err)
+	}
+	if len(seen) != 3 || seen[1].rec.Line != 2 || seen[2].rec.Line != 3 {
+		t.Errorf("seen = %+v, want lines 1 to 3", seen)
+	}
+	if len(errs) != 2 || !strings.Contains(errs[1].Error(), "line 3") {
+		t.Errorf("errors = %v, want 2 errors ending with line 3", errs)
+	}
+}
+
+func TestRouteLineTooLong(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenLine
+	r := newTestRouter(manager, &seen)
+
+	input := "{\"type\":\"a\"}\n{\"data\":\"" + strings.Repeat("x", 64) + "\"}\n"
+	err := Route(context.Background(), strings.NewReader(input), r,
+		WithMaxLineSize(32), WithSource("export.ndjson"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("Route() error = %v, want line 2 error", err)
+	}
+	if len(seen) != 1 {
+		t.Errorf("seen %d lines, want 1", len(seen))
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRouteCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var seen []seenLine
+	r := newTestRouter(manage.NewBufferManager(), &seen)
+
+	err := Route(ctx, strings.NewReader("{}\n"), r)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Route() error = %v, want context.Canceled", err)
+	}
+	if len(seen) != 0 {
+		t.Errorf("seen %d lines, want 0", len(seen))
+	}
+}
@@ -0,0 +1,281 @@
+// Package redis 提供Redis Streams消息来源
+// 通过消费者组（XREADGROUP）读取条目并路由，处理成功后确认（XACK），
+// 并定期认领（XAUTOCLAIM）其他消费者长时间未确认的条目
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/source"
+)
+
+const (
+	// DefaultCount 是每次读取的默认最大条目数
+	DefaultCount = 10
+	// DefaultBlock 是读取没有新条目时的默认阻塞时间
+	DefaultBlock = 5 * time.Second
+	// DefaultClaimMinIdle 是认领待确认条目的默认最小空闲时间
+	DefaultClaimMinIdle = time.Minute
+	// DefaultClaimInterval 是两次认领之间的默认间隔
+	DefaultClaimInterval = 30 * time.Second
+)
+
+// ErrNoPayload 表示条目中没有WithPayloadField指定的字段
+var ErrNoPayload = errors.New("redis: entry has no payload field")
+
+// Field 定义条目中的一个字段
+type Field struct {
+	Name  string
+	Value []byte
+}
+
+// Entry 定义流中的一个条目
+type Entry struct {
+	// Stream 条目所在的流
+	Stream string
+	// ID 条目ID，例如"1700000000000-0"
+	ID string
+	// Fields 按写入顺序排列的字段
+	Fields []Field
+}
+
+// Field 获取指定名称的第一个字段值
+func (e Entry) Field(name string) ([]byte, bool) {
+	for _, f := range e.Fields {
+		if f.Name == name {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Client 定义消息来源需要的Redis命令，由使用方基于所选的Redis客户端库实现
+type Client interface {
+	// ReadGroup 执行XREADGROUP GROUP group consumer COUNT count BLOCK block STREAMS streams... >...
+	// 阻塞超时没有新条目时返回空列表和nil
+	ReadGroup(ctx context.Context, group, consumer string, streams []string, count int, block time.Duration) ([]Entry, error)
+	// Ack 执行XACK stream group ids...
+	Ack(ctx context.Context, stream, group string, ids ...string) error
+	// AutoClaim 执行XAUTOCLAIM stream group consumer minIdle start COUNT count
+	// 返回: 下一次认领的起始ID（"0-0"表示已经扫描完毕）和认领到的条目
+	AutoClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, start string, count int) (string, []Entry, error)
+}
+
+// Info 定义正在处理的条目的元数据
+type Info struct {
+	Entry
+	// Group 消费者组名称
+	Group string
+	// Claimed 条目是否是从待确认列表中认领的（可能已经被处理过）
+	Claimed bool
+}
+
+// infoKey 是条目元数据在标准context中的键
+type infoKey struct{}
+
+// InfoFromContext 获取正在处理的条目，处理器可以直接传入路由上下文
+// 条目的字段值只在路由期间有效
+func InfoFromContext(ctx context.Context) (Info, bool) {
+	info, ok := ctx.Value(infoKey{}).(Info)
+	return info, ok
+}
+
+// Option 定义Redis Streams消息来源的配置选项
+type Option func(*redisSource)
+
+// WithCount 设置每次读取和认领的最大条目数，默认DefaultCount
+func WithCount(n int) Option {
+	return func(s *redisSource) {
+		s.count = n
+	}
+}
+
+// WithBlock 设置读取没有新条目时的阻塞时间，默认DefaultBlock
+func WithBlock(d time.Duration) Option {
+	return func(s *redisSource) {
+		s.block = d
+	}
+}
+
+// WithPayloadField 设置作为缓冲区内容路由的字段
+// 默认为空，此时所有字段编码为JSON对象（字段值按字符串编码）后路由
+func WithPayloadField(name string) Option {
+	return func(s *redisSource) {
+		s.payloadField = name
+	}
+}
+
+// WithClaim 设置认领待确认条目的最小空闲时间和认领间隔
+// 默认DefaultClaimMinIdle和DefaultClaimInterval，minIdle小于等于0时不认领
+func WithClaim(minIdle, interval time.Duration) Option {
+	return func(s *redisSource) {
+		s.claimMinIdle = minIdle
+		s.claimInterval = interval
+	}
+}
+
+// WithErrorHandler 设置条目处理失败时的回调
+// 处理失败的条目不会被确认，留在待确认列表中等待认领后重新处理
+func WithErrorHandler(fn source.ErrorHandler) Option {
+	return func(s *redisSource) {
+		s.onError = fn
+	}
+}
+
+// redisSource 是Redis Streams消息来源的实现
+type redisSource struct {
+	client        Client
+	router        source.Router
+	group         string
+	consumer      string
+	streams       []string
+	count         int
+	block         time.Duration
+	payloadField  string
+	claimMinIdle  time.Duration
+	claimInterval time.Duration
+	onError       source.ErrorHandler
+	lastClaim     time.Time
+}
+
+// New 创建Redis Streams消息来源，消费者组需要事先创建（XGROUP CREATE）
+//   - group: 消费者组名称
+//   - consumer: 组内的消费者名称，同一个组的各个实例应该使用不同的名称
+//   - streams: 要读取的流
+//
+// 每个条目的上下文通过InfoFromContext提供条目ID和字段，
+// 通过ctx.Metadata()提供传输层名称"redis"、流名称和接收时间
+func New(client Client, r source.Router, group, consumer string, streams []string, opts ...Option) source.Source {
+	s := &redisSource{
+		client:        client,
+		router:        r,
+		group:         group,
+		consumer:      consumer,
+		streams:       streams,
+		count:         DefaultCount,
+		block:         DefaultBlock,
+		claimMinIdle:  DefaultClaimMinIdle,
+		claimInterval: DefaultClaimInterval,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run 持续读取并路由条目，直到ctx被取消或Redis命令失败
+// 启动时和每隔认领间隔先认领空闲超时的待确认条目，再读取新条目
+func (s *redisSource) Run(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if s.claimMinIdle > 0 && time.Since(s.lastClaim) >= s.claimInterval {
+			if err := s.claim(ctx); err != nil {
+				return err
+			}
+			s.lastClaim = time.Now()
+		}
+
+		entries, err := s.client.ReadGroup(ctx, s.group, s.consumer, s.streams, s.count, s.block)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return err
+		}
+		if err := s.handle(ctx, entries, false); err != nil {
+			return err
+		}
+	}
+}
+
+// claim 认领所有流中空闲超时的待确认条目并路由
+func (s *redisSource) claim(ctx context.Context) error {
+	for _, stream := range s.streams {
+		start := "0-0"
+		for {
+			next, entries, err := s.client.AutoClaim(ctx, stream, s.group, s.consumer, s.claimMinIdle, start, s.count)
+			if err != nil {
+				return err
+			}
+			if err := s.handle(ctx, entries, true); err != nil {
+				return err
+			}
+			if next == "0-0" || next == "" {
+				break
+			}
+			start = next
+		}
+	}
+	return nil
+}
+
+// handle 路由一批条目，并确认处理成功的条目
+func (s *redisSource) handle(ctx context.Context, entries []Entry, claimed bool) error {
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.route(ctx, Info{Entry: entry, Group: s.group, Claimed: claimed}); err != nil {
+			if s.onError != nil {
+				s.onError(fmt.Errorf("redis: %s %s: %w", entry.Stream, entry.ID, err))
+			}
+			continue
+		}
+		if err := s.client.Ack(context.WithoutCancel(ctx), entry.Stream, s.group, entry.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// route 路由一个条目
+func (s *redisSource) route(ctx context.Context, info Info) error {
+	manager := s.router.BufferManager()
+	buf := manager.Acquire()
+	defer manager.Release(buf)
+
+	if s.payloadField != "" {
+		payload, ok := info.Field(s.payloadField)
+		if !ok {
+			return ErrNoPayload
+		}
+		buf.Write(payload)
+	} else {
+		buf.Write(encodeFields(info.Fields))
+	}
+
+	ctx = context.WithValue(ctx, infoKey{}, info)
+	ctx = router_context.WithMetadata(ctx, router_context.Metadata{
+		Transport:  "redis",
+		Source:     info.Stream,
+		ReceivedAt: time.Now(),
+	})
+	_, err := s.router.Route(ctx, buf)
+	return err
+}
+
+// encodeFields 把字段编码为JSON对象，保持字段顺序，重复的字段名保留第一个
+func encodeFields(fields []Field) []byte {
+	out := []byte{'{'}
+	seen := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		if seen[f.Name] {
+			continue
+		}
+		seen[f.Name] = true
+		if len(out) > 1 {
+			out = append(out, ',')
+		}
+		name, _ := json.Marshal(f.Name)
+		value, _ := json.Marshal(string(f.Value))
+		out = append(append(append(out, name...), ':'), value...)
+	}
+	return append(out, '}')
+}
@@ -0,0 +1,189 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/router/routertest"
+)
+
+// errDrained 表示预设的批次已经用完
+var errDrained = errors.New("drained")
+
+// fakeClient 依次返回预设的读取批次和认领结果，批次用完后ReadGroup返回errDrained
+type fakeClient struct {
+	reads   [][]Entry
+	claims  map[string][][]Entry
+	acked   []string
+	claimed []string
+}
+
+func (c *fakeClient) ReadGroup(ctx context.Context, group, consumer string, streams []string, count int, block time.Duration) ([]Entry, error) {
+	if len(c.reads) == 0 {
+		return nil, errDrained
+	}
+	batch := c.reads[0]
+	c.reads = c.reads[1:]
+	return batch, nil
+}
+
+func (c *fakeClient) Ack(ctx context.Context, stream, group string, ids ...string) error {
+	for _, id := range ids {
+		c.acked = append(c.acked, stream+"/"+id)
+	}
+	return nil
+}
+
+func (c *fakeClient) AutoClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, start string, count int) (string, []Entry, error) {
+	c.claimed = append(c.claimed, stream+"@"+start)
+	pages := c.claims[stream]
+	if len(pages) == 0 {
+		return "0-0", nil, nil
+	}
+	c.claims[stream] = pages[1:]
+	if len(pages) == 1 {
+		return "0-0", pages[0], nil
+	}
+	return pages[0][len(pages[0])-1].ID, pages[0], nil
+}
+
+// entry 创建测试条目
+func entry(stream, id, payload string) Entry {
+	return Entry{
+		Stream: stream,
+		ID:     id,
+		Fields: []Field{{Name: "type", Value: []byte("order")}, {Name: "payload", Value: []byte(payload)}},
+	}
+}
+
+// seenEntry 记录处理器看到的一个条目
+type seenEntry struct {
+	info Info
+	data string
+}
+
+// newTestRouter 创建记录所有条目并拒绝内容为"bad"的条目的路由器
+func newTestRouter(manager manage.BufferManager, seen *[]seenEntry) router.Router {
+	return routertest.NewSourceRouter(manager, func(ctx router_context.Context) error {
+		info, _ := InfoFromContext(ctx)
+		*seen = append(*seen, seenEntry{info: info, data: string(ctx.Buffer().Get())})
+		// 元数据的来源是条目所在的流
+		if ctx.Metadata().Source != info.Stream {
+			return routertest.ErrMissingMetadata
+		}
+		return nil
+	}, routertest.ExpectMetadata(router_context.Metadata{Transport: "redis"}))
+}
+
+func TestRunAcksSuccessfulEntries(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenEntry
+	client := &fakeClient{reads: [][]Entry{
+		{entry("orders", "1-0", "a"), entry("orders", "2-0", "bad")},
+		nil,
+		{entry("events", "3-0", "c")},
+	}}
+	var errs []error
+	src := New(client, newTestRouter(manager, &seen), "workers", "w1", []string{"orders", "events"},
+		WithPayloadField("payload"), WithClaim(0, 0),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }))
+
+	if err := src.Run(context.Background()); !errors.Is(err, errDrained) {
+		t.Fatalf("Run() error = %v, want errDrained", err)
+	}
+	if len(seen) != 3 || seen[0].data != "a" || seen[0].info.Group != "workers" || seen[0].info.Claimed {
+		t.Errorf("seen = %+v", seen)
+	}
+	if got := strings.Join(client.acked, ","); got != "orders/1-0,events/3-0" {
+		t.Errorf("acked = %s, want orders/1-0,events/3-0", got)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "2-0") {
+		t.Errorf("errors = %v, want one error for 2-0", errs)
+	}
+	if len(client.claimed) != 0 {
+		t.Errorf("claimed = %v, want none when claiming is disabled", client.claimed)
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRunClaimsPendingEntries(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenEntry
+	client := &fakeClient{
+		claims: map[string][][]Entry{
+			"orders": {
+				{entry("orders", "1-0", "a")},
+				{entry("orders", "5-0", "b")},
+			},
+		},
+	}
+	src := New(client, newTestRouter(manager, &seen), "workers", "w1", []string{"orders", "events"},
+		WithPayloadField("payload"), WithClaim(time.Minute, time.Hour))
+
+	if err := src.Run(context.Background()); !errors.Is(err, errDrained) {
+		t.Fatalf("Run() error = %v, want errDrained", err)
+	}
+	if got := strings.Join(client.claimed, ","); got != "orders@0-0,orders@1-0,events@0-0" {
+		t.Errorf("claim calls = %s", got)
+	}
+	if len(seen) != 2 || !seen[0].info.Claimed || !seen[1].info.Claimed {
+		t.Errorf("seen = %+v, want 2 claimed entries", seen)
+	}
+	if got := strings.Join(client.acked, ","); got != "orders/1-0,orders/5-0" {
+		t.Errorf("acked = %s", got)
+	}
+}
+
+func TestRunEncodesFieldsAsJSON(t *testing.T) {
+	manager := manage.NewBufferManager()
+	var seen []seenEntry
+	client := &fakeClient{reads: [][]Entry{{
+		{Stream: "s", ID: "1-0", Fields: []Field{
+			{Name: "type", Value: []byte("order")},
+			{Name: "note", Value: []byte(`say "hi"`)},
+			{Name: "type", Value: []byte("dup")},
+		}},
+		{Stream: "s", ID: "2-0", Fields: []Field{{Name: "type", Value: []byte("x")}}},
+	}}}
+	var errs []error
+	src := New(client, newTestRouter(manager, &seen), "g", "c", []string{"s"}, WithClaim(0, 0),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	src.Run(context.Background())
+
+	if len(seen) != 2 || seen[0].data != `{"type":"order","note":"say \"hi\""}` {
+		t.Errorf("seen = %+v", seen)
+	}
+	if len(errs) != 0 {
+		t.Errorf("errors = %v", errs)
+	}
+
+	// 指定的负载字段不存在时报告错误且不确认
+	client = &fakeClient{reads: [][]Entry{{{Stream: "s", ID: "1-0"}}}}
+	src = New(client, newTestRouter(manager, &seen), "g", "c", []string{"s"}, WithClaim(0, 0),
+		WithPayloadField("payload"), WithErrorHandler(func(err error) { errs = append(errs, err) }))
+	src.Run(context.Background())
+	if len(errs) != 1 || !errors.Is(errs[0], ErrNoPayload) || len(client.acked) != 0 {
+		t.Errorf("errors = %v, acked = %v; want ErrNoPayload and no ack", errs, client.acked)
+	}
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var seen []seenEntry
+	src := New(&fakeClient{}, newTestRouter(manage.NewBufferManager(), &seen), "g", "c", []string{"s"})
+	if err := src.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,198 @@
+// Package serial 提供串口（UART）消息来源
+// 从串口逐帧读取设备数据并路由，处理器写入的响应写回串口，适用于把串口设备桥接到网络的现场网关
+package serial
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/source"
+)
+
+// ErrDeadlineUnsupported 表示按空闲时间分帧时串口不支持SetReadDeadline
+var ErrDeadlineUnsupported = errors.New("serial: idle timeout framing requires SetReadDeadline")
+
+// Port 定义串口接口，例如以原始模式打开的*os.File
+// 波特率、校验位等参数由使用方在打开串口时配置
+// 实现SetReadDeadline(time.Time) error时，ctx被取消后阻塞中的读取立即返回
+type Port interface {
+	io.ReadWriter
+}
+
+// deadlineSetter 定义可以设置读超时的串口
+type deadlineSetter interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// Router 定义串口消息来源需要的路由器功能
+type Router interface {
+	router.RouteResponder
+	router.BufferManagerAccessor
+}
+
+// Option 定义串口消息来源的配置选项
+type Option func(*serialSource)
+
+// WithFramer 设置帧提取器，默认按行分帧
+// 例如frame.NewDelimiterFramer、frame.NewSLIPFramer；framer同时实现frame.Encoder时响应按相同的帧格式编码
+func WithFramer(framer frame.Framer) Option {
+	return func(s *serialSource) {
+		s.framer = framer
+	}
+}
+
+// WithIdleTimeout 按空闲时间分帧：收到数据后超过d没有新数据即认为一帧结束
+// 适用于Modbus RTU等没有分隔符的协议，要求串口实现SetReadDeadline，设置后WithFramer不再生效
+func WithIdleTimeout(d time.Duration) Option {
+	return func(s *serialSource) {
+		s.idle = d
+	}
+}
+
+// WithMaxFrameSize 设置按空闲时间分帧时的最大帧长度，默认frame.DefaultMaxFrameSize
+// 数据达到最大长度时立即作为一帧路由
+func WithMaxFrameSize(n int) Option {
+	return func(s *serialSource) {
+		s.maxSize = n
+	}
+}
+
+// WithName 设置ctx.Metadata()中的来源名称，例如设备路径"/dev/ttyUSB0"
+func WithName(name string) Option {
+	return func(s *serialSource) {
+		s.name = name
+	}
+}
+
+// WithErrorHandler 设置帧处理或响应写入失败时的回调
+func WithErrorHandler(fn source.ErrorHandler) Option {
+	return func(s *serialSource) {
+		s.onError = fn
+	}
+}
+
+// serialSource 是串口消息来源的实现
+type serialSource struct {
+	port    Port
+	router  Router
+	framer  frame.Framer
+	idle    time.Duration
+	maxSize int
+	name    string
+	onError source.ErrorHandler
+}
+
+// New 创建串口消息来源，Run不会关闭串口
+// 每一帧的上下文通过ctx.Metadata()提供传输层名称"serial"、WithName设置的名称和接收时间
+func New(port Port, r Router, opts ...Option) source.Source {
+	s := &serialSource{
+		port:    port,
+		router:  r,
+		framer:  frame.NewLineFramer(0),
+		maxSize: frame.DefaultMaxFrameSize,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run 持续读取并路由设备帧，直到ctx被取消、串口读取失败或读到io.EOF
+// 帧处理失败交给WithErrorHandler设置的回调，不会停止读取
+// 返回: 读到io.EOF时返回nil，在帧中间结束时返回io.ErrUnexpectedEOF
+func (s *serialSource) Run(ctx context.Context) error {
+	ds, canDeadline := s.port.(deadlineSetter)
+	if s.idle > 0 && !canDeadline {
+		return ErrDeadlineUnsupported
+	}
+	if canDeadline {
+		// 上下文被取消时让阻塞中的读取立即返回
+		stop := context.AfterFunc(ctx, func() {
+			ds.SetReadDeadline(time.Unix(1, 0))
+		})
+		defer stop()
+	}
+
+	var readFrame func(buf buffer.Buffer) error
+	var w io.Writer
+	if s.idle > 0 {
+		chunk := make([]byte, min(s.maxSize, 4096))
+		readFrame = func(buf buffer.Buffer) error {
+			return s.readIdleFrame(ctx, ds, chunk, buf)
+		}
+		w = s.port
+	} else {
+		br := bufio.NewReader(s.port)
+		readFrame = func(buf buffer.Buffer) error {
+			return s.framer.ReadFrame(br, buf)
+		}
+		enc, _ := s.framer.(frame.Encoder)
+		w = frame.NewFrameWriter(s.port, enc)
+	}
+
+	manager := s.router.BufferManager()
+	md := router_context.Metadata{Transport: "serial", Source: s.name}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		buf := manager.Acquire()
+		if err := readFrame(buf); err != nil {
+			manager.Release(buf)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		md.ReceivedAt = time.Now()
+		err := s.router.RouteTo(router_context.WithMetadata(ctx, md), buf, w)
+		manager.Release(buf)
+		if err != nil && s.onError != nil {
+			s.onError(err)
+		}
+	}
+}
+
+// readIdleFrame 读取一帧：阻塞直到收到数据，之后超过空闲时间没有新数据即结束
+func (s *serialSource) readIdleFrame(ctx context.Context, ds deadlineSetter, chunk []byte, buf buffer.Buffer) error {
+	ds.SetReadDeadline(time.Time{})
+	// 在设置超时之后检查，避免覆盖取消时设置的过期时间
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for {
+		n, err := s.port.Read(chunk[:min(len(chunk), s.maxSize-buf.Len())])
+		buf.Write(chunk[:n])
+		if buf.Len() >= s.maxSize {
+			return nil
+		}
+		switch {
+		case err == nil:
+		case errors.Is(err, os.ErrDeadlineExceeded) && buf.Len() > 0 && ctx.Err() == nil:
+			return nil
+		case errors.Is(err, io.EOF) && buf.Len() > 0:
+			// 先路由已经收到的数据，下一次读取再返回io.EOF
+			return nil
+		default:
+			return err
+		}
+		if n > 0 {
+			ds.SetReadDeadline(time.Now().Add(s.idle))
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package serial
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/router/routertest"
+)
+
+// newTestRouter 创建把每帧发送到frames的路由器，内容以"ping"开头的帧回复"pong"
+func newTestRouter(manager manage.BufferManager, frames chan<- string) router.Router {
+	return routertest.NewSourceRouter(manager, func(ctx router_context.Context) error {
+		data := string(ctx.Buffer().Get())
+		frames <- data
+		if data == "ping" {
+			ctx.Response().Write([]byte("pong"))
+		}
+		return nil
+	}, routertest.ExpectMetadata(router_context.Metadata{Transport: "serial", Source: "/dev/ttyTEST"}))
+}
+
+// runSource 在后台运行消息来源，返回接收Run结果的通道
+func runSource(ctx context.Context, port Port, r Router, opts ...Option) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		done <- New(port, r, append(opts, WithName("/dev/ttyTEST"))...).Run(ctx)
+	}()
+	return done
+}
+
+// expectFrame 等待下一帧并检查内容
+func expectFrame(t *testing.T, frames <-chan string, want string) {
+	t.Helper()
+	select {
+	case got := <-frames:
+		if got != want {
+			t.Errorf("frame = %q, want %q", got, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for frame %q", want)
+	}
+}
+
+// expectDone 等待Run返回并检查错误
+func expectDone(t *testing.T, done <-chan error, want error) {
+	t.Helper()
+	select {
+	case err := <-done:
+		if !errors.Is(err, want) {
+			t.Errorf("Run() error = %v, want %v", err, want)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for Run to return")
+	}
+}
+
+func TestRunSLIPWithResponse(t *testing.T) {
+	manager := manage.NewBufferManager()
+	frames := make(chan string, 4)
+	port, device := net.Pipe()
+	defer port.Close()
+
+	slip := frame.NewSLIPFramer(0)
+	done := runSource(context.Background(), port, newTestRouter(manager, frames), WithFramer(slip))
+
+	enc := slip.(frame.Encoder)
+	enc.WriteFrame(device, []byte("ping"))
+	expectFrame(t, frames, "ping")
+
+	// 响应按SLIP编码写回设备
+	resp := buffer.NewBuffer()
+	device.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := slip.ReadFrame(bufio.NewReader(device), resp); err != nil || string(resp.Get()) != "pong" {
+		t.Fatalf("response = %q, %v; want pong", resp.Get(), err)
+	}
+
+	enc.WriteFrame(device, []byte("temp=21"))
+	expectFrame(t, frames, "temp=21")
+	device.Close()
+	expectDone(t, done, nil)
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRunIdleTimeout(t *testing.T) {
+	manager := manage.NewBufferManager()
+	frames := make(chan string, 4)
+	port, device := net.Pipe()
+	defer port.Close()
+
+	done := runSource(context.Background(), port, newTestRouter(manager, frames),
+		WithIdleTimeout(50*time.Millisecond), WithMaxFrameSize(8))
+
+	device.Write([]byte{0x01, 0x03})
+	device.Write([]byte{0x00, 0x10})
+	expectFrame(t, frames, "\x01\x03\x00\x10")
+
+	// 达到最大长度时立即作为一帧
+	device.Write([]byte("0123456789"))
+	expectFrame(t, frames, "01234567")
+	expectFrame(t, frames, "89")
+
+	device.Write([]byte("tail"))
+	device.Close()
+	expectFrame(t, frames, "tail")
+	expectDone(t, done, nil)
+	if n := manager.Stats().Outstanding; n != 0 {
+		t.Errorf("outstanding buffers = %d, want 0", n)
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	for _, opts := range [][]Option{nil, {WithIdleTimeout(time.Second)}} {
+		port, device := net.Pipe()
+		ctx, cancel := context.WithCancel(context.Background())
+		done := runSource(ctx, port, newTestRouter(manage.NewBufferManager(), make(chan string, 1)), opts...)
+		cancel()
+		expectDone(t, done, context.Canceled)
+		port.Close()
+		device.Close()
+	}
+}
+
+// plainPort 是不支持SetReadDeadline的串口
+type plainPort struct {
+	io.Reader
+	io.Writer
+}
+
+func TestRunIdleTimeoutRequiresDeadline(t *testing.T) {
+	port := plainPort{Reader: bytes.NewReader(nil), Writer: io.Discard}
+	err := New(port, newTestRouter(manage.NewBufferManager(), nil), WithIdleTimeout(time.Millisecond)).Run(context.Background())
+	if !errors.Is(err, ErrDeadlineUnsupported) {
+		t.Errorf("Run() error = %v, want ErrDeadlineUnsupported", err)
+	}
+}
+
+func TestRunHandlerErrors(t *testing.T) {
+	r := router.NewRouter()
+	r.Match("", func(ctx router_context.Context) error { return errors.New("rejected") })
+
+	var errs []error
+	port := plainPort{Reader: bytes.NewReader([]byte("a\nb\n")), Writer: io.Discard}
+	err := New(port, r, WithErrorHandler(func(err error) { errs = append(errs, err) })).Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(errs) != 2 {
+		t.Errorf("errors = %v, want 2", errs)
+	}
+}
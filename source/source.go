@@ -0,0 +1,27 @@
+// Package source 定义消息来源的公共接口
+// 各子包把外部系统（事件流、消息队列、文件等）接入路由器，每条消息作为一个缓冲区路由
+package source
+
+import (
+	"context"
+
+	"github.com/aomirun/content-router/router"
+)
+
+// Source 定义消息来源接口
+type Source interface {
+	// Run 持续读取消息并路由，直到ctx被取消或遇到无法恢复的错误
+	// 返回: ctx被取消时返回ctx的错误
+	Run(ctx context.Context) error
+}
+
+// Router 定义消息来源需要的路由器功能
+// 消息缓冲区从路由器的BufferManager获取，路由完成后释放
+type Router interface {
+	router.RouteHandler
+	router.BufferManagerAccessor
+}
+
+// ErrorHandler 定义单条消息处理失败时的回调
+// 处理失败不会停止消息来源
+type ErrorHandler func(err error)
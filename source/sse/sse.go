@@ -0,0 +1,245 @@
+// Package sse 提供Server-Sent Events消息来源
+// 订阅上游事件流，把每个事件的数据作为缓冲区路由
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/source"
+)
+
+// DefaultRetry 是连接断开后重新连接前的默认等待时间，服务端可以通过retry字段修改
+const DefaultRetry = 3 * time.Second
+
+// Event 定义事件的元数据，事件数据作为缓冲区路由
+type Event struct {
+	// ID 事件标识，重新连接时通过Last-Event-ID请求头发送最后收到的标识
+	ID string
+	// Name 事件名称，没有event字段时为"message"
+	Name string
+}
+
+// eventKey 是事件元数据在标准context中的键
+type eventKey struct{}
+
+// EventFromContext 获取事件元数据，处理器可以直接传入路由上下文
+func EventFromContext(ctx context.Context) (Event, bool) {
+	ev, ok := ctx.Value(eventKey{}).(Event)
+	return ev, ok
+}
+
+// Option 定义SSE消息来源的配置选项
+type Option func(*sseSource)
+
+// WithClient 设置发起订阅请求使用的http.Client，默认使用http.DefaultClient
+func WithClient(client *http.Client) Option {
+	return func(s *sseSource) {
+		if client != nil {
+			s.client = client
+		}
+	}
+}
+
+// WithHeader 为订阅请求添加请求头，例如用于认证
+func WithHeader(key, value string) Option {
+	return func(s *sseSource) {
+		s.header.Add(key, value)
+	}
+}
+
+// WithRetry 设置重新连接前的等待时间，默认DefaultRetry
+func WithRetry(d time.Duration) Option {
+	return func(s *sseSource) {
+		if d > 0 {
+			s.retry = d
+		}
+	}
+}
+
+// WithErrorHandler 设置事件处理失败或连接断开时的回调
+func WithErrorHandler(fn source.ErrorHandler) Option {
+	return func(s *sseSource) {
+		s.onError = fn
+	}
+}
+
+// sseSource 是SSE消息来源的实现
+type sseSource struct {
+	url     string
+	router  source.Router
+	client  *http.Client
+	header  http.Header
+	retry   time.Duration
+	onError source.ErrorHandler
+
+	lastID string
+}
+
+// New 创建订阅url的SSE消息来源
+// 连接断开后按重试间隔自动重新连接，服务端返回204 No Content时停止
+//
+// 每个事件的上下文通过EventFromContext提供事件名称和标识，
+// 通过ctx.Metadata()提供传输层名称"sse"、来源url和接收时间
+func New(url string, r source.Router, opts ...Option) source.Source {
+	s := &sseSource{
+		url:    url,
+		router: r,
+		client: http.DefaultClient,
+		header: make(http.Header),
+		retry:  DefaultRetry,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// errStop 表示服务端要求停止重新连接
+var errStop = errors.New("sse: server requested stop")
+
+// Run 订阅事件流并路由每个事件
+func (s *sseSource) Run(ctx context.Context) error {
+	for {
+		err := s.subscribe(ctx)
+		if errors.Is(err, errStop) {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.report(err)
+
+		timer := time.NewTimer(s.retry)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// subscribe 建立一次订阅并读取事件，直到连接断开
+func (s *sseSource) subscribe(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header = s.header.Clone()
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	if s.lastID != "" {
+		req.Header.Set("Last-Event-ID", s.lastID)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		return errStop
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("sse: unexpected status %s", resp.Status)
+	}
+	return s.readEvents(ctx, bufio.NewReader(resp.Body))
+}
+
+// readEvents 按事件流格式解析事件并路由
+func (s *sseSource) readEvents(ctx context.Context, r *bufio.Reader) error {
+	manager := s.router.BufferManager()
+	buf := manager.Acquire()
+	defer func() { manager.Release(buf) }()
+
+	name := ""
+	hasData := false
+	for {
+		line, err := r.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			// 超长的行拼接完整后再解析
+			long := bytes.Clone(line)
+			for err == bufio.ErrBufferFull {
+				line, err = r.ReadSlice('\n')
+				long = append(long, line...)
+			}
+			line = long
+		}
+		if err != nil {
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+
+		// 空行表示事件结束
+		if len(line) == 0 {
+			if hasData {
+				s.dispatch(ctx, Event{ID: s.lastID, Name: name}, buf)
+				manager.Release(buf)
+				buf = manager.Acquire()
+			}
+			name, hasData = "", false
+			continue
+		}
+		if line[0] == ':' {
+			continue // 注释
+		}
+
+		field, value, _ := bytes.Cut(line, []byte(":"))
+		value = bytes.TrimPrefix(value, []byte(" "))
+		switch string(field) {
+		case "data":
+			if hasData {
+				buf.Write([]byte{'\n'})
+			}
+			buf.Write(value)
+			hasData = true
+		case "event":
+			name = string(value)
+		case "id":
+			if bytes.IndexByte(value, 0) < 0 {
+				s.lastID = string(value)
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(string(value)); err == nil && ms >= 0 {
+				s.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
+
+// dispatch 路由一个事件
+func (s *sseSource) dispatch(ctx context.Context, ev Event, buf buffer.Buffer) {
+	if ev.Name == "" {
+		ev.Name = "message"
+	}
+	ctx = context.WithValue(ctx, eventKey{}, ev)
+	ctx = router_context.WithMetadata(ctx, router_context.Metadata{
+		Transport:  "sse",
+		Source:     s.url,
+		ReceivedAt: time.Now(),
+	})
+	if _, err := s.router.Route(ctx, buf); err != nil {
+		s.report(err)
+	}
+}
+
+// report 调用错误回调
+func (s *sseSource) report(err error) {
+	if err != nil && s.onError != nil {
+		s.onError(err)
+	}
+}
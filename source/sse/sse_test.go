@@ -0,0 +1,106 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+)
+
+func TestSSESource(t *testing.T) {
+	var mu sync.Mutex
+	var lastIDs []string
+	connections := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		connections++
+		n := connections
+		lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+		mu.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Missing custom header")
+		}
+		switch n {
+		case 1:
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, ": comment\n")
+			fmt.Fprint(w, "retry: 10\n")
+			fmt.Fprint(w, "event: order\nid: 1\ndata: {\"id\":1}\n\n")
+			fmt.Fprint(w, "data: line one\r\ndata: line two\r\n\r\n")
+		default:
+			// 第二次连接时要求客户端停止
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	manager := manage.NewBufferManager()
+	r := router.NewRouter(router.WithBufferManager(manager))
+	type received struct {
+		event Event
+		data  string
+		md    router_context.Metadata
+	}
+	var got []received
+	r.Match("", func(ctx router_context.Context) error {
+		ev, _ := EventFromContext(ctx)
+		got = append(got, received{ev, string(ctx.Buffer().Get()), ctx.Metadata()})
+		return nil
+	})
+
+	var errs []error
+	src := New(server.URL, r,
+		WithHeader("Authorization", "Bearer token"),
+		WithErrorHandler(func(err error) { errs = append(errs, err) }))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := src.Run(ctx); err != nil {
+		t.Fatalf("Run should stop cleanly on 204, got %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(got))
+	}
+	if got[0].event != (Event{ID: "1", Name: "order"}) || got[0].data != `{"id":1}` {
+		t.Errorf("Unexpected first event %+v", got[0])
+	}
+	if got[1].event != (Event{ID: "1", Name: "message"}) || got[1].data != "line one\nline two" {
+		t.Errorf("Unexpected second event %+v", got[1])
+	}
+	if got[0].md.Transport != "sse" || got[0].md.Source != server.URL {
+		t.Errorf("Unexpected metadata %+v", got[0].md)
+	}
+	if len(lastIDs) != 2 || lastIDs[1] != "1" {
+		t.Errorf("Reconnect should send Last-Event-ID, got %q", lastIDs)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Disconnect should be reported once, got %v", errs)
+	}
+	if stats := manager.Stats(); stats.Outstanding != 0 {
+		t.Errorf("Every buffer should be released, got %+v", stats)
+	}
+}
+
+func TestSSESourceCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	src := New(server.URL, router.NewRouter(), WithRetry(time.Hour), WithErrorHandler(func(err error) {
+		cancel()
+	}))
+	if err := src.Run(ctx); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
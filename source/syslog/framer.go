@@ -0,0 +1,68 @@
+package syslog
+
+import (
+	"bufio"
+	"io"
+
+	"github.com/aomirun/content-router/buffer"
+	"github.com/aomirun/content-router/frame"
+)
+
+// maxLengthDigits 是长度前缀允许的最大位数
+const maxLengthDigits = 9
+
+// octetFramer 是syslog over TCP的Framer，实现RFC6587的两种分帧方式
+type octetFramer struct {
+	maxSize int
+	lines   frame.Framer
+}
+
+// NewFramer 创建syslog over TCP的Framer
+// 以数字开头的帧按"长度 空格 消息"的长度前缀方式读取，其余按换行分帧
+//   - maxSize: 消息的最大长度，小于等于0时使用frame.DefaultMaxFrameSize
+func NewFramer(maxSize int) frame.Framer {
+	if maxSize <= 0 {
+		maxSize = frame.DefaultMaxFrameSize
+	}
+	return &octetFramer{maxSize: maxSize, lines: frame.NewLineFramer(maxSize)}
+}
+
+// ReadFrame 读取下一条消息
+func (f *octetFramer) ReadFrame(r *bufio.Reader, buf buffer.Buffer) error {
+	first, err := r.Peek(1)
+	if err != nil {
+		return err
+	}
+	if first[0] < '1' || first[0] > '9' {
+		return f.lines.ReadFrame(r, buf)
+	}
+
+	length := 0
+	for digits := 0; ; digits++ {
+		c, err := r.ReadByte()
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return err
+		}
+		if c == ' ' {
+			break
+		}
+		if c < '0' || c > '9' || digits == maxLengthDigits {
+			return ErrMalformed
+		}
+		length = length*10 + int(c-'0')
+	}
+	if length > f.maxSize {
+		return frame.ErrFrameTooLarge
+	}
+
+	if _, err := io.CopyN(buf, r, int64(length)); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	return nil
+}
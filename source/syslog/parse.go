@@ -0,0 +1,217 @@
+package syslog
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMalformed 表示消息不是有效的syslog格式
+var ErrMalformed = errors.New("syslog: malformed message")
+
+// nilValue 是RFC5424中表示字段为空的值
+const nilValue = "-"
+
+// Message 定义syslog消息的信封，消息正文作为缓冲区路由
+type Message struct {
+	// Facility 设施代码（0-23）
+	Facility int
+	// Severity 严重级别（0-7），0为Emergency，7为Debug
+	Severity int
+	// Version 协议版本，RFC5424消息为1，RFC3164消息为0
+	Version int
+	// Timestamp 消息时间，缺失时为零值；RFC3164时间戳没有年份，使用当前年份
+	Timestamp time.Time
+	Hostname  string
+	AppName   string
+	ProcID    string
+	// MsgID 消息类型标识，只有RFC5424消息才有
+	MsgID string
+	// StructuredData 结构化数据，SD-ID到参数的映射，只有RFC5424消息才有
+	StructuredData map[string]map[string]string
+}
+
+// Parse 解析RFC5424或RFC3164格式的syslog消息
+// 返回: 信封和消息正文（引用data的内存，RFC5424正文开头的BOM会被去掉）
+func Parse(data []byte) (Message, []byte, error) {
+	var msg Message
+	rest, err := parsePriority(data, &msg)
+	if err != nil {
+		return msg, nil, err
+	}
+	if len(rest) >= 2 && rest[0] >= '1' && rest[0] <= '9' && (rest[1] == ' ' || rest[1] >= '0' && rest[1] <= '9') {
+		return parse5424(rest, msg)
+	}
+	body := parse3164(rest, &msg, time.Now())
+	return msg, body, nil
+}
+
+// parsePriority 解析"<PRI>"部分
+func parsePriority(data []byte, msg *Message) ([]byte, error) {
+	if len(data) < 3 || data[0] != '<' {
+		return nil, ErrMalformed
+	}
+	end := bytes.IndexByte(data[:min(len(data), 5)], '>')
+	if end < 2 {
+		return nil, ErrMalformed
+	}
+	pri, err := strconv.Atoi(string(data[1:end]))
+	if err != nil || pri < 0 || pri > 191 {
+		return nil, ErrMalformed
+	}
+	msg.Facility, msg.Severity = pri/8, pri%8
+	return data[end+1:], nil
+}
+
+// parse5424 解析RFC5424格式中PRI之后的部分
+func parse5424(data []byte, msg Message) (Message, []byte, error) {
+	fields := make([]string, 6)
+	for i := range fields {
+		var field []byte
+		field, data, _ = bytes.Cut(data, []byte{' '})
+		fields[i] = string(field)
+	}
+	version, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return msg, nil, ErrMalformed
+	}
+	msg.Version = version
+	if fields[1] != nilValue {
+		if msg.Timestamp, err = time.Parse(time.RFC3339Nano, fields[1]); err != nil {
+			return msg, nil, ErrMalformed
+		}
+	}
+	msg.Hostname = optional(fields[2])
+	msg.AppName = optional(fields[3])
+	msg.ProcID = optional(fields[4])
+	msg.MsgID = optional(fields[5])
+
+	body, err := parseStructuredData(data, &msg)
+	if err != nil {
+		return msg, nil, err
+	}
+	return msg, bytes.TrimPrefix(body, []byte("\xEF\xBB\xBF")), nil
+}
+
+// parseStructuredData 解析结构化数据，返回其后的消息正文
+func parseStructuredData(data []byte, msg *Message) ([]byte, error) {
+	if len(data) > 0 && data[0] == '-' {
+		return skipSpace(data[1:]), nil
+	}
+	for len(data) > 0 && data[0] == '[' {
+		end := bytes.IndexAny(data, " ]")
+		if end < 2 {
+			return nil, ErrMalformed
+		}
+		id := string(data[1:end])
+		params := make(map[string]string)
+		data = data[end:]
+
+		for len(data) > 0 && data[0] == ' ' {
+			eq := bytes.IndexByte(data, '=')
+			if eq < 2 || eq+1 >= len(data) || data[eq+1] != '"' {
+				return nil, ErrMalformed
+			}
+			name := string(data[1:eq])
+			value, rest, err := parseParamValue(data[eq+2:])
+			if err != nil {
+				return nil, err
+			}
+			params[name] = value
+			data = rest
+		}
+		if len(data) == 0 || data[0] != ']' {
+			return nil, ErrMalformed
+		}
+		data = data[1:]
+
+		if msg.StructuredData == nil {
+			msg.StructuredData = make(map[string]map[string]string)
+		}
+		msg.StructuredData[id] = params
+	}
+	return skipSpace(data), nil
+}
+
+// parseParamValue 解析引号内的参数值，处理\"、\\和\]转义
+func parseParamValue(data []byte) (string, []byte, error) {
+	var b strings.Builder
+	for i := 0; i < len(data); i++ {
+		switch c := data[i]; c {
+		case '\\':
+			if i+1 < len(data) && (data[i+1] == '"' || data[i+1] == '\\' || data[i+1] == ']') {
+				i++
+				b.WriteByte(data[i])
+			} else {
+				b.WriteByte(c)
+			}
+		case '"':
+			return b.String(), data[i+1:], nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", nil, ErrMalformed
+}
+
+// parse3164 尽量解析RFC3164格式中PRI之后的部分，无法识别的内容作为正文
+func parse3164(data []byte, msg *Message, now time.Time) []byte {
+	if len(data) >= len(time.Stamp) {
+		if ts, err := time.ParseInLocation(time.Stamp, string(data[:len(time.Stamp)]), now.Location()); err == nil {
+			msg.Timestamp = ts.AddDate(now.Year(), 0, 0)
+			// 跨年时收到上一年末尾的消息
+			if msg.Timestamp.After(now.Add(24 * time.Hour)) {
+				msg.Timestamp = msg.Timestamp.AddDate(-1, 0, 0)
+			}
+			data = skipSpace(data[len(time.Stamp):])
+
+			var host []byte
+			host, rest, ok := bytes.Cut(data, []byte{' '})
+			if ok && !bytes.HasSuffix(host, []byte{':'}) {
+				msg.Hostname = string(host)
+				data = rest
+			}
+		}
+	}
+
+	// TAG是由字母数字组成的程序名，后面可以跟"[PID]"，以":"结束
+	i := 0
+	for i < len(data) && i < 48 && data[i] != '[' && data[i] != ':' && data[i] != ' ' {
+		i++
+	}
+	if i == 0 || i >= len(data) || (data[i] != '[' && data[i] != ':') {
+		return data
+	}
+	tag, rest := string(data[:i]), data[i:]
+	procID := ""
+	if rest[0] == '[' {
+		end := bytes.IndexByte(rest, ']')
+		if end < 0 {
+			return data
+		}
+		procID, rest = string(rest[1:end]), rest[end+1:]
+	}
+	if len(rest) > 0 && rest[0] == ':' {
+		rest = rest[1:]
+	}
+	msg.AppName, msg.ProcID = tag, procID
+	return skipSpace(rest)
+}
+
+// optional 把NILVALUE转换为空字符串
+func optional(s string) string {
+	if s == nilValue {
+		return ""
+	}
+	return s
+}
+
+// skipSpace 跳过开头的一个空格
+func skipSpace(data []byte) []byte {
+	if len(data) > 0 && data[0] == ' ' {
+		return data[1:]
+	}
+	return data
+}
@@ -0,0 +1,72 @@
+package syslog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse5424(t *testing.T) {
+	data := []byte(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="App\"lication" eventID="1011"][examplePriority@32473 class="high"] ` + "\xEF\xBB\xBF" + `An application event log entry...`)
+	msg, body, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if msg.Facility != 20 || msg.Severity != 5 || msg.Version != 1 {
+		t.Errorf("Unexpected priority %+v", msg)
+	}
+	if want := time.Date(2003, 10, 11, 22, 14, 15, 3e6, time.UTC); !msg.Timestamp.Equal(want) {
+		t.Errorf("Expected timestamp %v, got %v", want, msg.Timestamp)
+	}
+	if msg.Hostname != "mymachine.example.com" || msg.AppName != "evntslog" || msg.ProcID != "" || msg.MsgID != "ID47" {
+		t.Errorf("Unexpected header %+v", msg)
+	}
+	if msg.StructuredData["exampleSDID@32473"]["eventSource"] != `App"lication` ||
+		msg.StructuredData["examplePriority@32473"]["class"] != "high" {
+		t.Errorf("Unexpected structured data %v", msg.StructuredData)
+	}
+	if string(body) != "An application event log entry..." {
+		t.Errorf("Unexpected body %q", body)
+	}
+}
+
+func TestParse5424NilValues(t *testing.T) {
+	msg, body, err := Parse([]byte("<34>1 - - - - - -"))
+	if err != nil || len(body) != 0 || !msg.Timestamp.IsZero() || msg.Hostname != "" || msg.StructuredData != nil {
+		t.Errorf("Unexpected result %+v %q %v", msg, body, err)
+	}
+}
+
+func TestParse3164(t *testing.T) {
+	now := time.Date(2024, 10, 12, 0, 0, 0, 0, time.UTC)
+	var msg Message
+	body := parse3164([]byte("Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick"), &msg, now)
+	if string(body) != "'su root' failed for lonvick" {
+		t.Errorf("Unexpected body %q", body)
+	}
+	if msg.Hostname != "mymachine" || msg.AppName != "su" || msg.ProcID != "123" {
+		t.Errorf("Unexpected header %+v", msg)
+	}
+	if want := time.Date(2024, 10, 11, 22, 14, 15, 0, time.UTC); !msg.Timestamp.Equal(want) {
+		t.Errorf("Expected timestamp %v, got %v", want, msg.Timestamp)
+	}
+
+	// 一月初收到上一年十二月的消息
+	msg = Message{}
+	parse3164([]byte("Dec 31 23:59:59 host app: bye"), &msg, time.Date(2025, 1, 1, 0, 0, 5, 0, time.UTC))
+	if msg.Timestamp.Year() != 2024 {
+		t.Errorf("Expected previous year, got %v", msg.Timestamp)
+	}
+}
+
+func TestParseLenient(t *testing.T) {
+	msg, body, err := Parse([]byte("<13>just some text"))
+	if err != nil || string(body) != "just some text" || msg.Severity != 5 || msg.Facility != 1 {
+		t.Errorf("Unexpected result %+v %q %v", msg, body, err)
+	}
+
+	for _, data := range []string{"no priority", "<999>1 - - - - - -", "<34>1 bad-time - - - - -", `<34>1 - - - - - [id a="unterminated`} {
+		if _, _, err := Parse([]byte(data)); err != ErrMalformed {
+			t.Errorf("Parse(%q) should fail, got %v", data, err)
+		}
+	}
+}
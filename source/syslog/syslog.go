@@ -0,0 +1,186 @@
+// Package syslog 提供syslog消息来源
+// 通过UDP和TCP接收RFC5424或RFC3164格式的消息，把信封解析为上下文元数据，
+// 把消息正文作为缓冲区路由，让路由器可以直接作为日志管道的入口
+package syslog
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/source"
+	"github.com/aomirun/content-router/transport"
+)
+
+// messageKey 是信封在标准context中的键
+type messageKey struct{}
+
+// MessageFromContext 获取消息的信封，处理器可以直接传入路由上下文
+// 无法解析的消息原样路由，此时返回false
+func MessageFromContext(ctx context.Context) (Message, bool) {
+	msg, ok := ctx.Value(messageKey{}).(Message)
+	return msg, ok
+}
+
+// Option 定义syslog消息来源的配置选项
+type Option func(*syslogSource)
+
+// WithUDP 在addr上接收UDP消息，例如":514"
+func WithUDP(addr string) Option {
+	return func(s *syslogSource) {
+		s.udpAddrs = append(s.udpAddrs, addr)
+	}
+}
+
+// WithTCP 在addr上接收TCP消息，同时支持按长度前缀和按换行分帧
+func WithTCP(addr string) Option {
+	return func(s *syslogSource) {
+		s.tcpAddrs = append(s.tcpAddrs, addr)
+	}
+}
+
+// WithPacketConn 在已经打开的pc上接收数据报，例如由systemd传入的套接字
+func WithPacketConn(pc net.PacketConn) Option {
+	return func(s *syslogSource) {
+		s.packetConns = append(s.packetConns, pc)
+	}
+}
+
+// WithListener 在已经打开的l上接受连接
+func WithListener(l net.Listener) Option {
+	return func(s *syslogSource) {
+		s.listeners = append(s.listeners, l)
+	}
+}
+
+// WithMaxMessageSize 设置TCP消息的最大长度，默认frame.DefaultMaxFrameSize
+func WithMaxMessageSize(n int) Option {
+	return func(s *syslogSource) {
+		s.maxSize = n
+	}
+}
+
+// WithErrorHandler 设置消息处理失败时的回调
+// 处理失败不会关闭TCP连接
+func WithErrorHandler(fn source.ErrorHandler) Option {
+	return func(s *syslogSource) {
+		s.onError = fn
+	}
+}
+
+// syslogSource 是syslog消息来源的实现
+type syslogSource struct {
+	router      source.Router
+	udpAddrs    []string
+	tcpAddrs    []string
+	packetConns []net.PacketConn
+	listeners   []net.Listener
+	maxSize     int
+	onError     source.ErrorHandler
+}
+
+// New 创建syslog消息来源，至少需要通过选项指定一个监听地址
+// 每条消息的上下文通过MessageFromContext提供信封，
+// 通过ctx.Metadata()提供传输层名称（"udp"或"tcp"）、对端地址和接收时间
+func New(r source.Router, opts ...Option) source.Source {
+	s := &syslogSource{router: r}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run 监听所有地址并路由收到的消息，直到ctx被取消或某个监听器失败
+func (s *syslogSource) Run(ctx context.Context) error {
+	packetConns := s.packetConns
+	listeners := s.listeners
+	closeAll := func() {
+		for _, pc := range packetConns {
+			pc.Close()
+		}
+		for _, l := range listeners {
+			l.Close()
+		}
+	}
+	for _, addr := range s.udpAddrs {
+		pc, err := net.ListenPacket("udp", addr)
+		if err != nil {
+			closeAll()
+			return err
+		}
+		packetConns = append(packetConns, pc)
+	}
+	for _, addr := range s.tcpAddrs {
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			closeAll()
+			return err
+		}
+		listeners = append(listeners, l)
+	}
+	if len(packetConns) == 0 && len(listeners) == 0 {
+		return errors.New("syslog: no listen address")
+	}
+
+	envelope := NewEnvelopeRouter(s.router, s.onError)
+	opts := []transport.Option{transport.WithBaseContext(ctx)}
+	udp := transport.NewUDPServer(envelope, opts...)
+	tcp := transport.NewTCPServer(envelope, NewFramer(s.maxSize), opts...)
+
+	errc := make(chan error, len(packetConns)+len(listeners))
+	var wg sync.WaitGroup
+	for _, pc := range packetConns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errc <- udp.Serve(pc)
+		}()
+	}
+	for _, l := range listeners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errc <- tcp.Serve(l)
+		}()
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+		err = ctx.Err()
+	case err = <-errc:
+	}
+	shutdownCtx := context.WithoutCancel(ctx)
+	udp.Shutdown(shutdownCtx)
+	tcp.Shutdown(shutdownCtx)
+	wg.Wait()
+	return err
+}
+
+// NewEnvelopeRouter 返回解析syslog信封后把正文交给r路由的路由器
+// 可以直接交给transport包的服务器，用于自定义监听方式
+//   - onError: 消息处理失败时的回调，为nil时忽略；处理失败不会作为错误返回，以免关闭TCP连接
+func NewEnvelopeRouter(r source.Router, onError source.ErrorHandler) router.Router {
+	manager := r.BufferManager()
+	envelope := router.NewRouter(router.WithBufferManager(manager))
+	envelope.Register(router.MatcherFunc(func(router_context.Context) bool { return true }), func(ctx router_context.Context) error {
+		var parent context.Context = ctx
+		body := ctx.Buffer().Get()
+		if msg, b, err := Parse(body); err == nil {
+			parent = context.WithValue(ctx, messageKey{}, msg)
+			body = b
+		}
+
+		buf := manager.Acquire()
+		defer manager.Release(buf)
+		buf.Write(body)
+		if _, err := r.Route(parent, buf); err != nil && onError != nil {
+			onError(err)
+		}
+		return nil
+	})
+	return envelope
+}
@@ -0,0 +1,127 @@
+package syslog
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
+	"github.com/aomirun/content-router/router"
+)
+
+// received 记录收到的消息
+type received struct {
+	msg       Message
+	ok        bool
+	body      string
+	transport string
+}
+
+func TestSyslogSource(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch := make(chan received, 8)
+	r := router.NewRouter()
+	r.Match("", func(ctx router_context.Context) error {
+		msg, ok := MessageFromContext(ctx)
+		ch <- received{msg, ok, string(ctx.Buffer().Get()), ctx.Metadata().Transport}
+		if ok && msg.AppName == "fail" {
+			return fmt.Errorf("rejected")
+		}
+		return nil
+	})
+
+	errs := make(chan error, 8)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	src := New(r, WithPacketConn(pc), WithListener(l), WithErrorHandler(func(err error) { errs <- err }))
+	go func() { done <- src.Run(ctx) }()
+
+	next := func() received {
+		t.Helper()
+		select {
+		case rec := <-ch:
+			return rec
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for message")
+			return received{}
+		}
+	}
+
+	udp, err := net.Dial("udp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udp.Close()
+	udp.Write([]byte("<34>1 2024-01-02T03:04:05Z host app 42 - - hello udp"))
+	if rec := next(); !rec.ok || rec.body != "hello udp" || rec.msg.ProcID != "42" || rec.transport != "udp" {
+		t.Errorf("Unexpected UDP message %+v", rec)
+	}
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	octet := "<13>1 - host fail - - - first"
+	fmt.Fprintf(conn, "%d %s", len(octet), octet)
+	fmt.Fprint(conn, "<13>Oct 11 22:14:15 host app: second\n")
+	fmt.Fprint(conn, "not syslog\n")
+
+	if rec := next(); rec.body != "first" || rec.transport != "tcp" {
+		t.Errorf("Unexpected octet-counted message %+v", rec)
+	}
+	if rec := next(); rec.body != "second" || rec.msg.AppName != "app" {
+		t.Errorf("Handler errors should not close the connection, got %+v", rec)
+	}
+	if rec := next(); rec.ok || rec.body != "not syslog" {
+		t.Errorf("Unparseable messages should be routed as is, got %+v", rec)
+	}
+	if err := <-errs; err.Error() != "rejected" {
+		t.Errorf("Expected handler error to be reported, got %v", err)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestFramer(t *testing.T) {
+	f := NewFramer(16)
+	r := bufio.NewReader(strings.NewReader("5 hello3 abcline\r\n"))
+	for _, want := range []string{"hello", "abc", "line"} {
+		buf := buffer.NewBuffer()
+		if err := f.ReadFrame(r, buf); err != nil || string(buf.Get()) != want {
+			t.Fatalf("Expected %q, got %q, %v", want, buf.Get(), err)
+		}
+	}
+	if err := f.ReadFrame(r, buffer.NewBuffer()); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+
+	for input, want := range map[string]error{
+		"9 x":      io.ErrUnexpectedEOF,
+		"12x3 abc": ErrMalformed,
+		"17 ":      frame.ErrFrameTooLarge,
+	} {
+		err := f.ReadFrame(bufio.NewReader(strings.NewReader(input)), buffer.NewBuffer())
+		if err != want {
+			t.Errorf("ReadFrame(%q) = %v, want %v", input, err, want)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package testutil
+
+import (
+	"bytes"
+
+	"github.com/aomirun/content-router/buffer"
+)
+
+// FakeBuffer 是记录调用、可以配置写入行为的buffer.Buffer实现
+// 数据保存在普通的字节切片中，行为与buffer.NewBuffer()一致
+type FakeBuffer struct {
+	CallRecorder
+
+	// Data 缓冲区内容，可以在测试中直接读写
+	Data []byte
+	// WriteErr 不为nil时Write和WriteString不写入并返回该错误
+	WriteErr error
+	// MaxLen 大于0时写入后长度超过该值的写入返回buffer.ErrTooLarge
+	MaxLen int
+}
+
+// NewFakeBuffer 创建内容为data的FakeBuffer
+func NewFakeBuffer(data string) *FakeBuffer {
+	return &FakeBuffer{Data: []byte(data)}
+}
+
+// Get 获取底层字节数组的引用
+func (b *FakeBuffer) Get() []byte {
+	b.record("Get")
+	return b.Data
+}
+
+// Len 获取当前有效数据长度
+func (b *FakeBuffer) Len() int {
+	b.record("Len")
+	return len(b.Data)
+}
+
+// Cap 获取缓冲区容量
+func (b *FakeBuffer) Cap() int {
+	b.record("Cap")
+	return cap(b.Data)
+}
+
+// Write 写入数据，按WriteErr和MaxLen的配置返回错误
+func (b *FakeBuffer) Write(p []byte) (n int, err error) {
+	b.record("Write", bytes.Clone(p))
+	if err := b.checkWrite(len(p)); err != nil {
+		return 0, err
+	}
+	b.Data = append(b.Data, p...)
+	return len(p), nil
+}
+
+// WriteString 写入字符串，按WriteErr和MaxLen的配置返回错误
+func (b *FakeBuffer) WriteString(s string) (n int, err error) {
+	b.record("WriteString", s)
+	if err := b.checkWrite(len(s)); err != nil {
+		return 0, err
+	}
+	b.Data = append(b.Data, s...)
+	return len(s), nil
+}
+
+// checkWrite 检查是否允许写入n个字节
+func (b *FakeBuffer) checkWrite(n int) error {
+	if b.WriteErr != nil {
+		return b.WriteErr
+	}
+	if b.MaxLen > 0 && len(b.Data)+n > b.MaxLen {
+		return buffer.ErrTooLarge
+	}
+	return nil
+}
+
+// Reset 清空内容
+func (b *FakeBuffer) Reset() {
+	b.record("Reset")
+	b.Data = b.Data[:0]
+}
+
+// Truncate 将缓冲区截断到指定长度
+func (b *FakeBuffer) Truncate(n int) {
+	b.record("Truncate", n)
+	if n >= 0 && n < len(b.Data) {
+		b.Data = b.Data[:n]
+	}
+}
+
+// Slice 返回共享数据的FakeBuffer，它有独立的调用记录，并继承写入行为的配置
+func (b *FakeBuffer) Slice(start, end int) buffer.Buffer {
+	b.record("Slice", start, end)
+	return &FakeBuffer{
+		Data:     b.Data[start:end:end],
+		WriteErr: b.WriteErr,
+		MaxLen:   b.MaxLen,
+	}
+}
+
+// Clone 返回数据的深拷贝，它有独立的调用记录，并继承写入行为的配置
+func (b *FakeBuffer) Clone() buffer.Buffer {
+	b.record("Clone")
+	return &FakeBuffer{
+		Data:     bytes.Clone(b.Data),
+		WriteErr: b.WriteErr,
+		MaxLen:   b.MaxLen,
+	}
+}
+
+// String 返回缓冲区内容，不记录调用
+func (b *FakeBuffer) String() string {
+	return string(b.Data)
+}
@@ -0,0 +1,65 @@
+// Package testutil 提供Buffer、Context和BufferManager的测试替身
+// 替身的行为可以配置，并记录方法调用，供使用方的单元测试直接使用，
+// 不必各自实现模拟的缓冲区和上下文
+package testutil
+
+import "sync"
+
+// Call 记录一次方法调用
+type Call struct {
+	// Method 方法名，例如"Write"
+	Method string
+	// Args 调用参数，切片参数会被复制
+	Args []any
+}
+
+// CallRecorder 记录方法调用，嵌入到各个测试替身中
+// 可以在多个goroutine中并发使用
+type CallRecorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Calls 返回所有调用，按调用顺序排列
+func (r *CallRecorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// CallsTo 返回指定方法的调用，按调用顺序排列
+func (r *CallRecorder) CallsTo(method string) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var calls []Call
+	for _, call := range r.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// CallCount 返回指定方法的调用次数
+func (r *CallRecorder) CallCount(method string) int {
+	return len(r.CallsTo(method))
+}
+
+// Called 判断指定方法是否被调用过
+func (r *CallRecorder) Called(method string) bool {
+	return r.CallCount(method) > 0
+}
+
+// ResetCalls 清空调用记录
+func (r *CallRecorder) ResetCalls() {
+	r.mu.Lock()
+	r.calls = nil
+	r.mu.Unlock()
+}
+
+// record 记录一次调用
+func (r *CallRecorder) record(method string, args ...any) {
+	r.mu.Lock()
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+	r.mu.Unlock()
+}
@@ -0,0 +1,197 @@
+package testutil
+
+import (
+	"context"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+)
+
+// FakeContext 是记录修改操作的router_context.Context实现
+// 它包装一个真实的上下文，读取操作的行为与路由器中完全一致，
+// 修改上下文状态的方法（Set、Abort、SetParam等）会被记录，可以直接传给处理器和中间件进行单元测试
+type FakeContext struct {
+	router_context.Context
+	CallRecorder
+
+	// Manager 提供响应缓冲区
+	Manager *FakeBufferManager
+}
+
+// ContextOption 定义FakeContext的配置选项
+type ContextOption func(*contextConfig)
+
+// contextConfig 保存FakeContext的配置
+type contextConfig struct {
+	parent   context.Context
+	manager  *FakeBufferManager
+	route    *router_context.RouteInfo
+	metadata *router_context.Metadata
+	values   [][2]any
+	params   [][2]string
+}
+
+// WithParent 设置父上下文，用于测试截止时间、取消和父上下文中的值
+func WithParent(ctx context.Context) ContextOption {
+	return func(c *contextConfig) {
+		c.parent = ctx
+	}
+}
+
+// WithManager 设置提供响应缓冲区的FakeBufferManager，默认新建一个
+func WithManager(m *FakeBufferManager) ContextOption {
+	return func(c *contextConfig) {
+		c.manager = m
+	}
+}
+
+// WithRoute 设置匹配到的路由，模拟路由器在调用处理器之前的状态
+func WithRoute(info router_context.RouteInfo) ContextOption {
+	return func(c *contextConfig) {
+		c.route = &info
+	}
+}
+
+// WithMetadata 设置传输层元数据
+func WithMetadata(md router_context.Metadata) ContextOption {
+	return func(c *contextConfig) {
+		c.metadata = &md
+	}
+}
+
+// WithValue 设置初始的键值，不计入调用记录
+func WithValue(key, value any) ContextOption {
+	return func(c *contextConfig) {
+		c.values = append(c.values, [2]any{key, value})
+	}
+}
+
+// WithParam 设置初始的匹配参数，不计入调用记录
+func WithParam(name, value string) ContextOption {
+	return func(c *contextConfig) {
+		c.params = append(c.params, [2]string{name, value})
+	}
+}
+
+// NewFakeContext 创建关联buf的FakeContext
+//   - buf: 上下文的缓冲区，可以是FakeBuffer，为nil时使用空的FakeBuffer
+//   - opts: 配置选项
+func NewFakeContext(buf buffer.Buffer, opts ...ContextOption) *FakeContext {
+	cfg := contextConfig{parent: context.Background()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if buf == nil {
+		buf = &FakeBuffer{}
+	}
+	if cfg.manager == nil {
+		cfg.manager = NewFakeBufferManager()
+	}
+
+	inner := router_context.NewContext(cfg.parent, buf)
+	router_context.SetBufferProvider(inner, cfg.manager)
+	if cfg.route != nil {
+		inner.SetRoute(cfg.route)
+	}
+	if cfg.metadata != nil {
+		inner.SetMetadata(*cfg.metadata)
+	}
+	for _, kv := range cfg.values {
+		inner.Set(kv[0], kv[1])
+	}
+	for _, kv := range cfg.params {
+		inner.SetParam(kv[0], kv[1])
+	}
+	return &FakeContext{Context: inner, Manager: cfg.manager}
+}
+
+// Finish 模拟路由器在分发完成后的清理：释放响应缓冲区并执行OnReset注册的回调
+// 之后可以用Manager.AssertBalanced检查处理器是否释放了获取的缓冲区
+func (c *FakeContext) Finish() {
+	router_context.ReleaseResponse(c.Context)
+	router_context.RunCleanups(c.Context)
+}
+
+// Set 设置键值对
+func (c *FakeContext) Set(key, value any) {
+	c.record("Set", key, value)
+	c.Context.Set(key, value)
+}
+
+// SetLazy 设置延迟计算的值
+func (c *FakeContext) SetLazy(key any, fn func() any) {
+	c.record("SetLazy", key)
+	c.Context.SetLazy(key, fn)
+}
+
+// Delete 删除键值对
+func (c *FakeContext) Delete(key any) {
+	c.record("Delete", key)
+	c.Context.Delete(key)
+}
+
+// SetParam 设置匹配参数
+func (c *FakeContext) SetParam(name, value string) {
+	c.record("SetParam", name, value)
+	c.Context.SetParam(name, value)
+}
+
+// SetRoute 设置匹配到的路由信息
+func (c *FakeContext) SetRoute(info *router_context.RouteInfo) {
+	c.record("SetRoute", info)
+	c.Context.SetRoute(info)
+}
+
+// Abort 终止后续处理
+func (c *FakeContext) Abort(err error) {
+	c.record("Abort", err)
+	c.Context.Abort(err)
+}
+
+// AddError 记录一个非致命错误
+func (c *FakeContext) AddError(err error) {
+	c.record("AddError", err)
+	c.Context.AddError(err)
+}
+
+// SetTraceID 设置追踪ID
+func (c *FakeContext) SetTraceID(id string) {
+	c.record("SetTraceID", id)
+	c.Context.SetTraceID(id)
+}
+
+// SetMetadata 设置传输层元数据
+func (c *FakeContext) SetMetadata(md router_context.Metadata) {
+	c.record("SetMetadata", md)
+	c.Context.SetMetadata(md)
+}
+
+// SetAttachment 设置命名的附加缓冲区
+func (c *FakeContext) SetAttachment(name string, buf buffer.Buffer) {
+	c.record("SetAttachment", name, buf)
+	c.Context.SetAttachment(name, buf)
+}
+
+// OnReset 注册清理回调，Finish时执行
+func (c *FakeContext) OnReset(fn func()) {
+	c.record("OnReset")
+	c.Context.OnReset(fn)
+}
+
+// Response 获取响应缓冲区，第一次调用时从Manager获取
+func (c *FakeContext) Response() buffer.Buffer {
+	c.record("Response")
+	return c.Context.Response()
+}
+
+// ResponseBytes 返回已写入的响应内容，没有获取响应缓冲区时返回nil，不记录调用
+func (c *FakeContext) ResponseBytes() []byte {
+	if !c.Context.HasResponse() {
+		return nil
+	}
+	resp := c.Context.Response()
+	if fake, ok := resp.(*FakeBuffer); ok {
+		return fake.Data
+	}
+	return resp.Get()
+}
@@ -0,0 +1,117 @@
+package testutil
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	"github.com/aomirun/content-router/manage"
+)
+
+// FakeBufferManager 是记录调用、跟踪未释放缓冲区的manage.BufferManager实现
+// 可以在多个goroutine中并发使用
+type FakeBufferManager struct {
+	CallRecorder
+
+	// NewBuffer 创建Acquire返回的缓冲区，为nil时返回空的FakeBuffer
+	// 必须在第一次Acquire之前设置
+	NewBuffer func() buffer.Buffer
+
+	mu             sync.Mutex
+	outstanding    []buffer.Buffer
+	acquired       uint64
+	released       uint64
+	doubleReleases int
+}
+
+// NewFakeBufferManager 创建FakeBufferManager
+func NewFakeBufferManager() *FakeBufferManager {
+	return &FakeBufferManager{}
+}
+
+// Acquire 创建一个缓冲区并记录为未释放
+func (m *FakeBufferManager) Acquire() buffer.Buffer {
+	m.record("Acquire")
+	var buf buffer.Buffer
+	if m.NewBuffer != nil {
+		buf = m.NewBuffer()
+	} else {
+		buf = &FakeBuffer{}
+	}
+
+	m.mu.Lock()
+	m.acquired++
+	m.outstanding = append(m.outstanding, buf)
+	m.mu.Unlock()
+	return buf
+}
+
+// Release 释放缓冲区
+// 释放不是由该管理器获取或已经释放过的缓冲区时计入DoubleReleases
+func (m *FakeBufferManager) Release(buf buffer.Buffer) {
+	m.record("Release", buf)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.released++
+	for i, b := range m.outstanding {
+		if b == buf {
+			m.outstanding = append(m.outstanding[:i], m.outstanding[i+1:]...)
+			return
+		}
+	}
+	m.doubleReleases++
+}
+
+// WithBuffer 获取一个缓冲区并传给fn，返回后总是释放缓冲区
+func (m *FakeBufferManager) WithBuffer(fn func(buf buffer.Buffer) error) error {
+	buf := m.Acquire()
+	defer m.Release(buf)
+	return fn(buf)
+}
+
+// Stats 返回使用统计，OutstandingBytes按未释放缓冲区当前的容量计算
+func (m *FakeBufferManager) Stats() manage.Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bytes := 0
+	for _, buf := range m.outstanding {
+		// 直接读取FakeBuffer的容量，避免在缓冲区上留下调用记录
+		if fake, ok := buf.(*FakeBuffer); ok {
+			bytes += cap(fake.Data)
+		} else {
+			bytes += buf.Cap()
+		}
+	}
+	return manage.Stats{
+		Acquired:         m.acquired,
+		Released:         m.released,
+		Outstanding:      len(m.outstanding),
+		OutstandingBytes: bytes,
+	}
+}
+
+// Outstanding 返回已获取但尚未释放的缓冲区，按获取顺序排列
+func (m *FakeBufferManager) Outstanding() []buffer.Buffer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]buffer.Buffer(nil), m.outstanding...)
+}
+
+// DoubleReleases 返回重复释放或释放未知缓冲区的次数
+func (m *FakeBufferManager) DoubleReleases() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.doubleReleases
+}
+
+// AssertBalanced 断言所有缓冲区都已释放，并且没有重复释放
+func (m *FakeBufferManager) AssertBalanced(t testing.TB) {
+	t.Helper()
+	if n := len(m.Outstanding()); n > 0 {
+		t.Errorf("testutil: %d buffers acquired but not released", n)
+	}
+	if n := m.DoubleReleases(); n > 0 {
+		t.Errorf("testutil: %d buffers released twice or not acquired from this manager", n)
+	}
+}
@@ -0,0 +1,203 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+)
+
+// 确保测试替身实现了对应的接口
+var (
+	_ buffer.Buffer          = (*FakeBuffer)(nil)
+	_ manage.BufferManager   = (*FakeBufferManager)(nil)
+	_ router_context.Context = (*FakeContext)(nil)
+)
+
+func TestFakeBuffer(t *testing.T) {
+	buf := NewFakeBuffer("hello")
+	buf.Write([]byte(" world"))
+	buf.Truncate(5)
+	if buf.String() != "hello" || buf.Len() != 5 {
+		t.Errorf("buffer = %q", buf.String())
+	}
+
+	calls := buf.Calls()
+	if len(calls) != 3 || calls[0].Method != "Write" || string(calls[0].Args[0].([]byte)) != " world" || calls[1].Args[0] != 5 {
+		t.Errorf("calls = %+v", calls)
+	}
+	if buf.CallCount("Len") != 1 || buf.Called("Reset") {
+		t.Errorf("Len calls = %d, Reset called = %v", buf.CallCount("Len"), buf.Called("Reset"))
+	}
+	buf.ResetCalls()
+	if len(buf.Calls()) != 0 {
+		t.Errorf("calls after ResetCalls = %+v", buf.Calls())
+	}
+
+	clone := buf.Clone().(*FakeBuffer)
+	clone.WriteString("!")
+	if buf.String() != "hello" || clone.String() != "hello!" || len(clone.CallsTo("Clone")) != 0 {
+		t.Errorf("buf = %q, clone = %q", buf.String(), clone.String())
+	}
+	if slice := buf.Slice(1, 3); string(slice.Get()) != "el" {
+		t.Errorf("slice = %q", slice.Get())
+	}
+}
+
+func TestFakeBuffer_WriteBehavior(t *testing.T) {
+	errDisk := errors.New("disk full")
+	buf := &FakeBuffer{WriteErr: errDisk}
+	if n, err := buf.Write([]byte("x")); n != 0 || !errors.Is(err, errDisk) {
+		t.Errorf("Write = %d, %v", n, err)
+	}
+
+	buf = &FakeBuffer{MaxLen: 4}
+	if _, err := buf.WriteString("1234"); err != nil {
+		t.Errorf("WriteString within limit: %v", err)
+	}
+	if _, err := buf.WriteString("5"); !errors.Is(err, buffer.ErrTooLarge) {
+		t.Errorf("WriteString over limit = %v, want ErrTooLarge", err)
+	}
+	if buf.String() != "1234" {
+		t.Errorf("buffer = %q", buf.String())
+	}
+}
+
+func TestFakeBufferManager(t *testing.T) {
+	m := NewFakeBufferManager()
+	a := m.Acquire()
+	b := m.Acquire()
+	a.Write(make([]byte, 10))
+	m.Release(a)
+
+	stats := m.Stats()
+	if stats.Acquired != 2 || stats.Released != 1 || stats.Outstanding != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if out := m.Outstanding(); len(out) != 1 || out[0] != b {
+		t.Errorf("outstanding = %v", out)
+	}
+
+	m.Release(a)
+	if m.DoubleReleases() != 1 {
+		t.Errorf("DoubleReleases = %d", m.DoubleReleases())
+	}
+
+	ft := &fakeT{}
+	m.AssertBalanced(ft)
+	if len(ft.failures) != 2 {
+		t.Errorf("AssertBalanced failures = %q", ft.failures)
+	}
+
+	m.WithBuffer(func(buf buffer.Buffer) error { return nil })
+	if m.CallCount("Acquire") != 3 || m.CallCount("Release") != 3 {
+		t.Errorf("calls = %+v", m.Calls())
+	}
+}
+
+func TestFakeBufferManager_NewBuffer(t *testing.T) {
+	m := &FakeBufferManager{NewBuffer: func() buffer.Buffer { return buffer.NewBuffer() }}
+	if _, ok := m.Acquire().(*FakeBuffer); ok {
+		t.Error("NewBuffer not used")
+	}
+}
+
+func TestFakeContext(t *testing.T) {
+	deadline := time.Now().Add(time.Minute)
+	parent, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	ctx := NewFakeContext(NewFakeBuffer("order:42"),
+		WithParent(parent),
+		WithRoute(router_context.RouteInfo{Name: "orders"}),
+		WithMetadata(router_context.Metadata{Transport: "test"}),
+		WithValue("tenant", "acme"),
+		WithParam("id", "42"),
+	)
+	if ctx.Route().Name != "orders" || ctx.Metadata().Transport != "test" || ctx.Param("id") != "42" {
+		t.Errorf("initial state: route=%v metadata=%+v id=%q", ctx.Route(), ctx.Metadata(), ctx.Param("id"))
+	}
+	if v, _ := ctx.GetString("tenant"); v != "acme" {
+		t.Errorf("tenant = %q", v)
+	}
+	if d, ok := ctx.Deadline(); !ok || !d.Equal(deadline) {
+		t.Errorf("deadline = %v, %v", d, ok)
+	}
+	if len(ctx.Calls()) != 0 {
+		t.Errorf("initial options recorded calls: %+v", ctx.Calls())
+	}
+
+	// 处理器直接使用FakeContext
+	handler := func(c router_context.Context) error {
+		c.Set("seen", true)
+		c.AddError(errors.New("warning"))
+		c.Response().Write([]byte("ok"))
+		c.Abort(nil)
+		return nil
+	}
+	handler(ctx)
+
+	if got := ctx.CallsTo("Set"); len(got) != 1 || got[0].Args[0] != "seen" || got[0].Args[1] != true {
+		t.Errorf("Set calls = %+v", got)
+	}
+	if !ctx.Called("Abort") || !ctx.IsAborted() || !errors.Is(ctx.AbortError(), router_context.ErrAborted) {
+		t.Errorf("Abort not applied")
+	}
+	if string(ctx.ResponseBytes()) != "ok" || len(ctx.Errors()) != 1 {
+		t.Errorf("response = %q, errors = %v", ctx.ResponseBytes(), ctx.Errors())
+	}
+
+	ctx.Finish()
+	ctx.Manager.AssertBalanced(t)
+}
+
+func TestFakeContext_WithRouter(t *testing.T) {
+	// 中间件可以直接用FakeContext测试
+	var seen string
+	mw := router.MiddlewareFunc(func(c router_context.Context, next router.HandlerFunc) error {
+		c.SetTraceID("trace-1")
+		return next(c)
+	})
+	ctx := NewFakeContext(nil)
+	mw(ctx, func(c router_context.Context) error {
+		seen = c.TraceID()
+		return nil
+	})
+	if seen != "trace-1" || ctx.CallCount("SetTraceID") != 1 {
+		t.Errorf("trace = %q, calls = %+v", seen, ctx.Calls())
+	}
+	if ctx.ResponseBytes() != nil {
+		t.Errorf("ResponseBytes without response = %q", ctx.ResponseBytes())
+	}
+}
+
+func TestFakeContext_Cleanups(t *testing.T) {
+	m := NewFakeBufferManager()
+	ctx := NewFakeContext(nil, WithManager(m))
+	buf := m.Acquire()
+	ctx.SetAttachment("head", buf)
+	ctx.OnReset(func() { m.Release(buf) })
+
+	ctx.Finish()
+	m.AssertBalanced(t)
+	if !ctx.Called("SetAttachment") || !ctx.Called("OnReset") {
+		t.Errorf("calls = %+v", ctx.Calls())
+	}
+}
+
+// fakeT 记录断言失败
+type fakeT struct {
+	testing.TB
+	failures []string
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.failures = append(f.failures, format)
+}
@@ -0,0 +1,33 @@
+package transport
+
+import (
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// trackedConn 包装服务器管理的连接，支持优雅关闭
+type trackedConn struct {
+	net.Conn
+	draining atomic.Bool
+}
+
+// drain 让连接在读完已经收到的数据后结束
+// 阻塞在读取中的空闲连接立即返回，正在处理帧的连接完成后在下一次读取时结束
+func (c *trackedConn) drain() {
+	c.draining.Store(true)
+	c.Conn.SetReadDeadline(time.Now())
+}
+
+// Read 读取数据，连接被drain后返回io.EOF
+func (c *trackedConn) Read(p []byte) (int, error) {
+	if c.draining.Load() {
+		return 0, io.EOF
+	}
+	n, err := c.Conn.Read(p)
+	if err != nil && c.draining.Load() {
+		return n, io.EOF
+	}
+	return n, err
+}
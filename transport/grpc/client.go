@@ -0,0 +1,167 @@
+package grpc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Client 是Router服务的客户端，与protoc生成的客户端使用相同的线路格式
+type Client interface {
+	// Route 打开一个Route双向流
+	Route(ctx context.Context) (Stream, error)
+}
+
+// Stream 是Route调用的双向流
+// Send和Recv可以在两个goroutine中并发调用，但各自不能并发调用
+type Stream interface {
+	// Send 发送一条消息
+	Send(c *Content) error
+
+	// Recv 接收下一条响应，流正常结束时返回io.EOF，以错误状态结束时返回*StatusError
+	Recv() (*Content, error)
+
+	// CloseSend 结束发送，服务端处理完已发送的消息后结束流
+	CloseSend() error
+}
+
+// ClientOption 定义客户端的配置选项
+type ClientOption func(*client)
+
+// WithHTTPClient 设置发起调用使用的http.Client
+// 默认客户端对http地址使用未加密的HTTP/2，对https地址使用TLS上的HTTP/2
+func WithHTTPClient(c *http.Client) ClientOption {
+	return func(cl *client) {
+		if c != nil {
+			cl.http = c
+		}
+	}
+}
+
+// WithClientMaxMessageSize 设置接收响应的最大长度，默认DefaultMaxMessageSize
+func WithClientMaxMessageSize(n int) ClientOption {
+	return func(cl *client) {
+		if n > 0 {
+			cl.maxSize = n
+		}
+	}
+}
+
+// client 是Client接口的实现
+type client struct {
+	target  string
+	http    *http.Client
+	maxSize int
+}
+
+// NewClient 创建连接target的客户端
+//   - target: 服务地址，例如"http://localhost:50051"
+func NewClient(target string, opts ...ClientOption) Client {
+	var protocols http.Protocols
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+	c := &client{
+		target:  strings.TrimSuffix(target, "/"),
+		http:    &http.Client{Transport: &http.Transport{Protocols: &protocols}},
+		maxSize: DefaultMaxMessageSize,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Route 打开一个Route双向流
+func (c *client) Route(ctx context.Context) (Stream, error) {
+	pr, pw := io.Pipe()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.target+RoutePath, pr)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("Te", "trailers")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		pw.CloseWithError(err)
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		pw.Close()
+		return nil, &StatusError{CodeUnknown, "unexpected HTTP status " + resp.Status}
+	}
+	if err := statusFrom(resp.Header); err != nil {
+		// 只有状态的响应表示调用在流开始之前失败
+		resp.Body.Close()
+		pw.Close()
+		return nil, err
+	}
+	return &stream{resp: resp, pw: pw, maxSize: c.maxSize}, nil
+}
+
+// stream 是Stream接口的实现
+type stream struct {
+	resp    *http.Response
+	pw      *io.PipeWriter
+	maxSize int
+
+	sendMu sync.Mutex
+	out    []byte
+	in     []byte
+}
+
+// Send 发送一条消息
+func (s *stream) Send(c *Content) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.out = appendMessage(s.out[:0], c)
+	_, err := s.pw.Write(s.out)
+	return err
+}
+
+// Recv 接收下一条响应
+func (s *stream) Recv() (*Content, error) {
+	var err error
+	s.in, err = readMessage(s.resp.Body, s.in[:0], s.maxSize)
+	if err != nil {
+		s.resp.Body.Close()
+		if err == io.EOF {
+			if err := statusFrom(s.resp.Trailer); err != nil {
+				return nil, err
+			}
+		}
+		return nil, err
+	}
+
+	c := new(Content)
+	if err := c.unmarshal(s.in); err != nil {
+		return nil, err
+	}
+	// 缓冲区会被下一次Recv复用
+	c.Data = bytes.Clone(c.Data)
+	return c, nil
+}
+
+// CloseSend 结束发送
+func (s *stream) CloseSend() error {
+	return s.pw.Close()
+}
+
+// statusFrom 从响应头或尾部读取状态，OK或没有状态时返回nil
+func statusFrom(h http.Header) error {
+	status := h.Get("Grpc-Status")
+	if status == "" || status == "0" {
+		return nil
+	}
+	code, err := strconv.ParseUint(status, 10, 32)
+	if err != nil {
+		return errors.New("grpc: invalid status " + status)
+	}
+	return &StatusError{Code(code), decodeGRPCMessage(h.Get("Grpc-Message"))}
+}
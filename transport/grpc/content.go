@@ -0,0 +1,123 @@
+package grpc
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// errMalformed 表示无法解码的protobuf消息
+var errMalformed = errors.New("grpc: malformed message")
+
+// protobuf线路类型
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// Content 对应router.proto中的Content消息
+type Content struct {
+	// Data 消息内容或处理器写入的响应
+	Data []byte
+	// Headers 随消息发送的键值对
+	Headers map[string]string
+	// Error 处理器返回的错误，只出现在响应中
+	Error string
+}
+
+// marshal 把消息按protobuf格式追加到dst
+func (c *Content) marshal(dst []byte) []byte {
+	if len(c.Data) > 0 {
+		dst = appendBytesField(dst, 1, c.Data)
+	}
+	for k, v := range c.Headers {
+		entry := appendBytesField(nil, 1, []byte(k))
+		entry = appendBytesField(entry, 2, []byte(v))
+		dst = appendBytesField(dst, 2, entry)
+	}
+	if c.Error != "" {
+		dst = appendBytesField(dst, 3, []byte(c.Error))
+	}
+	return dst
+}
+
+// unmarshal 解码protobuf格式的消息，忽略未知字段
+// 解码后Data引用data的内存
+func (c *Content) unmarshal(data []byte) error {
+	*c = Content{}
+	return eachField(data, func(num int, value []byte) error {
+		switch num {
+		case 1:
+			c.Data = value
+		case 2:
+			var k, v string
+			if err := eachField(value, func(num int, value []byte) error {
+				switch num {
+				case 1:
+					k = string(value)
+				case 2:
+					v = string(value)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			if c.Headers == nil {
+				c.Headers = make(map[string]string)
+			}
+			c.Headers[k] = v
+		case 3:
+			c.Error = string(value)
+		}
+		return nil
+	})
+}
+
+// appendBytesField 追加一个长度前缀字段
+func appendBytesField(dst []byte, num int, value []byte) []byte {
+	dst = binary.AppendUvarint(dst, uint64(num)<<3|wireBytes)
+	dst = binary.AppendUvarint(dst, uint64(len(value)))
+	return append(dst, value...)
+}
+
+// eachField 遍历消息中的长度前缀字段，跳过其他线路类型的字段
+func eachField(data []byte, fn func(num int, value []byte) error) error {
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 || tag>>3 == 0 {
+			return errMalformed
+		}
+		data = data[n:]
+
+		switch tag & 7 {
+		case wireVarint:
+			if _, n = binary.Uvarint(data); n <= 0 {
+				return errMalformed
+			}
+			data = data[n:]
+		case wireFixed64, wireFixed32:
+			size := 8
+			if tag&7 == wireFixed32 {
+				size = 4
+			}
+			if len(data) < size {
+				return errMalformed
+			}
+			data = data[size:]
+		case wireBytes:
+			length, n := binary.Uvarint(data)
+			if n <= 0 || length > uint64(len(data)-n) {
+				return errMalformed
+			}
+			value := data[n : n+int(length)]
+			data = data[n+int(length):]
+			if err := fn(int(tag>>3), value); err != nil {
+				return err
+			}
+		default:
+			return errMalformed
+		}
+	}
+	return nil
+}
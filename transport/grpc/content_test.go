@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestContentRoundTrip(t *testing.T) {
+	in := Content{
+		Data:    []byte("payload"),
+		Headers: map[string]string{"tenant": "acme", "empty": ""},
+		Error:   "failed",
+	}
+	var out Content
+	if err := out.unmarshal(in.marshal(nil)); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !bytes.Equal(out.Data, in.Data) || out.Error != in.Error || len(out.Headers) != 2 ||
+		out.Headers["tenant"] != "acme" || out.Headers["empty"] != "" {
+		t.Errorf("Unexpected round trip %+v", out)
+	}
+}
+
+func TestContentSkipsUnknownFields(t *testing.T) {
+	var data []byte
+	data = binary.AppendUvarint(data, 5<<3|wireVarint)
+	data = binary.AppendUvarint(data, 300)
+	data = binary.AppendUvarint(data, 6<<3|wireFixed32)
+	data = append(data, 1, 2, 3, 4)
+	data = appendBytesField(data, 1, []byte("x"))
+
+	var c Content
+	if err := c.unmarshal(data); err != nil || string(c.Data) != "x" {
+		t.Errorf("Unknown fields should be skipped, got %+v, %v", c, err)
+	}
+	if err := c.unmarshal([]byte{0x0A, 5, 'x'}); err != errMalformed {
+		t.Errorf("Expected errMalformed for truncated field, got %v", err)
+	}
+}
+
+func TestGRPCMessageEncoding(t *testing.T) {
+	msg := "bad 100% 数据\n"
+	encoded := encodeGRPCMessage(msg)
+	if encoded != "bad 100%25 %E6%95%B0%E6%8D%AE%0A" {
+		t.Errorf("Unexpected encoding %q", encoded)
+	}
+	if decoded := decodeGRPCMessage(encoded); decoded != msg {
+		t.Errorf("Expected %q, got %q", msg, decoded)
+	}
+}
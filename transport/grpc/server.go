@@ -0,0 +1,189 @@
+// Package grpc 提供gRPC双向流适配器
+// 客户端通过Router/Route方法逐条发送Content消息，路由器处理后按顺序流式返回响应，
+// 让非Go的生产者也可以接入路由器。服务定义见router.proto
+//
+// 本包直接实现gRPC的HTTP/2线路协议，不依赖gRPC库，不支持消息压缩
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+)
+
+// RoutePath 是Route方法的HTTP路径
+const RoutePath = "/contentrouter.v1.Router/Route"
+
+// DefaultMaxMessageSize 是默认的最大消息长度，与gRPC的默认值一致
+const DefaultMaxMessageSize = 4 * 1024 * 1024
+
+// Router 定义gRPC适配器需要的路由器功能
+type Router interface {
+	router.RouteResponder
+	router.BufferManagerAccessor
+}
+
+// headersKey 是消息键值对在标准context中的键
+type headersKey struct{}
+
+// HeadersFromContext 获取随消息发送的键值对，处理器可以直接传入路由上下文
+func HeadersFromContext(ctx context.Context) (map[string]string, bool) {
+	headers, ok := ctx.Value(headersKey{}).(map[string]string)
+	return headers, ok
+}
+
+// Option 定义gRPC适配器的配置选项
+type Option func(*handler)
+
+// WithMaxMessageSize 设置接收消息的最大长度，默认DefaultMaxMessageSize
+func WithMaxMessageSize(n int) Option {
+	return func(h *handler) {
+		if n > 0 {
+			h.maxSize = n
+		}
+	}
+}
+
+// handler 是gRPC适配器的http.Handler实现
+type handler struct {
+	router  Router
+	maxSize int
+}
+
+// NewHandler 创建提供Router服务的http.Handler
+// gRPC要求HTTP/2，不使用TLS时需要在http.Server上启用未加密的HTTP/2：
+//
+//	var protocols http.Protocols
+//	protocols.SetUnencryptedHTTP2(true)
+//	server := &http.Server{Addr: ":50051", Handler: grpc.NewHandler(r), Protocols: &protocols}
+//
+// 每条消息的处理器错误写入响应的error字段，不会结束流
+func NewHandler(r Router, opts ...Option) http.Handler {
+	h := &handler{
+		router:  r,
+		maxSize: DefaultMaxMessageSize,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// ServeHTTP 处理一次Route调用
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !isGRPCContentType(r.Header.Get("Content-Type")) {
+		http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+		return
+	}
+	if r.URL.Path != RoutePath {
+		writeStatus(w, CodeUnimplemented, "unknown method "+r.URL.Path)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// HTTP/2下请求和响应本来就是全双工的，这里只是为了兼容测试用的HTTP/1.1连接
+	rc.EnableFullDuplex()
+	w.Header().Set("Content-Type", "application/grpc")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	code, msg := h.serveStream(r.Context(), r, w, rc)
+	setStatusTrailers(w, code, msg)
+}
+
+// serveStream 逐条读取、路由消息并写回响应，返回结束流的状态
+func (h *handler) serveStream(ctx context.Context, r *http.Request, w io.Writer, rc *http.ResponseController) (Code, string) {
+	md := router_context.Metadata{
+		Transport: "grpc",
+		Source:    r.RemoteAddr,
+	}
+	manager := h.router.BufferManager()
+	var in, out []byte
+	for {
+		var err error
+		in, err = readMessage(r.Body, in[:0], h.maxSize)
+		if err != nil {
+			return streamStatus(ctx, err)
+		}
+		var req Content
+		if err := req.unmarshal(in); err != nil {
+			return CodeInternal, err.Error()
+		}
+
+		md.ReceivedAt = time.Now()
+		msgCtx := router_context.WithMetadata(ctx, md)
+		if req.Headers != nil {
+			msgCtx = context.WithValue(msgCtx, headersKey{}, req.Headers)
+		}
+
+		buf := manager.Acquire()
+		buf.Write(req.Data)
+		respBuf := manager.Acquire()
+		err = h.router.RouteTo(msgCtx, buf, respBuf)
+		manager.Release(buf)
+		resp := Content{Data: respBuf.Get()}
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		out = appendMessage(out[:0], &resp)
+		manager.Release(respBuf)
+
+		if _, err := w.Write(out); err != nil {
+			return streamStatus(ctx, err)
+		}
+		if err := rc.Flush(); err != nil {
+			return streamStatus(ctx, err)
+		}
+	}
+}
+
+// streamStatus 把结束流的错误转换为状态
+func streamStatus(ctx context.Context, err error) (Code, string) {
+	var se *StatusError
+	switch {
+	case err == io.EOF:
+		return CodeOK, ""
+	case errors.As(err, &se):
+		return se.Code, se.Message
+	case ctx.Err() != nil:
+		return CodeCanceled, ctx.Err().Error()
+	default:
+		return CodeInternal, err.Error()
+	}
+}
+
+// writeStatus 写出只有状态的响应，用于流开始之前的错误
+func writeStatus(w http.ResponseWriter, code Code, msg string) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Grpc-Status", strconv.FormatUint(uint64(code), 10))
+	if msg != "" {
+		w.Header().Set("Grpc-Message", encodeGRPCMessage(msg))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// setStatusTrailers 在响应尾部设置状态
+func setStatusTrailers(w http.ResponseWriter, code Code, msg string) {
+	w.Header().Set(http.TrailerPrefix+"Grpc-Status", strconv.FormatUint(uint64(code), 10))
+	if msg != "" {
+		w.Header().Set(http.TrailerPrefix+"Grpc-Message", encodeGRPCMessage(msg))
+	}
+}
+
+// isGRPCContentType 判断是否为protobuf编码的gRPC请求
+func isGRPCContentType(ct string) bool {
+	ct, _, _ = strings.Cut(ct, ";")
+	return ct == "application/grpc" || ct == "application/grpc+proto"
+}
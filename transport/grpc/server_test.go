@@ -0,0 +1,146 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+)
+
+// startServer 启动使用未加密HTTP/2的测试服务器
+func startServer(t *testing.T, h http.Handler) *httptest.Server {
+	t.Helper()
+	server := httptest.NewUnstartedServer(h)
+	server.Config.Protocols = new(http.Protocols)
+	server.Config.Protocols.SetHTTP1(true)
+	server.Config.Protocols.SetUnencryptedHTTP2(true)
+	server.Start()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRouteStream(t *testing.T) {
+	manager := manage.NewBufferManager()
+	r := router.NewRouter(router.WithBufferManager(manager))
+	r.Match("ping", func(ctx router_context.Context) error {
+		headers, _ := HeadersFromContext(ctx)
+		md := ctx.Metadata()
+		ctx.Response().WriteString("pong " + headers["tenant"] + " " + md.Transport)
+		return nil
+	})
+	r.Match("fail", func(ctx router_context.Context) error {
+		return errors.New("rejected")
+	})
+	var proto int
+	h := NewHandler(r)
+	server := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		proto = req.ProtoMajor
+		h.ServeHTTP(w, req)
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := NewClient(server.URL).Route(ctx)
+	if err != nil {
+		t.Fatalf("Route: %v", err)
+	}
+
+	requests := []*Content{
+		{Data: []byte("ping"), Headers: map[string]string{"tenant": "acme"}},
+		{Data: []byte("fail")},
+		{Data: []byte("ping")},
+	}
+	// 第一条响应在结束发送之前就能收到
+	if err := stream.Send(requests[0]); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	got := []*Content{first}
+	for _, req := range requests[1:] {
+		if err := stream.Send(req); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	stream.CloseSend()
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		got = append(got, resp)
+	}
+
+	if proto != 2 {
+		t.Errorf("Client should use HTTP/2, got HTTP/%d", proto)
+	}
+	if len(got) != 3 {
+		t.Fatalf("Expected one response per message, got %d", len(got))
+	}
+	if string(got[0].Data) != "pong acme grpc" || got[0].Error != "" {
+		t.Errorf("Unexpected first response %+v", got[0])
+	}
+	if got[1].Error != "rejected" || len(got[1].Data) != 0 {
+		t.Errorf("Handler errors should be reported in the response, got %+v", got[1])
+	}
+	if string(got[2].Data) != "pong  grpc" {
+		t.Errorf("Unexpected third response %q", got[2].Data)
+	}
+	if stats := manager.Stats(); stats.Outstanding != 0 {
+		t.Errorf("Every buffer should be released, got %+v", stats)
+	}
+}
+
+func TestRouteStreamMessageTooLarge(t *testing.T) {
+	r := router.NewRouter()
+	server := startServer(t, NewHandler(r, WithMaxMessageSize(4)))
+
+	stream, err := NewClient(server.URL).Route(context.Background())
+	if err != nil {
+		t.Fatalf("Route: %v", err)
+	}
+	stream.Send(&Content{Data: []byte("too large")})
+	stream.CloseSend()
+
+	_, err = stream.Recv()
+	var se *StatusError
+	if !errors.As(err, &se) || se.Code != CodeResourceExhausted {
+		t.Errorf("Expected ResourceExhausted, got %v", err)
+	}
+}
+
+func TestUnknownMethod(t *testing.T) {
+	server := startServer(t, NewHandler(router.NewRouter()))
+
+	resp, err := http.Post(server.URL+"/other.Service/Call", "application/grpc", strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	resp.Body.Close()
+	if err := statusFrom(resp.Header); err == nil || err.(*StatusError).Code != CodeUnimplemented {
+		t.Errorf("Expected Unimplemented, got %v", err)
+	}
+
+	resp, err = http.Post(server.URL+RoutePath, "application/json", strings.NewReader(""))
+	if err != nil {
+		t.Fatalf("Post: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected 415, got %d", resp.StatusCode)
+	}
+}
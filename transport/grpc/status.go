@@ -0,0 +1,110 @@
+package grpc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Code 是gRPC状态码
+type Code uint32
+
+// 本包使用的gRPC状态码
+const (
+	CodeOK                Code = 0
+	CodeCanceled          Code = 1
+	CodeUnknown           Code = 2
+	CodeInvalidArgument   Code = 3
+	CodeResourceExhausted Code = 8
+	CodeUnimplemented     Code = 12
+	CodeInternal          Code = 13
+)
+
+// StatusError 表示以非OK状态结束的调用
+type StatusError struct {
+	Code    Code
+	Message string
+}
+
+// Error 实现error接口
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("grpc: code %d: %s", e.Code, e.Message)
+}
+
+// frameHeaderSize 是gRPC消息帧头的长度：1字节压缩标志和4字节大端序长度
+const frameHeaderSize = 5
+
+// readMessage 读取一个gRPC消息帧，消息内容读入buf并返回
+// 流在帧边界结束时返回io.EOF
+func readMessage(r io.Reader, buf []byte, maxSize int) ([]byte, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, &StatusError{CodeInternal, "truncated message header"}
+		}
+		return nil, err
+	}
+	if header[0] != 0 {
+		return nil, &StatusError{CodeUnimplemented, "compressed messages are not supported"}
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if uint64(size) > uint64(maxSize) {
+		return nil, &StatusError{CodeResourceExhausted, fmt.Sprintf("message size %d exceeds limit %d", size, maxSize)}
+	}
+
+	if cap(buf) < int(size) {
+		buf = make([]byte, size)
+	}
+	buf = buf[:size]
+	if _, err := io.ReadFull(r, buf); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, &StatusError{CodeInternal, "truncated message"}
+		}
+		return nil, err
+	}
+	return buf, nil
+}
+
+// appendMessage 把消息编码为gRPC消息帧追加到dst
+func appendMessage(dst []byte, c *Content) []byte {
+	start := len(dst)
+	dst = append(dst, 0, 0, 0, 0, 0)
+	dst = c.marshal(dst)
+	binary.BigEndian.PutUint32(dst[start+1:], uint32(len(dst)-start-frameHeaderSize))
+	return dst
+}
+
+// encodeGRPCMessage 按gRPC规范对grpc-message进行百分号编码
+func encodeGRPCMessage(msg string) string {
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// decodeGRPCMessage 解码百分号编码的grpc-message，无效的转义保持原样
+func decodeGRPCMessage(msg string) string {
+	if !strings.Contains(msg, "%") {
+		return msg
+	}
+	var b strings.Builder
+	for i := 0; i < len(msg); i++ {
+		if msg[i] == '%' && i+2 < len(msg) {
+			if v, err := strconv.ParseUint(msg[i+1:i+3], 16, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 2
+				continue
+			}
+		}
+		b.WriteByte(msg[i])
+	}
+	return b.String()
+}
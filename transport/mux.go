@@ -0,0 +1,300 @@
+package transport
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+)
+
+// 连接多路复用的默认配置
+const (
+	// DefaultPeekSize 是用于识别协议的最大字节数
+	DefaultPeekSize = 32
+	// DefaultPeekTimeout 是等待客户端发送首个字节的最长时间
+	DefaultPeekTimeout = time.Second
+)
+
+// ConnHandler 定义接管连接的处理器
+// 处理器获得连接的所有权，负责在处理完成后关闭连接；
+// 连接重放已经用于识别协议的字节，处理器可以从头读取完整的数据流。
+// ctx不是路由器的上下文，处理器返回后可以继续在其他goroutine中使用，
+// 可以通过router_context.MetadataFromContext获取连接元数据
+type ConnHandler func(ctx context.Context, conn net.Conn)
+
+// Mux 定义连接多路复用器接口
+// 连接的首批字节作为缓冲区交给路由器匹配（TLS、HTTP、SSH或自定义协议），
+// 匹配的处理器接管整个连接。客户端在超时时间内没有发送数据时（服务端先发言的协议）使用空缓冲区匹配
+type Mux interface {
+	// Handle 注册匹配器和接管连接的处理器，按注册顺序匹配
+	Handle(matcher router.Matcher, handler ConnHandler)
+
+	// Listen 返回只接收匹配matcher的连接的net.Listener
+	// 可以交给http.Server.Serve等已有的服务器使用
+	Listen(matcher router.Matcher) net.Listener
+
+	// Serve 在l上接受连接并分发，直到多路复用器被关闭
+	// 返回: 被关闭时返回ErrServerClosed，否则返回接受连接时的错误
+	Serve(l net.Listener) error
+
+	// Close 关闭所有监听器，包括Listen返回的监听器
+	Close() error
+}
+
+// connKey 是被识别的连接在上下文中的键
+type connKey struct{}
+
+// muxImpl 是Mux接口的实现
+type muxImpl struct {
+	router router.Router
+	opts   options
+	nextID atomic.Uint64
+
+	mu        sync.Mutex
+	listeners []net.Listener
+	closed    bool
+	done      chan struct{}
+}
+
+// NewMux 创建连接多路复用器
+// 未匹配任何处理器的连接会被关闭
+func NewMux(opts ...Option) Mux {
+	return &muxImpl{
+		router: router.NewRouter(),
+		opts:   newOptions(opts),
+		done:   make(chan struct{}),
+	}
+}
+
+// Handle 注册匹配器和接管连接的处理器
+func (m *muxImpl) Handle(matcher router.Matcher, handler ConnHandler) {
+	m.router.Register(matcher, func(ctx router_context.Context) error {
+		c := ctx.Value(connKey{}).(*muxConn)
+		if c.taken.CompareAndSwap(false, true) {
+			// 路由器的上下文在Route返回后被重置复用，处理器使用分发时构建的上下文
+			handler(c.ctx, c.peekedConn)
+		}
+		return nil
+	})
+}
+
+// Listen 返回只接收匹配matcher的连接的net.Listener
+func (m *muxImpl) Listen(matcher router.Matcher) net.Listener {
+	ml := &muxListener{
+		conns: make(chan net.Conn),
+		done:  m.done,
+		addr:  m.addr,
+	}
+
+	m.Handle(matcher, func(ctx context.Context, conn net.Conn) {
+		select {
+		case ml.conns <- conn:
+		case <-m.done:
+			conn.Close()
+		}
+	})
+	return ml
+}
+
+// Serve 在l上接受连接并分发
+func (m *muxImpl) Serve(l net.Listener) error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		l.Close()
+		return ErrServerClosed
+	}
+	m.listeners = append(m.listeners, l)
+	m.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			select {
+			case <-m.done:
+				return ErrServerClosed
+			default:
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				time.Sleep(5 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+		go m.dispatch(conn)
+	}
+}
+
+// dispatch 识别连接的协议并交给匹配的处理器
+func (m *muxImpl) dispatch(conn net.Conn) {
+	c := &muxConn{peekedConn: newPeekedConn(conn)}
+
+	conn.SetReadDeadline(time.Now().Add(m.opts.peekTimeout))
+	head := c.peek(m.opts.peekSize)
+	conn.SetReadDeadline(time.Time{})
+
+	md := router_context.Metadata{
+		Transport:    "tcp",
+		Source:       conn.RemoteAddr().String(),
+		ReceivedAt:   time.Now(),
+		ConnectionID: strconv.FormatUint(m.nextID.Add(1), 10),
+	}
+	ctx := context.WithValue(router_context.WithMetadata(m.opts.baseContext, md), connKey{}, c)
+	c.ctx = ctx
+
+	buf := m.router.BufferManager().Acquire()
+	buf.Write(head)
+	m.router.Route(ctx, buf)
+	m.router.BufferManager().Release(buf)
+
+	if !c.taken.Load() {
+		conn.Close()
+	}
+}
+
+// Close 关闭所有监听器
+func (m *muxImpl) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	close(m.done)
+	for _, l := range m.listeners {
+		l.Close()
+	}
+	return nil
+}
+
+// addr 返回第一个监听器的地址
+func (m *muxImpl) addr() net.Addr {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.listeners) == 0 {
+		return muxAddr{}
+	}
+	return m.listeners[0].Addr()
+}
+
+// muxAddr 是多路复用器尚未开始监听时子监听器使用的地址
+type muxAddr struct{}
+
+// Network 返回网络类型
+func (muxAddr) Network() string { return "mux" }
+
+// String 返回地址描述
+func (muxAddr) String() string { return "mux" }
+
+// muxConn 是正在识别协议的连接
+type muxConn struct {
+	*peekedConn
+	// ctx 是分发时构建的上下文，交给接管连接的处理器
+	ctx   context.Context
+	taken atomic.Bool
+}
+
+// peekedConn 重放已经读取的字节的连接
+type peekedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// newPeekedConn 包装连接
+func newPeekedConn(conn net.Conn) *peekedConn {
+	return &peekedConn{Conn: conn, r: bufio.NewReader(conn)}
+}
+
+// peek 读取首批到达的数据但不消费，最多n个字节
+func (c *peekedConn) peek(n int) []byte {
+	if _, err := c.r.Peek(1); err != nil {
+		return nil
+	}
+	head, _ := c.r.Peek(min(c.r.Buffered(), n))
+	return head
+}
+
+// Read 先返回已经读取的字节，再从连接读取
+func (c *peekedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// muxListener 是Listen返回的net.Listener
+type muxListener struct {
+	conns chan net.Conn
+	done  <-chan struct{}
+	addr  func() net.Addr
+}
+
+// Accept 等待下一个匹配的连接
+func (l *muxListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.done:
+		return nil, ErrServerClosed
+	}
+}
+
+// Close 子监听器随多路复用器一起关闭，单独关闭不做任何事
+func (l *muxListener) Close() error {
+	return nil
+}
+
+// Addr 返回多路复用器监听的地址
+func (l *muxListener) Addr() net.Addr {
+	return l.addr()
+}
+
+// TLSMatcher 匹配TLS握手（ClientHello记录）
+func TLSMatcher() router.Matcher {
+	return router.MatcherFunc(func(ctx router_context.Context) bool {
+		data := ctx.Buffer().Get()
+		return len(data) >= 3 && data[0] == 0x16 && data[1] == 0x03 && data[2] <= 0x04
+	})
+}
+
+// http1Methods 是HTTP/1.x请求方法
+var http1Methods = [][]byte{
+	[]byte("GET "), []byte("POST "), []byte("PUT "), []byte("DELETE "), []byte("HEAD "),
+	[]byte("OPTIONS "), []byte("PATCH "), []byte("CONNECT "), []byte("TRACE "),
+}
+
+// HTTP1Matcher 匹配以HTTP/1.x请求方法开头的连接
+func HTTP1Matcher() router.Matcher {
+	return router.MatcherFunc(func(ctx router_context.Context) bool {
+		data := ctx.Buffer().Get()
+		for _, method := range http1Methods {
+			if bytes.HasPrefix(data, method) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// HTTP2Matcher 匹配以HTTP/2明文连接前言开头的连接
+func HTTP2Matcher() router.Matcher {
+	return router.PrefixMatcher("PRI * HTTP/2.0")
+}
+
+// SSHMatcher 匹配以SSH协议版本交换开头的连接
+func SSHMatcher() router.Matcher {
+	return router.PrefixMatcher("SSH-")
+}
+
+// ServerFirstMatcher 匹配在超时时间内没有发送任何数据的连接，用于服务端先发言的协议
+func ServerFirstMatcher() router.Matcher {
+	return router.MatcherFunc(func(ctx router_context.Context) bool {
+		return ctx.Buffer().Len() == 0
+	})
+}
@@ -0,0 +1,170 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+)
+
+func TestMux(t *testing.T) {
+	mux := NewMux(WithPeekTimeout(50 * time.Millisecond))
+
+	// 已有的HTTP服务器通过子监听器接收HTTP连接
+	httpL := mux.Listen(HTTP1Matcher())
+	httpSrv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "http:"+r.URL.Path)
+	})}
+	go httpSrv.Serve(httpL)
+	defer httpSrv.Close()
+
+	// 自定义协议的处理器接管连接，并能读到用于识别协议的字节
+	mux.Handle(router.PrefixMatcher("HELLO"), func(ctx context.Context, conn net.Conn) {
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		io.WriteString(conn, "echo:"+line)
+	})
+	// 服务端先发言的协议
+	mux.Handle(ServerFirstMatcher(), func(ctx context.Context, conn net.Conn) {
+		defer conn.Close()
+		io.WriteString(conn, "220 ready\n")
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- mux.Serve(l)
+	}()
+	addr := l.Addr().String()
+
+	resp, err := http.Get("http://" + addr + "/status")
+	if err != nil {
+		t.Fatalf("HTTP request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "http:/status" {
+		t.Errorf("Unexpected HTTP response %q", body)
+	}
+
+	conn, _ := net.Dial("tcp", addr)
+	io.WriteString(conn, "HELLO world\n")
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || line != "echo:HELLO world\n" {
+		t.Errorf("Expected the replayed greeting, got %q, %v", line, err)
+	}
+	conn.Close()
+
+	conn, _ = net.Dial("tcp", addr)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	line, err = bufio.NewReader(conn).ReadString('\n')
+	if err != nil || line != "220 ready\n" {
+		t.Errorf("Expected the server greeting, got %q, %v", line, err)
+	}
+	conn.Close()
+
+	// 未匹配的连接被关闭
+	conn, _ = net.Dial("tcp", addr)
+	io.WriteString(conn, "UNKNOWN\n")
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+		t.Errorf("Unmatched connection should be closed, got %v", err)
+	}
+	conn.Close()
+
+	mux.Close()
+	if err := <-done; !errors.Is(err, ErrServerClosed) {
+		t.Errorf("Expected ErrServerClosed, got %v", err)
+	}
+	if _, err := httpL.Accept(); !errors.Is(err, ErrServerClosed) {
+		t.Errorf("Child listener should be closed with the mux, got %v", err)
+	}
+}
+
+func TestMuxHandlerKeepsContext(t *testing.T) {
+	mux := NewMux()
+	type result struct {
+		md  router_context.Metadata
+		ok  bool
+		err error
+	}
+	results := make(chan result, 1)
+	// 处理器在goroutine中服务连接，Route返回后继续使用ctx
+	mux.Handle(router.PrefixMatcher("HELLO"), func(ctx context.Context, conn net.Conn) {
+		go func() {
+			defer conn.Close()
+			bufio.NewReader(conn).ReadString('\n')
+			md, ok := router_context.MetadataFromContext(ctx)
+			results <- result{md, ok, ctx.Err()}
+		}()
+	})
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	go mux.Serve(l)
+	defer mux.Close()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, "HELLO")
+	time.Sleep(20 * time.Millisecond)
+	io.WriteString(conn, "\n")
+
+	select {
+	case r := <-results:
+		if !r.ok || r.md.Transport != "tcp" || r.err != nil {
+			t.Errorf("Handler context after Route returned: metadata %+v, %v, err %v", r.md, r.ok, r.err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("handler did not finish")
+	}
+}
+
+// newTestContext 创建包含指定内容的上下文
+func newTestContext(content string) router_context.Context {
+	buf := buffer.NewBuffer()
+	buf.WriteString(content)
+	return router_context.NewContext(context.Background(), buf)
+}
+
+func TestProtocolMatchers(t *testing.T) {
+	tests := []struct {
+		name    string
+		matcher router.Matcher
+		data    string
+		want    bool
+	}{
+		{"TLS", TLSMatcher(), "\x16\x03\x01\x02\x00", true},
+		{"TLS/HTTP", TLSMatcher(), "GET / HTTP/1.1", false},
+		{"HTTP1", HTTP1Matcher(), "POST /api HTTP/1.1", true},
+		{"HTTP1/SSH", HTTP1Matcher(), "SSH-2.0-OpenSSH", false},
+		{"HTTP2", HTTP2Matcher(), "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n", true},
+		{"SSH", SSHMatcher(), "SSH-2.0-OpenSSH", true},
+		{"ServerFirst", ServerFirstMatcher(), "", true},
+		{"ServerFirst/data", ServerFirstMatcher(), "x", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.matcher.Match(newTestContext(tt.data)); got != tt.want {
+				t.Errorf("Match(%q) = %v, expected %v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
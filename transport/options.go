@@ -0,0 +1,97 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+)
+
+// Option 定义传输层服务器的配置选项
+type Option func(*options)
+
+// options 保存配置选项
+type options struct {
+	maxConns     int
+	baseContext  context.Context
+	errorHandler func(conn net.Conn, err error)
+	// packetErrorHandler 只作用于数据报服务器
+	packetErrorHandler func(addr net.Addr, err error)
+	// tlsConfig 只作用于面向连接的服务器
+	tlsConfig *tls.Config
+	// peekSize和peekTimeout只作用于连接多路复用器
+	peekSize    int
+	peekTimeout time.Duration
+}
+
+// WithMaxConns 设置同时服务的最大连接数，达到上限时暂停接受新连接
+// 0表示不限制，该选项只作用于面向连接的服务器
+func WithMaxConns(n int) Option {
+	return func(o *options) {
+		o.maxConns = n
+	}
+}
+
+// WithBaseContext 设置所有连接的父上下文，默认使用context.Background()
+func WithBaseContext(ctx context.Context) Option {
+	return func(o *options) {
+		if ctx != nil {
+			o.baseContext = ctx
+		}
+	}
+}
+
+// WithErrorHandler 设置连接因错误结束时的回调，例如用于记录日志
+// 服务器关闭导致的连接结束不会触发回调
+func WithErrorHandler(fn func(conn net.Conn, err error)) Option {
+	return func(o *options) {
+		o.errorHandler = fn
+	}
+}
+
+// WithPacketErrorHandler 设置数据报处理失败时的回调
+// 该选项只作用于数据报服务器，处理失败不会影响后续数据报
+func WithPacketErrorHandler(fn func(addr net.Addr, err error)) Option {
+	return func(o *options) {
+		o.packetErrorHandler = fn
+	}
+}
+
+// WithTLSConfig 让面向连接的服务器在接受的连接上进行TLS握手
+// 每个连接的上下文中传输层名称为"tls"
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = cfg
+	}
+}
+
+// WithPeekSize 设置连接多路复用器用于识别协议的最大字节数，默认DefaultPeekSize
+func WithPeekSize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.peekSize = n
+		}
+	}
+}
+
+// WithPeekTimeout 设置连接多路复用器等待客户端发送首个字节的最长时间，默认DefaultPeekTimeout
+func WithPeekTimeout(d time.Duration) Option {
+	return func(o *options) {
+		if d > 0 {
+			o.peekTimeout = d
+		}
+	}
+}
+
+// newOptions 应用配置选项
+func newOptions(opts []Option) options {
+	o := options{
+		baseContext: context.Background(),
+		peekSize:    DefaultPeekSize,
+		peekTimeout: DefaultPeekTimeout,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
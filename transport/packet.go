@@ -0,0 +1,224 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+)
+
+// maxDatagramSize 是数据报的最大长度
+const maxDatagramSize = 64 * 1024
+
+// ErrNoReplyAddr 表示数据报没有可以回复的来源地址
+var ErrNoReplyAddr = errors.New("transport: datagram has no reply address")
+
+// PacketRouter 定义数据报服务器需要的路由器功能
+type PacketRouter interface {
+	router.RouteResponder
+	router.BufferManagerAccessor
+}
+
+// PacketServer 定义数据报服务器接口
+type PacketServer interface {
+	// Serve 从pc读取数据报并逐个路由，直到服务器被关闭
+	// 返回: 服务器被关闭时返回ErrServerClosed，否则返回读取时的错误
+	Serve(pc net.PacketConn) error
+
+	// ListenAndServe 监听addr并调用Serve
+	ListenAndServe(addr string) error
+
+	// Shutdown 优雅关闭服务器
+	// 停止读取新的数据报，让正在处理的数据报完成并写回响应，
+	// ctx到期时强制关闭并返回ctx的错误
+	Shutdown(ctx context.Context) error
+
+	// Close 立即关闭服务器
+	Close() error
+}
+
+// packetServerImpl 是PacketServer接口的实现
+type packetServerImpl struct {
+	network string
+	router  PacketRouter
+	opts    options
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	conns   map[net.PacketConn]struct{}
+	closing bool
+	wg      sync.WaitGroup
+}
+
+// NewUDPServer 创建UDP服务器
+// 每个数据报作为一个缓冲区路由，上下文通过ctx.Metadata()提供来源地址，
+// 处理器写入ctx.Response()的内容作为数据报回复给来源地址
+//
+// 数据报按接收顺序依次处理
+func NewUDPServer(r PacketRouter, opts ...Option) PacketServer {
+	return newPacketServer("udp", r, opts)
+}
+
+// newPacketServer 创建指定网络类型的数据报服务器
+func newPacketServer(network string, r PacketRouter, opts []Option) *packetServerImpl {
+	o := newOptions(opts)
+	ctx, cancel := context.WithCancel(o.baseContext)
+	return &packetServerImpl{
+		network: network,
+		router:  r,
+		opts:    o,
+		ctx:     ctx,
+		cancel:  cancel,
+		conns:   make(map[net.PacketConn]struct{}),
+	}
+}
+
+// ListenAndServe 监听addr并调用Serve
+func (s *packetServerImpl) ListenAndServe(addr string) error {
+	pc, err := net.ListenPacket(s.network, addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(pc)
+}
+
+// Serve 从pc读取数据报并逐个路由
+func (s *packetServerImpl) Serve(pc net.PacketConn) error {
+	if !s.track(pc, true) {
+		pc.Close()
+		return ErrServerClosed
+	}
+	defer s.track(pc, false)
+	defer pc.Close()
+
+	scratch := make([]byte, maxDatagramSize)
+	manager := s.router.BufferManager()
+	for {
+		n, addr, err := pc.ReadFrom(scratch)
+		if err != nil {
+			if s.isClosing() {
+				return ErrServerClosed
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				continue
+			}
+			return err
+		}
+
+		buf := manager.Acquire()
+		buf.Write(scratch[:n])
+		err = s.route(pc, addr, buf)
+		manager.Release(buf)
+		if err != nil && s.opts.packetErrorHandler != nil {
+			s.opts.packetErrorHandler(addr, err)
+		}
+	}
+}
+
+// route 路由一个数据报，并把响应回复给来源地址
+func (s *packetServerImpl) route(pc net.PacketConn, addr net.Addr, buf buffer.Buffer) error {
+	md := router_context.Metadata{
+		Transport:  s.network,
+		ReceivedAt: time.Now(),
+	}
+	if hasAddr(addr) {
+		md.Source = addr.String()
+	}
+	ctx := router_context.WithMetadata(s.ctx, md)
+	return s.router.RouteTo(ctx, buf, &replyWriter{pc: pc, addr: addr})
+}
+
+// Shutdown 优雅关闭服务器
+func (s *packetServerImpl) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closing = true
+	// 让阻塞在读取中的循环立即返回，正在处理的数据报不受影响
+	for pc := range s.conns {
+		pc.SetReadDeadline(time.Now())
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.Close()
+		return ctx.Err()
+	}
+}
+
+// Close 立即关闭服务器
+func (s *packetServerImpl) Close() error {
+	s.mu.Lock()
+	s.closing = true
+	s.cancel()
+	for pc := range s.conns {
+		pc.Close()
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// track 记录或移除数据报连接，服务器正在关闭时拒绝记录
+func (s *packetServerImpl) track(pc net.PacketConn, add bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !add {
+		delete(s.conns, pc)
+		s.wg.Done()
+		return true
+	}
+	if s.closing {
+		return false
+	}
+	s.conns[pc] = struct{}{}
+	s.wg.Add(1)
+	return true
+}
+
+// isClosing 判断服务器是否正在关闭
+func (s *packetServerImpl) isClosing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closing
+}
+
+// hasAddr 判断来源地址是否可以用于回复，未绑定地址的Unix域套接字客户端没有地址
+func hasAddr(addr net.Addr) bool {
+	if addr == nil {
+		return false
+	}
+	if ua, ok := addr.(*net.UnixAddr); ok {
+		return ua != nil && ua.Name != ""
+	}
+	return true
+}
+
+// replyWriter 把每次Write作为数据报发送给指定地址
+type replyWriter struct {
+	pc   net.PacketConn
+	addr net.Addr
+}
+
+// Write 发送一个数据报
+func (w *replyWriter) Write(p []byte) (int, error) {
+	if !hasAddr(w.addr) {
+		return 0, ErrNoReplyAddr
+	}
+	return w.pc.WriteTo(p, w.addr)
+}
@@ -0,0 +1,119 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+)
+
+// startUDPServer 在随机端口上启动数据报服务器，返回监听地址和Serve的结果通道
+func startUDPServer(t *testing.T, srv PacketServer) (string, <-chan error) {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(pc)
+	}()
+	return pc.LocalAddr().String(), done
+}
+
+func TestUDPServer(t *testing.T) {
+	r := router.NewRouter()
+	r.Match("PING", func(ctx router_context.Context) error {
+		md := ctx.Metadata()
+		if md.Transport != "udp" || md.Source == "" || md.ReceivedAt.IsZero() {
+			t.Errorf("Unexpected metadata %+v", md)
+		}
+		ctx.Response().WriteString("PONG")
+		return nil
+	})
+	received := make(chan string, 1)
+	r.Match("metric", func(ctx router_context.Context) error {
+		received <- string(ctx.Buffer().Get())
+		return nil
+	})
+
+	srv := NewUDPServer(r)
+	addr, done := startUDPServer(t, srv)
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	conn.Write([]byte("metric.cpu:1|g"))
+	select {
+	case got := <-received:
+		if got != "metric.cpu:1|g" {
+			t.Errorf("Routed %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Datagram was not routed")
+	}
+
+	conn.Write([]byte("PING"))
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	reply := make([]byte, 16)
+	n, err := conn.Read(reply)
+	if err != nil || string(reply[:n]) != "PONG" {
+		t.Errorf("Expected PONG reply, got %q, %v", reply[:n], err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+	if err := <-done; !errors.Is(err, ErrServerClosed) {
+		t.Errorf("Expected ErrServerClosed, got %v", err)
+	}
+}
+
+func TestUDPServerErrorHandler(t *testing.T) {
+	handlerErr := errors.New("bad datagram")
+	r := router.NewRouter()
+	r.Match("", func(ctx router_context.Context) error {
+		if strings.HasPrefix(string(ctx.Buffer().Get()), "BAD") {
+			return handlerErr
+		}
+		ctx.Response().WriteString("OK")
+		return nil
+	})
+
+	errs := make(chan error, 1)
+	srv := NewUDPServer(r, WithPacketErrorHandler(func(addr net.Addr, err error) {
+		errs <- err
+	}))
+	addr, _ := startUDPServer(t, srv)
+	defer srv.Close()
+
+	conn, _ := net.Dial("udp", addr)
+	defer conn.Close()
+	conn.Write([]byte("BAD"))
+	select {
+	case err := <-errs:
+		if !errors.Is(err, handlerErr) {
+			t.Errorf("Expected handler error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Error handler was not called")
+	}
+
+	// 处理失败不影响后续数据报
+	conn.Write([]byte("GOOD"))
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	reply := make([]byte, 16)
+	if n, err := conn.Read(reply); err != nil || string(reply[:n]) != "OK" {
+		t.Errorf("Expected OK reply, got %q, %v", reply[:n], err)
+	}
+}
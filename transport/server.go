@@ -0,0 +1,264 @@
+// Package transport 提供把路由器接入网络的传输层服务器
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
+	"github.com/aomirun/content-router/router"
+)
+
+// ErrServerClosed 表示服务器已经被Shutdown或Close关闭
+var ErrServerClosed = errors.New("transport: server closed")
+
+// Server 定义面向连接的传输层服务器接口
+type Server interface {
+	// Serve 在l上接受连接并逐帧路由，直到服务器被关闭
+	// 返回: 服务器被关闭时返回ErrServerClosed，否则返回接受连接时的错误
+	Serve(l net.Listener) error
+
+	// ListenAndServe 监听addr并调用Serve
+	ListenAndServe(addr string) error
+
+	// Shutdown 优雅关闭服务器
+	// 停止接受新连接，让正在处理的帧完成并写回响应后关闭空闲连接，
+	// ctx到期时强制关闭剩余连接并返回ctx的错误
+	Shutdown(ctx context.Context) error
+
+	// Close 立即关闭所有监听器和连接
+	Close() error
+}
+
+// serverImpl 是Server接口的实现
+type serverImpl struct {
+	network string
+	handler router.ConnServer
+	framer  frame.Framer
+	opts    options
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	nextID atomic.Uint64
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[*trackedConn]struct{}
+	closing   bool
+	wg        sync.WaitGroup
+}
+
+// NewTCPServer 创建TCP服务器
+//   - handler: 处理连接的路由器，通常是router.Router
+//   - framer: 帧提取器，会被所有连接并发使用，必须是无状态的
+//   - opts: 服务器配置选项
+//
+// 每个连接的上下文通过ctx.Metadata()提供传输层名称、对端地址和连接标识
+func NewTCPServer(handler router.ConnServer, framer frame.Framer, opts ...Option) Server {
+	return newServer("tcp", handler, framer, opts)
+}
+
+// newServer 创建指定网络类型的服务器
+func newServer(network string, handler router.ConnServer, framer frame.Framer, opts []Option) *serverImpl {
+	o := newOptions(opts)
+	ctx, cancel := context.WithCancel(o.baseContext)
+	return &serverImpl{
+		network:   network,
+		handler:   handler,
+		framer:    framer,
+		opts:      o,
+		ctx:       ctx,
+		cancel:    cancel,
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[*trackedConn]struct{}),
+	}
+}
+
+// ListenAndServe 监听addr并调用Serve
+func (s *serverImpl) ListenAndServe(addr string) error {
+	l, err := net.Listen(s.network, addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve 在l上接受连接并逐帧路由
+func (s *serverImpl) Serve(l net.Listener) error {
+	if s.opts.tlsConfig != nil {
+		l = tls.NewListener(l, s.opts.tlsConfig)
+	}
+	if !s.trackListener(l, true) {
+		l.Close()
+		return ErrServerClosed
+	}
+	defer s.trackListener(l, false)
+
+	var sem chan struct{}
+	if s.opts.maxConns > 0 {
+		sem = make(chan struct{}, s.opts.maxConns)
+	}
+
+	var backoff time.Duration
+	for {
+		if sem != nil {
+			select {
+			case sem <- struct{}{}:
+			case <-s.ctx.Done():
+				return ErrServerClosed
+			}
+		}
+
+		conn, err := l.Accept()
+		if err != nil {
+			if sem != nil {
+				<-sem
+			}
+			if s.isClosing() {
+				return ErrServerClosed
+			}
+			// 超时类错误（例如文件描述符耗尽）按指数退避后重试
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				backoff = min(max(backoff*2, 5*time.Millisecond), time.Second)
+				time.Sleep(backoff)
+				continue
+			}
+			return err
+		}
+		backoff = 0
+
+		tc := &trackedConn{Conn: conn}
+		if !s.trackConn(tc, true) {
+			conn.Close()
+			if sem != nil {
+				<-sem
+			}
+			return ErrServerClosed
+		}
+		go func() {
+			defer func() {
+				if sem != nil {
+					<-sem
+				}
+			}()
+			s.serveConn(tc)
+		}()
+	}
+}
+
+// serveConn 服务一个连接，结束后关闭连接
+func (s *serverImpl) serveConn(tc *trackedConn) {
+	defer s.trackConn(tc, false)
+	defer tc.Close()
+
+	transport := s.network
+	if s.opts.tlsConfig != nil {
+		transport = "tls"
+	}
+	md := router_context.Metadata{
+		Transport:    transport,
+		Source:       tc.RemoteAddr().String(),
+		ConnectionID: strconv.FormatUint(s.nextID.Add(1), 10),
+	}
+	ctx := router_context.WithMetadata(s.ctx, md)
+
+	err := s.handler.ServeConn(ctx, tc, s.framer)
+	if err != nil && !s.isClosing() && s.opts.errorHandler != nil {
+		s.opts.errorHandler(tc.Conn, err)
+	}
+}
+
+// Shutdown 优雅关闭服务器
+func (s *serverImpl) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closing = true
+	s.closeListenersLocked()
+	// 让空闲连接的读取立即返回，正在处理的帧不受影响
+	for tc := range s.conns {
+		tc.drain()
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		s.Close()
+		return ctx.Err()
+	}
+}
+
+// Close 立即关闭所有监听器和连接
+func (s *serverImpl) Close() error {
+	s.mu.Lock()
+	s.closing = true
+	s.closeListenersLocked()
+	s.cancel()
+	for tc := range s.conns {
+		tc.Close()
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// closeListenersLocked 关闭所有监听器，调用方必须持有s.mu
+func (s *serverImpl) closeListenersLocked() {
+	for l := range s.listeners {
+		l.Close()
+		delete(s.listeners, l)
+	}
+}
+
+// trackListener 记录或移除监听器，服务器正在关闭时拒绝记录
+func (s *serverImpl) trackListener(l net.Listener, add bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !add {
+		delete(s.listeners, l)
+		return true
+	}
+	if s.closing {
+		return false
+	}
+	s.listeners[l] = struct{}{}
+	return true
+}
+
+// trackConn 记录或移除连接，服务器正在关闭时拒绝记录
+func (s *serverImpl) trackConn(tc *trackedConn, add bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !add {
+		delete(s.conns, tc)
+		s.wg.Done()
+		return true
+	}
+	if s.closing {
+		return false
+	}
+	s.conns[tc] = struct{}{}
+	s.wg.Add(1)
+	return true
+}
+
+// isClosing 判断服务器是否正在关闭
+func (s *serverImpl) isClosing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closing
+}
@@ -0,0 +1,180 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
+	"github.com/aomirun/content-router/router"
+)
+
+// startTCPServer 在随机端口上启动服务器，返回监听地址和Serve的结果通道
+func startTCPServer(t *testing.T, srv Server) (string, <-chan error) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(l)
+	}()
+	return l.Addr().String(), done
+}
+
+// roundTrip 发送一行并读取一行响应
+func roundTrip(t *testing.T, conn net.Conn, reader *bufio.Reader, line string) string {
+	t.Helper()
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Write([]byte(line + "\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	resp, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	return resp
+}
+
+func TestTCPServer(t *testing.T) {
+	r := router.NewRouter()
+	r.Match("WHO", func(ctx router_context.Context) error {
+		md := ctx.Metadata()
+		ctx.Response().WriteString(md.Transport + " " + md.ConnectionID)
+		return nil
+	})
+
+	srv := NewTCPServer(r, frame.NewLineFramer(0))
+	addr, done := startTCPServer(t, srv)
+
+	for _, expected := range []string{"tcp 1\n", "tcp 2\n"} {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatalf("Dial failed: %v", err)
+		}
+		if resp := roundTrip(t, conn, bufio.NewReader(conn), "WHO"); resp != expected {
+			t.Errorf("Expected %q, got %q", expected, resp)
+		}
+		conn.Close()
+	}
+
+	srv.Close()
+	if err := <-done; !errors.Is(err, ErrServerClosed) {
+		t.Errorf("Expected ErrServerClosed, got %v", err)
+	}
+}
+
+func TestTCPServerShutdown(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	r := router.NewRouter()
+	r.Match("SLOW", func(ctx router_context.Context) error {
+		close(started)
+		<-release
+		ctx.Response().WriteString("DONE")
+		return nil
+	})
+
+	srv := NewTCPServer(r, frame.NewLineFramer(0))
+	addr, done := startTCPServer(t, srv)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("SLOW\n"))
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		shutdown <- srv.Shutdown(ctx)
+	}()
+
+	// 正在处理的帧完成后仍然写回响应
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	resp, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || resp != "DONE\n" {
+		t.Errorf("Expected DONE before shutdown, got %q, %v", resp, err)
+	}
+
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+	if err := <-done; !errors.Is(err, ErrServerClosed) {
+		t.Errorf("Expected ErrServerClosed, got %v", err)
+	}
+	if _, err := net.Dial("tcp", addr); err == nil {
+		t.Error("Server should stop accepting connections after shutdown")
+	}
+}
+
+func TestTCPServerMaxConns(t *testing.T) {
+	r := router.NewRouter()
+	r.Match("PING", func(ctx router_context.Context) error {
+		ctx.Response().WriteString("PONG")
+		return nil
+	})
+
+	srv := NewTCPServer(r, frame.NewLineFramer(0), WithMaxConns(1))
+	addr, _ := startTCPServer(t, srv)
+	defer srv.Close()
+
+	first, _ := net.Dial("tcp", addr)
+	if resp := roundTrip(t, first, bufio.NewReader(first), "PING"); resp != "PONG\n" {
+		t.Fatalf("Expected PONG, got %q", resp)
+	}
+
+	// 第二个连接在第一个连接关闭前不会被服务
+	second, _ := net.Dial("tcp", addr)
+	defer second.Close()
+	second.Write([]byte("PING\n"))
+	reader := bufio.NewReader(second)
+	second.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	if _, err := reader.ReadString('\n'); err == nil {
+		t.Fatal("Second connection should wait for a free slot")
+	}
+
+	first.Close()
+	second.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if resp, err := reader.ReadString('\n'); err != nil || resp != "PONG\n" {
+		t.Errorf("Expected PONG after the first connection closed, got %q, %v", resp, err)
+	}
+}
+
+func TestTCPServerErrorHandler(t *testing.T) {
+	handlerErr := errors.New("bad frame")
+	r := router.NewRouter()
+	r.Match("BAD", func(ctx router_context.Context) error {
+		return handlerErr
+	})
+
+	errs := make(chan error, 1)
+	srv := NewTCPServer(r, frame.NewLineFramer(0), WithErrorHandler(func(conn net.Conn, err error) {
+		errs <- err
+	}))
+	addr, _ := startTCPServer(t, srv)
+	defer srv.Close()
+
+	conn, _ := net.Dial("tcp", addr)
+	defer conn.Close()
+	conn.Write([]byte("BAD\n"))
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, handlerErr) {
+			t.Errorf("Expected handler error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Error handler was not called")
+	}
+}
@@ -0,0 +1,61 @@
+package transport
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
+	"github.com/aomirun/content-router/router"
+)
+
+// selfSignedConfig 生成自签名证书的TLS配置
+func selfSignedConfig(t *testing.T) *tls.Config {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %v", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}
+}
+
+func TestTCPServerWithTLS(t *testing.T) {
+	r := router.NewRouter()
+	r.Match("PING", func(ctx router_context.Context) error {
+		ctx.Response().WriteString("PONG " + ctx.Metadata().Transport)
+		return nil
+	})
+
+	srv := NewTCPServer(r, frame.NewLineFramer(0), WithTLSConfig(selfSignedConfig(t)))
+	addr, _ := startTCPServer(t, srv)
+	defer srv.Close()
+
+	conn, err := tls.Dial("tcp", addr, &tls.Config{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+	if resp := roundTrip(t, conn, bufio.NewReader(conn), "PING"); resp != "PONG tls\n" {
+		t.Errorf("Expected PONG tls, got %q", resp)
+	}
+}
@@ -0,0 +1,22 @@
+package transport
+
+import (
+	"github.com/aomirun/content-router/frame"
+	"github.com/aomirun/content-router/router"
+)
+
+// NewUnixServer 创建Unix域套接字的流式服务器，用于sidecar和本机进程间通信
+// ListenAndServe的addr是套接字文件路径，监听器关闭时删除该文件
+//
+// 其余行为与NewTCPServer相同
+func NewUnixServer(handler router.ConnServer, framer frame.Framer, opts ...Option) Server {
+	return newServer("unix", handler, framer, opts)
+}
+
+// NewUnixgramServer 创建Unix域套接字的数据报服务器
+// 只有绑定了地址的客户端才能收到回复，未绑定地址时写入响应会返回错误
+//
+// 其余行为与NewUDPServer相同
+func NewUnixgramServer(r PacketRouter, opts ...Option) PacketServer {
+	return newPacketServer("unixgram", r, opts)
+}
@@ -0,0 +1,113 @@
+package transport
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/frame"
+	"github.com/aomirun/content-router/router"
+)
+
+func TestUnixServer(t *testing.T) {
+	r := router.NewRouter()
+	r.Match("PING", func(ctx router_context.Context) error {
+		if md := ctx.Metadata(); md.Transport != "unix" || md.ConnectionID == "" {
+			t.Errorf("Unexpected metadata %+v", md)
+		}
+		ctx.Response().WriteString("PONG")
+		return nil
+	})
+
+	path := filepath.Join(t.TempDir(), "router.sock")
+	srv := NewUnixServer(r, frame.NewLineFramer(0))
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.ListenAndServe(path)
+	}()
+
+	var conn net.Conn
+	var err error
+	for i := 0; i < 100; i++ {
+		if conn, err = net.Dial("unix", path); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	if resp := roundTrip(t, conn, bufio.NewReader(conn), "PING"); resp != "PONG\n" {
+		t.Errorf("Expected PONG, got %q", resp)
+	}
+
+	srv.Close()
+	if err := <-done; !errors.Is(err, ErrServerClosed) {
+		t.Errorf("Expected ErrServerClosed, got %v", err)
+	}
+}
+
+func TestUnixgramServer(t *testing.T) {
+	r := router.NewRouter()
+	r.Match("PING", func(ctx router_context.Context) error {
+		ctx.Response().WriteString("PONG")
+		return nil
+	})
+
+	dir := t.TempDir()
+	serverPath := filepath.Join(dir, "server.sock")
+	pc, err := net.ListenPacket("unixgram", serverPath)
+	if err != nil {
+		t.Fatalf("ListenPacket failed: %v", err)
+	}
+	errs := make(chan error, 1)
+	srv := NewUnixgramServer(r, WithPacketErrorHandler(func(addr net.Addr, err error) {
+		errs <- err
+	}))
+	go srv.Serve(pc)
+	defer srv.Close()
+
+	// 绑定了地址的客户端可以收到回复
+	clientAddr := &net.UnixAddr{Name: filepath.Join(dir, "client.sock"), Net: "unixgram"}
+	client, err := net.DialUnix("unixgram", clientAddr, &net.UnixAddr{Name: serverPath, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("DialUnix failed: %v", err)
+	}
+	defer client.Close()
+
+	client.Write([]byte("PING"))
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	reply := make([]byte, 16)
+	if n, err := client.Read(reply); err != nil || string(reply[:n]) != "PONG" {
+		t.Errorf("Expected PONG reply, got %q, %v", reply[:n], err)
+	}
+
+	// 未绑定地址的客户端无法收到回复
+	anonymous, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: serverPath, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("DialUnix failed: %v", err)
+	}
+	defer anonymous.Close()
+	anonymous.Write([]byte("PING"))
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrNoReplyAddr) {
+			t.Errorf("Expected ErrNoReplyAddr, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Reply to an unbound client should fail")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown failed: %v", err)
+	}
+}
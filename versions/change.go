@@ -0,0 +1,82 @@
+package versions
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/router"
+)
+
+var (
+	// ErrRouteExists 表示Add的路由名称已经存在
+	ErrRouteExists = errors.New("versions: route already exists")
+	// ErrRouteNotFound 表示Replace或Remove的路由不存在
+	ErrRouteNotFound = errors.New("versions: route not found")
+)
+
+// Route 定义版本中的一条路由，与router.RegisterRoute的参数对应
+type Route struct {
+	// Info 路由信息，Name在同一版本中唯一，Replace和Remove通过它引用路由
+	Info router_context.RouteInfo
+	// Matcher 匹配器
+	Matcher router.Matcher
+	// Handler 处理器
+	Handler router.HandlerFunc
+	// Middlewares 路由中间件
+	Middlewares []router.MiddlewareFunc
+}
+
+// Change 定义对路由表的一项修改
+// 接收上一版本的路由列表，返回修改后的列表，不能修改传入的切片
+type Change func(routes []Route) ([]Route, error)
+
+// Add 在路由表末尾添加一条路由，同名路由已经存在时返回ErrRouteExists
+// middleware是只包裹该路由的路由中间件，见router.RouteRegistrar
+func Add(info router_context.RouteInfo, matcher router.Matcher, handler router.HandlerFunc, middleware ...router.MiddlewareFunc) Change {
+	return func(routes []Route) ([]Route, error) {
+		if info.Name != "" && indexOf(routes, info.Name) >= 0 {
+			return nil, fmt.Errorf("%w: %q", ErrRouteExists, info.Name)
+		}
+		return append(slices.Clip(routes), Route{Info: info, Matcher: matcher, Handler: handler, Middlewares: middleware}), nil
+	}
+}
+
+// Replace 替换同名路由的匹配器、处理器和路由中间件，保持其匹配优先级，路由不存在时返回ErrRouteNotFound
+func Replace(info router_context.RouteInfo, matcher router.Matcher, handler router.HandlerFunc, middleware ...router.MiddlewareFunc) Change {
+	return func(routes []Route) ([]Route, error) {
+		i := indexOf(routes, info.Name)
+		if info.Name == "" || i < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrRouteNotFound, info.Name)
+		}
+		routes = slices.Clone(routes)
+		routes[i] = Route{Info: info, Matcher: matcher, Handler: handler, Middlewares: middleware}
+		return routes, nil
+	}
+}
+
+// Remove 删除指定名称的路由，路由不存在时返回ErrRouteNotFound
+func Remove(name string) Change {
+	return func(routes []Route) ([]Route, error) {
+		i := indexOf(routes, name)
+		if name == "" || i < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrRouteNotFound, name)
+		}
+		return slices.Delete(slices.Clone(routes), i, i+1), nil
+	}
+}
+
+// Reset 清空路由表，之后的修改从空路由表开始，用于推送完整的配置
+func Reset() Change {
+	return func(routes []Route) ([]Route, error) {
+		return nil, nil
+	}
+}
+
+// indexOf 返回指定名称的路由的位置，不存在时返回-1
+func indexOf(routes []Route, name string) int {
+	return slices.IndexFunc(routes, func(route Route) bool {
+		return route.Info.Name == name
+	})
+}
@@ -0,0 +1,256 @@
+// Package versions 把路由修改作为命名版本批量应用，并支持原子地回滚到之前的版本
+// 每个版本在独立的路由器上注册完整的路由表，切换版本只是替换当前路由器的指针，
+// 正在处理的消息在原版本上完成，之后的消息使用新版本，适合向网关集群推送配置
+package versions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aomirun/content-router/buffer"
+	"github.com/aomirun/content-router/events"
+	"github.com/aomirun/content-router/manage"
+	"github.com/aomirun/content-router/router"
+)
+
+// InitialVersion 是New创建的空路由表版本的名称
+const InitialVersion = "initial"
+
+// DefaultHistory 是默认保留的版本数量
+const DefaultHistory = 16
+
+var (
+	// ErrVersionExists 表示版本名称已经被使用
+	ErrVersionExists = errors.New("versions: version already exists")
+	// ErrVersionNotFound 表示版本不存在或已经不在保留的历史中
+	ErrVersionNotFound = errors.New("versions: version not found")
+)
+
+// Version 描述一个已应用的版本
+type Version struct {
+	// Name 版本名称
+	Name string `json:"name"`
+	// Parent 应用修改时的当前版本，初始版本为空
+	Parent string `json:"parent,omitempty"`
+	// Applied 版本的创建时间
+	Applied time.Time `json:"applied"`
+	// Routes 路由名称，按匹配顺序排列
+	Routes []string `json:"routes"`
+	// Current 是否为当前版本
+	Current bool `json:"current"`
+}
+
+// Option 定义版本化路由器的配置选项
+type Option func(*Router)
+
+// WithHistory 设置保留的版本数量，默认DefaultHistory，超出时丢弃最早创建的版本
+func WithHistory(n int) Option {
+	return func(r *Router) {
+		if n > 0 {
+			r.history = n
+		}
+	}
+}
+
+// WithClock 设置获取当前时间的函数，用于测试
+func WithClock(now func() time.Time) Option {
+	return func(r *Router) {
+		r.now = now
+	}
+}
+
+// WithEvents 设置发布events.ConfigReloaded的事件总线，Apply和Rollback切换版本后发布
+func WithEvents(bus *events.Bus) Option {
+	return func(r *Router) {
+		r.events = bus
+	}
+}
+
+// version 是一个已构建的版本
+type version struct {
+	name    string
+	parent  string
+	applied time.Time
+	routes  []Route
+	router  router.Router
+}
+
+// Router 是版本化的路由器
+// 实现router.RouteHandler、router.RouteResponder、router.BufferManagerAccessor和router.RouteInspector，
+// 可以代替普通路由器交给消息来源和传输层服务器，所有方法都是线程安全的
+type Router struct {
+	factory func() router.Router
+	history int
+	now     func() time.Time
+	events  *events.Bus
+
+	mu       sync.Mutex // 串行化Apply和Rollback
+	versions []*version // 保留的版本，按创建顺序排列
+	current  atomic.Pointer[version]
+}
+
+// New 创建版本化的路由器，初始版本InitialVersion没有路由
+//   - factory: 为每个版本创建路由器，在这里配置中间件和路由器选项；
+//     所有版本应当共享同一个BufferManager（默认的manage.Default()即可），
+//     因为消息来源获取缓冲区和路由可能发生在不同版本上
+func New(factory func() router.Router, opts ...Option) *Router {
+	r := &Router{
+		factory: factory,
+		history: DefaultHistory,
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	initial := &version{name: InitialVersion, applied: r.now(), router: factory()}
+	r.versions = []*version{initial}
+	r.current.Store(initial)
+	return r
+}
+
+// Apply 在当前版本的基础上应用一批修改，创建名为name的新版本并切换到它
+// 修改按顺序应用，任何一项失败时整批放弃，当前版本保持不变
+func (r *Router) Apply(name string, changes ...Change) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.find(name) != nil {
+		return fmt.Errorf("%w: %q", ErrVersionExists, name)
+	}
+	parent := r.current.Load()
+	routes := parent.routes
+	for i, change := range changes {
+		var err error
+		if routes, err = change(routes); err != nil {
+			return fmt.Errorf("versions: %s: change %d: %w", name, i, err)
+		}
+	}
+
+	next := &version{
+		name:    name,
+		parent:  parent.name,
+		applied: r.now(),
+		routes:  routes,
+		router:  r.factory(),
+	}
+	for _, route := range routes {
+		next.router.RegisterRoute(route.Info, route.Matcher, route.Handler, route.Middlewares...)
+	}
+
+	r.versions = append(r.versions, next)
+	if len(r.versions) > r.history {
+		r.versions = slices.Delete(r.versions, 0, len(r.versions)-r.history)
+	}
+	r.current.Store(next)
+	r.events.Publish(events.ConfigReloaded{Version: name, Previous: parent.name})
+	return nil
+}
+
+// Rollback 原子地切换到保留的历史中名为name的版本
+// 回滚不删除之后的版本，之后的Apply在回滚到的版本的基础上应用
+func (r *Router) Rollback(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	v := r.find(name)
+	if v == nil {
+		return fmt.Errorf("%w: %q", ErrVersionNotFound, name)
+	}
+	previous := r.current.Swap(v)
+	r.events.Publish(events.ConfigReloaded{Version: name, Previous: previous.name, Rollback: true})
+	return nil
+}
+
+// Undo 回滚到当前版本的上一版本（Parent）
+func (r *Router) Undo() error {
+	current := r.Current()
+	if current.Parent == "" {
+		return fmt.Errorf("%w: %s has no parent", ErrVersionNotFound, current.Name)
+	}
+	return r.Rollback(current.Parent)
+}
+
+// Current 返回当前版本
+func (r *Router) Current() Version {
+	return r.current.Load().describe(true)
+}
+
+// Versions 返回保留的所有版本，按创建顺序排列
+func (r *Router) Versions() []Version {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.current.Load()
+	versions := make([]Version, len(r.versions))
+	for i, v := range r.versions {
+		versions[i] = v.describe(v == current)
+	}
+	return versions
+}
+
+// Router 返回当前版本的路由器，用于读取路由拓扑或健康检查等
+// 返回的路由器在版本切换后不再接收新的消息，不应该长期持有或在上面注册路由
+func (r *Router) Router() router.Router {
+	return r.current.Load().router
+}
+
+// Route 使用当前版本路由消息
+func (r *Router) Route(ctx context.Context, buf buffer.Buffer) (buffer.Buffer, error) {
+	return r.Router().Route(ctx, buf)
+}
+
+// RouteTo 使用当前版本路由消息并写回响应
+func (r *Router) RouteTo(ctx context.Context, buf buffer.Buffer, w io.Writer) error {
+	return r.Router().RouteTo(ctx, buf, w)
+}
+
+// BufferManager 返回当前版本的缓冲区管理器
+func (r *Router) BufferManager() manage.BufferManager {
+	return r.Router().BufferManager()
+}
+
+// Inspect 返回当前版本的路由拓扑
+func (r *Router) Inspect() router.Topology {
+	return r.Router().Inspect()
+}
+
+// Routes 返回当前版本的路由
+func (r *Router) Routes() []router.RouteDescription {
+	return r.Router().Routes()
+}
+
+// Validate 静态分析当前版本的路由表
+func (r *Router) Validate() []router.Diagnostic {
+	return r.Router().Validate()
+}
+
+// find 查找保留的版本，调用方持有mu
+func (r *Router) find(name string) *version {
+	for _, v := range r.versions {
+		if v.name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// describe 返回版本的描述
+func (v *version) describe(current bool) Version {
+	routes := make([]string, len(v.routes))
+	for i, route := range v.routes {
+		routes[i] = route.Info.Name
+	}
+	return Version{
+		Name:    v.name,
+		Parent:  v.parent,
+		Applied: v.applied,
+		Routes:  routes,
+		Current: current,
+	}
+}
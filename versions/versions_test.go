@@ -0,0 +1,256 @@
+package versions
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/aomirun/content-router/buffer"
+	router_context "github.com/aomirun/content-router/context"
+	"github.com/aomirun/content-router/events"
+	"github.com/aomirun/content-router/router"
+	"github.com/aomirun/content-router/source"
+)
+
+// route 路由msg并返回匹配的路由名称，没有匹配时返回空字符串
+func route(t *testing.T, r *Router, msg string) string {
+	t.Helper()
+	buf := buffer.NewBuffer()
+	buf.WriteString(msg)
+	var name string
+	ctx := context.WithValue(context.Background(), nameKey{}, &name)
+	if _, err := r.Route(ctx, buf); err != nil {
+		t.Fatalf("Route(%q): %v", msg, err)
+	}
+	return name
+}
+
+// nameKey 是记录匹配的路由名称的键
+type nameKey struct{}
+
+// record 记录匹配的路由名称
+func record(ctx router_context.Context) error {
+	*ctx.Value(nameKey{}).(*string) = ctx.Route().Name
+	return nil
+}
+
+func info(name string) router_context.RouteInfo {
+	return router_context.RouteInfo{Name: name, Pattern: name}
+}
+
+func TestApplyRollback(t *testing.T) {
+	r := New(func() router.Router { return router.NewRouter() })
+	if got := route(t, r, "order:1"); got != "" {
+		t.Errorf("initial version matched %q", got)
+	}
+
+	if err := r.Apply("v1",
+		Add(info("orders"), router.PrefixMatcher("order:"), record),
+		Add(info("rest"), router.PrefixMatcher(""), record),
+	); err != nil {
+		t.Fatalf("Apply v1: %v", err)
+	}
+	if got := route(t, r, "order:1"); got != "orders" {
+		t.Errorf("v1 matched %q", got)
+	}
+
+	if err := r.Apply("v2",
+		Replace(info("orders"), router.PrefixMatcher("ORDER:"), record),
+		Remove("rest"),
+	); err != nil {
+		t.Fatalf("Apply v2: %v", err)
+	}
+	if got := route(t, r, "order:1"); got != "" {
+		t.Errorf("v2 matched %q", got)
+	}
+
+	if err := r.Undo(); err != nil {
+		t.Fatalf("Undo: %v", err)
+	}
+	if got := route(t, r, "order:1"); got != "orders" || r.Current().Name != "v1" {
+		t.Errorf("after undo matched %q in %s", got, r.Current().Name)
+	}
+
+	// 回滚后的修改基于回滚到的版本
+	if err := r.Apply("v3", Add(info("audit"), router.PrefixMatcher("audit"), record)); err != nil {
+		t.Fatalf("Apply v3: %v", err)
+	}
+	current := r.Current()
+	if current.Parent != "v1" || !slices.Equal(current.Routes, []string{"orders", "rest", "audit"}) {
+		t.Errorf("current = %+v", current)
+	}
+
+	if err := r.Rollback(InitialVersion); err != nil {
+		t.Fatalf("Rollback: %v", err)
+	}
+	if got := route(t, r, "order:1"); got != "" {
+		t.Errorf("initial version matched %q", got)
+	}
+	if err := r.Undo(); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("Undo initial = %v", err)
+	}
+
+	var names []string
+	for _, v := range r.Versions() {
+		names = append(names, v.Name)
+		if v.Current != (v.Name == InitialVersion) {
+			t.Errorf("version %s current = %v", v.Name, v.Current)
+		}
+	}
+	if !slices.Equal(names, []string{InitialVersion, "v1", "v2", "v3"}) {
+		t.Errorf("versions = %v", names)
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	r := New(func() router.Router { return router.NewRouter() })
+	if err := r.Apply("v1", Add(info("orders"), router.PrefixMatcher("order:"), record)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		version string
+		changes []Change
+		want    error
+	}{
+		{"existing version", "v1", nil, ErrVersionExists},
+		{"existing route", "v2", []Change{Add(info("orders"), router.PrefixMatcher("x"), record)}, ErrRouteExists},
+		{"replace missing", "v2", []Change{Replace(info("refunds"), router.PrefixMatcher("x"), record)}, ErrRouteNotFound},
+		{"remove missing", "v2", []Change{Add(info("refunds"), router.PrefixMatcher("x"), record), Remove("audit")}, ErrRouteNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := r.Apply(tt.version, tt.changes...); !errors.Is(err, tt.want) {
+				t.Errorf("Apply = %v, want %v", err, tt.want)
+			}
+			// 失败的批次不改变当前版本
+			if current := r.Current(); current.Name != "v1" || len(current.Routes) != 1 {
+				t.Errorf("current = %+v", current)
+			}
+		})
+	}
+	if err := r.Rollback("v9"); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("Rollback = %v", err)
+	}
+
+	if err := r.Apply("v2", Reset(), Add(info("refunds"), router.PrefixMatcher("refund:"), record)); err != nil {
+		t.Fatal(err)
+	}
+	if routes := r.Current().Routes; !slices.Equal(routes, []string{"refunds"}) {
+		t.Errorf("routes after reset = %v", routes)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	r := New(func() router.Router { return router.NewRouter() }, WithHistory(2))
+	for _, name := range []string{"v1", "v2", "v3"} {
+		if err := r.Apply(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var names []string
+	for _, v := range r.Versions() {
+		names = append(names, v.Name)
+	}
+	if !slices.Equal(names, []string{"v2", "v3"}) {
+		t.Errorf("versions = %v", names)
+	}
+	if err := r.Rollback("v1"); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("Rollback trimmed version = %v", err)
+	}
+}
+
+func TestConcurrentRollback(t *testing.T) {
+	r := New(func() router.Router { return router.NewRouter() })
+	if err := r.Apply("v1", Add(info("a"), router.PrefixMatcher(""), record)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Apply("v2", Replace(info("a"), router.PrefixMatcher(""), record)); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Go(func() {
+			for range 200 {
+				if got := route(t, r, "x"); got != "a" {
+					t.Errorf("matched %q", got)
+					return
+				}
+			}
+		})
+	}
+	for i := range 200 {
+		if err := r.Rollback([]string{"v1", "v2"}[i%2]); err != nil {
+			t.Error(err)
+		}
+	}
+	wg.Wait()
+}
+
+func TestEvents(t *testing.T) {
+	bus := events.NewBus()
+	ch, cancel := bus.Channel(4, events.KindConfigReloaded)
+	defer cancel()
+
+	r := New(func() router.Router { return router.NewRouter() }, WithEvents(bus))
+	if err := r.Apply("v1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Apply("v1"); err == nil {
+		t.Fatal("duplicate version applied")
+	}
+	if err := r.Undo(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []events.ConfigReloaded{
+		{Version: "v1", Previous: InitialVersion},
+		{Version: InitialVersion, Previous: "v1", Rollback: true},
+	}
+	for _, w := range want {
+		if got := <-ch; got != w {
+			t.Errorf("event = %+v, want %+v", got, w)
+		}
+	}
+	if len(ch) != 0 {
+		t.Errorf("unexpected event %+v", <-ch)
+	}
+}
+
+func TestSourceRouter(t *testing.T) {
+	// Router可以直接交给消息来源
+	var _ source.Router = New(func() router.Router { return router.NewRouter() })
+}
+
+func TestRouteMiddleware(t *testing.T) {
+	var calls int
+	count := func(ctx router_context.Context, next router.HandlerFunc) error {
+		calls++
+		return next(ctx)
+	}
+	r := New(func() router.Router { return router.NewRouter() })
+	if err := r.Apply("v1",
+		Add(info("orders"), router.PrefixMatcher("order:"), record, count),
+		Add(info("rest"), router.PrefixMatcher(""), record),
+	); err != nil {
+		t.Fatal(err)
+	}
+	route(t, r, "order:1")
+	route(t, r, "invoice:1")
+	if calls != 1 {
+		t.Errorf("route middleware ran %d times, want 1", calls)
+	}
+
+	// Replace不带中间件时移除原来的路由中间件
+	if err := r.Apply("v2", Replace(info("orders"), router.PrefixMatcher("order:"), record)); err != nil {
+		t.Fatal(err)
+	}
+	route(t, r, "order:2")
+	if calls != 1 {
+		t.Errorf("route middleware ran %d times after Replace, want 1", calls)
+	}
+}